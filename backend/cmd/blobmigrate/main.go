@@ -0,0 +1,135 @@
+// Команда blobmigrate переносит файлы, загруженные до перехода на BlobStore, из локальных каталогов
+// uploads/* в хранилище, настроенное переменными окружения STORAGE_* и S3_*, и записывает
+// ключ объекта и хеш содержимого в записи о файлах. По умолчанию выполняется пробный запуск,
+// который только проверяет наличие файлов на диске; с флагом -apply файлы переносятся.
+// Повторный запуск продолжает с еще не перенесенных записей; одинаковое содержимое хранится одним объектом.
+//
+// Пример:
+//
+//	go run ./cmd/blobmigrate
+//	go run ./cmd/blobmigrate -apply -source chat
+//	go run ./cmd/blobmigrate -apply -delete-local
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"tutoring-platform/internal/config"
+	"tutoring-platform/internal/database"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/internal/storage"
+	"tutoring-platform/pkg/logger"
+)
+
+func main() {
+	source := flag.String("source", "", "file source to migrate: chat, homework, lesson_broadcasts or broadcasts (default: all)")
+	apply := flag.Bool("apply", false, "upload files and update records (default: dry run)")
+	deleteLocal := flag.Bool("delete-local", false, "remove local files after a successful upload (requires -apply)")
+	chatDir := flag.String("chat-dir", "./uploads/chat", "directory of legacy chat attachments")
+	broadcastDir := flag.String("broadcast-dir", "./uploads/lesson_broadcasts", "directory of legacy lesson broadcast files")
+	adminBroadcastDir := flag.String("admin-broadcast-dir", "./uploads/broadcasts", "directory of legacy admin broadcast attachments")
+	flag.Parse()
+
+	if err := run(*source, *apply, *deleteLocal, *chatDir, *broadcastDir, *adminBroadcastDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(source string, apply, deleteLocal bool, chatDir, broadcastDir, adminBroadcastDir string) error {
+	sources := models.StoredFileSources
+	if source != "" {
+		if !slices.Contains(models.StoredFileSources, source) {
+			flag.Usage()
+			return fmt.Errorf("unknown source: %s", source)
+		}
+		sources = []string{source}
+	}
+	if deleteLocal && !apply {
+		return errors.New("-delete-local requires -apply")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logger.Setup(cfg.Server.Env)
+
+	db, err := database.New(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	store, err := storage.New(&cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize file storage: %w", err)
+	}
+	fileStorage := service.NewFileStorageService(store, repository.NewBlobRepository(db.Sqlx), cfg.Storage.PresignTTL)
+
+	// Пути в записях повторяют то, как их сохраняли исходные обработчики загрузки
+	resolvers := map[string]func(*models.LegacyFile) string{
+		models.StoredFileSourceChat: func(f *models.LegacyFile) string {
+			if filepath.IsAbs(f.FilePath) {
+				return f.FilePath
+			}
+			return filepath.Join(chatDir, filepath.Base(f.FilePath))
+		},
+		models.StoredFileSourceHomework: func(f *models.LegacyFile) string {
+			return f.FilePath
+		},
+		models.StoredFileSourceLessonBroadcasts: func(f *models.LegacyFile) string {
+			return filepath.Join(broadcastDir, filepath.Base(f.FilePath))
+		},
+		models.StoredFileSourceBroadcasts: func(f *models.LegacyFile) string {
+			return filepath.Join(adminBroadcastDir, filepath.Base(f.FilePath))
+		},
+	}
+
+	var results []*service.LegacyMigrationResult
+	for _, src := range sources {
+		result, err := fileStorage.MigrateLegacyFiles(context.Background(), src, resolvers[src], apply, deleteLocal)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+
+	if err := printResults(results, apply, cfg.Storage.Backend); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Failed > 0 {
+			return errors.New("some files failed to migrate")
+		}
+	}
+	return nil
+}
+
+// printResults выводит итоги по источникам в виде таблицы
+func printResults(results []*service.LegacyMigrationResult, apply bool, backend string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tTOTAL\tMIGRATED\tMISSING\tFAILED")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", r.Source, r.Total, r.Migrated, r.Missing, r.Failed)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	mode := "dry run (MIGRATED = files found on disk)"
+	if apply {
+		mode = "applied"
+	}
+	fmt.Printf("\n%s, storage backend: %s\n", mode, backend)
+	return nil
+}
@@ -0,0 +1,154 @@
+// Команда importer выполняет массовый импорт пользователей, занятий или записей на занятия
+// из CSV/XLSX файла теми же правилами, что и POST /api/v1/admin/import/{kind}.
+// По умолчанию выполняется пробный запуск с построчным отчетом; с флагом -apply файл без ошибок
+// применяется в одной транзакции. Подключение к БД настраивается переменными окружения DB_*.
+//
+// Пример:
+//
+//	go run ./cmd/importer -kind lessons -file lessons.xlsx -tz Europe/Moscow
+//	go run ./cmd/importer -kind lessons -file lessons.xlsx -tz Europe/Moscow -apply
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"tutoring-platform/internal/config"
+	"tutoring-platform/internal/database"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/internal/validator"
+	"tutoring-platform/pkg/logger"
+)
+
+func main() {
+	kind := flag.String("kind", "", "import kind: users, lessons or bookings")
+	file := flag.String("file", "", "path to CSV or XLSX file")
+	apply := flag.Bool("apply", false, "apply the file (default: dry run)")
+	tz := flag.String("tz", "UTC", "IANA time zone for lesson times without offset")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if err := run(models.ImportKind(*kind), *file, *apply, *tz, *asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(kind models.ImportKind, path string, apply bool, tz string, asJSON bool) error {
+	if !kind.IsValid() || path == "" {
+		flag.Usage()
+		return models.ErrInvalidImportKind
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return models.ErrInvalidImportTimezone
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logger.Setup(cfg.Server.Env)
+
+	db, err := database.New(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	lessonRepo := repository.NewLessonRepository(db.Sqlx)
+	bookingRepo := repository.NewBookingRepository(db.Sqlx)
+	creditRepo := repository.NewCreditRepository(db.Sqlx)
+	userRepo := repository.NewUserRepository(db.Sqlx)
+	// Пользовательские роли из БД допустимы в файле так же, как при создании через API
+	userService := service.NewUserService(userRepo, creditRepo)
+	userService.SetPermissionService(service.NewPermissionService(repository.NewPermissionRepository(db.Sqlx)))
+	bookingValidator := validator.NewBookingValidator(lessonRepo, bookingRepo, creditRepo)
+	// Уведомления о записях при импорте не отправляются
+	bookingService := service.NewBookingService(
+		db.Pool,
+		bookingRepo,
+		lessonRepo,
+		creditRepo,
+		repository.NewCancelledBookingRepository(db.Sqlx),
+		bookingValidator,
+		nil,
+		userRepo,
+	)
+	importService := service.NewImportService(
+		db.Pool,
+		repository.NewImportRepository(db.Sqlx),
+		userService,
+		bookingService,
+		lessonRepo,
+		bookingRepo,
+		creditRepo,
+		bookingValidator,
+	)
+
+	report, importErr := importService.Import(context.Background(), &service.ImportRequest{
+		Kind:     kind,
+		FileName: filepath.Base(path),
+		Data:     data,
+		DryRun:   !apply,
+		Location: loc,
+		// CLI запускает оператор с доступом к БД: права как у администратора
+		Actor: &models.User{Role: models.RoleAdmin},
+	})
+	if report != nil {
+		if err := printReport(report, asJSON); err != nil {
+			return err
+		}
+	}
+	if importErr != nil {
+		return importErr
+	}
+	if report.HasErrors() {
+		return errors.New("file has errors")
+	}
+	return nil
+}
+
+// printReport выводит построчный отчет в виде таблицы или JSON
+func printReport(report *models.ImportReport, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ROW\tSTATUS\tKEY\tDETAILS")
+	for _, row := range report.Rows {
+		details := strings.Join(row.Errors, "; ")
+		if row.TemporaryPassword != "" {
+			details = "temporary password: " + row.TemporaryPassword
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", row.Row, row.Status, row.Key, details)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	mode := "dry run"
+	if !report.DryRun {
+		mode = "applied"
+	}
+	fmt.Printf("\n%s: total %d, valid %d, created %d, skipped %d, errors %d\n",
+		mode, report.Total, report.Valid, report.Created, report.Skipped, report.Failed)
+	return nil
+}
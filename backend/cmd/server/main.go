@@ -21,9 +21,13 @@ import (
 	"tutoring-platform/internal/database"
 	"tutoring-platform/internal/handlers"
 	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/ratelimit"
 	"tutoring-platform/internal/repository"
 	"tutoring-platform/internal/service"
 	"tutoring-platform/internal/sse"
+	"tutoring-platform/internal/storage"
+	"tutoring-platform/internal/upload"
 	"tutoring-platform/internal/validator"
 	"tutoring-platform/pkg/auth"
 	"tutoring-platform/pkg/logger"
@@ -236,11 +240,12 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 	creditRepo := repository.NewCreditRepository(db.Sqlx)
 	swapRepo := repository.NewSwapRepository(db.Sqlx)
 	sessionRepo := repository.NewSessionRepository(db.Sqlx)
-	trialRequestRepo := repository.NewTrialRequestRepository(db.Sqlx)
+	trialRequestRepo := repository.NewTrialRequestRepository(db.Sqlx, userRepo)
 	telegramUserRepo := repository.NewTelegramUserRepository(db.Sqlx)
 	telegramTokenRepo := repository.NewTelegramTokenRepository(db.Sqlx)
 	broadcastRepo := repository.NewBroadcastRepository(db.Sqlx)
 	broadcastListRepo := repository.NewBroadcastListRepository(db.Sqlx)
+	broadcastAudienceRepo := repository.NewBroadcastAudienceRepository(db.Sqlx)
 	lessonModificationRepo := repository.NewLessonModificationRepository(db.Sqlx)
 	paymentRepo := repository.NewPaymentRepository(db.Sqlx)
 	chatRepo := repository.NewChatRepository(db.Sqlx)
@@ -248,6 +253,68 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 	homeworkRepo := repository.NewHomeworkRepository(db.Sqlx)
 	lessonBroadcastRepo := repository.NewLessonBroadcastRepository(db.Sqlx)
 	subjectRepo := repository.NewSubjectRepository(db.Sqlx)
+	academicCalendarRepo := repository.NewAcademicCalendarRepository(db.Sqlx)
+	groupRepo := repository.NewGroupRepository(db.Sqlx)
+	analyticsRepo := repository.NewAnalyticsRepository(db.Sqlx)
+	payrollRepo := repository.NewPayrollRepository(db.Sqlx)
+	importRepo := repository.NewImportRepository(db.Sqlx)
+	accountDataRepo := repository.NewAccountDataRepository(db.Sqlx)
+	auditRepo := repository.NewAuditRepository(db.Sqlx)
+	permissionRepo := repository.NewPermissionRepository(db.Sqlx)
+	moderationRepo := repository.NewModerationRepository(db.Sqlx)
+	subscriptionRepo := repository.NewSubscriptionRepository(db.Sqlx)
+	searchRepo := repository.NewSearchRepository(db.Sqlx)
+	blobRepo := repository.NewBlobRepository(db.Sqlx)
+	quarantineRepo := repository.NewQuarantineRepository(db.Sqlx)
+	storageQuotaRepo := repository.NewStorageQuotaRepository(db.Sqlx)
+
+	// Uploaded files go to the configured BlobStore (local directory or S3-compatible storage).
+	// Files uploaded before the switch keep working from their original local paths
+	blobStore, err := storage.New(&cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize file storage: %w", err)
+	}
+	fileStorageService := service.NewFileStorageService(blobStore, blobRepo, cfg.Storage.PresignTTL)
+	log.Info().Str("backend", cfg.Storage.Backend).Msg("File storage initialized")
+
+	// Uploads are checked by content type and stripped of image metadata; the malware scan is optional
+	switch cfg.UploadScan.Scanner {
+	case config.UploadScannerClamd:
+		clamdScanner, err := upload.NewClamdScanner(cfg.UploadScan.ClamdAddress, cfg.UploadScan.Timeout)
+		if err != nil {
+			return fmt.Errorf("failed to initialize clamd scanner: %w", err)
+		}
+		if err := clamdScanner.Ping(context.Background()); err != nil {
+			log.Warn().Err(err).Msg("clamd is not reachable, uploads will be rejected until it is available")
+		}
+		fileStorageService.SetScanner(clamdScanner, quarantineRepo)
+		log.Info().Str("address", cfg.UploadScan.ClamdAddress).Msg("Upload malware scan enabled")
+	case config.UploadScannerStub:
+		fileStorageService.SetScanner(upload.StubScanner{}, quarantineRepo)
+		log.Warn().Msg("Upload malware scan uses the stub scanner (EICAR test string only)")
+	}
+
+	// Thumbnails for images are always built; PDF previews need pdftoppm (poppler-utils)
+	var pdfRenderer upload.PDFRenderer
+	if renderer, err := upload.NewPdftoppmRenderer(); err == nil {
+		pdfRenderer = renderer
+	} else {
+		log.Info().Err(err).Msg("PDF previews disabled")
+	}
+	fileStorageService.SetThumbnailer(upload.NewThumbnailer(pdfRenderer))
+
+	// Per-user quotas are checked on every upload; the garbage collector removes files of records deleted
+	// longer than the grace period ago, including files left in the pre-BlobStore upload directories
+	fileStorageService.SetQuotas(storageQuotaRepo, cfg.Storage.UserQuotaBytes)
+	fileStorageService.SetGarbageCollection(cfg.Storage.GCGracePeriod, map[string]string{
+		models.StoredFileSourceChat:             "./uploads/chat",
+		models.StoredFileSourceHomework:         "./uploads/homework",
+		models.StoredFileSourceLessonBroadcasts: "./uploads/lesson_broadcasts",
+		models.StoredFileSourceBroadcasts:       "./uploads/broadcasts",
+	})
+	if cfg.Storage.GCInterval > 0 {
+		fileStorageService.StartGCWorker(cfg.Storage.GCInterval)
+	}
 
 	// Initialize validators
 	bookingValidator := validator.NewBookingValidator(lessonRepo, bookingRepo, creditRepo)
@@ -326,6 +393,7 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 	// Initialize YooKassa client (if configured)
 	var yookassaClient *service.YooKassaClient
 	var paymentService *service.PaymentService
+	var subscriptionService *service.SubscriptionService
 	if cfg.YooKassa.ShopID != "" && cfg.YooKassa.SecretKey != "" {
 		yookassaClient = service.NewYooKassaClient(cfg.YooKassa.ShopID, cfg.YooKassa.SecretKey)
 		log.Info().Msg("YooKassa payment gateway configured")
@@ -353,13 +421,45 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 		lessonService.SetTelegramService(telegramService)
 	}
 
+	// Wire up academic calendar to lesson service (skip/shift recurring lessons on holidays and breaks)
+	academicCalendarService := service.NewAcademicCalendarService(db.Pool, academicCalendarRepo, lessonRepo, creditRepo)
+	groupService := service.NewGroupService(db.Pool, groupRepo, lessonRepo, userRepo, subjectRepo, creditRepo)
+	lessonService.SetBlockedDatesProvider(academicCalendarService)
+
+	// Analytics reads materialized views; the worker refreshes them on start and then on a schedule
+	analyticsService := service.NewAnalyticsService(analyticsRepo)
+	analyticsService.StartRefreshWorker()
+
+	payrollService := service.NewPayrollService(db.Pool, payrollRepo, userRepo)
+	importService := service.NewImportService(db.Pool, importRepo, userService, bookingService, lessonRepo, bookingRepo, creditRepo, bookingValidator)
+	accountDataService := service.NewAccountDataService(db.Pool, accountDataRepo, userRepo, creditRepo, paymentRepo)
+	accountDataService.SetFileStorage(fileStorageService)
+
 	creditService := service.NewCreditService(db.Pool, creditRepo)
 	swapService := service.NewSwapService(db.Pool, swapRepo, bookingRepo, lessonRepo, swapValidator)
-	trialRequestService := service.NewTrialRequestService(trialRequestRepo, trialRequestValidator, nil) // TelegramService will be created below
+	trialRequestService := service.NewTrialRequestService(db.Pool, trialRequestRepo, lessonRepo, userRepo, trialRequestValidator, nil) // TelegramService will be created below
 	bulkEditService := service.NewBulkEditService(db.Pool, lessonRepo, lessonModificationRepo, userRepo, creditRepo)
 
-	// Initialize chat service (moderation will be handled by the service internally)
-	chatService := service.NewChatService(chatRepo, userRepo, nil)
+	// Initialize moderation service: the review queue is always available, message checks only when enabled.
+	// The backend is OpenRouter (LLM) or the local regex moderator, with regex fallback behind a circuit breaker
+	var openRouterClient *service.OpenRouterClient
+	if cfg.Moderation.Provider == config.ModerationProviderOpenRouter && cfg.Moderation.OpenRouterAPIKey != "" {
+		openRouterClient = service.NewOpenRouterClientWithConfig(cfg.Moderation.OpenRouterAPIKey, cfg.Moderation.OpenRouterModel, cfg.Moderation.Timeout, 3)
+	}
+	moderationService := service.NewModerationService(openRouterClient, chatRepo, telegramService)
+	moderationService.SetReviewRepository(moderationRepo)
+
+	// Initialize chat service (messages go through moderation before delivery when it is enabled)
+	var chatModeration *service.ModerationService
+	if cfg.Moderation.Enabled {
+		chatModeration = moderationService
+		log.Info().Str("moderator", moderationService.ModeratorName()).Msg("Chat message moderation enabled")
+	}
+	chatService := service.NewChatService(chatRepo, userRepo, chatModeration)
+	chatService.SetGroupRepository(chatRepo)
+	chatService.SetReadRepository(chatRepo)
+	chatService.SetMessageRepository(chatRepo)
+	moderationService.SetDeliveryHandler(chatService.DeliverMessage)
 
 	// Initialize SSE connection manager for real-time chat updates
 	// ChatParticipantsProvider callback returns participants for a chat room
@@ -371,12 +471,13 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 			log.Warn().Err(err).Str("room_id", roomID.String()).Msg("Failed to get chat room participants for SSE")
 			return nil
 		}
-		return []uuid.UUID{room.TeacherID, room.StudentID}
+		return room.ParticipantIDs()
 	}
 	sseManager := sse.NewConnectionManagerUUID(chatParticipantsProvider)
 
 	// Wire up SSE manager to chat service for broadcasting messages
 	chatService.SetSSEManager(sseManager)
+	moderationService.SetSSEManager(sseManager)
 
 	// Wire up Telegram service to chat service for message notifications
 	if telegramService != nil {
@@ -388,28 +489,65 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 
 	// Initialize homework service
 	homeworkService := service.NewHomeworkService(homeworkRepo, lessonRepo, bookingRepo, userRepo)
+	homeworkService.SetFileStorage(fileStorageService)
 
 	// Initialize broadcast service always (for list management), even without Telegram bot
 	// Pass nil telegramClient if not configured - sending will fail gracefully but CRUD operations work
-	broadcastService = service.NewBroadcastService(broadcastRepo, broadcastListRepo, telegramUserRepo, userRepo, telegramClient)
+	broadcastService = service.NewBroadcastService(broadcastRepo, broadcastListRepo, broadcastAudienceRepo, telegramUserRepo, userRepo, telegramClient)
+	broadcastService.SetFileStorage(fileStorageService)
+
+	// Scheduled broadcasts can only be delivered through the bot, so the scheduler runs only when it is configured
+	if telegramClient != nil {
+		broadcastService.StartScheduler()
+	}
 
 	// Initialize lesson broadcast service (for lesson-specific broadcasts to enrolled students)
 	// Pass nil telegramClient if not configured - sending will fail gracefully
 	uploadDir := "./uploads/lesson_broadcasts"
 	lessonBroadcastService := service.NewLessonBroadcastService(db.Sqlx, lessonBroadcastRepo, lessonRepo, userRepo, telegramUserRepo, telegramClient, uploadDir)
+	lessonBroadcastService.SetFileStorage(fileStorageService)
 
 	// Initialize payment service only if YooKassa is configured
 	if yookassaClient != nil {
 		paymentService = service.NewPaymentService(db.Pool, paymentRepo, creditService, yookassaClient, userRepo, cfg.YooKassa.ReturnURL)
+
+		// Subscriptions bill through saved YooKassa payment methods, so they require YooKassa as well
+		subscriptionService = service.NewSubscriptionService(db.Pool, subscriptionRepo, creditService, yookassaClient, userRepo, cfg.YooKassa.ReturnURL)
+		subscriptionService.StartBillingWorker()
 	}
 
+	// Role permissions are stored in the DB; ownership checkers back the *.own permissions
+	permissionService := service.NewPermissionService(permissionRepo)
+	permissionService.RegisterOwnershipChecker(service.ResourceLesson, service.OwnershipCheckerFunc(permissionRepo.IsLessonTeacher))
+	userService.SetPermissionService(permissionService)
+
+	// Full-text search respects chat membership and lesson visibility rules
+	searchService := service.NewSearchService(searchRepo)
+
 	// Initialize payment settings service
 	paymentSettingsService := service.NewPaymentSettingsService(userRepo)
 
+	// Unified audit log: services record detailed before/after events, AuditMiddleware covers other admin mutations
+	auditService := service.NewAuditService(auditRepo)
+	authService.SetAuditService(auditService)
+	authService.SetPermissionService(permissionService)
+	userService.SetAuditService(auditService)
+	lessonService.SetAuditService(auditService)
+	chatService.SetAuditService(auditService)
+	moderationService.SetAuditService(auditService)
+	paymentSettingsService.SetAuditService(auditService)
+	paymentSettingsService.SetPermissionService(permissionService)
+	if telegramService != nil {
+		telegramService.SetAuditService(auditService)
+	}
+
 	// Now update TrialRequestService with TelegramService
 	if telegramService != nil {
 		trialRequestService.SetTelegramService(telegramService)
 
+		// Follow-up reminders are delivered through the bot
+		trialRequestService.StartReminderWorker()
+
 		// Start polling in development mode (production uses webhook)
 		if cfg.IsDevelopment() {
 			// Создаём обработчик, который передаёт обновления в TelegramService
@@ -439,10 +577,25 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 	// Initialize CSRF store для защиты от CSRF атак
 	csrfStore := middleware.NewCSRFTokenStore()
 
-	// Initialize rate limiters для защиты от brute-force атак
-	loginRateLimiter := middleware.LoginRateLimiter()
-	trialRequestRateLimiter := middleware.TrialRequestRateLimiter()
-	paymentRateLimiter := middleware.PaymentRateLimiter() // 10 requests/min per user
+	// Initialize rate limit store для защиты от brute-force атак
+	// memory - per process; postgres / redis - limits shared between replicas and kept across restarts
+	rateLimitStore, err := ratelimit.New(&cfg.RateLimit, db.Sqlx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize rate limit store: %w", err)
+	}
+	if fallbackStore, ok := rateLimitStore.(*ratelimit.FallbackStore); ok {
+		if redisStore, ok := fallbackStore.Shared().(*ratelimit.RedisStore); ok {
+			if err := redisStore.Ping(context.Background()); err != nil {
+				log.Warn().Err(err).Msg("Redis is not reachable, rate limits follow RATE_LIMIT_<GROUP>_ON_STORE_ERROR until it is available")
+			}
+		}
+	}
+	log.Info().Str("store", cfg.RateLimit.Store).Msg("Rate limit store initialized")
+	loginRateLimit := middleware.RateLimitByIP(rateLimitStore, "login", ratelimit.Limit(cfg.RateLimit.Login), cfg.Server.TrustedProxies)
+	trialRequestRateLimit := middleware.RateLimitByIP(rateLimitStore, "trial_request", ratelimit.Limit(cfg.RateLimit.TrialRequest), cfg.Server.TrustedProxies)
+	paymentRateLimit := middleware.RateLimitByUser(rateLimitStore, "payment", ratelimit.Limit(cfg.RateLimit.Payment))
+	chatSendRateLimit := middleware.RateLimitByUser(rateLimitStore, "chat_send", ratelimit.Limit(cfg.RateLimit.ChatSend))
+	uploadRateLimit := middleware.RateLimitByUser(rateLimitStore, "upload", ratelimit.Limit(cfg.RateLimit.Upload))
 
 	// Initialize body limit config для защиты от DoS атак через большие payload'ы
 	bodyLimitConfig := middleware.DefaultBodyLimitConfig()
@@ -463,9 +616,25 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 		log.Error().Err(err).Msg("Failed to create chat upload directory")
 	}
 	chatHandler := handlers.NewChatHandler(chatService, chatUploadDir)
+	chatHandler.SetFileStorage(fileStorageService)
 	homeworkHandler := handlers.NewHomeworkHandler(homeworkService)
+	homeworkHandler.SetFileStorage(fileStorageService)
 	lessonBroadcastHandler := handlers.NewLessonBroadcastHandler(lessonBroadcastService, uploadDir)
+	lessonBroadcastHandler.SetFileStorage(fileStorageService)
 	subjectsHandler := handlers.NewSubjectsHandler(subjectRepo)
+	subjectsHandler.SetAuditService(auditService)
+	academicCalendarHandler := handlers.NewAcademicCalendarHandler(academicCalendarService)
+	groupHandler := handlers.NewGroupHandler(groupService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	payrollHandler := handlers.NewPayrollHandler(payrollService)
+	importHandler := handlers.NewImportHandler(importService)
+	accountDataHandler := handlers.NewAccountDataHandler(accountDataService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	permissionHandler := handlers.NewPermissionHandler(permissionService)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
+	uploadQuarantineHandler := handlers.NewUploadQuarantineHandler(fileStorageService)
+	storageHandler := handlers.NewStorageHandler(fileStorageService)
+	searchHandler := handlers.NewSearchHandler(searchService)
 
 	// Initialize broadcast handler always (for list management CRUD)
 	// telegramService can be nil - SendBroadcast will fail gracefully, but list CRUD works
@@ -483,6 +652,13 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 		paymentHandler = handlers.NewPaymentHandler(paymentService, cfg)
 	}
 
+	// Initialize subscription handler only if service is available
+	var subscriptionHandler *handlers.SubscriptionHandler
+	if subscriptionService != nil {
+		subscriptionHandler = handlers.NewSubscriptionHandler(subscriptionService)
+		paymentHandler.SetSubscriptionProcessor(subscriptionService)
+	}
+
 	// Initialize payment settings handler
 	paymentSettingsHandler := handlers.NewPaymentSettingsHandler(paymentSettingsService)
 
@@ -518,11 +694,11 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 		// Public routes
 		r.Group(func(r chi.Router) {
 			// Auth routes с rate limiting для защиты от brute-force
-			r.With(middleware.RateLimitMiddleware(loginRateLimiter)).Post("/auth/register", authHandler.Register)
-			r.With(middleware.RateLimitMiddleware(loginRateLimiter)).Post("/auth/login", authHandler.Login)
-			r.With(middleware.RateLimitMiddleware(loginRateLimiter)).Post("/auth/register-telegram", authHandler.RegisterViaTelegram)
+			r.With(loginRateLimit).Post("/auth/register", authHandler.Register)
+			r.With(loginRateLimit).Post("/auth/login", authHandler.Login)
+			r.With(loginRateLimit).Post("/auth/register-telegram", authHandler.RegisterViaTelegram)
 			// Trial requests с rate limiting для защиты от спама
-			r.With(middleware.RateLimitMiddleware(trialRequestRateLimiter)).Post("/trial-requests", trialRequestHandler.CreateTrialRequest)
+			r.With(trialRequestRateLimit).Post("/trial-requests", trialRequestHandler.CreateTrialRequest)
 			// Public subjects list (no authentication required for browsing subjects)
 			r.Get("/subjects", subjectsHandler.GetSubjects)
 			// Telegram webhook - public endpoint (only if Telegram is configured)
@@ -538,6 +714,11 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 		// Protected routes
 		r.Group(func(r chi.Router) {
 			r.Use(authMiddleware.Authenticate)
+			r.Use(middleware.AuditMiddleware(auditService))
+			// Read-only impersonation sessions may only read, stop impersonation or log out
+			r.Use(middleware.ReadOnlySessionGuard)
+			// Role permissions for RequirePermission and handler checks
+			r.Use(middleware.LoadPermissions(permissionService))
 
 			// GET /csrf-token endpoint (доступен без CSRF токена)
 			r.Get("/csrf-token", authHandler.GetCSRFToken)
@@ -549,6 +730,14 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/logout", authHandler.Logout)
 				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/profile", authHandler.UpdateProfile)
 				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/change-password", authHandler.ChangePassword)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/impersonation/stop", authHandler.StopImpersonation)
+			})
+
+			// Personal data export and account erasure request (erasure is executed after admin approval)
+			r.Route("/me", func(r chi.Router) {
+				r.Get("/export", accountDataHandler.Export)
+				r.Get("/erasure", accountDataHandler.GetErasureRequest)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/erasure", accountDataHandler.RequestErasure)
 			})
 
 			// User routes
@@ -557,25 +746,24 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 				// GET /users/{id} and all write operations require admin
 				r.Get("/", userHandler.GetUsers)
 
-				// Admin-only routes (GET specific user + all write operations)
-				r.Group(func(r chi.Router) {
-					r.Use(middleware.RequireAdmin)
-					r.Get("/{id}", userHandler.GetUser)
-					// State-changing endpoints с CSRF protection
-					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/", userHandler.CreateUser)
-					r.With(middleware.CSRFMiddleware(csrfStore)).Put("/{id}", userHandler.UpdateUser)
-					r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/{id}", userHandler.DeleteUser)
-					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{id}/credits", creditHandler.AddCredits)
-
-					// Admin Telegram management routes (only if Telegram is configured)
-					if adminTelegramHandler != nil {
-						r.Get("/telegram", adminTelegramHandler.ListUsersWithTelegram)
-						r.Get("/{id}/telegram", adminTelegramHandler.GetUserTelegramInfo)
-						r.With(middleware.CSRFMiddleware(csrfStore)).Put("/{id}/telegram", adminTelegramHandler.SetUserTelegram)
-						r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/{id}/telegram", adminTelegramHandler.UnlinkUserTelegram)
-						r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{id}/telegram/message", adminTelegramHandler.SendMessageToUser)
-					}
-				})
+				// User management (GET specific user + all write operations), per-route permissions
+				r.With(middleware.RequirePermission(models.PermUsersRead)).Get("/{id}", userHandler.GetUser)
+				// State-changing endpoints с CSRF protection
+				r.With(middleware.RequirePermission(models.PermUsersCreate), middleware.CSRFMiddleware(csrfStore)).Post("/", userHandler.CreateUser)
+				r.With(middleware.RequirePermission(models.PermUsersEdit), middleware.CSRFMiddleware(csrfStore)).Put("/{id}", userHandler.UpdateUser)
+				r.With(middleware.RequirePermission(models.PermUsersDelete), middleware.CSRFMiddleware(csrfStore)).Delete("/{id}", userHandler.DeleteUser)
+				r.With(middleware.RequirePermission(models.PermCreditsGrant), middleware.CSRFMiddleware(csrfStore)).Post("/{id}/credits", creditHandler.AddCredits)
+				// Admin impersonation ("view as user"): separate time-limited session, audited
+				r.With(middleware.RequirePermission(models.PermUsersImpersonate), middleware.CSRFMiddleware(csrfStore)).Post("/{id}/impersonate", authHandler.StartImpersonation)
+
+				// Telegram management routes (only if Telegram is configured)
+				if adminTelegramHandler != nil {
+					r.With(middleware.RequirePermission(models.PermUsersRead)).Get("/telegram", adminTelegramHandler.ListUsersWithTelegram)
+					r.With(middleware.RequirePermission(models.PermUsersRead)).Get("/{id}/telegram", adminTelegramHandler.GetUserTelegramInfo)
+					r.With(middleware.RequirePermission(models.PermUsersEdit), middleware.CSRFMiddleware(csrfStore)).Put("/{id}/telegram", adminTelegramHandler.SetUserTelegram)
+					r.With(middleware.RequirePermission(models.PermUsersEdit), middleware.CSRFMiddleware(csrfStore)).Delete("/{id}/telegram", adminTelegramHandler.UnlinkUserTelegram)
+					r.With(middleware.RequirePermission(models.PermUsersEdit), middleware.CSRFMiddleware(csrfStore)).Post("/{id}/telegram/message", adminTelegramHandler.SendMessageToUser)
+				}
 			})
 
 			// Subject routes
@@ -583,9 +771,9 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 				// GET single subject endpoint (accessible to authenticated users)
 				r.Get("/{id}", subjectsHandler.GetSubject)
 
-				// Admin or Teacher routes for subject management
+				// Subject management
 				r.Group(func(r chi.Router) {
-					r.Use(middleware.RequireAdminOrTeacher)
+					r.Use(middleware.RequirePermission(models.PermSubjectsManage))
 					// State-changing endpoints с CSRF protection
 					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/", subjectsHandler.CreateSubject)
 					r.With(middleware.CSRFMiddleware(csrfStore)).Put("/{id}", subjectsHandler.UpdateSubject)
@@ -597,9 +785,9 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 			r.Get("/my-subjects", subjectsHandler.GetMySubjects)
 			r.Route("/teachers/{id}/subjects", func(r chi.Router) {
 				r.Get("/", subjectsHandler.GetTeacherSubjects)
-				// Admin or Teacher routes for assigning subjects to teachers
+				// Assigning subjects to teachers
 				r.Group(func(r chi.Router) {
-					r.Use(middleware.RequireAdminOrTeacher)
+					r.Use(middleware.RequirePermission(models.PermSubjectsManage))
 					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/", subjectsHandler.AssignSubjectToTeacher)
 					r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/{subjectId}", subjectsHandler.RemoveSubjectFromTeacher)
 				})
@@ -617,14 +805,16 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 				r.Route("/{id}/homework", func(r chi.Router) {
 					// GET homework list - доступно всем авторизованным пользователям
 					r.Get("/", homeworkHandler.GetHomework)
-					// Upload homework - только admin или teacher урока (CSRF protected, with 10MB body limit for file uploads)
-					r.With(middleware.BodyLimitMiddlewareForFileUpload(bodyLimitConfig), middleware.CSRFMiddleware(csrfStore)).Post("/", homeworkHandler.UploadHomework)
+					// Upload homework - только admin или teacher урока (CSRF protected, with 10MB body limit for file uploads, rate limited per user)
+					r.With(middleware.BodyLimitMiddlewareForFileUpload(bodyLimitConfig), middleware.CSRFMiddleware(csrfStore), uploadRateLimit).Post("/", homeworkHandler.UploadHomework)
 					// Delete homework file - только creator или admin (CSRF protected)
 					r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/{file_id}", homeworkHandler.DeleteHomework)
 					// Update homework description - только admin, creator или teacher урока (CSRF protected)
 					r.With(middleware.CSRFMiddleware(csrfStore)).Patch("/{file_id}", homeworkHandler.UpdateHomework)
 					// Download homework file - доступно всем авторизованным пользователям
 					r.Get("/{file_id}/download", homeworkHandler.DownloadHomework)
+					// Image / PDF first-page preview - same access rules as download
+					r.Get("/{file_id}/thumbnail", homeworkHandler.DownloadHomeworkThumbnail)
 				})
 
 				// Lesson broadcast routes - доступно admin или teacher урока
@@ -632,26 +822,23 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 					// GET broadcasts - доступно всем авторизованным пользователям
 					r.Get("/", lessonBroadcastHandler.ListBroadcasts)
 					r.Get("/{broadcast_id}", lessonBroadcastHandler.GetBroadcast)
-					// Create broadcast - только admin или teacher урока (CSRF protected, with 10MB body limit for file uploads)
-					r.With(middleware.BodyLimitMiddlewareForFileUpload(bodyLimitConfig), middleware.CSRFMiddleware(csrfStore)).Post("/", lessonBroadcastHandler.CreateBroadcast)
+					// Create broadcast - только admin или teacher урока (CSRF protected, with 10MB body limit for file uploads, rate limited per user)
+					r.With(middleware.BodyLimitMiddlewareForFileUpload(bodyLimitConfig), middleware.CSRFMiddleware(csrfStore), uploadRateLimit).Post("/", lessonBroadcastHandler.CreateBroadcast)
 					// Download broadcast file - доступно всем авторизованным пользователям с проверкой прав
 					r.Get("/{broadcast_id}/files/{file_id}/download", lessonBroadcastHandler.DownloadBroadcastFile)
 				})
 
-				// Admin or Teacher lesson endpoints с CSRF protection
+				// Lesson management endpoints с CSRF protection
 				r.Group(func(r chi.Router) {
-					r.Use(middleware.RequireAdminOrTeacher)
 					r.Use(middleware.CSRFMiddleware(csrfStore))
-					r.Post("/", lessonHandler.CreateLesson)
-					r.Delete("/{id}", lessonHandler.DeleteLesson)
-					r.Post("/{id}/apply-to-all", lessonHandler.ApplyToAllSubsequent)
-					r.Post("/{id}/report/send-to-parents", lessonHandler.SendReportToParents)
-					r.Post("/{id}/recurring", lessonHandler.CreateRecurringSeriesFromLesson)
-					r.Delete("/{id}/recurring", lessonHandler.CancelRecurringFromLesson)
+					r.With(middleware.RequirePermission(models.PermLessonsCreate)).Post("/", lessonHandler.CreateLesson)
+					r.With(middleware.RequireOwnership(permissionService, service.ResourceLesson, models.PermLessonsDeleteOwn, models.PermLessonsDeleteAll, "id")).Delete("/{id}", lessonHandler.DeleteLesson)
+					r.With(middleware.RequireOwnership(permissionService, service.ResourceLesson, models.PermLessonsEditOwn, models.PermLessonsEditAll, "id")).Put("/{id}", lessonHandler.UpdateLesson)
+					r.With(middleware.RequireOwnership(permissionService, service.ResourceLesson, models.PermLessonsEditOwn, models.PermLessonsEditAll, "id")).Post("/{id}/apply-to-all", lessonHandler.ApplyToAllSubsequent)
+					r.With(middleware.RequireOwnership(permissionService, service.ResourceLesson, models.PermLessonsEditOwn, models.PermLessonsEditAll, "id")).Post("/{id}/report/send-to-parents", lessonHandler.SendReportToParents)
+					r.With(middleware.RequirePermission(models.PermLessonsCreate)).Post("/{id}/recurring", lessonHandler.CreateRecurringSeriesFromLesson)
+					r.With(middleware.RequireOwnership(permissionService, service.ResourceLesson, models.PermLessonsDeleteOwn, models.PermLessonsDeleteAll, "id")).Delete("/{id}/recurring", lessonHandler.CancelRecurringFromLesson)
 				})
-
-				// Update lesson - доступно admin и teacher (проверка прав внутри обработчика)
-				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/{id}", lessonHandler.UpdateLesson)
 			})
 
 			// Booking routes
@@ -670,9 +857,9 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 				r.Get("/", creditHandler.GetMyCredits)
 				r.Get("/balance", creditHandler.GetBalance) // Optimized endpoint for sidebar polling
 				r.Get("/history", creditHandler.GetMyHistory)
-				// Admin and teacher routes (GET only - CSRF не нужен)
+				// Balances of other users (GET only - CSRF не нужен)
 				r.Group(func(r chi.Router) {
-					r.Use(middleware.RequireAdminOrTeacher)
+					r.Use(middleware.RequirePermission(models.PermCreditsReadAll))
 					r.Get("/all", creditHandler.GetAllCredits)
 					r.Get("/user/{id}", creditHandler.GetUserCredits)
 				})
@@ -689,13 +876,25 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 			if paymentHandler != nil {
 				r.Route("/payments", func(r chi.Router) {
 					r.Get("/history", paymentHandler.GetHistory)
-					r.With(middleware.UserRateLimitMiddleware(paymentRateLimiter), middleware.CSRFMiddleware(csrfStore)).Post("/create", paymentHandler.CreatePayment)
+					r.With(paymentRateLimit, middleware.CSRFMiddleware(csrfStore)).Post("/create", paymentHandler.CreatePayment)
 				})
 			}
 
-			// Trial requests - GET list (admin or teacher)
+			// Subscription routes (only if YooKassa is configured)
+			if subscriptionHandler != nil {
+				r.Route("/subscriptions", func(r chi.Router) {
+					r.Get("/plans", subscriptionHandler.ListPlans)
+					r.Get("/me", subscriptionHandler.GetMySubscription)
+					r.With(paymentRateLimit, middleware.CSRFMiddleware(csrfStore)).Post("/", subscriptionHandler.Subscribe)
+					r.With(paymentRateLimit, middleware.CSRFMiddleware(csrfStore)).Post("/me/plan", subscriptionHandler.ChangePlan)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/me/cancel", subscriptionHandler.CancelMySubscription)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/me/resume", subscriptionHandler.ResumeMySubscription)
+				})
+			}
+
+			// Trial requests - GET list
 			r.Group(func(r chi.Router) {
-				r.Use(middleware.RequireAdminOrTeacher)
+				r.Use(middleware.RequirePermission(models.PermTrialRequestsRead))
 				r.Get("/trial-requests", trialRequestHandler.GetTrialRequests)
 			})
 
@@ -710,10 +909,10 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 				})
 			}
 
-			// Telegram broadcast routes - admin and teacher (always available, even without Telegram)
+			// Telegram broadcast routes (always available, even without Telegram)
 			// List management (CRUD) works without bot, but sending requires bot configured
 			r.Group(func(r chi.Router) {
-				r.Use(middleware.RequireAdminOrTeacher)
+				r.Use(middleware.RequirePermission(models.PermBroadcastsManage))
 
 				// Telegram users management (GET only)
 				r.Get("/admin/telegram/users", broadcastHandler.GetLinkedUsers)
@@ -728,13 +927,156 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 				// Broadcasts (GET + CSRF protected sending)
 				r.Get("/admin/telegram/broadcasts", broadcastHandler.GetBroadcasts)
 				r.Get("/admin/telegram/broadcasts/{id}", broadcastHandler.GetBroadcastDetails)
-				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/admin/telegram/broadcast", broadcastHandler.SendBroadcast)
+				r.With(middleware.BodyLimitMiddlewareForFileUpload(bodyLimitConfig), middleware.CSRFMiddleware(csrfStore)).Post("/admin/telegram/broadcast", broadcastHandler.SendBroadcast)
 				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/admin/telegram/broadcasts/{id}/cancel", broadcastHandler.CancelBroadcast)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/admin/telegram/broadcasts/{id}/message", broadcastHandler.EditBroadcastMessage)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/admin/telegram/broadcasts/{id}/messages", broadcastHandler.RecallBroadcast)
+
+				// Broadcast templates and saved audiences
+				r.Get("/admin/telegram/templates", broadcastHandler.GetTemplates)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/admin/telegram/templates", broadcastHandler.CreateTemplate)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/admin/telegram/templates/{id}", broadcastHandler.UpdateTemplate)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/admin/telegram/templates/{id}", broadcastHandler.DeleteTemplate)
+				r.Get("/admin/telegram/audiences", broadcastHandler.GetAudiences)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/admin/telegram/audiences", broadcastHandler.CreateAudience)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/admin/telegram/audiences/preview", broadcastHandler.PreviewAudience)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/admin/telegram/audiences/{id}", broadcastHandler.UpdateAudience)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/admin/telegram/audiences/{id}", broadcastHandler.DeleteAudience)
+			})
+
+			// Bulk lesson modifications - admin only (GET + CSRF protected revert)
+			r.Route("/lesson-modifications", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermLessonModificationsRevert))
+
+				r.Get("/{id}", lessonHandler.GetLessonModification)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{id}/revert", lessonHandler.RevertLessonModification)
+			})
+
+			// Academic calendar - admin only (GET + CSRF protected state-changing)
+			r.Route("/admin/calendar", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermCalendarManage))
+
+				r.Get("/", academicCalendarHandler.ListEntries)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/", academicCalendarHandler.CreateEntry)
+				r.With(middleware.BodyLimitMiddlewareForFileUpload(bodyLimitConfig), middleware.CSRFMiddleware(csrfStore)).Post("/import", academicCalendarHandler.ImportICS)
+				r.Get("/conflicts", academicCalendarHandler.PreviewConflicts)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/conflicts/cancel", academicCalendarHandler.CancelConflicts)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/{id}", academicCalendarHandler.UpdateEntry)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/{id}", academicCalendarHandler.DeleteEntry)
+			})
+
+			// Trial request CRM pipeline - admin only (GET + CSRF protected state-changing)
+			r.Route("/admin/trial-requests", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermTrialRequestsManage))
+
+				r.Get("/{id}", trialRequestHandler.GetTrialRequestByID)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/{id}/status", trialRequestHandler.UpdateTrialRequestStatus)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/{id}/assignee", trialRequestHandler.AssignTrialRequest)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/{id}/follow-up", trialRequestHandler.SetTrialRequestFollowUp)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{id}/notes", trialRequestHandler.AddTrialRequestNote)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{id}/convert", trialRequestHandler.ConvertTrialRequest)
+			})
+
+			// Analytics dashboard - admin only (reads rollups, refresh is CSRF protected)
+			r.Route("/admin/analytics", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermAnalyticsRead))
+
+				r.Get("/revenue", analyticsHandler.GetRevenue)
+				r.Get("/credits", analyticsHandler.GetCreditFlow)
+				r.Get("/utilization", analyticsHandler.GetUtilization)
+				r.Get("/bookings", analyticsHandler.GetBookingActivity)
+				r.Get("/retention", analyticsHandler.GetRetention)
+				r.Get("/status", analyticsHandler.GetRefreshState)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/refresh", analyticsHandler.RefreshViews)
+			})
+
+			// Teacher payroll - admin only (rates, period close, adjustments, exports; mutations CSRF protected)
+			r.Route("/admin/payroll", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermPayrollManage))
+
+				r.Get("/rates", payrollHandler.ListRates)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/rates", payrollHandler.CreateRate)
+				r.Get("/preview", payrollHandler.Preview)
+				r.Get("/periods", payrollHandler.ListPeriods)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/periods", payrollHandler.ClosePeriod)
+				r.Get("/periods/{id}", payrollHandler.GetPeriod)
+				r.Get("/periods/{id}/statements/{teacherId}", payrollHandler.GetStatement)
+				r.Get("/periods/{id}/export", payrollHandler.ExportPeriod)
+				r.Get("/adjustments", payrollHandler.ListAdjustments)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/adjustments", payrollHandler.CreateAdjustment)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/adjustments/{id}", payrollHandler.DeleteAdjustment)
+			})
+
+			// Bulk import from CSV/XLSX - admin only (dry run by default; upload CSRF protected)
+			r.Route("/admin/import", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermImportRun))
+
+				r.Get("/runs", importHandler.ListRuns)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{kind}", importHandler.Import)
+			})
+
+			// Audit log - admin only, read-only (events are append-only)
+			r.Route("/admin/audit", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermAuditRead))
+
+				r.Get("/", auditHandler.ListEvents)
+				r.Get("/verify", auditHandler.VerifyChain)
+			})
+
+			// Roles and permissions - custom roles, role -> permission mapping stored in the DB
+			r.Route("/admin/roles", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermRolesManage))
+
+				r.Get("/", permissionHandler.ListRoles)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/", permissionHandler.CreateRole)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/{name}", permissionHandler.UpdateRole)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/{name}", permissionHandler.DeleteRole)
+			})
+			r.With(middleware.RequirePermission(models.PermRolesManage)).Get("/admin/permissions", permissionHandler.ListPermissions)
+
+			// Account erasure requests - admin only (approve anonymizes PII, financial records are kept)
+			r.Route("/admin/erasure-requests", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermErasureReview))
+
+				r.Get("/", accountDataHandler.ListErasureRequests)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{id}/approve", accountDataHandler.ApproveErasure)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{id}/reject", accountDataHandler.RejectErasure)
 			})
 
+			// Groups (cohorts) - admin only (GET + CSRF protected state-changing)
+			r.Route("/admin/groups", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermGroupsManage))
+
+				r.Get("/", groupHandler.ListGroups)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/", groupHandler.CreateGroup)
+				r.Get("/{id}", groupHandler.GetGroup)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/{id}", groupHandler.UpdateGroup)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/{id}", groupHandler.DeleteGroup)
+				r.Get("/{id}/members", groupHandler.ListMembers)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{id}/members", groupHandler.AddMember)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/{id}/members/{studentId}", groupHandler.RemoveMember)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{id}/series", groupHandler.LinkSeries)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/{id}/series/{seriesId}", groupHandler.UnlinkSeries)
+				r.Get("/{id}/history", groupHandler.GetHistory)
+			})
+
+			// Subscriptions and plans - admin only (only if YooKassa is configured)
+			if subscriptionHandler != nil {
+				r.Route("/admin/subscriptions", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(models.PermSubscriptionsManage))
+
+					r.Get("/", subscriptionHandler.AdminListSubscriptions)
+					r.Get("/plans", subscriptionHandler.AdminListPlans)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/plans", subscriptionHandler.CreatePlan)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Put("/plans/{id}", subscriptionHandler.UpdatePlan)
+					r.Get("/{id}/invoices", subscriptionHandler.AdminGetInvoices)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/{id}/cancel", subscriptionHandler.AdminCancelSubscription)
+				})
+			}
+
 			// Payment settings management - admin only (GET + CSRF protected state-changing)
 			r.Group(func(r chi.Router) {
-				r.Use(middleware.RequireAdminOnly)
+				r.Use(middleware.RequirePermission(models.PermPaymentsManage))
 
 				r.Get("/admin/payment-settings", paymentSettingsHandler.ListStudentsPaymentStatus)
 				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/admin/users/{id}/payment-settings", paymentSettingsHandler.UpdatePaymentStatus)
@@ -744,37 +1086,107 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 			r.Route("/chat", func(r chi.Router) {
 				// Get user's chat rooms
 				r.Get("/rooms", chatHandler.GetMyRooms)
+				// Total unread badge (optimized endpoint for sidebar polling)
+				r.Get("/unread", chatHandler.GetUnreadCount)
 				// Create/get room with participant
 				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/rooms", chatHandler.GetOrCreateRoom)
 				// Messages in a room
 				r.Route("/rooms/{roomId}", func(r chi.Router) {
 					// Get messages with pagination
 					r.Get("/messages", chatHandler.GetMessages)
-					// Send message (with optional file attachments, with 5MB body limit)
-					r.With(middleware.BodyLimitMiddlewareWithLimit(middleware.BodyLimitLarge), middleware.CSRFMiddleware(csrfStore)).Post("/messages", chatHandler.SendMessage)
+					// Send message (with optional file attachments, with 5MB body limit, rate limited per user)
+					r.With(middleware.BodyLimitMiddlewareWithLimit(middleware.BodyLimitLarge), middleware.CSRFMiddleware(csrfStore), chatSendRateLimit).Post("/messages", chatHandler.SendMessage)
 					// Download file attachment
 					r.Get("/files/{fileId}", chatHandler.DownloadFile)
+					// Image / PDF first-page preview of an attachment
+					r.Get("/files/{fileId}/thumbnail", chatHandler.DownloadThumbnail)
+					// Read receipts and typing indicator
+					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/read", chatHandler.MarkRead)
+					r.Get("/reads", chatHandler.GetReadStates)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/typing", chatHandler.SendTyping)
+					// Participants of group rooms: roles and mute (room owners and moderators)
+					r.Get("/participants", chatHandler.GetParticipants)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/participants/{userId}/mute", chatHandler.MuteParticipant)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/participants/{userId}/mute", chatHandler.UnmuteParticipant)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Put("/participants/{userId}/role", chatHandler.SetParticipantRole)
+				})
+				// Editing, edit history, replies and reactions of a message
+				r.Route("/messages/{messageId}", func(r chi.Router) {
+					r.With(middleware.CSRFMiddleware(csrfStore)).Put("/", chatHandler.EditMessage)
+					r.Get("/edits", chatHandler.GetMessageEdits)
+					r.Get("/replies", chatHandler.GetReplies)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Post("/reactions", chatHandler.AddReaction)
+					r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/reactions", chatHandler.RemoveReaction)
 				})
+				// Own messages blocked by moderation and appeals
+				r.Get("/moderation/blocked", moderationHandler.ListMyBlocked)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/moderation/blocked/{id}/appeal", moderationHandler.Appeal)
 			})
 
 			// SSE endpoint for real-time chat events (authenticated users)
 			r.Get("/events/chat", sseHandler.HandleChatEvents)
 
+			// Full-text search over own chats, visible homework and lesson reports
+			r.Get("/search", searchHandler.Search)
+
 			// Admin chat routes
 			r.Group(func(r chi.Router) {
-				r.Use(middleware.RequireAdmin)
+				r.Use(middleware.RequirePermission(models.PermChatReadAll))
 				r.Get("/admin/chats", chatHandler.ListAllChats)
 			})
 
+			// Announcement channels and group room membership
+			r.Route("/admin/chat", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermChatChannelsManage))
+				r.Use(middleware.CSRFMiddleware(csrfStore))
+
+				r.Post("/channels", chatHandler.CreateChannel)
+				r.Post("/rooms/{roomId}/participants", chatHandler.AddParticipants)
+				r.Delete("/rooms/{roomId}/participants/{userId}", chatHandler.RemoveParticipant)
+				r.Post("/lesson-groups/{recurringGroupId}/sync", chatHandler.SyncLessonGroup)
+			})
+
+			// Moderation review queue - release or confirm blocks, per-rule statistics
+			r.Route("/admin/moderation", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermModerationReview))
+
+				r.Get("/queue", moderationHandler.ListQueue)
+				r.Get("/stats", moderationHandler.GetStats)
+				r.Get("/blocked/{id}", moderationHandler.GetBlocked)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/blocked/{id}/release", moderationHandler.ReleaseBlocked)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/blocked/{id}/confirm", moderationHandler.ConfirmBlocked)
+
+				// Uploads rejected by the malware scan
+				r.Get("/quarantine", uploadQuarantineHandler.ListQuarantine)
+			})
+
+			// Own storage usage and quota
+			r.Get("/storage/usage", storageHandler.GetMyUsage)
+
+			// Storage usage report, per-user quotas and manual garbage collection
+			r.Route("/admin/storage", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(models.PermStorageManage))
+
+				r.Get("/usage", storageHandler.ListUsage)
+				r.Get("/quotas/{userId}", storageHandler.GetUserQuota)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Put("/quotas/{userId}", storageHandler.SetUserQuota)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Delete("/quotas/{userId}", storageHandler.ResetUserQuota)
+				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/gc", storageHandler.RunGC)
+			})
+
 			// Teacher routes (teacher-only endpoints)
 			r.Route("/teacher", func(r chi.Router) {
-				r.Use(middleware.RequireTeacher)
+				r.Use(middleware.RequirePermission(models.PermTeachingSchedule))
 
 				// Teacher schedule - calendar view with lessons and enrolled students (GET only)
 				r.Get("/schedule", teacherHandler.GetTeacherSchedule)
 
+				// Earnings - current period preview and closed period statements (GET only)
+				r.Get("/earnings", payrollHandler.GetMyEarnings)
+				r.Get("/earnings/{periodId}", payrollHandler.GetMyStatement)
+
 				// Lesson broadcasts - send message to all students in a lesson (CSRF protected)
-				r.With(middleware.CSRFMiddleware(csrfStore)).Post("/lessons/{id}/broadcast", teacherHandler.SendLessonBroadcast)
+				r.With(middleware.RequireOwnership(permissionService, service.ResourceLesson, models.PermLessonsBroadcastOwn, models.PermLessonsBroadcastAll, "id"), middleware.CSRFMiddleware(csrfStore)).Post("/lessons/{id}/broadcast", teacherHandler.SendLessonBroadcast)
 			})
 
 		})
@@ -854,10 +1266,8 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 	cancelSessionCleanup()
 	log.Debug().Msg("  - Session cleanup goroutine cancelled")
 
-	// 2b. Stop rate limiter cleanup goroutines (global)
-	loginRateLimiter.Stop()
-	trialRequestRateLimiter.Stop()
-	paymentRateLimiter.Stop()
+	// 2b. Stop rate limit store cleanup goroutine and close its connections
+	rateLimitStore.Stop()
 	log.Debug().Msg("  - Rate limiter cleanup stopped")
 
 	// 2c. Shutdown Telegram service (if it was initialized)
@@ -868,12 +1278,31 @@ func initializeApp(cfg *config.Config, db *database.DB) error {
 	}
 
 	// 2d. Shutdown Broadcast service (if it was initialized)
-	// This stops the internal rate limiter and cancels all active broadcast goroutines
+	// This stops the scheduler and the internal rate limiter and cancels all active broadcast goroutines
 	if broadcastService != nil {
 		broadcastService.Shutdown()
 		log.Debug().Msg("  - Broadcast service shutdown complete")
 	}
 
+	// 2d2. Stop subscription billing worker (if it was started)
+	// Waits for an in-flight billing cycle to finish before the database is closed
+	if subscriptionService != nil {
+		subscriptionService.Shutdown()
+		log.Debug().Msg("  - Subscription billing worker stopped")
+	}
+
+	// 2d2a. Stop storage garbage collector (cancels an in-flight pass)
+	fileStorageService.Shutdown()
+	log.Debug().Msg("  - Storage garbage collector stopped")
+
+	// 2d3. Stop analytics refresh worker (cancels an in-flight refresh)
+	analyticsService.Shutdown()
+	log.Debug().Msg("  - Analytics refresh worker stopped")
+
+	// 2d4. Stop trial request follow-up reminders (no-op if the worker was not started)
+	trialRequestService.Shutdown()
+	log.Debug().Msg("  - Trial request reminder worker stopped")
+
 	// 2e. Stop Telegram polling if it was started (development mode)
 	// Must be done after TelegramService.Shutdown() to avoid race conditions
 	if telegramClient != nil && cfg.IsDevelopment() {
@@ -14,11 +14,15 @@ import (
 
 // Config содержит всю конфигурацию приложения
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	Session  SessionConfig
-	Telegram TelegramConfig
-	YooKassa YooKassaConfig
+	Database   DatabaseConfig
+	Server     ServerConfig
+	Session    SessionConfig
+	Telegram   TelegramConfig
+	YooKassa   YooKassaConfig
+	Moderation ModerationConfig
+	Storage    StorageConfig
+	UploadScan UploadScanConfig
+	RateLimit  RateLimitConfig
 }
 
 // DatabaseConfig содержит конфигурацию подключения к базе данных
@@ -80,6 +84,91 @@ type YooKassaConfig struct {
 	ReturnURL string
 }
 
+// Бэкенды модерации сообщений чата
+const (
+	ModerationProviderOpenRouter = "openrouter"
+	ModerationProviderRegex      = "regex"
+)
+
+// ModerationConfig содержит конфигурацию модерации сообщений чата
+type ModerationConfig struct {
+	Enabled          bool          // Сообщения проходят модерацию перед доставкой
+	Provider         string        // openrouter или regex (локальная проверка без внешних вызовов)
+	OpenRouterAPIKey string        // Ключ OpenRouter API (обязателен для provider=openrouter)
+	OpenRouterModel  string        // Модель OpenRouter (пусто - модель по умолчанию)
+	Timeout          time.Duration // Таймаут запроса к OpenRouter
+}
+
+// Бэкенды хранилища загруженных файлов
+const (
+	StorageBackendLocal = "local"
+	StorageBackendS3    = "s3"
+)
+
+// StorageConfig содержит конфигурацию хранилища загруженных файлов
+type StorageConfig struct {
+	Backend        string        // local или s3 (пусто - local)
+	LocalDir       string        // Каталог для backend=local
+	S3Endpoint     string        // Например https://storage.yandexcloud.net или http://minio:9000
+	S3Region       string        // Регион для подписи запросов
+	S3Bucket       string        // Бакет для файлов
+	S3AccessKeyID  string        // Ключ доступа
+	S3SecretKey    string        // Секретный ключ
+	S3UsePathStyle bool          // endpoint/bucket/key вместо bucket.endpoint/key (нужно для MinIO)
+	PresignTTL     time.Duration // Срок действия ссылок на скачивание напрямую из S3 (0 - файлы отдаются через API)
+	UserQuotaBytes int64         // Квота пользователя по умолчанию (0 - без ограничения)
+	GCInterval     time.Duration // Период очистки файлов без используемых записей (0 - только ручной запуск)
+	GCGracePeriod  time.Duration // Сколько хранятся файлы удаленных сообщений и занятий до очистки (0 - 72 часа)
+}
+
+// Антивирусные сканеры загружаемых файлов
+const (
+	UploadScannerNone  = "none"
+	UploadScannerClamd = "clamd"
+	UploadScannerStub  = "stub"
+)
+
+// UploadScanConfig содержит конфигурацию антивирусной проверки загружаемых файлов
+type UploadScanConfig struct {
+	Scanner      string        // none, clamd или stub (пусто - none); stub находит только тестовую строку EICAR
+	ClamdAddress string        // Например tcp://clamav:3310 или unix:///run/clamav/clamd.ctl
+	Timeout      time.Duration // Ограничение на проверку одного файла
+}
+
+// Хранилища счетчиков rate limiting
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+	RateLimitStoreRedis    = "redis"
+)
+
+// Поведение группы rate limiting, когда общее хранилище недоступно
+const (
+	RateLimitOnStoreErrorAllow  = "allow"  // пропустить запрос без ограничения
+	RateLimitOnStoreErrorDeny   = "deny"   // отклонить запрос с 503
+	RateLimitOnStoreErrorMemory = "memory" // считать лимит в памяти процесса, пока хранилище недоступно
+)
+
+// RateLimitRule лимит группы маршрутов: не больше Requests запросов за Window (Requests = 0 - без ограничения)
+type RateLimitRule struct {
+	Requests     int
+	Window       time.Duration
+	OnStoreError string // allow, deny или memory (пусто - allow)
+}
+
+// RateLimitConfig содержит конфигурацию ограничения частоты запросов
+type RateLimitConfig struct {
+	Store         string // memory, postgres или redis (пусто - memory); общие хранилища делят лимиты между репликами
+	RedisAddr     string // host:port для store=redis
+	RedisPassword string
+	RedisDB       int
+	Login         RateLimitRule // Вход и регистрация, по IP
+	TrialRequest  RateLimitRule // Заявки на пробный урок, по IP
+	Payment       RateLimitRule // Создание платежей и подписок, по пользователю
+	ChatSend      RateLimitRule // Отправка сообщений в чат, по пользователю
+	Upload        RateLimitRule // Загрузка домашних заданий и рассылок с файлами, по пользователю
+}
+
 // isValidTelegramToken проверяет что токен соответствует формату Telegram бота
 // Telegram токены имеют формат: <bot_id>:<token_string>
 // Пример: 123456789:ABCDEfghijklmnoPQRSTUvwxyz123456789
@@ -338,6 +427,68 @@ func Load() (*Config, error) {
 	// CRITICAL SECURITY: Проверяем потенциальное раскрытие токена
 	checkTelegramTokenExposure(telegramBotToken)
 
+	// Загружаем конфигурацию модерации: без ключа OpenRouter по умолчанию используется regex
+	openRouterAPIKey := getEnv("OPENROUTER_API_KEY", "")
+	defaultModerationProvider := ModerationProviderRegex
+	if openRouterAPIKey != "" {
+		defaultModerationProvider = ModerationProviderOpenRouter
+	}
+	moderationTimeoutSeconds, err := strconv.Atoi(getEnv("MODERATION_TIMEOUT_SECONDS", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("некорректный MODERATION_TIMEOUT_SECONDS: %w", err)
+	}
+
+	// Загружаем конфигурацию хранилища файлов
+	presignTTLSeconds, err := strconv.Atoi(getEnv("STORAGE_PRESIGN_TTL_SECONDS", "300"))
+	if err != nil {
+		return nil, fmt.Errorf("некорректный STORAGE_PRESIGN_TTL_SECONDS: %w", err)
+	}
+
+	userQuotaMB, err := strconv.ParseInt(getEnv("STORAGE_USER_QUOTA_MB", "0"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный STORAGE_USER_QUOTA_MB: %w", err)
+	}
+	gcIntervalHours, err := strconv.Atoi(getEnv("STORAGE_GC_INTERVAL_HOURS", "24"))
+	if err != nil {
+		return nil, fmt.Errorf("некорректный STORAGE_GC_INTERVAL_HOURS: %w", err)
+	}
+	gcGraceHours, err := strconv.Atoi(getEnv("STORAGE_GC_GRACE_HOURS", "72"))
+	if err != nil {
+		return nil, fmt.Errorf("некорректный STORAGE_GC_GRACE_HOURS: %w", err)
+	}
+
+	// Загружаем конфигурацию антивирусной проверки
+	uploadScanTimeoutSeconds, err := strconv.Atoi(getEnv("UPLOAD_SCAN_TIMEOUT_SECONDS", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("некорректный UPLOAD_SCAN_TIMEOUT_SECONDS: %w", err)
+	}
+
+	// Загружаем конфигурацию rate limiting
+	redisDB, err := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("некорректный REDIS_DB: %w", err)
+	}
+	loginLimit, err := loadRateLimitRule("LOGIN", 10, 60, RateLimitOnStoreErrorMemory)
+	if err != nil {
+		return nil, err
+	}
+	trialRequestLimit, err := loadRateLimitRule("TRIAL_REQUEST", 5, 600, RateLimitOnStoreErrorMemory)
+	if err != nil {
+		return nil, err
+	}
+	paymentLimit, err := loadRateLimitRule("PAYMENT", 10, 60, RateLimitOnStoreErrorAllow)
+	if err != nil {
+		return nil, err
+	}
+	chatSendLimit, err := loadRateLimitRule("CHAT_SEND", 30, 60, RateLimitOnStoreErrorAllow)
+	if err != nil {
+		return nil, err
+	}
+	uploadLimit, err := loadRateLimitRule("UPLOAD", 20, 600, RateLimitOnStoreErrorAllow)
+	if err != nil {
+		return nil, err
+	}
+
 	// Determine default SameSite based on environment
 	defaultSameSite := "Lax"
 	if isProduction {
@@ -393,6 +544,43 @@ func Load() (*Config, error) {
 			SecretKey: getEnv("YOOKASSA_SECRET_KEY", ""),
 			ReturnURL: getEnv("YOOKASSA_RETURN_URL", "http://localhost:5173/payment-success"),
 		},
+		Moderation: ModerationConfig{
+			Enabled:          getEnv("MODERATION_ENABLED", "false") == "true",
+			Provider:         getEnv("MODERATION_PROVIDER", defaultModerationProvider),
+			OpenRouterAPIKey: openRouterAPIKey,
+			OpenRouterModel:  getEnv("OPENROUTER_MODEL", ""),
+			Timeout:          time.Duration(moderationTimeoutSeconds) * time.Second,
+		},
+		Storage: StorageConfig{
+			Backend:        getEnv("STORAGE_BACKEND", StorageBackendLocal),
+			LocalDir:       getEnv("STORAGE_LOCAL_DIR", "./uploads/blobs"),
+			S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+			S3Region:       getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:       getEnv("S3_BUCKET", ""),
+			S3AccessKeyID:  getEnv("S3_ACCESS_KEY_ID", ""),
+			S3SecretKey:    getEnv("S3_SECRET_ACCESS_KEY", ""),
+			S3UsePathStyle: getEnv("S3_USE_PATH_STYLE", "false") == "true",
+			PresignTTL:     time.Duration(presignTTLSeconds) * time.Second,
+			UserQuotaBytes: userQuotaMB * 1024 * 1024,
+			GCInterval:     time.Duration(gcIntervalHours) * time.Hour,
+			GCGracePeriod:  time.Duration(gcGraceHours) * time.Hour,
+		},
+		UploadScan: UploadScanConfig{
+			Scanner:      getEnv("UPLOAD_SCANNER", UploadScannerNone),
+			ClamdAddress: getEnv("CLAMD_ADDRESS", ""),
+			Timeout:      time.Duration(uploadScanTimeoutSeconds) * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Store:         getEnv("RATE_LIMIT_STORE", RateLimitStoreMemory),
+			RedisAddr:     getEnv("REDIS_HOST", "localhost") + ":" + getEnv("REDIS_PORT", "6379"),
+			RedisPassword: getEnv("REDIS_PASSWORD", ""),
+			RedisDB:       redisDB,
+			Login:         loginLimit,
+			TrialRequest:  trialRequestLimit,
+			Payment:       paymentLimit,
+			ChatSend:      chatSendLimit,
+			Upload:        uploadLimit,
+		},
 	}
 
 	// Валидируем конфигурацию
@@ -479,9 +667,124 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Валидируем конфигурацию модерации (если включена)
+	if c.Moderation.Enabled {
+		switch c.Moderation.Provider {
+		case ModerationProviderRegex:
+		case ModerationProviderOpenRouter:
+			if c.Moderation.OpenRouterAPIKey == "" {
+				return fmt.Errorf("OPENROUTER_API_KEY обязателен при MODERATION_PROVIDER=openrouter")
+			}
+		default:
+			return fmt.Errorf("MODERATION_PROVIDER должен быть openrouter или regex (текущее значение: %s)", c.Moderation.Provider)
+		}
+		if c.Moderation.Timeout <= 0 {
+			return fmt.Errorf("MODERATION_TIMEOUT_SECONDS должен быть больше 0")
+		}
+	}
+
+	// Валидируем конфигурацию хранилища файлов
+	switch c.Storage.Backend {
+	case "", StorageBackendLocal:
+	case StorageBackendS3:
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" || c.Storage.S3Region == "" {
+			return fmt.Errorf("S3_ENDPOINT, S3_BUCKET и S3_REGION обязательны при STORAGE_BACKEND=s3")
+		}
+		if c.Storage.S3AccessKeyID == "" || c.Storage.S3SecretKey == "" {
+			return fmt.Errorf("S3_ACCESS_KEY_ID и S3_SECRET_ACCESS_KEY обязательны при STORAGE_BACKEND=s3")
+		}
+	default:
+		return fmt.Errorf("STORAGE_BACKEND должен быть local или s3 (текущее значение: %s)", c.Storage.Backend)
+	}
+	if c.Storage.PresignTTL < 0 || c.Storage.PresignTTL > 7*24*time.Hour {
+		return fmt.Errorf("STORAGE_PRESIGN_TTL_SECONDS должен быть от 0 до 604800")
+	}
+	if c.Storage.UserQuotaBytes < 0 {
+		return fmt.Errorf("STORAGE_USER_QUOTA_MB не может быть отрицательным")
+	}
+	if c.Storage.GCInterval < 0 {
+		return fmt.Errorf("STORAGE_GC_INTERVAL_HOURS не может быть отрицательным")
+	}
+	if c.Storage.GCGracePeriod < 0 {
+		return fmt.Errorf("STORAGE_GC_GRACE_HOURS не может быть отрицательным")
+	}
+
+	// Валидируем конфигурацию антивирусной проверки
+	switch c.UploadScan.Scanner {
+	case "", UploadScannerNone:
+	case UploadScannerClamd:
+		if c.UploadScan.ClamdAddress == "" {
+			return fmt.Errorf("CLAMD_ADDRESS обязателен при UPLOAD_SCANNER=clamd")
+		}
+		if c.UploadScan.Timeout <= 0 {
+			return fmt.Errorf("UPLOAD_SCAN_TIMEOUT_SECONDS должен быть больше 0")
+		}
+	case UploadScannerStub:
+		if c.IsProduction() {
+			return fmt.Errorf("UPLOAD_SCANNER=stub не допускается в production")
+		}
+	default:
+		return fmt.Errorf("UPLOAD_SCANNER должен быть none, clamd или stub (текущее значение: %s)", c.UploadScan.Scanner)
+	}
+
+	// Валидируем конфигурацию rate limiting
+	switch c.RateLimit.Store {
+	case "", RateLimitStoreMemory, RateLimitStorePostgres:
+	case RateLimitStoreRedis:
+		if c.RateLimit.RedisAddr == "" {
+			return fmt.Errorf("REDIS_HOST и REDIS_PORT обязательны при RATE_LIMIT_STORE=redis")
+		}
+		if c.RateLimit.RedisDB < 0 {
+			return fmt.Errorf("REDIS_DB не может быть отрицательным")
+		}
+	default:
+		return fmt.Errorf("RATE_LIMIT_STORE должен быть memory, postgres или redis (текущее значение: %s)", c.RateLimit.Store)
+	}
+	rules := []struct {
+		name string
+		rule RateLimitRule
+	}{
+		{"LOGIN", c.RateLimit.Login},
+		{"TRIAL_REQUEST", c.RateLimit.TrialRequest},
+		{"PAYMENT", c.RateLimit.Payment},
+		{"CHAT_SEND", c.RateLimit.ChatSend},
+		{"UPLOAD", c.RateLimit.Upload},
+	}
+	for _, r := range rules {
+		if r.rule.Requests < 0 {
+			return fmt.Errorf("RATE_LIMIT_%s_REQUESTS не может быть отрицательным", r.name)
+		}
+		if r.rule.Requests > 0 && r.rule.Window <= 0 {
+			return fmt.Errorf("RATE_LIMIT_%s_WINDOW_SECONDS должен быть больше 0", r.name)
+		}
+		switch r.rule.OnStoreError {
+		case "", RateLimitOnStoreErrorAllow, RateLimitOnStoreErrorDeny, RateLimitOnStoreErrorMemory:
+		default:
+			return fmt.Errorf("RATE_LIMIT_%s_ON_STORE_ERROR должен быть allow, deny или memory (текущее значение: %s)", r.name, r.rule.OnStoreError)
+		}
+	}
+
 	return nil
 }
 
+// loadRateLimitRule загружает лимит группы маршрутов из RATE_LIMIT_<name>_REQUESTS, RATE_LIMIT_<name>_WINDOW_SECONDS
+// и RATE_LIMIT_<name>_ON_STORE_ERROR
+func loadRateLimitRule(name string, defaultRequests, defaultWindowSeconds int, defaultOnStoreError string) (RateLimitRule, error) {
+	requests, err := strconv.Atoi(getEnv("RATE_LIMIT_"+name+"_REQUESTS", strconv.Itoa(defaultRequests)))
+	if err != nil {
+		return RateLimitRule{}, fmt.Errorf("некорректный RATE_LIMIT_%s_REQUESTS: %w", name, err)
+	}
+	windowSeconds, err := strconv.Atoi(getEnv("RATE_LIMIT_"+name+"_WINDOW_SECONDS", strconv.Itoa(defaultWindowSeconds)))
+	if err != nil {
+		return RateLimitRule{}, fmt.Errorf("некорректный RATE_LIMIT_%s_WINDOW_SECONDS: %w", name, err)
+	}
+	return RateLimitRule{
+		Requests:     requests,
+		Window:       time.Duration(windowSeconds) * time.Second,
+		OnStoreError: getEnv("RATE_LIMIT_"+name+"_ON_STORE_ERROR", defaultOnStoreError),
+	}, nil
+}
+
 // GetDSN возвращает строку подключения PostgreSQL
 func (c *DatabaseConfig) GetDSN() string {
 	// Строим DSN, пропуская пароль если он пустой (для peer auth)
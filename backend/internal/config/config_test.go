@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestIsValidTelegramToken проверяет валидацию формата Telegram токена
@@ -1133,3 +1134,225 @@ func TestSessionSecretValidation_AllCriteria(t *testing.T) {
 		})
 	}
 }
+
+// TestValidate_ModerationConfig проверяет валидацию бэкенда модерации
+func TestValidate_ModerationConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		moderation ModerationConfig
+		errMsg     string
+	}{
+		{
+			name:       "disabled_without_key",
+			moderation: ModerationConfig{Provider: ModerationProviderOpenRouter},
+		},
+		{
+			name:       "regex_without_key",
+			moderation: ModerationConfig{Enabled: true, Provider: ModerationProviderRegex, Timeout: 30 * time.Second},
+		},
+		{
+			name:       "openrouter_with_key",
+			moderation: ModerationConfig{Enabled: true, Provider: ModerationProviderOpenRouter, OpenRouterAPIKey: "sk-or-test", Timeout: 30 * time.Second},
+		},
+		{
+			name:       "openrouter_without_key",
+			moderation: ModerationConfig{Enabled: true, Provider: ModerationProviderOpenRouter, Timeout: 30 * time.Second},
+			errMsg:     "OPENROUTER_API_KEY",
+		},
+		{
+			name:       "unknown_provider",
+			moderation: ModerationConfig{Enabled: true, Provider: "openai", Timeout: 30 * time.Second},
+			errMsg:     "MODERATION_PROVIDER",
+		},
+		{
+			name:       "zero_timeout",
+			moderation: ModerationConfig{Enabled: true, Provider: ModerationProviderRegex},
+			errMsg:     "MODERATION_TIMEOUT_SECONDS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:     ServerConfig{Env: "development", Port: "8080"},
+				Database:   DatabaseConfig{Host: "localhost", Port: 5432, Name: "db", User: "user"},
+				Session:    SessionConfig{Secret: "secret_key_at_least_32_characters_long", MaxAge: time.Hour},
+				Moderation: tt.moderation,
+			}
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+// TestValidate_StorageConfig проверяет валидацию хранилища файлов
+func TestValidate_StorageConfig(t *testing.T) {
+	s3 := StorageConfig{
+		Backend:       StorageBackendS3,
+		S3Endpoint:    "http://localhost:9000",
+		S3Region:      "us-east-1",
+		S3Bucket:      "uploads",
+		S3AccessKeyID: "minio",
+		S3SecretKey:   "minio-secret",
+		PresignTTL:    5 * time.Minute,
+	}
+	s3WithoutBucket := s3
+	s3WithoutBucket.S3Bucket = ""
+	s3WithoutSecret := s3
+	s3WithoutSecret.S3SecretKey = ""
+
+	tests := []struct {
+		name    string
+		storage StorageConfig
+		errMsg  string
+	}{
+		{name: "default_local", storage: StorageConfig{}},
+		{name: "local", storage: StorageConfig{Backend: StorageBackendLocal, LocalDir: "./uploads/blobs"}},
+		{name: "s3", storage: s3},
+		{name: "s3_without_bucket", storage: s3WithoutBucket, errMsg: "S3_BUCKET"},
+		{name: "s3_without_secret", storage: s3WithoutSecret, errMsg: "S3_SECRET_ACCESS_KEY"},
+		{name: "unknown_backend", storage: StorageConfig{Backend: "gcs"}, errMsg: "STORAGE_BACKEND"},
+		{name: "presign_ttl_too_long", storage: StorageConfig{PresignTTL: 8 * 24 * time.Hour}, errMsg: "STORAGE_PRESIGN_TTL_SECONDS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:   ServerConfig{Env: "development", Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost", Port: 5432, Name: "db", User: "user"},
+				Session:  SessionConfig{Secret: "secret_key_at_least_32_characters_long", MaxAge: time.Hour},
+				Storage:  tt.storage,
+			}
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+// TestValidate_UploadScanConfig проверяет валидацию антивирусной проверки загрузок
+func TestValidate_UploadScanConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		uploadScan UploadScanConfig
+		errMsg     string
+	}{
+		{name: "default_none", env: "development", uploadScan: UploadScanConfig{}},
+		{name: "clamd", env: "development", uploadScan: UploadScanConfig{Scanner: UploadScannerClamd, ClamdAddress: "tcp://clamav:3310", Timeout: 30 * time.Second}},
+		{name: "clamd_without_address", env: "development", uploadScan: UploadScanConfig{Scanner: UploadScannerClamd, Timeout: 30 * time.Second}, errMsg: "CLAMD_ADDRESS"},
+		{name: "clamd_without_timeout", env: "development", uploadScan: UploadScanConfig{Scanner: UploadScannerClamd, ClamdAddress: "tcp://clamav:3310"}, errMsg: "UPLOAD_SCAN_TIMEOUT_SECONDS"},
+		{name: "stub_in_development", env: "development", uploadScan: UploadScanConfig{Scanner: UploadScannerStub}},
+		{name: "stub_in_production", env: "production", uploadScan: UploadScanConfig{Scanner: UploadScannerStub}, errMsg: "UPLOAD_SCANNER=stub"},
+		{name: "unknown_scanner", env: "development", uploadScan: UploadScanConfig{Scanner: "sophos"}, errMsg: "UPLOAD_SCANNER"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:     ServerConfig{Env: tt.env, Port: "8080", ProductionDomain: "example.com"},
+				Database:   DatabaseConfig{Host: "localhost", Port: 5432, Name: "db", User: "user", Password: "password"},
+				Session:    SessionConfig{Secret: "secret_key_at_least_32_characters_long", MaxAge: time.Hour, Secure: true},
+				UploadScan: tt.uploadScan,
+			}
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_StorageMaintenanceConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage StorageConfig
+		errMsg  string
+	}{
+		{name: "defaults", storage: StorageConfig{}},
+		{name: "quota_and_gc", storage: StorageConfig{UserQuotaBytes: 500 << 20, GCInterval: 24 * time.Hour, GCGracePeriod: 72 * time.Hour}},
+		{name: "negative_quota", storage: StorageConfig{UserQuotaBytes: -1}, errMsg: "STORAGE_USER_QUOTA_MB"},
+		{name: "negative_gc_interval", storage: StorageConfig{GCInterval: -time.Hour}, errMsg: "STORAGE_GC_INTERVAL_HOURS"},
+		{name: "negative_gc_grace", storage: StorageConfig{GCGracePeriod: -time.Hour}, errMsg: "STORAGE_GC_GRACE_HOURS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:   ServerConfig{Env: "development", Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost", Port: 5432, Name: "db", User: "user", Password: "password"},
+				Session:  SessionConfig{Secret: "secret_key_at_least_32_characters_long", MaxAge: time.Hour},
+				Storage:  tt.storage,
+			}
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_RateLimitConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		rateLimit RateLimitConfig
+		errMsg    string
+	}{
+		{name: "defaults", rateLimit: RateLimitConfig{}},
+		{name: "redis", rateLimit: RateLimitConfig{Store: RateLimitStoreRedis, RedisAddr: "localhost:6379", Login: RateLimitRule{Requests: 10, Window: time.Minute}}},
+		{name: "unknown_store", rateLimit: RateLimitConfig{Store: "memcached"}, errMsg: "RATE_LIMIT_STORE"},
+		{name: "redis_without_address", rateLimit: RateLimitConfig{Store: RateLimitStoreRedis}, errMsg: "REDIS_HOST"},
+		{name: "negative_requests", rateLimit: RateLimitConfig{ChatSend: RateLimitRule{Requests: -1}}, errMsg: "RATE_LIMIT_CHAT_SEND_REQUESTS"},
+		{name: "missing_window", rateLimit: RateLimitConfig{Upload: RateLimitRule{Requests: 5}}, errMsg: "RATE_LIMIT_UPLOAD_WINDOW_SECONDS"},
+		{name: "login_fallback_memory", rateLimit: RateLimitConfig{Login: RateLimitRule{Requests: 10, Window: time.Minute, OnStoreError: RateLimitOnStoreErrorMemory}}},
+		{name: "unknown_on_store_error", rateLimit: RateLimitConfig{Login: RateLimitRule{Requests: 10, Window: time.Minute, OnStoreError: "ignore"}}, errMsg: "RATE_LIMIT_LOGIN_ON_STORE_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:    ServerConfig{Env: "development", Port: "8080"},
+				Database:  DatabaseConfig{Host: "localhost", Port: 5432, Name: "db", User: "user", Password: "password"},
+				Session:   SessionConfig{Secret: "secret_key_at_least_32_characters_long", MaxAge: time.Hour},
+				RateLimit: tt.rateLimit,
+			}
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+-- 061_academic_calendar.sql
+-- Purpose: Admin-managed academic calendar (terms, holidays, breaks)
+-- Holidays and breaks block generation of recurring lessons on covered dates
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS academic_calendar_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entry_type VARCHAR(20) NOT NULL,
+    name VARCHAR(200) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual',
+    external_uid VARCHAR(255),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT academic_calendar_entry_type_check CHECK (entry_type IN ('term', 'holiday', 'break')),
+    CONSTRAINT academic_calendar_source_check CHECK (source IN ('manual', 'ics')),
+    CONSTRAINT academic_calendar_dates_check CHECK (end_date >= start_date)
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_academic_calendar_dates
+    ON academic_calendar_entries(start_date, end_date)
+    WHERE deleted_at IS NULL;
+
+-- ICS import is idempotent by UID: re-importing the same file updates entries instead of duplicating them
+CREATE UNIQUE INDEX IF NOT EXISTS idx_academic_calendar_external_uid
+    ON academic_calendar_entries(external_uid)
+    WHERE external_uid IS NOT NULL AND deleted_at IS NULL;
+
+-- ============================================================================
+-- TRIGGERS
+-- ============================================================================
+
+DROP TRIGGER IF EXISTS update_academic_calendar_entries_updated_at ON academic_calendar_entries;
+CREATE TRIGGER update_academic_calendar_entries_updated_at
+    BEFORE UPDATE ON academic_calendar_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE academic_calendar_entries IS 'Academic calendar: terms, public holidays and school breaks';
+COMMENT ON COLUMN academic_calendar_entries.entry_type IS 'term (informational), holiday or break (block lesson generation)';
+COMMENT ON COLUMN academic_calendar_entries.start_date IS 'First covered date (inclusive)';
+COMMENT ON COLUMN academic_calendar_entries.end_date IS 'Last covered date (inclusive)';
+COMMENT ON COLUMN academic_calendar_entries.source IS 'manual (created in admin panel) or ics (imported from ICS file)';
+COMMENT ON COLUMN academic_calendar_entries.external_uid IS 'UID of the VEVENT for ICS-imported entries';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TRIGGER IF EXISTS update_academic_calendar_entries_updated_at ON academic_calendar_entries;
+DROP INDEX IF EXISTS idx_academic_calendar_external_uid;
+DROP INDEX IF EXISTS idx_academic_calendar_dates;
+DROP TABLE IF EXISTS academic_calendar_entries;
+COMMIT;
+*/
@@ -0,0 +1,74 @@
+-- 062_lesson_modification_snapshots.sql
+-- Purpose: Make bulk lesson modifications revertible
+-- 1. Restore lesson_modifications audit table (dropped together with templates in 058,
+--    but still written by BulkEditService) in the shape used by LessonModificationRepository
+-- 2. Store before/after snapshots per affected lesson, including credit movements,
+--    so POST /lesson-modifications/{id}/revert can restore lessons atomically
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS lesson_modifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    original_lesson_id UUID NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    modification_type VARCHAR(50) NOT NULL,
+    applied_by_id UUID NOT NULL REFERENCES users(id),
+    applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    affected_lessons_count INTEGER NOT NULL DEFAULT 0,
+    changes_json JSONB NOT NULL DEFAULT '{}'::jsonb,
+    notes TEXT NOT NULL DEFAULT '',
+    CONSTRAINT lesson_modifications_type_check CHECK (
+        modification_type IN ('add_student', 'remove_student', 'change_teacher', 'change_time', 'change_capacity')
+    )
+);
+
+ALTER TABLE lesson_modifications ADD COLUMN IF NOT EXISTS reverted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE lesson_modifications ADD COLUMN IF NOT EXISTS reverted_by_id UUID REFERENCES users(id);
+
+CREATE TABLE IF NOT EXISTS lesson_modification_snapshots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    modification_id UUID NOT NULL REFERENCES lesson_modifications(id) ON DELETE CASCADE,
+    lesson_id UUID NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    before_state JSONB NOT NULL,
+    after_state JSONB NOT NULL,
+    credit_movements JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT lesson_modification_snapshots_unique UNIQUE (modification_id, lesson_id)
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_lesson_modifications_original_lesson
+    ON lesson_modifications(original_lesson_id, applied_at DESC);
+CREATE INDEX IF NOT EXISTS idx_lesson_modifications_applied_by
+    ON lesson_modifications(applied_by_id, applied_at DESC);
+CREATE INDEX IF NOT EXISTS idx_lesson_modifications_applied_at
+    ON lesson_modifications(applied_at DESC);
+CREATE INDEX IF NOT EXISTS idx_lesson_modification_snapshots_lesson
+    ON lesson_modification_snapshots(lesson_id);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE lesson_modifications IS 'Audit trail of bulk lesson modifications (apply-to-all)';
+COMMENT ON COLUMN lesson_modifications.reverted_at IS 'When the modification was reverted (NULL = active)';
+COMMENT ON COLUMN lesson_modifications.reverted_by_id IS 'Admin who reverted the modification';
+COMMENT ON TABLE lesson_modification_snapshots IS 'Per-lesson before/after state of a bulk modification, used for revert';
+COMMENT ON COLUMN lesson_modification_snapshots.before_state IS 'Lesson state before modification: teacher, time, capacity, booked students';
+COMMENT ON COLUMN lesson_modification_snapshots.after_state IS 'Lesson state right after modification; revert is refused if the lesson no longer matches it';
+COMMENT ON COLUMN lesson_modification_snapshots.credit_movements IS 'Credit changes per student caused by the modification (negative = deducted)';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TABLE IF EXISTS lesson_modification_snapshots;
+ALTER TABLE lesson_modifications DROP COLUMN IF EXISTS reverted_by_id;
+ALTER TABLE lesson_modifications DROP COLUMN IF EXISTS reverted_at;
+COMMIT;
+*/
@@ -0,0 +1,103 @@
+-- 063_student_groups.sql
+-- Purpose: Group classes / cohorts as first-class entities
+-- 1. student_groups: named cohort with a teacher and an optional subject
+-- 2. student_group_series: recurring series (lessons.recurring_group_id) taught to the group
+-- 3. student_group_members: membership periods (left_at IS NULL = active member)
+-- 4. student_group_membership_events: append-only audit of membership changes
+--    with the number of lessons enrolled/unenrolled and credits moved
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS student_groups (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    teacher_id UUID NOT NULL REFERENCES users(id),
+    subject_id UUID REFERENCES subjects(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS student_group_series (
+    group_id UUID NOT NULL REFERENCES student_groups(id) ON DELETE CASCADE,
+    recurring_group_id UUID NOT NULL,
+    linked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    linked_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, recurring_group_id)
+);
+
+CREATE TABLE IF NOT EXISTS student_group_members (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    group_id UUID NOT NULL REFERENCES student_groups(id) ON DELETE CASCADE,
+    student_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    left_at TIMESTAMP WITH TIME ZONE,
+    added_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    removed_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS student_group_membership_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    group_id UUID NOT NULL REFERENCES student_groups(id) ON DELETE CASCADE,
+    student_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    lessons_affected INTEGER NOT NULL DEFAULT 0,
+    credits_delta INTEGER NOT NULL DEFAULT 0,
+    performed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT student_group_membership_events_action_check CHECK (
+        action IN ('added', 'removed', 'enrolled', 'unenrolled')
+    )
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_student_groups_teacher
+    ON student_groups(teacher_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_student_group_series_recurring
+    ON student_group_series(recurring_group_id);
+-- Студент может быть активным участником группы только один раз
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_group_members_active_unique
+    ON student_group_members(group_id, student_id) WHERE left_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_student_group_members_student
+    ON student_group_members(student_id) WHERE left_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_student_group_membership_events_group
+    ON student_group_membership_events(group_id, created_at DESC);
+
+-- ============================================================================
+-- TRIGGERS
+-- ============================================================================
+
+DROP TRIGGER IF EXISTS update_student_groups_updated_at ON student_groups;
+CREATE TRIGGER update_student_groups_updated_at
+    BEFORE UPDATE ON student_groups
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE student_groups IS 'Named cohorts of students taught by one teacher';
+COMMENT ON TABLE student_group_series IS 'Recurring lesson series (lessons.recurring_group_id) linked to a group; members are enrolled in their future lessons';
+COMMENT ON TABLE student_group_members IS 'Group membership periods; left_at IS NULL means active member';
+COMMENT ON TABLE student_group_membership_events IS 'Audit log of membership changes: added/removed members, enrolled/unenrolled on series link changes';
+COMMENT ON COLUMN student_group_membership_events.credits_delta IS 'Credits moved for the student (negative = deducted, positive = refunded)';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TABLE IF EXISTS student_group_membership_events;
+DROP TABLE IF EXISTS student_group_members;
+DROP TABLE IF EXISTS student_group_series;
+DROP TABLE IF EXISTS student_groups;
+COMMIT;
+*/
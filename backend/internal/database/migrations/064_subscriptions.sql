@@ -0,0 +1,129 @@
+-- 064_subscriptions.sql
+-- Purpose: Monthly subscription plans that auto-grant credits
+-- 1. subscription_plans: credits granted per billing period and price
+-- 2. subscriptions: one live subscription per user, billed via a saved YooKassa payment method
+--    (save_payment_method on the first payment, payment_method_id for recurring charges)
+-- 3. subscription_invoices: one row per charge attempt (initial, renewal, proration);
+--    credits are granted exactly once per succeeded invoice (processed_at)
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS subscription_plans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    credits_per_period INTEGER NOT NULL CHECK (credits_per_period > 0 AND credits_per_period <= 100),
+    price DECIMAL(10, 2) NOT NULL CHECK (price > 0),
+    period_months INTEGER NOT NULL DEFAULT 1 CHECK (period_months BETWEEN 1 AND 12),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_id UUID NOT NULL REFERENCES subscription_plans(id),
+    pending_plan_id UUID REFERENCES subscription_plans(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    payment_method_id VARCHAR(255),
+    current_period_start TIMESTAMP WITH TIME ZONE,
+    current_period_end TIMESTAMP WITH TIME ZONE,
+    next_billing_at TIMESTAMP WITH TIME ZONE,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    last_failure_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT subscriptions_status_check CHECK (
+        status IN ('pending', 'active', 'past_due', 'cancelled', 'expired')
+    )
+);
+
+CREATE TABLE IF NOT EXISTS subscription_invoices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_id UUID NOT NULL REFERENCES subscription_plans(id),
+    kind VARCHAR(20) NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    credits INTEGER NOT NULL CHECK (credits >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempt INTEGER NOT NULL DEFAULT 1,
+    yookassa_payment_id VARCHAR(255) UNIQUE,
+    confirmation_url TEXT,
+    failure_reason TEXT NOT NULL DEFAULT '',
+    processed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT subscription_invoices_kind_check CHECK (kind IN ('initial', 'renewal', 'proration')),
+    CONSTRAINT subscription_invoices_status_check CHECK (status IN ('pending', 'succeeded', 'failed'))
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- У пользователя может быть только одна незавершённая подписка
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_user_live
+    ON subscriptions(user_id) WHERE status IN ('pending', 'active', 'past_due');
+-- Поиск подписок к списанию фоновым биллингом
+CREATE INDEX IF NOT EXISTS idx_subscriptions_due
+    ON subscriptions(next_billing_at) WHERE status IN ('active', 'past_due');
+CREATE INDEX IF NOT EXISTS idx_subscription_invoices_subscription
+    ON subscription_invoices(subscription_id, created_at DESC);
+-- Не более одного незавершённого счёта на подписку
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscription_invoices_one_pending
+    ON subscription_invoices(subscription_id) WHERE status = 'pending';
+
+-- ============================================================================
+-- TRIGGERS
+-- ============================================================================
+
+DROP TRIGGER IF EXISTS update_subscription_plans_updated_at ON subscription_plans;
+CREATE TRIGGER update_subscription_plans_updated_at
+    BEFORE UPDATE ON subscription_plans
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_subscriptions_updated_at ON subscriptions;
+CREATE TRIGGER update_subscriptions_updated_at
+    BEFORE UPDATE ON subscriptions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_subscription_invoices_updated_at ON subscription_invoices;
+CREATE TRIGGER update_subscription_invoices_updated_at
+    BEFORE UPDATE ON subscription_invoices
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE subscription_plans IS 'Subscription plans: credits granted every period_months for price (RUB)';
+COMMENT ON TABLE subscriptions IS 'User subscriptions billed via a saved YooKassa payment method';
+COMMENT ON COLUMN subscriptions.pending_plan_id IS 'Plan that takes effect from the next period (downgrade)';
+COMMENT ON COLUMN subscriptions.status IS 'pending: waiting for first payment, active, past_due: renewal failed (dunning), cancelled, expired: dunning exhausted';
+COMMENT ON COLUMN subscriptions.payment_method_id IS 'YooKassa saved payment method used for recurring charges';
+COMMENT ON COLUMN subscriptions.failed_attempts IS 'Consecutive failed renewal attempts in the current dunning cycle';
+COMMENT ON TABLE subscription_invoices IS 'Charge attempts for subscriptions; credits are granted once per succeeded invoice';
+COMMENT ON COLUMN subscription_invoices.kind IS 'initial: first payment with save_payment_method, renewal: recurring charge, proration: mid-period upgrade';
+COMMENT ON COLUMN subscription_invoices.processed_at IS 'When credits for the invoice were granted (idempotency guard)';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TABLE IF EXISTS subscription_invoices;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS subscription_plans;
+COMMIT;
+*/
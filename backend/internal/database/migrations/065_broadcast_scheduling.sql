@@ -0,0 +1,102 @@
+-- 065_broadcast_scheduling.sql
+-- Purpose: Scheduled broadcasts, message templates and dynamic audiences
+-- 1. broadcast_templates: reusable message bodies with per-recipient placeholders
+--    ({first_name}, {last_name}, {balance}, {next_lesson})
+-- 2. broadcast_audiences: saved filters (role, subject, teacher, low balance, inactivity)
+--    resolved into recipients at send time instead of frozen user_ids arrays
+-- 3. broadcasts: scheduled_at + 'scheduled' status picked up by the scheduler worker,
+--    template_id / audience_id record where the broadcast came from
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS broadcast_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL CHECK (char_length(body) > 0 AND char_length(body) <= 4096),
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS broadcast_audiences (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    filter JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+ALTER TABLE broadcasts
+    ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS template_id UUID REFERENCES broadcast_templates(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS audience_id UUID REFERENCES broadcast_audiences(id) ON DELETE SET NULL;
+
+ALTER TABLE broadcasts DROP CONSTRAINT IF EXISTS broadcasts_status_check;
+ALTER TABLE broadcasts ADD CONSTRAINT broadcasts_status_check CHECK (
+    status IN ('scheduled', 'pending', 'in_progress', 'completed', 'failed', 'cancelled')
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Поиск отложенных рассылок, время которых наступило (scheduler worker)
+CREATE INDEX IF NOT EXISTS idx_broadcasts_scheduled
+    ON broadcasts(scheduled_at) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_broadcast_templates_active
+    ON broadcast_templates(name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_broadcast_audiences_active
+    ON broadcast_audiences(name) WHERE deleted_at IS NULL;
+
+-- ============================================================================
+-- TRIGGERS
+-- ============================================================================
+
+DROP TRIGGER IF EXISTS update_broadcast_templates_updated_at ON broadcast_templates;
+CREATE TRIGGER update_broadcast_templates_updated_at
+    BEFORE UPDATE ON broadcast_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_broadcast_audiences_updated_at ON broadcast_audiences;
+CREATE TRIGGER update_broadcast_audiences_updated_at
+    BEFORE UPDATE ON broadcast_audiences
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE broadcast_templates IS 'Reusable broadcast message bodies with per-recipient placeholders';
+COMMENT ON COLUMN broadcast_templates.body IS 'Message text; {first_name}, {last_name}, {balance}, {next_lesson} are rendered per recipient';
+COMMENT ON TABLE broadcast_audiences IS 'Saved recipient filters resolved at send time';
+COMMENT ON COLUMN broadcast_audiences.filter IS 'JSON filter: roles, subject_ids, teacher_ids, max_balance, inactive_days (all conditions are ANDed)';
+COMMENT ON COLUMN broadcasts.scheduled_at IS 'When a scheduled broadcast should be sent (status = scheduled until then)';
+COMMENT ON COLUMN broadcasts.template_id IS 'Template the message was taken from (body is copied into message)';
+COMMENT ON COLUMN broadcasts.audience_id IS 'Saved audience resolved into recipients when sending starts';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DELETE FROM broadcasts WHERE status = 'scheduled';
+ALTER TABLE broadcasts DROP CONSTRAINT IF EXISTS broadcasts_status_check;
+ALTER TABLE broadcasts ADD CONSTRAINT broadcasts_status_check CHECK (
+    status IN ('pending', 'in_progress', 'completed', 'failed', 'cancelled')
+);
+ALTER TABLE broadcasts
+    DROP COLUMN IF EXISTS audience_id,
+    DROP COLUMN IF EXISTS template_id,
+    DROP COLUMN IF EXISTS scheduled_at;
+DROP TABLE IF EXISTS broadcast_audiences;
+DROP TABLE IF EXISTS broadcast_templates;
+COMMIT;
+*/
@@ -0,0 +1,78 @@
+-- 066_broadcast_rich_media.sql
+-- Purpose: Rich-media admin broadcasts
+-- 1. broadcasts: Telegram parse_mode (HTML / MarkdownV2), inline keyboard buttons,
+--    edited_at / recalled_at for editing and deleting delivered messages
+-- 2. broadcast_attachments: photos and documents sent before the text message,
+--    telegram_file_id is cached after the first upload and reused for other recipients
+-- 3. broadcast_logs: chat_id and Telegram message IDs of every delivered message,
+--    required for editMessageText / deleteMessage
+
+BEGIN;
+
+ALTER TABLE broadcasts
+    ADD COLUMN IF NOT EXISTS parse_mode VARCHAR(20) NOT NULL DEFAULT ''
+        CHECK (parse_mode IN ('', 'HTML', 'MarkdownV2')),
+    ADD COLUMN IF NOT EXISTS buttons JSONB NOT NULL DEFAULT '[]'::jsonb,
+    ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS recalled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS broadcast_attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    broadcast_id UUID NOT NULL REFERENCES broadcasts(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('photo', 'document')),
+    file_name VARCHAR(255) NOT NULL,
+    file_path VARCHAR(500) NOT NULL,
+    file_size BIGINT NOT NULL CHECK (file_size > 0),
+    mime_type VARCHAR(100) NOT NULL,
+    telegram_file_id VARCHAR(255),
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE broadcast_logs
+    ADD COLUMN IF NOT EXISTS chat_id BIGINT,
+    ADD COLUMN IF NOT EXISTS message_id BIGINT,
+    ADD COLUMN IF NOT EXISTS attachment_message_ids BIGINT[] NOT NULL DEFAULT '{}';
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_broadcast_attachments_broadcast
+    ON broadcast_attachments(broadcast_id, position);
+-- Доставленные сообщения для редактирования и отзыва рассылки
+CREATE INDEX IF NOT EXISTS idx_broadcast_logs_delivered
+    ON broadcast_logs(broadcast_id) WHERE message_id IS NOT NULL;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN broadcasts.parse_mode IS 'Telegram parse_mode: empty (plain text), HTML or MarkdownV2';
+COMMENT ON COLUMN broadcasts.buttons IS 'Inline keyboard: array of rows, each button has text and either url or callback_data';
+COMMENT ON COLUMN broadcasts.edited_at IS 'Last time delivered messages were edited via editMessageText';
+COMMENT ON COLUMN broadcasts.recalled_at IS 'When delivered messages were deleted from recipients chats';
+COMMENT ON TABLE broadcast_attachments IS 'Photos and documents attached to admin broadcasts';
+COMMENT ON COLUMN broadcast_attachments.telegram_file_id IS 'file_id returned by Telegram after the first upload, reused for other recipients';
+COMMENT ON COLUMN broadcast_logs.message_id IS 'Telegram message_id of the delivered text message';
+COMMENT ON COLUMN broadcast_logs.attachment_message_ids IS 'Telegram message_ids of delivered attachments';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+ALTER TABLE broadcast_logs
+    DROP COLUMN IF EXISTS attachment_message_ids,
+    DROP COLUMN IF EXISTS message_id,
+    DROP COLUMN IF EXISTS chat_id;
+DROP TABLE IF EXISTS broadcast_attachments;
+ALTER TABLE broadcasts
+    DROP COLUMN IF EXISTS recalled_at,
+    DROP COLUMN IF EXISTS edited_at,
+    DROP COLUMN IF EXISTS buttons,
+    DROP COLUMN IF EXISTS parse_mode;
+COMMIT;
+*/
@@ -0,0 +1,95 @@
+-- 067_trial_request_crm.sql
+-- Purpose: Trial request CRM pipeline
+-- 1. trial_requests: pipeline status (new -> contacted -> scheduled -> converted / lost),
+--    assignment to an admin, follow-up due date with reminder tracking
+-- 2. trial_requests: links to the student, trial lesson and booking created on conversion
+--    (used for funnel analytics)
+-- 3. trial_request_activities: notes and history of status changes, assignments and follow-ups
+
+BEGIN;
+
+ALTER TABLE trial_requests
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'new'
+        CHECK (status IN ('new', 'contacted', 'scheduled', 'converted', 'lost')),
+    ADD COLUMN IF NOT EXISTS assigned_to UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS follow_up_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS follow_up_reminded_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS lost_reason TEXT,
+    ADD COLUMN IF NOT EXISTS converted_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS converted_lesson_id UUID REFERENCES lessons(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS converted_booking_id UUID REFERENCES bookings(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS converted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS trial_request_activities (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trial_request_id INT NOT NULL REFERENCES trial_requests(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('note', 'status_change', 'assignment', 'follow_up', 'conversion')),
+    body TEXT NOT NULL DEFAULT '',
+    from_status VARCHAR(20),
+    to_status VARCHAR(20),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_trial_requests_status ON trial_requests(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_trial_requests_assigned_to ON trial_requests(assigned_to) WHERE assigned_to IS NOT NULL;
+-- Заявки с наступающим сроком напоминания, по которым напоминание ещё не отправлено
+CREATE INDEX IF NOT EXISTS idx_trial_requests_follow_up_due
+    ON trial_requests(follow_up_at)
+    WHERE follow_up_at IS NOT NULL AND follow_up_reminded_at IS NULL AND status NOT IN ('converted', 'lost');
+CREATE INDEX IF NOT EXISTS idx_trial_request_activities_request
+    ON trial_request_activities(trial_request_id, created_at DESC);
+
+-- ============================================================================
+-- TRIGGERS
+-- ============================================================================
+
+DROP TRIGGER IF EXISTS update_trial_requests_updated_at ON trial_requests;
+CREATE TRIGGER update_trial_requests_updated_at
+    BEFORE UPDATE ON trial_requests
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN trial_requests.status IS 'CRM pipeline status: new, contacted, scheduled, converted, lost';
+COMMENT ON COLUMN trial_requests.assigned_to IS 'Admin responsible for processing the request';
+COMMENT ON COLUMN trial_requests.follow_up_at IS 'When the assigned admin should follow up with the lead';
+COMMENT ON COLUMN trial_requests.follow_up_reminded_at IS 'When the follow-up reminder was sent; reset when follow_up_at changes';
+COMMENT ON COLUMN trial_requests.converted_user_id IS 'Student account created from this request';
+COMMENT ON COLUMN trial_requests.converted_lesson_id IS 'Trial lesson created on conversion';
+COMMENT ON COLUMN trial_requests.converted_booking_id IS 'Booking of the student on the trial lesson';
+COMMENT ON TABLE trial_request_activities IS 'Notes and activity log of trial requests';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TABLE IF EXISTS trial_request_activities;
+DROP TRIGGER IF EXISTS update_trial_requests_updated_at ON trial_requests;
+DROP INDEX IF EXISTS idx_trial_requests_follow_up_due;
+DROP INDEX IF EXISTS idx_trial_requests_assigned_to;
+DROP INDEX IF EXISTS idx_trial_requests_status;
+ALTER TABLE trial_requests
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS converted_at,
+    DROP COLUMN IF EXISTS converted_booking_id,
+    DROP COLUMN IF EXISTS converted_lesson_id,
+    DROP COLUMN IF EXISTS converted_user_id,
+    DROP COLUMN IF EXISTS lost_reason,
+    DROP COLUMN IF EXISTS follow_up_reminded_at,
+    DROP COLUMN IF EXISTS follow_up_at,
+    DROP COLUMN IF EXISTS assigned_to,
+    DROP COLUMN IF EXISTS status;
+COMMIT;
+*/
@@ -0,0 +1,135 @@
+-- 068_analytics_views.sql
+-- Purpose: Admin analytics rollups
+-- Daily aggregates are stored in materialized views and refreshed on a schedule by the
+-- backend (REFRESH MATERIALIZED VIEW CONCURRENTLY), so /admin/analytics never scans raw tables.
+-- 1. analytics_daily_revenue: succeeded payments and subscription invoices per day
+-- 2. analytics_daily_credit_flow: credits added / deducted / refunded per day
+-- 3. analytics_daily_utilization: seats booked vs capacity per day, teacher and subject
+-- 4. analytics_daily_booking_activity: bookings, cancellations and swaps per day
+-- 5. analytics_student_monthly_activity: months in which each student booked lessons (cohort retention)
+-- 6. analytics_refresh_state: when each view was last refreshed
+-- All days are UTC calendar days.
+
+BEGIN;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS analytics_daily_revenue AS
+SELECT (created_at AT TIME ZONE 'UTC')::date AS day,
+       'payment'::varchar(20) AS source,
+       COUNT(*) AS payments_count,
+       SUM(amount) AS amount_total,
+       SUM(credits) AS credits_total
+FROM payments
+WHERE status = 'succeeded'
+GROUP BY 1
+UNION ALL
+SELECT (COALESCE(processed_at, created_at) AT TIME ZONE 'UTC')::date AS day,
+       'subscription'::varchar(20) AS source,
+       COUNT(*) AS payments_count,
+       SUM(amount) AS amount_total,
+       SUM(credits) AS credits_total
+FROM subscription_invoices
+WHERE status = 'succeeded'
+GROUP BY 1
+WITH DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS analytics_daily_credit_flow AS
+SELECT (created_at AT TIME ZONE 'UTC')::date AS day,
+       operation_type,
+       COUNT(*) AS transactions_count,
+       SUM(ABS(amount)) AS credits_total
+FROM credit_transactions
+GROUP BY 1, 2
+WITH DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS analytics_daily_utilization AS
+SELECT (start_time AT TIME ZONE 'UTC')::date AS day,
+       teacher_id,
+       COALESCE(NULLIF(TRIM(subject), ''), '') AS subject,
+       COUNT(*) AS lessons_count,
+       SUM(current_students) AS seats_booked,
+       SUM(max_students) AS seats_total
+FROM lessons
+WHERE deleted_at IS NULL
+GROUP BY 1, 2, 3
+WITH DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS analytics_daily_booking_activity AS
+SELECT day,
+       SUM(booked) AS bookings_count,
+       SUM(cancelled) AS cancellations_count,
+       SUM(swapped) AS swaps_count
+FROM (
+    SELECT (booked_at AT TIME ZONE 'UTC')::date AS day, 1 AS booked, 0 AS cancelled, 0 AS swapped
+    FROM bookings
+    WHERE booked_at IS NOT NULL
+    UNION ALL
+    SELECT (cancelled_at AT TIME ZONE 'UTC')::date, 0, 1, 0
+    FROM bookings
+    WHERE status = 'cancelled' AND cancelled_at IS NOT NULL
+    UNION ALL
+    SELECT (created_at AT TIME ZONE 'UTC')::date, 0, 0, 1
+    FROM swaps
+) events
+GROUP BY day
+WITH DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS analytics_student_monthly_activity AS
+SELECT b.student_id,
+       date_trunc('month', b.booked_at AT TIME ZONE 'UTC')::date AS activity_month,
+       COUNT(*) AS bookings_count
+FROM bookings b
+JOIN users u ON u.id = b.student_id AND u.role = 'student'
+WHERE b.booked_at IS NOT NULL
+GROUP BY 1, 2
+WITH DATA;
+
+CREATE TABLE IF NOT EXISTS analytics_refresh_state (
+    view_name VARCHAR(100) PRIMARY KEY,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Уникальные индексы обязательны для REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX IF NOT EXISTS idx_analytics_daily_revenue_key
+    ON analytics_daily_revenue(day, source);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_analytics_daily_credit_flow_key
+    ON analytics_daily_credit_flow(day, operation_type);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_analytics_daily_utilization_key
+    ON analytics_daily_utilization(day, teacher_id, subject);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_analytics_daily_booking_activity_key
+    ON analytics_daily_booking_activity(day);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_analytics_student_monthly_activity_key
+    ON analytics_student_monthly_activity(student_id, activity_month);
+CREATE INDEX IF NOT EXISTS idx_analytics_student_monthly_activity_month
+    ON analytics_student_monthly_activity(activity_month);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON MATERIALIZED VIEW analytics_daily_revenue IS 'Daily revenue from succeeded payments and subscription invoices';
+COMMENT ON MATERIALIZED VIEW analytics_daily_credit_flow IS 'Daily credits added, deducted and refunded';
+COMMENT ON MATERIALIZED VIEW analytics_daily_utilization IS 'Daily booked seats vs capacity per teacher and subject';
+COMMENT ON MATERIALIZED VIEW analytics_daily_booking_activity IS 'Daily bookings, cancellations and swaps';
+COMMENT ON MATERIALIZED VIEW analytics_student_monthly_activity IS 'Months in which each student booked lessons, used for cohort retention';
+COMMENT ON TABLE analytics_refresh_state IS 'Last refresh time of analytics materialized views';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TABLE IF EXISTS analytics_refresh_state;
+DROP MATERIALIZED VIEW IF EXISTS analytics_student_monthly_activity;
+DROP MATERIALIZED VIEW IF EXISTS analytics_daily_booking_activity;
+DROP MATERIALIZED VIEW IF EXISTS analytics_daily_utilization;
+DROP MATERIALIZED VIEW IF EXISTS analytics_daily_credit_flow;
+DROP MATERIALIZED VIEW IF EXISTS analytics_daily_revenue;
+COMMIT;
+*/
@@ -0,0 +1,120 @@
+-- 069_teacher_payroll.sql
+-- Purpose: Teacher payroll
+-- 1. teacher_pay_rates: pay rate history per teacher (per lesson, per student-hour or percentage
+--    of consumed credits). The rate applied to a lesson is the latest one effective on the lesson date
+-- 2. payroll_periods: closed payroll periods; periods must not overlap
+-- 3. teacher_earnings_statements / teacher_earnings_lines: earnings frozen at period close,
+--    later rate changes or lesson edits do not affect closed statements
+-- 4. teacher_earnings_adjustments: manual bonuses and deductions; pending adjustments are
+--    attached to the period that covers their date when the period is closed
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS teacher_pay_rates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    teacher_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rate_type VARCHAR(20) NOT NULL CHECK (rate_type IN ('per_lesson', 'per_student_hour', 'credit_percentage')),
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    credit_value DECIMAL(12, 2) NOT NULL DEFAULT 2800 CHECK (credit_value > 0),
+    effective_from DATE NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT teacher_pay_rates_unique_effective UNIQUE (teacher_id, effective_from)
+);
+
+CREATE TABLE IF NOT EXISTS payroll_periods (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    closed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    closed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT payroll_periods_valid_range CHECK (period_end >= period_start),
+    -- Период включает обе границы; пересекающиеся периоды запрещены
+    CONSTRAINT payroll_periods_no_overlap EXCLUDE USING GIST (
+        daterange(period_start, period_end, '[]') WITH &&
+    )
+);
+
+CREATE TABLE IF NOT EXISTS teacher_earnings_statements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    period_id UUID NOT NULL REFERENCES payroll_periods(id) ON DELETE CASCADE,
+    teacher_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    lessons_count INT NOT NULL DEFAULT 0,
+    unrated_lessons INT NOT NULL DEFAULT 0,
+    student_hours DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    credits_consumed INT NOT NULL DEFAULT 0,
+    lessons_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    adjustments_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT teacher_earnings_statements_unique UNIQUE (period_id, teacher_id)
+);
+
+CREATE TABLE IF NOT EXISTS teacher_earnings_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    statement_id UUID NOT NULL REFERENCES teacher_earnings_statements(id) ON DELETE CASCADE,
+    lesson_id UUID REFERENCES lessons(id) ON DELETE SET NULL,
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    students_count INT NOT NULL,
+    student_hours DECIMAL(12, 2) NOT NULL,
+    credits_consumed INT NOT NULL,
+    rate_type VARCHAR(20),
+    rate_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    amount DECIMAL(12, 2) NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS teacher_earnings_adjustments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    teacher_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_id UUID REFERENCES payroll_periods(id) ON DELETE SET NULL,
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount <> 0),
+    reason TEXT NOT NULL,
+    applies_on DATE NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_teacher_pay_rates_teacher ON teacher_pay_rates(teacher_id, effective_from DESC);
+CREATE INDEX IF NOT EXISTS idx_payroll_periods_start ON payroll_periods(period_start DESC);
+CREATE INDEX IF NOT EXISTS idx_teacher_earnings_statements_teacher ON teacher_earnings_statements(teacher_id);
+CREATE INDEX IF NOT EXISTS idx_teacher_earnings_lines_statement ON teacher_earnings_lines(statement_id, start_time);
+CREATE INDEX IF NOT EXISTS idx_teacher_earnings_adjustments_pending
+    ON teacher_earnings_adjustments(applies_on)
+    WHERE period_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_teacher_earnings_adjustments_period
+    ON teacher_earnings_adjustments(period_id, teacher_id)
+    WHERE period_id IS NOT NULL;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE teacher_pay_rates IS 'Teacher pay rate history; the latest rate effective on the lesson date applies';
+COMMENT ON COLUMN teacher_pay_rates.amount IS 'Rubles per lesson, rubles per student-hour, or percent of consumed credits value';
+COMMENT ON COLUMN teacher_pay_rates.credit_value IS 'Ruble value of one credit for credit_percentage rates';
+COMMENT ON TABLE payroll_periods IS 'Closed payroll periods (both dates inclusive)';
+COMMENT ON TABLE teacher_earnings_statements IS 'Earnings statement of a teacher frozen at period close';
+COMMENT ON TABLE teacher_earnings_lines IS 'Per-lesson earnings copied at period close';
+COMMENT ON COLUMN teacher_earnings_lines.rate_type IS 'NULL when the teacher had no pay rate on the lesson date';
+COMMENT ON TABLE teacher_earnings_adjustments IS 'Manual bonuses (positive) and deductions (negative); period_id is NULL until the covering period is closed';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TABLE IF EXISTS teacher_earnings_adjustments;
+DROP TABLE IF EXISTS teacher_earnings_lines;
+DROP TABLE IF EXISTS teacher_earnings_statements;
+DROP TABLE IF EXISTS payroll_periods;
+DROP TABLE IF EXISTS teacher_pay_rates;
+COMMIT;
+*/
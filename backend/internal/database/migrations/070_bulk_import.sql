@@ -0,0 +1,58 @@
+-- 070_bulk_import.sql
+-- Purpose: Bulk import of users, lessons and bookings from CSV/XLSX
+-- 1. import_runs: log of applied imports (who, what file, how many rows were created or skipped)
+-- 2. import_external_ids: mapping of external IDs from source files to created entities,
+--    so re-importing the same file skips already imported rows and bookings can reference
+--    lessons by their external ID
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS import_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('users', 'lessons', 'bookings')),
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    rows_total INTEGER NOT NULL DEFAULT 0 CHECK (rows_total >= 0),
+    rows_created INTEGER NOT NULL DEFAULT 0 CHECK (rows_created >= 0),
+    rows_skipped INTEGER NOT NULL DEFAULT 0 CHECK (rows_skipped >= 0),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS import_external_ids (
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('users', 'lessons', 'bookings')),
+    external_id VARCHAR(255) NOT NULL CHECK (external_id <> ''),
+    entity_id UUID NOT NULL,
+    import_id UUID REFERENCES import_runs(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_type, external_id)
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_import_runs_created_at
+    ON import_runs(created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_import_external_ids_entity
+    ON import_external_ids(entity_type, entity_id);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE import_runs IS 'Applied bulk imports (dry runs are not recorded)';
+COMMENT ON TABLE import_external_ids IS 'External IDs from import files mapped to created users, lessons and bookings';
+COMMENT ON COLUMN import_external_ids.entity_id IS 'ID of the created entity; not a foreign key because entity_type selects the table';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TABLE IF EXISTS import_external_ids;
+DROP TABLE IF EXISTS import_runs;
+COMMIT;
+*/
@@ -0,0 +1,54 @@
+-- 071_account_erasure.sql
+-- Purpose: Account erasure requests (GDPR-style "right to be forgotten")
+-- 1. account_erasure_requests: user requests erasure, an admin approves or rejects it.
+--    On approval PII is anonymized in users, trial_requests, telegram_users and chat;
+--    financial records (payments, credit transactions, bookings) are preserved and keep
+--    pointing to the anonymized user row
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS account_erasure_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'rejected', 'completed')),
+    reason TEXT NOT NULL DEFAULT '',
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT account_erasure_requests_reviewed CHECK (
+        (status = 'pending' AND reviewed_at IS NULL) OR (status <> 'pending' AND reviewed_at IS NOT NULL)
+    )
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Не более одного открытого запроса на пользователя
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_erasure_requests_one_pending
+    ON account_erasure_requests(user_id)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_account_erasure_requests_status
+    ON account_erasure_requests(status, created_at DESC);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE account_erasure_requests IS 'User requests to erase personal data; executed when an admin approves them';
+COMMENT ON COLUMN account_erasure_requests.status IS 'pending -> completed (approved and anonymized) or rejected';
+COMMENT ON COLUMN account_erasure_requests.review_note IS 'Admin comment, e.g. the reason for rejection';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TABLE IF EXISTS account_erasure_requests;
+COMMIT;
+*/
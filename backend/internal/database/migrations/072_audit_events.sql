@@ -0,0 +1,75 @@
+-- 072_audit_events.sql
+-- Purpose: Unified append-only audit log for privileged mutations
+-- 1. audit_events: actor, action, target, before/after diff, IP and request ID
+-- 2. Tamper evidence: every row stores hash = sha256(prev_hash || payload) of the previous row,
+--    so editing or removing a row breaks the chain (checked by GET /admin/audit/verify)
+-- 3. UPDATE, DELETE and TRUNCATE are rejected by triggers
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    -- Без внешнего ключа: записи журнала не должны меняться при удалении пользователя
+    actor_id UUID,
+    actor_role VARCHAR(20) NOT NULL DEFAULT '',
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id VARCHAR(64) NOT NULL DEFAULT '',
+    before_data JSONB,
+    after_data JSONB,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    method VARCHAR(10) NOT NULL DEFAULT '',
+    path VARCHAR(512) NOT NULL DEFAULT '',
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_no_update ON audit_events;
+CREATE TRIGGER audit_events_no_update
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit_events(target_type, target_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events(action, created_at DESC);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE audit_events IS 'Append-only audit log of privileged mutations with a hash chain';
+COMMENT ON COLUMN audit_events.before_data IS 'Changed fields before the mutation (full object for deletes)';
+COMMENT ON COLUMN audit_events.after_data IS 'Changed fields after the mutation (full object for creates)';
+COMMENT ON COLUMN audit_events.prev_hash IS 'Hash of the previous event, 64 zeros for the first one';
+COMMENT ON COLUMN audit_events.hash IS 'sha256 of prev_hash and the canonical event payload';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+COMMIT;
+*/
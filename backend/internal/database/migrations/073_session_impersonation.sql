@@ -0,0 +1,39 @@
+-- 073_session_impersonation.sql
+-- Purpose: Admin impersonation ("view as user")
+-- 1. sessions.impersonator_id: admin who opened the session on behalf of user_id
+--    (NULL for regular sessions). Impersonation sessions are short-lived and never extended
+-- 2. sessions.read_only: mutating requests are rejected (impersonation is read-only by default)
+
+BEGIN;
+
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS impersonator_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS read_only BOOLEAN NOT NULL DEFAULT false;
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_sessions_impersonator
+    ON sessions(impersonator_id)
+    WHERE impersonator_id IS NOT NULL;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN sessions.impersonator_id IS 'Admin who impersonates user_id in this session; NULL for regular sessions';
+COMMENT ON COLUMN sessions.read_only IS 'Session may only perform read (GET) requests';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP INDEX IF EXISTS idx_sessions_impersonator;
+ALTER TABLE sessions DROP COLUMN IF EXISTS read_only;
+ALTER TABLE sessions DROP COLUMN IF EXISTS impersonator_id;
+COMMIT;
+*/
@@ -0,0 +1,95 @@
+-- 074_permissions.sql
+-- Purpose: Fine-grained permissions
+-- 1. roles: built-in (is_system) and custom roles; users.role references roles.name instead of a CHECK list
+-- 2. role_permissions: role -> permission mapping (permission names come from the registry in code)
+-- 3. Seed: built-in roles keep their current access; custom "manager" role manages users and credits
+--    but cannot delete users
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY CHECK (name ~ '^[a-z][a-z0-9_]{1,49}$'),
+    description TEXT NOT NULL DEFAULT '',
+    is_system BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description, is_system) VALUES
+    ('student', 'Студент', true),
+    ('teacher', 'Преподаватель (методист)', true),
+    ('admin', 'Администратор', true),
+    ('manager', 'Менеджер: пользователи, кредиты, заявки и группы без удаления пользователей', false)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', p FROM unnest(ARRAY[
+    'users.read', 'users.list.all', 'users.create', 'users.edit', 'users.delete', 'users.impersonate',
+    'credits.read.all', 'credits.grant',
+    'subjects.manage',
+    'lessons.create', 'lessons.edit.own', 'lessons.edit.all', 'lessons.delete.own', 'lessons.delete.all',
+    'lessons.broadcast.own', 'lessons.broadcast.all', 'lessons.modifications.revert',
+    'broadcasts.manage', 'trial_requests.read', 'trial_requests.manage', 'calendar.manage',
+    'analytics.read', 'payroll.manage', 'import.run', 'audit.read', 'erasure.review',
+    'groups.manage', 'subscriptions.manage', 'payments.manage', 'chat.read.all', 'roles.manage'
+]) AS p
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'teacher', p FROM unnest(ARRAY[
+    'users.list.all', 'credits.read.all', 'subjects.manage',
+    'lessons.create', 'lessons.edit.own', 'lessons.delete.all', 'lessons.broadcast.own',
+    'broadcasts.manage', 'trial_requests.read', 'teaching.schedule'
+]) AS p
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'manager', p FROM unnest(ARRAY[
+    'users.read', 'users.list.all', 'users.create', 'users.edit',
+    'credits.read.all', 'credits.grant',
+    'trial_requests.read', 'trial_requests.manage', 'groups.manage', 'payments.manage', 'analytics.read'
+]) AS p
+ON CONFLICT DO NOTHING;
+
+-- Роль пользователя теперь проверяется по таблице roles
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE users ADD CONSTRAINT users_role_fkey
+    FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- FK checks on role rename/delete must see soft-deleted users too (idx_users_role is partial)
+CREATE INDEX IF NOT EXISTS idx_users_role_fk ON users(role);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE roles IS 'User roles: built-in (is_system, cannot be deleted) and custom roles';
+COMMENT ON TABLE role_permissions IS 'Role to permission mapping; permission names are defined by the registry in code';
+COMMENT ON COLUMN roles.is_system IS 'Built-in role (student, teacher, admin): cannot be deleted, admin permissions are locked';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+UPDATE users SET role = 'student' WHERE role NOT IN ('student', 'teacher', 'admin');
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('student', 'admin', 'teacher'));
+DROP INDEX IF EXISTS idx_users_role_fk;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+COMMIT;
+*/
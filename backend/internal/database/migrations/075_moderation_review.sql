@@ -0,0 +1,65 @@
+-- 075_moderation_review.sql
+-- Purpose: Admin review queue and user appeals for messages blocked by moderation
+-- 1. blocked_messages: rule that triggered the block, review status, reviewer and note
+-- 2. blocked_messages: appeal from the sender (one per block, reopens a confirmed block)
+-- 3. admin_reviewed column that the code already writes but the table never had
+-- 4. New permission moderation.review for the admin role
+
+BEGIN;
+
+ALTER TABLE blocked_messages
+    ADD COLUMN IF NOT EXISTS admin_reviewed BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS rule VARCHAR(50) NOT NULL DEFAULT 'unknown',
+    ADD COLUMN IF NOT EXISTS review_status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (review_status IN ('pending', 'released', 'confirmed')),
+    ADD COLUMN IF NOT EXISTS reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS review_note TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS appeal_reason TEXT,
+    ADD COLUMN IF NOT EXISTS appealed_at TIMESTAMP WITH TIME ZONE;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'moderation.review')
+ON CONFLICT DO NOTHING;
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Review queue: pending blocks, appealed first, newest first
+CREATE INDEX IF NOT EXISTS idx_blocked_messages_review_queue
+ON blocked_messages(review_status, appealed_at DESC NULLS LAST, blocked_at DESC);
+
+-- Per-rule statistics
+CREATE INDEX IF NOT EXISTS idx_blocked_messages_rule
+ON blocked_messages(rule);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN blocked_messages.rule IS 'Rule that blocked the message: ai, phone, email, social, video_platform, obfuscated_phone';
+COMMENT ON COLUMN blocked_messages.review_status IS 'pending - awaiting admin review, released - delivered after review, confirmed - block upheld';
+COMMENT ON COLUMN blocked_messages.appeal_reason IS 'Sender appeal text; an appeal reopens a confirmed block for review';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DELETE FROM role_permissions WHERE permission = 'moderation.review';
+DROP INDEX IF EXISTS idx_blocked_messages_rule;
+DROP INDEX IF EXISTS idx_blocked_messages_review_queue;
+ALTER TABLE blocked_messages
+    DROP COLUMN IF EXISTS appealed_at,
+    DROP COLUMN IF EXISTS appeal_reason,
+    DROP COLUMN IF EXISTS review_note,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS reviewed_by,
+    DROP COLUMN IF EXISTS review_status,
+    DROP COLUMN IF EXISTS rule,
+    DROP COLUMN IF EXISTS admin_reviewed;
+COMMIT;
+*/
@@ -0,0 +1,220 @@
+-- 076_group_chat_rooms.sql
+-- Purpose: Multi-participant chat rooms next to the existing 1:1 teacher-student rooms
+-- 1. chat_rooms: room_type (direct, lesson_group, channel), title, description, linked series or cohort
+-- 2. chat_room_participants: membership of group rooms with role (owner, moderator, member) and mute
+-- 3. sync_lesson_group_chat(): creates a room per recurring group lesson series and syncs members from bookings
+-- 4. Booking trigger keeps lesson group membership in sync, backfill for existing series
+-- 5. New permission chat.channels.manage for the admin role
+
+BEGIN;
+
+-- ============================================================================
+-- CHAT ROOMS: room type and group room attributes
+-- ============================================================================
+
+ALTER TABLE chat_rooms
+    ADD COLUMN IF NOT EXISTS room_type VARCHAR(20) NOT NULL DEFAULT 'direct'
+        CHECK (room_type IN ('direct', 'lesson_group', 'channel')),
+    ADD COLUMN IF NOT EXISTS title VARCHAR(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS recurring_group_id UUID,
+    ADD COLUMN IF NOT EXISTS student_group_id UUID REFERENCES student_groups(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- Group rooms have no teacher/student pair
+ALTER TABLE chat_rooms ALTER COLUMN teacher_id DROP NOT NULL;
+ALTER TABLE chat_rooms ALTER COLUMN student_id DROP NOT NULL;
+
+ALTER TABLE chat_rooms DROP CONSTRAINT IF EXISTS chat_rooms_group_shape;
+ALTER TABLE chat_rooms ADD CONSTRAINT chat_rooms_group_shape CHECK (
+    (room_type = 'direct' AND recurring_group_id IS NULL)
+    OR (room_type = 'lesson_group' AND recurring_group_id IS NOT NULL AND teacher_id IS NULL AND student_id IS NULL)
+    OR (room_type = 'channel' AND recurring_group_id IS NULL AND teacher_id IS NULL AND student_id IS NULL)
+);
+
+-- ============================================================================
+-- PARTICIPANTS OF GROUP ROOMS
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS chat_room_participants (
+    room_id UUID NOT NULL REFERENCES chat_rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member'
+        CHECK (role IN ('owner', 'moderator', 'member')),
+    muted_until TIMESTAMP WITH TIME ZONE,
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (room_id, user_id)
+);
+
+-- ============================================================================
+-- FUNCTION: Create or sync the chat room of a recurring group lesson series
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION sync_lesson_group_chat(p_recurring_group_id UUID)
+RETURNS UUID AS $$
+DECLARE
+    v_room_id UUID;
+    v_title TEXT;
+BEGIN
+    IF p_recurring_group_id IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    -- Only group series get a room: individual lessons already have 1:1 chats
+    SELECT COALESCE(NULLIF(MAX(l.subject), ''), 'Group lessons')
+    INTO v_title
+    FROM lessons l
+    WHERE l.recurring_group_id = p_recurring_group_id
+      AND l.deleted_at IS NULL
+    HAVING MAX(l.max_students) > 1;
+
+    IF v_title IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO chat_rooms (id, room_type, title, recurring_group_id, created_at, updated_at)
+    VALUES (gen_random_uuid(), 'lesson_group', v_title, p_recurring_group_id, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+    ON CONFLICT (recurring_group_id) WHERE room_type = 'lesson_group' AND deleted_at IS NULL
+    DO UPDATE SET updated_at = CURRENT_TIMESTAMP
+    RETURNING id INTO v_room_id;
+
+    -- Teachers of the series own the room
+    INSERT INTO chat_room_participants (room_id, user_id, role)
+    SELECT DISTINCT v_room_id, l.teacher_id, 'owner'
+    FROM lessons l
+    WHERE l.recurring_group_id = p_recurring_group_id
+      AND l.deleted_at IS NULL
+      AND l.teacher_id IS NOT NULL
+    ON CONFLICT (room_id, user_id) DO UPDATE SET role = 'owner';
+
+    -- Students with an active booking on any lesson of the series are members
+    INSERT INTO chat_room_participants (room_id, user_id, role)
+    SELECT DISTINCT v_room_id, b.student_id, 'member'
+    FROM bookings b
+    JOIN lessons l ON l.id = b.lesson_id
+    WHERE l.recurring_group_id = p_recurring_group_id
+      AND l.deleted_at IS NULL
+      AND b.status = 'active'
+    ON CONFLICT (room_id, user_id) DO NOTHING;
+
+    -- Members without active bookings leave the room; staff added manually stays
+    DELETE FROM chat_room_participants p
+    WHERE p.room_id = v_room_id
+      AND p.role = 'member'
+      AND NOT EXISTS (
+          SELECT 1
+          FROM bookings b
+          JOIN lessons l ON l.id = b.lesson_id
+          WHERE l.recurring_group_id = p_recurring_group_id
+            AND l.deleted_at IS NULL
+            AND b.status = 'active'
+            AND b.student_id = p.user_id
+      );
+
+    RETURN v_room_id;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON FUNCTION sync_lesson_group_chat(UUID) IS
+'Creates the chat room of a recurring group lesson series and syncs members from active bookings. Returns room id or NULL.';
+
+-- ============================================================================
+-- TRIGGER: Sync lesson group chat on booking changes
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION sync_lesson_group_chat_on_booking()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_recurring_group_id UUID;
+BEGIN
+    SELECT l.recurring_group_id INTO v_recurring_group_id
+    FROM lessons l
+    WHERE l.id = NEW.lesson_id;
+
+    IF v_recurring_group_id IS NOT NULL THEN
+        PERFORM sync_lesson_group_chat(v_recurring_group_id);
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_sync_lesson_group_chat ON bookings;
+CREATE TRIGGER trigger_sync_lesson_group_chat
+AFTER INSERT OR UPDATE OF status ON bookings
+FOR EACH ROW
+EXECUTE FUNCTION sync_lesson_group_chat_on_booking();
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'chat.channels.manage')
+ON CONFLICT DO NOTHING;
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- One room per recurring series (also the conflict target of sync_lesson_group_chat)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_chat_rooms_recurring_group
+ON chat_rooms(recurring_group_id) WHERE room_type = 'lesson_group' AND deleted_at IS NULL;
+
+-- Group rooms of a user
+CREATE INDEX IF NOT EXISTS idx_chat_room_participants_user
+ON chat_room_participants(user_id);
+
+-- ============================================================================
+-- BACKFILL: Rooms for existing recurring group series
+-- ============================================================================
+
+DO $$
+DECLARE
+    v_series RECORD;
+    v_created INTEGER := 0;
+BEGIN
+    FOR v_series IN
+        SELECT DISTINCT recurring_group_id
+        FROM lessons
+        WHERE recurring_group_id IS NOT NULL
+          AND deleted_at IS NULL
+    LOOP
+        IF sync_lesson_group_chat(v_series.recurring_group_id) IS NOT NULL THEN
+            v_created := v_created + 1;
+        END IF;
+    END LOOP;
+
+    RAISE NOTICE 'Backfill: synced % lesson group chat rooms', v_created;
+END $$;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN chat_rooms.room_type IS 'direct - 1:1 teacher-student, lesson_group - recurring group series, channel - admin announcements';
+COMMENT ON COLUMN chat_rooms.student_group_id IS 'Cohort whose active members were added to the channel on creation';
+COMMENT ON TABLE chat_room_participants IS 'Members of lesson_group and channel rooms; direct rooms use teacher_id/student_id';
+COMMENT ON COLUMN chat_room_participants.role IS 'owner and moderator can post in channels and mute members';
+COMMENT ON COLUMN chat_room_participants.muted_until IS 'Member cannot post until this time; NULL - not muted';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DELETE FROM role_permissions WHERE permission = 'chat.channels.manage';
+DROP TRIGGER IF EXISTS trigger_sync_lesson_group_chat ON bookings;
+DROP FUNCTION IF EXISTS sync_lesson_group_chat_on_booking();
+DROP FUNCTION IF EXISTS sync_lesson_group_chat(UUID);
+DROP TABLE IF EXISTS chat_room_participants;
+DELETE FROM chat_rooms WHERE room_type <> 'direct';
+DROP INDEX IF EXISTS idx_chat_rooms_recurring_group;
+ALTER TABLE chat_rooms DROP CONSTRAINT IF EXISTS chat_rooms_group_shape;
+ALTER TABLE chat_rooms
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS student_group_id,
+    DROP COLUMN IF EXISTS recurring_group_id,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS room_type;
+COMMIT;
+*/
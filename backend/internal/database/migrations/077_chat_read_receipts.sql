@@ -0,0 +1,71 @@
+-- 077_chat_read_receipts.sql
+-- Purpose: Per-participant read state of chat rooms for read receipts and unread counters
+-- 1. chat_read_states: last read message of each participant in each room (direct and group)
+-- 2. Backfill: existing conversations are marked as read so users do not get the whole history as unread
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS chat_read_states (
+    room_id UUID NOT NULL REFERENCES chat_rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_read_message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+    -- created_at of the last read message: messages after it are unread
+    last_read_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (room_id, user_id)
+);
+
+-- ============================================================================
+-- BACKFILL: Mark existing conversations as read up to the latest delivered message
+-- ============================================================================
+
+WITH last_messages AS (
+    SELECT DISTINCT ON (m.room_id) m.room_id, m.id, m.created_at
+    FROM messages m
+    WHERE m.deleted_at IS NULL AND m.status = 'delivered'
+    ORDER BY m.room_id, m.created_at DESC, m.id DESC
+),
+room_members AS (
+    SELECT cr.id AS room_id, cr.teacher_id AS user_id
+    FROM chat_rooms cr
+    WHERE cr.room_type = 'direct' AND cr.teacher_id IS NOT NULL
+    UNION
+    SELECT cr.id, cr.student_id
+    FROM chat_rooms cr
+    WHERE cr.room_type = 'direct' AND cr.student_id IS NOT NULL
+    UNION
+    SELECT p.room_id, p.user_id
+    FROM chat_room_participants p
+)
+INSERT INTO chat_read_states (room_id, user_id, last_read_message_id, last_read_at)
+SELECT rm.room_id, rm.user_id, lm.id, lm.created_at
+FROM room_members rm
+JOIN last_messages lm ON lm.room_id = rm.room_id
+ON CONFLICT (room_id, user_id) DO NOTHING;
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Unread badge: read states of a user across rooms
+CREATE INDEX IF NOT EXISTS idx_chat_read_states_user
+ON chat_read_states(user_id);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE chat_read_states IS 'Read receipts: last message read by each participant of a chat room';
+COMMENT ON COLUMN chat_read_states.last_read_at IS 'created_at of last_read_message_id; delivered messages of others after it are unread';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP INDEX IF EXISTS idx_chat_read_states_user;
+DROP TABLE IF EXISTS chat_read_states;
+COMMIT;
+*/
@@ -0,0 +1,66 @@
+-- 078_message_edits_replies_reactions.sql
+-- Purpose: Editing, replies and emoji reactions for chat messages
+-- 1. messages.edited_at and message_edits: edit history (previous text of every edit)
+-- 2. messages.reply_to_message_id: replies with quoted preview of the original message
+-- 3. message_reactions: emoji reactions, one row per user and emoji
+
+BEGIN;
+
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS reply_to_message_id UUID REFERENCES messages(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS message_edits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    previous_text TEXT NOT NULL,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    edited_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS message_reactions (
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Edit history of a message
+CREATE INDEX IF NOT EXISTS idx_message_edits_message
+ON message_edits(message_id, edited_at DESC);
+
+-- Replies to a message (thread view)
+CREATE INDEX IF NOT EXISTS idx_messages_reply_to
+ON messages(reply_to_message_id, created_at)
+WHERE reply_to_message_id IS NOT NULL;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN messages.edited_at IS 'Last edit time; an edited message goes through moderation again';
+COMMENT ON COLUMN messages.reply_to_message_id IS 'Message this one replies to (same room)';
+COMMENT ON TABLE message_edits IS 'Edit history: text of the message before each edit';
+COMMENT ON TABLE message_reactions IS 'Emoji reactions to delivered messages';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP INDEX IF EXISTS idx_messages_reply_to;
+DROP INDEX IF EXISTS idx_message_edits_message;
+DROP TABLE IF EXISTS message_reactions;
+DROP TABLE IF EXISTS message_edits;
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS reply_to_message_id,
+    DROP COLUMN IF EXISTS edited_at;
+COMMIT;
+*/
@@ -0,0 +1,80 @@
+-- 079_full_text_search.sql
+-- Purpose: Full-text search over chat history, homework and lesson reports
+-- 1. Stored generated tsvector columns combining 'russian' and 'english' configurations,
+--    so both Russian and English word forms match (columns follow edits of the source text)
+-- 2. GIN indexes for the @@ operator
+
+BEGIN;
+
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        to_tsvector('russian', COALESCE(message_text, '')) ||
+        to_tsvector('english', COALESCE(message_text, ''))
+    ) STORED;
+
+ALTER TABLE lesson_homework
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        to_tsvector('russian', COALESCE(text_content, '')) ||
+        to_tsvector('english', COALESCE(text_content, ''))
+    ) STORED;
+
+-- Subject has a higher weight, so matches in it rank above matches in the text
+ALTER TABLE lessons
+    ADD COLUMN IF NOT EXISTS homework_search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', COALESCE(subject, '')), 'A') ||
+        setweight(to_tsvector('russian', COALESCE(homework_text, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(homework_text, '')), 'B')
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS report_search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', COALESCE(subject, '')), 'A') ||
+        setweight(to_tsvector('russian', COALESCE(report_text, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(report_text, '')), 'B')
+    ) STORED;
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Only delivered messages are searchable
+CREATE INDEX IF NOT EXISTS idx_messages_search
+ON messages USING GIN (search_vector)
+WHERE deleted_at IS NULL AND status = 'delivered';
+
+CREATE INDEX IF NOT EXISTS idx_lesson_homework_search
+ON lesson_homework USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS idx_lessons_homework_search
+ON lessons USING GIN (homework_search_vector)
+WHERE deleted_at IS NULL AND homework_text IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_lessons_report_search
+ON lessons USING GIN (report_search_vector)
+WHERE deleted_at IS NULL AND report_text IS NOT NULL;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN messages.search_vector IS 'Full-text search vector of message text (russian + english)';
+COMMENT ON COLUMN lesson_homework.search_vector IS 'Full-text search vector of homework text content (russian + english)';
+COMMENT ON COLUMN lessons.homework_search_vector IS 'Full-text search vector of subject (weight A) and homework text (weight B)';
+COMMENT ON COLUMN lessons.report_search_vector IS 'Full-text search vector of subject (weight A) and lesson report (weight B)';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP INDEX IF EXISTS idx_lessons_report_search;
+DROP INDEX IF EXISTS idx_lessons_homework_search;
+DROP INDEX IF EXISTS idx_lesson_homework_search;
+DROP INDEX IF EXISTS idx_messages_search;
+ALTER TABLE lessons
+    DROP COLUMN IF EXISTS report_search_vector,
+    DROP COLUMN IF EXISTS homework_search_vector;
+ALTER TABLE lesson_homework DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE messages DROP COLUMN IF EXISTS search_vector;
+COMMIT;
+*/
@@ -0,0 +1,64 @@
+-- 080_blob_storage.sql
+-- Purpose: Uploaded files move from hard-coded local paths to a BlobStore (local directory or S3-compatible)
+-- 1. storage_key: object key in the BlobStore ("<prefix>/<ab>/<sha256><ext>");
+--    NULL means a legacy file that still lives at file_path on local disk
+-- 2. content_hash: SHA-256 of the content; identical uploads share one object,
+--    so an object may only be deleted when no row references its key
+
+BEGIN;
+
+ALTER TABLE file_attachments
+    ADD COLUMN IF NOT EXISTS storage_key VARCHAR(512),
+    ADD COLUMN IF NOT EXISTS content_hash CHAR(64);
+
+ALTER TABLE lesson_homework
+    ADD COLUMN IF NOT EXISTS storage_key VARCHAR(512),
+    ADD COLUMN IF NOT EXISTS content_hash CHAR(64);
+
+ALTER TABLE broadcast_files
+    ADD COLUMN IF NOT EXISTS storage_key VARCHAR(512),
+    ADD COLUMN IF NOT EXISTS content_hash CHAR(64);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Reference checks before deleting a shared object
+CREATE INDEX IF NOT EXISTS idx_file_attachments_storage_key
+ON file_attachments(storage_key)
+WHERE storage_key IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_lesson_homework_storage_key
+ON lesson_homework(storage_key)
+WHERE storage_key IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_broadcast_files_storage_key
+ON broadcast_files(storage_key)
+WHERE storage_key IS NOT NULL;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN file_attachments.storage_key IS 'BlobStore object key (NULL - legacy file at file_path)';
+COMMENT ON COLUMN file_attachments.content_hash IS 'SHA-256 of the file content (hex)';
+COMMENT ON COLUMN lesson_homework.storage_key IS 'BlobStore object key (NULL - legacy file at file_path)';
+COMMENT ON COLUMN lesson_homework.content_hash IS 'SHA-256 of the file content (hex)';
+COMMENT ON COLUMN broadcast_files.storage_key IS 'BlobStore object key (NULL - legacy file at file_path)';
+COMMENT ON COLUMN broadcast_files.content_hash IS 'SHA-256 of the file content (hex)';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP INDEX IF EXISTS idx_broadcast_files_storage_key;
+DROP INDEX IF EXISTS idx_lesson_homework_storage_key;
+DROP INDEX IF EXISTS idx_file_attachments_storage_key;
+ALTER TABLE broadcast_files DROP COLUMN IF EXISTS content_hash, DROP COLUMN IF EXISTS storage_key;
+ALTER TABLE lesson_homework DROP COLUMN IF EXISTS content_hash, DROP COLUMN IF EXISTS storage_key;
+ALTER TABLE file_attachments DROP COLUMN IF EXISTS content_hash, DROP COLUMN IF EXISTS storage_key;
+COMMIT;
+*/
@@ -0,0 +1,54 @@
+-- 081_upload_quarantine.sql
+-- Purpose: Quarantine for uploads flagged by the malware scanner
+-- 1. upload_quarantine keeps metadata of rejected files; the content is stored in BlobStore
+--    under the 'quarantine/' prefix and is never served to users
+-- 2. The uploader is kept for investigation and set to NULL if the user is deleted
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS upload_quarantine (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source VARCHAR(32) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    file_size BIGINT NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    storage_key VARCHAR(512),
+    signature VARCHAR(255) NOT NULL,
+    scanner VARCHAR(32) NOT NULL,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_upload_quarantine_source CHECK (source IN ('chat', 'homework', 'lesson_broadcasts'))
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_upload_quarantine_created_at
+ON upload_quarantine(created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_upload_quarantine_uploaded_by
+ON upload_quarantine(uploaded_by)
+WHERE uploaded_by IS NOT NULL;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE upload_quarantine IS 'Uploads rejected by the malware scanner';
+COMMENT ON COLUMN upload_quarantine.storage_key IS 'BlobStore key of the quarantined content (NULL if it could not be stored)';
+COMMENT ON COLUMN upload_quarantine.signature IS 'Malware signature reported by the scanner';
+COMMENT ON COLUMN upload_quarantine.scanner IS 'Scanner that flagged the file (clamd, stub)';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP TABLE IF EXISTS upload_quarantine;
+COMMIT;
+*/
@@ -0,0 +1,44 @@
+-- 082_file_thumbnails.sql
+-- Purpose: Thumbnails for chat attachments and homework files
+-- 1. thumbnail_key points to a JPEG preview in BlobStore ('thumbnails/' prefix, keyed by content hash,
+--    so identical files share one preview)
+-- 2. NULL until the preview is generated in the background, or if the type has no preview
+
+BEGIN;
+
+ALTER TABLE file_attachments ADD COLUMN IF NOT EXISTS thumbnail_key VARCHAR(512);
+ALTER TABLE lesson_homework ADD COLUMN IF NOT EXISTS thumbnail_key VARCHAR(512);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Reference checks before deleting a preview object
+CREATE INDEX IF NOT EXISTS idx_file_attachments_thumbnail_key
+ON file_attachments(thumbnail_key)
+WHERE thumbnail_key IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_lesson_homework_thumbnail_key
+ON lesson_homework(thumbnail_key)
+WHERE thumbnail_key IS NOT NULL;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN file_attachments.thumbnail_key IS 'BlobStore key of the JPEG preview (NULL - no preview)';
+COMMENT ON COLUMN lesson_homework.thumbnail_key IS 'BlobStore key of the JPEG preview (NULL - no preview)';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP INDEX IF EXISTS idx_lesson_homework_thumbnail_key;
+DROP INDEX IF EXISTS idx_file_attachments_thumbnail_key;
+ALTER TABLE lesson_homework DROP COLUMN IF EXISTS thumbnail_key;
+ALTER TABLE file_attachments DROP COLUMN IF EXISTS thumbnail_key;
+COMMIT;
+*/
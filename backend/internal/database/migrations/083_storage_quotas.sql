@@ -0,0 +1,49 @@
+-- 083_storage_quotas.sql
+-- Purpose: Per-user storage quotas and permission for storage administration
+-- 1. user_storage_quotas overrides the default per-user quota (STORAGE_USER_QUOTA_MB) for single users;
+--    usage is the total size of the user's live uploads (chat attachments, homework, lesson broadcast files)
+-- 2. storage.manage grants the usage report, quota management and manual garbage collection runs
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS user_storage_quotas (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    quota_bytes BIGINT NOT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_user_storage_quotas_bytes CHECK (quota_bytes >= 0)
+);
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'storage.manage')
+ON CONFLICT DO NOTHING;
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Usage of a sender's broadcast files is counted on every upload when quotas are enabled
+CREATE INDEX IF NOT EXISTS idx_lesson_broadcasts_sender
+ON lesson_broadcasts(sender_id);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE user_storage_quotas IS 'Per-user storage quota overrides; users without a row get the configured default';
+COMMENT ON COLUMN user_storage_quotas.quota_bytes IS 'Maximum total size of live uploads in bytes (0 forbids uploads)';
+COMMENT ON COLUMN user_storage_quotas.updated_by IS 'Admin who set the quota';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DELETE FROM role_permissions WHERE permission = 'storage.manage';
+DROP INDEX IF EXISTS idx_lesson_broadcasts_sender;
+DROP TABLE IF EXISTS user_storage_quotas;
+COMMIT;
+*/
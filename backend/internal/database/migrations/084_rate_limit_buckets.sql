@@ -0,0 +1,40 @@
+-- 084_rate_limit_buckets.sql
+-- Purpose: Shared rate limit counters for RATE_LIMIT_STORE=postgres
+-- 1. rate_limit_buckets keeps one GCRA state per key (route group + client IP or user ID),
+--    so limits are shared between server replicas and survive restarts
+-- 2. The table is UNLOGGED: counters are cheap to lose after a database crash and are written on every limited request
+
+BEGIN;
+
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tat TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Periodic cleanup deletes buckets whose limit has fully recovered
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_tat
+ON rate_limit_buckets(tat);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE rate_limit_buckets IS 'Rate limit counters shared between server replicas (RATE_LIMIT_STORE=postgres)';
+COMMENT ON COLUMN rate_limit_buckets.key IS 'Route group and client key, e.g. login:ip:203.0.113.7 or chat_send:user:<uuid>';
+COMMENT ON COLUMN rate_limit_buckets.tat IS 'Theoretical arrival time of the next request (GCRA); the limit is fully recovered once it has passed';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP INDEX IF EXISTS idx_rate_limit_buckets_tat;
+DROP TABLE IF EXISTS rate_limit_buckets;
+COMMIT;
+*/
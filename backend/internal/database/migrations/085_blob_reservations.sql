@@ -0,0 +1,41 @@
+-- 085_blob_reservations.sql
+-- Purpose: Protect shared BlobStore objects between an upload and the row that will reference them
+-- 1. An upload that stores (or dedups onto) an object reserves its key until the file row is created;
+--    Release and the garbage collector treat a fresh reservation as a reference
+-- 2. Storing, reserving and deleting an object are serialized by a transaction-level advisory lock on the key,
+--    so a concurrent Release cannot delete an object that an upload has just reused
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS blob_reservations (
+    storage_key VARCHAR(512) PRIMARY KEY,
+    reserved_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Expired reservations are purged by reservation time
+CREATE INDEX IF NOT EXISTS idx_blob_reservations_reserved_at
+ON blob_reservations(reserved_at);
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON TABLE blob_reservations IS 'BlobStore objects stored by uploads whose file rows may not exist yet';
+COMMENT ON COLUMN blob_reservations.storage_key IS 'Object key in the BlobStore';
+COMMENT ON COLUMN blob_reservations.reserved_at IS 'Last upload of this content; the reservation counts as a reference for a limited time';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP INDEX IF EXISTS idx_blob_reservations_reserved_at;
+DROP TABLE IF EXISTS blob_reservations;
+COMMIT;
+*/
@@ -0,0 +1,39 @@
+-- 086_broadcast_attachment_storage.sql
+-- Purpose: Admin broadcast attachments are stored in the BlobStore like the other uploaded files
+-- 1. storage_key: object key in the BlobStore ("broadcasts/<ab>/<sha256><ext>");
+--    NULL means a legacy attachment that still lives at file_path inside ./uploads/broadcasts
+-- 2. content_hash: SHA-256 of the content; the object is shared with identical uploads
+
+BEGIN;
+
+ALTER TABLE broadcast_attachments
+    ADD COLUMN IF NOT EXISTS storage_key VARCHAR(512),
+    ADD COLUMN IF NOT EXISTS content_hash CHAR(64);
+
+-- ============================================================================
+-- INDEXES FOR PERFORMANCE
+-- ============================================================================
+
+-- Reference checks before deleting a shared object
+CREATE INDEX IF NOT EXISTS idx_broadcast_attachments_storage_key
+ON broadcast_attachments(storage_key)
+WHERE storage_key IS NOT NULL;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN broadcast_attachments.storage_key IS 'BlobStore object key (NULL - legacy file at file_path)';
+COMMENT ON COLUMN broadcast_attachments.content_hash IS 'SHA-256 of the file content (hex)';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+/*
+BEGIN;
+DROP INDEX IF EXISTS idx_broadcast_attachments_storage_key;
+ALTER TABLE broadcast_attachments DROP COLUMN IF EXISTS content_hash, DROP COLUMN IF EXISTS storage_key;
+COMMIT;
+*/
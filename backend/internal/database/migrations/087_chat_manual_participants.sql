@@ -0,0 +1,97 @@
+-- 087_chat_manual_participants.sql
+-- Purpose: Members added to a lesson group room by hand are no longer removed by the booking sync
+-- 1. chat_room_participants.added_manually: set for participants added through the participants API
+-- 2. sync_lesson_group_chat(): removes only members that joined through a booking and have no active booking left
+
+BEGIN;
+
+ALTER TABLE chat_room_participants
+    ADD COLUMN IF NOT EXISTS added_manually BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- ============================================================================
+-- FUNCTION: Keep manually added members during the lesson group sync
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION sync_lesson_group_chat(p_recurring_group_id UUID)
+RETURNS UUID AS $$
+DECLARE
+    v_room_id UUID;
+    v_title TEXT;
+BEGIN
+    IF p_recurring_group_id IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    -- Only group series get a room: individual lessons already have 1:1 chats
+    SELECT COALESCE(NULLIF(MAX(l.subject), ''), 'Group lessons')
+    INTO v_title
+    FROM lessons l
+    WHERE l.recurring_group_id = p_recurring_group_id
+      AND l.deleted_at IS NULL
+    HAVING MAX(l.max_students) > 1;
+
+    IF v_title IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO chat_rooms (id, room_type, title, recurring_group_id, created_at, updated_at)
+    VALUES (gen_random_uuid(), 'lesson_group', v_title, p_recurring_group_id, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+    ON CONFLICT (recurring_group_id) WHERE room_type = 'lesson_group' AND deleted_at IS NULL
+    DO UPDATE SET updated_at = CURRENT_TIMESTAMP
+    RETURNING id INTO v_room_id;
+
+    -- Teachers of the series own the room
+    INSERT INTO chat_room_participants (room_id, user_id, role)
+    SELECT DISTINCT v_room_id, l.teacher_id, 'owner'
+    FROM lessons l
+    WHERE l.recurring_group_id = p_recurring_group_id
+      AND l.deleted_at IS NULL
+      AND l.teacher_id IS NOT NULL
+    ON CONFLICT (room_id, user_id) DO UPDATE SET role = 'owner';
+
+    -- Students with an active booking on any lesson of the series are members
+    INSERT INTO chat_room_participants (room_id, user_id, role)
+    SELECT DISTINCT v_room_id, b.student_id, 'member'
+    FROM bookings b
+    JOIN lessons l ON l.id = b.lesson_id
+    WHERE l.recurring_group_id = p_recurring_group_id
+      AND l.deleted_at IS NULL
+      AND b.status = 'active'
+    ON CONFLICT (room_id, user_id) DO NOTHING;
+
+    -- Members without active bookings leave the room; staff and members added manually stay
+    DELETE FROM chat_room_participants p
+    WHERE p.room_id = v_room_id
+      AND p.role = 'member'
+      AND NOT p.added_manually
+      AND NOT EXISTS (
+          SELECT 1
+          FROM bookings b
+          JOIN lessons l ON l.id = b.lesson_id
+          WHERE l.recurring_group_id = p_recurring_group_id
+            AND l.deleted_at IS NULL
+            AND b.status = 'active'
+            AND b.student_id = p.user_id
+      );
+
+    RETURN v_room_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ============================================================================
+-- COMMENTS FOR DOCUMENTATION
+-- ============================================================================
+
+COMMENT ON COLUMN chat_room_participants.added_manually IS 'Added through the participants API; kept in lesson_group rooms without an active booking';
+
+COMMIT;
+
+-- ============================================================================
+-- ROLLBACK INSTRUCTIONS
+-- ============================================================================
+-- Restore sync_lesson_group_chat() by running its definition from 076_group_chat_rooms.sql, then:
+/*
+BEGIN;
+ALTER TABLE chat_room_participants DROP COLUMN IF EXISTS added_manually;
+COMMIT;
+*/
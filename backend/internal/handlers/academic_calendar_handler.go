@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// maxICSFileSize ограничивает размер импортируемого ICS файла
+const maxICSFileSize = 2 << 20
+
+// AcademicCalendarHandler обрабатывает эндпоинты академического календаря (admin only)
+type AcademicCalendarHandler struct {
+	calendarService *service.AcademicCalendarService
+}
+
+// NewAcademicCalendarHandler создает новый AcademicCalendarHandler
+func NewAcademicCalendarHandler(calendarService *service.AcademicCalendarService) *AcademicCalendarHandler {
+	return &AcademicCalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// ListEntries обрабатывает GET /api/v1/admin/calendar
+// @Summary      List academic calendar entries
+// @Description  Get terms, holidays and breaks, optionally overlapping the from/to period (YYYY-MM-DD)
+// @Tags         calendar
+// @Produce      json
+// @Param        from  query     string  false  "Period start (YYYY-MM-DD)"
+// @Param        to    query     string  false  "Period end (YYYY-MM-DD)"
+// @Success      200   {object}  response.SuccessResponse{data=[]models.AcademicCalendarEntry}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      500   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/calendar [get]
+func (h *AcademicCalendarHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseCalendarRange(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := h.calendarService.ListEntries(r.Context(), from, to)
+	if err != nil {
+		response.InternalError(w, "Failed to retrieve calendar entries")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"entries": entries,
+	})
+}
+
+// CreateEntry обрабатывает POST /api/v1/admin/calendar
+// @Summary      Create academic calendar entry
+// @Description  Create a term, holiday or break. Holidays and breaks block recurring lesson generation
+// @Tags         calendar
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.CreateCalendarEntryRequest  true  "Calendar entry"
+// @Success      201   {object}  response.SuccessResponse{data=models.AcademicCalendarEntry}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      500   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/calendar [post]
+func (h *AcademicCalendarHandler) CreateEntry(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var req models.CreateCalendarEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	entry, err := h.calendarService.CreateEntry(r.Context(), user.ID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to create calendar entry")
+		return
+	}
+
+	response.Created(w, entry)
+}
+
+// UpdateEntry обрабатывает PUT /api/v1/admin/calendar/{id}
+// @Summary      Update academic calendar entry
+// @Tags         calendar
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                             true  "Entry ID"
+// @Param        body  body      models.UpdateCalendarEntryRequest  true  "Updated fields"
+// @Success      200   {object}  response.SuccessResponse{data=models.AcademicCalendarEntry}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      404   {object}  response.ErrorResponse
+// @Failure      500   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/calendar/{id} [put]
+func (h *AcademicCalendarHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid calendar entry ID")
+		return
+	}
+
+	var req models.UpdateCalendarEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	entry, err := h.calendarService.UpdateEntry(r.Context(), id, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to update calendar entry")
+		return
+	}
+
+	response.OK(w, entry)
+}
+
+// DeleteEntry обрабатывает DELETE /api/v1/admin/calendar/{id}
+// @Summary      Delete academic calendar entry
+// @Tags         calendar
+// @Produce      json
+// @Param        id   path      string  true  "Entry ID"
+// @Success      200  {object}  response.SuccessResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/calendar/{id} [delete]
+func (h *AcademicCalendarHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid calendar entry ID")
+		return
+	}
+
+	if err := h.calendarService.DeleteEntry(r.Context(), id); err != nil {
+		h.handleError(w, err, "Failed to delete calendar entry")
+		return
+	}
+
+	response.OK(w, map[string]string{
+		"message": "Calendar entry deleted successfully",
+	})
+}
+
+// ImportICS обрабатывает POST /api/v1/admin/calendar/import
+// Принимает multipart/form-data с полем "file" или тело с Content-Type text/calendar
+// @Summary      Import academic calendar from ICS
+// @Description  Import holidays/breaks/terms from an ICS file. Re-importing the same file updates entries by UID
+// @Tags         calendar
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file        formData  file    true   "ICS file"
+// @Param        entry_type  query     string  false  "Type for events without CATEGORIES (default: holiday)"
+// @Success      200  {object}  response.SuccessResponse{data=models.ICSImportResult}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/calendar/import [post]
+func (h *AcademicCalendarHandler) ImportICS(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxICSFileSize+1024)
+
+	var source io.Reader = r.Body
+	entryType := r.URL.Query().Get("entry_type")
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxICSFileSize); err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Failed to parse form data")
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeMissingField, "ICS file is required")
+			return
+		}
+		defer file.Close()
+		source = file
+
+		if formType := r.FormValue("entry_type"); formType != "" {
+			entryType = formType
+		}
+	}
+
+	result, err := h.calendarService.ImportICS(r.Context(), user.ID, source, models.CalendarEntryType(entryType))
+	if err != nil {
+		h.handleError(w, err, "Failed to import calendar")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// PreviewConflicts обрабатывает GET /api/v1/admin/calendar/conflicts
+// @Summary      Preview lessons conflicting with holidays
+// @Description  List future lessons falling on holidays/breaks, by entry_id (repeatable) or from/to period
+// @Tags         calendar
+// @Produce      json
+// @Param        entry_id  query     string  false  "Calendar entry ID (repeatable)"
+// @Param        from      query     string  false  "Period start (YYYY-MM-DD)"
+// @Param        to        query     string  false  "Period end (YYYY-MM-DD)"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.CalendarConflict}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/calendar/conflicts [get]
+func (h *AcademicCalendarHandler) PreviewConflicts(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseCalendarRange(w, r)
+	if !ok {
+		return
+	}
+
+	var entryIDs []uuid.UUID
+	for _, raw := range r.URL.Query()["entry_id"] {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid entry_id")
+			return
+		}
+		entryIDs = append(entryIDs, id)
+	}
+
+	conflicts, err := h.calendarService.PreviewConflicts(r.Context(), entryIDs, from, to)
+	if err != nil {
+		response.InternalError(w, "Failed to find conflicting lessons")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"conflicts": conflicts,
+		"count":     len(conflicts),
+	})
+}
+
+// CancelConflicts обрабатывает POST /api/v1/admin/calendar/conflicts/cancel
+// @Summary      Cancel lessons conflicting with holidays
+// @Description  Cancel selected future lessons on holidays/breaks, cancelling bookings and refunding credits atomically
+// @Tags         calendar
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.CancelCalendarConflictsRequest  true  "Lessons to cancel"
+// @Success      200   {object}  response.SuccessResponse{data=models.CancelCalendarConflictsResult}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      404   {object}  response.ErrorResponse
+// @Failure      409   {object}  response.ErrorResponse
+// @Failure      500   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/calendar/conflicts/cancel [post]
+func (h *AcademicCalendarHandler) CancelConflicts(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var req models.CancelCalendarConflictsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	result, err := h.calendarService.CancelConflictingLessons(r.Context(), user.ID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to cancel lessons")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// handleError преобразует ошибки сервиса календаря в HTTP ответы
+func (h *AcademicCalendarHandler) handleError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrCalendarEntryNotFound):
+		response.NotFound(w, "Calendar entry not found")
+	case errors.Is(err, repository.ErrLessonNotFound):
+		response.NotFound(w, "Lesson not found")
+	case errors.Is(err, models.ErrLessonAlreadyStarted),
+		errors.Is(err, models.ErrLessonNotOnBlockedDate):
+		response.Conflict(w, response.ErrCodeConflict, err.Error())
+	case errors.Is(err, models.ErrInvalidCalendarEntryType),
+		errors.Is(err, models.ErrInvalidCalendarEntryName),
+		errors.Is(err, models.ErrInvalidCalendarDates),
+		errors.Is(err, models.ErrInvalidICSFile),
+		errors.Is(err, models.ErrInvalidLessonID):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	default:
+		response.InternalError(w, fallback)
+	}
+}
+
+// parseCalendarRange разбирает параметры from/to (YYYY-MM-DD); to включает весь указанный день
+func parseCalendarRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	var from, to time.Time
+	var err error
+
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		if from, err = time.Parse(models.CalendarDateLayout, raw); err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid from date format (expected YYYY-MM-DD)")
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		if to, err = time.Parse(models.CalendarDateLayout, raw); err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid to date format (expected YYYY-MM-DD)")
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "to must not be before from")
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// AccountDataHandler обрабатывает выгрузку персональных данных и запросы на удаление аккаунта
+type AccountDataHandler struct {
+	accountDataService *service.AccountDataService
+}
+
+// NewAccountDataHandler создает новый AccountDataHandler
+func NewAccountDataHandler(accountDataService *service.AccountDataService) *AccountDataHandler {
+	return &AccountDataHandler{
+		accountDataService: accountDataService,
+	}
+}
+
+// Export обрабатывает GET /api/v1/me/export
+// @Summary      Export my personal data
+// @Description  ZIP archive with profile, bookings, credit history, payments, chat messages with attachments,
+// @Description  homework and swaps as JSON files plus manifest.json
+// @Tags         account
+// @Produce      application/zip
+// @Success      200  {file}    binary
+// @Failure      401  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /me/export [get]
+func (h *AccountDataHandler) Export(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	export, err := h.accountDataService.Export(r.Context(), user.ID)
+	if err != nil {
+		h.handleError(w, err, "Failed to export account data")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteZip(&buf); err != nil {
+		log.Printf("ERROR: Failed to build account export for user %s: %v", user.ID, err)
+		response.InternalError(w, "Failed to export account data")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", export.FileName()))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("ERROR: Failed to write account export: %v", err)
+	}
+}
+
+// GetErasureRequest обрабатывает GET /api/v1/me/erasure
+// @Summary      Get my latest account erasure request
+// @Tags         account
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=models.AccountErasureRequest}
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /me/erasure [get]
+func (h *AccountDataHandler) GetErasureRequest(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	erasure, err := h.accountDataService.GetMyErasureRequest(r.Context(), user.ID)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve erasure request")
+		return
+	}
+
+	response.OK(w, erasure)
+}
+
+// RequestErasure обрабатывает POST /api/v1/me/erasure
+// @Summary      Request account erasure
+// @Description  Creates a request to anonymize personal data. The account is erased only after an admin approves it
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      models.CreateErasureRequest  false  "Optional reason"
+// @Success      201  {object}  response.SuccessResponse{data=models.AccountErasureRequest}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /me/erasure [post]
+func (h *AccountDataHandler) RequestErasure(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var req models.CreateErasureRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+			return
+		}
+	}
+
+	erasure, err := h.accountDataService.RequestErasure(r.Context(), user.ID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to create erasure request")
+		return
+	}
+
+	response.Created(w, erasure)
+}
+
+// ListErasureRequests обрабатывает GET /api/v1/admin/erasure-requests
+// @Summary      List account erasure requests
+// @Description  Pending requests first, then reviewed ones, newest first
+// @Tags         account
+// @Produce      json
+// @Param        status  query     string  false  "pending, rejected or completed"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.AccountErasureRequest}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/erasure-requests [get]
+func (h *AccountDataHandler) ListErasureRequests(w http.ResponseWriter, r *http.Request) {
+	var status *models.ErasureRequestStatus
+	if raw := r.URL.Query().Get("status"); raw != "" {
+		parsed := models.ErasureRequestStatus(raw)
+		status = &parsed
+	}
+
+	requests, err := h.accountDataService.ListErasureRequests(r.Context(), status)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve erasure requests")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"requests": requests,
+	})
+}
+
+// ApproveErasure обрабатывает POST /api/v1/admin/erasure-requests/{id}/approve
+// @Summary      Approve account erasure
+// @Description  Anonymizes the user, their trial requests, Telegram link and chat messages, removes their
+// @Description  chat attachments and sessions. Payments, credits and bookings are preserved
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                       true   "Erasure request ID"
+// @Param        payload  body      models.ReviewErasureRequest  false  "Optional note"
+// @Success      200  {object}  response.SuccessResponse{data=models.ErasureResult}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/erasure-requests/{id}/approve [post]
+func (h *AccountDataHandler) ApproveErasure(w http.ResponseWriter, r *http.Request) {
+	admin, req, requestID, ok := h.parseReview(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.accountDataService.ApproveErasure(r.Context(), admin.ID, requestID, req)
+	if err != nil {
+		h.handleError(w, err, "Failed to approve erasure request")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// RejectErasure обрабатывает POST /api/v1/admin/erasure-requests/{id}/reject
+// @Summary      Reject account erasure
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                       true  "Erasure request ID"
+// @Param        payload  body      models.ReviewErasureRequest  true  "Rejection reason"
+// @Success      200  {object}  response.SuccessResponse{data=models.AccountErasureRequest}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/erasure-requests/{id}/reject [post]
+func (h *AccountDataHandler) RejectErasure(w http.ResponseWriter, r *http.Request) {
+	admin, req, requestID, ok := h.parseReview(w, r)
+	if !ok {
+		return
+	}
+
+	erasure, err := h.accountDataService.RejectErasure(r.Context(), admin.ID, requestID, req)
+	if err != nil {
+		h.handleError(w, err, "Failed to reject erasure request")
+		return
+	}
+
+	response.OK(w, erasure)
+}
+
+// parseReview разбирает администратора, ID запроса и комментарий решения
+func (h *AccountDataHandler) parseReview(w http.ResponseWriter, r *http.Request) (*models.User, *models.ReviewErasureRequest, uuid.UUID, bool) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return nil, nil, uuid.Nil, false
+	}
+
+	requestID, ok := parseUUIDParam(w, r, "id", "Invalid erasure request ID")
+	if !ok {
+		return nil, nil, uuid.Nil, false
+	}
+
+	var req models.ReviewErasureRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+			return nil, nil, uuid.Nil, false
+		}
+	}
+
+	return admin, &req, requestID, true
+}
+
+// handleError преобразует ошибки выгрузки и удаления данных в HTTP ответы
+func (h *AccountDataHandler) handleError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrErasureRequestNotFound):
+		response.NotFound(w, "Erasure request not found")
+	case errors.Is(err, repository.ErrUserNotFound):
+		response.NotFound(w, "User not found")
+	case errors.Is(err, models.ErrErasureAdminAccount):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, models.ErrErasureRequestExists):
+		response.Conflict(w, response.ErrCodeAlreadyExists, err.Error())
+	case errors.Is(err, models.ErrErasureRequestNotPending),
+		errors.Is(err, models.ErrAccountAlreadyErased):
+		response.Conflict(w, response.ErrCodeConflict, err.Error())
+	case errors.Is(err, models.ErrInvalidErasureComment),
+		errors.Is(err, models.ErrInvalidErasureStatus):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	default:
+		log.Printf("ERROR: %s: %v", fallback, err)
+		response.InternalError(w, fallback)
+	}
+}
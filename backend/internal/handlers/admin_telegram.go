@@ -10,6 +10,7 @@ import (
 	"log"
 
 	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
 	"tutoring-platform/internal/repository"
 	"tutoring-platform/internal/service"
 	"tutoring-platform/pkg/response"
@@ -39,14 +40,14 @@ func NewAdminTelegramHandler(
 // ListUsersWithTelegram обрабатывает GET /admin/users/telegram
 // Возвращает список всех пользователей с информацией о их Telegram статусе
 func (h *AdminTelegramHandler) ListUsersWithTelegram(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	// Проверяем, что пользователь админ
-	if !user.IsAdmin() {
+	// Проверяем право на управление пользователями
+	if !middleware.HasPermission(r.Context(), models.PermUsersRead) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -116,8 +117,8 @@ func (h *AdminTelegramHandler) UnlinkUserTelegram(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Проверяем, что пользователь админ
-	if !user.IsAdmin() {
+	// Проверяем право на управление пользователями
+	if !middleware.HasPermission(r.Context(), models.PermUsersEdit) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -158,14 +159,14 @@ func (h *AdminTelegramHandler) UnlinkUserTelegram(w http.ResponseWriter, r *http
 // SetUserTelegram обрабатывает PUT /admin/users/{id}/telegram
 // Устанавливает или обновляет Telegram username пользователя
 func (h *AdminTelegramHandler) SetUserTelegram(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	// Проверяем, что пользователь админ
-	if !user.IsAdmin() {
+	// Проверяем право на управление пользователями
+	if !middleware.HasPermission(r.Context(), models.PermUsersEdit) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -238,14 +239,14 @@ func (h *AdminTelegramHandler) SetUserTelegram(w http.ResponseWriter, r *http.Re
 // SendMessageToUser обрабатывает POST /admin/users/{id}/telegram/message
 // Отправляет сообщение пользователю в Telegram
 func (h *AdminTelegramHandler) SendMessageToUser(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	// Проверяем, что пользователь админ
-	if !user.IsAdmin() {
+	// Проверяем право на управление пользователями
+	if !middleware.HasPermission(r.Context(), models.PermUsersEdit) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -314,14 +315,14 @@ func (h *AdminTelegramHandler) SendMessageToUser(w http.ResponseWriter, r *http.
 // GetUserTelegramInfo обрабатывает GET /admin/users/{id}/telegram
 // Возвращает детальную информацию о Telegram привязке пользователя
 func (h *AdminTelegramHandler) GetUserTelegramInfo(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	// Проверяем, что пользователь админ
-	if !user.IsAdmin() {
+	// Проверяем право на управление пользователями
+	if !middleware.HasPermission(r.Context(), models.PermUsersRead) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
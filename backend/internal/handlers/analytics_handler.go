@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// AnalyticsHandler обрабатывает эндпоинты аналитики (admin only)
+type AnalyticsHandler struct {
+	analyticsService *service.AnalyticsService
+}
+
+// NewAnalyticsHandler создает новый AnalyticsHandler
+func NewAnalyticsHandler(analyticsService *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// GetRevenue обрабатывает GET /api/v1/admin/analytics/revenue
+// @Summary      Revenue analytics
+// @Description  Revenue from succeeded payments and subscription invoices per period
+// @Tags         analytics
+// @Produce      json
+// @Param        from         query     string  false  "Start date (YYYY-MM-DD), default: 90 days ago"
+// @Param        to           query     string  false  "End date inclusive (YYYY-MM-DD), default: today"
+// @Param        granularity  query     string  false  "day, week or month (default)"
+// @Success      200  {object}  response.SuccessResponse{data=models.RevenueReport}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/analytics/revenue [get]
+func (h *AnalyticsHandler) GetRevenue(w http.ResponseWriter, r *http.Request) {
+	q, ok := parseAnalyticsQuery(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.analyticsService.GetRevenue(r.Context(), q)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve revenue analytics")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"revenue": report,
+		"from":    q.From,
+		"to":      q.To,
+	})
+}
+
+// GetCreditFlow обрабатывает GET /api/v1/admin/analytics/credits
+// @Summary      Credit flow analytics
+// @Description  Credits added, deducted and refunded per period; burn = deducted - refunded
+// @Tags         analytics
+// @Produce      json
+// @Param        from         query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to           query     string  false  "End date inclusive (YYYY-MM-DD)"
+// @Param        granularity  query     string  false  "day, week or month"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.CreditFlowPoint}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/analytics/credits [get]
+func (h *AnalyticsHandler) GetCreditFlow(w http.ResponseWriter, r *http.Request) {
+	q, ok := parseAnalyticsQuery(w, r)
+	if !ok {
+		return
+	}
+
+	points, err := h.analyticsService.GetCreditFlow(r.Context(), q)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve credit analytics")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"credits": points,
+		"from":    q.From,
+		"to":      q.To,
+	})
+}
+
+// GetUtilization обрабатывает GET /api/v1/admin/analytics/utilization
+// @Summary      Seat utilization analytics
+// @Description  Booked seats vs capacity per teacher and subject for the period
+// @Tags         analytics
+// @Produce      json
+// @Param        from        query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to          query     string  false  "End date inclusive (YYYY-MM-DD)"
+// @Param        teacher_id  query     string  false  "Teacher ID"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.UtilizationRow}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/analytics/utilization [get]
+func (h *AnalyticsHandler) GetUtilization(w http.ResponseWriter, r *http.Request) {
+	q, ok := parseAnalyticsQuery(w, r)
+	if !ok {
+		return
+	}
+
+	var teacherID *uuid.UUID
+	if raw := r.URL.Query().Get("teacher_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid teacher ID")
+			return
+		}
+		teacherID = &id
+	}
+
+	rows, err := h.analyticsService.GetUtilization(r.Context(), q, teacherID)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve utilization analytics")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"utilization": rows,
+		"from":        q.From,
+		"to":          q.To,
+	})
+}
+
+// GetBookingActivity обрабатывает GET /api/v1/admin/analytics/bookings
+// @Summary      Booking, cancellation and swap analytics
+// @Description  Bookings, cancellations and swaps per period with cancellation and swap rates
+// @Tags         analytics
+// @Produce      json
+// @Param        from         query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to           query     string  false  "End date inclusive (YYYY-MM-DD)"
+// @Param        granularity  query     string  false  "day, week or month"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.BookingActivityPoint}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/analytics/bookings [get]
+func (h *AnalyticsHandler) GetBookingActivity(w http.ResponseWriter, r *http.Request) {
+	q, ok := parseAnalyticsQuery(w, r)
+	if !ok {
+		return
+	}
+
+	points, err := h.analyticsService.GetBookingActivity(r.Context(), q)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve booking analytics")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"bookings": points,
+		"from":     q.From,
+		"to":       q.To,
+	})
+}
+
+// GetRetention обрабатывает GET /api/v1/admin/analytics/retention
+// @Summary      Cohort retention
+// @Description  Monthly retention of student cohorts by the month of their first booking
+// @Tags         analytics
+// @Produce      json
+// @Param        from    query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to      query     string  false  "End date inclusive (YYYY-MM-DD)"
+// @Param        months  query     int     false  "Months to track (max 24)"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.CohortRetention}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/analytics/retention [get]
+func (h *AnalyticsHandler) GetRetention(w http.ResponseWriter, r *http.Request) {
+	q, ok := parseAnalyticsQuery(w, r)
+	if !ok {
+		return
+	}
+
+	months := 0
+	if raw := r.URL.Query().Get("months"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid months")
+			return
+		}
+		months = parsed
+	}
+
+	cohorts, err := h.analyticsService.GetRetention(r.Context(), q, months)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve retention analytics")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"cohorts": cohorts,
+		"from":    q.From,
+		"to":      q.To,
+	})
+}
+
+// GetRefreshState обрабатывает GET /api/v1/admin/analytics/status
+// @Summary      Analytics freshness
+// @Description  Last refresh time of each analytics materialized view
+// @Tags         analytics
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=[]models.AnalyticsRefreshState}
+// @Security     SessionAuth
+// @Router       /admin/analytics/status [get]
+func (h *AnalyticsHandler) GetRefreshState(w http.ResponseWriter, r *http.Request) {
+	states, err := h.analyticsService.GetRefreshState(r.Context())
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve analytics status")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"views": states,
+	})
+}
+
+// RefreshViews обрабатывает POST /api/v1/admin/analytics/refresh
+// @Summary      Refresh analytics
+// @Description  Refresh analytics materialized views immediately instead of waiting for the schedule
+// @Tags         analytics
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=[]models.AnalyticsRefreshState}
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/analytics/refresh [post]
+func (h *AnalyticsHandler) RefreshViews(w http.ResponseWriter, r *http.Request) {
+	if err := h.analyticsService.RefreshViews(r.Context()); err != nil {
+		h.handleError(w, err, "Failed to refresh analytics")
+		return
+	}
+
+	h.GetRefreshState(w, r)
+}
+
+// handleError преобразует ошибки аналитики в HTTP ответы
+func (h *AnalyticsHandler) handleError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrAnalyticsRefreshInProgress):
+		response.Conflict(w, response.ErrCodeConflict, "Analytics refresh is already in progress")
+	case errors.Is(err, models.ErrInvalidAnalyticsGranularity),
+		errors.Is(err, models.ErrInvalidAnalyticsPeriod),
+		errors.Is(err, models.ErrAnalyticsPeriodTooLong):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	default:
+		log.Printf("ERROR: %s: %v", fallback, err)
+		response.InternalError(w, fallback)
+	}
+}
+
+// parseAnalyticsQuery разбирает период и шаг агрегации из query параметров.
+// Параметр to включает указанный день, поэтому к нему прибавляется сутки
+func parseAnalyticsQuery(w http.ResponseWriter, r *http.Request) (*models.AnalyticsQuery, bool) {
+	query := r.URL.Query()
+	q := &models.AnalyticsQuery{Granularity: models.AnalyticsGranularity(query.Get("granularity"))}
+
+	if raw := query.Get("from"); raw != "" {
+		from, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid from date, expected YYYY-MM-DD")
+			return nil, false
+		}
+		q.From = from
+	}
+	if raw := query.Get("to"); raw != "" {
+		to, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid to date, expected YYYY-MM-DD")
+			return nil, false
+		}
+		q.To = to.AddDate(0, 0, 1)
+	}
+
+	return q, true
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// AuditHandler обрабатывает эндпоинты журнала аудита
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler создает новый AuditHandler
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListEvents обрабатывает GET /api/v1/admin/audit
+// @Summary      List audit events
+// @Description  Append-only log of privileged mutations, newest first
+// @Tags         audit
+// @Produce      json
+// @Param        actor_id     query     string  false  "Actor user ID"
+// @Param        action       query     string  false  "Action, e.g. user.update"
+// @Param        target_type  query     string  false  "Target type: user, subject, lesson, chat_room, message, route"
+// @Param        target_id    query     string  false  "Target ID"
+// @Param        from         query     string  false  "From time (RFC3339, inclusive)"
+// @Param        to           query     string  false  "To time (RFC3339, exclusive)"
+// @Param        limit        query     int     false  "Page size (default 50, max 500)"
+// @Param        offset       query     int     false  "Offset"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.AuditEvent}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/audit [get]
+func (h *AuditHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.AuditFilter{
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+	}
+
+	var ok bool
+	if filter.ActorID, ok = parseOptionalUUIDQuery(w, r, "actor_id"); !ok {
+		return
+	}
+	if filter.From, ok = parseOptionalTimeQuery(w, r, "from"); !ok {
+		return
+	}
+	if filter.To, ok = parseOptionalTimeQuery(w, r, "to"); !ok {
+		return
+	}
+	if filter.Limit, ok = parseOptionalIntQuery(w, r, "limit"); !ok {
+		return
+	}
+	if filter.Offset, ok = parseOptionalIntQuery(w, r, "offset"); !ok {
+		return
+	}
+
+	events, total, err := h.auditService.List(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidAuditFilter) {
+			response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+			return
+		}
+		log.Printf("ERROR: Failed to list audit events: %v", err)
+		response.InternalError(w, "Failed to retrieve audit events")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"events": events,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// VerifyChain обрабатывает GET /api/v1/admin/audit/verify
+// @Summary      Verify audit log integrity
+// @Description  Recomputes the hash chain and reports the first event that was modified or removed
+// @Tags         audit
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=models.AuditVerifyResult}
+// @Security     SessionAuth
+// @Router       /admin/audit/verify [get]
+func (h *AuditHandler) VerifyChain(w http.ResponseWriter, r *http.Request) {
+	result, err := h.auditService.Verify(r.Context())
+	if err != nil {
+		log.Printf("ERROR: Failed to verify audit chain: %v", err)
+		response.InternalError(w, "Failed to verify audit log")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// parseOptionalTimeQuery разбирает необязательный параметр времени в формате RFC3339
+func parseOptionalTimeQuery(w http.ResponseWriter, r *http.Request, name string) (*time.Time, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid "+name+", expected RFC3339")
+		return nil, false
+	}
+	return &t, true
+}
+
+// parseOptionalIntQuery разбирает необязательный целочисленный параметр (0, если не задан)
+func parseOptionalIntQuery(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid "+name)
+		return 0, false
+	}
+	return n, true
+}
@@ -266,6 +266,16 @@ func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Сессия имперсонации явно помечается, чтобы фронтенд показал баннер "просмотр от имени"
+	if session, ok := middleware.GetSessionFromContext(r.Context()); ok && session != nil {
+		if info := session.ImpersonationInfo(); info != nil {
+			respBody["impersonation"] = info
+		}
+	}
+
+	// Права роли, чтобы фронтенд скрывал недоступные разделы
+	respBody["permissions"] = middleware.GetPermissionsFromContext(r.Context()).List()
+
 	// Добавляем payment_enabled для студентов (уже включено в user object через MarshalJSON)
 	// User.PaymentEnabled уже сериализуется в JSON автоматически
 
@@ -315,7 +325,7 @@ func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Обновляем профиль текущего пользователя
-	updatedUser, err := h.userService.UpdateUser(r.Context(), user.ID, &req)
+	updatedUser, err := h.userService.UpdateUser(r.Context(), user, user.ID, &req)
 	if err != nil {
 		// Проверяем типы ошибок
 		if err == models.ErrInvalidTelegramHandle {
@@ -330,6 +340,10 @@ func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 			response.BadRequest(w, response.ErrCodeValidationFailed, "Full name is required and must be at least 2 characters")
 			return
 		}
+		if err == models.ErrRoleAssignDenied {
+			response.Forbidden(w, "Not allowed to change your role")
+			return
+		}
 
 		response.InternalError(w, "Failed to update profile")
 		return
@@ -86,7 +86,7 @@ func TestChangePasswordInvalidOldPassword(t *testing.T) {
 	// Создаем тестового пользователя
 	testPassword := "TestPassword123!"
 	uniqueEmail := fmt.Sprintf("%s-change-pass-test@example.com", uuid.New().String()[:8])
-	user, err := userService.CreateUser(ctx, &models.CreateUserRequest{
+	user, err := userService.CreateUser(ctx, testAdminActor, &models.CreateUserRequest{
 		Email:    uniqueEmail,
 		Password: testPassword,
 		FirstName: "Change Password Test", LastName: "Lastname",
@@ -141,7 +141,7 @@ func TestChangePasswordWeakPassword(t *testing.T) {
 	// Создаем тестового пользователя
 	testPassword := "TestPassword123!"
 	uniqueEmail := fmt.Sprintf("%s-weak-pass-test@example.com", uuid.New().String()[:8])
-	user, err := userService.CreateUser(ctx, &models.CreateUserRequest{
+	user, err := userService.CreateUser(ctx, testAdminActor, &models.CreateUserRequest{
 		Email:    uniqueEmail,
 		Password: testPassword,
 		FirstName: "Weak Password Test", LastName: "Lastname",
@@ -195,7 +195,7 @@ func TestChangePasswordSameAsOld(t *testing.T) {
 	// Создаем тестового пользователя
 	testPassword := "TestPassword123!"
 	uniqueEmail := fmt.Sprintf("%s-same-pass-test@example.com", uuid.New().String()[:8])
-	user, err := userService.CreateUser(ctx, &models.CreateUserRequest{
+	user, err := userService.CreateUser(ctx, testAdminActor, &models.CreateUserRequest{
 		Email:    uniqueEmail,
 		Password: testPassword,
 		FirstName: "Same Password Test", LastName: "Lastname",
@@ -250,7 +250,7 @@ func TestChangePasswordSuccess(t *testing.T) {
 	testPassword := "TestPassword123!"
 	newPassword := "NewPassword123!"
 	uniqueEmail := fmt.Sprintf("%s-success-pass-test@example.com", uuid.New().String()[:8])
-	user, err := userService.CreateUser(ctx, &models.CreateUserRequest{
+	user, err := userService.CreateUser(ctx, testAdminActor, &models.CreateUserRequest{
 		Email:    uniqueEmail,
 		Password: testPassword,
 		FirstName: "Success Password Test", LastName: "Lastname",
@@ -332,7 +332,7 @@ func TestChangePasswordMissingOldPassword(t *testing.T) {
 	// Создаем тестового пользователя
 	testPassword := "TestPassword123!"
 	uniqueEmail := fmt.Sprintf("%s-missing-old@example.com", uuid.New().String()[:8])
-	user, err := userService.CreateUser(ctx, &models.CreateUserRequest{
+	user, err := userService.CreateUser(ctx, testAdminActor, &models.CreateUserRequest{
 		Email:    uniqueEmail,
 		Password: testPassword,
 		FirstName: "Missing Old Password Test", LastName: "Lastname",
@@ -385,7 +385,7 @@ func TestChangePasswordMissingNewPassword(t *testing.T) {
 	// Создаем тестового пользователя
 	testPassword := "TestPassword123!"
 	uniqueEmail := fmt.Sprintf("%s-missing-new@example.com", uuid.New().String()[:8])
-	user, err := userService.CreateUser(ctx, &models.CreateUserRequest{
+	user, err := userService.CreateUser(ctx, testAdminActor, &models.CreateUserRequest{
 		Email:    uniqueEmail,
 		Password: testPassword,
 		FirstName: "Missing New Password Test", LastName: "Lastname",
@@ -438,7 +438,7 @@ func TestChangePasswordInvalidJSON(t *testing.T) {
 	// Создаем тестового пользователя
 	testPassword := "TestPassword123!"
 	uniqueEmail := fmt.Sprintf("%s-invalid-json@example.com", uuid.New().String()[:8])
-	user, err := userService.CreateUser(ctx, &models.CreateUserRequest{
+	user, err := userService.CreateUser(ctx, testAdminActor, &models.CreateUserRequest{
 		Email:    uniqueEmail,
 		Password: testPassword,
 		FirstName: "Invalid JSON Test", LastName: "Lastname",
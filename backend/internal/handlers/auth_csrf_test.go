@@ -52,7 +52,7 @@ func TestLoginGeneratesCSRFToken(t *testing.T) {
 
 	testPassword := "TestPassword123!"
 	uniqueEmail := fmt.Sprintf("%s-csrf-test@example.com", uuid.New().String()[:8])
-	user, err := userService.CreateUser(ctx, &models.CreateUserRequest{
+	user, err := userService.CreateUser(ctx, testAdminActor, &models.CreateUserRequest{
 		Email:    uniqueEmail,
 		Password: testPassword,
 		FirstName: "CSRF Test User", LastName: "Lastname",
@@ -206,7 +206,7 @@ func TestGetCSRFTokenEndpoint(t *testing.T) {
 
 	testPassword := "TestPassword123!"
 	uniqueEmail := fmt.Sprintf("%s-csrf-endpoint-test@example.com", uuid.New().String()[:8])
-	user, err := userService.CreateUser(ctx, &models.CreateUserRequest{
+	user, err := userService.CreateUser(ctx, testAdminActor, &models.CreateUserRequest{
 		Email:    uniqueEmail,
 		Password: testPassword,
 		FirstName: "CSRF Endpoint Test User", LastName: "Lastname",
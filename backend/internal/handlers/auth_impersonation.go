@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/pkg/response"
+)
+
+const (
+	// impersonatorCookieName cookie с сессией администратора на время имперсонации
+	impersonatorCookieName = "impersonator_session"
+	// impersonatorCookiePath cookie администратора отправляется только на эндпоинты имперсонации
+	impersonatorCookiePath = "/api/v1/auth/impersonation"
+)
+
+// StartImpersonation обрабатывает POST /api/v1/users/{id}/impersonate
+// @Summary      Start impersonation ("view as user")
+// @Description  Opens a separate time-limited session as the user (read-only unless allow_write). The admin session is kept and restored by POST /auth/impersonation/stop. Admins cannot be impersonated
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                            true   "User ID"
+// @Param        request  body      models.StartImpersonationRequest  false  "Duration (minutes, default 30, max 60), allow_write, reason"
+// @Success      200  {object}  response.SuccessResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /users/{id}/impersonate [post]
+func (h *AuthHandler) StartImpersonation(w http.ResponseWriter, r *http.Request) {
+	adminSession, ok := middleware.GetSessionFromContext(r.Context())
+	if !ok || adminSession == nil {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+	adminCookie, err := r.Cookie("session")
+	if err != nil {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	targetID, ok := parseUUIDParam(w, r, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req models.StartImpersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	resp, err := h.authService.StartImpersonation(r.Context(), adminSession, targetID, &req, r.RemoteAddr, r.Header.Get("User-Agent"))
+	if err != nil {
+		h.handleImpersonationError(w, err)
+		return
+	}
+
+	maxAge := int(time.Until(resp.Session.ExpiresAt).Seconds())
+	// Сессия администратора сохраняется, чтобы вернуться в нее после завершения имперсонации
+	h.setSessionCookie(w, impersonatorCookieName, adminCookie.Value, impersonatorCookiePath, maxAge)
+	h.setSessionCookie(w, "session", resp.SessionToken, "/", maxAge)
+
+	var csrfToken string
+	if h.csrfStore != nil {
+		token, err := h.csrfStore.GenerateToken(resp.Session.ID.String())
+		if err != nil {
+			response.InternalError(w, "Failed to generate CSRF token")
+			return
+		}
+		csrfToken = token
+		w.Header().Set("X-CSRF-Token", csrfToken)
+	}
+
+	response.OK(w, map[string]interface{}{
+		"user": resp.User,
+		"impersonation": &models.ImpersonationInfo{
+			ImpersonatorID:   adminSession.UserID,
+			ImpersonatorName: adminSession.UserName,
+			ExpiresAt:        resp.Session.ExpiresAt,
+			ReadOnly:         resp.Session.ReadOnly,
+		},
+		"csrf_token": csrfToken,
+	})
+}
+
+// StopImpersonation обрабатывает POST /api/v1/auth/impersonation/stop
+// @Summary      Stop impersonation
+// @Description  Ends the impersonation session and restores the admin session if it is still valid
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /auth/impersonation/stop [post]
+func (h *AuthHandler) StopImpersonation(w http.ResponseWriter, r *http.Request) {
+	session, ok := middleware.GetSessionFromContext(r.Context())
+	if !ok || session == nil {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	impersonatorToken := ""
+	if cookie, err := r.Cookie(impersonatorCookieName); err == nil {
+		impersonatorToken = cookie.Value
+	}
+
+	adminSession, err := h.authService.StopImpersonation(r.Context(), session, impersonatorToken)
+	if err != nil {
+		h.handleImpersonationError(w, err)
+		return
+	}
+
+	if h.csrfStore != nil {
+		h.csrfStore.DeleteToken(session.ID.String())
+	}
+	h.setSessionCookie(w, impersonatorCookieName, "", impersonatorCookiePath, -1)
+
+	if adminSession == nil {
+		// Сессия администратора истекла или отсутствует - требуется повторный вход
+		h.setSessionCookie(w, "session", "", "/", -1)
+		response.OK(w, map[string]interface{}{
+			"restored": false,
+		})
+		return
+	}
+
+	h.setSessionCookie(w, "session", impersonatorToken, "/", int(time.Until(adminSession.ExpiresAt).Seconds()))
+
+	var csrfToken string
+	if h.csrfStore != nil {
+		token, err := h.csrfStore.GenerateToken(adminSession.ID.String())
+		if err != nil {
+			response.InternalError(w, "Failed to generate CSRF token")
+			return
+		}
+		csrfToken = token
+		w.Header().Set("X-CSRF-Token", csrfToken)
+	}
+
+	response.OK(w, map[string]interface{}{
+		"restored":   true,
+		"csrf_token": csrfToken,
+	})
+}
+
+// setSessionCookie устанавливает HttpOnly cookie сессии (maxAge < 0 удаляет cookie)
+func (h *AuthHandler) setSessionCookie(w http.ResponseWriter, name, value, path string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		Secure:   h.isProduction,
+		HttpOnly: true,
+		SameSite: h.sameSite,
+	})
+}
+
+// handleImpersonationError обрабатывает ошибки имперсонации
+func (h *AuthHandler) handleImpersonationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidImpersonationRequest):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	case errors.Is(err, models.ErrNotImpersonating):
+		response.BadRequest(w, response.ErrCodeInvalidInput, err.Error())
+	case errors.Is(err, models.ErrImpersonateAdmin),
+		errors.Is(err, models.ErrImpersonateSelf),
+		errors.Is(err, models.ErrImpersonationNested),
+		errors.Is(err, models.ErrImpersonateHigherRole):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, repository.ErrUnauthorized):
+		response.Forbidden(w, "Admin access required")
+	case errors.Is(err, repository.ErrUserNotFound):
+		response.NotFound(w, "User not found")
+	default:
+		log.Printf("ERROR: Impersonation failed: %v", err)
+		response.InternalError(w, "Failed to process impersonation")
+	}
+}
@@ -58,16 +58,27 @@ func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Студенты создают бронирования для себя
-	// Администраторы и методисты могут создавать бронирования для любого студента
+	// С правом lessons.edit.all можно записать любого студента на любое занятие, с lessons.edit.own - на свое
 	if user.IsStudent() {
 		req.StudentID = user.ID
 		req.IsAdmin = false
-	} else if user.IsAdmin() || user.IsTeacher() {
+	} else if middleware.HasPermission(r.Context(), models.PermLessonsEditOwn, models.PermLessonsEditAll) {
 		// Админ/методист должен указать student_id в запросе
 		if req.StudentID == uuid.Nil {
 			response.BadRequest(w, response.ErrCodeInvalidInput, "student_id is required for admin or teacher")
 			return
 		}
+		if !middleware.HasPermission(r.Context(), models.PermLessonsEditAll) {
+			lesson, err := h.bookingService.GetLesson(r.Context(), req.LessonID)
+			if err != nil {
+				h.handleBookingError(w, err)
+				return
+			}
+			if !canManageLesson(r.Context(), user, lesson.TeacherID) {
+				response.Forbidden(w, "You can only book students into your own lessons")
+				return
+			}
+		}
 		req.IsAdmin = true
 		req.AdminID = user.ID // Track admin/teacher who created the booking
 	} else {
@@ -323,7 +334,19 @@ func (h *BookingHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
 	req := &models.CancelBookingRequest{
 		BookingID: bookingID,
 		StudentID: user.ID,
-		IsAdmin:   user.IsAdmin() || user.IsTeacher(),
+	}
+
+	// С правом lessons.edit.all можно отменить любую запись, с lessons.edit.own - запись на свое занятие.
+	// Остальные отменяют только свои записи (проверяется сервисом)
+	if middleware.HasPermission(r.Context(), models.PermLessonsEditAll) {
+		req.IsAdmin = true
+	} else if middleware.HasPermission(r.Context(), models.PermLessonsEditOwn) {
+		booking, err := h.bookingService.GetBooking(r.Context(), bookingID)
+		if err != nil {
+			h.handleBookingError(w, err)
+			return
+		}
+		req.IsAdmin = canManageLesson(r.Context(), user, booking.TeacherID)
 	}
 
 	// Отменяем бронирование и получаем результат с информацией о статусе операции
@@ -34,13 +34,13 @@ func NewBroadcastHandler(broadcastService *service.BroadcastService, telegramSer
 // GetLinkedUsers обрабатывает GET /api/v1/admin/telegram/users
 // Возвращает список пользователей с привязанным Telegram (только для админов)
 func (h *BroadcastHandler) GetLinkedUsers(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -117,7 +117,7 @@ func (h *BroadcastHandler) CreateBroadcastList(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -156,13 +156,13 @@ func (h *BroadcastHandler) CreateBroadcastList(w http.ResponseWriter, r *http.Re
 // GetBroadcastLists обрабатывает GET /api/v1/admin/telegram/lists
 // Возвращает все списки рассылки
 func (h *BroadcastHandler) GetBroadcastLists(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -184,13 +184,13 @@ func (h *BroadcastHandler) GetBroadcastLists(w http.ResponseWriter, r *http.Requ
 // GetBroadcastListByID обрабатывает GET /api/v1/admin/telegram/lists/{id}
 // Возвращает конкретный список рассылки по ID
 func (h *BroadcastHandler) GetBroadcastListByID(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -218,13 +218,13 @@ func (h *BroadcastHandler) GetBroadcastListByID(w http.ResponseWriter, r *http.R
 // UpdateBroadcastList обрабатывает PUT /api/v1/admin/telegram/lists/{id}
 // Обновляет список рассылки
 func (h *BroadcastHandler) UpdateBroadcastList(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -278,13 +278,13 @@ func (h *BroadcastHandler) UpdateBroadcastList(w http.ResponseWriter, r *http.Re
 // DeleteBroadcastList обрабатывает DELETE /api/v1/admin/telegram/lists/{id}
 // Удаляет список рассылки (мягкое удаление)
 func (h *BroadcastHandler) DeleteBroadcastList(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -309,7 +309,8 @@ func (h *BroadcastHandler) DeleteBroadcastList(w http.ResponseWriter, r *http.Re
 }
 
 // SendBroadcast обрабатывает POST /api/v1/admin/telegram/broadcast
-// Создает и отправляет массовую рассылку
+// Создает и отправляет массовую рассылку.
+// Принимает JSON или multipart/form-data: поле "payload" с JSON запроса и файлы в поле "files"
 func (h *BroadcastHandler) SendBroadcast(w http.ResponseWriter, r *http.Request) {
 	user, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
@@ -317,7 +318,7 @@ func (h *BroadcastHandler) SendBroadcast(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -328,8 +329,8 @@ func (h *BroadcastHandler) SendBroadcast(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var req models.SendBroadcastRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	req, files, err := decodeSendBroadcastRequest(r)
+	if err != nil {
 		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
 		return
 	}
@@ -340,36 +341,21 @@ func (h *BroadcastHandler) SendBroadcast(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Создаем broadcast в зависимости от того, указан list_id или user_ids
-	var broadcast *models.Broadcast
-	var err error
+	// Создаем broadcast: получатели из list_id, user_ids или сохраненной аудитории, текст из message или шаблона
+	broadcast, err := h.broadcastService.CreateBroadcastFromRequest(r.Context(), req, files, user.ID)
+	if err != nil {
+		log.Printf("ERROR: Failed to create broadcast: %v", err)
+		h.handleBroadcastError(w, err)
+		return
+	}
 
-	if req.ListID != nil {
-		// Используем существующую логику с list_id
-		broadcast, err = h.broadcastService.CreateBroadcast(
-			r.Context(),
-			*req.ListID,
-			req.Message,
-			user.ID,
-		)
-		if err != nil {
-			log.Printf("ERROR: Failed to create broadcast: %v", err)
-			h.handleBroadcastError(w, err)
-			return
-		}
-	} else {
-		// Используем новую логику с user_ids
-		broadcast, err = h.broadcastService.CreateBroadcastForUsers(
-			r.Context(),
-			req.UserIDs,
-			req.Message,
-			user.ID,
-		)
-		if err != nil {
-			log.Printf("ERROR: Failed to create broadcast for users: %v", err)
-			h.handleBroadcastError(w, err)
-			return
-		}
+	// Отложенную рассылку запустит планировщик
+	if broadcast.IsScheduled() {
+		response.Created(w, map[string]interface{}{
+			"broadcast": broadcast,
+			"message":   "Broadcast scheduled successfully",
+		})
+		return
 	}
 
 	// Запускаем отправку (проверяем что Telegram настроен синхронно)
@@ -388,13 +374,13 @@ func (h *BroadcastHandler) SendBroadcast(w http.ResponseWriter, r *http.Request)
 // GetBroadcasts обрабатывает GET /api/v1/admin/telegram/broadcasts
 // Возвращает список всех рассылок с пагинацией
 func (h *BroadcastHandler) GetBroadcasts(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -444,13 +430,13 @@ type DeliveryInfo struct {
 // GetBroadcastDetails обрабатывает GET /api/v1/admin/telegram/broadcasts/{id}
 // Возвращает детали конкретной рассылки с логами
 func (h *BroadcastHandler) GetBroadcastDetails(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -518,13 +504,13 @@ func (h *BroadcastHandler) GetBroadcastDetails(w http.ResponseWriter, r *http.Re
 // CancelBroadcast обрабатывает POST /api/v1/admin/telegram/broadcasts/{id}/cancel
 // Отменяет запланированную или выполняющуюся рассылку
 func (h *BroadcastHandler) CancelBroadcast(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -560,6 +546,11 @@ func (h *BroadcastHandler) CancelBroadcast(w http.ResponseWriter, r *http.Reques
 
 // handleBroadcastError обрабатывает специфичные для broadcast ошибки
 func (h *BroadcastHandler) handleBroadcastError(w http.ResponseWriter, err error) {
+	// Вложение отклонено проверкой файла
+	if writeUploadRejection(w, err) {
+		return
+	}
+
 	// Проверяем ошибки repository
 	if errors.Is(err, repository.ErrBroadcastListNotFound) {
 		response.NotFound(w, "Broadcast list not found")
@@ -569,6 +560,14 @@ func (h *BroadcastHandler) handleBroadcastError(w http.ResponseWriter, err error
 		response.NotFound(w, "Broadcast not found")
 		return
 	}
+	if errors.Is(err, repository.ErrBroadcastTemplateNotFound) {
+		response.NotFound(w, "Broadcast template not found")
+		return
+	}
+	if errors.Is(err, repository.ErrBroadcastAudienceNotFound) {
+		response.NotFound(w, "Broadcast audience not found")
+		return
+	}
 
 	// Проверяем ошибки models (валидация)
 	if errors.Is(err, models.ErrInvalidBroadcastName) {
@@ -591,6 +590,25 @@ func (h *BroadcastHandler) handleBroadcastError(w http.ResponseWriter, err error
 		response.BadRequest(w, response.ErrCodeValidationFailed, "Broadcast message must not exceed 4096 characters")
 		return
 	}
+	if errors.Is(err, models.ErrUnknownBroadcastPlaceholder) ||
+		errors.Is(err, models.ErrInvalidBroadcastTemplateName) ||
+		errors.Is(err, models.ErrInvalidBroadcastAudienceName) ||
+		errors.Is(err, models.ErrInvalidAudienceRole) ||
+		errors.Is(err, models.ErrInvalidAudienceMaxBalance) ||
+		errors.Is(err, models.ErrInvalidAudienceInactiveDays) ||
+		errors.Is(err, models.ErrBroadcastAudienceConflict) ||
+		errors.Is(err, models.ErrBroadcastMessageOrTemplate) ||
+		errors.Is(err, models.ErrBroadcastScheduledInPast) ||
+		errors.Is(err, models.ErrInvalidBroadcastParseMode) ||
+		errors.Is(err, models.ErrInvalidBroadcastHTML) ||
+		errors.Is(err, models.ErrInvalidBroadcastButtons) ||
+		errors.Is(err, models.ErrInvalidBroadcastButton) ||
+		errors.Is(err, models.ErrInvalidBroadcastFileType) ||
+		errors.Is(err, models.ErrTooManyFiles) ||
+		errors.Is(err, models.ErrFileTooLarge) {
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+		return
+	}
 
 	// Проверяем ошибки service
 	if errors.Is(err, service.ErrBroadcastAlreadyInProgress) {
@@ -601,6 +619,12 @@ func (h *BroadcastHandler) handleBroadcastError(w http.ResponseWriter, err error
 		response.Conflict(w, response.ErrCodeConflict, "Cannot cancel broadcast in current status")
 		return
 	}
+	if errors.Is(err, service.ErrBroadcastNotDelivered) ||
+		errors.Is(err, service.ErrBroadcastRecalled) ||
+		errors.Is(err, service.ErrBroadcastUpdateInProgress) {
+		response.Conflict(w, response.ErrCodeConflict, err.Error())
+		return
+	}
 	if errors.Is(err, service.ErrUsersNotLinkedToTelegram) {
 		response.BadRequest(w, response.ErrCodeValidationFailed, "Some users are not linked to Telegram")
 		return
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/pkg/response"
+)
+
+// GetTemplates обрабатывает GET /api/v1/admin/telegram/templates
+// Возвращает все шаблоны рассылок
+func (h *BroadcastHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	_, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	templates, err := h.broadcastService.GetTemplates(r.Context())
+	if err != nil {
+		log.Printf("ERROR: Failed to retrieve broadcast templates: %v", err)
+		response.InternalError(w, "Failed to retrieve broadcast templates")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// CreateTemplate обрабатывает POST /api/v1/admin/telegram/templates
+// Создает шаблон рассылки с подстановками {first_name}, {last_name}, {balance}, {next_lesson}
+func (h *BroadcastHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	var req models.SaveBroadcastTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	template, err := h.broadcastService.CreateTemplate(r.Context(), &req, user.ID)
+	if err != nil {
+		log.Printf("ERROR: Failed to create broadcast template: %v", err)
+		h.handleBroadcastError(w, err)
+		return
+	}
+
+	response.Created(w, map[string]interface{}{
+		"template": template,
+	})
+}
+
+// UpdateTemplate обрабатывает PUT /api/v1/admin/telegram/templates/{id}
+// Обновляет шаблон рассылки
+func (h *BroadcastHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	_, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid template ID")
+		return
+	}
+
+	var req models.SaveBroadcastTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	template, err := h.broadcastService.UpdateTemplate(r.Context(), templateID, &req)
+	if err != nil {
+		log.Printf("ERROR: Failed to update broadcast template %s: %v", templateID, err)
+		h.handleBroadcastError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"template": template,
+	})
+}
+
+// DeleteTemplate обрабатывает DELETE /api/v1/admin/telegram/templates/{id}
+// Удаляет шаблон рассылки (мягкое удаление)
+func (h *BroadcastHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	_, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid template ID")
+		return
+	}
+
+	if err := h.broadcastService.DeleteTemplate(r.Context(), templateID); err != nil {
+		log.Printf("ERROR: Failed to delete broadcast template %s: %v", templateID, err)
+		h.handleBroadcastError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]string{
+		"message": "Broadcast template deleted successfully",
+	})
+}
+
+// GetAudiences обрабатывает GET /api/v1/admin/telegram/audiences
+// Возвращает все сохраненные аудитории
+func (h *BroadcastHandler) GetAudiences(w http.ResponseWriter, r *http.Request) {
+	_, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	audiences, err := h.broadcastService.GetAudiences(r.Context())
+	if err != nil {
+		log.Printf("ERROR: Failed to retrieve broadcast audiences: %v", err)
+		response.InternalError(w, "Failed to retrieve broadcast audiences")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"audiences": audiences,
+		"count":     len(audiences),
+	})
+}
+
+// CreateAudience обрабатывает POST /api/v1/admin/telegram/audiences
+// Создает сохраненную аудиторию с фильтром получателей
+func (h *BroadcastHandler) CreateAudience(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	var req models.SaveBroadcastAudienceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	audience, err := h.broadcastService.CreateAudience(r.Context(), &req, user.ID)
+	if err != nil {
+		log.Printf("ERROR: Failed to create broadcast audience: %v", err)
+		h.handleBroadcastError(w, err)
+		return
+	}
+
+	response.Created(w, map[string]interface{}{
+		"audience": audience,
+	})
+}
+
+// UpdateAudience обрабатывает PUT /api/v1/admin/telegram/audiences/{id}
+// Обновляет сохраненную аудиторию
+func (h *BroadcastHandler) UpdateAudience(w http.ResponseWriter, r *http.Request) {
+	_, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	audienceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid audience ID")
+		return
+	}
+
+	var req models.SaveBroadcastAudienceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	audience, err := h.broadcastService.UpdateAudience(r.Context(), audienceID, &req)
+	if err != nil {
+		log.Printf("ERROR: Failed to update broadcast audience %s: %v", audienceID, err)
+		h.handleBroadcastError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"audience": audience,
+	})
+}
+
+// DeleteAudience обрабатывает DELETE /api/v1/admin/telegram/audiences/{id}
+// Удаляет сохраненную аудиторию (мягкое удаление)
+func (h *BroadcastHandler) DeleteAudience(w http.ResponseWriter, r *http.Request) {
+	_, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	audienceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid audience ID")
+		return
+	}
+
+	if err := h.broadcastService.DeleteAudience(r.Context(), audienceID); err != nil {
+		log.Printf("ERROR: Failed to delete broadcast audience %s: %v", audienceID, err)
+		h.handleBroadcastError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]string{
+		"message": "Broadcast audience deleted successfully",
+	})
+}
+
+// PreviewAudience обрабатывает POST /api/v1/admin/telegram/audiences/preview
+// Возвращает количество получателей, подходящих под фильтр на текущий момент
+func (h *BroadcastHandler) PreviewAudience(w http.ResponseWriter, r *http.Request) {
+	_, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	var filter models.AudienceFilter
+	if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	count, err := h.broadcastService.PreviewAudience(r.Context(), filter)
+	if err != nil {
+		log.Printf("ERROR: Failed to preview broadcast audience: %v", err)
+		h.handleBroadcastError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"recipient_count": count,
+	})
+}
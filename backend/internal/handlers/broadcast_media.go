@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/pkg/response"
+)
+
+// maxBroadcastUploadSize лимит multipart запроса рассылки: 10 файлов по 10MB + текст и метаданные
+const maxBroadcastUploadSize = 110 * 1024 * 1024
+
+// decodeSendBroadcastRequest разбирает запрос рассылки из JSON тела
+// или из multipart формы (JSON в поле "payload", вложения в поле "files")
+func decodeSendBroadcastRequest(r *http.Request) (*models.SendBroadcastRequest, []*multipart.FileHeader, error) {
+	var req models.SendBroadcastRequest
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, nil, err
+		}
+		return &req, nil, nil
+	}
+
+	if err := r.ParseMultipartForm(maxBroadcastUploadSize); err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal([]byte(r.FormValue("payload")), &req); err != nil {
+		return nil, nil, err
+	}
+
+	var files []*multipart.FileHeader
+	if r.MultipartForm != nil && r.MultipartForm.File != nil {
+		files = r.MultipartForm.File["files"]
+	}
+	return &req, files, nil
+}
+
+// EditBroadcastMessage обрабатывает PUT /api/v1/admin/telegram/broadcasts/{id}/message
+// Меняет текст доставленной рассылки в чатах получателей
+func (h *BroadcastHandler) EditBroadcastMessage(w http.ResponseWriter, r *http.Request) {
+	_, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	broadcastID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid broadcast ID")
+		return
+	}
+
+	var req models.EditBroadcastMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	broadcast, err := h.broadcastService.EditBroadcastMessage(r.Context(), broadcastID, &req)
+	if err != nil {
+		log.Printf("ERROR: Failed to edit broadcast %s: %v", broadcastID, err)
+		h.handleBroadcastError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"broadcast": broadcast,
+		"message":   "Broadcast edit started",
+	})
+}
+
+// RecallBroadcast обрабатывает DELETE /api/v1/admin/telegram/broadcasts/{id}/messages
+// Удаляет доставленные сообщения рассылки из чатов получателей
+func (h *BroadcastHandler) RecallBroadcast(w http.ResponseWriter, r *http.Request) {
+	_, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), models.PermBroadcastsManage) {
+		response.Forbidden(w, "Admin or teacher access required")
+		return
+	}
+
+	broadcastID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid broadcast ID")
+		return
+	}
+
+	if err := h.broadcastService.RecallBroadcast(r.Context(), broadcastID); err != nil {
+		log.Printf("ERROR: Failed to recall broadcast %s: %v", broadcastID, err)
+		h.handleBroadcastError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]string{
+		"message": "Broadcast recall started",
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"tutoring-platform/internal/models"
 
@@ -11,6 +12,10 @@ import (
 
 func TestSendBroadcastRequest_Validate(t *testing.T) {
 	listID := uuid.New()
+	audienceID := uuid.New()
+	templateID := uuid.New()
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Minute)
 
 	tests := []struct {
 		name    string
@@ -68,6 +73,105 @@ func TestSendBroadcastRequest_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid with audience_id and template_id",
+			req: models.SendBroadcastRequest{
+				AudienceID: &audienceID,
+				TemplateID: &templateID,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid - audience_id with list_id",
+			req: models.SendBroadcastRequest{
+				ListID:     &listID,
+				AudienceID: &audienceID,
+				Message:    "Test message",
+			},
+			wantErr: true,
+			errMsg:  models.ErrBroadcastAudienceConflict.Error(),
+		},
+		{
+			name: "invalid - both message and template_id",
+			req: models.SendBroadcastRequest{
+				ListID:     &listID,
+				TemplateID: &templateID,
+				Message:    "Test message",
+			},
+			wantErr: true,
+			errMsg:  models.ErrBroadcastMessageOrTemplate.Error(),
+		},
+		{
+			name: "invalid - unknown placeholder",
+			req: models.SendBroadcastRequest{
+				ListID:  &listID,
+				Message: "Привет, {name}!",
+			},
+			wantErr: true,
+			errMsg:  models.ErrUnknownBroadcastPlaceholder.Error(),
+		},
+		{
+			name: "valid - scheduled in the future",
+			req: models.SendBroadcastRequest{
+				ListID:      &listID,
+				Message:     "Привет, {first_name}!",
+				ScheduledAt: &future,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid - scheduled in the past",
+			req: models.SendBroadcastRequest{
+				ListID:      &listID,
+				Message:     "Test message",
+				ScheduledAt: &past,
+			},
+			wantErr: true,
+			errMsg:  models.ErrBroadcastScheduledInPast.Error(),
+		},
+		{
+			name: "valid - HTML with buttons",
+			req: models.SendBroadcastRequest{
+				ListID:    &listID,
+				Message:   "<b>Скидка</b> для {first_name}",
+				ParseMode: models.BroadcastParseModeHTML,
+				Buttons: models.BroadcastKeyboard{{
+					{Text: "Открыть", URL: "https://example.com/offer"},
+					{Text: "Не интересно", CallbackData: "offer:decline"},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid - unknown parse mode",
+			req: models.SendBroadcastRequest{
+				ListID:    &listID,
+				Message:   "Test message",
+				ParseMode: "Markdown",
+			},
+			wantErr: true,
+			errMsg:  models.ErrInvalidBroadcastParseMode.Error(),
+		},
+		{
+			name: "invalid - unclosed HTML tag",
+			req: models.SendBroadcastRequest{
+				ListID:    &listID,
+				Message:   "<b>Скидка",
+				ParseMode: models.BroadcastParseModeHTML,
+			},
+			wantErr: true,
+			errMsg:  models.ErrInvalidBroadcastHTML.Error(),
+		},
+		{
+			name: "invalid - button without action",
+			req: models.SendBroadcastRequest{
+				ListID:  &listID,
+				Message: "Test message",
+				Buttons: models.BroadcastKeyboard{{{Text: "Кнопка"}}},
+			},
+			wantErr: true,
+			errMsg:  models.ErrInvalidBroadcastButton.Error(),
+		},
 	}
 
 	for _, tt := range tests {
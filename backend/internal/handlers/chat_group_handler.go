@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/pkg/response"
+)
+
+// GetParticipants returns participants of a chat room with their roles and mute state
+// @Summary      Chat room participants
+// @Tags         chat
+// @Produce      json
+// @Param        roomId  path      string  true  "Room ID"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.ChatRoomParticipant}
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/rooms/{roomId}/participants [get]
+func (h *ChatHandler) GetParticipants(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	roomID, ok := parseUUIDParam(w, r, "roomId", "Invalid room ID format")
+	if !ok {
+		return
+	}
+
+	canReadAll := middleware.HasPermission(r.Context(), models.PermChatReadAll)
+	participants, err := h.chatService.GetRoomParticipants(r.Context(), roomID, user.ID, canReadAll)
+	if err != nil {
+		h.handleGroupError(w, err)
+		return
+	}
+
+	response.OK(w, participants)
+}
+
+// MuteParticipant mutes a participant of a group room
+// @Summary      Mute chat participant
+// @Description  Room owners and moderators (or channel managers) can mute members; only owners can mute moderators
+// @Tags         chat
+// @Accept       json
+// @Produce      json
+// @Param        roomId   path      string                  true  "Room ID"
+// @Param        userId   path      string                  true  "Participant user ID"
+// @Param        request  body      models.ChatMuteRequest  true  "Mute duration in minutes"
+// @Success      200  {object}  response.SuccessResponse{data=models.ChatRoomParticipant}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/rooms/{roomId}/participants/{userId}/mute [post]
+func (h *ChatHandler) MuteParticipant(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	roomID, ok := parseUUIDParam(w, r, "roomId", "Invalid room ID format")
+	if !ok {
+		return
+	}
+	userID, ok := parseUUIDParam(w, r, "userId", "Invalid user ID format")
+	if !ok {
+		return
+	}
+
+	var req models.ChatMuteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	canManage := middleware.HasPermission(r.Context(), models.PermChatChannelsManage)
+	participant, err := h.chatService.MuteParticipant(r.Context(), user.ID, roomID, userID, canManage, &req)
+	if err != nil {
+		h.handleGroupError(w, err)
+		return
+	}
+
+	response.OK(w, participant)
+}
+
+// UnmuteParticipant removes the mute of a group room participant
+// @Summary      Unmute chat participant
+// @Tags         chat
+// @Produce      json
+// @Param        roomId  path      string  true  "Room ID"
+// @Param        userId  path      string  true  "Participant user ID"
+// @Success      200  {object}  response.SuccessResponse{data=models.ChatRoomParticipant}
+// @Failure      403  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/rooms/{roomId}/participants/{userId}/mute [delete]
+func (h *ChatHandler) UnmuteParticipant(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	roomID, ok := parseUUIDParam(w, r, "roomId", "Invalid room ID format")
+	if !ok {
+		return
+	}
+	userID, ok := parseUUIDParam(w, r, "userId", "Invalid user ID format")
+	if !ok {
+		return
+	}
+
+	canManage := middleware.HasPermission(r.Context(), models.PermChatChannelsManage)
+	participant, err := h.chatService.UnmuteParticipant(r.Context(), user.ID, roomID, userID, canManage)
+	if err != nil {
+		h.handleGroupError(w, err)
+		return
+	}
+
+	response.OK(w, participant)
+}
+
+// SetParticipantRole promotes a participant to moderator or demotes to member
+// @Summary      Change chat participant role
+// @Description  Available to the room owner and channel managers
+// @Tags         chat
+// @Accept       json
+// @Produce      json
+// @Param        roomId   path      string                             true  "Room ID"
+// @Param        userId   path      string                             true  "Participant user ID"
+// @Param        request  body      models.ChatParticipantRoleRequest  true  "Role: moderator or member"
+// @Success      200  {object}  response.SuccessResponse{data=models.ChatRoomParticipant}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/rooms/{roomId}/participants/{userId}/role [put]
+func (h *ChatHandler) SetParticipantRole(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	roomID, ok := parseUUIDParam(w, r, "roomId", "Invalid room ID format")
+	if !ok {
+		return
+	}
+	userID, ok := parseUUIDParam(w, r, "userId", "Invalid user ID format")
+	if !ok {
+		return
+	}
+
+	var req models.ChatParticipantRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	canManage := middleware.HasPermission(r.Context(), models.PermChatChannelsManage)
+	participant, err := h.chatService.SetParticipantRole(r.Context(), user.ID, roomID, userID, canManage, &req)
+	if err != nil {
+		h.handleGroupError(w, err)
+		return
+	}
+
+	response.OK(w, participant)
+}
+
+// CreateChannel creates an announcement channel
+// @Summary      Create announcement channel
+// @Description  Only owners and moderators can post in a channel; the creator becomes its owner. Active members of student_group_id join as members
+// @Tags         chat
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateChannelRequest  true  "Channel"
+// @Success      201  {object}  response.SuccessResponse{data=models.ChatRoom}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/chat/channels [post]
+func (h *ChatHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var req models.CreateChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	room, err := h.chatService.CreateChannel(r.Context(), user.ID, &req)
+	if err != nil {
+		h.handleGroupError(w, err)
+		return
+	}
+
+	response.Created(w, room)
+}
+
+// AddParticipants adds users to a group room
+// @Summary      Add chat room participants
+// @Tags         chat
+// @Accept       json
+// @Produce      json
+// @Param        roomId   path      string                          true  "Room ID"
+// @Param        request  body      models.ChatParticipantsRequest  true  "User IDs"
+// @Success      200  {object}  response.SuccessResponse{data=models.ChatRoom}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/chat/rooms/{roomId}/participants [post]
+func (h *ChatHandler) AddParticipants(w http.ResponseWriter, r *http.Request) {
+	roomID, ok := parseUUIDParam(w, r, "roomId", "Invalid room ID format")
+	if !ok {
+		return
+	}
+
+	var req models.ChatParticipantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	room, err := h.chatService.AddParticipants(r.Context(), roomID, &req)
+	if err != nil {
+		h.handleGroupError(w, err)
+		return
+	}
+
+	response.OK(w, room)
+}
+
+// RemoveParticipant removes a user from a group room
+// @Summary      Remove chat room participant
+// @Tags         chat
+// @Param        roomId  path  string  true  "Room ID"
+// @Param        userId  path  string  true  "Participant user ID"
+// @Success      204
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/chat/rooms/{roomId}/participants/{userId} [delete]
+func (h *ChatHandler) RemoveParticipant(w http.ResponseWriter, r *http.Request) {
+	roomID, ok := parseUUIDParam(w, r, "roomId", "Invalid room ID format")
+	if !ok {
+		return
+	}
+	userID, ok := parseUUIDParam(w, r, "userId", "Invalid user ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.chatService.RemoveParticipant(r.Context(), roomID, userID); err != nil {
+		h.handleGroupError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// SyncLessonGroup creates (if missing) the chat room of a recurring group lesson series and syncs members with bookings
+// @Summary      Sync lesson group chat
+// @Tags         chat
+// @Produce      json
+// @Param        recurringGroupId  path      string  true  "Recurring group ID of the lesson series"
+// @Success      200  {object}  response.SuccessResponse{data=models.ChatRoom}
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/chat/lesson-groups/{recurringGroupId}/sync [post]
+func (h *ChatHandler) SyncLessonGroup(w http.ResponseWriter, r *http.Request) {
+	recurringGroupID, ok := parseUUIDParam(w, r, "recurringGroupId", "Invalid recurring group ID format")
+	if !ok {
+		return
+	}
+
+	room, err := h.chatService.SyncLessonGroup(r.Context(), recurringGroupID)
+	if err != nil {
+		h.handleGroupError(w, err)
+		return
+	}
+
+	response.OK(w, room)
+}
+
+// handleGroupError maps group room errors to HTTP responses
+func (h *ChatHandler) handleGroupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidChatChannel),
+		errors.Is(err, models.ErrInvalidChatParticipants),
+		errors.Is(err, models.ErrInvalidChatMute),
+		errors.Is(err, models.ErrInvalidChatParticipantRole),
+		errors.Is(err, models.ErrChatNotGroupRoom):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	case errors.Is(err, repository.ErrChatRoomNotFound), errors.Is(err, repository.ErrChatParticipantNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, repository.ErrUnauthorized), errors.Is(err, models.ErrChatNotRoomStaff):
+		response.Forbidden(w, "Access denied")
+	case errors.Is(err, models.ErrChatOwnerLocked):
+		response.Conflict(w, response.ErrCodeConflict, err.Error())
+	default:
+		log.Error().Err(err).Msg("Chat group room request failed")
+		response.InternalError(w, "Failed to process chat room request")
+	}
+}
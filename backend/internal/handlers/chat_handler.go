@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"io"
 	"mime"
 	"net/http"
@@ -26,6 +28,7 @@ type ChatHandler struct {
 	chatService  *service.ChatService
 	uploadDir    string
 	uploadDirAbs string
+	fileStorage  *service.FileStorageService
 }
 
 // NewChatHandler creates a new ChatHandler
@@ -42,6 +45,11 @@ func NewChatHandler(chatService *service.ChatService, uploadDir string) *ChatHan
 	}
 }
 
+// SetFileStorage stores new attachments in the BlobStore instead of uploadDir
+func (h *ChatHandler) SetFileStorage(fileStorage *service.FileStorageService) {
+	h.fileStorage = fileStorage
+}
+
 // GetMyRooms retrieves list of chat rooms for the current user
 func (h *ChatHandler) GetMyRooms(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -162,8 +170,24 @@ func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		RoomID:      parsedRoomID,
 		MessageText: messageText,
 	}
+	if replyTo := r.FormValue("reply_to_message_id"); replyTo != "" {
+		replyToID, err := uuid.Parse(replyTo)
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid reply_to_message_id format")
+			return
+		}
+		sendReq.ReplyToMessageID = &replyToID
+	}
 
 	message, err := h.chatService.SendMessage(ctx, session.UserID, sendReq)
+	if errors.Is(err, models.ErrInvalidReplyTarget) {
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+		return
+	}
+	if errors.Is(err, models.ErrChatParticipantMuted) || errors.Is(err, models.ErrChatChannelReadOnly) {
+		response.Forbidden(w, err.Error())
+		return
+	}
 	if err != nil {
 		// Логируем полную ошибку для диагностики
 		log.Error().Err(err).
@@ -179,15 +203,18 @@ func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	files := r.MultipartForm.File["files"]
 	if len(files) > 0 {
 		uploadsDir := h.uploadDir
-		if err := os.MkdirAll(uploadsDir, 0755); err != nil {
-			// Логируем полную ошибку для диагностики
-			log.Error().Err(err).
-				Str("directory", uploadsDir).
-				Str("method", "SendMessage:MkdirAll").
-				Msg("Failed to create uploads directory")
-			// Возвращаем клиенту только обобщённое сообщение
-			response.BadRequest(w, response.ErrCodeChatFileUploadFailed, "Unable to process file uploads")
-			return
+		// Без BlobStore файлы сохраняются в локальную директорию
+		if h.fileStorage == nil {
+			if err := os.MkdirAll(uploadsDir, 0755); err != nil {
+				// Логируем полную ошибку для диагностики
+				log.Error().Err(err).
+					Str("directory", uploadsDir).
+					Str("method", "SendMessage:MkdirAll").
+					Msg("Failed to create uploads directory")
+				// Возвращаем клиенту только обобщённое сообщение
+				response.BadRequest(w, response.ErrCodeChatFileUploadFailed, "Unable to process file uploads")
+				return
+			}
 		}
 
 		for _, fileHeader := range files {
@@ -223,6 +250,38 @@ func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 			}
 			defer file.Close()
 
+			if h.fileStorage != nil {
+				blob, err := h.fileStorage.Save(ctx, models.StoredFileSourceChat, session.UserID, fileHeader.Filename, file, mimeType)
+				if err != nil {
+					if writeUploadRejection(w, err) {
+						return
+					}
+					response.BadRequest(w, response.ErrCodeChatFileUploadFailed, "Unable to save file")
+					return
+				}
+				attachment := &models.FileAttachment{
+					MessageID:   message.ID,
+					FileName:    fileHeader.Filename,
+					FilePath:    blob.Key,
+					FileSize:    blob.Size,
+					MimeType:    mimeType,
+					StorageKey:  sql.NullString{String: blob.Key, Valid: true},
+					ContentHash: sql.NullString{String: blob.Hash, Valid: true},
+				}
+				if err := h.chatService.CreateAttachment(ctx, session.UserID, attachment); err != nil {
+					h.fileStorage.ReleaseQuietly(ctx, blob.Key)
+					log.Error().Err(err).
+						Str("message_id", message.ID.String()).
+						Str("filename", fileHeader.Filename).
+						Str("method", "SendMessage:CreateAttachment").
+						Msg("Failed to save attachment metadata")
+					response.BadRequest(w, response.ErrCodeChatFileUploadFailed, "Unable to attach file")
+					return
+				}
+				h.fileStorage.GenerateThumbnailAsync(ctx, models.StoredFileSourceChat, attachment.ID, blob, mimeType)
+				continue
+			}
+
 			filename := uuid.New().String() + filepath.Ext(filepath.Base(filepath.Clean(fileHeader.Filename)))
 			filePath := filepath.Join(uploadsDir, filename)
 			dst, err := os.Create(filePath)
@@ -350,39 +409,14 @@ func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 
 // DownloadFile downloads a file from a message
 func (h *ChatHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	session, ok := middleware.GetSessionFromContext(ctx)
-	if !ok || session == nil {
-		response.Unauthorized(w, "Session not found")
-		return
-	}
-
-	roomID := chi.URLParam(r, "roomId")
-	fileID := chi.URLParam(r, "fileId")
-
-	parsedRoomID, err := uuid.Parse(roomID)
-	if err != nil {
-		log.Debug().Err(err).Str("room_id", roomID).Msg("Invalid room ID format")
-		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid room ID format")
+	attachment, ok := h.attachmentForDownload(w, r)
+	if !ok {
 		return
 	}
 
-	parsedFileID, err := uuid.Parse(fileID)
-	if err != nil {
-		log.Debug().Err(err).Str("file_id", fileID).Msg("Invalid file ID format")
-		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid file ID format")
-		return
-	}
-
-	attachment, err := h.chatService.GetAttachmentByID(ctx, session.UserID, parsedRoomID, parsedFileID)
-	if err != nil {
-		log.Error().Err(err).
-			Str("user_id", session.UserID.String()).
-			Str("file_id", parsedFileID.String()).
-			Str("room_id", parsedRoomID.String()).
-			Str("method", "GetAttachmentByID").
-			Msg("Failed to retrieve attachment metadata")
-		response.BadRequest(w, response.ErrCodeChatFileNotFound, "File not found")
+	// Вложения, загруженные после перехода на BlobStore, отдаются из хранилища
+	if attachment.StorageKey.Valid && h.fileStorage != nil {
+		serveStoredFile(w, r, h.fileStorage, attachment.StorageKey.String, attachment.FileName, attachment.MimeType)
 		return
 	}
 
@@ -435,6 +469,62 @@ func (h *ChatHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, fullPath)
 }
 
+// DownloadThumbnail serves the JPEG preview of an image or PDF attachment
+func (h *ChatHandler) DownloadThumbnail(w http.ResponseWriter, r *http.Request) {
+	attachment, ok := h.attachmentForDownload(w, r)
+	if !ok {
+		return
+	}
+
+	// Превью строится в фоне после загрузки и есть только у файлов в BlobStore
+	if !attachment.ThumbnailKey.Valid || h.fileStorage == nil {
+		response.NotFound(w, "Thumbnail not available")
+		return
+	}
+	serveStoredThumbnail(w, r, h.fileStorage, attachment.ThumbnailKey.String)
+}
+
+// attachmentForDownload resolves the attachment from the URL and checks that the user may access the room.
+// Writes the error response and returns false on failure
+func (h *ChatHandler) attachmentForDownload(w http.ResponseWriter, r *http.Request) (*models.FileAttachment, bool) {
+	ctx := r.Context()
+	session, ok := middleware.GetSessionFromContext(ctx)
+	if !ok || session == nil {
+		response.Unauthorized(w, "Session not found")
+		return nil, false
+	}
+
+	roomID := chi.URLParam(r, "roomId")
+	fileID := chi.URLParam(r, "fileId")
+
+	parsedRoomID, err := uuid.Parse(roomID)
+	if err != nil {
+		log.Debug().Err(err).Str("room_id", roomID).Msg("Invalid room ID format")
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid room ID format")
+		return nil, false
+	}
+
+	parsedFileID, err := uuid.Parse(fileID)
+	if err != nil {
+		log.Debug().Err(err).Str("file_id", fileID).Msg("Invalid file ID format")
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid file ID format")
+		return nil, false
+	}
+
+	attachment, err := h.chatService.GetAttachmentByID(ctx, session.UserID, parsedRoomID, parsedFileID)
+	if err != nil {
+		log.Error().Err(err).
+			Str("user_id", session.UserID.String()).
+			Str("file_id", parsedFileID.String()).
+			Str("room_id", parsedRoomID.String()).
+			Str("method", "GetAttachmentByID").
+			Msg("Failed to retrieve attachment metadata")
+		response.BadRequest(w, response.ErrCodeChatFileNotFound, "File not found")
+		return nil, false
+	}
+	return attachment, true
+}
+
 // ListAllChats returns all chats for admin panel
 func (h *ChatHandler) ListAllChats(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -444,11 +534,11 @@ func (h *ChatHandler) ListAllChats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if session.UserRole != models.RoleAdmin {
+	if !middleware.HasPermission(ctx, models.PermChatReadAll) {
 		log.Warn().
 			Str("user_id", session.UserID.String()).
 			Str("role", string(session.UserRole)).
-			Msg("Non-admin user attempted to access ListAllChats")
+			Msg("User without chat.read.all attempted to access ListAllChats")
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -17,6 +17,7 @@ import (
 	"tutoring-platform/internal/service"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -160,6 +161,11 @@ func (m *MockUserRepository) Create(ctx context.Context, user *models.User) erro
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	args := m.Called(ctx, tx, user)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
 	args := m.Called(ctx, id, updates)
 	return args.Error(0)
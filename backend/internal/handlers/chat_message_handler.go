@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/pkg/response"
+)
+
+// EditMessage edits the text of the current user's own message
+// @Summary      Edit chat message
+// @Description  Sender can edit a delivered message within 15 minutes. Previous text is kept in edit history. With moderation enabled the edited message is hidden until it passes moderation again; participants receive SSE message_edited
+// @Tags         chat
+// @Accept       json
+// @Produce      json
+// @Param        messageId  path      string                     true  "Message ID"
+// @Param        request    body      models.EditMessageRequest  true  "New text"
+// @Success      200  {object}  response.SuccessResponse{data=models.Message}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/messages/{messageId} [put]
+func (h *ChatHandler) EditMessage(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	messageID, ok := parseUUIDParam(w, r, "messageId", "Invalid message ID format")
+	if !ok {
+		return
+	}
+
+	var req models.EditMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	message, err := h.chatService.EditMessage(r.Context(), user.ID, messageID, &req)
+	if err != nil {
+		h.handleMessageError(w, err)
+		return
+	}
+
+	response.OK(w, message)
+}
+
+// GetMessageEdits returns edit history of a message
+// @Summary      Chat message edit history
+// @Tags         chat
+// @Produce      json
+// @Param        messageId  path      string  true  "Message ID"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.MessageEdit}
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/messages/{messageId}/edits [get]
+func (h *ChatHandler) GetMessageEdits(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	messageID, ok := parseUUIDParam(w, r, "messageId", "Invalid message ID format")
+	if !ok {
+		return
+	}
+
+	canReadAll := middleware.HasPermission(r.Context(), models.PermChatReadAll)
+	edits, err := h.chatService.GetMessageEdits(r.Context(), user.ID, messageID, canReadAll)
+	if err != nil {
+		h.handleMessageError(w, err)
+		return
+	}
+
+	response.OK(w, edits)
+}
+
+// GetReplies returns delivered replies to a message
+// @Summary      Replies to chat message
+// @Description  Thread view: delivered replies in chronological order, each with quoted preview and reactions
+// @Tags         chat
+// @Produce      json
+// @Param        messageId  path      string  true  "Message ID"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.Message}
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/messages/{messageId}/replies [get]
+func (h *ChatHandler) GetReplies(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	messageID, ok := parseUUIDParam(w, r, "messageId", "Invalid message ID format")
+	if !ok {
+		return
+	}
+
+	canReadAll := middleware.HasPermission(r.Context(), models.PermChatReadAll)
+	replies, err := h.chatService.GetReplies(r.Context(), user.ID, messageID, canReadAll)
+	if err != nil {
+		h.handleMessageError(w, err)
+		return
+	}
+
+	response.OK(w, replies)
+}
+
+// AddReaction adds an emoji reaction of the current user to a message
+// @Summary      Add reaction to chat message
+// @Description  Adds one of the allowed emoji; repeated reaction is a no-op. Participants receive SSE reaction_updated
+// @Tags         chat
+// @Accept       json
+// @Produce      json
+// @Param        messageId  path      string                  true  "Message ID"
+// @Param        request    body      models.ReactionRequest  true  "Emoji"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.MessageReactionSummary}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/messages/{messageId}/reactions [post]
+func (h *ChatHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	messageID, ok := parseUUIDParam(w, r, "messageId", "Invalid message ID format")
+	if !ok {
+		return
+	}
+
+	var req models.ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	reactions, err := h.chatService.AddReaction(r.Context(), user.ID, messageID, &req)
+	if err != nil {
+		h.handleMessageError(w, err)
+		return
+	}
+
+	response.OK(w, reactions)
+}
+
+// RemoveReaction removes an emoji reaction of the current user from a message
+// @Summary      Remove reaction from chat message
+// @Tags         chat
+// @Produce      json
+// @Param        messageId  path      string  true  "Message ID"
+// @Param        emoji      query     string  true  "Emoji"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.MessageReactionSummary}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/messages/{messageId}/reactions [delete]
+func (h *ChatHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	messageID, ok := parseUUIDParam(w, r, "messageId", "Invalid message ID format")
+	if !ok {
+		return
+	}
+
+	req := models.ReactionRequest{Emoji: r.URL.Query().Get("emoji")}
+	reactions, err := h.chatService.RemoveReaction(r.Context(), user.ID, messageID, &req)
+	if err != nil {
+		h.handleMessageError(w, err)
+		return
+	}
+
+	response.OK(w, reactions)
+}
+
+// handleMessageError maps message edit, reply and reaction errors to HTTP responses
+func (h *ChatHandler) handleMessageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrMessageContentEmpty), errors.Is(err, models.ErrMessageContentTooLong),
+		errors.Is(err, models.ErrInvalidReaction), errors.Is(err, models.ErrMessageEditWindowExpired):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	case errors.Is(err, repository.ErrChatRoomNotFound), errors.Is(err, repository.ErrMessageNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, repository.ErrUnauthorized):
+		response.Forbidden(w, "Access denied")
+	case errors.Is(err, models.ErrMessageNotEditable), errors.Is(err, models.ErrChatParticipantMuted),
+		errors.Is(err, models.ErrChatChannelReadOnly):
+		response.Forbidden(w, err.Error())
+	default:
+		log.Error().Err(err).Msg("Chat message request failed")
+		response.InternalError(w, "Failed to process chat request")
+	}
+}
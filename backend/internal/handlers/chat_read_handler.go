@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/pkg/response"
+)
+
+// MarkRead marks a chat room as read by the current user
+// @Summary      Mark chat room read
+// @Description  Moves the read marker forward to message_id (or to the latest delivered message) and sends SSE message_read to other participants
+// @Tags         chat
+// @Accept       json
+// @Produce      json
+// @Param        roomId   path      string                  true   "Room ID"
+// @Param        request  body      models.MarkReadRequest  false  "Last read message"
+// @Success      200  {object}  response.SuccessResponse{data=models.ChatReadState}
+// @Success      204  "Room has no messages"
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/rooms/{roomId}/read [post]
+func (h *ChatHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	roomID, ok := parseUUIDParam(w, r, "roomId", "Invalid room ID format")
+	if !ok {
+		return
+	}
+
+	// Тело необязательно: без message_id комната читается целиком
+	var req models.MarkReadRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+			return
+		}
+	}
+
+	state, err := h.chatService.MarkRoomRead(r.Context(), user.ID, roomID, &req)
+	if err != nil {
+		h.handleReadError(w, err)
+		return
+	}
+	if state == nil {
+		response.NoContent(w)
+		return
+	}
+
+	response.OK(w, state)
+}
+
+// GetReadStates returns read receipts of chat room participants
+// @Summary      Chat room read receipts
+// @Tags         chat
+// @Produce      json
+// @Param        roomId  path      string  true  "Room ID"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.ChatReadState}
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/rooms/{roomId}/reads [get]
+func (h *ChatHandler) GetReadStates(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	roomID, ok := parseUUIDParam(w, r, "roomId", "Invalid room ID format")
+	if !ok {
+		return
+	}
+
+	canReadAll := middleware.HasPermission(r.Context(), models.PermChatReadAll)
+	states, err := h.chatService.ListReadStates(r.Context(), roomID, user.ID, canReadAll)
+	if err != nil {
+		h.handleReadError(w, err)
+		return
+	}
+
+	response.OK(w, states)
+}
+
+// SendTyping broadcasts an ephemeral typing indicator to other room participants
+// @Summary      Typing indicator
+// @Description  Sends SSE typing event to other participants; nothing is stored. Clients repeat it every few seconds while typing
+// @Tags         chat
+// @Param        roomId  path  string  true  "Room ID"
+// @Success      204
+// @Failure      403  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/rooms/{roomId}/typing [post]
+func (h *ChatHandler) SendTyping(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	roomID, ok := parseUUIDParam(w, r, "roomId", "Invalid room ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.chatService.SendTyping(r.Context(), user.ID, roomID); err != nil {
+		h.handleReadError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// GetUnreadCount returns the total unread badge of the current user (optimized for polling)
+// @Summary      Unread chat messages (optimized for sidebar)
+// @Description  Total unread delivered messages and number of rooms with unread messages - optimized for frequent polling
+// @Tags         chat
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=models.ChatUnreadSummary}
+// @Failure      401  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/unread [get]
+func (h *ChatHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	// Как и /credits/balance: короткий клиентский кэш снижает нагрузку от частого опроса
+	w.Header().Set("Cache-Control", "private, max-age=5")
+
+	summary, err := h.chatService.GetUnreadSummary(r.Context(), user.ID)
+	if err != nil {
+		// Бейдж не критичен: при ошибке возвращаем нули, а не ошибку
+		log.Error().Err(err).Str("user_id", user.ID.String()).Msg("Failed to count unread chat messages")
+		response.OK(w, models.ChatUnreadSummary{})
+		return
+	}
+
+	response.OK(w, summary)
+}
+
+// handleReadError maps read receipt and typing errors to HTTP responses
+func (h *ChatHandler) handleReadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidMessageID):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	case errors.Is(err, repository.ErrChatRoomNotFound), errors.Is(err, repository.ErrMessageNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, repository.ErrUnauthorized):
+		response.Forbidden(w, "Access denied")
+	case errors.Is(err, models.ErrChatParticipantMuted), errors.Is(err, models.ErrChatChannelReadOnly):
+		response.Forbidden(w, err.Error())
+	default:
+		log.Error().Err(err).Msg("Chat read state request failed")
+		response.InternalError(w, "Failed to process chat request")
+	}
+}
@@ -35,9 +35,9 @@ func NewCreditHandler(creditService *service.CreditService, userService *service
 }
 
 // GetMyCredits обрабатывает GET /api/v1/credits
-// Возвращает собственный баланс для студентов, все балансы для админов
+// Возвращает собственный баланс, а с правом credits.read.all - балансы всех студентов
 // @Summary      Get credit balance
-// @Description  Get current credit balance (student: own balance, admin: all students)
+// @Description  Get current credit balance (own balance; all students with credits.read.all)
 // @Tags         credits
 // @Accept       json
 // @Produce      json
@@ -52,8 +52,8 @@ func (h *CreditHandler) GetMyCredits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if user.IsAdmin() {
-		// Админ может видеть все кредиты - получаем список пользователей и их балансы
+	if middleware.HasPermission(r.Context(), models.PermCreditsReadAll) {
+		// С правом credits.read.all видны все кредиты - получаем список пользователей и их балансы
 		users, err := h.userService.ListUsers(r.Context(), ptrUserRole(models.RoleStudent))
 		if err != nil {
 			response.InternalError(w, "Failed to retrieve credit balances")
@@ -139,11 +139,8 @@ func (h *CreditHandler) GetMyHistory(w http.ResponseWriter, r *http.Request) {
 
 	filter := &models.GetCreditHistoryFilter{}
 
-	// Студенты видят только свою историю
-	if user.IsStudent() {
-		filter.UserID = &user.ID
-	} else if user.IsAdmin() || user.IsTeacher() {
-		// Админы и методисты могут фильтровать по user_id, если указан
+	// С правом credits.read.all можно фильтровать по user_id, если указан; остальные видят только свою историю
+	if middleware.HasPermission(r.Context(), models.PermCreditsReadAll) {
 		if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
 			userID, err := uuid.Parse(userIDStr)
 			if err == nil {
@@ -151,7 +148,6 @@ func (h *CreditHandler) GetMyHistory(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	} else {
-		// Преподаватели видят только свою историю
 		filter.UserID = &user.ID
 	}
 
@@ -248,8 +244,8 @@ func (h *CreditHandler) AddCredits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Только админы могут добавлять/списывать кредиты
-	if !user.IsAdmin() {
+	// Начислять и списывать кредиты можно с правом credits.grant
+	if !middleware.HasPermission(r.Context(), models.PermCreditsGrant) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -421,14 +417,14 @@ func (h *CreditHandler) handleCreditError(w http.ResponseWriter, err error) {
 // @Security     SessionAuth
 // @Router       /credits/user/{id} [get]
 func (h *CreditHandler) GetUserCredits(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	// Только админы и методисты могут видеть баланс других пользователей
-	if !user.IsAdmin() && !user.IsTeacher() {
+	// Баланс других пользователей доступен с правом credits.read.all
+	if !middleware.HasPermission(r.Context(), models.PermCreditsReadAll) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -528,14 +524,14 @@ func (h *CreditHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
 // @Security     SessionAuth
 // @Router       /credits/all [get]
 func (h *CreditHandler) GetAllCredits(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	// Только админы и методисты могут получать все кредиты
-	if !user.IsAdmin() && !user.IsTeacher() {
+	// Все кредиты доступны с правом credits.read.all
+	if !middleware.HasPermission(r.Context(), models.PermCreditsReadAll) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -0,0 +1,449 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// GroupHandler обрабатывает эндпоинты учебных групп (admin only)
+type GroupHandler struct {
+	groupService *service.GroupService
+}
+
+// NewGroupHandler создает новый GroupHandler
+func NewGroupHandler(groupService *service.GroupService) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+	}
+}
+
+// ListGroups обрабатывает GET /api/v1/admin/groups
+// @Summary      List groups
+// @Description  Get all groups, optionally filtered by teacher
+// @Tags         groups
+// @Produce      json
+// @Param        teacher_id  query     string  false  "Teacher ID"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.GroupWithDetails}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups [get]
+func (h *GroupHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	var teacherID *uuid.UUID
+	if raw := r.URL.Query().Get("teacher_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid teacher ID")
+			return
+		}
+		teacherID = &id
+	}
+
+	groups, err := h.groupService.ListGroups(r.Context(), teacherID)
+	if err != nil {
+		response.InternalError(w, "Failed to retrieve groups")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"groups": groups,
+	})
+}
+
+// GetGroup обрабатывает GET /api/v1/admin/groups/{id}
+// @Summary      Get group
+// @Description  Get a group with its linked series and active members
+// @Tags         groups
+// @Produce      json
+// @Param        id   path      string  true  "Group ID"
+// @Success      200  {object}  response.SuccessResponse{data=interface{}}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups/{id} [get]
+func (h *GroupHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	group, err := h.groupService.GetGroup(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve group")
+		return
+	}
+
+	members, err := h.groupService.GetMembers(r.Context(), id, false)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve group members")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"group":   group,
+		"members": members,
+	})
+}
+
+// CreateGroup обрабатывает POST /api/v1/admin/groups
+// @Summary      Create group
+// @Tags         groups
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.CreateGroupRequest  true  "Group"
+// @Success      201   {object}  response.SuccessResponse{data=models.GroupWithDetails}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      500   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups [post]
+func (h *GroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var req models.CreateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	group, err := h.groupService.CreateGroup(r.Context(), user.ID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to create group")
+		return
+	}
+
+	response.Created(w, group)
+}
+
+// UpdateGroup обрабатывает PUT /api/v1/admin/groups/{id}
+// @Summary      Update group
+// @Description  Update name, description, teacher or subject. Existing lessons are not changed
+// @Tags         groups
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                     true  "Group ID"
+// @Param        body  body      models.UpdateGroupRequest  true  "Updated fields"
+// @Success      200   {object}  response.SuccessResponse{data=models.GroupWithDetails}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      404   {object}  response.ErrorResponse
+// @Failure      500   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups/{id} [put]
+func (h *GroupHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	group, err := h.groupService.UpdateGroup(r.Context(), id, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to update group")
+		return
+	}
+
+	response.OK(w, group)
+}
+
+// DeleteGroup обрабатывает DELETE /api/v1/admin/groups/{id}
+// @Summary      Delete group
+// @Description  Delete a group without active members
+// @Tags         groups
+// @Produce      json
+// @Param        id   path      string  true  "Group ID"
+// @Success      200  {object}  response.SuccessResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups/{id} [delete]
+func (h *GroupHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.DeleteGroup(r.Context(), id); err != nil {
+		h.handleError(w, err, "Failed to delete group")
+		return
+	}
+
+	response.OK(w, map[string]string{
+		"message": "Group deleted successfully",
+	})
+}
+
+// ListMembers обрабатывает GET /api/v1/admin/groups/{id}/members
+// @Summary      List group members
+// @Tags         groups
+// @Produce      json
+// @Param        id              path   string  true   "Group ID"
+// @Param        include_former  query  bool    false  "Include students who left the group"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.GroupMember}
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups/{id}/members [get]
+func (h *GroupHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := h.groupService.GetMembers(r.Context(), id, r.URL.Query().Get("include_former") == "true")
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve group members")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"members": members,
+	})
+}
+
+// AddMember обрабатывает POST /api/v1/admin/groups/{id}/members
+// @Summary      Add student to group
+// @Description  Add a student and enroll them in all future lessons of the linked series, deducting credits
+// @Tags         groups
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                        true  "Group ID"
+// @Param        body  body      models.AddGroupMemberRequest  true  "Student"
+// @Success      200   {object}  response.SuccessResponse{data=models.GroupEnrollmentResult}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      404   {object}  response.ErrorResponse
+// @Failure      409   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups/{id}/members [post]
+func (h *GroupHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AddGroupMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	result, err := h.groupService.AddMember(r.Context(), user.ID, id, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to add group member")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// RemoveMember обрабатывает DELETE /api/v1/admin/groups/{id}/members/{studentId}
+// @Summary      Remove student from group
+// @Description  Remove a student and unenroll them from future lessons of the linked series with refunds
+// @Tags         groups
+// @Produce      json
+// @Param        id         path      string  true  "Group ID"
+// @Param        studentId  path      string  true  "Student ID"
+// @Success      200  {object}  response.SuccessResponse{data=models.GroupEnrollmentResult}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups/{id}/members/{studentId} [delete]
+func (h *GroupHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	studentID, err := uuid.Parse(chi.URLParam(r, "studentId"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid student ID")
+		return
+	}
+
+	result, err := h.groupService.RemoveMember(r.Context(), user.ID, id, studentID)
+	if err != nil {
+		h.handleError(w, err, "Failed to remove group member")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// LinkSeries обрабатывает POST /api/v1/admin/groups/{id}/series
+// @Summary      Link recurring series to group
+// @Description  Link a recurring series and enroll all current members in its future lessons
+// @Tags         groups
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                         true  "Group ID"
+// @Param        body  body      models.LinkGroupSeriesRequest  true  "Series"
+// @Success      200   {object}  response.SuccessResponse{data=models.GroupEnrollmentResult}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      404   {object}  response.ErrorResponse
+// @Failure      409   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups/{id}/series [post]
+func (h *GroupHandler) LinkSeries(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.LinkGroupSeriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	result, err := h.groupService.LinkSeries(r.Context(), user.ID, id, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to link series")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// UnlinkSeries обрабатывает DELETE /api/v1/admin/groups/{id}/series/{seriesId}
+// @Summary      Unlink recurring series from group
+// @Description  Unlink a series and unenroll current members from its future lessons with refunds
+// @Tags         groups
+// @Produce      json
+// @Param        id        path      string  true  "Group ID"
+// @Param        seriesId  path      string  true  "Recurring group ID"
+// @Success      200  {object}  response.SuccessResponse{data=models.GroupEnrollmentResult}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups/{id}/series/{seriesId} [delete]
+func (h *GroupHandler) UnlinkSeries(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	seriesID, err := uuid.Parse(chi.URLParam(r, "seriesId"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid series ID")
+		return
+	}
+
+	result, err := h.groupService.UnlinkSeries(r.Context(), user.ID, id, seriesID)
+	if err != nil {
+		h.handleError(w, err, "Failed to unlink series")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// GetHistory обрабатывает GET /api/v1/admin/groups/{id}/history
+// @Summary      Group membership history
+// @Description  Audit trail of membership changes with lessons and credits affected
+// @Tags         groups
+// @Produce      json
+// @Param        id   path      string  true  "Group ID"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.GroupMembershipEvent}
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/groups/{id}/history [get]
+func (h *GroupHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := h.groupService.GetMembershipHistory(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve group history")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"events": events,
+	})
+}
+
+// handleError преобразует ошибки сервиса групп в HTTP ответы
+func (h *GroupHandler) handleError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrGroupNotFound):
+		response.NotFound(w, "Group not found")
+	case errors.Is(err, repository.ErrUserNotFound):
+		response.NotFound(w, "User not found")
+	case errors.Is(err, repository.ErrSubjectNotFound):
+		response.NotFound(w, "Subject not found")
+	case errors.Is(err, repository.ErrInsufficientCredits):
+		response.Conflict(w, response.ErrCodeInsufficientCredits, err.Error())
+	case errors.Is(err, repository.ErrLessonFull):
+		response.Conflict(w, response.ErrCodeLessonFull, err.Error())
+	case errors.Is(err, models.ErrAlreadyGroupMember),
+		errors.Is(err, models.ErrSeriesAlreadyLinked),
+		errors.Is(err, models.ErrGroupHasMembers):
+		response.Conflict(w, response.ErrCodeConflict, err.Error())
+	case errors.Is(err, models.ErrNotGroupMember),
+		errors.Is(err, models.ErrSeriesNotLinked):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, models.ErrInvalidGroupName),
+		errors.Is(err, models.ErrGroupDescriptionTooLong),
+		errors.Is(err, models.ErrInvalidTeacherID),
+		errors.Is(err, models.ErrInvalidStudentID),
+		errors.Is(err, models.ErrInvalidSubjectID),
+		errors.Is(err, models.ErrInvalidRecurringGroupID),
+		errors.Is(err, models.ErrSeriesHasNoFutureLessons):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	default:
+		response.InternalError(w, fallback)
+	}
+}
+
+// parseGroupID разбирает ID группы из URL
+func parseGroupID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid group ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -23,6 +23,7 @@ import (
 // HomeworkHandler обрабатывает HTTP запросы для домашних заданий
 type HomeworkHandler struct {
 	homeworkService *service.HomeworkService
+	fileStorage     *service.FileStorageService
 }
 
 // NewHomeworkHandler создает новый HomeworkHandler
@@ -32,6 +33,11 @@ func NewHomeworkHandler(homeworkService *service.HomeworkService) *HomeworkHandl
 	}
 }
 
+// SetFileStorage подключает BlobStore для скачивания файлов, загруженных после перехода на него
+func (h *HomeworkHandler) SetFileStorage(fileStorage *service.FileStorageService) {
+	h.fileStorage = fileStorage
+}
+
 // validateFilePath проверяет что путь безопасен для чтения
 // - должен находиться в разрешенной директории
 // - не должен содержать path traversal символы
@@ -168,6 +174,9 @@ func (h *HomeworkHandler) UploadHomework(w http.ResponseWriter, r *http.Request)
 		case models.ErrFileStorageFailed:
 			response.BadRequest(w, response.ErrCodeInternalError, "Failed to save file")
 		default:
+			if writeUploadRejection(w, err) {
+				return
+			}
 			// Проверка на ошибки из repository
 			if errors.Is(err, repository.ErrLessonNotFound) {
 				response.NotFound(w, "Lesson not found")
@@ -280,40 +289,14 @@ func (h *HomeworkHandler) DeleteHomework(w http.ResponseWriter, r *http.Request)
 // Требует авторизации
 // Доступ: те же правила что и для GetHomework
 func (h *HomeworkHandler) DownloadHomework(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	session, ok := middleware.GetSessionFromContext(ctx)
-	if !ok || session == nil {
-		response.Unauthorized(w, "Session not found")
-		return
-	}
-
-	// Получаем ID урока из URL параметра
-	lessonID := chi.URLParam(r, "id")
-	_, err := uuid.Parse(lessonID)
-	if err != nil {
-		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid lesson ID")
-		return
-	}
-
-	// Получаем ID файла домашнего задания из URL параметра
-	fileID := chi.URLParam(r, "file_id")
-	parsedFileID, err := uuid.Parse(fileID)
-	if err != nil {
-		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid file ID")
+	homework, ok := h.homeworkForDownload(w, r)
+	if !ok {
 		return
 	}
 
-	// Вызываем сервис для получения домашнего задания с проверкой доступа
-	homework, err := h.homeworkService.GetHomeworkByIDWithAccess(ctx, session.UserID, parsedFileID)
-	if err != nil {
-		// Обработка специфичных ошибок
-		if errors.Is(err, repository.ErrHomeworkNotFound) {
-			response.NotFound(w, "Homework file not found")
-		} else if errors.Is(err, repository.ErrUnauthorized) {
-			response.Forbidden(w, "You don't have permission to download this homework file")
-		} else {
-			response.InternalError(w, "Failed to get homework")
-		}
+	// Файлы, загруженные после перехода на BlobStore, отдаются из хранилища
+	if homework.StorageKey.Valid && h.fileStorage != nil {
+		serveStoredFile(w, r, h.fileStorage, homework.StorageKey.String, homework.FileName, homework.MimeType)
 		return
 	}
 
@@ -347,6 +330,66 @@ func (h *HomeworkHandler) DownloadHomework(w http.ResponseWriter, r *http.Reques
 	http.ServeFile(w, r, homework.FilePath)
 }
 
+// DownloadHomeworkThumbnail отдает превью изображения или первой страницы PDF домашнего задания
+// GET /api/v1/lessons/:id/homework/:file_id/thumbnail
+// Требует авторизации
+// Доступ: те же правила что и для DownloadHomework
+func (h *HomeworkHandler) DownloadHomeworkThumbnail(w http.ResponseWriter, r *http.Request) {
+	homework, ok := h.homeworkForDownload(w, r)
+	if !ok {
+		return
+	}
+
+	// Превью строится в фоне после загрузки и есть только у файлов в BlobStore
+	if !homework.ThumbnailKey.Valid || h.fileStorage == nil {
+		response.NotFound(w, "Thumbnail not available")
+		return
+	}
+	serveStoredThumbnail(w, r, h.fileStorage, homework.ThumbnailKey.String)
+}
+
+// homeworkForDownload получает домашнее задание из URL с проверкой доступа.
+// При ошибке пишет ответ и возвращает false
+func (h *HomeworkHandler) homeworkForDownload(w http.ResponseWriter, r *http.Request) (*models.LessonHomework, bool) {
+	ctx := r.Context()
+	session, ok := middleware.GetSessionFromContext(ctx)
+	if !ok || session == nil {
+		response.Unauthorized(w, "Session not found")
+		return nil, false
+	}
+
+	// Получаем ID урока из URL параметра
+	lessonID := chi.URLParam(r, "id")
+	_, err := uuid.Parse(lessonID)
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid lesson ID")
+		return nil, false
+	}
+
+	// Получаем ID файла домашнего задания из URL параметра
+	fileID := chi.URLParam(r, "file_id")
+	parsedFileID, err := uuid.Parse(fileID)
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid file ID")
+		return nil, false
+	}
+
+	// Вызываем сервис для получения домашнего задания с проверкой доступа
+	homework, err := h.homeworkService.GetHomeworkByIDWithAccess(ctx, session.UserID, parsedFileID)
+	if err != nil {
+		// Обработка специфичных ошибок
+		if errors.Is(err, repository.ErrHomeworkNotFound) {
+			response.NotFound(w, "Homework file not found")
+		} else if errors.Is(err, repository.ErrUnauthorized) {
+			response.Forbidden(w, "You don't have permission to download this homework file")
+		} else {
+			response.InternalError(w, "Failed to get homework")
+		}
+		return nil, false
+	}
+	return homework, true
+}
+
 // UpdateHomework обновляет описание домашнего задания
 // PATCH /api/v1/lessons/:id/homework/:file_id
 // Требует авторизации: admin, creator файла, или teacher урока
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// defaultImportRunsLimit количество записей журнала импортов по умолчанию
+const defaultImportRunsLimit = 50
+
+// ImportHandler обрабатывает эндпоинты массового импорта из CSV/XLSX
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+// NewImportHandler создает новый ImportHandler
+func NewImportHandler(importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// Import обрабатывает POST /api/v1/admin/import/{kind}
+// @Summary      Bulk import users, lessons or bookings
+// @Description  Validates a CSV or XLSX file row by row. By default only returns the report (dry run);
+// @Description  with dry_run=false a file without errors is applied in a single transaction.
+// @Description  Rows that already exist (by email, external_id, same lesson or active booking) are skipped
+// @Tags         import
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        kind     path      string  true   "users, lessons or bookings"
+// @Param        file     formData  file    true   "CSV or XLSX file with a header row"
+// @Param        dry_run  query     bool    false  "Only validate the file (default true)"
+// @Param        tz       query     string  false  "IANA time zone for lesson times without offset (default UTC)"
+// @Success      200  {object}  response.SuccessResponse{data=models.ImportReport}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      422  {object}  response.ErrorResponse  "File has row errors, report in error.details"
+// @Security     SessionAuth
+// @Router       /admin/import/{kind} [post]
+func (h *ImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	kind := models.ImportKind(chi.URLParam(r, "kind"))
+	if !kind.IsValid() {
+		response.BadRequest(w, response.ErrCodeInvalidInput, models.ErrInvalidImportKind.Error())
+		return
+	}
+
+	dryRun := true
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid dry_run")
+			return
+		}
+		dryRun = parsed
+	}
+
+	loc := time.UTC
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		parsed, err := time.LoadLocation(tz)
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, models.ErrInvalidImportTimezone.Error())
+			return
+		}
+		loc = parsed
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, models.MaxImportFileSize+1<<20)
+	if err := r.ParseMultipartForm(models.MaxImportFileSize); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Failed to parse form data")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeMissingField, "Import file is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxImportFileSize+1))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Failed to read import file")
+		return
+	}
+
+	report, err := h.importService.Import(r.Context(), &service.ImportRequest{
+		Kind:     kind,
+		FileName: header.Filename,
+		Data:     data,
+		DryRun:   dryRun,
+		Location: loc,
+		Actor:    user,
+	})
+	if err != nil {
+		h.handleError(w, err, report)
+		return
+	}
+
+	response.OK(w, report)
+}
+
+// ListRuns обрабатывает GET /api/v1/admin/import/runs
+// @Summary      List applied imports
+// @Description  Journal of applied bulk imports, newest first (dry runs are not recorded)
+// @Tags         import
+// @Produce      json
+// @Param        limit  query     int  false  "Max records (default 50, max 500)"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.ImportRun}
+// @Security     SessionAuth
+// @Router       /admin/import/runs [get]
+func (h *ImportHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	limit := defaultImportRunsLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 500 {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := h.importService.ListRuns(r.Context(), limit)
+	if err != nil {
+		log.Printf("ERROR: Failed to list import runs: %v", err)
+		response.InternalError(w, "Failed to retrieve import runs")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"runs": runs,
+	})
+}
+
+// handleError преобразует ошибки импорта в HTTP ответы; отчет со строковыми ошибками
+// возвращается в details ответа 422
+func (h *ImportHandler) handleError(w http.ResponseWriter, err error, report *models.ImportReport) {
+	switch {
+	case errors.Is(err, models.ErrImportHasErrors):
+		response.ErrorWithDetails(w, http.StatusUnprocessableEntity, response.ErrCodeValidationFailed, err.Error(), report)
+	case errors.Is(err, models.ErrInvalidImportKind),
+		errors.Is(err, models.ErrImportUnsupportedFormat),
+		errors.Is(err, models.ErrImportFileTooLarge),
+		errors.Is(err, models.ErrImportEmptyFile),
+		errors.Is(err, models.ErrImportMissingColumns),
+		errors.Is(err, models.ErrImportTooManyRows):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	default:
+		log.Printf("ERROR: Failed to import file: %v", err)
+		response.InternalError(w, "Failed to import file")
+	}
+}
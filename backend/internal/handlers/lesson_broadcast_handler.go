@@ -33,6 +33,7 @@ type LessonBroadcastServiceInterface interface {
 type LessonBroadcastHandler struct {
 	broadcastService LessonBroadcastServiceInterface
 	uploadDir        string
+	fileStorage      *service.FileStorageService
 }
 
 // NewLessonBroadcastHandler создает новый LessonBroadcastHandler
@@ -43,6 +44,11 @@ func NewLessonBroadcastHandler(broadcastService LessonBroadcastServiceInterface,
 	}
 }
 
+// SetFileStorage подключает BlobStore для скачивания файлов, загруженных после перехода на него
+func (h *LessonBroadcastHandler) SetFileStorage(fileStorage *service.FileStorageService) {
+	h.fileStorage = fileStorage
+}
+
 // CreateBroadcast обрабатывает POST /api/v1/lessons/:id/broadcasts
 // Создает и отправляет рассылку для студентов урока
 func (h *LessonBroadcastHandler) CreateBroadcast(w http.ResponseWriter, r *http.Request) {
@@ -335,6 +341,12 @@ func (h *LessonBroadcastHandler) DownloadBroadcastFile(w http.ResponseWriter, r
 		return
 	}
 
+	// Файлы, загруженные после перехода на BlobStore, отдаются из хранилища
+	if file.StorageKey.Valid && h.fileStorage != nil {
+		serveStoredFile(w, r, h.fileStorage, file.StorageKey.String, file.FileName, file.MimeType)
+		return
+	}
+
 	// Полный путь к файлу на диске
 	fullPath := filepath.Join(h.uploadDir, file.FilePath)
 
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -37,6 +38,15 @@ func NewLessonHandler(lessonService *service.LessonService, bookingService *serv
 	}
 }
 
+// canManageLesson проверяет права с областью на занятие преподавателя teacherID:
+// lessons.edit.all - любое занятие, lessons.edit.own - только свое
+func canManageLesson(ctx context.Context, user *models.User, teacherID uuid.UUID) bool {
+	if middleware.HasPermission(ctx, models.PermLessonsEditAll) {
+		return true
+	}
+	return middleware.HasPermission(ctx, models.PermLessonsEditOwn) && teacherID == user.ID
+}
+
 // GetLessons обрабатывает GET /api/v1/lessons
 // Возвращает занятия с учетом правил видимости:
 // - Admin: все занятия
@@ -126,8 +136,8 @@ func (h *LessonHandler) CreateLesson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Только админы и методисты могут создавать занятия
-	if !user.IsAdmin() && !user.IsTeacher() {
+	// Создание занятий требует права lessons.create
+	if !middleware.HasPermission(r.Context(), models.PermLessonsCreate) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -138,9 +148,8 @@ func (h *LessonHandler) CreateLesson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Методист (учитель) может назначить только себя преподавателем
-	// Админ может назначить любого преподавателя
-	if user.IsTeacher() && !user.IsAdmin() {
+	// Без права lessons.edit.all можно назначить преподавателем только себя
+	if !middleware.HasPermission(r.Context(), models.PermLessonsEditAll) {
 		if req.TeacherID != user.ID {
 			response.Forbidden(w, "Вы можете назначить только себя преподавателем занятия")
 			return
@@ -230,20 +239,11 @@ func (h *LessonHandler) UpdateLesson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Методист (учитель) может назначить только себя преподавателем
-	// Админ может назначить любого преподавателя
-	if user.IsTeacher() && !user.IsAdmin() && req.TeacherID != nil {
-		if *req.TeacherID != user.ID {
-			response.Forbidden(w, "Вы можете назначить только себя преподавателем занятия")
-			return
-		}
-	}
-
-	// Проверяем права доступа:
-	// - Admin может редактировать все поля всех занятий
-	// - Teacher может редактировать только homework_text и report_text своих занятий
-	// - Student не может редактировать
-	isTeacherOwnLesson := user.IsTeacher() && lesson.TeacherID == user.ID
+	// Проверяем права доступа (маршрут уже проверил lessons.edit.own для своего занятия или lessons.edit.all):
+	// - lessons.edit.all - все поля любых занятий
+	// - lessons.edit.own - только homework_text и report_text своих занятий
+	canEditAll := middleware.HasPermission(r.Context(), models.PermLessonsEditAll)
+	isOwnLesson := lesson.TeacherID == user.ID
 	isTextFieldsOnlyUpdate := (req.HomeworkText != nil || req.ReportText != nil) &&
 		req.TeacherID == nil &&
 		req.StartTime == nil &&
@@ -259,67 +259,47 @@ func (h *LessonHandler) UpdateLesson(w http.ResponseWriter, r *http.Request) {
 		Str("user_role", string(user.Role)).
 		Str("lesson_id", lessonID.String()).
 		Str("lesson_teacher_id", lesson.TeacherID.String()).
-		Bool("is_teacher_own_lesson", isTeacherOwnLesson).
+		Bool("can_edit_all", canEditAll).
+		Bool("is_own_lesson", isOwnLesson).
 		Bool("is_text_fields_only", isTextFieldsOnlyUpdate).
-		Bool("has_homework_text", req.HomeworkText != nil).
-		Bool("has_report_text", req.ReportText != nil).
 		Msg("UpdateLesson authorization check")
 
-	if !user.IsAdmin() && !user.IsTeacher() {
-		// Если преподаватель пытается обновить только homework_text или report_text своего урока
-		if isTeacherOwnLesson && isTextFieldsOnlyUpdate {
-			// Teacher может редактировать homework_text и report_text своих уроков
-			log.Debug().
-				Str("teacher_id", user.ID.String()).
+	if !canEditAll {
+		if !middleware.HasPermission(r.Context(), models.PermLessonsEditOwn) || !isOwnLesson {
+			log.Warn().
+				Str("user_id", user.ID.String()).
+				Str("lesson_teacher_id", lesson.TeacherID.String()).
 				Str("lesson_id", lessonID.String()).
-				Msg("Teacher updating text fields for own lesson")
-		} else if user.IsTeacher() {
-			// Преподаватель пытается обновить что-то другое или не свой урок
-			if isTextFieldsOnlyUpdate && !isTeacherOwnLesson {
-				// Teacher пытается обновить текстовые поля, но урок не его
-				log.Warn().
-					Str("user_id", user.ID.String()).
-					Str("lesson_teacher_id", lesson.TeacherID.String()).
-					Str("lesson_id", lessonID.String()).
-					Msg("Teacher tried to update text fields for another teacher's lesson")
-				response.Forbidden(w, "Вы можете редактировать только свои занятия")
-				return
-			} else if isTeacherOwnLesson && !isTextFieldsOnlyUpdate {
-				// Teacher пытается обновить не только текстовые поля своего урока
-				log.Warn().
-					Str("user_id", user.ID.String()).
-					Str("lesson_id", lessonID.String()).
-					Msg("Teacher tried to update non-text fields for own lesson")
-				response.Forbidden(w, "Преподаватели могут редактировать только текстовые поля (домашнее задание и отчет)")
-				return
-			} else {
-				// Другие случаи для преподавателя
-				response.Forbidden(w, "Вы можете редактировать только текстовые поля своих занятий")
-				return
-			}
-		} else {
-			// Студент или другой пользователь
-			response.Forbidden(w, "Только администраторы и методисты могут редактировать занятия")
+				Msg("User tried to update a lesson without edit permission")
+			response.Forbidden(w, "Вы можете редактировать только свои занятия")
+			return
+		}
+		if !isTextFieldsOnlyUpdate {
+			log.Warn().
+				Str("user_id", user.ID.String()).
+				Str("lesson_id", lessonID.String()).
+				Msg("User tried to update non-text fields for own lesson")
+			response.Forbidden(w, "Преподаватели могут редактировать только текстовые поля (домашнее задание и отчет)")
 			return
 		}
 	}
 
 	// Проверяем право на редактирование отчета: только после начала занятия
 	isPastLesson := lesson.StartTime.Before(time.Now())
-	if req.ReportText != nil && !isPastLesson && !user.IsAdmin() {
+	if req.ReportText != nil && !isPastLesson && !canEditAll {
 		response.Forbidden(w, "Отчет можно редактировать только после начала занятия")
 		return
 	}
 
-	// Логируем редактирование прошлых занятий админом или методистом для аудита
-	if isPastLesson && (user.IsAdmin() || user.IsTeacher()) {
+	// Логируем редактирование прошлых занятий для аудита
+	if isPastLesson {
 		log.Warn().
 			Str("user_id", user.ID.String()).
 			Str("user_email", user.Email).
 			Str("user_role", string(user.Role)).
 			Str("lesson_id", lessonID.String()).
 			Str("lesson_start_time", lesson.StartTime.Format("2006-01-02 15:04")).
-			Msg("Admin/Teacher is editing a past lesson")
+			Msg("User is editing a past lesson")
 	}
 
 	// Обновляем занятие
@@ -334,7 +314,7 @@ func (h *LessonHandler) UpdateLesson(w http.ResponseWriter, r *http.Request) {
 		"lesson": updatedLesson.ToResponseWithoutTeacher(),
 	}
 
-	if isPastLesson && user.IsAdmin() {
+	if isPastLesson && canEditAll {
 		responseData["warning"] = "Это занятие уже началось. Изменения могут повлиять на записанных студентов."
 	}
 
@@ -343,14 +323,14 @@ func (h *LessonHandler) UpdateLesson(w http.ResponseWriter, r *http.Request) {
 
 // DeleteLesson обрабатывает DELETE /api/v1/lessons/:id
 func (h *LessonHandler) DeleteLesson(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	// Только админы и методисты могут удалять занятия
-	if !user.IsAdmin() && !user.IsTeacher() {
+	// Удаление требует lessons.delete.own или lessons.delete.all (владение проверяет RequireOwnership)
+	if !middleware.HasPermission(r.Context(), models.PermLessonsDeleteOwn, models.PermLessonsDeleteAll) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -504,16 +484,8 @@ func (h *LessonHandler) GetLessonStudents(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Проверка доступа: админы и методисты видят все занятия, преподаватели видят только свои
-	if !user.IsAdmin() && !user.IsTeacher() {
-		if !user.IsTeacher() || lesson.TeacherID != user.ID {
-			response.Forbidden(w, "Access denied")
-			return
-		}
-	}
-
-	// Студенты не могут просматривать список студентов занятия
-	if user.IsStudent() {
+	// Проверка доступа: lessons.edit.all - любые занятия, lessons.edit.own - только свои
+	if !canManageLesson(r.Context(), user, lesson.TeacherID) {
 		response.Forbidden(w, "Access denied")
 		return
 	}
@@ -597,16 +569,20 @@ func ptrBookingStatus(status models.BookingStatus) *models.BookingStatus {
 
 // ApplyToAllSubsequent handles POST /api/v1/lessons/:id/apply-to-all - Bulk edit (Admin only)
 // @Summary      Apply modification to all subsequent lessons
-// @Description  Apply lesson modification to all future matching lessons (bulk edit, admin only)
+// @Description  Apply lesson modification to all future matching lessons (bulk edit, admin only).
+// @Description  With dry_run=true nothing is changed: returns affected lessons, capacity violations, teacher overlaps,
+// @Description  credit deltas and a preview_token. Passing the token back guarantees the same target set (409 otherwise).
 // @Tags         lessons
 // @Accept       json
 // @Produce      json
 // @Param        id  path      string  true  "Lesson ID"
+// @Param        dry_run  query     bool  false  "Preview only, do not apply"
 // @Param        payload  body      models.ApplyToAllSubsequentRequest  true  "Modification details"
 // @Success      200  {object}  response.SuccessResponse{data=interface{}}
 // @Failure      400  {object}  response.ErrorResponse
 // @Failure      403  {object}  response.ErrorResponse
 // @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
 // @Security     SessionAuth
 // @Router       /lessons/{id}/apply-to-all [post]
 func (h *LessonHandler) ApplyToAllSubsequent(w http.ResponseWriter, r *http.Request) {
@@ -616,11 +592,6 @@ func (h *LessonHandler) ApplyToAllSubsequent(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if !admin.IsAdmin() && !admin.IsTeacher() {
-		response.Forbidden(w, "Only admins and teachers can apply bulk edits")
-		return
-	}
-
 	lessonID := chi.URLParam(r, "id")
 	if lessonID == "" {
 		response.BadRequest(w, response.ErrCodeInvalidInput, "Lesson ID is required")
@@ -649,8 +620,30 @@ func (h *LessonHandler) ApplyToAllSubsequent(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if r.URL.Query().Get("dry_run") == "true" || req.DryRun {
+		preview, err := h.bulkEditService.PreviewApplyToAllSubsequent(r.Context(), &req)
+		if err != nil {
+			if errors.Is(err, repository.ErrLessonNotFound) {
+				response.NotFound(w, "Lesson not found")
+				return
+			}
+			if errors.Is(err, repository.ErrUserNotFound) {
+				response.BadRequest(w, response.ErrCodeValidationFailed, "Referenced user not found")
+				return
+			}
+			response.InternalError(w, "Failed to preview bulk edit: "+err.Error())
+			return
+		}
+		response.OK(w, preview)
+		return
+	}
+
 	modification, err := h.bulkEditService.ApplyToAllSubsequent(r.Context(), admin.ID, &req)
 	if err != nil {
+		if errors.Is(err, models.ErrPreviewTokenMismatch) {
+			response.Conflict(w, response.ErrCodeConflict, err.Error())
+			return
+		}
 		if errors.Is(err, repository.ErrLessonNotFound) {
 			response.NotFound(w, "Lesson not found")
 			return
@@ -670,16 +663,98 @@ func (h *LessonHandler) ApplyToAllSubsequent(w http.ResponseWriter, r *http.Requ
 	response.OK(w, modification)
 }
 
-// SendReportToParents отправляет отчет о занятии родителям студентов
-func (h *LessonHandler) SendReportToParents(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+// GetLessonModification handles GET /api/v1/lesson-modifications/:id (Admin only)
+// @Summary      Get bulk modification details
+// @Description  Get a bulk lesson modification with per-lesson before/after snapshots
+// @Tags         lessons
+// @Produce      json
+// @Param        id   path      string  true  "Modification ID"
+// @Success      200  {object}  response.SuccessResponse{data=interface{}}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /lesson-modifications/{id} [get]
+func (h *LessonHandler) GetLessonModification(w http.ResponseWriter, r *http.Request) {
+	modificationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid modification ID format")
+		return
+	}
+
+	modification, snapshots, err := h.bulkEditService.GetModificationWithSnapshots(r.Context(), modificationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrLessonModificationNotFound) {
+			response.NotFound(w, "Modification not found")
+			return
+		}
+		log.Error().Err(err).Str("modification_id", modificationID.String()).Msg("Failed to get lesson modification")
+		response.InternalError(w, "Failed to retrieve modification")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"modification": modification,
+		"snapshots":    snapshots,
+		"revertible":   !modification.IsReverted() && len(snapshots) > 0,
+	})
+}
+
+// RevertLessonModification handles POST /api/v1/lesson-modifications/:id/revert (Admin only)
+// @Summary      Revert bulk modification
+// @Description  Atomically restore all lessons affected by a bulk modification, re-creating bookings and reversing credit movements. Returns 409 with conflicting lessons if they changed since.
+// @Tags         lessons
+// @Produce      json
+// @Param        id   path      string  true  "Modification ID"
+// @Success      200  {object}  response.SuccessResponse{data=models.RevertModificationResult}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /lesson-modifications/{id}/revert [post]
+func (h *LessonHandler) RevertLessonModification(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
-		response.Forbidden(w, "Only admins and teachers can send reports to parents")
+	modificationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid modification ID format")
+		return
+	}
+
+	result, err := h.bulkEditService.RevertModification(r.Context(), admin.ID, modificationID)
+	if err != nil {
+		var conflictErr *service.RevertConflictError
+		switch {
+		case errors.As(err, &conflictErr):
+			response.ErrorWithDetails(w, http.StatusConflict, response.ErrCodeConflict,
+				"Lessons changed after the modification was applied", conflictErr.Conflicts)
+		case errors.Is(err, repository.ErrLessonModificationNotFound):
+			response.NotFound(w, "Modification not found")
+		case errors.Is(err, models.ErrModificationAlreadyReverted):
+			response.Conflict(w, response.ErrCodeConflict, "Modification has already been reverted")
+		case errors.Is(err, models.ErrModificationNotRevertible):
+			response.BadRequest(w, response.ErrCodeValidationFailed, "Modification has no snapshots and cannot be reverted")
+		case errors.Is(err, repository.ErrInsufficientCredits):
+			response.Conflict(w, response.ErrCodeInsufficientCredits, err.Error())
+		case errors.Is(err, repository.ErrLessonOverlapConflict):
+			response.Conflict(w, response.ErrCodeScheduleConflict, "Teacher has overlapping lessons at the original time")
+		default:
+			log.Error().Err(err).Str("modification_id", modificationID.String()).Msg("Failed to revert lesson modification")
+			response.InternalError(w, "Failed to revert modification")
+		}
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// SendReportToParents отправляет отчет о занятии родителям студентов
+func (h *LessonHandler) SendReportToParents(w http.ResponseWriter, r *http.Request) {
+	if _, ok := middleware.GetUserFromContext(r.Context()); !ok {
+		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// TestCanManageLesson проверяет права с областью на занятие для пользовательских ролей
+func TestCanManageLesson(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Role: models.UserRole("coordinator")}
+	otherTeacher := uuid.New()
+
+	tests := []struct {
+		name      string
+		perms     models.PermissionSet
+		teacherID uuid.UUID
+		want      bool
+	}{
+		{name: "edit_all_other_lesson", perms: models.NewPermissionSet(models.PermLessonsEditAll), teacherID: otherTeacher, want: true},
+		{name: "edit_own_own_lesson", perms: models.NewPermissionSet(models.PermLessonsEditOwn), teacherID: user.ID, want: true},
+		{name: "edit_own_other_lesson", perms: models.NewPermissionSet(models.PermLessonsEditOwn), teacherID: otherTeacher, want: false},
+		{name: "no_permissions", perms: models.NewPermissionSet(), teacherID: user.ID, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := middleware.SetUserInContext(context.Background(), user)
+			ctx = context.WithValue(ctx, middleware.PermissionsContextKey, tt.perms)
+			if got := canManageLesson(ctx, user, tt.teacherID); got != tt.want {
+				t.Errorf("canManageLesson() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
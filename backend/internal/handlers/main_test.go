@@ -5,8 +5,14 @@ import (
 	"testing"
 
 	"tutoring-platform/internal/database"
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
 )
 
+// testAdminActor администратор, от имени которого тесты создают и изменяют пользователей через UserService
+var testAdminActor = &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
 func TestMain(m *testing.M) {
 	pool := database.GetTestPoolForMain()
 	if pool != nil {
@@ -59,8 +59,8 @@ func (h *TeacherHandler) SendLessonBroadcast(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Проверяем что пользователь - методист или админ
-	if !user.IsTeacher() && !user.IsAdmin() {
+	// Проверяем право на рассылку по занятиям
+	if !middleware.HasPermission(r.Context(), models.PermLessonsBroadcastOwn, models.PermLessonsBroadcastAll) {
 		response.Forbidden(w, "Only teachers and admins can send lesson broadcasts")
 		return
 	}
@@ -84,9 +84,8 @@ func (h *TeacherHandler) SendLessonBroadcast(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Проверяем что текущий методист может отправлять рассылку для этого занятия
-	// (методист может отправлять для всех занятий, или ограничить по факультету/группе в будущем)
-	if !user.IsAdmin() && lesson.TeacherID != user.ID {
+	// Без права lessons.broadcast.all рассылка доступна только по своим занятиям
+	if !middleware.HasPermission(r.Context(), models.PermLessonsBroadcastAll) && lesson.TeacherID != user.ID {
 		response.Forbidden(w, "You can only send broadcasts for your own lessons")
 		return
 	}
@@ -190,12 +189,6 @@ func (h *TeacherHandler) GetTeacherSchedule(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Проверяем что пользователь - методист или админ
-	if !user.IsTeacher() && !user.IsAdmin() {
-		response.Forbidden(w, "Only teachers and admins can access teacher schedule")
-		return
-	}
-
 	// Парсим query параметры
 	startDateStr := r.URL.Query().Get("start_date")
 	endDateStr := r.URL.Query().Get("end_date")
@@ -261,11 +254,11 @@ func (h *TeacherHandler) GetTeacherSchedule(w http.ResponseWriter, r *http.Reque
 	// (аналогично credits.go:177 и swaps.go:158)
 	endDate = endDate.Add(24 * time.Hour)
 
-	// Определяем методистID - для методиста это его ID, для админа можно фильтровать
+	// Определяем методистID - свои занятия, а с правом lessons.edit.all можно фильтровать
 	var lessons []*models.TeacherScheduleLesson
 
-	if user.IsAdmin() {
-		// Админ может запросить расписание любого методиста через query param
+	if middleware.HasPermission(r.Context(), models.PermLessonsEditAll) {
+		// С правом lessons.edit.all можно запросить расписание любого методиста через query param
 		teacherIDParam := r.URL.Query().Get("teacher_id")
 		if teacherIDParam != "" {
 			teacherID, err := uuid.Parse(teacherIDParam)
@@ -281,7 +274,7 @@ func (h *TeacherHandler) GetTeacherSchedule(w http.ResponseWriter, r *http.Reque
 				return
 			}
 		} else {
-			// Админ без teacher_id получает расписание ВСЕХ методистов
+			// Без teacher_id - расписание ВСЕХ методистов
 			lessons, err = h.lessonRepo.GetAllTeachersSchedule(r.Context(), startDate, endDate)
 			if err != nil {
 				log.Printf("[ERROR] Failed to get all teachers schedule: %v\n", err)
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// ModerationHandler обрабатывает эндпоинты очереди модерации и апелляций
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+}
+
+// NewModerationHandler создает новый ModerationHandler
+func NewModerationHandler(moderationService *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// ListQueue обрабатывает GET /api/v1/admin/moderation/queue
+// @Summary      Moderation review queue
+// @Description  Messages blocked by moderation; appealed first, then newest
+// @Tags         moderation
+// @Produce      json
+// @Param        status    query     string  false  "Review status: pending, released, confirmed"
+// @Param        rule      query     string  false  "Rule: ai, phone, email, social, video_platform, obfuscated_phone"
+// @Param        appealed  query     bool    false  "Only appealed blocks"
+// @Param        limit     query     int     false  "Page size (default 50, max 200)"
+// @Param        offset    query     int     false  "Offset"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.BlockedMessageReview}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/moderation/queue [get]
+func (h *ModerationHandler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.ModerationQueueFilter{
+		Status:       query.Get("status"),
+		Rule:         query.Get("rule"),
+		AppealedOnly: query.Get("appealed") == "true",
+	}
+
+	var ok bool
+	if filter.Limit, ok = parseOptionalIntQuery(w, r, "limit"); !ok {
+		return
+	}
+	if filter.Offset, ok = parseOptionalIntQuery(w, r, "offset"); !ok {
+		return
+	}
+
+	items, total, err := h.moderationService.ListQueue(r.Context(), filter)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"items":  items,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// GetBlocked обрабатывает GET /api/v1/admin/moderation/blocked/{id}
+// @Summary      Get blocked message
+// @Tags         moderation
+// @Produce      json
+// @Param        id   path      string  true  "Blocked message ID"
+// @Success      200  {object}  response.SuccessResponse{data=models.BlockedMessageReview}
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/moderation/blocked/{id} [get]
+func (h *ModerationHandler) GetBlocked(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDParam(w, r, "id", "Invalid blocked message ID")
+	if !ok {
+		return
+	}
+
+	item, err := h.moderationService.GetBlocked(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, item)
+}
+
+// ReleaseBlocked обрабатывает POST /api/v1/admin/moderation/blocked/{id}/release
+// @Summary      Release blocked message
+// @Description  Marks the block as a false positive and delivers the message to the recipient
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                          true   "Blocked message ID"
+// @Param        request  body      models.ModerationReviewRequest  false  "Reviewer note"
+// @Success      200  {object}  response.SuccessResponse{data=models.BlockedMessageReview}
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/moderation/blocked/{id}/release [post]
+func (h *ModerationHandler) ReleaseBlocked(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.moderationService.ReleaseBlocked)
+}
+
+// ConfirmBlocked обрабатывает POST /api/v1/admin/moderation/blocked/{id}/confirm
+// @Summary      Confirm block
+// @Description  Upholds the block; the sender can still appeal once
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                          true   "Blocked message ID"
+// @Param        request  body      models.ModerationReviewRequest  false  "Reviewer note"
+// @Success      200  {object}  response.SuccessResponse{data=models.BlockedMessageReview}
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/moderation/blocked/{id}/confirm [post]
+func (h *ModerationHandler) ConfirmBlocked(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.moderationService.ConfirmBlocked)
+}
+
+// review разбирает запрос решения по блокировке и вызывает decide
+func (h *ModerationHandler) review(w http.ResponseWriter, r *http.Request,
+	decide func(ctx context.Context, reviewerID, id uuid.UUID, req *models.ModerationReviewRequest) (*models.BlockedMessageReview, error)) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseUUIDParam(w, r, "id", "Invalid blocked message ID")
+	if !ok {
+		return
+	}
+
+	// Комментарий необязателен: пустое тело допустимо
+	var req models.ModerationReviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+			return
+		}
+	}
+
+	item, err := decide(r.Context(), user.ID, id, &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, item)
+}
+
+// GetStats обрабатывает GET /api/v1/admin/moderation/stats
+// @Summary      Moderation statistics
+// @Description  Per-rule block counts, review outcomes and release rate, plus moderation backend state
+// @Tags         moderation
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse
+// @Security     SessionAuth
+// @Router       /admin/moderation/stats [get]
+func (h *ModerationHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.moderationService.RuleStats(r.Context())
+	if err != nil {
+		log.Printf("ERROR: Failed to get moderation stats: %v", err)
+		response.InternalError(w, "Failed to retrieve moderation statistics")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"rules":           rules,
+		"moderator":       h.moderationService.ModeratorName(),
+		"circuit_breaker": h.moderationService.GetCircuitBreakerStatus(),
+	})
+}
+
+// ListMyBlocked обрабатывает GET /api/v1/chat/moderation/blocked
+// @Summary      My blocked messages
+// @Description  Messages of the current user blocked by moderation, with reason and appeal state
+// @Tags         moderation
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=[]models.BlockedMessageReview}
+// @Security     SessionAuth
+// @Router       /chat/moderation/blocked [get]
+func (h *ModerationHandler) ListMyBlocked(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	items, err := h.moderationService.ListMyBlocked(r.Context(), user.ID)
+	if err != nil {
+		log.Printf("ERROR: Failed to list blocked messages for user %s: %v", user.ID, err)
+		response.InternalError(w, "Failed to retrieve blocked messages")
+		return
+	}
+
+	response.OK(w, items)
+}
+
+// Appeal обрабатывает POST /api/v1/chat/moderation/blocked/{id}/appeal
+// @Summary      Appeal blocked message
+// @Description  The sender can appeal a block once; a confirmed block goes back to the review queue
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                          true  "Blocked message ID"
+// @Param        request  body      models.ModerationAppealRequest  true  "Appeal reason"
+// @Success      200  {object}  response.SuccessResponse{data=models.BlockedMessageReview}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /chat/moderation/blocked/{id}/appeal [post]
+func (h *ModerationHandler) Appeal(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseUUIDParam(w, r, "id", "Invalid blocked message ID")
+	if !ok {
+		return
+	}
+
+	var req models.ModerationAppealRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	item, err := h.moderationService.Appeal(r.Context(), user.ID, id, &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, item)
+}
+
+// handleError обрабатывает ошибки модерации
+func (h *ModerationHandler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidModerationFilter),
+		errors.Is(err, models.ErrInvalidModerationReview),
+		errors.Is(err, models.ErrInvalidModerationAppeal):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	case errors.Is(err, repository.ErrBlockedMessageNotFound), errors.Is(err, repository.ErrMessageNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, repository.ErrUnauthorized):
+		response.Forbidden(w, "You can only appeal your own messages")
+	case errors.Is(err, models.ErrModerationAlreadyReviewed), errors.Is(err, models.ErrModerationAppealExists):
+		response.Conflict(w, response.ErrCodeConflict, err.Error())
+	default:
+		log.Printf("ERROR: Moderation request failed: %v", err)
+		response.InternalError(w, "Failed to process moderation request")
+	}
+}
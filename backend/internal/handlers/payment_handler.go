@@ -27,10 +27,17 @@ type PaymentServiceInterface interface {
 	ProcessPaymentCancellation(ctx context.Context, paymentID string) error
 }
 
+// SubscriptionPaymentProcessor обрабатывает webhook платежей по счетам подписок
+type SubscriptionPaymentProcessor interface {
+	ProcessInvoicePaymentSucceeded(ctx context.Context, yookassaPaymentID, paymentMethodID string, methodSaved bool) error
+	ProcessInvoicePaymentCancelled(ctx context.Context, yookassaPaymentID, reason string) error
+}
+
 // PaymentHandler обрабатывает запросы связанные с платежами
 type PaymentHandler struct {
-	paymentService PaymentServiceInterface
-	cfg            *config.Config
+	paymentService        PaymentServiceInterface
+	subscriptionProcessor SubscriptionPaymentProcessor
+	cfg                   *config.Config
 }
 
 // NewPaymentHandler создает новый PaymentHandler
@@ -41,6 +48,12 @@ func NewPaymentHandler(paymentService PaymentServiceInterface, cfg *config.Confi
 	}
 }
 
+// SetSubscriptionProcessor подключает обработку webhook для платежей по подпискам
+// Платежи подписок отличаются наличием metadata.subscription_invoice_id
+func (h *PaymentHandler) SetSubscriptionProcessor(processor SubscriptionPaymentProcessor) {
+	h.subscriptionProcessor = processor
+}
+
 // CreatePayment создает новый платеж
 // POST /api/v1/payments/create
 // @Summary      Create payment
@@ -208,6 +221,12 @@ func (h *PaymentHandler) YooKassaWebhook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Платежи по подпискам обрабатываются отдельно: у них нет записи в таблице payments
+	if webhook.Object.Metadata.SubscriptionInvoiceID != "" && h.subscriptionProcessor != nil {
+		h.handleSubscriptionWebhook(w, r, &webhook)
+		return
+	}
+
 	// Обрабатываем событие в зависимости от типа
 	switch webhook.Event {
 	case "payment.succeeded":
@@ -240,3 +259,36 @@ func (h *PaymentHandler) YooKassaWebhook(w http.ResponseWriter, r *http.Request)
 	// Возвращаем 200 OK для подтверждения получения webhook
 	w.WriteHeader(http.StatusOK)
 }
+
+// handleSubscriptionWebhook обрабатывает webhook платежа по счёту подписки
+func (h *PaymentHandler) handleSubscriptionWebhook(w http.ResponseWriter, r *http.Request, webhook *models.YooKassaWebhookRequest) {
+	ctx := r.Context()
+	object := &webhook.Object
+
+	switch webhook.Event {
+	case "payment.succeeded":
+		if err := h.subscriptionProcessor.ProcessInvoicePaymentSucceeded(ctx, object.ID, object.PaymentMethod.ID, object.PaymentMethod.Saved); err != nil {
+			log.Printf("ERROR: Failed to process subscription payment success for payment ID %s: %v", object.ID, err)
+			response.InternalError(w, "Failed to process payment")
+			return
+		}
+		log.Printf("INFO: Subscription payment %s processed successfully", object.ID)
+
+	case "payment.canceled":
+		reason := object.CancellationDetails.Reason
+		if reason == "" {
+			reason = "платёж отменён"
+		}
+		if err := h.subscriptionProcessor.ProcessInvoicePaymentCancelled(ctx, object.ID, reason); err != nil {
+			log.Printf("ERROR: Failed to process subscription payment cancellation for payment ID %s: %v", object.ID, err)
+			response.InternalError(w, "Failed to process cancellation")
+			return
+		}
+		log.Printf("INFO: Subscription payment %s cancellation processed", object.ID)
+
+	default:
+		log.Printf("INFO: Received unknown webhook event type: %s", webhook.Event)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -56,8 +56,8 @@ func (h *PaymentSettingsHandler) ListStudentsPaymentStatus(w http.ResponseWriter
 		return
 	}
 
-	// Только админы могут получать список настроек платежей
-	if !user.IsAdmin() {
+	// Список настроек платежей доступен с правом payments.manage
+	if !middleware.HasPermission(r.Context(), models.PermPaymentsManage) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -135,8 +135,8 @@ func (h *PaymentSettingsHandler) UpdatePaymentStatus(w http.ResponseWriter, r *h
 		return
 	}
 
-	// Только админы могут изменять настройки платежей
-	if !user.IsAdmin() {
+	// Изменение настроек платежей требует права payments.manage
+	if !middleware.HasPermission(r.Context(), models.PermPaymentsManage) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
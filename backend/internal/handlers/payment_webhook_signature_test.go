@@ -83,7 +83,8 @@ func TestYooKassaWebhook_ValidSignature(t *testing.T) {
 				Currency: "RUB",
 			},
 			Metadata: struct {
-				PaymentID string `json:"payment_id"`
+				PaymentID             string `json:"payment_id"`
+				SubscriptionInvoiceID string `json:"subscription_invoice_id"`
 			}{
 				PaymentID: "test_payment_123",
 			},
@@ -0,0 +1,474 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// PayrollHandler обрабатывает эндпоинты расчета оплаты преподавателей
+// (admin: ставки, периоды, корректировки, выгрузки; teacher: собственные начисления)
+type PayrollHandler struct {
+	payrollService *service.PayrollService
+}
+
+// NewPayrollHandler создает новый PayrollHandler
+func NewPayrollHandler(payrollService *service.PayrollService) *PayrollHandler {
+	return &PayrollHandler{
+		payrollService: payrollService,
+	}
+}
+
+// ListRates обрабатывает GET /api/v1/admin/payroll/rates
+// @Summary      List teacher pay rates
+// @Description  Pay rate history of all teachers or one teacher, newest first
+// @Tags         payroll
+// @Produce      json
+// @Param        teacher_id  query     string  false  "Teacher ID"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.TeacherPayRate}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/payroll/rates [get]
+func (h *PayrollHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	teacherID, ok := parseOptionalUUIDQuery(w, r, "teacher_id")
+	if !ok {
+		return
+	}
+
+	rates, err := h.payrollService.ListRates(r.Context(), teacherID)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve pay rates")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"rates": rates,
+	})
+}
+
+// CreateRate обрабатывает POST /api/v1/admin/payroll/rates
+// @Summary      Set teacher pay rate
+// @Description  Set a pay rate (per_lesson, per_student_hour or credit_percentage) effective from a date
+// @Tags         payroll
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.CreatePayRateRequest  true  "Pay rate"
+// @Success      201   {object}  response.SuccessResponse{data=models.TeacherPayRate}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      409   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/payroll/rates [post]
+func (h *PayrollHandler) CreateRate(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var req models.CreatePayRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	rate, err := h.payrollService.CreateRate(r.Context(), user.ID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to set pay rate")
+		return
+	}
+
+	response.Created(w, rate)
+}
+
+// Preview обрабатывает GET /api/v1/admin/payroll/preview
+// @Summary      Preview earnings
+// @Description  Calculate earnings statements for a period without closing it
+// @Tags         payroll
+// @Produce      json
+// @Param        from        query     string  true   "Period start (YYYY-MM-DD)"
+// @Param        to          query     string  true   "Period end inclusive (YYYY-MM-DD)"
+// @Param        teacher_id  query     string  false  "Teacher ID"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.EarningsStatement}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/payroll/preview [get]
+func (h *PayrollHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	teacherID, ok := parseOptionalUUIDQuery(w, r, "teacher_id")
+	if !ok {
+		return
+	}
+
+	req := &models.PayrollPeriodRequest{
+		PeriodStart: r.URL.Query().Get("from"),
+		PeriodEnd:   r.URL.Query().Get("to"),
+	}
+	statements, err := h.payrollService.Preview(r.Context(), req, teacherID)
+	if err != nil {
+		h.handleError(w, err, "Failed to calculate earnings")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"statements": statements,
+	})
+}
+
+// ListPeriods обрабатывает GET /api/v1/admin/payroll/periods
+// @Summary      List closed payroll periods
+// @Tags         payroll
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=[]models.PayrollPeriod}
+// @Security     SessionAuth
+// @Router       /admin/payroll/periods [get]
+func (h *PayrollHandler) ListPeriods(w http.ResponseWriter, r *http.Request) {
+	periods, err := h.payrollService.ListPeriods(r.Context())
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve payroll periods")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"periods": periods,
+	})
+}
+
+// ClosePeriod обрабатывает POST /api/v1/admin/payroll/periods
+// @Summary      Close payroll period
+// @Description  Freeze earnings statements of all teachers for a finished period and attach pending adjustments
+// @Tags         payroll
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.PayrollPeriodRequest  true  "Period"
+// @Success      201   {object}  response.SuccessResponse{data=models.PayrollPeriodDetails}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      409   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/payroll/periods [post]
+func (h *PayrollHandler) ClosePeriod(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var req models.PayrollPeriodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	details, err := h.payrollService.ClosePeriod(r.Context(), user.ID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to close payroll period")
+		return
+	}
+
+	response.Created(w, details)
+}
+
+// GetPeriod обрабатывает GET /api/v1/admin/payroll/periods/{id}
+// @Summary      Get payroll period
+// @Description  Closed period with earnings statement totals per teacher
+// @Tags         payroll
+// @Produce      json
+// @Param        id   path      string  true  "Period ID"
+// @Success      200  {object}  response.SuccessResponse{data=models.PayrollPeriodDetails}
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/payroll/periods/{id} [get]
+func (h *PayrollHandler) GetPeriod(w http.ResponseWriter, r *http.Request) {
+	periodID, ok := parseUUIDParam(w, r, "id", "Invalid period ID")
+	if !ok {
+		return
+	}
+
+	details, err := h.payrollService.GetPeriod(r.Context(), periodID)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve payroll period")
+		return
+	}
+
+	response.OK(w, details)
+}
+
+// GetStatement обрабатывает GET /api/v1/admin/payroll/periods/{id}/statements/{teacherId}
+// @Summary      Get teacher earnings statement
+// @Description  Frozen statement of a teacher for a closed period with lessons and adjustments
+// @Tags         payroll
+// @Produce      json
+// @Param        id         path      string  true  "Period ID"
+// @Param        teacherId  path      string  true  "Teacher ID"
+// @Success      200  {object}  response.SuccessResponse{data=models.EarningsStatement}
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/payroll/periods/{id}/statements/{teacherId} [get]
+func (h *PayrollHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	periodID, ok := parseUUIDParam(w, r, "id", "Invalid period ID")
+	if !ok {
+		return
+	}
+	teacherID, ok := parseUUIDParam(w, r, "teacherId", "Invalid teacher ID")
+	if !ok {
+		return
+	}
+
+	statement, err := h.payrollService.GetStatement(r.Context(), periodID, teacherID)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve earnings statement")
+		return
+	}
+
+	response.OK(w, statement)
+}
+
+// ExportPeriod обрабатывает GET /api/v1/admin/payroll/periods/{id}/export
+// @Summary      Export payroll period
+// @Description  Download statements of a closed period as CSV or XLSX
+// @Tags         payroll
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        id      path      string  true   "Period ID"
+// @Param        format  query     string  false  "csv (default) or xlsx"
+// @Success      200  {file}    file
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/payroll/periods/{id}/export [get]
+func (h *PayrollHandler) ExportPeriod(w http.ResponseWriter, r *http.Request) {
+	periodID, ok := parseUUIDParam(w, r, "id", "Invalid period ID")
+	if !ok {
+		return
+	}
+
+	format := models.PayrollExportFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = models.PayrollExportCSV
+	}
+
+	export, err := h.payrollService.ExportPeriod(r.Context(), periodID, format)
+	if err != nil {
+		h.handleError(w, err, "Failed to export payroll period")
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", export.FileName))
+	w.Header().Set("Content-Length", strconv.Itoa(len(export.Data)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(export.Data); err != nil {
+		log.Printf("ERROR: Failed to write payroll export: %v", err)
+	}
+}
+
+// ListAdjustments обрабатывает GET /api/v1/admin/payroll/adjustments
+// @Summary      List earnings adjustments
+// @Tags         payroll
+// @Produce      json
+// @Param        teacher_id  query     string  false  "Teacher ID"
+// @Param        period_id   query     string  false  "Closed period ID"
+// @Param        pending     query     bool    false  "Only adjustments not yet attached to a closed period"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.EarningsAdjustment}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/payroll/adjustments [get]
+func (h *PayrollHandler) ListAdjustments(w http.ResponseWriter, r *http.Request) {
+	var filter repository.AdjustmentFilter
+	var ok bool
+	if filter.TeacherID, ok = parseOptionalUUIDQuery(w, r, "teacher_id"); !ok {
+		return
+	}
+	if filter.PeriodID, ok = parseOptionalUUIDQuery(w, r, "period_id"); !ok {
+		return
+	}
+	filter.PendingOnly = r.URL.Query().Get("pending") == "true"
+
+	adjustments, err := h.payrollService.ListAdjustments(r.Context(), filter)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve adjustments")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"adjustments": adjustments,
+	})
+}
+
+// CreateAdjustment обрабатывает POST /api/v1/admin/payroll/adjustments
+// @Summary      Create earnings adjustment
+// @Description  Bonus (positive amount) or deduction (negative amount) applied in the period covering applies_on
+// @Tags         payroll
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.CreateEarningsAdjustmentRequest  true  "Adjustment"
+// @Success      201   {object}  response.SuccessResponse{data=models.EarningsAdjustment}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      409   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/payroll/adjustments [post]
+func (h *PayrollHandler) CreateAdjustment(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var req models.CreateEarningsAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	adjustment, err := h.payrollService.CreateAdjustment(r.Context(), user.ID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to create adjustment")
+		return
+	}
+
+	response.Created(w, adjustment)
+}
+
+// DeleteAdjustment обрабатывает DELETE /api/v1/admin/payroll/adjustments/{id}
+// @Summary      Delete pending earnings adjustment
+// @Tags         payroll
+// @Param        id   path      string  true  "Adjustment ID"
+// @Success      204
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/payroll/adjustments/{id} [delete]
+func (h *PayrollHandler) DeleteAdjustment(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDParam(w, r, "id", "Invalid adjustment ID")
+	if !ok {
+		return
+	}
+
+	if err := h.payrollService.DeleteAdjustment(r.Context(), id); err != nil {
+		h.handleError(w, err, "Failed to delete adjustment")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// GetMyEarnings обрабатывает GET /api/v1/teacher/earnings
+// @Summary      My earnings
+// @Description  Current open period preview and frozen statements of closed periods for the authenticated teacher
+// @Tags         payroll
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=models.TeacherEarnings}
+// @Security     SessionAuth
+// @Router       /teacher/earnings [get]
+func (h *PayrollHandler) GetMyEarnings(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	earnings, err := h.payrollService.GetTeacherEarnings(r.Context(), user.ID)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve earnings")
+		return
+	}
+
+	response.OK(w, earnings)
+}
+
+// GetMyStatement обрабатывает GET /api/v1/teacher/earnings/{periodId}
+// @Summary      My earnings statement
+// @Description  Frozen statement of the authenticated teacher for a closed period
+// @Tags         payroll
+// @Produce      json
+// @Param        periodId  path      string  true  "Period ID"
+// @Success      200  {object}  response.SuccessResponse{data=models.EarningsStatement}
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /teacher/earnings/{periodId} [get]
+func (h *PayrollHandler) GetMyStatement(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	periodID, ok := parseUUIDParam(w, r, "periodId", "Invalid period ID")
+	if !ok {
+		return
+	}
+
+	statement, err := h.payrollService.GetStatement(r.Context(), periodID, user.ID)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve earnings statement")
+		return
+	}
+
+	response.OK(w, statement)
+}
+
+// handleError преобразует ошибки расчета оплаты в HTTP ответы
+func (h *PayrollHandler) handleError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrPayrollPeriodNotFound):
+		response.NotFound(w, "Payroll period not found")
+	case errors.Is(err, repository.ErrEarningsStatementNotFound):
+		response.NotFound(w, "Earnings statement not found")
+	case errors.Is(err, repository.ErrEarningsAdjustmentNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, repository.ErrUserNotFound):
+		response.NotFound(w, "User not found")
+	case errors.Is(err, repository.ErrPayRateExists):
+		response.Conflict(w, response.ErrCodeAlreadyExists, err.Error())
+	case errors.Is(err, repository.ErrPayrollPeriodOverlap),
+		errors.Is(err, models.ErrPayrollPeriodClosed):
+		response.Conflict(w, response.ErrCodeConflict, err.Error())
+	case errors.Is(err, models.ErrInvalidTeacherID),
+		errors.Is(err, models.ErrInvalidPayRateType),
+		errors.Is(err, models.ErrInvalidPayRateAmount),
+		errors.Is(err, models.ErrInvalidPayRateCreditValue),
+		errors.Is(err, models.ErrInvalidPayrollDates),
+		errors.Is(err, models.ErrPayrollPeriodTooLong),
+		errors.Is(err, models.ErrPayrollPeriodNotFinished),
+		errors.Is(err, models.ErrInvalidAdjustmentAmount),
+		errors.Is(err, models.ErrInvalidAdjustmentReason),
+		errors.Is(err, models.ErrInvalidPayrollExportFormat):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	default:
+		log.Printf("ERROR: %s: %v", fallback, err)
+		response.InternalError(w, fallback)
+	}
+}
+
+// parseOptionalUUIDQuery разбирает необязательный UUID из query параметра
+func parseOptionalUUIDQuery(w http.ResponseWriter, r *http.Request, name string) (*uuid.UUID, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, fmt.Sprintf("Invalid %s", name))
+		return nil, false
+	}
+	return &id, true
+}
+
+// parseUUIDParam разбирает UUID из параметра пути
+func parseUUIDParam(w http.ResponseWriter, r *http.Request, name, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, message)
+		return uuid.Nil, false
+	}
+	return id, true
+}
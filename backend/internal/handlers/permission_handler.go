@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// PermissionHandler обрабатывает эндпоинты управления ролями и правами
+type PermissionHandler struct {
+	permissionService *service.PermissionService
+}
+
+// NewPermissionHandler создает новый PermissionHandler
+func NewPermissionHandler(permissionService *service.PermissionService) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+	}
+}
+
+// ListPermissions обрабатывает GET /api/v1/admin/permissions
+// @Summary      List permission registry
+// @Description  All permissions that can be assigned to roles
+// @Tags         roles
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=[]models.PermissionDefinition}
+// @Security     SessionAuth
+// @Router       /admin/permissions [get]
+func (h *PermissionHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, h.permissionService.ListPermissions())
+}
+
+// ListRoles обрабатывает GET /api/v1/admin/roles
+// @Summary      List roles
+// @Description  Built-in and custom roles with their permissions and number of users
+// @Tags         roles
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=[]models.Role}
+// @Security     SessionAuth
+// @Router       /admin/roles [get]
+func (h *PermissionHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.permissionService.ListRoles(r.Context())
+	if err != nil {
+		log.Printf("ERROR: Failed to list roles: %v", err)
+		response.InternalError(w, "Failed to retrieve roles")
+		return
+	}
+
+	response.OK(w, roles)
+}
+
+// CreateRole обрабатывает POST /api/v1/admin/roles
+// @Summary      Create custom role
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.RoleRequest  true  "Role name, description and permissions"
+// @Success      201  {object}  response.SuccessResponse{data=models.Role}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/roles [post]
+func (h *PermissionHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var req models.RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	role, err := h.permissionService.CreateRole(r.Context(), &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.Created(w, role)
+}
+
+// UpdateRole обрабатывает PUT /api/v1/admin/roles/{name}
+// @Summary      Update role permissions
+// @Description  Replaces description and permissions of a role. Admin permissions cannot be changed
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        name     path      string              true  "Role name"
+// @Param        request  body      models.RoleRequest  true  "Description and permissions"
+// @Success      200  {object}  response.SuccessResponse{data=models.Role}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/roles/{name} [put]
+func (h *PermissionHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	var req models.RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+	req.Name = models.UserRole(chi.URLParam(r, "name"))
+
+	role, err := h.permissionService.UpdateRole(r.Context(), &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, role)
+}
+
+// DeleteRole обрабатывает DELETE /api/v1/admin/roles/{name}
+// @Summary      Delete custom role
+// @Description  Built-in roles and roles assigned to users cannot be deleted
+// @Tags         roles
+// @Param        name  path  string  true  "Role name"
+// @Success      204
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/roles/{name} [delete]
+func (h *PermissionHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	if err := h.permissionService.DeleteRole(r.Context(), models.UserRole(chi.URLParam(r, "name"))); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// handleError обрабатывает ошибки управления ролями
+func (h *PermissionHandler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidRoleRequest), errors.Is(err, models.ErrUnknownPermission):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	case errors.Is(err, models.ErrSystemRoleLocked):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, repository.ErrRoleNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, repository.ErrRoleExists):
+		response.Conflict(w, response.ErrCodeAlreadyExists, err.Error())
+	case errors.Is(err, repository.ErrRoleInUse):
+		response.Conflict(w, response.ErrCodeConflict, err.Error())
+	default:
+		log.Printf("ERROR: Role management failed: %v", err)
+		response.InternalError(w, "Failed to process role")
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// SearchHandler обрабатывает эндпоинт полнотекстового поиска
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+// NewSearchHandler создает новый SearchHandler
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search обрабатывает GET /api/v1/search
+// @Summary      Full-text search
+// @Description  Searches chat history, homework and lesson reports (Russian and English word forms). Results are ranked by relevance; matches in snippet are wrapped in <mark>, the rest of the snippet is HTML-escaped. Only chats the user participates in and lessons visible to the user are searched
+// @Tags         search
+// @Produce      json
+// @Param        q      query     string  true   "Search query (2-200 characters, supports quotes and -exclusion)"
+// @Param        type   query     string  false  "Result type: messages, homework, reports (default: all)"
+// @Param        limit  query     int     false  "Max results (default 20, max 50)"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.SearchResult}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /search [get]
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	query := r.URL.Query()
+	q := &models.SearchQuery{
+		Query: query.Get("q"),
+		Type:  query.Get("type"),
+	}
+	if q.Limit, ok = parseOptionalIntQuery(w, r, "limit"); !ok {
+		return
+	}
+
+	results, err := h.searchService.Search(r.Context(), user.ID, string(user.Role), q)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidSearchQuery) {
+			response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+			return
+		}
+		log.Printf("ERROR: Failed to search: %v", err)
+		response.InternalError(w, "Failed to perform search")
+		return
+	}
+
+	response.OK(w, results)
+}
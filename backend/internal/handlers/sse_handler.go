@@ -137,5 +137,5 @@ func (h *SSEHandler) GetChatParticipants(ctx context.Context, userID, roomID uui
 		return nil, fmt.Errorf("unauthorized: user %s is not a participant of room %s", userID, roomID)
 	}
 
-	return []uuid.UUID{room.TeacherID, room.StudentID}, nil
+	return room.ParticipantIDs(), nil
 }
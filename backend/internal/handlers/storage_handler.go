@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// StorageHandler обрабатывает эндпоинты использования хранилища файлов, квот и очистки
+type StorageHandler struct {
+	fileStorage *service.FileStorageService
+}
+
+// NewStorageHandler создает новый StorageHandler
+func NewStorageHandler(fileStorage *service.FileStorageService) *StorageHandler {
+	return &StorageHandler{
+		fileStorage: fileStorage,
+	}
+}
+
+// GetMyUsage обрабатывает GET /api/v1/storage/usage
+// @Summary      My storage usage
+// @Description  Total size of the current user's uploads and the quota that applies to them
+// @Tags         storage
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=models.StorageQuotaStatus}
+// @Security     SessionAuth
+// @Router       /storage/usage [get]
+func (h *StorageHandler) GetMyUsage(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status, err := h.fileStorage.GetQuotaStatus(r.Context(), user.ID)
+	if err != nil {
+		log.Printf("ERROR: Failed to get storage usage for user %s: %v", user.ID, err)
+		response.InternalError(w, "Failed to get storage usage")
+		return
+	}
+	response.OK(w, status)
+}
+
+// ListUsage обрабатывает GET /api/v1/admin/storage/usage
+// @Summary      Storage usage report
+// @Description  Live uploads per uploader (group_by=user) or per teacher of the lesson or direct chat (group_by=teacher), largest first
+// @Tags         storage
+// @Produce      json
+// @Param        group_by  query     string  false  "user (default) or teacher"
+// @Param        limit     query     int     false  "Page size (default 50, max 200)"
+// @Param        offset    query     int     false  "Offset"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.StorageUsage}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/storage/usage [get]
+func (h *StorageHandler) ListUsage(w http.ResponseWriter, r *http.Request) {
+	filter := &models.StorageUsageFilter{GroupBy: r.URL.Query().Get("group_by")}
+
+	var ok bool
+	if filter.Limit, ok = parseOptionalIntQuery(w, r, "limit"); !ok {
+		return
+	}
+	if filter.Offset, ok = parseOptionalIntQuery(w, r, "offset"); !ok {
+		return
+	}
+
+	items, total, err := h.fileStorage.ListUsage(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidStorageUsageFilter) {
+			response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+			return
+		}
+		log.Printf("ERROR: Failed to list storage usage: %v", err)
+		response.InternalError(w, "Failed to list storage usage")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"items":    items,
+		"total":    total,
+		"group_by": filter.GroupBy,
+		"limit":    filter.Limit,
+		"offset":   filter.Offset,
+	})
+}
+
+// GetUserQuota обрабатывает GET /api/v1/admin/storage/quotas/{userId}
+// @Summary      User storage quota
+// @Description  Used space and the effective quota of a user
+// @Tags         storage
+// @Produce      json
+// @Param        userId  path      string  true  "User ID"
+// @Success      200  {object}  response.SuccessResponse{data=models.StorageQuotaStatus}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/storage/quotas/{userId} [get]
+func (h *StorageHandler) GetUserQuota(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUUIDParam(w, r, "userId", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	status, err := h.fileStorage.GetQuotaStatus(r.Context(), userID)
+	if err != nil {
+		log.Printf("ERROR: Failed to get storage quota for user %s: %v", userID, err)
+		response.InternalError(w, "Failed to get storage quota")
+		return
+	}
+	response.OK(w, status)
+}
+
+// SetUserQuota обрабатывает PUT /api/v1/admin/storage/quotas/{userId}
+// @Summary      Set user storage quota
+// @Description  Overrides the default quota for one user; 0 forbids new uploads
+// @Tags         storage
+// @Accept       json
+// @Produce      json
+// @Param        userId   path      string                         true  "User ID"
+// @Param        request  body      models.SetStorageQuotaRequest  true  "Quota in bytes"
+// @Success      200  {object}  response.SuccessResponse{data=models.StorageQuotaStatus}
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/storage/quotas/{userId} [put]
+func (h *StorageHandler) SetUserQuota(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, ok := parseUUIDParam(w, r, "userId", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req models.SetStorageQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	status, err := h.fileStorage.SetQuota(r.Context(), userID, &req, admin.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidStorageQuota):
+			response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+		case errors.Is(err, repository.ErrUserNotFound):
+			response.NotFound(w, "User not found")
+		default:
+			log.Printf("ERROR: Failed to set storage quota for user %s: %v", userID, err)
+			response.InternalError(w, "Failed to set storage quota")
+		}
+		return
+	}
+	response.OK(w, status)
+}
+
+// ResetUserQuota обрабатывает DELETE /api/v1/admin/storage/quotas/{userId}
+// @Summary      Reset user storage quota
+// @Description  Removes the per-user override; the default quota applies again
+// @Tags         storage
+// @Produce      json
+// @Param        userId  path      string  true  "User ID"
+// @Success      200  {object}  response.SuccessResponse{data=models.StorageQuotaStatus}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/storage/quotas/{userId} [delete]
+func (h *StorageHandler) ResetUserQuota(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUUIDParam(w, r, "userId", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	status, err := h.fileStorage.ResetQuota(r.Context(), userID)
+	if err != nil {
+		log.Printf("ERROR: Failed to reset storage quota for user %s: %v", userID, err)
+		response.InternalError(w, "Failed to reset storage quota")
+		return
+	}
+	response.OK(w, status)
+}
+
+// RunGC обрабатывает POST /api/v1/admin/storage/gc
+// @Summary      Collect orphaned files
+// @Description  Deletes stored files that no live record references, older than the grace period. dry_run only counts them
+// @Tags         storage
+// @Produce      json
+// @Param        dry_run  query     bool  false  "Only count orphaned files"
+// @Success      200  {object}  response.SuccessResponse{data=service.StorageGCResult}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/storage/gc [post]
+func (h *StorageHandler) RunGC(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid dry_run")
+			return
+		}
+		dryRun = parsed
+	}
+
+	result, err := h.fileStorage.RunGC(r.Context(), dryRun)
+	if err != nil {
+		log.Printf("ERROR: Storage garbage collection failed: %v", err)
+		response.InternalError(w, "Failed to collect orphaned files")
+		return
+	}
+	response.OK(w, result)
+}
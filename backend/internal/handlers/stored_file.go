@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/internal/storage"
+	"tutoring-platform/internal/upload"
+	"tutoring-platform/pkg/response"
+)
+
+// serveStoredFile отдает файл из BlobStore. Если хранилище выдает временные ссылки (S3),
+// клиент перенаправляется на него и файл не проходит через API; иначе содержимое передается потоком
+func serveStoredFile(w http.ResponseWriter, r *http.Request, fileStorage *service.FileStorageService, key, fileName, mimeType string) {
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	url, err := fileStorage.PresignURL(r.Context(), key, fileName, mimeType)
+	if err != nil {
+		log.Printf("ERROR: Failed to presign stored file %s: %v", key, err)
+		response.InternalError(w, "Failed to get file")
+		return
+	}
+	if url != "" {
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, url, http.StatusFound)
+		return
+	}
+
+	body, info, err := fileStorage.Open(r.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		response.NotFound(w, "File not found on server")
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to open stored file %s: %v", key, err)
+		response.InternalError(w, "Failed to get file")
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if info.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("ERROR: Failed to stream stored file %s: %v", key, err)
+	}
+}
+
+// serveStoredThumbnail отдает превью файла из BlobStore для показа в браузере (inline).
+// Превью небольшие и не меняются (ключ выводится из хеша содержимого), поэтому всегда идут через API
+// и кэшируются клиентом
+func serveStoredThumbnail(w http.ResponseWriter, r *http.Request, fileStorage *service.FileStorageService, key string) {
+	body, info, err := fileStorage.Open(r.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		response.NotFound(w, "Thumbnail not available")
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to open thumbnail %s: %v", key, err)
+		response.InternalError(w, "Failed to get thumbnail")
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Disposition", "inline")
+	w.Header().Set("Content-Type", upload.ThumbnailMimeType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	if info.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("ERROR: Failed to stream thumbnail %s: %v", key, err)
+	}
+}
+
+// writeUploadRejection отвечает клиенту, если загрузку отклонила проверка файла
+// (несоответствие типа, антивирус, квота). Возвращает false для остальных ошибок
+func writeUploadRejection(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, models.ErrFileTypeMismatch):
+		response.BadRequest(w, response.ErrCodeFileTypeMismatch, "File content does not match its type or extension")
+	case errors.Is(err, models.ErrImageTooLarge):
+		response.BadRequest(w, response.ErrCodeValidationFailed, "Image dimensions are too large")
+	case errors.Is(err, models.ErrFileInfected):
+		response.BadRequest(w, response.ErrCodeFileInfected, "File was rejected by the malware scan")
+	case errors.Is(err, models.ErrStorageQuotaExceeded):
+		response.Error(w, http.StatusRequestEntityTooLarge, response.ErrCodeStorageQuotaExceeded, "Storage quota exceeded")
+	case errors.Is(err, models.ErrFileScanFailed):
+		response.ServiceUnavailable(w, "File scanning is temporarily unavailable, try again later")
+	default:
+		return false
+	}
+	return true
+}
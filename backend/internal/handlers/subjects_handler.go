@@ -11,12 +11,14 @@ import (
 	"tutoring-platform/internal/middleware"
 	"tutoring-platform/internal/models"
 	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
 	"tutoring-platform/pkg/response"
 )
 
 // SubjectsHandler обрабатывает эндпоинты предметов
 type SubjectsHandler struct {
-	subjectRepo repository.SubjectRepository
+	subjectRepo  repository.SubjectRepository
+	auditService *service.AuditService
 }
 
 // NewSubjectsHandler создает новый SubjectsHandler
@@ -26,6 +28,11 @@ func NewSubjectsHandler(subjectRepo repository.SubjectRepository) *SubjectsHandl
 	}
 }
 
+// SetAuditService устанавливает журнал аудита для изменений предметов
+func (h *SubjectsHandler) SetAuditService(auditService *service.AuditService) {
+	h.auditService = auditService
+}
+
 // GetSubjects обрабатывает GET /api/v1/subjects
 // @Summary      List all subjects
 // @Description  Get list of all available subjects
@@ -94,13 +101,13 @@ func (h *SubjectsHandler) GetSubject(w http.ResponseWriter, r *http.Request) {
 // @Security     SessionAuth
 // @Router       /subjects [post]
 func (h *SubjectsHandler) CreateSubject(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermSubjectsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -125,6 +132,7 @@ func (h *SubjectsHandler) CreateSubject(w http.ResponseWriter, r *http.Request)
 		response.InternalError(w, "Failed to create subject")
 		return
 	}
+	h.auditService.Record(r.Context(), models.AuditActionSubjectCreate, models.AuditTargetSubject, subject.ID.String(), nil, subject)
 
 	w.WriteHeader(http.StatusCreated)
 	response.OK(w, subject)
@@ -146,13 +154,13 @@ func (h *SubjectsHandler) CreateSubject(w http.ResponseWriter, r *http.Request)
 // @Security     SessionAuth
 // @Router       /subjects/{id} [put]
 func (h *SubjectsHandler) UpdateSubject(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermSubjectsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -175,6 +183,8 @@ func (h *SubjectsHandler) UpdateSubject(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	before := *subject
+
 	var req models.UpdateSubjectRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
@@ -201,6 +211,7 @@ func (h *SubjectsHandler) UpdateSubject(w http.ResponseWriter, r *http.Request)
 		response.InternalError(w, "Failed to update subject")
 		return
 	}
+	h.auditService.Record(r.Context(), models.AuditActionSubjectUpdate, models.AuditTargetSubject, id.String(), &before, subject)
 
 	response.OK(w, subject)
 }
@@ -219,13 +230,13 @@ func (h *SubjectsHandler) UpdateSubject(w http.ResponseWriter, r *http.Request)
 // @Security     SessionAuth
 // @Router       /subjects/{id} [delete]
 func (h *SubjectsHandler) DeleteSubject(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermSubjectsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -238,7 +249,7 @@ func (h *SubjectsHandler) DeleteSubject(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Проверяем, что предмет существует
-	_, err = h.subjectRepo.GetByID(r.Context(), id)
+	subject, err := h.subjectRepo.GetByID(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, repository.ErrSubjectNotFound) {
 			response.NotFound(w, "Subject not found")
@@ -252,6 +263,7 @@ func (h *SubjectsHandler) DeleteSubject(w http.ResponseWriter, r *http.Request)
 		response.InternalError(w, "Failed to delete subject")
 		return
 	}
+	h.auditService.Record(r.Context(), models.AuditActionSubjectDelete, models.AuditTargetSubject, id.String(), subject, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -301,13 +313,13 @@ func (h *SubjectsHandler) GetTeacherSubjects(w http.ResponseWriter, r *http.Requ
 // @Security     SessionAuth
 // @Router       /teachers/{id}/subjects [post]
 func (h *SubjectsHandler) AssignSubjectToTeacher(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermSubjectsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -339,6 +351,8 @@ func (h *SubjectsHandler) AssignSubjectToTeacher(w http.ResponseWriter, r *http.
 		response.InternalError(w, "Failed to assign subject")
 		return
 	}
+	h.auditService.Record(r.Context(), models.AuditActionSubjectAssign, models.AuditTargetUser, teacherID.String(),
+		nil, map[string]interface{}{"subject_id": req.SubjectID})
 
 	w.WriteHeader(http.StatusCreated)
 	response.OK(w, map[string]interface{}{
@@ -360,13 +374,13 @@ func (h *SubjectsHandler) AssignSubjectToTeacher(w http.ResponseWriter, r *http.
 // @Security     SessionAuth
 // @Router       /teachers/{id}/subjects/{subjectId} [delete]
 func (h *SubjectsHandler) RemoveSubjectFromTeacher(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	if !user.IsAdmin() && !user.IsTeacher() {
+	if !middleware.HasPermission(r.Context(), models.PermSubjectsManage) {
 		response.Forbidden(w, "Admin or teacher access required")
 		return
 	}
@@ -389,6 +403,8 @@ func (h *SubjectsHandler) RemoveSubjectFromTeacher(w http.ResponseWriter, r *htt
 		response.InternalError(w, "Failed to remove subject")
 		return
 	}
+	h.auditService.Record(r.Context(), models.AuditActionSubjectUnassign, models.AuditTargetUser, teacherID.String(),
+		map[string]interface{}{"subject_id": subjectID}, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
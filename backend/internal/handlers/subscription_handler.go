@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// SubscriptionHandler обрабатывает эндпоинты подписок (пользователь) и тарифов (admin)
+type SubscriptionHandler struct {
+	subscriptionService *service.SubscriptionService
+}
+
+// NewSubscriptionHandler создает новый SubscriptionHandler
+func NewSubscriptionHandler(subscriptionService *service.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+// ListPlans обрабатывает GET /api/v1/subscriptions/plans
+// @Summary      List subscription plans
+// @Description  Get subscription plans available for purchase
+// @Tags         subscriptions
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=[]models.SubscriptionPlan}
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /subscriptions/plans [get]
+func (h *SubscriptionHandler) ListPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.subscriptionService.ListPlans(r.Context(), true)
+	if err != nil {
+		response.InternalError(w, "Failed to retrieve subscription plans")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"plans": plans,
+	})
+}
+
+// GetMySubscription обрабатывает GET /api/v1/subscriptions/me
+// @Summary      Get my subscription
+// @Description  Get the current user's live subscription and its invoices
+// @Tags         subscriptions
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=interface{}}
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /subscriptions/me [get]
+func (h *SubscriptionHandler) GetMySubscription(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	sub, err := h.subscriptionService.GetUserSubscription(r.Context(), user.ID)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve subscription")
+		return
+	}
+
+	invoices, err := h.subscriptionService.GetUserInvoices(r.Context(), user.ID)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve subscription invoices")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"subscription": sub,
+		"invoices":     invoices,
+	})
+}
+
+// Subscribe обрабатывает POST /api/v1/subscriptions
+// @Summary      Subscribe to a plan
+// @Description  Create a subscription and return YooKassa confirmation URL for the first payment
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.SubscribeRequest  true  "Plan"
+// @Success      201   {object}  response.SuccessResponse{data=models.SubscribeResponse}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      403   {object}  response.ErrorResponse
+// @Failure      409   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /subscriptions [post]
+func (h *SubscriptionHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var req models.SubscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	result, err := h.subscriptionService.Subscribe(r.Context(), user.ID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to create subscription")
+		return
+	}
+
+	response.Created(w, result)
+}
+
+// ChangePlan обрабатывает POST /api/v1/subscriptions/me/plan
+// @Summary      Change subscription plan
+// @Description  Upgrade immediately with a prorated charge, or schedule a downgrade for the next period
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.ChangeSubscriptionPlanRequest  true  "New plan"
+// @Success      200   {object}  response.SuccessResponse{data=models.ChangeSubscriptionPlanResponse}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      404   {object}  response.ErrorResponse
+// @Failure      409   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /subscriptions/me/plan [post]
+func (h *SubscriptionHandler) ChangePlan(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var req models.ChangeSubscriptionPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	result, err := h.subscriptionService.ChangePlan(r.Context(), user.ID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to change subscription plan")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// CancelMySubscription обрабатывает POST /api/v1/subscriptions/me/cancel
+// @Summary      Cancel my subscription
+// @Description  Cancel at period end (default) or immediately; credits already granted are kept
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.CancelSubscriptionRequest  false  "Cancellation options"
+// @Success      200   {object}  response.SuccessResponse{data=models.SubscriptionWithPlan}
+// @Failure      404   {object}  response.ErrorResponse
+// @Failure      409   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /subscriptions/me/cancel [post]
+func (h *SubscriptionHandler) CancelMySubscription(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	req, ok := decodeCancelSubscriptionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.CancelSubscription(r.Context(), user.ID, req)
+	if err != nil {
+		h.handleError(w, err, "Failed to cancel subscription")
+		return
+	}
+
+	response.OK(w, sub)
+}
+
+// ResumeMySubscription обрабатывает POST /api/v1/subscriptions/me/resume
+// @Summary      Resume my subscription
+// @Description  Undo a cancellation scheduled for the end of the period
+// @Tags         subscriptions
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=models.SubscriptionWithPlan}
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /subscriptions/me/resume [post]
+func (h *SubscriptionHandler) ResumeMySubscription(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	sub, err := h.subscriptionService.ResumeSubscription(r.Context(), user.ID)
+	if err != nil {
+		h.handleError(w, err, "Failed to resume subscription")
+		return
+	}
+
+	response.OK(w, sub)
+}
+
+// AdminListPlans обрабатывает GET /api/v1/admin/subscriptions/plans
+// @Summary      List all subscription plans
+// @Tags         subscriptions
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=[]models.SubscriptionPlan}
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/subscriptions/plans [get]
+func (h *SubscriptionHandler) AdminListPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.subscriptionService.ListPlans(r.Context(), false)
+	if err != nil {
+		response.InternalError(w, "Failed to retrieve subscription plans")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"plans": plans,
+	})
+}
+
+// CreatePlan обрабатывает POST /api/v1/admin/subscriptions/plans
+// @Summary      Create subscription plan
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.CreateSubscriptionPlanRequest  true  "Plan"
+// @Success      201   {object}  response.SuccessResponse{data=models.SubscriptionPlan}
+// @Failure      400   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/subscriptions/plans [post]
+func (h *SubscriptionHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateSubscriptionPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	plan, err := h.subscriptionService.CreatePlan(r.Context(), &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to create subscription plan")
+		return
+	}
+
+	response.Created(w, plan)
+}
+
+// UpdatePlan обрабатывает PUT /api/v1/admin/subscriptions/plans/{id}
+// @Summary      Update subscription plan
+// @Description  Price and credit changes apply to existing subscriptions from their next renewal
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                                true  "Plan ID"
+// @Param        body  body      models.UpdateSubscriptionPlanRequest  true  "Changes"
+// @Success      200   {object}  response.SuccessResponse{data=models.SubscriptionPlan}
+// @Failure      400   {object}  response.ErrorResponse
+// @Failure      404   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/subscriptions/plans/{id} [put]
+func (h *SubscriptionHandler) UpdatePlan(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid plan ID")
+		return
+	}
+
+	var req models.UpdateSubscriptionPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	plan, err := h.subscriptionService.UpdatePlan(r.Context(), id, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to update subscription plan")
+		return
+	}
+
+	response.OK(w, plan)
+}
+
+// AdminListSubscriptions обрабатывает GET /api/v1/admin/subscriptions
+// @Summary      List subscriptions
+// @Tags         subscriptions
+// @Produce      json
+// @Param        status  query     string  false  "Status filter (pending, active, past_due, cancelled, expired)"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.SubscriptionWithPlan}
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/subscriptions [get]
+func (h *SubscriptionHandler) AdminListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	var status *models.SubscriptionStatus
+	if raw := r.URL.Query().Get("status"); raw != "" {
+		s := models.SubscriptionStatus(raw)
+		status = &s
+	}
+
+	subs, err := h.subscriptionService.ListSubscriptions(r.Context(), status)
+	if err != nil {
+		response.InternalError(w, "Failed to retrieve subscriptions")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"subscriptions": subs,
+	})
+}
+
+// AdminGetInvoices обрабатывает GET /api/v1/admin/subscriptions/{id}/invoices
+// @Summary      List subscription invoices
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id   path      string  true  "Subscription ID"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.SubscriptionInvoice}
+// @Failure      404  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/subscriptions/{id}/invoices [get]
+func (h *SubscriptionHandler) AdminGetInvoices(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid subscription ID")
+		return
+	}
+
+	invoices, err := h.subscriptionService.GetSubscriptionInvoices(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to retrieve subscription invoices")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"invoices": invoices,
+	})
+}
+
+// AdminCancelSubscription обрабатывает POST /api/v1/admin/subscriptions/{id}/cancel
+// @Summary      Cancel subscription
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                            true   "Subscription ID"
+// @Param        body  body      models.CancelSubscriptionRequest  false  "Cancellation options"
+// @Success      200   {object}  response.SuccessResponse{data=models.SubscriptionWithPlan}
+// @Failure      404   {object}  response.ErrorResponse
+// @Failure      409   {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) AdminCancelSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid subscription ID")
+		return
+	}
+
+	req, ok := decodeCancelSubscriptionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.CancelSubscriptionByID(r.Context(), id, req)
+	if err != nil {
+		h.handleError(w, err, "Failed to cancel subscription")
+		return
+	}
+
+	response.OK(w, sub)
+}
+
+// handleError преобразует ошибки сервиса подписок в HTTP ответы
+func (h *SubscriptionHandler) handleError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrSubscriptionNotFound):
+		response.NotFound(w, "Subscription not found")
+	case errors.Is(err, repository.ErrSubscriptionPlanNotFound):
+		response.NotFound(w, "Subscription plan not found")
+	case errors.Is(err, repository.ErrPaymentDisabledForUser):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, models.ErrSubscriptionAlreadyExists),
+		errors.Is(err, models.ErrSubscriptionNotActive),
+		errors.Is(err, models.ErrSubscriptionSamePlan),
+		errors.Is(err, models.ErrSubscriptionNoPaymentMethod),
+		errors.Is(err, models.ErrSubscriptionPaymentInProcess),
+		errors.Is(err, models.ErrPlanNotActive):
+		response.Conflict(w, response.ErrCodeConflict, err.Error())
+	case errors.Is(err, models.ErrInvalidPlanName),
+		errors.Is(err, models.ErrPlanDescriptionTooLong),
+		errors.Is(err, models.ErrInvalidPlanCredits),
+		errors.Is(err, models.ErrInvalidPlanPrice),
+		errors.Is(err, models.ErrInvalidPlanPeriod),
+		errors.Is(err, models.ErrInvalidPlanID):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	default:
+		response.InternalError(w, fallback)
+	}
+}
+
+// decodeCancelSubscriptionRequest разбирает необязательное тело запроса отмены подписки
+func decodeCancelSubscriptionRequest(w http.ResponseWriter, r *http.Request) (*models.CancelSubscriptionRequest, bool) {
+	req := &models.CancelSubscriptionRequest{}
+	if r.ContentLength == 0 {
+		return req, true
+	}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return nil, false
+	}
+	return req, true
+}
@@ -129,19 +129,17 @@ func (h *SwapHandler) GetSwapHistory(w http.ResponseWriter, r *http.Request) {
 
 	filter := &models.GetSwapHistoryFilter{}
 
-	// Студенты видят только свою историю обменов
-	if user.IsStudent() {
-		filter.StudentID = &user.ID
-	} else if user.IsAdmin() {
-		// Админы могут фильтровать по student_id, если указан
+	// С правом credits.read.all можно фильтровать по student_id, если указан; остальные видят только свою историю обменов
+	if middleware.HasPermission(r.Context(), models.PermCreditsReadAll) {
 		if studentIDStr := r.URL.Query().Get("student_id"); studentIDStr != "" {
 			studentID, err := uuid.Parse(studentIDStr)
 			if err == nil {
 				filter.StudentID = &studentID
 			}
 		}
+	} else {
+		filter.StudentID = &user.ID
 	}
-	// Преподаватели в настоящее время не имеют доступа к истории обменов (не реализовано в модели)
 
 	// Парсим опциональные фильтры по дате
 	if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
@@ -10,6 +10,7 @@ import (
 	"log"
 
 	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
 	"tutoring-platform/internal/service"
 	"tutoring-platform/pkg/response"
 )
@@ -70,8 +71,14 @@ func (h *TrialRequestHandler) CreateTrialRequest(w http.ResponseWriter, r *http.
 
 // GetTrialRequests обрабатывает GET /api/v1/trial-requests
 // Это эндпоинт только для админов
+// Query параметры: status (new, contacted, scheduled, converted, lost), assigned_to (UUID, me или none)
 func (h *TrialRequestHandler) GetTrialRequests(w http.ResponseWriter, r *http.Request) {
-	requests, err := h.service.GetAllTrialRequests(r.Context())
+	filter, ok := parseTrialRequestFilter(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := h.service.ListTrialRequests(r.Context(), filter)
 	if err != nil {
 		log.Printf("ERROR: Failed to retrieve trial requests: %v", err)
 		response.InternalError(w, "Failed to retrieve trial requests")
@@ -100,14 +107,18 @@ func (h *TrialRequestHandler) GetTrialRequestByID(w http.ResponseWriter, r *http
 		return
 	}
 
-	trialRequest, err := h.service.GetTrialRequestByID(r.Context(), id)
+	details, err := h.service.GetTrialRequestDetails(r.Context(), id)
 	if err != nil {
+		if errors.Is(err, repository.ErrTrialRequestNotFound) {
+			response.NotFound(w, "Trial request not found")
+			return
+		}
 		log.Printf("ERROR: Failed to get trial request by ID %d: %v", id, err)
-		response.NotFound(w, "Trial request not found")
+		response.InternalError(w, "Failed to retrieve trial request")
 		return
 	}
 
 	response.OK(w, map[string]interface{}{
-		"trial_request": trialRequest,
+		"trial_request": details,
 	})
 }
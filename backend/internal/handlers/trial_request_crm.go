@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/pkg/response"
+)
+
+// UpdateTrialRequestStatus обрабатывает PUT /api/v1/admin/trial-requests/{id}/status
+// Это эндпоинт только для админов
+func (h *TrialRequestHandler) UpdateTrialRequestStatus(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseTrialRequestID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTrialRequestStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	trialRequest, err := h.service.UpdateStatus(r.Context(), id, &req, user.ID)
+	if err != nil {
+		h.handleCRMError(w, err, "Failed to update trial request status")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"trial_request": trialRequest,
+	})
+}
+
+// AssignTrialRequest обрабатывает PUT /api/v1/admin/trial-requests/{id}/assignee
+// Это эндпоинт только для админов
+func (h *TrialRequestHandler) AssignTrialRequest(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseTrialRequestID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AssignTrialRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	trialRequest, err := h.service.Assign(r.Context(), id, &req, user.ID)
+	if err != nil {
+		h.handleCRMError(w, err, "Failed to assign trial request")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"trial_request": trialRequest,
+	})
+}
+
+// SetTrialRequestFollowUp обрабатывает PUT /api/v1/admin/trial-requests/{id}/follow-up
+// Это эндпоинт только для админов
+func (h *TrialRequestHandler) SetTrialRequestFollowUp(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseTrialRequestID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SetTrialRequestFollowUpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	trialRequest, err := h.service.SetFollowUp(r.Context(), id, &req, user.ID)
+	if err != nil {
+		h.handleCRMError(w, err, "Failed to set trial request follow-up")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"trial_request": trialRequest,
+	})
+}
+
+// AddTrialRequestNote обрабатывает POST /api/v1/admin/trial-requests/{id}/notes
+// Это эндпоинт только для админов
+func (h *TrialRequestHandler) AddTrialRequestNote(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseTrialRequestID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AddTrialRequestNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	activity, err := h.service.AddNote(r.Context(), id, &req, user.ID)
+	if err != nil {
+		h.handleCRMError(w, err, "Failed to add trial request note")
+		return
+	}
+
+	response.Created(w, map[string]interface{}{
+		"activity": activity,
+	})
+}
+
+// ConvertTrialRequest обрабатывает POST /api/v1/admin/trial-requests/{id}/convert
+// Создает студента, пробное занятие и запись на него. Временный пароль студента возвращается один раз
+// Это эндпоинт только для админов
+func (h *TrialRequestHandler) ConvertTrialRequest(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	id, ok := parseTrialRequestID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ConvertTrialRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.ConvertTrialRequest(r.Context(), id, &req, user.ID)
+	if err != nil {
+		h.handleCRMError(w, err, "Failed to convert trial request")
+		return
+	}
+
+	response.Created(w, result)
+}
+
+// handleCRMError преобразует ошибки CRM воронки в HTTP ответы
+func (h *TrialRequestHandler) handleCRMError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrTrialRequestNotFound):
+		response.NotFound(w, "Trial request not found")
+	case errors.Is(err, repository.ErrUserExists):
+		response.Conflict(w, response.ErrCodeAlreadyExists, "User with this email already exists")
+	case errors.Is(err, repository.ErrLessonOverlapConflict):
+		response.Conflict(w, response.ErrCodeScheduleConflict, "Teacher already has a lesson at this time")
+	case errors.Is(err, models.ErrTrialRequestAlreadyConverted),
+		errors.Is(err, models.ErrTrialRequestClosed),
+		errors.Is(err, models.ErrInvalidTrialRequestTransition):
+		response.Conflict(w, response.ErrCodeConflict, err.Error())
+	case errors.Is(err, models.ErrInvalidTrialRequestStatus),
+		errors.Is(err, models.ErrTrialRequestConvertViaAction),
+		errors.Is(err, models.ErrInvalidTrialRequestLostReason),
+		errors.Is(err, models.ErrInvalidTrialRequestNote),
+		errors.Is(err, models.ErrTrialRequestFollowUpInPast),
+		errors.Is(err, models.ErrInvalidTrialRequestAssignee),
+		errors.Is(err, models.ErrInvalidUserID),
+		errors.Is(err, models.ErrInvalidEmail),
+		errors.Is(err, models.ErrInvalidFullName),
+		errors.Is(err, models.ErrInvalidTeacherID),
+		errors.Is(err, models.ErrInvalidLessonTime),
+		errors.Is(err, models.ErrInvalidColor),
+		errors.Is(err, models.ErrSubjectTooLong):
+		response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+	default:
+		log.Printf("ERROR: %s: %v", fallback, err)
+		response.InternalError(w, fallback)
+	}
+}
+
+// parseTrialRequestFilter разбирает фильтры списка заявок из query параметров
+func parseTrialRequestFilter(w http.ResponseWriter, r *http.Request) (models.TrialRequestFilter, bool) {
+	var filter models.TrialRequestFilter
+	query := r.URL.Query()
+
+	if raw := query.Get("status"); raw != "" {
+		status := models.TrialRequestStatus(raw)
+		if !status.IsValid() {
+			response.BadRequest(w, response.ErrCodeValidationFailed, models.ErrInvalidTrialRequestStatus.Error())
+			return filter, false
+		}
+		filter.Status = &status
+	}
+
+	switch raw := query.Get("assigned_to"); raw {
+	case "":
+	case "none":
+		filter.Unassigned = true
+	case "me":
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			response.Unauthorized(w, "Authentication required")
+			return filter, false
+		}
+		filter.AssignedTo = &user.ID
+	default:
+		assignee, err := uuid.Parse(raw)
+		if err != nil {
+			response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid assigned_to")
+			return filter, false
+		}
+		filter.AssignedTo = &assignee
+	}
+
+	return filter, true
+}
+
+// parseTrialRequestID разбирает ID заявки из URL
+func parseTrialRequestID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid trial request ID")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// UploadQuarantineHandler обрабатывает эндпоинты карантина загрузок, отклоненных антивирусом
+type UploadQuarantineHandler struct {
+	fileStorage *service.FileStorageService
+}
+
+// NewUploadQuarantineHandler создает новый UploadQuarantineHandler
+func NewUploadQuarantineHandler(fileStorage *service.FileStorageService) *UploadQuarantineHandler {
+	return &UploadQuarantineHandler{
+		fileStorage: fileStorage,
+	}
+}
+
+// ListQuarantine обрабатывает GET /api/v1/admin/moderation/quarantine
+// @Summary      Upload quarantine
+// @Description  Uploads rejected by the malware scan, newest first
+// @Tags         moderation
+// @Produce      json
+// @Param        limit   query     int  false  "Page size (default 50, max 200)"
+// @Param        offset  query     int  false  "Offset"
+// @Success      200  {object}  response.SuccessResponse{data=[]models.QuarantinedFile}
+// @Failure      400  {object}  response.ErrorResponse
+// @Security     SessionAuth
+// @Router       /admin/moderation/quarantine [get]
+func (h *UploadQuarantineHandler) ListQuarantine(w http.ResponseWriter, r *http.Request) {
+	filter := &models.QuarantineFilter{}
+
+	var ok bool
+	if filter.Limit, ok = parseOptionalIntQuery(w, r, "limit"); !ok {
+		return
+	}
+	if filter.Offset, ok = parseOptionalIntQuery(w, r, "offset"); !ok {
+		return
+	}
+
+	items, total, err := h.fileStorage.ListQuarantine(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidQuarantineFilter) {
+			response.BadRequest(w, response.ErrCodeValidationFailed, err.Error())
+			return
+		}
+		log.Printf("ERROR: Failed to list upload quarantine: %v", err)
+		response.InternalError(w, "Failed to list quarantined files")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"items":  items,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
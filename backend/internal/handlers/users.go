@@ -43,7 +43,7 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 // @Security     SessionAuth
 // @Router       /users [get]
 func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
@@ -59,8 +59,8 @@ func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Админы и методисты могут получить полный список без фильтров
-	if !user.IsAdmin() && !user.IsTeacher() {
+	// Полный список без фильтра по роли доступен с правом users.list.all
+	if !middleware.HasPermission(r.Context(), models.PermUsersListAll) {
 		// Не-админы и не-методисты должны указать role
 		if roleFilter == nil {
 			response.Forbidden(w, "Admin or teacher access required for unfiltered user list")
@@ -110,8 +110,8 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Только админы могут создавать пользователей
-	if !user.IsAdmin() {
+	// Создание пользователей требует права users.create
+	if !middleware.HasPermission(r.Context(), models.PermUsersCreate) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -123,7 +123,7 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Создаем пользователя
-	newUser, err := h.userService.CreateUser(r.Context(), &req)
+	newUser, err := h.userService.CreateUser(r.Context(), user, &req)
 	if err != nil {
 		h.handleUserError(w, err)
 		return
@@ -147,14 +147,14 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 // @Security     SessionAuth
 // @Router       /users/{id} [get]
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
-	user, ok := middleware.GetUserFromContext(r.Context())
+	_, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	// Только админы могут получать детали пользователя
-	if !user.IsAdmin() {
+	// Детали пользователя доступны с правом users.read
+	if !middleware.HasPermission(r.Context(), models.PermUsersRead) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -196,8 +196,8 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Только админы могут обновлять пользователей
-	if !user.IsAdmin() {
+	// Изменение пользователей требует права users.edit
+	if !middleware.HasPermission(r.Context(), models.PermUsersEdit) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -215,7 +215,7 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Обновляем пользователя
-	updatedUser, err := h.userService.UpdateUser(r.Context(), userID, &req)
+	updatedUser, err := h.userService.UpdateUser(r.Context(), user, userID, &req)
 	if err != nil {
 		h.handleUserError(w, err)
 		return
@@ -245,8 +245,8 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Только админы могут удалять пользователей
-	if !user.IsAdmin() {
+	// Удаление пользователей требует права users.delete
+	if !middleware.HasPermission(r.Context(), models.PermUsersDelete) {
 		response.Forbidden(w, "Admin access required")
 		return
 	}
@@ -264,7 +264,7 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Удаляем пользователя
-	if err := h.userService.DeleteUser(r.Context(), userID); err != nil {
+	if err := h.userService.DeleteUser(r.Context(), user, userID); err != nil {
 		h.handleUserError(w, err)
 		return
 	}
@@ -302,6 +302,16 @@ func (h *UserHandler) handleUserError(w http.ResponseWriter, err error) {
 		return
 	}
 
+	// Проверяем ошибки прав на назначение ролей
+	if errors.Is(err, models.ErrRoleAssignDenied) {
+		response.Forbidden(w, "Not allowed to assign this role")
+		return
+	}
+	if errors.Is(err, models.ErrUserOutranksActor) {
+		response.Forbidden(w, "Not allowed to modify a user with a higher role")
+		return
+	}
+
 	// Проверяем ошибки models (валидация)
 	if errors.Is(err, models.ErrInvalidEmail) {
 		response.BadRequest(w, response.ErrCodeValidationFailed, "Invalid email address. Must be in format: user@example.com")
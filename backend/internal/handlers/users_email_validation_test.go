@@ -103,7 +103,7 @@ func TestUpdateUser_EmailValidation(t *testing.T) {
 			require.NoError(t, err)
 
 			// Вызываем сервис
-			result, err := userService.UpdateUser(ctx, targetUserID, &updateReq)
+			result, err := userService.UpdateUser(ctx, admin, targetUserID, &updateReq)
 
 			// Проверяем результаты
 			if tt.expectedStatus == http.StatusOK {
@@ -141,7 +141,7 @@ func TestUpdateUser_DuplicateEmail(t *testing.T) {
 		Email: &newEmail,
 	}
 
-	_, err := userService.UpdateUser(ctx, student2.ID, updateReq)
+	_, err := userService.UpdateUser(ctx, testAdminActor, student2.ID, updateReq)
 	assert.Error(t, err, "expected error when assigning duplicate email")
 	assert.True(t, errors.Is(err, repository.ErrUserExists), "should be ErrUserExists error")
 }
@@ -174,7 +174,7 @@ func TestUpdateUser_PreservesOtherFields(t *testing.T) {
 		Email: &newEmail,
 	}
 
-	result, err := userService.UpdateUser(ctx, student.ID, updateReq)
+	result, err := userService.UpdateUser(ctx, testAdminActor, student.ID, updateReq)
 	require.NoError(t, err, "update should succeed")
 	require.NotNil(t, result, "result should not be nil")
 
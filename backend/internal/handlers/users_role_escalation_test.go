@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tutoring-platform/internal/middleware"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roleEscalationUserRepo хранит пользователей в памяти для проверки назначения ролей
+type roleEscalationUserRepo struct {
+	users map[uuid.UUID]*models.User
+}
+
+func (m *roleEscalationUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (m *roleEscalationUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (m *roleEscalationUserRepo) Create(ctx context.Context, user *models.User) error {
+	user.ID = uuid.New()
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *roleEscalationUserRepo) CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	return m.Create(ctx, user)
+}
+
+func (m *roleEscalationUserRepo) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	user, ok := m.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	if role, ok := updates["role"]; ok {
+		user.Role = role.(models.UserRole)
+	}
+	if firstName, ok := updates["first_name"]; ok {
+		user.FirstName = firstName.(string)
+	}
+	return nil
+}
+
+func (m *roleEscalationUserRepo) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return nil
+}
+
+func (m *roleEscalationUserRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(m.users, id)
+	return nil
+}
+
+func (m *roleEscalationUserRepo) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	return m.Delete(ctx, id)
+}
+
+func (m *roleEscalationUserRepo) List(ctx context.Context, roleFilter *models.UserRole) ([]*models.User, error) {
+	return nil, nil
+}
+
+func (m *roleEscalationUserRepo) ListWithPagination(ctx context.Context, roleFilter *models.UserRole, offset, limit int) ([]*models.User, int, error) {
+	return nil, 0, nil
+}
+
+func (m *roleEscalationUserRepo) Exists(ctx context.Context, email string) (bool, error) {
+	_, err := m.GetByEmail(ctx, email)
+	return err == nil, nil
+}
+
+func (m *roleEscalationUserRepo) UpdateTelegramUsername(ctx context.Context, userID uuid.UUID, username string) error {
+	return nil
+}
+
+func (m *roleEscalationUserRepo) GetAllUsersWithTelegramInfo(ctx context.Context) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+// staticRoleRegistry права ролей как в миграции 074, включая пользовательскую роль manager
+type staticRoleRegistry map[models.UserRole]models.PermissionSet
+
+func (r staticRoleRegistry) RoleExists(ctx context.Context, role models.UserRole) (bool, error) {
+	_, ok := r[role]
+	return ok, nil
+}
+
+func (r staticRoleRegistry) PermissionsFor(ctx context.Context, role models.UserRole) models.PermissionSet {
+	if set, ok := r[role]; ok {
+		return set
+	}
+	return models.NewPermissionSet()
+}
+
+const roleManager models.UserRole = "manager"
+
+func newRoleEscalationFixture(t *testing.T) (http.Handler, map[models.UserRole]*models.User) {
+	t.Helper()
+
+	registry := staticRoleRegistry{
+		models.RoleAdmin:   models.DefaultRolePermissions[models.RoleAdmin],
+		models.RoleTeacher: models.DefaultRolePermissions[models.RoleTeacher],
+		models.RoleStudent: models.DefaultRolePermissions[models.RoleStudent],
+		roleManager: models.NewPermissionSet(
+			models.PermUsersRead, models.PermUsersListAll, models.PermUsersCreate, models.PermUsersEdit,
+			models.PermCreditsReadAll, models.PermCreditsGrant,
+			models.PermTrialRequestsRead, models.PermTrialRequestsManage, models.PermGroupsManage,
+			models.PermPaymentsManage, models.PermAnalyticsRead,
+		),
+	}
+
+	repo := &roleEscalationUserRepo{users: make(map[uuid.UUID]*models.User)}
+	users := make(map[models.UserRole]*models.User)
+	for _, role := range []models.UserRole{models.RoleAdmin, roleManager, models.RoleStudent} {
+		user := &models.User{ID: uuid.New(), Email: string(role) + "@example.com", FirstName: "Test", LastName: string(role), Role: role}
+		repo.users[user.ID] = user
+		users[role] = user
+	}
+
+	userService := service.NewUserService(repo, nil)
+	userService.SetPermissionService(registry)
+	handler := NewUserHandler(userService)
+
+	router := chi.NewRouter()
+	// Вместо Authenticate и LoadPermissions: пользователь и его права берутся из заголовка X-Test-Role
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := models.UserRole(r.Header.Get("X-Test-Role"))
+			ctx := context.WithValue(r.Context(), middleware.UserContextKey, users[role])
+			ctx = context.WithValue(ctx, middleware.PermissionsContextKey, registry.PermissionsFor(ctx, role))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	router.Post("/users", handler.CreateUser)
+	router.Put("/users/{id}", handler.UpdateUser)
+	router.Delete("/users/{id}", handler.DeleteUser)
+	return router, users
+}
+
+func roleEscalationRequest(router http.Handler, actor models.UserRole, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", string(actor))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestUserRoleEscalation менеджер с правами users.create и users.edit не может повысить себя
+// или других до администратора и не может изменять пользователей с большими правами
+func TestUserRoleEscalation(t *testing.T) {
+	router, users := newRoleEscalationFixture(t)
+	manager := users[roleManager]
+
+	t.Run("manager cannot promote self to admin", func(t *testing.T) {
+		rec := roleEscalationRequest(router, roleManager, http.MethodPut, "/users/"+manager.ID.String(), `{"role":"admin"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+		assert.Equal(t, roleManager, manager.Role)
+	})
+
+	t.Run("manager cannot create admin", func(t *testing.T) {
+		rec := roleEscalationRequest(router, roleManager, http.MethodPost, "/users",
+			`{"email":"new-admin@example.com","password":"password123","first_name":"New","last_name":"Admin","role":"admin"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+	})
+
+	t.Run("manager cannot promote student", func(t *testing.T) {
+		rec := roleEscalationRequest(router, roleManager, http.MethodPut, "/users/"+users[models.RoleStudent].ID.String(), `{"role":"manager"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+		assert.Equal(t, models.RoleStudent, users[models.RoleStudent].Role)
+	})
+
+	t.Run("manager cannot edit admin", func(t *testing.T) {
+		rec := roleEscalationRequest(router, roleManager, http.MethodPut, "/users/"+users[models.RoleAdmin].ID.String(), `{"first_name":"Hacked"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+		assert.Equal(t, "Test", users[models.RoleAdmin].FirstName)
+	})
+
+	t.Run("manager edits student and keeps unchanged role", func(t *testing.T) {
+		rec := roleEscalationRequest(router, roleManager, http.MethodPut, "/users/"+users[models.RoleStudent].ID.String(), `{"first_name":"Renamed","role":"student"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "Renamed", users[models.RoleStudent].FirstName)
+	})
+
+	t.Run("admin changes manager role", func(t *testing.T) {
+		rec := roleEscalationRequest(router, models.RoleAdmin, http.MethodPut, "/users/"+manager.ID.String(), `{"role":"teacher"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, models.RoleTeacher, manager.Role)
+	})
+}
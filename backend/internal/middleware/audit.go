@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/service"
+)
+
+// AuditMiddleware добавляет в контекст метаданные запроса для журнала аудита (автор, IP, request ID).
+// Успешные изменяющие запросы администратора, по которым сервисы не записали подробное событие,
+// записываются общим событием http.request, так что ни одно привилегированное изменение не теряется.
+// В сессии имперсонации автором считается администратор, и событием impersonation.request
+// записывается каждый запрос. Должен подключаться после Authenticate
+func AuditMiddleware(auditService *service.AuditService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auditReq := &service.AuditRequest{
+				IP:        clientIP(r),
+				RequestID: chiMiddleware.GetReqID(r.Context()),
+				Method:    r.Method,
+				Path:      r.URL.Path,
+			}
+			user, ok := GetUserFromContext(r.Context())
+			if ok {
+				auditReq.ActorID = user.ID
+				auditReq.ActorRole = user.Role
+			}
+			session, hasSession := GetSessionFromContext(r.Context())
+			impersonating := ok && hasSession && session.IsImpersonation()
+			if impersonating {
+				auditReq.ActorID = session.ImpersonatorID.UUID
+				auditReq.ActorRole = models.RoleAdmin
+			}
+
+			ctx := service.WithAuditRequest(r.Context(), auditReq)
+			wrapped := newResponseWriter(w)
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			if impersonating {
+				auditService.Record(ctx, models.AuditActionImpersonationRequest, models.AuditTargetUser, user.ID.String(), nil, map[string]interface{}{
+					"session_id": session.ID,
+					"route":      routePattern(ctx, r),
+					"status":     wrapped.Status(),
+				})
+				return
+			}
+
+			if !ok || !user.IsAdmin() || !isMutatingMethod(r.Method) ||
+				wrapped.Status() >= http.StatusBadRequest || auditReq.Recorded() {
+				return
+			}
+
+			targetID := ""
+			if rctx := chi.RouteContext(ctx); rctx != nil {
+				targetID = rctx.URLParam("id")
+			}
+			auditService.Record(ctx, models.AuditActionHTTPRequest, models.AuditTargetRoute, targetID, nil, map[string]interface{}{
+				"route":  routePattern(ctx, r),
+				"status": wrapped.Status(),
+			})
+		})
+	}
+}
+
+// routePattern возвращает шаблон маршрута chi (или путь, если маршрут не сопоставлен)
+func routePattern(ctx context.Context, r *http.Request) string {
+	if rctx := chi.RouteContext(ctx); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return r.URL.Path
+}
+
+// isMutatingMethod проверяет, изменяет ли запрос состояние
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// clientIP возвращает IP клиента из RemoteAddr (уже подмененного chi RealIP за доверенным прокси)
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 
 	"tutoring-platform/internal/models"
@@ -103,6 +104,10 @@ func (m *MockUserRepository) Create(ctx context.Context, user *models.User) erro
 	return nil
 }
 
+func (m *MockUserRepository) CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	return m.Create(ctx, user)
+}
+
 func (m *MockUserRepository) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
 	return nil
 }
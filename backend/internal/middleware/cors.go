@@ -25,7 +25,7 @@ func DefaultCORSConfig() *CORSConfig {
 		AllowedOrigins:   []string{"http://localhost:3000", "http://localhost:3001", "http://localhost:3002", "http://localhost:3003", "http://localhost:3004", "http://localhost:3005", "http://localhost:3006", "http://localhost:5173", "http://localhost:5174"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"X-CSRF-Token"}, // CSRF токен должен быть доступен frontend
+		ExposedHeaders:   []string{"X-CSRF-Token", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"}, // CSRF токен и состояние лимитов должны быть доступны frontend
 		AllowCredentials: true,
 		MaxAge:           86400, // 24 часа
 	}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"tutoring-platform/pkg/response"
+)
+
+// readOnlyAllowedPaths изменяющие запросы, разрешенные в сессии только для чтения:
+// завершение имперсонации и выход
+var readOnlyAllowedPaths = []string{
+	"/auth/impersonation/stop",
+	"/auth/logout",
+}
+
+// ReadOnlySessionGuard отклоняет изменяющие запросы в сессиях только для чтения
+// (имперсонация без allow_write). Должен подключаться после Authenticate
+func ReadOnlySessionGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, ok := GetSessionFromContext(r.Context())
+		if !ok || !session.ReadOnly || !isMutatingMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		for _, suffix := range readOnlyAllowedPaths {
+			if strings.HasSuffix(r.URL.Path, suffix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		response.Forbidden(w, "Impersonation session is read-only")
+	})
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestReadOnlySessionGuard(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	guard := ReadOnlySessionGuard(okHandler)
+
+	readOnly := &models.SessionWithUser{}
+	readOnly.ReadOnly = true
+	readOnly.ImpersonatorID = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	writable := &models.SessionWithUser{}
+
+	tests := []struct {
+		name    string
+		session *models.SessionWithUser
+		method  string
+		path    string
+		want    int
+	}{
+		{"read-only POST is rejected", readOnly, http.MethodPost, "/api/v1/bookings", http.StatusForbidden},
+		{"read-only PUT is rejected", readOnly, http.MethodPut, "/api/v1/auth/profile", http.StatusForbidden},
+		{"read-only DELETE is rejected", readOnly, http.MethodDelete, "/api/v1/bookings/1", http.StatusForbidden},
+		{"read-only GET is allowed", readOnly, http.MethodGet, "/api/v1/bookings", http.StatusOK},
+		{"read-only impersonation stop is allowed", readOnly, http.MethodPost, "/api/v1/auth/impersonation/stop", http.StatusOK},
+		{"read-only logout is allowed", readOnly, http.MethodPost, "/api/v1/auth/logout", http.StatusOK},
+		{"writable session POST is allowed", writable, http.MethodPost, "/api/v1/bookings", http.StatusOK},
+		{"no session is passed through", nil, http.MethodPost, "/api/v1/auth/login", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.session != nil {
+				req = req.WithContext(SetSessionInContext(req.Context(), tt.session))
+			}
+			w := httptest.NewRecorder()
+			guard.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/service"
+	"tutoring-platform/pkg/response"
+)
+
+// PermissionsContextKey ключ контекста для прав текущего пользователя
+const PermissionsContextKey ContextKey = "permissions"
+
+// LoadPermissions загружает права роли текущего пользователя в контекст.
+// Должен подключаться после Authenticate
+func LoadPermissions(permissionService *service.PermissionService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetUserFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			perms := permissionService.PermissionsFor(r.Context(), user.Role)
+			ctx := context.WithValue(r.Context(), PermissionsContextKey, perms)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetPermissionsFromContext возвращает права текущего пользователя. Если права не загружены
+// middleware LoadPermissions, используются права встроенной роли по умолчанию
+func GetPermissionsFromContext(ctx context.Context) models.PermissionSet {
+	if perms, ok := ctx.Value(PermissionsContextKey).(models.PermissionSet); ok {
+		return perms
+	}
+	if user, ok := GetUserFromContext(ctx); ok {
+		if perms, ok := models.DefaultRolePermissions[user.Role]; ok {
+			return perms
+		}
+	}
+	return models.NewPermissionSet()
+}
+
+// HasPermission проверяет, есть ли у текущего пользователя хотя бы одно из прав
+func HasPermission(ctx context.Context, perms ...models.Permission) bool {
+	return GetPermissionsFromContext(ctx).HasAny(perms...)
+}
+
+// RequirePermission middleware, который пропускает пользователя с хотя бы одним из прав
+func RequirePermission(perms ...models.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := GetUserFromContext(r.Context()); !ok {
+				response.Unauthorized(w, "Authentication required")
+				return
+			}
+
+			if !HasPermission(r.Context(), perms...) {
+				response.Forbidden(w, "Insufficient permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireOwnership middleware для прав с областью: allPerm дает доступ к любому объекту,
+// ownPerm - только к объекту из URL параметра param, которым владеет пользователь
+func RequireOwnership(permissionService *service.PermissionService, resource string, ownPerm, allPerm models.Permission, param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetUserFromContext(r.Context())
+			if !ok {
+				response.Unauthorized(w, "Authentication required")
+				return
+			}
+
+			resourceID, err := uuid.Parse(chi.URLParam(r, param))
+			if err != nil {
+				response.BadRequest(w, response.ErrCodeInvalidInput, "Invalid "+resource+" ID")
+				return
+			}
+
+			allowed, err := permissionService.Authorize(r.Context(), user, ownPerm, allPerm, resource, resourceID)
+			if err != nil {
+				log.Printf("ERROR: Failed to check %s ownership: %v", resource, err)
+				response.InternalError(w, "Failed to check permissions")
+				return
+			}
+			if !allowed {
+				response.Forbidden(w, "Insufficient permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
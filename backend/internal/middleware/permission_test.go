@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+// newUnreachablePermissionService PermissionService без доступной БД: используются права встроенных ролей
+func newUnreachablePermissionService(t *testing.T) *service.PermissionService {
+	t.Helper()
+	db, err := sqlx.Open("pgx", "postgres://postgres@127.0.0.1:1/none?connect_timeout=1")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return service.NewPermissionService(repository.NewPermissionRepository(db))
+}
+
+func permissionTestRequest(method, path string, user *models.User) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	if user != nil {
+		req = req.WithContext(SetUserInContext(req.Context(), user))
+	}
+	return req
+}
+
+func TestLoadPermissions(t *testing.T) {
+	var got models.PermissionSet
+	handler := LoadPermissions(newUnreachablePermissionService(t))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = r.Context().Value(PermissionsContextKey).(models.PermissionSet)
+	}))
+
+	teacher := &models.User{ID: uuid.New(), Role: models.RoleTeacher}
+	handler.ServeHTTP(httptest.NewRecorder(), permissionTestRequest(http.MethodGet, "/", teacher))
+	if got == nil || !got.Has(models.PermTeachingSchedule) || got.Has(models.PermUsersEdit) {
+		t.Errorf("teacher permissions = %v, want default teacher permissions", got.List())
+	}
+
+	got = nil
+	handler.ServeHTTP(httptest.NewRecorder(), permissionTestRequest(http.MethodGet, "/", nil))
+	if got != nil {
+		t.Errorf("permissions loaded without user: %v", got.List())
+	}
+}
+
+func TestGetPermissionsFromContext(t *testing.T) {
+	ctx := context.WithValue(SetUserInContext(context.Background(), &models.User{Role: models.RoleAdmin}),
+		PermissionsContextKey, models.NewPermissionSet(models.PermUsersRead))
+	if !HasPermission(ctx, models.PermUsersRead) || HasPermission(ctx, models.PermUsersEdit) {
+		t.Error("loaded permissions must take precedence over role defaults")
+	}
+
+	ctx = SetUserInContext(context.Background(), &models.User{Role: models.RoleAdmin})
+	if !HasPermission(ctx, models.PermUsersEdit) {
+		t.Error("admin without loaded permissions must fall back to default admin permissions")
+	}
+	if HasPermission(context.Background(), models.PermUsersRead) {
+		t.Error("anonymous context must have no permissions")
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	handler := RequirePermission(models.PermUsersEdit, models.PermUsersCreate)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	manager := SetUserInContext(context.Background(), &models.User{ID: uuid.New(), Role: "manager"})
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want int
+	}{
+		{"anonymous", context.Background(), http.StatusUnauthorized},
+		{"admin", SetUserInContext(context.Background(), &models.User{Role: models.RoleAdmin}), http.StatusOK},
+		{"teacher", SetUserInContext(context.Background(), &models.User{Role: models.RoleTeacher}), http.StatusForbidden},
+		{"student", SetUserInContext(context.Background(), &models.User{Role: models.RoleStudent}), http.StatusForbidden},
+		{"custom role with one of permissions", context.WithValue(manager, PermissionsContextKey, models.NewPermissionSet(models.PermUsersCreate)), http.StatusOK},
+		{"custom role without permissions", context.WithValue(manager, PermissionsContextKey, models.NewPermissionSet(models.PermUsersRead)), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil).WithContext(tt.ctx))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireOwnership(t *testing.T) {
+	teacher := &models.User{ID: uuid.New(), Role: models.RoleTeacher}
+	ownLesson, otherLesson, brokenLesson := uuid.New(), uuid.New(), uuid.New()
+
+	permissionService := newUnreachablePermissionService(t)
+	permissionService.RegisterOwnershipChecker(service.ResourceLesson, service.OwnershipCheckerFunc(
+		func(ctx context.Context, userID, resourceID uuid.UUID) (bool, error) {
+			if resourceID == brokenLesson {
+				return false, errors.New("db down")
+			}
+			return userID == teacher.ID && resourceID == ownLesson, nil
+		}))
+
+	router := chi.NewRouter()
+	router.With(RequireOwnership(permissionService, service.ResourceLesson, models.PermLessonsEditOwn, models.PermLessonsEditAll, "id")).
+		Put("/lessons/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+	tests := []struct {
+		name string
+		user *models.User
+		path string
+		want int
+	}{
+		{"anonymous", nil, "/lessons/" + ownLesson.String(), http.StatusUnauthorized},
+		{"own permission on own lesson", teacher, "/lessons/" + ownLesson.String(), http.StatusOK},
+		{"own permission on other lesson", teacher, "/lessons/" + otherLesson.String(), http.StatusForbidden},
+		{"all permission on any lesson", &models.User{ID: uuid.New(), Role: models.RoleAdmin}, "/lessons/" + otherLesson.String(), http.StatusOK},
+		{"no permission", &models.User{ID: uuid.New(), Role: models.RoleStudent}, "/lessons/" + ownLesson.String(), http.StatusForbidden},
+		{"invalid id", teacher, "/lessons/not-a-uuid", http.StatusBadRequest},
+		{"checker error", teacher, "/lessons/" + brokenLesson.String(), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, permissionTestRequest(http.MethodPut, tt.path, tt.user))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -49,25 +49,12 @@ func NewIPRateLimiter(r rate.Limit, b int) *IPRateLimiter {
 // NewIPRateLimiterWithProxies создает новый rate limiter с доверенными прокси
 // trustedProxies - список доверенных прокси-серверов (могут содержать порт)
 func NewIPRateLimiterWithProxies(r rate.Limit, b int, trustedProxies []string) *IPRateLimiter {
-	proxiesMap := make(map[string]bool)
-	for _, proxy := range trustedProxies {
-		// Нормализуем IP (убираем порт если есть)
-		ip, _, err := net.SplitHostPort(proxy)
-		if err != nil {
-			ip = proxy
-		}
-		// Парсим IP для валидации
-		if parsed := net.ParseIP(strings.TrimSpace(ip)); parsed != nil {
-			proxiesMap[parsed.String()] = true
-		}
-	}
-
 	limiter := &IPRateLimiter{
 		ips:            make(map[string]*LimiterEntry),
 		mu:             &sync.RWMutex{},
 		r:              r,
 		b:              b,
-		trustedProxies: proxiesMap,
+		trustedProxies: normalizeTrustedProxies(trustedProxies),
 		ttl:            1 * time.Hour, // TTL для неиспользуемых entries
 		stopChan:       make(chan struct{}),
 	}
@@ -220,6 +207,7 @@ func getIPAddress(r *http.Request) string {
 }
 
 // RateLimitMiddleware создает middleware для rate limiting с защитой от спуфинга
+// DEPRECATED: счетчики хранятся только в памяти процесса; используйте RateLimitByIP с ratelimit.Store
 func RateLimitMiddleware(limiter *IPRateLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -419,6 +407,7 @@ func PaymentRateLimiter() *UserRateLimiter {
 }
 
 // UserRateLimitMiddleware создает middleware для user-based rate limiting
+// DEPRECATED: счетчики хранятся только в памяти процесса; используйте RateLimitByUser с ratelimit.Store
 // Используется для payment endpoints и других чувствительных операций
 // Требует authenticated user в контексте
 func UserRateLimitMiddleware(limiter *UserRateLimiter) func(http.Handler) http.Handler {
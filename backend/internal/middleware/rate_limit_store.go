@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tutoring-platform/internal/config"
+	"tutoring-platform/internal/ratelimit"
+	"tutoring-platform/pkg/response"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimitByIP ограничивает частоту запросов группы маршрутов по IP клиента. Счетчики хранятся в store,
+// поэтому при общем хранилище (postgres, redis) лимит один на все реплики. Limit.Requests = 0 отключает ограничение
+func RateLimitByIP(store ratelimit.Store, group string, limit ratelimit.Limit, trustedProxies []string) func(http.Handler) http.Handler {
+	proxies := normalizeTrustedProxies(trustedProxies)
+	return func(next http.Handler) http.Handler {
+		if limit.Requests <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := group + ":ip:" + getIPAddressSecure(r, proxies)
+			if !applyRateLimit(w, r, store, key, limit) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitByUser ограничивает частоту запросов группы маршрутов по пользователю.
+// Требует authenticated user в контексте
+func RateLimitByUser(store ratelimit.Store, group string, limit ratelimit.Limit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit.Requests <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetUserFromContext(r.Context())
+			if !ok {
+				response.Unauthorized(w, "Authentication required")
+				return
+			}
+			key := group + ":user:" + user.ID.String()
+			if !applyRateLimit(w, r, store, key, limit) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// applyRateLimit учитывает запрос, выставляет заголовки RateLimit-* и отвечает 429 с Retry-After при превышении.
+// Ошибку хранилища группа обрабатывает по limit.OnStoreError: deny отвечает 503, allow (по умолчанию) пропускает запрос.
+// Политику memory выполняет ratelimit.FallbackStore; если хранилище ее не поддерживает, запрос отклоняется
+func applyRateLimit(w http.ResponseWriter, r *http.Request, store ratelimit.Store, key string, limit ratelimit.Limit) bool {
+	result, err := store.Allow(r.Context(), key, limit)
+	if err != nil {
+		switch limit.OnStoreError {
+		case "", config.RateLimitOnStoreErrorAllow:
+			log.Error().Err(err).Str("key", key).Msg("Rate limit store unavailable, request allowed")
+			return true
+		default:
+			log.Error().Err(err).Str("key", key).Msg("Rate limit store unavailable, request rejected")
+			response.ServiceUnavailable(w, "Service temporarily unavailable. Please try again later.")
+			return false
+		}
+	}
+
+	setRateLimitHeaders(w.Header(), result, limit)
+	if !result.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		response.TooManyRequests(w, "Rate limit exceeded. Please try again later.")
+		return false
+	}
+	return true
+}
+
+// setRateLimitHeaders выставляет заголовки RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset (секунды до полного
+// восстановления лимита) и RateLimit-Policy ("10;w=60") по draft-ietf-httpapi-ratelimit-headers
+func setRateLimitHeaders(h http.Header, result *ratelimit.Result, limit ratelimit.Limit) {
+	h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, ceilSeconds(limit.Window)))
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд, чтобы клиент не повторил запрос раньше времени
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// normalizeTrustedProxies приводит список доверенных прокси к карте IP для быстрого поиска
+func normalizeTrustedProxies(trustedProxies []string) map[string]bool {
+	proxiesMap := make(map[string]bool)
+	for _, proxy := range trustedProxies {
+		// Нормализуем IP (убираем порт если есть)
+		ip, _, err := net.SplitHostPort(proxy)
+		if err != nil {
+			ip = proxy
+		}
+		// Парсим IP для валидации
+		if parsed := net.ParseIP(strings.TrimSpace(ip)); parsed != nil {
+			proxiesMap[parsed.String()] = true
+		}
+	}
+	return proxiesMap
+}
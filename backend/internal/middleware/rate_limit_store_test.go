@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"tutoring-platform/internal/config"
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/ratelimit"
+
+	"github.com/google/uuid"
+)
+
+// failingRateLimitStore имитирует недоступное общее хранилище
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Allow(ctx context.Context, key string, limit ratelimit.Limit) (*ratelimit.Result, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingRateLimitStore) Stop() {}
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+// TestRateLimitByIP_Headers проверяет заголовки RateLimit-* и ответ 429 с Retry-After
+func TestRateLimitByIP_Headers(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	defer store.Stop()
+
+	limit := ratelimit.Limit{Requests: 2, Window: time.Minute}
+	handler := RateLimitByIP(store, "login", limit, nil)(okHandler)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected %d, got %d", i+1, http.StatusOK, w.Code)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != strconv.Itoa(1-i) {
+			t.Errorf("request %d: RateLimit-Remaining = %q, want %d", i+1, got, 1-i)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("RateLimit-Limit = %q, want 2", got)
+		}
+		if got := w.Header().Get("RateLimit-Policy"); got != "2;w=60" {
+			t.Errorf("RateLimit-Policy = %q, want 2;w=60", got)
+		}
+		if w.Header().Get("Retry-After") != "" {
+			t.Error("Retry-After must be set only on 429")
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+	req.RemoteAddr = "203.0.113.7:1234"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+	if got := w.Header().Get("RateLimit-Reset"); got != "60" {
+		t.Errorf("RateLimit-Reset = %q, want 60", got)
+	}
+
+	// Другой IP не затронут
+	req = httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+	req.RemoteAddr = "203.0.113.8:1234"
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("other IP: expected %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+// TestRateLimitByUser проверяет лимит по пользователю и отказ без аутентификации
+func TestRateLimitByUser(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	defer store.Stop()
+
+	handler := RateLimitByUser(store, "chat_send", ratelimit.Limit{Requests: 1, Window: time.Minute})(okHandler)
+	user := &models.User{ID: uuid.New()}
+
+	codes := []int{http.StatusOK, http.StatusTooManyRequests}
+	for i, want := range codes {
+		req := httptest.NewRequest("POST", "/api/v1/chat/rooms/1/messages", nil)
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, user))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("request %d: expected %d, got %d", i+1, want, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/chat/rooms/1/messages", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated: expected %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+// TestRateLimit_StoreUnavailable проверяет поведение групп при недоступном хранилище: allow пропускает запросы,
+// deny отвечает 503, memory продолжает ограничивать по счетчикам процесса
+func TestRateLimit_StoreUnavailable(t *testing.T) {
+	tests := []struct {
+		name         string
+		group        string
+		onStoreError string
+		codes        []int
+	}{
+		{name: "allow", group: "payment", onStoreError: config.RateLimitOnStoreErrorAllow, codes: []int{http.StatusOK, http.StatusOK, http.StatusOK}},
+		{name: "deny", group: "login", onStoreError: config.RateLimitOnStoreErrorDeny, codes: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}},
+		{name: "memory", group: "login", onStoreError: config.RateLimitOnStoreErrorMemory, codes: []int{http.StatusOK, http.StatusTooManyRequests}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := ratelimit.NewFallbackStore(failingRateLimitStore{})
+			defer store.Stop()
+
+			limit := ratelimit.Limit{Requests: 1, Window: time.Minute, OnStoreError: tt.onStoreError}
+			handler := RateLimitByIP(store, tt.group, limit, nil)(okHandler)
+
+			for i, want := range tt.codes {
+				req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+				if w.Code != want {
+					t.Errorf("request %d: expected %d, got %d", i+1, want, w.Code)
+				}
+				if tt.onStoreError != config.RateLimitOnStoreErrorMemory && w.Header().Get("RateLimit-Limit") != "" {
+					t.Error("RateLimit headers must not be set without a store decision")
+				}
+			}
+		})
+	}
+}
+
+// TestRateLimit_StoreUnavailableWithoutFallback проверяет, что memory без FallbackStore не пропускает запросы
+func TestRateLimit_StoreUnavailableWithoutFallback(t *testing.T) {
+	limit := ratelimit.Limit{Requests: 1, Window: time.Minute, OnStoreError: config.RateLimitOnStoreErrorMemory}
+	handler := RateLimitByIP(failingRateLimitStore{}, "login", limit, nil)(okHandler)
+
+	req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
+
+// TestRateLimit_Disabled проверяет, что Requests = 0 отключает ограничение
+func TestRateLimit_Disabled(t *testing.T) {
+	handler := RateLimitByUser(failingRateLimitStore{}, "upload", ratelimit.Limit{})(okHandler)
+
+	req := httptest.NewRequest("POST", "/api/v1/lessons/1/homework", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+}
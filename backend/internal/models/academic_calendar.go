@@ -0,0 +1,269 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarEntryType определяет тип записи академического календаря
+type CalendarEntryType string
+
+const (
+	// CalendarEntryTerm представляет учебный период (четверть, семестр) - информационная запись
+	CalendarEntryTerm CalendarEntryType = "term"
+	// CalendarEntryHoliday представляет праздничный день - занятия не проводятся
+	CalendarEntryHoliday CalendarEntryType = "holiday"
+	// CalendarEntryBreak представляет каникулы - занятия не проводятся
+	CalendarEntryBreak CalendarEntryType = "break"
+)
+
+// CalendarEntrySource определяет источник записи календаря
+type CalendarEntrySource string
+
+const (
+	CalendarSourceManual CalendarEntrySource = "manual"
+	CalendarSourceICS    CalendarEntrySource = "ics"
+)
+
+// HolidayPolicy определяет, что делать с занятием серии, попавшим на заблокированную дату
+type HolidayPolicy string
+
+const (
+	// HolidayPolicySkip пропускает занятие (по умолчанию)
+	HolidayPolicySkip HolidayPolicy = "skip"
+	// HolidayPolicyShift переносит занятие на ближайший свободный день до следующего занятия серии
+	HolidayPolicyShift HolidayPolicy = "shift"
+	// HolidayPolicyIgnore создаёт занятие несмотря на праздник
+	HolidayPolicyIgnore HolidayPolicy = "ignore"
+)
+
+// CalendarDateLayout формат дат академического календаря в API
+const CalendarDateLayout = "2006-01-02"
+
+// AcademicCalendarEntry представляет запись академического календаря (период, праздник, каникулы)
+// StartDate и EndDate включительные
+type AcademicCalendarEntry struct {
+	ID          uuid.UUID           `db:"id" json:"id"`
+	EntryType   CalendarEntryType   `db:"entry_type" json:"entry_type"`
+	Name        string              `db:"name" json:"name"`
+	StartDate   time.Time           `db:"start_date" json:"start_date"`
+	EndDate     time.Time           `db:"end_date" json:"end_date"`
+	Source      CalendarEntrySource `db:"source" json:"source"`
+	ExternalUID sql.NullString      `db:"external_uid" json:"external_uid,omitempty"`
+	CreatedBy   uuid.NullUUID       `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt   time.Time           `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time           `db:"updated_at" json:"updated_at"`
+	DeletedAt   sql.NullTime        `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// BlocksLessons проверяет, запрещает ли запись проведение занятий
+func (e *AcademicCalendarEntry) BlocksLessons() bool {
+	return e.EntryType == CalendarEntryHoliday || e.EntryType == CalendarEntryBreak
+}
+
+// CoversDate проверяет, попадает ли календарная дата момента t в период записи
+// Дата берётся в часовом поясе t, чтобы занятие в 23:00 не "переезжало" на следующий день
+func (e *AcademicCalendarEntry) CoversDate(t time.Time) bool {
+	day := civilDate(t)
+	return !day.Before(civilDate(e.StartDate)) && !day.After(civilDate(e.EndDate))
+}
+
+// civilDate возвращает полночь UTC для календарной даты t (для сравнения дат без учёта времени)
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// AcademicCalendar набор записей календаря, используемый при генерации занятий
+type AcademicCalendar []*AcademicCalendarEntry
+
+// BlockingEntry возвращает первую блокирующую запись, покрывающую дату t, или nil
+func (c AcademicCalendar) BlockingEntry(t time.Time) *AcademicCalendarEntry {
+	for _, entry := range c {
+		if entry.BlocksLessons() && entry.CoversDate(t) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// IsBlocked проверяет, заблокирована ли дата t праздником или каникулами
+func (c AcademicCalendar) IsBlocked(t time.Time) bool {
+	return c.BlockingEntry(t) != nil
+}
+
+// ResolveOccurrence применяет политику к занятию серии, запланированному на момент t
+// Возвращает итоговое время начала и false, если занятие не нужно создавать
+// maxShiftDays ограничивает перенос, чтобы занятие не "догнало" следующее в серии
+func (c AcademicCalendar) ResolveOccurrence(t time.Time, policy HolidayPolicy, maxShiftDays int) (time.Time, bool) {
+	if !c.IsBlocked(t) {
+		return t, true
+	}
+
+	switch policy {
+	case HolidayPolicyIgnore:
+		return t, true
+	case HolidayPolicyShift:
+		for shift := 1; shift <= maxShiftDays; shift++ {
+			candidate := t.AddDate(0, 0, shift)
+			if !c.IsBlocked(candidate) {
+				return candidate, true
+			}
+		}
+		return t, false
+	default:
+		return t, false
+	}
+}
+
+// ParseHolidayPolicy разбирает политику из строки (пустая строка = skip)
+func ParseHolidayPolicy(value string) (HolidayPolicy, error) {
+	switch HolidayPolicy(strings.ToLower(strings.TrimSpace(value))) {
+	case "", HolidayPolicySkip:
+		return HolidayPolicySkip, nil
+	case HolidayPolicyShift:
+		return HolidayPolicyShift, nil
+	case HolidayPolicyIgnore:
+		return HolidayPolicyIgnore, nil
+	default:
+		return "", ErrInvalidHolidayPolicy
+	}
+}
+
+// IsValidCalendarEntryType проверяет тип записи календаря
+func IsValidCalendarEntryType(t CalendarEntryType) bool {
+	switch t {
+	case CalendarEntryTerm, CalendarEntryHoliday, CalendarEntryBreak:
+		return true
+	default:
+		return false
+	}
+}
+
+// CreateCalendarEntryRequest представляет запрос на создание записи календаря
+// Даты передаются в формате YYYY-MM-DD
+type CreateCalendarEntryRequest struct {
+	EntryType CalendarEntryType `json:"entry_type"`
+	Name      string            `json:"name"`
+	StartDate string            `json:"start_date"`
+	EndDate   string            `json:"end_date,omitempty"` // Optional: по умолчанию равна start_date
+}
+
+// Validate выполняет валидацию CreateCalendarEntryRequest
+func (r *CreateCalendarEntryRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+
+	if !IsValidCalendarEntryType(r.EntryType) {
+		return ErrInvalidCalendarEntryType
+	}
+	if r.Name == "" || len(r.Name) > 200 {
+		return ErrInvalidCalendarEntryName
+	}
+	if _, _, err := r.Dates(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Dates разбирает даты начала и окончания запроса
+func (r *CreateCalendarEntryRequest) Dates() (time.Time, time.Time, error) {
+	start, err := time.Parse(CalendarDateLayout, r.StartDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidCalendarDates
+	}
+	end := start
+	if r.EndDate != "" {
+		end, err = time.Parse(CalendarDateLayout, r.EndDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidCalendarDates
+		}
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, ErrInvalidCalendarDates
+	}
+	return start, end, nil
+}
+
+// UpdateCalendarEntryRequest представляет запрос на обновление записи календаря
+type UpdateCalendarEntryRequest struct {
+	EntryType *CalendarEntryType `json:"entry_type,omitempty"`
+	Name      *string            `json:"name,omitempty"`
+	StartDate *string            `json:"start_date,omitempty"`
+	EndDate   *string            `json:"end_date,omitempty"`
+}
+
+// Validate выполняет валидацию UpdateCalendarEntryRequest
+func (r *UpdateCalendarEntryRequest) Validate() error {
+	if r.EntryType != nil && !IsValidCalendarEntryType(*r.EntryType) {
+		return ErrInvalidCalendarEntryType
+	}
+	if r.Name != nil {
+		trimmed := strings.TrimSpace(*r.Name)
+		if trimmed == "" || len(trimmed) > 200 {
+			return ErrInvalidCalendarEntryName
+		}
+		r.Name = &trimmed
+	}
+	if r.StartDate != nil {
+		if _, err := time.Parse(CalendarDateLayout, *r.StartDate); err != nil {
+			return ErrInvalidCalendarDates
+		}
+	}
+	if r.EndDate != nil {
+		if _, err := time.Parse(CalendarDateLayout, *r.EndDate); err != nil {
+			return ErrInvalidCalendarDates
+		}
+	}
+	return nil
+}
+
+// CalendarConflict представляет будущее занятие, попадающее на праздник или каникулы
+type CalendarConflict struct {
+	LessonID         uuid.UUID  `json:"lesson_id"`
+	TeacherID        uuid.UUID  `json:"teacher_id"`
+	TeacherName      string     `json:"teacher_name"`
+	StartTime        time.Time  `json:"start_time"`
+	EndTime          time.Time  `json:"end_time"`
+	Subject          string     `json:"subject,omitempty"`
+	CurrentStudents  int        `json:"current_students"`
+	CreditsCost      int        `json:"credits_cost"`
+	RecurringGroupID *uuid.UUID `json:"recurring_group_id,omitempty"`
+	EntryID          uuid.UUID  `json:"entry_id"`
+	EntryName        string     `json:"entry_name"`
+	EntryType        string     `json:"entry_type"`
+}
+
+// CancelCalendarConflictsRequest представляет запрос на массовую отмену конфликтующих занятий
+type CancelCalendarConflictsRequest struct {
+	LessonIDs []uuid.UUID `json:"lesson_ids"`
+}
+
+// Validate выполняет валидацию CancelCalendarConflictsRequest
+func (r *CancelCalendarConflictsRequest) Validate() error {
+	if len(r.LessonIDs) == 0 {
+		return ErrInvalidLessonID
+	}
+	for _, id := range r.LessonIDs {
+		if id == uuid.Nil {
+			return ErrInvalidLessonID
+		}
+	}
+	return nil
+}
+
+// CancelCalendarConflictsResult результат массовой отмены занятий с возвратом кредитов
+type CancelCalendarConflictsResult struct {
+	CancelledLessons  int `json:"cancelled_lessons"`
+	CancelledBookings int `json:"cancelled_bookings"`
+	RefundedCredits   int `json:"refunded_credits"`
+}
+
+// ICSImportResult результат импорта календаря из ICS файла
+type ICSImportResult struct {
+	Created int                      `json:"created"`
+	Updated int                      `json:"updated"`
+	Skipped int                      `json:"skipped"`
+	Entries []*AcademicCalendarEntry `json:"entries"`
+}
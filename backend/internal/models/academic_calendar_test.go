@@ -0,0 +1,182 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func calendarDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAcademicCalendarEntry_CoversDate(t *testing.T) {
+	entry := &AcademicCalendarEntry{
+		EntryType: CalendarEntryBreak,
+		StartDate: calendarDate(2025, 1, 1),
+		EndDate:   calendarDate(2025, 1, 8),
+	}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"first day", time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), true},
+		{"last day late evening", time.Date(2025, 1, 8, 23, 30, 0, 0, time.UTC), true},
+		{"day before", time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC), false},
+		{"day after", time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC), false},
+		{"local timezone uses local date", time.Date(2025, 1, 9, 1, 0, 0, 0, time.FixedZone("MSK", 3*3600)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entry.CoversDate(tt.t); got != tt.want {
+				t.Errorf("CoversDate(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcademicCalendar_ResolveOccurrence(t *testing.T) {
+	calendar := AcademicCalendar{
+		{EntryType: CalendarEntryHoliday, Name: "Holiday", StartDate: calendarDate(2025, 3, 10), EndDate: calendarDate(2025, 3, 11)},
+		{EntryType: CalendarEntryTerm, Name: "Term", StartDate: calendarDate(2025, 1, 1), EndDate: calendarDate(2025, 5, 31)},
+		{EntryType: CalendarEntryBreak, Name: "Long break", StartDate: calendarDate(2025, 4, 1), EndDate: calendarDate(2025, 4, 14)},
+	}
+
+	lessonOnHoliday := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	lessonOnBreak := time.Date(2025, 4, 2, 15, 0, 0, 0, time.UTC)
+	regularLesson := time.Date(2025, 3, 17, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		policy   HolidayPolicy
+		wantTime time.Time
+		wantOK   bool
+	}{
+		{"regular day is kept", regularLesson, HolidayPolicySkip, regularLesson, true},
+		{"term does not block", time.Date(2025, 2, 3, 15, 0, 0, 0, time.UTC), HolidayPolicySkip, time.Date(2025, 2, 3, 15, 0, 0, 0, time.UTC), true},
+		{"skip on holiday", lessonOnHoliday, HolidayPolicySkip, lessonOnHoliday, false},
+		{"ignore on holiday", lessonOnHoliday, HolidayPolicyIgnore, lessonOnHoliday, true},
+		{"shift to first free day keeps time", lessonOnHoliday, HolidayPolicyShift, time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC), true},
+		{"shift beyond limit is skipped", lessonOnBreak, HolidayPolicyShift, lessonOnBreak, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := calendar.ResolveOccurrence(tt.t, tt.policy, 6)
+			if ok != tt.wantOK {
+				t.Fatalf("ResolveOccurrence() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !got.Equal(tt.wantTime) {
+				t.Errorf("ResolveOccurrence() time = %v, want %v", got, tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestAcademicCalendar_Empty(t *testing.T) {
+	var calendar AcademicCalendar
+	now := time.Now()
+
+	got, ok := calendar.ResolveOccurrence(now, HolidayPolicySkip, 6)
+	if !ok || !got.Equal(now) {
+		t.Error("empty calendar should not block any date")
+	}
+}
+
+func TestParseHolidayPolicy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    HolidayPolicy
+		wantErr bool
+	}{
+		{"", HolidayPolicySkip, false},
+		{"skip", HolidayPolicySkip, false},
+		{" Shift ", HolidayPolicyShift, false},
+		{"ignore", HolidayPolicyIgnore, false},
+		{"postpone", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseHolidayPolicy(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHolidayPolicy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseHolidayPolicy(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateCalendarEntryRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     CreateCalendarEntryRequest
+		wantErr error
+	}{
+		{
+			name: "valid single day holiday",
+			req:  CreateCalendarEntryRequest{EntryType: CalendarEntryHoliday, Name: "День Победы", StartDate: "2025-05-09"},
+		},
+		{
+			name: "valid break",
+			req:  CreateCalendarEntryRequest{EntryType: CalendarEntryBreak, Name: "Весенние каникулы", StartDate: "2025-03-24", EndDate: "2025-03-30"},
+		},
+		{
+			name:    "invalid type",
+			req:     CreateCalendarEntryRequest{EntryType: "vacation", Name: "x", StartDate: "2025-03-24"},
+			wantErr: ErrInvalidCalendarEntryType,
+		},
+		{
+			name:    "blank name",
+			req:     CreateCalendarEntryRequest{EntryType: CalendarEntryTerm, Name: "   ", StartDate: "2025-03-24"},
+			wantErr: ErrInvalidCalendarEntryName,
+		},
+		{
+			name:    "end before start",
+			req:     CreateCalendarEntryRequest{EntryType: CalendarEntryBreak, Name: "x", StartDate: "2025-03-24", EndDate: "2025-03-20"},
+			wantErr: ErrInvalidCalendarDates,
+		},
+		{
+			name:    "bad date format",
+			req:     CreateCalendarEntryRequest{EntryType: CalendarEntryBreak, Name: "x", StartDate: "24.03.2025"},
+			wantErr: ErrInvalidCalendarDates,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if err != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateLessonRequest_Validate_HolidayPolicy(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour)
+	req := CreateLessonRequest{
+		TeacherID:     uuid.New(),
+		StartTime:     start,
+		EndTime:       start.Add(time.Hour),
+		MaxStudents:   1,
+		Color:         "#3B82F6",
+		HolidayPolicy: "postpone",
+	}
+
+	if err := req.Validate(); err != ErrInvalidHolidayPolicy {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalidHolidayPolicy)
+	}
+
+	req.HolidayPolicy = "shift"
+	if err := req.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error = %v", err)
+	}
+}
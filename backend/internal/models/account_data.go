@@ -0,0 +1,214 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErasureRequestStatus статус запроса на удаление персональных данных
+type ErasureRequestStatus string
+
+const (
+	// ErasureRequestPending запрос ожидает решения администратора
+	ErasureRequestPending ErasureRequestStatus = "pending"
+	// ErasureRequestRejected администратор отклонил запрос
+	ErasureRequestRejected ErasureRequestStatus = "rejected"
+	// ErasureRequestCompleted запрос одобрен, персональные данные обезличены
+	ErasureRequestCompleted ErasureRequestStatus = "completed"
+)
+
+// IsValid проверяет, что статус запроса поддерживается
+func (s ErasureRequestStatus) IsValid() bool {
+	switch s {
+	case ErasureRequestPending, ErasureRequestRejected, ErasureRequestCompleted:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	// MaxErasureCommentLength ограничивает длину причины запроса и комментария администратора
+	MaxErasureCommentLength = 1000
+
+	// ErasedFirstName и ErasedLastName подставляются вместо имени обезличенного пользователя
+	ErasedFirstName = "Удаленный"
+	ErasedLastName  = "пользователь"
+	// ErasedText подставляется вместо текста сообщений и заявок обезличенного пользователя
+	ErasedText = "[удалено]"
+	// erasedEmailDomain зарезервированный домен (RFC 2606) для обезличенных email
+	erasedEmailDomain = "erased.invalid"
+)
+
+// ErasedEmail возвращает уникальный обезличенный email пользователя
+func ErasedEmail(userID uuid.UUID) string {
+	return "erased-" + userID.String() + "@" + erasedEmailDomain
+}
+
+// IsErasedEmail проверяет, что email принадлежит обезличенному пользователю
+func IsErasedEmail(email string) bool {
+	return strings.HasSuffix(email, "@"+erasedEmailDomain)
+}
+
+// AccountErasureRequest запрос пользователя на удаление персональных данных
+type AccountErasureRequest struct {
+	ID         uuid.UUID            `db:"id" json:"id"`
+	UserID     uuid.UUID            `db:"user_id" json:"user_id"`
+	UserName   string               `db:"user_name" json:"user_name"`
+	UserEmail  string               `db:"user_email" json:"user_email"`
+	Status     ErasureRequestStatus `db:"status" json:"status"`
+	Reason     string               `db:"reason" json:"reason"`
+	ReviewNote string               `db:"review_note" json:"review_note,omitempty"`
+	ReviewedBy uuid.NullUUID        `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time           `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time            `db:"created_at" json:"created_at"`
+}
+
+// CreateErasureRequest запрос пользователя на удаление аккаунта
+type CreateErasureRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Validate проверяет запрос на удаление аккаунта
+func (r *CreateErasureRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if len([]rune(r.Reason)) > MaxErasureCommentLength {
+		return ErrInvalidErasureComment
+	}
+	return nil
+}
+
+// ReviewErasureRequest решение администратора по запросу на удаление
+type ReviewErasureRequest struct {
+	Note string `json:"note"`
+}
+
+// Validate проверяет комментарий администратора. Отказ должен быть обоснован
+func (r *ReviewErasureRequest) Validate(reject bool) error {
+	r.Note = strings.TrimSpace(r.Note)
+	if len([]rune(r.Note)) > MaxErasureCommentLength || (reject && r.Note == "") {
+		return ErrInvalidErasureComment
+	}
+	return nil
+}
+
+// ErasureResult итог обезличивания аккаунта
+type ErasureResult struct {
+	Request             *AccountErasureRequest `json:"request"`
+	TrialRequests       int64                  `json:"trial_requests"`
+	MessagesRedacted    int64                  `json:"messages_redacted"`
+	AttachmentsRemoved  int                    `json:"attachments_removed"`
+	TelegramLinkRemoved bool                   `json:"telegram_link_removed"`
+}
+
+// ExportTelegramLink привязка Telegram в выгрузке данных
+type ExportTelegramLink struct {
+	TelegramID int64     `db:"telegram_id" json:"telegram_id"`
+	Username   *string   `db:"username" json:"username,omitempty"`
+	Subscribed bool      `db:"subscribed" json:"subscribed"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// ExportBooking запись на занятие в выгрузке данных
+type ExportBooking struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	LessonID    uuid.UUID  `db:"lesson_id" json:"lesson_id"`
+	Status      string     `db:"status" json:"status"`
+	BookedAt    time.Time  `db:"booked_at" json:"booked_at"`
+	CancelledAt *time.Time `db:"cancelled_at" json:"cancelled_at,omitempty"`
+	StartTime   time.Time  `db:"start_time" json:"start_time"`
+	EndTime     time.Time  `db:"end_time" json:"end_time"`
+	Subject     string     `db:"subject" json:"subject,omitempty"`
+	TeacherName string     `db:"teacher_name" json:"teacher_name"`
+}
+
+// ExportCreditTransaction операция с кредитами в выгрузке данных
+type ExportCreditTransaction struct {
+	ID            uuid.UUID     `db:"id" json:"id"`
+	Amount        int           `db:"amount" json:"amount"`
+	OperationType string        `db:"operation_type" json:"operation_type"`
+	Reason        string        `db:"reason" json:"reason,omitempty"`
+	BookingID     uuid.NullUUID `db:"booking_id" json:"booking_id,omitempty"`
+	BalanceBefore *int          `db:"balance_before" json:"balance_before,omitempty"`
+	BalanceAfter  *int          `db:"balance_after" json:"balance_after,omitempty"`
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+}
+
+// ExportCredits баланс и история кредитов в выгрузке данных
+type ExportCredits struct {
+	Balance      int                        `json:"balance"`
+	Transactions []*ExportCreditTransaction `json:"transactions"`
+}
+
+// ExportMessage сообщение чата в выгрузке данных
+type ExportMessage struct {
+	ID          uuid.UUID            `db:"id" json:"id"`
+	RoomID      uuid.UUID            `db:"room_id" json:"room_id"`
+	SenderID    uuid.NullUUID        `db:"sender_id" json:"sender_id,omitempty"`
+	SenderName  string               `db:"sender_name" json:"sender_name"`
+	Text        string               `db:"message_text" json:"text"`
+	Status      string               `db:"status" json:"status"`
+	CreatedAt   time.Time            `db:"created_at" json:"created_at"`
+	EditedAt    *time.Time           `db:"edited_at" json:"edited_at,omitempty"`
+	Edits       []*ExportMessageEdit `db:"-" json:"edits,omitempty"`
+	Attachments []*ExportAttachment  `db:"-" json:"attachments,omitempty"`
+}
+
+// ExportMessageEdit предыдущая версия текста сообщения в выгрузке данных
+type ExportMessageEdit struct {
+	MessageID    uuid.UUID     `db:"message_id" json:"-"`
+	PreviousText string        `db:"previous_text" json:"previous_text"`
+	EditedBy     uuid.NullUUID `db:"edited_by" json:"edited_by,omitempty"`
+	EditedAt     time.Time     `db:"edited_at" json:"edited_at"`
+}
+
+// ExportAttachment вложение сообщения в выгрузке данных. ArchivePath - путь к файлу внутри архива
+// (пустой, если файл не найден)
+type ExportAttachment struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	MessageID   uuid.UUID `db:"message_id" json:"-"`
+	FileName    string    `db:"file_name" json:"file_name"`
+	FilePath    string    `db:"file_path" json:"-"`
+	FileSize    int64     `db:"file_size" json:"file_size"`
+	MimeType    string    `db:"mime_type" json:"mime_type"`
+	UploadedAt  time.Time `db:"uploaded_at" json:"uploaded_at"`
+	ArchivePath string    `db:"-" json:"archive_path,omitempty"`
+
+	// Ключ в BlobStore; NULL - файл лежит на диске по FilePath
+	StorageKey sql.NullString `db:"storage_key" json:"-"`
+}
+
+// ExportHomework домашнее задание занятия в выгрузке данных
+type ExportHomework struct {
+	LessonID     uuid.UUID `db:"lesson_id" json:"lesson_id"`
+	StartTime    time.Time `db:"start_time" json:"start_time"`
+	Subject      string    `db:"subject" json:"subject,omitempty"`
+	HomeworkText string    `db:"homework_text" json:"homework_text,omitempty"`
+	Files        []string  `db:"-" json:"files,omitempty"`
+}
+
+// ExportHomeworkFile файл домашнего задания (для группировки по занятиям)
+type ExportHomeworkFile struct {
+	LessonID uuid.UUID `db:"lesson_id"`
+	FileName string    `db:"file_name"`
+}
+
+// ExportSwap перенос записи в выгрузке данных
+type ExportSwap struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OldLessonID    uuid.UUID `db:"old_lesson_id" json:"old_lesson_id"`
+	OldLessonStart time.Time `db:"old_lesson_start" json:"old_lesson_start"`
+	NewLessonID    uuid.UUID `db:"new_lesson_id" json:"new_lesson_id"`
+	NewLessonStart time.Time `db:"new_lesson_start" json:"new_lesson_start"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// AccountExportManifest описание архива выгрузки данных
+type AccountExportManifest struct {
+	UserID      uuid.UUID `json:"user_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Files       []string  `json:"files"`
+}
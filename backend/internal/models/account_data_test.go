@@ -0,0 +1,82 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestErasedEmail(t *testing.T) {
+	id := uuid.New()
+	email := ErasedEmail(id)
+
+	if !strings.Contains(email, id.String()) {
+		t.Errorf("ErasedEmail(%s) = %q, want it to contain user ID", id, email)
+	}
+	if !IsErasedEmail(email) {
+		t.Errorf("IsErasedEmail(%q) = false, want true", email)
+	}
+	if IsErasedEmail("student@example.com") {
+		t.Error("IsErasedEmail(student@example.com) = true, want false")
+	}
+	if ErasedEmail(uuid.New()) == email {
+		t.Error("ErasedEmail must be unique per user")
+	}
+}
+
+func TestCreateErasureRequestValidate(t *testing.T) {
+	req := &CreateErasureRequest{Reason: "  больше не занимаюсь  "}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Reason != "больше не занимаюсь" {
+		t.Errorf("Reason = %q, want trimmed", req.Reason)
+	}
+
+	if err := (&CreateErasureRequest{}).Validate(); err != nil {
+		t.Errorf("empty reason: Validate() error = %v, want nil", err)
+	}
+
+	long := &CreateErasureRequest{Reason: strings.Repeat("я", MaxErasureCommentLength+1)}
+	if err := long.Validate(); !errors.Is(err, ErrInvalidErasureComment) {
+		t.Errorf("long reason: Validate() error = %v, want ErrInvalidErasureComment", err)
+	}
+}
+
+func TestReviewErasureRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		note    string
+		reject  bool
+		wantErr bool
+	}{
+		{"approve without note", "", false, false},
+		{"approve with note", "по заявлению", false, false},
+		{"reject without note", "   ", true, true},
+		{"reject with note", "есть неоплаченные занятия", true, false},
+		{"note too long", strings.Repeat("a", MaxErasureCommentLength+1), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &ReviewErasureRequest{Note: tt.note}
+			err := req.Validate(tt.reject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%v) error = %v, wantErr %v", tt.reject, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestErasureRequestStatusIsValid(t *testing.T) {
+	for _, status := range []ErasureRequestStatus{ErasureRequestPending, ErasureRequestRejected, ErasureRequestCompleted} {
+		if !status.IsValid() {
+			t.Errorf("%q.IsValid() = false, want true", status)
+		}
+	}
+	if ErasureRequestStatus("approved").IsValid() {
+		t.Error(`"approved".IsValid() = true, want false`)
+	}
+}
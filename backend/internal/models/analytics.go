@@ -0,0 +1,242 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnalyticsGranularity шаг агрегации аналитики
+type AnalyticsGranularity string
+
+// Поддерживаемые шаги агрегации (значения date_trunc в PostgreSQL)
+const (
+	AnalyticsGranularityDay   AnalyticsGranularity = "day"
+	AnalyticsGranularityWeek  AnalyticsGranularity = "week"
+	AnalyticsGranularityMonth AnalyticsGranularity = "month"
+)
+
+// Ограничения запросов аналитики
+const (
+	DefaultAnalyticsPeriodDays = 90
+	MaxAnalyticsPeriodDays     = 731 // Два года
+	MaxRetentionMonths         = 24
+)
+
+// AnalyticsQuery период и шаг агрегации отчета. Период задается в днях UTC: [From, To)
+type AnalyticsQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity AnalyticsGranularity
+}
+
+// ApplyDefaults заполняет незаданный период последними 90 днями, а шаг - месяцем
+func (q *AnalyticsQuery) ApplyDefaults(now time.Time) {
+	if q.To.IsZero() {
+		q.To = truncateToUTCDay(now).AddDate(0, 0, 1)
+	}
+	if q.From.IsZero() {
+		q.From = q.To.AddDate(0, 0, -DefaultAnalyticsPeriodDays)
+	}
+	if q.Granularity == "" {
+		q.Granularity = AnalyticsGranularityMonth
+	}
+	q.From = truncateToUTCDay(q.From)
+	q.To = truncateToUTCDay(q.To)
+}
+
+// Validate выполняет валидацию AnalyticsQuery (после ApplyDefaults)
+func (q *AnalyticsQuery) Validate() error {
+	switch q.Granularity {
+	case AnalyticsGranularityDay, AnalyticsGranularityWeek, AnalyticsGranularityMonth:
+	default:
+		return ErrInvalidAnalyticsGranularity
+	}
+	if !q.To.After(q.From) {
+		return ErrInvalidAnalyticsPeriod
+	}
+	if q.To.Sub(q.From) > MaxAnalyticsPeriodDays*24*time.Hour {
+		return ErrAnalyticsPeriodTooLong
+	}
+	return nil
+}
+
+// truncateToUTCDay отбрасывает время, оставляя начало дня по UTC
+func truncateToUTCDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// RevenuePoint выручка за период по источнику (разовые платежи или подписки)
+type RevenuePoint struct {
+	Period        time.Time `db:"period" json:"period"`
+	Source        string    `db:"source" json:"source"`
+	PaymentsCount int64     `db:"payments_count" json:"payments_count"`
+	Amount        float64   `db:"amount_total" json:"amount"`
+	Credits       int64     `db:"credits_total" json:"credits"`
+}
+
+// RevenueReport выручка по периодам и итог
+type RevenueReport struct {
+	Points      []*RevenuePoint `json:"points"`
+	TotalAmount float64         `json:"total_amount"`
+	TotalCount  int64           `json:"total_count"`
+}
+
+// CreditFlowPoint движение кредитов за период.
+// Burn - фактически израсходованные кредиты: списания минус возвраты
+type CreditFlowPoint struct {
+	Period   time.Time `db:"period" json:"period"`
+	Added    int64     `db:"added" json:"added"`
+	Deducted int64     `db:"deducted" json:"deducted"`
+	Refunded int64     `db:"refunded" json:"refunded"`
+	Burn     int64     `db:"-" json:"burn"`
+}
+
+// UtilizationRow заполняемость мест за период по преподавателю и предмету
+type UtilizationRow struct {
+	TeacherID   uuid.UUID `db:"teacher_id" json:"teacher_id"`
+	TeacherName string    `db:"teacher_name" json:"teacher_name"`
+	Subject     string    `db:"subject" json:"subject"`
+	Lessons     int64     `db:"lessons_count" json:"lessons"`
+	SeatsBooked int64     `db:"seats_booked" json:"seats_booked"`
+	SeatsTotal  int64     `db:"seats_total" json:"seats_total"`
+	Utilization float64   `db:"-" json:"utilization"` // Доля занятых мест, 0..1
+}
+
+// BookingActivityPoint записи, отмены и переносы за период
+type BookingActivityPoint struct {
+	Period           time.Time `db:"period" json:"period"`
+	Bookings         int64     `db:"bookings_count" json:"bookings"`
+	Cancellations    int64     `db:"cancellations_count" json:"cancellations"`
+	Swaps            int64     `db:"swaps_count" json:"swaps"`
+	CancellationRate float64   `db:"-" json:"cancellation_rate"`
+	SwapRate         float64   `db:"-" json:"swap_rate"`
+}
+
+// StudentMonthActivity месяц, в котором студент записывался на занятия
+type StudentMonthActivity struct {
+	StudentID     uuid.UUID `db:"student_id"`
+	ActivityMonth time.Time `db:"activity_month"`
+}
+
+// CohortRetention удержание когорты студентов, впервые записавшихся на занятие в месяце Cohort.
+// Retention[i] - доля студентов когорты, активных через i месяцев (Retention[0] всегда 1)
+type CohortRetention struct {
+	Cohort    time.Time `json:"cohort"`
+	Size      int       `json:"size"`
+	Active    []int     `json:"active"`
+	Retention []float64 `json:"retention"`
+}
+
+// AnalyticsRefreshState время последнего обновления материализованного представления
+type AnalyticsRefreshState struct {
+	ViewName    string    `db:"view_name" json:"view_name"`
+	RefreshedAt time.Time `db:"refreshed_at" json:"refreshed_at"`
+	DurationMs  int       `db:"duration_ms" json:"duration_ms"`
+}
+
+// ratio безопасно делит, возвращая 0 для пустого знаменателя
+func ratio(numerator, denominator int64) float64 {
+	if denominator <= 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// Finalize вычисляет итоги отчета о выручке
+func (r *RevenueReport) Finalize() {
+	r.TotalAmount = 0
+	r.TotalCount = 0
+	for _, p := range r.Points {
+		r.TotalAmount += p.Amount
+		r.TotalCount += p.PaymentsCount
+	}
+}
+
+// Finalize вычисляет расход кредитов
+func (p *CreditFlowPoint) Finalize() {
+	p.Burn = p.Deducted - p.Refunded
+}
+
+// Finalize вычисляет долю занятых мест
+func (r *UtilizationRow) Finalize() {
+	r.Utilization = ratio(r.SeatsBooked, r.SeatsTotal)
+}
+
+// Finalize вычисляет доли отмен и переносов от числа записей
+func (p *BookingActivityPoint) Finalize() {
+	p.CancellationRate = ratio(p.Cancellations, p.Bookings)
+	p.SwapRate = ratio(p.Swaps, p.Bookings)
+}
+
+// BuildCohortRetention строит таблицу удержания по месяцам активности студентов.
+// Когорта студента - первый месяц активности. Учитываются когорты из [from, to),
+// удержание считается на maxMonths месяцев вперед, но не дальше месяца to
+func BuildCohortRetention(activity []*StudentMonthActivity, from, to time.Time, maxMonths int) []*CohortRetention {
+	firstMonth := make(map[uuid.UUID]time.Time)
+	months := make(map[uuid.UUID][]time.Time)
+	for _, a := range activity {
+		month := monthStart(a.ActivityMonth)
+		if first, ok := firstMonth[a.StudentID]; !ok || month.Before(first) {
+			firstMonth[a.StudentID] = month
+		}
+		months[a.StudentID] = append(months[a.StudentID], month)
+	}
+
+	from = monthStart(from)
+	lastMonth := monthStart(to)
+	if !to.Equal(lastMonth) {
+		lastMonth = lastMonth.AddDate(0, 1, 0)
+	}
+
+	cohorts := make(map[time.Time]*CohortRetention)
+	for studentID, cohort := range firstMonth {
+		if cohort.Before(from) || !cohort.Before(lastMonth) {
+			continue
+		}
+
+		c, ok := cohorts[cohort]
+		if !ok {
+			span := monthsBetween(cohort, lastMonth)
+			if span > maxMonths {
+				span = maxMonths
+			}
+			c = &CohortRetention{Cohort: cohort, Active: make([]int, span), Retention: make([]float64, span)}
+			cohorts[cohort] = c
+		}
+		c.Size++
+
+		seen := make(map[int]bool)
+		for _, month := range months[studentID] {
+			offset := monthsBetween(cohort, month)
+			if offset < 0 || offset >= len(c.Active) || seen[offset] {
+				continue
+			}
+			seen[offset] = true
+			c.Active[offset]++
+		}
+	}
+
+	result := make([]*CohortRetention, 0, len(cohorts))
+	for _, c := range cohorts {
+		for i, active := range c.Active {
+			c.Retention[i] = ratio(int64(active), int64(c.Size))
+		}
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Cohort.Before(result[j].Cohort) })
+	return result
+}
+
+// monthStart возвращает первое число месяца по UTC
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// monthsBetween число полных календарных месяцев от from до to
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
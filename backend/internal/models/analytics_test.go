@@ -0,0 +1,131 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestAnalyticsQuery_DefaultsAndValidate(t *testing.T) {
+	now := time.Date(2026, 5, 20, 15, 30, 0, 0, time.UTC)
+
+	q := AnalyticsQuery{}
+	q.ApplyDefaults(now)
+	if !q.To.Equal(time.Date(2026, 5, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("To = %v, want start of tomorrow", q.To)
+	}
+	if q.To.Sub(q.From) != DefaultAnalyticsPeriodDays*24*time.Hour {
+		t.Errorf("default period = %v", q.To.Sub(q.From))
+	}
+	if q.Granularity != AnalyticsGranularityMonth {
+		t.Errorf("Granularity = %q, want month", q.Granularity)
+	}
+	if err := q.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		q       AnalyticsQuery
+		wantErr error
+	}{
+		{name: "unknown granularity", q: AnalyticsQuery{Granularity: "year"}, wantErr: ErrInvalidAnalyticsGranularity},
+		{name: "reversed period", q: AnalyticsQuery{From: now, To: now.AddDate(0, 0, -1)}, wantErr: ErrInvalidAnalyticsPeriod},
+		{name: "too long", q: AnalyticsQuery{From: now.AddDate(-3, 0, 0), To: now}, wantErr: ErrAnalyticsPeriodTooLong},
+		{name: "week", q: AnalyticsQuery{Granularity: AnalyticsGranularityWeek}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.q.ApplyDefaults(now)
+			if err := tt.q.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalyticsFinalize(t *testing.T) {
+	report := RevenueReport{Points: []*RevenuePoint{
+		{Source: "payment", PaymentsCount: 2, Amount: 5600},
+		{Source: "subscription", PaymentsCount: 1, Amount: 9900.5},
+	}}
+	report.Finalize()
+	if report.TotalAmount != 15500.5 || report.TotalCount != 3 {
+		t.Errorf("totals = %v / %d", report.TotalAmount, report.TotalCount)
+	}
+
+	flow := CreditFlowPoint{Added: 10, Deducted: 7, Refunded: 2}
+	flow.Finalize()
+	if flow.Burn != 5 {
+		t.Errorf("Burn = %d, want 5", flow.Burn)
+	}
+
+	row := UtilizationRow{SeatsBooked: 3, SeatsTotal: 4}
+	row.Finalize()
+	if row.Utilization != 0.75 {
+		t.Errorf("Utilization = %v, want 0.75", row.Utilization)
+	}
+
+	empty := UtilizationRow{}
+	empty.Finalize()
+	if empty.Utilization != 0 {
+		t.Errorf("empty Utilization = %v, want 0", empty.Utilization)
+	}
+
+	activity := BookingActivityPoint{Bookings: 20, Cancellations: 5, Swaps: 2}
+	activity.Finalize()
+	if activity.CancellationRate != 0.25 || activity.SwapRate != 0.1 {
+		t.Errorf("rates = %v / %v", activity.CancellationRate, activity.SwapRate)
+	}
+}
+
+func TestBuildCohortRetention(t *testing.T) {
+	month := func(m time.Month) time.Time { return time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC) }
+	a, b, c, old := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	activity := []*StudentMonthActivity{
+		// Январская когорта: a активен в январе и марте, b только в январе
+		{StudentID: a, ActivityMonth: month(time.January)},
+		{StudentID: a, ActivityMonth: month(time.March)},
+		{StudentID: b, ActivityMonth: month(time.January)},
+		// Февральская когорта: c активен в феврале и марте
+		{StudentID: c, ActivityMonth: month(time.February)},
+		{StudentID: c, ActivityMonth: month(time.March)},
+		// Когорта до начала периода не учитывается
+		{StudentID: old, ActivityMonth: month(time.January).AddDate(0, -2, 0)},
+		{StudentID: old, ActivityMonth: month(time.February)},
+	}
+
+	cohorts := BuildCohortRetention(activity, month(time.January), time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), 12)
+	if len(cohorts) != 2 {
+		t.Fatalf("got %d cohorts, want 2", len(cohorts))
+	}
+
+	jan := cohorts[0]
+	if !jan.Cohort.Equal(month(time.January)) || jan.Size != 2 {
+		t.Fatalf("january cohort = %+v", jan)
+	}
+	wantActive := []int{2, 0, 1}
+	wantRetention := []float64{1, 0, 0.5}
+	if len(jan.Active) != len(wantActive) {
+		t.Fatalf("january Active = %v, want %v", jan.Active, wantActive)
+	}
+	for i := range wantActive {
+		if jan.Active[i] != wantActive[i] || jan.Retention[i] != wantRetention[i] {
+			t.Errorf("january month %d: active %d retention %v, want %d %v",
+				i, jan.Active[i], jan.Retention[i], wantActive[i], wantRetention[i])
+		}
+	}
+
+	feb := cohorts[1]
+	if feb.Size != 1 || len(feb.Active) != 2 || feb.Active[1] != 1 || feb.Retention[1] != 1 {
+		t.Errorf("february cohort = %+v", feb)
+	}
+
+	limited := BuildCohortRetention(activity, month(time.January), month(time.April), 2)
+	if len(limited[0].Active) != 2 {
+		t.Errorf("maxMonths not applied: %v", limited[0].Active)
+	}
+}
@@ -0,0 +1,256 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction действие, записываемое в журнал аудита
+type AuditAction string
+
+const (
+	// AuditActionUserUpdate изменение пользователя администратором (роль, email, имя, пароль и т.д.)
+	AuditActionUserUpdate AuditAction = "user.update"
+	// AuditActionUserDelete удаление пользователя
+	AuditActionUserDelete AuditAction = "user.delete"
+	// AuditActionUserPaymentStatus включение или отключение платежей студента
+	AuditActionUserPaymentStatus AuditAction = "user.payment_status"
+	// AuditActionUserTelegramLink привязка Telegram к пользователю администратором
+	AuditActionUserTelegramLink AuditAction = "user.telegram_link"
+	// AuditActionSubjectCreate создание предмета
+	AuditActionSubjectCreate AuditAction = "subject.create"
+	// AuditActionSubjectUpdate изменение предмета
+	AuditActionSubjectUpdate AuditAction = "subject.update"
+	// AuditActionSubjectDelete удаление предмета
+	AuditActionSubjectDelete AuditAction = "subject.delete"
+	// AuditActionSubjectAssign назначение предмета преподавателю
+	AuditActionSubjectAssign AuditAction = "subject.assign"
+	// AuditActionSubjectUnassign снятие предмета с преподавателя
+	AuditActionSubjectUnassign AuditAction = "subject.unassign"
+	// AuditActionLessonDelete удаление занятия
+	AuditActionLessonDelete AuditAction = "lesson.delete"
+	// AuditActionLessonSeriesDelete удаление серии повторяющихся занятий
+	AuditActionLessonSeriesDelete AuditAction = "lesson.series_delete"
+	// AuditActionChatRoomView просмотр администратором чата, в котором он не участвует
+	AuditActionChatRoomView AuditAction = "chat.room_view"
+	// AuditActionChatMessageDelete удаление сообщения чата
+	AuditActionChatMessageDelete AuditAction = "chat.message_delete"
+	// AuditActionChatChannelCreate создание канала объявлений
+	AuditActionChatChannelCreate AuditAction = "chat.channel_create"
+	// AuditActionChatParticipantsAdd добавление участников в групповую комнату
+	AuditActionChatParticipantsAdd AuditAction = "chat.participants_add"
+	// AuditActionChatParticipantRemove удаление участника из групповой комнаты
+	AuditActionChatParticipantRemove AuditAction = "chat.participant_remove"
+	// AuditActionChatParticipantRole смена роли участника групповой комнаты
+	AuditActionChatParticipantRole AuditAction = "chat.participant_role"
+	// AuditActionChatParticipantMute мьют или снятие мьюта участника групповой комнаты
+	AuditActionChatParticipantMute AuditAction = "chat.participant_mute"
+	// AuditActionImpersonationStart администратор начал сессию от имени пользователя
+	AuditActionImpersonationStart AuditAction = "impersonation.start"
+	// AuditActionImpersonationStop администратор завершил сессию имперсонации
+	AuditActionImpersonationStop AuditAction = "impersonation.stop"
+	// AuditActionImpersonationRequest запрос, выполненный в сессии имперсонации (записывается middleware)
+	AuditActionImpersonationRequest AuditAction = "impersonation.request"
+	// AuditActionModerationRelease администратор снял блокировку сообщения
+	AuditActionModerationRelease AuditAction = "moderation.release"
+	// AuditActionModerationConfirm администратор подтвердил блокировку сообщения
+	AuditActionModerationConfirm AuditAction = "moderation.confirm"
+	// AuditActionHTTPRequest привилегированный запрос без отдельного хука в сервисе (записывается middleware)
+	AuditActionHTTPRequest AuditAction = "http.request"
+)
+
+// Типы объектов журнала аудита
+const (
+	AuditTargetUser     = "user"
+	AuditTargetSubject  = "subject"
+	AuditTargetLesson   = "lesson"
+	AuditTargetChatRoom = "chat_room"
+	AuditTargetMessage  = "message"
+	AuditTargetRoute    = "route"
+
+	AuditTargetBlockedMessage = "blocked_message"
+)
+
+const (
+	// AuditGenesisHash prev_hash первой записи цепочки
+	AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+	// DefaultAuditPageSize размер страницы журнала по умолчанию
+	DefaultAuditPageSize = 50
+	// MaxAuditPageSize максимальный размер страницы журнала
+	MaxAuditPageSize = 500
+)
+
+// auditIgnoredFields поля, изменение которых не считается значимым для аудита
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// AuditRedacted значение поля с персональными данными в журнале аудита. Журнал только дополняется
+// и не обезличивается при удалении пользователя, поэтому для таких полей фиксируется лишь факт изменения
+const AuditRedacted = "[redacted]"
+
+// auditPersonalFields поля с персональными данными, значения которых не попадают в журнал аудита
+var auditPersonalFields = map[string]bool{
+	"email":                    true,
+	"first_name":               true,
+	"last_name":                true,
+	"full_name":                true,
+	"phone":                    true,
+	"telegram_username":        true,
+	"parent_telegram_username": true,
+	"telegram_id":              true,
+	"chat_id":                  true,
+	"username":                 true,
+}
+
+// AuditEvent запись журнала аудита. Записи только добавляются, каждая хранит хеш предыдущей
+type AuditEvent struct {
+	ID         int64           `db:"id" json:"id"`
+	ActorID    uuid.NullUUID   `db:"actor_id" json:"actor_id,omitempty"`
+	ActorRole  string          `db:"actor_role" json:"actor_role,omitempty"`
+	ActorName  string          `db:"actor_name" json:"actor_name,omitempty"`
+	Action     AuditAction     `db:"action" json:"action"`
+	TargetType string          `db:"target_type" json:"target_type"`
+	TargetID   string          `db:"target_id" json:"target_id,omitempty"`
+	Before     json.RawMessage `db:"before_data" json:"before,omitempty"`
+	After      json.RawMessage `db:"after_data" json:"after,omitempty"`
+	IP         string          `db:"ip" json:"ip,omitempty"`
+	RequestID  string          `db:"request_id" json:"request_id,omitempty"`
+	Method     string          `db:"method" json:"method,omitempty"`
+	Path       string          `db:"path" json:"path,omitempty"`
+	PrevHash   string          `db:"prev_hash" json:"prev_hash"`
+	Hash       string          `db:"hash" json:"hash"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+// AuditFilter фильтр журнала аудита
+type AuditFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+// Validate проверяет фильтр и подставляет размер страницы по умолчанию
+func (f *AuditFilter) Validate() error {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return ErrInvalidAuditFilter
+	}
+	if f.Limit < 0 || f.Limit > MaxAuditPageSize || f.Offset < 0 {
+		return ErrInvalidAuditFilter
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultAuditPageSize
+	}
+	return nil
+}
+
+// AuditVerifyResult результат проверки целостности цепочки хешей
+type AuditVerifyResult struct {
+	Checked    int64  `json:"checked"`
+	Valid      bool   `json:"valid"`
+	BrokenAtID *int64 `json:"broken_at_id,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// AuditDiff возвращает измененные поля объекта до и после изменения.
+// Для создания (before == nil) возвращается весь новый объект, для удаления (after == nil) - весь старый.
+// Значения персональных данных заменяются на AuditRedacted. Если значимых изменений нет, оба результата nil
+func AuditDiff(before, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	beforeMap, err := auditObject(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterMap, err := auditObject(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case beforeMap == nil && afterMap == nil:
+		return nil, nil, nil
+	case beforeMap == nil:
+		afterJSON, err := marshalAuditObject(afterMap)
+		return nil, afterJSON, err
+	case afterMap == nil:
+		beforeJSON, err := marshalAuditObject(beforeMap)
+		return beforeJSON, nil, err
+	}
+
+	changedBefore := make(map[string]interface{})
+	changedAfter := make(map[string]interface{})
+	for key, oldValue := range beforeMap {
+		if newValue := afterMap[key]; !auditIgnoredFields[key] && !reflect.DeepEqual(oldValue, newValue) {
+			changedBefore[key] = oldValue
+			changedAfter[key] = newValue
+		}
+	}
+	for key, newValue := range afterMap {
+		if _, ok := beforeMap[key]; !ok && !auditIgnoredFields[key] {
+			changedBefore[key] = nil
+			changedAfter[key] = newValue
+		}
+	}
+	if len(changedAfter) == 0 {
+		return nil, nil, nil
+	}
+	redactAuditObject(changedBefore)
+	redactAuditObject(changedAfter)
+
+	beforeJSON, err := json.Marshal(changedBefore)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := json.Marshal(changedAfter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+// auditObject приводит значение к JSON объекту; не объекты оборачиваются в {"value": ...}
+func auditObject(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err == nil {
+		return obj, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"value": value}, nil
+}
+
+// marshalAuditObject сериализует объект целиком без игнорируемых полей и персональных данных
+func marshalAuditObject(obj map[string]interface{}) (json.RawMessage, error) {
+	for key := range auditIgnoredFields {
+		delete(obj, key)
+	}
+	redactAuditObject(obj)
+	return json.Marshal(obj)
+}
+
+// redactAuditObject заменяет непустые значения персональных данных на AuditRedacted
+func redactAuditObject(obj map[string]interface{}) {
+	for key, value := range obj {
+		if auditPersonalFields[key] && value != nil && value != "" {
+			obj[key] = AuditRedacted
+		}
+	}
+}
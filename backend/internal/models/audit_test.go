@@ -0,0 +1,153 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func decodeAuditJSON(t *testing.T, data json.RawMessage) map[string]interface{} {
+	t.Helper()
+	if data == nil {
+		return nil
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		t.Fatalf("invalid audit JSON %s: %v", data, err)
+	}
+	return obj
+}
+
+func TestAuditDiff_Update(t *testing.T) {
+	before := &Subject{Name: "Математика", Description: "Алгебра"}
+	after := &Subject{Name: "Математика", Description: "Алгебра и геометрия"}
+
+	beforeJSON, afterJSON, err := AuditDiff(before, after)
+	if err != nil {
+		t.Fatalf("AuditDiff() error = %v", err)
+	}
+
+	b := decodeAuditJSON(t, beforeJSON)
+	a := decodeAuditJSON(t, afterJSON)
+	if len(b) != 1 || len(a) != 1 {
+		t.Fatalf("expected only changed field, got before=%v after=%v", b, a)
+	}
+	if b["description"] != "Алгебра" || a["description"] != "Алгебра и геометрия" {
+		t.Errorf("unexpected diff: before=%v after=%v", b, a)
+	}
+}
+
+func TestAuditDiff_CreateAndDelete(t *testing.T) {
+	obj := map[string]interface{}{"name": "Физика", "updated_at": "2026-01-01"}
+
+	beforeJSON, afterJSON, err := AuditDiff(nil, obj)
+	if err != nil {
+		t.Fatalf("AuditDiff(create) error = %v", err)
+	}
+	if beforeJSON != nil {
+		t.Errorf("create: before = %s, want nil", beforeJSON)
+	}
+	if a := decodeAuditJSON(t, afterJSON); a["name"] != "Физика" || a["updated_at"] != nil {
+		t.Errorf("create: after = %v, want full object without updated_at", a)
+	}
+
+	var nilSubject *Subject
+	beforeJSON, afterJSON, err = AuditDiff(obj, nilSubject)
+	if err != nil {
+		t.Fatalf("AuditDiff(delete) error = %v", err)
+	}
+	if afterJSON != nil {
+		t.Errorf("delete: after = %s, want nil", afterJSON)
+	}
+	if b := decodeAuditJSON(t, beforeJSON); b["name"] != "Физика" {
+		t.Errorf("delete: before = %v, want full object", b)
+	}
+}
+
+func TestAuditDiff_NoChanges(t *testing.T) {
+	before := map[string]interface{}{"payment_enabled": true, "updated_at": "a"}
+	after := map[string]interface{}{"payment_enabled": true, "updated_at": "b"}
+
+	beforeJSON, afterJSON, err := AuditDiff(before, after)
+	if err != nil {
+		t.Fatalf("AuditDiff() error = %v", err)
+	}
+	if beforeJSON != nil || afterJSON != nil {
+		t.Errorf("expected no diff, got before=%s after=%s", beforeJSON, afterJSON)
+	}
+}
+
+func TestAuditDiff_AddedField(t *testing.T) {
+	beforeJSON, afterJSON, err := AuditDiff(
+		map[string]interface{}{"role": "student"},
+		map[string]interface{}{"role": "student", "password_changed": true},
+	)
+	if err != nil {
+		t.Fatalf("AuditDiff() error = %v", err)
+	}
+
+	b := decodeAuditJSON(t, beforeJSON)
+	a := decodeAuditJSON(t, afterJSON)
+	if v, ok := b["password_changed"]; !ok || v != nil {
+		t.Errorf("before = %v, want password_changed: null", b)
+	}
+	if a["password_changed"] != true {
+		t.Errorf("after = %v, want password_changed: true", a)
+	}
+}
+
+func TestAuditDiff_RedactsPersonalData(t *testing.T) {
+	before := map[string]interface{}{"email": "old@example.com", "first_name": "Анна", "role": "student"}
+	after := map[string]interface{}{"email": "new@example.com", "first_name": "Анна", "role": "teacher"}
+
+	beforeJSON, afterJSON, err := AuditDiff(before, after)
+	if err != nil {
+		t.Fatalf("AuditDiff() error = %v", err)
+	}
+	b := decodeAuditJSON(t, beforeJSON)
+	a := decodeAuditJSON(t, afterJSON)
+	if b["email"] != AuditRedacted || a["email"] != AuditRedacted {
+		t.Errorf("changed email must be redacted: before=%v after=%v", b, a)
+	}
+	if _, ok := a["first_name"]; ok {
+		t.Errorf("unchanged first_name must not be recorded: after=%v", a)
+	}
+	if b["role"] != "student" || a["role"] != "teacher" {
+		t.Errorf("role must be recorded as is: before=%v after=%v", b, a)
+	}
+
+	_, afterJSON, err = AuditDiff(nil, map[string]interface{}{"email": "new@example.com", "last_name": "", "role": "student"})
+	if err != nil {
+		t.Fatalf("AuditDiff(create) error = %v", err)
+	}
+	a = decodeAuditJSON(t, afterJSON)
+	if a["email"] != AuditRedacted || a["last_name"] != "" || a["role"] != "student" {
+		t.Errorf("create: after = %v, want email redacted", a)
+	}
+}
+
+func TestAuditFilterValidate(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	f := &AuditFilter{From: &from, To: &to}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if f.Limit != DefaultAuditPageSize {
+		t.Errorf("Limit = %d, want default %d", f.Limit, DefaultAuditPageSize)
+	}
+
+	invalid := []*AuditFilter{
+		{From: &to, To: &from},
+		{Limit: MaxAuditPageSize + 1},
+		{Limit: -1},
+		{Offset: -1},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); !errors.Is(err, ErrInvalidAuditFilter) {
+			t.Errorf("Validate(%+v) error = %v, want ErrInvalidAuditFilter", f, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Подстановки, которые рендерятся в тексте рассылки для каждого получателя
+const (
+	BroadcastPlaceholderFirstName  = "{first_name}"
+	BroadcastPlaceholderLastName   = "{last_name}"
+	BroadcastPlaceholderBalance    = "{balance}"
+	BroadcastPlaceholderNextLesson = "{next_lesson}"
+)
+
+// BroadcastNoUpcomingLesson подставляется в {next_lesson}, если у получателя нет будущих занятий
+const BroadcastNoUpcomingLesson = "не запланировано"
+
+// AudienceMaxInactiveDays максимальный период неактивности в фильтре аудитории
+const AudienceMaxInactiveDays = 365
+
+// broadcastPlaceholderPattern находит все подстановки вида {name} в тексте
+var broadcastPlaceholderPattern = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// knownBroadcastPlaceholders поддерживаемые подстановки
+var knownBroadcastPlaceholders = map[string]bool{
+	BroadcastPlaceholderFirstName:  true,
+	BroadcastPlaceholderLastName:   true,
+	BroadcastPlaceholderBalance:    true,
+	BroadcastPlaceholderNextLesson: true,
+}
+
+// AudienceFilter сохранённый фильтр получателей рассылки.
+// Все заданные условия объединяются через AND, пустой фильтр выбирает всех подписанных пользователей.
+type AudienceFilter struct {
+	Roles        []UserRole  `json:"roles,omitempty"`         // Роли пользователей
+	SubjectIDs   []uuid.UUID `json:"subject_ids,omitempty"`   // Студенты с записями на занятия по предмету и преподаватели предмета
+	TeacherIDs   []uuid.UUID `json:"teacher_ids,omitempty"`   // Студенты, записанные к этим преподавателям
+	MaxBalance   *int        `json:"max_balance,omitempty"`   // Баланс кредитов не выше порога
+	InactiveDays *int        `json:"inactive_days,omitempty"` // Не было занятий за последние N дней
+}
+
+// Value реализует driver.Valuer для хранения фильтра в JSONB
+func (f AudienceFilter) Value() (driver.Value, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan реализует sql.Scanner для чтения фильтра из JSONB
+func (f *AudienceFilter) Scan(value interface{}) error {
+	if value == nil {
+		*f = AudienceFilter{}
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	default:
+		return fmt.Errorf("unsupported audience filter type %T", value)
+	}
+}
+
+// Validate выполняет валидацию AudienceFilter
+func (f *AudienceFilter) Validate() error {
+	for _, role := range f.Roles {
+		if role != RoleStudent && role != RoleTeacher && role != RoleAdmin {
+			return ErrInvalidAudienceRole
+		}
+	}
+	if f.MaxBalance != nil && *f.MaxBalance < 0 {
+		return ErrInvalidAudienceMaxBalance
+	}
+	if f.InactiveDays != nil && (*f.InactiveDays < 1 || *f.InactiveDays > AudienceMaxInactiveDays) {
+		return ErrInvalidAudienceInactiveDays
+	}
+	return nil
+}
+
+// BroadcastAudience сохранённая аудитория рассылки, получатели вычисляются в момент отправки
+type BroadcastAudience struct {
+	ID          uuid.UUID      `db:"id" json:"id"`
+	Name        string         `db:"name" json:"name"`
+	Description string         `db:"description" json:"description"`
+	Filter      AudienceFilter `db:"filter" json:"filter"`
+	CreatedBy   uuid.UUID      `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+	DeletedAt   *time.Time     `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// BroadcastTemplate шаблон текста рассылки с подстановками
+type BroadcastTemplate struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	Body      string     `db:"body" json:"body"`
+	CreatedBy uuid.UUID  `db:"created_by" json:"created_by"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// BroadcastRecipient данные получателя для рендеринга подстановок
+type BroadcastRecipient struct {
+	UserID       uuid.UUID  `db:"user_id" json:"user_id"`
+	FirstName    string     `db:"first_name" json:"first_name"`
+	LastName     string     `db:"last_name" json:"last_name"`
+	Balance      int        `db:"balance" json:"balance"`
+	NextLessonAt *time.Time `db:"next_lesson_at" json:"next_lesson_at,omitempty"`
+}
+
+// SaveBroadcastTemplateRequest запрос на создание или обновление шаблона рассылки
+type SaveBroadcastTemplateRequest struct {
+	Name string `json:"name"`
+	Body string `json:"body"`
+}
+
+// SaveBroadcastAudienceRequest запрос на создание или обновление аудитории рассылки
+type SaveBroadcastAudienceRequest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Filter      AudienceFilter `json:"filter"`
+}
+
+// Validate выполняет валидацию SaveBroadcastTemplateRequest
+func (r *SaveBroadcastTemplateRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || utf8.RuneCountInString(r.Name) > 200 {
+		return ErrInvalidBroadcastTemplateName
+	}
+	return ValidateBroadcastMessage(r.Body)
+}
+
+// Validate выполняет валидацию SaveBroadcastAudienceRequest
+func (r *SaveBroadcastAudienceRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || utf8.RuneCountInString(r.Name) > 200 {
+		return ErrInvalidBroadcastAudienceName
+	}
+	return r.Filter.Validate()
+}
+
+// ValidateBroadcastMessage проверяет длину текста рассылки и допустимость подстановок
+func ValidateBroadcastMessage(message string) error {
+	if message == "" {
+		return ErrInvalidBroadcastMessage
+	}
+	if len(message) > 4096 {
+		return ErrBroadcastMessageTooLong
+	}
+	for _, placeholder := range broadcastPlaceholderPattern.FindAllString(message, -1) {
+		if !knownBroadcastPlaceholders[placeholder] {
+			return ErrUnknownBroadcastPlaceholder
+		}
+	}
+	return nil
+}
+
+// HasBroadcastPlaceholders проверяет, нужно ли рендерить текст отдельно для каждого получателя
+func HasBroadcastPlaceholders(message string) bool {
+	for _, placeholder := range broadcastPlaceholderPattern.FindAllString(message, -1) {
+		if knownBroadcastPlaceholders[placeholder] {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderBroadcastMessage подставляет данные получателя в текст рассылки.
+// Значения экранируются под parseMode, чтобы имя вида "<Аня>" не ломало разметку
+func RenderBroadcastMessage(message string, recipient *BroadcastRecipient, parseMode string) string {
+	nextLesson := BroadcastNoUpcomingLesson
+	if recipient.NextLessonAt != nil {
+		nextLesson = recipient.NextLessonAt.Format("02.01.2006 15:04")
+	}
+
+	return strings.NewReplacer(
+		BroadcastPlaceholderFirstName, EscapeBroadcastText(recipient.FirstName, parseMode),
+		BroadcastPlaceholderLastName, EscapeBroadcastText(recipient.LastName, parseMode),
+		BroadcastPlaceholderBalance, EscapeBroadcastText(strconv.Itoa(recipient.Balance), parseMode),
+		BroadcastPlaceholderNextLesson, EscapeBroadcastText(nextLesson, parseMode),
+	).Replace(message)
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestAudienceFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  AudienceFilter
+		wantErr error
+	}{
+		{
+			name:   "empty filter",
+			filter: AudienceFilter{},
+		},
+		{
+			name: "all conditions",
+			filter: AudienceFilter{
+				Roles:        []UserRole{RoleStudent},
+				SubjectIDs:   []uuid.UUID{uuid.New()},
+				TeacherIDs:   []uuid.UUID{uuid.New()},
+				MaxBalance:   intPtr(0),
+				InactiveDays: intPtr(30),
+			},
+		},
+		{
+			name:    "unknown role",
+			filter:  AudienceFilter{Roles: []UserRole{"parent"}},
+			wantErr: ErrInvalidAudienceRole,
+		},
+		{
+			name:    "negative balance",
+			filter:  AudienceFilter{MaxBalance: intPtr(-1)},
+			wantErr: ErrInvalidAudienceMaxBalance,
+		},
+		{
+			name:    "zero inactive days",
+			filter:  AudienceFilter{InactiveDays: intPtr(0)},
+			wantErr: ErrInvalidAudienceInactiveDays,
+		},
+		{
+			name:    "inactive days above limit",
+			filter:  AudienceFilter{InactiveDays: intPtr(AudienceMaxInactiveDays + 1)},
+			wantErr: ErrInvalidAudienceInactiveDays,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); err != tt.wantErr {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAudienceFilter_ScanValueRoundTrip(t *testing.T) {
+	filter := AudienceFilter{
+		Roles:        []UserRole{RoleStudent, RoleTeacher},
+		TeacherIDs:   []uuid.UUID{uuid.New()},
+		InactiveDays: intPtr(14),
+	}
+
+	value, err := filter.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	var scanned AudienceFilter
+	if err := scanned.Scan([]byte(value.(string))); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(scanned.Roles) != 2 || scanned.Roles[1] != RoleTeacher {
+		t.Errorf("Roles = %v, want %v", scanned.Roles, filter.Roles)
+	}
+	if len(scanned.TeacherIDs) != 1 || scanned.TeacherIDs[0] != filter.TeacherIDs[0] {
+		t.Errorf("TeacherIDs = %v, want %v", scanned.TeacherIDs, filter.TeacherIDs)
+	}
+	if scanned.InactiveDays == nil || *scanned.InactiveDays != 14 {
+		t.Errorf("InactiveDays = %v, want 14", scanned.InactiveDays)
+	}
+	if scanned.MaxBalance != nil {
+		t.Errorf("MaxBalance = %v, want nil", *scanned.MaxBalance)
+	}
+
+	if err := scanned.Scan(nil); err != nil || len(scanned.Roles) != 0 {
+		t.Errorf("Scan(nil) should reset filter, got %+v (err %v)", scanned, err)
+	}
+}
+
+func TestValidateBroadcastMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		wantErr error
+	}{
+		{name: "plain text", message: "Занятия переносятся"},
+		{name: "known placeholders", message: "{first_name} {last_name}, баланс {balance}, занятие {next_lesson}"},
+		{name: "braces without placeholder", message: "Формула: f(x) = {x | x > 0}"},
+		{name: "empty", message: "", wantErr: ErrInvalidBroadcastMessage},
+		{name: "unknown placeholder", message: "Привет, {name}!", wantErr: ErrUnknownBroadcastPlaceholder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateBroadcastMessage(tt.message); err != tt.wantErr {
+				t.Errorf("ValidateBroadcastMessage() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHasBroadcastPlaceholders(t *testing.T) {
+	if HasBroadcastPlaceholders("Обычное сообщение") {
+		t.Error("plain message should not require rendering")
+	}
+	if !HasBroadcastPlaceholders("Ваш баланс: {balance}") {
+		t.Error("message with {balance} should require rendering")
+	}
+}
+
+func TestRenderBroadcastMessage(t *testing.T) {
+	next := time.Date(2026, 10, 20, 17, 30, 0, 0, time.UTC)
+	recipient := &BroadcastRecipient{
+		FirstName:    "Анна",
+		LastName:     "Иванова",
+		Balance:      3,
+		NextLessonAt: &next,
+	}
+
+	got := RenderBroadcastMessage("{first_name} {last_name}: {balance} кр., ближайшее занятие {next_lesson}. {first_name}!", recipient, "")
+	want := "Анна Иванова: 3 кр., ближайшее занятие 20.10.2026 17:30. Анна!"
+	if got != want {
+		t.Errorf("RenderBroadcastMessage() = %q, want %q", got, want)
+	}
+
+	recipient.NextLessonAt = nil
+	if got := RenderBroadcastMessage("{next_lesson}", recipient, ""); got != BroadcastNoUpcomingLesson {
+		t.Errorf("RenderBroadcastMessage() without lesson = %q, want %q", got, BroadcastNoUpcomingLesson)
+	}
+}
+
+func TestSaveBroadcastTemplateRequest_Validate(t *testing.T) {
+	req := SaveBroadcastTemplateRequest{Name: "  Напоминание  ", Body: "Привет, {first_name}"}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if req.Name != "Напоминание" {
+		t.Errorf("Name = %q, want trimmed", req.Name)
+	}
+
+	if err := (&SaveBroadcastTemplateRequest{Name: " ", Body: "x"}).Validate(); err != ErrInvalidBroadcastTemplateName {
+		t.Errorf("Validate() = %v, want %v", err, ErrInvalidBroadcastTemplateName)
+	}
+	if err := (&SaveBroadcastTemplateRequest{Name: "x", Body: "{unknown}"}).Validate(); err != ErrUnknownBroadcastPlaceholder {
+		t.Errorf("Validate() = %v, want %v", err, ErrUnknownBroadcastPlaceholder)
+	}
+}
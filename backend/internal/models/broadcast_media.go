@@ -0,0 +1,272 @@
+package models
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Режимы форматирования рассылки (Telegram parse_mode), пустая строка - обычный текст
+const (
+	BroadcastParseModeHTML       = "HTML"
+	BroadcastParseModeMarkdownV2 = "MarkdownV2"
+)
+
+// Типы вложений рассылки
+const (
+	BroadcastAttachmentPhoto    = "photo"
+	BroadcastAttachmentDocument = "document"
+)
+
+// Ограничения inline клавиатуры
+const (
+	MaxBroadcastButtonRows     = 8
+	MaxBroadcastButtonsPerRow  = 8
+	MaxBroadcastButtonTextLen  = 64
+	MaxBroadcastCallbackLength = 64 // Лимит Telegram на callback_data в байтах
+)
+
+// allowedBroadcastHTMLTags теги, которые Telegram поддерживает в parse_mode=HTML
+var allowedBroadcastHTMLTags = map[string]bool{
+	"b": true, "strong": true, "i": true, "em": true, "u": true, "ins": true,
+	"s": true, "strike": true, "del": true, "span": true, "tg-spoiler": true,
+	"a": true, "code": true, "pre": true, "blockquote": true,
+}
+
+// broadcastHTMLTagPattern разбирает открывающий или закрывающий тег
+var broadcastHTMLTagPattern = regexp.MustCompile(`^<(/?)([a-z][a-z-]*)(\s[^<>]*)?>`)
+
+// broadcastPhotoMimeTypes изображения, которые отправляются как фото (sendPhoto)
+var broadcastPhotoMimeTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+}
+
+// broadcastDocumentMimeTypes файлы, которые отправляются как документ (sendDocument)
+var broadcastDocumentMimeTypes = map[string]bool{
+	"application/pdf":    true,
+	"application/msword": true,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
+	"application/vnd.ms-excel": true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
+	"application/vnd.ms-powerpoint":                                             true,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+	"application/zip": true,
+	"text/plain":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+}
+
+// BroadcastButton inline кнопка под сообщением рассылки: ссылка или callback
+type BroadcastButton struct {
+	Text         string `json:"text"`
+	URL          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
+}
+
+// BroadcastKeyboard inline клавиатура рассылки: ряды кнопок
+type BroadcastKeyboard [][]BroadcastButton
+
+// Value реализует driver.Valuer для хранения клавиатуры в JSONB
+func (k BroadcastKeyboard) Value() (driver.Value, error) {
+	if k == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(k)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan реализует sql.Scanner для чтения клавиатуры из JSONB
+func (k *BroadcastKeyboard) Scan(value interface{}) error {
+	if value == nil {
+		*k = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, k)
+	case string:
+		return json.Unmarshal([]byte(v), k)
+	default:
+		return fmt.Errorf("unsupported broadcast keyboard type %T", value)
+	}
+}
+
+// Validate проверяет клавиатуру на соответствие ограничениям Telegram
+func (k BroadcastKeyboard) Validate() error {
+	if len(k) > MaxBroadcastButtonRows {
+		return ErrInvalidBroadcastButtons
+	}
+	for _, row := range k {
+		if len(row) == 0 || len(row) > MaxBroadcastButtonsPerRow {
+			return ErrInvalidBroadcastButtons
+		}
+		for i := range row {
+			if err := row[i].Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate проверяет текст и действие кнопки
+func (b *BroadcastButton) Validate() error {
+	b.Text = strings.TrimSpace(b.Text)
+	if b.Text == "" || utf8.RuneCountInString(b.Text) > MaxBroadcastButtonTextLen {
+		return ErrInvalidBroadcastButtons
+	}
+
+	hasURL := b.URL != ""
+	hasCallback := b.CallbackData != ""
+	if hasURL == hasCallback {
+		return ErrInvalidBroadcastButton
+	}
+	if hasCallback && len(b.CallbackData) > MaxBroadcastCallbackLength {
+		return ErrInvalidBroadcastButton
+	}
+	if hasURL && !isAllowedBroadcastURL(b.URL) {
+		return ErrInvalidBroadcastButton
+	}
+	return nil
+}
+
+// isAllowedBroadcastURL разрешает только http(s) ссылки с хостом и tg:// ссылки
+func isAllowedBroadcastURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return u.Host != ""
+	case "tg":
+		return u.Host != "" || u.Opaque != ""
+	default:
+		return false
+	}
+}
+
+// BroadcastAttachment вложение админской рассылки (фото или документ)
+type BroadcastAttachment struct {
+	ID             uuid.UUID      `db:"id" json:"id"`
+	BroadcastID    uuid.UUID      `db:"broadcast_id" json:"broadcast_id"`
+	Kind           string         `db:"kind" json:"kind"`
+	FileName       string         `db:"file_name" json:"file_name"`
+	FilePath       string         `db:"file_path" json:"-"`
+	FileSize       int64          `db:"file_size" json:"file_size"`
+	MimeType       string         `db:"mime_type" json:"mime_type"`
+	TelegramFileID *string        `db:"telegram_file_id" json:"-"`
+	StorageKey     sql.NullString `db:"storage_key" json:"-"`
+	ContentHash    sql.NullString `db:"content_hash" json:"-"`
+	Position       int            `db:"position" json:"position"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+}
+
+// BroadcastAttachmentKind определяет способ отправки вложения по MIME типу
+func BroadcastAttachmentKind(mimeType string) (string, error) {
+	if broadcastPhotoMimeTypes[mimeType] {
+		return BroadcastAttachmentPhoto, nil
+	}
+	if broadcastDocumentMimeTypes[mimeType] {
+		return BroadcastAttachmentDocument, nil
+	}
+	return "", ErrInvalidBroadcastFileType
+}
+
+// EditBroadcastMessageRequest запрос на изменение текста уже доставленной рассылки
+type EditBroadcastMessageRequest struct {
+	Message string `json:"message"`
+}
+
+// Validate выполняет валидацию EditBroadcastMessageRequest
+func (r *EditBroadcastMessageRequest) Validate() error {
+	return ValidateBroadcastMessage(r.Message)
+}
+
+// ValidateBroadcastParseMode проверяет, что режим форматирования поддерживается
+func ValidateBroadcastParseMode(parseMode string) error {
+	switch parseMode {
+	case "", BroadcastParseModeHTML, BroadcastParseModeMarkdownV2:
+		return nil
+	default:
+		return ErrInvalidBroadcastParseMode
+	}
+}
+
+// ValidateBroadcastFormatting проверяет разметку текста для выбранного parse_mode.
+// Для HTML допускаются только теги Telegram, все теги должны быть закрыты в правильном порядке,
+// а символ < вне тегов должен быть экранирован как &lt;. MarkdownV2 проверяет сам Telegram
+func ValidateBroadcastFormatting(message, parseMode string) error {
+	if err := ValidateBroadcastParseMode(parseMode); err != nil {
+		return err
+	}
+	if parseMode != BroadcastParseModeHTML {
+		return nil
+	}
+
+	var stack []string
+	for i := 0; i < len(message); i++ {
+		if message[i] == '>' {
+			return ErrInvalidBroadcastHTML
+		}
+		if message[i] != '<' {
+			continue
+		}
+
+		match := broadcastHTMLTagPattern.FindStringSubmatch(message[i:])
+		if match == nil || !allowedBroadcastHTMLTags[match[2]] {
+			return ErrInvalidBroadcastHTML
+		}
+
+		if match[1] == "/" {
+			if len(stack) == 0 || stack[len(stack)-1] != match[2] {
+				return ErrInvalidBroadcastHTML
+			}
+			stack = stack[:len(stack)-1]
+		} else {
+			stack = append(stack, match[2])
+		}
+		i += len(match[0]) - 1
+	}
+
+	if len(stack) > 0 {
+		return ErrInvalidBroadcastHTML
+	}
+	return nil
+}
+
+// markdownV2Escaper экранирует все спецсимволы MarkdownV2
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// htmlEscaper экранирует символы, которые Telegram требует экранировать в parse_mode=HTML
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// EscapeBroadcastText экранирует произвольный текст (например, подставляемое имя),
+// чтобы он не ломал разметку сообщения
+func EscapeBroadcastText(text, parseMode string) string {
+	switch parseMode {
+	case BroadcastParseModeHTML:
+		return htmlEscaper.Replace(text)
+	case BroadcastParseModeMarkdownV2:
+		return markdownV2Escaper.Replace(text)
+	default:
+		return text
+	}
+}
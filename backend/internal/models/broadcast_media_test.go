@@ -0,0 +1,120 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateBroadcastFormatting(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		parseMode string
+		wantErr   error
+	}{
+		{name: "plain text ignores markup", message: "<b>не тег", parseMode: ""},
+		{name: "markdown is checked by telegram", message: "*жирный*", parseMode: BroadcastParseModeMarkdownV2},
+		{name: "html nested tags", message: `<b>Важно:</b> <i>занятие <u>перенесено</u></i> <a href="https://example.com">подробнее</a>`, parseMode: BroadcastParseModeHTML},
+		{name: "html escaped brackets", message: "1 &lt; 2", parseMode: BroadcastParseModeHTML},
+		{name: "html unknown tag", message: "<script>alert(1)</script>", parseMode: BroadcastParseModeHTML, wantErr: ErrInvalidBroadcastHTML},
+		{name: "html unclosed tag", message: "<b>жирный", parseMode: BroadcastParseModeHTML, wantErr: ErrInvalidBroadcastHTML},
+		{name: "html crossed tags", message: "<b><i>текст</b></i>", parseMode: BroadcastParseModeHTML, wantErr: ErrInvalidBroadcastHTML},
+		{name: "html bare bracket", message: "1 < 2", parseMode: BroadcastParseModeHTML, wantErr: ErrInvalidBroadcastHTML},
+		{name: "unknown parse mode", message: "текст", parseMode: "Markdown", wantErr: ErrInvalidBroadcastParseMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateBroadcastFormatting(tt.message, tt.parseMode); err != tt.wantErr {
+				t.Errorf("ValidateBroadcastFormatting() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEscapeBroadcastText(t *testing.T) {
+	if got := EscapeBroadcastText("<Аня & Co>", BroadcastParseModeHTML); got != "&lt;Аня &amp; Co&gt;" {
+		t.Errorf("HTML escape = %q", got)
+	}
+	if got := EscapeBroadcastText("a_b*c.d!", BroadcastParseModeMarkdownV2); got != `a\_b\*c\.d\!` {
+		t.Errorf("MarkdownV2 escape = %q", got)
+	}
+	if got := EscapeBroadcastText("<b>", ""); got != "<b>" {
+		t.Errorf("plain text should not be escaped, got %q", got)
+	}
+}
+
+func TestRenderBroadcastMessage_EscapesValues(t *testing.T) {
+	recipient := &BroadcastRecipient{FirstName: "<Аня>", LastName: "Smith-Jones", Balance: 1}
+
+	if got := RenderBroadcastMessage("<b>{first_name}</b>", recipient, BroadcastParseModeHTML); got != "<b>&lt;Аня&gt;</b>" {
+		t.Errorf("HTML render = %q", got)
+	}
+	if got := RenderBroadcastMessage("*{last_name}*", recipient, BroadcastParseModeMarkdownV2); got != `*Smith\-Jones*` {
+		t.Errorf("MarkdownV2 render = %q", got)
+	}
+}
+
+func TestBroadcastKeyboard_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		keyboard BroadcastKeyboard
+		wantErr  error
+	}{
+		{name: "empty", keyboard: nil},
+		{
+			name: "url and callback buttons",
+			keyboard: BroadcastKeyboard{
+				{{Text: "Сайт", URL: "https://example.com"}, {Text: "Канал", URL: "tg://resolve?domain=example"}},
+				{{Text: "Да", CallbackData: "poll:yes"}},
+			},
+		},
+		{name: "empty row", keyboard: BroadcastKeyboard{{}}, wantErr: ErrInvalidBroadcastButtons},
+		{name: "blank text", keyboard: BroadcastKeyboard{{{Text: "  ", URL: "https://example.com"}}}, wantErr: ErrInvalidBroadcastButtons},
+		{name: "both actions", keyboard: BroadcastKeyboard{{{Text: "x", URL: "https://example.com", CallbackData: "x"}}}, wantErr: ErrInvalidBroadcastButton},
+		{name: "javascript url", keyboard: BroadcastKeyboard{{{Text: "x", URL: "javascript:alert(1)"}}}, wantErr: ErrInvalidBroadcastButton},
+		{name: "long callback", keyboard: BroadcastKeyboard{{{Text: "x", CallbackData: strings.Repeat("a", 65)}}}, wantErr: ErrInvalidBroadcastButton},
+		{name: "too many rows", keyboard: make(BroadcastKeyboard, MaxBroadcastButtonRows+1), wantErr: ErrInvalidBroadcastButtons},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.keyboard.Validate(); err != tt.wantErr {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBroadcastKeyboard_ScanValueRoundTrip(t *testing.T) {
+	keyboard := BroadcastKeyboard{{{Text: "Сайт", URL: "https://example.com"}}}
+
+	value, err := keyboard.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	var scanned BroadcastKeyboard
+	if err := scanned.Scan([]byte(value.(string))); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(scanned) != 1 || scanned[0][0] != keyboard[0][0] {
+		t.Errorf("scanned = %+v, want %+v", scanned, keyboard)
+	}
+
+	if value, _ := BroadcastKeyboard(nil).Value(); value != "[]" {
+		t.Errorf("nil keyboard Value() = %v, want []", value)
+	}
+}
+
+func TestBroadcastAttachmentKind(t *testing.T) {
+	if kind, err := BroadcastAttachmentKind("image/png"); err != nil || kind != BroadcastAttachmentPhoto {
+		t.Errorf("image/png = %q, %v", kind, err)
+	}
+	if kind, err := BroadcastAttachmentKind("application/pdf"); err != nil || kind != BroadcastAttachmentDocument {
+		t.Errorf("application/pdf = %q, %v", kind, err)
+	}
+	if _, err := BroadcastAttachmentKind("application/x-msdownload"); err != ErrInvalidBroadcastFileType {
+		t.Errorf("executable should be rejected, got %v", err)
+	}
+}
@@ -1,8 +1,12 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +20,8 @@ type ApplyToAllSubsequentRequest struct {
 	TeacherID        *uuid.UUID `json:"teacher_id,omitempty"`
 	NewStartTime     *string    `json:"new_start_time,omitempty"` // ISO format
 	NewMaxStudents   *int       `json:"new_max_students,omitempty"`
+	DryRun           bool       `json:"dry_run,omitempty"`       // Only preview the changes, do not apply
+	PreviewToken     string     `json:"preview_token,omitempty"` // Token from a dry run; apply fails if the target set changed
 }
 
 // Validate validates the ApplyToAllSubsequentRequest based on modification type
@@ -52,6 +58,34 @@ func (r *ApplyToAllSubsequentRequest) Validate() error {
 	return nil
 }
 
+// ComputePreviewToken returns a token binding the modification parameters to the exact set of target lessons.
+// A dry run returns it, and applying with the same token fails if the target set has changed in between.
+func (r *ApplyToAllSubsequentRequest) ComputePreviewToken(lessonIDs []uuid.UUID) string {
+	ids := make([]string, 0, len(lessonIDs))
+	for _, id := range lessonIDs {
+		ids = append(ids, id.String())
+	}
+	sort.Strings(ids)
+
+	parts := []string{r.LessonID.String(), r.ModificationType}
+	if r.StudentID != nil {
+		parts = append(parts, "student="+r.StudentID.String())
+	}
+	if r.TeacherID != nil {
+		parts = append(parts, "teacher="+r.TeacherID.String())
+	}
+	if r.NewStartTime != nil {
+		parts = append(parts, "start="+*r.NewStartTime)
+	}
+	if r.NewMaxStudents != nil {
+		parts = append(parts, fmt.Sprintf("capacity=%d", *r.NewMaxStudents))
+	}
+	parts = append(parts, ids...)
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
 // LessonModification represents an audit trail entry for bulk lesson modifications
 type LessonModification struct {
 	ID                   uuid.UUID       `json:"id" db:"id"`
@@ -63,6 +97,13 @@ type LessonModification struct {
 	AffectedLessonsCount int             `json:"affected_lessons_count" db:"affected_lessons_count"`
 	ChangesJSON          json.RawMessage `json:"changes_json" db:"changes_json"`
 	Notes                string          `json:"notes" db:"notes"`
+	RevertedAt           *time.Time      `json:"reverted_at,omitempty" db:"reverted_at"`
+	RevertedByID         *uuid.UUID      `json:"reverted_by_id,omitempty" db:"reverted_by_id"`
+}
+
+// IsReverted returns true if the modification has already been reverted
+func (m *LessonModification) IsReverted() bool {
+	return m.RevertedAt != nil
 }
 
 // ApplyToAllSubsequentResponse represents the response after applying a modification
@@ -72,3 +113,143 @@ type ApplyToAllSubsequentResponse struct {
 	AffectedLessonsCount int                 `json:"affected_lessons_count"`
 	Message              string              `json:"message"`
 }
+
+// LessonSnapshot captures the lesson fields a bulk modification can change
+type LessonSnapshot struct {
+	TeacherID   uuid.UUID   `json:"teacher_id"`
+	StartTime   time.Time   `json:"start_time"`
+	EndTime     time.Time   `json:"end_time"`
+	MaxStudents int         `json:"max_students"`
+	StudentIDs  []uuid.UUID `json:"student_ids"` // Students with active bookings
+}
+
+// HasStudent returns true if the student has an active booking in the snapshot
+func (s *LessonSnapshot) HasStudent(studentID uuid.UUID) bool {
+	for _, id := range s.StudentIDs {
+		if id == studentID {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns human-readable differences between two snapshots (empty if equal)
+func (s *LessonSnapshot) Diff(other *LessonSnapshot) []string {
+	var diffs []string
+	if s.TeacherID != other.TeacherID {
+		diffs = append(diffs, "teacher changed")
+	}
+	if !s.StartTime.Equal(other.StartTime) || !s.EndTime.Equal(other.EndTime) {
+		diffs = append(diffs, "time changed")
+	}
+	if s.MaxStudents != other.MaxStudents {
+		diffs = append(diffs, "capacity changed")
+	}
+	if len(s.StudentIDs) != len(other.StudentIDs) {
+		diffs = append(diffs, "enrolled students changed")
+	} else {
+		for _, id := range s.StudentIDs {
+			if !other.HasStudent(id) {
+				diffs = append(diffs, "enrolled students changed")
+				break
+			}
+		}
+	}
+	return diffs
+}
+
+// CreditMovement is a credit balance change caused by a bulk modification (negative = deducted)
+type CreditMovement struct {
+	StudentID uuid.UUID `json:"student_id"`
+	Amount    int       `json:"amount"`
+}
+
+// LessonModificationSnapshot stores before/after state of one lesson affected by a bulk modification
+type LessonModificationSnapshot struct {
+	ID              uuid.UUID        `json:"id"`
+	ModificationID  uuid.UUID        `json:"modification_id"`
+	LessonID        uuid.UUID        `json:"lesson_id"`
+	Before          LessonSnapshot   `json:"before"`
+	After           LessonSnapshot   `json:"after"`
+	CreditMovements []CreditMovement `json:"credit_movements"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+// RevertConflict describes a lesson that cannot be reverted because it changed after the modification
+type RevertConflict struct {
+	LessonID  uuid.UUID `json:"lesson_id"`
+	StartTime time.Time `json:"start_time"`
+	Reasons   []string  `json:"reasons"`
+}
+
+// RevertModificationResult represents the outcome of reverting a bulk modification
+type RevertModificationResult struct {
+	ModificationID    uuid.UUID `json:"modification_id"`
+	RevertedLessons   int       `json:"reverted_lessons"`
+	RestoredBookings  int       `json:"restored_bookings"`
+	CancelledBookings int       `json:"cancelled_bookings"`
+	CreditsRefunded   int       `json:"credits_refunded"`
+	CreditsDeducted   int       `json:"credits_deducted"`
+}
+
+// BulkEditLessonPreview describes how a single target lesson would change
+type BulkEditLessonPreview struct {
+	LessonID        uuid.UUID     `json:"lesson_id"`
+	TeacherID       uuid.UUID     `json:"teacher_id"`
+	StartTime       time.Time     `json:"start_time"`
+	EndTime         time.Time     `json:"end_time"`
+	CurrentStudents int           `json:"current_students"`
+	MaxStudents     int           `json:"max_students"`
+	NewTeacherID    *uuid.UUID    `json:"new_teacher_id,omitempty"`
+	NewStartTime    *time.Time    `json:"new_start_time,omitempty"`
+	NewEndTime      *time.Time    `json:"new_end_time,omitempty"`
+	NewMaxStudents  *int          `json:"new_max_students,omitempty"`
+	StudentsAdded   []BookingInfo `json:"students_added,omitempty"`
+	StudentsRemoved []BookingInfo `json:"students_removed,omitempty"`
+	Unchanged       bool          `json:"unchanged,omitempty"` // e.g. student to remove is not booked
+	Issues          []string      `json:"issues,omitempty"`
+}
+
+// BulkEditCapacityViolation describes a lesson whose capacity would be violated
+type BulkEditCapacityViolation struct {
+	LessonID        uuid.UUID `json:"lesson_id"`
+	StartTime       time.Time `json:"start_time"`
+	CurrentStudents int       `json:"current_students"`
+	MaxStudents     int       `json:"max_students"`
+	Reason          string    `json:"reason"`
+}
+
+// BulkEditTeacherOverlap describes a target lesson that would overlap another lesson of the teacher
+type BulkEditTeacherOverlap struct {
+	LessonID             uuid.UUID `json:"lesson_id"`
+	TeacherID            uuid.UUID `json:"teacher_id"`
+	StartTime            time.Time `json:"start_time"`
+	EndTime              time.Time `json:"end_time"`
+	ConflictingLessonID  uuid.UUID `json:"conflicting_lesson_id"`
+	ConflictingStartTime time.Time `json:"conflicting_start_time"`
+	ConflictingEndTime   time.Time `json:"conflicting_end_time"`
+}
+
+// StudentCreditDelta describes how a student's credit balance would change
+type StudentCreditDelta struct {
+	StudentID      uuid.UUID `json:"student_id"`
+	StudentName    string    `json:"student_name"`
+	CurrentBalance int       `json:"current_balance"`
+	Delta          int       `json:"delta"` // negative = deducted, positive = refunded
+	BalanceAfter   int       `json:"balance_after"`
+	Insufficient   bool      `json:"insufficient,omitempty"`
+}
+
+// BulkEditPreview is the dry-run result of ApplyToAllSubsequent
+type BulkEditPreview struct {
+	SourceLessonID       uuid.UUID                   `json:"source_lesson_id"`
+	ModificationType     string                      `json:"modification_type"`
+	AffectedLessonsCount int                         `json:"affected_lessons_count"`
+	Lessons              []*BulkEditLessonPreview    `json:"lessons"`
+	CapacityViolations   []BulkEditCapacityViolation `json:"capacity_violations"`
+	TeacherOverlaps      []BulkEditTeacherOverlap    `json:"teacher_overlaps"`
+	CreditDeltas         []StudentCreditDelta        `json:"credit_deltas"`
+	ValidationErrors     []string                    `json:"validation_errors"`
+	CanApply             bool                        `json:"can_apply"`
+	PreviewToken         string                      `json:"preview_token"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLessonSnapshot_Diff(t *testing.T) {
+	teacherID := uuid.New()
+	studentA := uuid.New()
+	studentB := uuid.New()
+	start := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	base := LessonSnapshot{
+		TeacherID:   teacherID,
+		StartTime:   start,
+		EndTime:     start.Add(2 * time.Hour),
+		MaxStudents: 4,
+		StudentIDs:  []uuid.UUID{studentA, studentB},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(s *LessonSnapshot)
+		want   int
+	}{
+		{"identical", func(s *LessonSnapshot) {}, 0},
+		{"same students in different order", func(s *LessonSnapshot) { s.StudentIDs = []uuid.UUID{studentB, studentA} }, 0},
+		{"same instant in other timezone", func(s *LessonSnapshot) { s.StartTime = start.In(time.FixedZone("MSK", 3*3600)) }, 0},
+		{"teacher changed", func(s *LessonSnapshot) { s.TeacherID = uuid.New() }, 1},
+		{"time changed", func(s *LessonSnapshot) { s.EndTime = s.EndTime.Add(time.Hour) }, 1},
+		{"capacity changed", func(s *LessonSnapshot) { s.MaxStudents = 6 }, 1},
+		{"student removed", func(s *LessonSnapshot) { s.StudentIDs = []uuid.UUID{studentA} }, 1},
+		{"student replaced", func(s *LessonSnapshot) { s.StudentIDs = []uuid.UUID{studentA, uuid.New()} }, 1},
+		{"everything changed", func(s *LessonSnapshot) {
+			s.TeacherID = uuid.New()
+			s.StartTime = s.StartTime.Add(time.Hour)
+			s.MaxStudents = 1
+			s.StudentIDs = nil
+		}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base
+			other.StudentIDs = append([]uuid.UUID(nil), base.StudentIDs...)
+			tt.modify(&other)
+
+			if got := base.Diff(&other); len(got) != tt.want {
+				t.Errorf("Diff() = %v, want %d difference(s)", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLessonModification_IsReverted(t *testing.T) {
+	modification := &LessonModification{}
+	if modification.IsReverted() {
+		t.Error("new modification should not be reverted")
+	}
+
+	now := time.Now()
+	modification.RevertedAt = &now
+	if !modification.IsReverted() {
+		t.Error("modification with reverted_at should be reverted")
+	}
+}
+
+func TestApplyToAllSubsequentRequest_ComputePreviewToken(t *testing.T) {
+	studentID := uuid.New()
+	lessonA := uuid.New()
+	lessonB := uuid.New()
+	req := &ApplyToAllSubsequentRequest{
+		LessonID:         uuid.New(),
+		ModificationType: "add_student",
+		StudentID:        &studentID,
+	}
+
+	token := req.ComputePreviewToken([]uuid.UUID{lessonA, lessonB})
+	if token == "" {
+		t.Fatal("token should not be empty")
+	}
+
+	if got := req.ComputePreviewToken([]uuid.UUID{lessonB, lessonA}); got != token {
+		t.Error("token should not depend on lesson order")
+	}
+	if got := req.ComputePreviewToken([]uuid.UUID{lessonA}); got == token {
+		t.Error("token should change when the target set shrinks")
+	}
+	if got := req.ComputePreviewToken([]uuid.UUID{lessonA, lessonB, uuid.New()}); got == token {
+		t.Error("token should change when the target set grows")
+	}
+
+	otherStudent := uuid.New()
+	other := *req
+	other.StudentID = &otherStudent
+	if got := other.ComputePreviewToken([]uuid.UUID{lessonA, lessonB}); got == token {
+		t.Error("token should change when modification parameters change")
+	}
+
+	confirmed := *req
+	confirmed.DryRun = true
+	confirmed.PreviewToken = token
+	if got := confirmed.ComputePreviewToken([]uuid.UUID{lessonA, lessonB}); got != token {
+		t.Error("dry_run and preview_token must not affect the token")
+	}
+}
@@ -36,6 +36,7 @@ type BlockedMessage struct {
 	BlockedAt     time.Time              `db:"blocked_at" json:"blocked_at"`
 	AdminNotified bool                   `db:"admin_notified" json:"admin_notified"`
 	AdminReviewed bool                   `db:"admin_reviewed" json:"admin_reviewed"`
+	Rule          string                 `db:"rule" json:"rule"`
 }
 
 const (
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MessageEditWindow время после отправки, в течение которого отправитель может изменить сообщение
+	MessageEditWindow = 15 * time.Minute
+	// MessagePreviewLength длина цитаты исходного сообщения в ответе (в символах)
+	MessagePreviewLength = 200
+	// MaxRepliesPageSize максимальное число ответов на сообщение за один запрос
+	MaxRepliesPageSize = 100
+)
+
+// AllowedReactions набор эмодзи, доступных для реакций на сообщения
+var AllowedReactions = []string{"👍", "👎", "❤️", "😂", "😮", "😢", "🎉", "🔥", "✅", "❓"}
+
+// MessageEdit предыдущая версия текста отредактированного сообщения
+type MessageEdit struct {
+	ID           uuid.UUID     `db:"id" json:"id"`
+	MessageID    uuid.UUID     `db:"message_id" json:"message_id"`
+	PreviousText string        `db:"previous_text" json:"previous_text"`
+	EditedBy     uuid.NullUUID `db:"edited_by" json:"edited_by,omitempty"`
+	EditedAt     time.Time     `db:"edited_at" json:"edited_at"`
+}
+
+// MessagePreview цитата исходного сообщения в ответе. Если исходное сообщение удалено,
+// заблокировано или еще на модерации, Available = false и текст не раскрывается
+type MessagePreview struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	SenderID    uuid.UUID `db:"sender_id" json:"sender_id,omitempty"`
+	SenderName  string    `db:"sender_name" json:"sender_name,omitempty"`
+	MessageText string    `db:"message_text" json:"message_text,omitempty"`
+	Available   bool      `db:"-" json:"available"`
+}
+
+// UnavailableMessagePreview цитата недоступного исходного сообщения
+func UnavailableMessagePreview(id uuid.UUID) *MessagePreview {
+	return &MessagePreview{ID: id}
+}
+
+// Truncate обрезает текст цитаты до MessagePreviewLength символов
+func (p *MessagePreview) Truncate() {
+	runes := []rune(p.MessageText)
+	if len(runes) > MessagePreviewLength {
+		p.MessageText = string(runes[:MessagePreviewLength]) + "…"
+	}
+}
+
+// MessageReactionSummary реакции одним эмодзи на сообщение
+type MessageReactionSummary struct {
+	Emoji   string      `json:"emoji"`
+	Count   int         `json:"count"`
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+// MessageReaction реакция пользователя на сообщение
+type MessageReaction struct {
+	MessageID uuid.UUID `db:"message_id" json:"message_id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Emoji     string    `db:"emoji" json:"emoji"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// SummarizeReactions группирует реакции по эмодзи в порядке первой реакции каждым эмодзи
+func SummarizeReactions(reactions []MessageReaction) []MessageReactionSummary {
+	summaries := []MessageReactionSummary{}
+	index := make(map[string]int)
+	for _, r := range reactions {
+		i, ok := index[r.Emoji]
+		if !ok {
+			i = len(summaries)
+			index[r.Emoji] = i
+			summaries = append(summaries, MessageReactionSummary{Emoji: r.Emoji})
+		}
+		summaries[i].Count++
+		summaries[i].UserIDs = append(summaries[i].UserIDs, r.UserID)
+	}
+	return summaries
+}
+
+// EditMessageRequest запрос на редактирование сообщения
+type EditMessageRequest struct {
+	MessageText string `json:"message_text"`
+}
+
+// Validate валидирует EditMessageRequest
+func (r *EditMessageRequest) Validate() error {
+	r.MessageText = strings.TrimSpace(r.MessageText)
+	if r.MessageText == "" {
+		return ErrMessageContentEmpty
+	}
+	if len(r.MessageText) > 4096 {
+		return ErrMessageContentTooLong
+	}
+	return nil
+}
+
+// ReactionRequest запрос на добавление или снятие реакции
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// Validate проверяет, что эмодзи входит в AllowedReactions
+func (r *ReactionRequest) Validate() error {
+	r.Emoji = strings.TrimSpace(r.Emoji)
+	for _, allowed := range AllowedReactions {
+		if r.Emoji == allowed {
+			return nil
+		}
+	}
+	return ErrInvalidReaction
+}
+
+// CanEdit проверяет, может ли пользователь изменить сообщение на момент now:
+// только отправитель, только доставленное сообщение и только в течение MessageEditWindow
+func (m *Message) CanEdit(userID uuid.UUID, now time.Time) error {
+	if m.SenderID != userID {
+		return ErrMessageNotEditable
+	}
+	if !m.IsDelivered() {
+		return ErrMessageNotEditable
+	}
+	if now.Sub(m.CreatedAt) > MessageEditWindow {
+		return ErrMessageEditWindowExpired
+	}
+	return nil
+}
+
+// IsEdited проверяет, редактировалось ли сообщение
+func (m *Message) IsEdited() bool {
+	return m.EditedAt.Valid
+}
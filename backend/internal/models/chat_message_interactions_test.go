@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMessageCanEdit(t *testing.T) {
+	now := time.Now()
+	senderID := uuid.New()
+	message := &Message{SenderID: senderID, Status: MessageStatusDelivered, CreatedAt: now.Add(-5 * time.Minute)}
+
+	if err := message.CanEdit(senderID, now); err != nil {
+		t.Errorf("sender within window: CanEdit() error = %v", err)
+	}
+	if err := message.CanEdit(uuid.New(), now); !errors.Is(err, ErrMessageNotEditable) {
+		t.Errorf("other user: CanEdit() error = %v, want ErrMessageNotEditable", err)
+	}
+	if err := message.CanEdit(senderID, now.Add(MessageEditWindow)); !errors.Is(err, ErrMessageEditWindowExpired) {
+		t.Errorf("after window: CanEdit() error = %v, want ErrMessageEditWindowExpired", err)
+	}
+
+	pending := &Message{SenderID: senderID, Status: MessageStatusPendingModeration, CreatedAt: now}
+	if err := pending.CanEdit(senderID, now); !errors.Is(err, ErrMessageNotEditable) {
+		t.Errorf("pending message: CanEdit() error = %v, want ErrMessageNotEditable", err)
+	}
+}
+
+func TestSummarizeReactions(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	summaries := SummarizeReactions([]MessageReaction{
+		{UserID: alice, Emoji: "🔥"},
+		{UserID: bob, Emoji: "👍"},
+		{UserID: bob, Emoji: "🔥"},
+	})
+
+	if len(summaries) != 2 {
+		t.Fatalf("SummarizeReactions() = %+v, want 2 emoji", summaries)
+	}
+	if summaries[0].Emoji != "🔥" || summaries[0].Count != 2 || summaries[0].UserIDs[1] != bob {
+		t.Errorf("first summary = %+v, want 🔥 from alice and bob", summaries[0])
+	}
+	if summaries[1].Emoji != "👍" || summaries[1].Count != 1 {
+		t.Errorf("second summary = %+v, want single 👍", summaries[1])
+	}
+	if empty := SummarizeReactions(nil); empty == nil || len(empty) != 0 {
+		t.Errorf("SummarizeReactions(nil) = %v, want empty slice", empty)
+	}
+}
+
+func TestMessageInteractionRequestsValidate(t *testing.T) {
+	if err := (&ReactionRequest{Emoji: " 👍 "}).Validate(); err != nil {
+		t.Errorf("allowed emoji: error = %v", err)
+	}
+	for _, emoji := range []string{"", "🤡", "like"} {
+		if err := (&ReactionRequest{Emoji: emoji}).Validate(); !errors.Is(err, ErrInvalidReaction) {
+			t.Errorf("emoji %q: error = %v, want ErrInvalidReaction", emoji, err)
+		}
+	}
+
+	edit := &EditMessageRequest{MessageText: "  исправлено  "}
+	if err := edit.Validate(); err != nil || edit.MessageText != "исправлено" {
+		t.Errorf("EditMessageRequest.Validate() error = %v, text = %q", err, edit.MessageText)
+	}
+	if err := (&EditMessageRequest{MessageText: " "}).Validate(); !errors.Is(err, ErrMessageContentEmpty) {
+		t.Errorf("empty edit: error = %v, want ErrMessageContentEmpty", err)
+	}
+
+	nilID := uuid.Nil
+	send := &SendMessageRequest{RoomID: uuid.New(), MessageText: "ответ", ReplyToMessageID: &nilID}
+	if err := send.Validate(); !errors.Is(err, ErrInvalidReplyTarget) {
+		t.Errorf("reply to nil message: error = %v, want ErrInvalidReplyTarget", err)
+	}
+}
+
+func TestMessagePreviewTruncate(t *testing.T) {
+	preview := &MessagePreview{MessageText: strings.Repeat("я", MessagePreviewLength+10)}
+	preview.Truncate()
+	if got := []rune(preview.MessageText); len(got) != MessagePreviewLength+1 {
+		t.Errorf("Truncate() length = %d, want %d with ellipsis", len(got), MessagePreviewLength+1)
+	}
+
+	short := &MessagePreview{MessageText: "коротко"}
+	short.Truncate()
+	if short.MessageText != "коротко" {
+		t.Errorf("Truncate() changed short text to %q", short.MessageText)
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatReadState последнее прочитанное участником сообщение комнаты.
+// LastReadAt - время создания этого сообщения, ReadAt - когда участник его прочитал
+type ChatReadState struct {
+	RoomID            uuid.UUID     `db:"room_id" json:"room_id"`
+	UserID            uuid.UUID     `db:"user_id" json:"user_id"`
+	LastReadMessageID uuid.NullUUID `db:"last_read_message_id" json:"last_read_message_id,omitempty"`
+	LastReadAt        time.Time     `db:"last_read_at" json:"last_read_at"`
+	ReadAt            time.Time     `db:"updated_at" json:"read_at"`
+}
+
+// MarkReadRequest запрос на отметку комнаты прочитанной.
+// Без message_id комната читается до последнего доставленного сообщения
+type MarkReadRequest struct {
+	MessageID *uuid.UUID `json:"message_id,omitempty"`
+}
+
+// Validate валидирует MarkReadRequest
+func (r *MarkReadRequest) Validate() error {
+	if r.MessageID != nil && *r.MessageID == uuid.Nil {
+		return ErrInvalidMessageID
+	}
+	return nil
+}
+
+// ChatUnreadSummary сводка непрочитанных сообщений пользователя для бейджа
+type ChatUnreadSummary struct {
+	Total int `json:"total"`
+	Rooms int `json:"rooms"`
+}
+
+// NewChatUnreadSummary собирает сводку из счетчиков непрочитанных по комнатам
+func NewChatUnreadSummary(counts map[uuid.UUID]int) ChatUnreadSummary {
+	var summary ChatUnreadSummary
+	for _, count := range counts {
+		if count > 0 {
+			summary.Total += count
+			summary.Rooms++
+		}
+	}
+	return summary
+}
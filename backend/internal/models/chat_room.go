@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,15 +11,55 @@ import (
 
 // MessageStatus constants moved to chat_message.go to avoid duplication
 
-// ChatRoom представляет комнату для 1-на-1 общения между преподавателем и студентом
+// Типы комнат чата
+const (
+	// ChatRoomTypeDirect комната 1-на-1 между преподавателем и студентом
+	ChatRoomTypeDirect = "direct"
+	// ChatRoomTypeLessonGroup комната серии групповых занятий, участники синхронизируются с записями
+	ChatRoomTypeLessonGroup = "lesson_group"
+	// ChatRoomTypeChannel канал объявлений: пишут только владельцы и модераторы
+	ChatRoomTypeChannel = "channel"
+)
+
+// Роли участников групповых комнат
+const (
+	ChatRoleOwner     = "owner"
+	ChatRoleModerator = "moderator"
+	ChatRoleMember    = "member"
+)
+
+const (
+	// MaxChatRoomTitleLength максимальная длина названия групповой комнаты
+	MaxChatRoomTitleLength = 200
+	// MaxChatRoomDescriptionLength максимальная длина описания групповой комнаты
+	MaxChatRoomDescriptionLength = 2000
+	// MaxChatParticipantsPerRequest максимальное число участников, добавляемых одним запросом
+	MaxChatParticipantsPerRequest = 500
+	// MaxChatMuteMinutes максимальная длительность мьюта (30 дней)
+	MaxChatMuteMinutes = 30 * 24 * 60
+)
+
+// ChatRoom представляет комнату чата: 1-на-1 между преподавателем и студентом (direct)
+// или групповую комнату (lesson_group, channel) с участниками из chat_room_participants
 type ChatRoom struct {
-	ID            uuid.UUID    `db:"id" json:"id"`
-	TeacherID     uuid.UUID    `db:"teacher_id" json:"teacher_id"`
-	StudentID     uuid.UUID    `db:"student_id" json:"student_id"`
-	LastMessageAt sql.NullTime `db:"last_message_at" json:"last_message_at,omitempty"`
-	CreatedAt     time.Time    `db:"created_at" json:"created_at"`
-	UpdatedAt     time.Time    `db:"updated_at" json:"updated_at"`
-	DeletedAt     sql.NullTime `db:"deleted_at" json:"deleted_at,omitempty"`
+	ID               uuid.UUID     `db:"id" json:"id"`
+	Type             string        `db:"room_type" json:"room_type"`
+	TeacherID        uuid.UUID     `db:"teacher_id" json:"teacher_id"`
+	StudentID        uuid.UUID     `db:"student_id" json:"student_id"`
+	Title            string        `db:"title" json:"title,omitempty"`
+	Description      string        `db:"description" json:"description,omitempty"`
+	RecurringGroupID uuid.NullUUID `db:"recurring_group_id" json:"recurring_group_id,omitempty"`
+	StudentGroupID   uuid.NullUUID `db:"student_group_id" json:"student_group_id,omitempty"`
+	LastMessageAt    sql.NullTime  `db:"last_message_at" json:"last_message_at,omitempty"`
+	CreatedAt        time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time     `db:"updated_at" json:"updated_at"`
+	DeletedAt        sql.NullTime  `db:"deleted_at" json:"deleted_at,omitempty"`
+
+	// Участники групповой комнаты (для direct не загружаются)
+	Participants []ChatRoomParticipant `db:"-" json:"participants,omitempty"`
+
+	// Число непрочитанных текущим пользователем сообщений (заполняется в списке комнат)
+	UnreadCount int `db:"-" json:"unread_count"`
 
 	// Информация о другом участнике (teacher для student, student для teacher)
 	ParticipantID   uuid.UUID `db:"participant_id" json:"participant_id,omitempty"`
@@ -26,6 +67,28 @@ type ChatRoom struct {
 	ParticipantRole string    `db:"participant_role" json:"participant_role,omitempty"`
 }
 
+// ChatRoomParticipant участник групповой комнаты
+type ChatRoomParticipant struct {
+	RoomID        uuid.UUID    `db:"room_id" json:"room_id"`
+	UserID        uuid.UUID    `db:"user_id" json:"user_id"`
+	Name          string       `db:"name" json:"name"`
+	UserRole      string       `db:"user_role" json:"user_role"`
+	Role          string       `db:"role" json:"role"`
+	MutedUntil    sql.NullTime `db:"muted_until" json:"muted_until,omitempty"`
+	AddedManually bool         `db:"added_manually" json:"added_manually"`
+	JoinedAt      time.Time    `db:"joined_at" json:"joined_at"`
+}
+
+// IsStaff проверяет, что участник - владелец или модератор комнаты
+func (p *ChatRoomParticipant) IsStaff() bool {
+	return p.Role == ChatRoleOwner || p.Role == ChatRoleModerator
+}
+
+// IsMuted проверяет, действует ли мьют участника на момент now
+func (p *ChatRoomParticipant) IsMuted(now time.Time) bool {
+	return p.MutedUntil.Valid && p.MutedUntil.Time.After(now)
+}
+
 // Message представляет сообщение в чате с модерацией и статусами.
 // Это единственный исходный тип для всех операций с сообщениями.
 // Status может быть: pending_moderation, delivered или blocked
@@ -39,7 +102,13 @@ type Message struct {
 	ModerationCompletedAt sql.NullTime     `db:"moderation_completed_at" json:"moderation_completed_at,omitempty"`
 	CreatedAt             time.Time        `db:"created_at" json:"created_at"`
 	DeletedAt             sql.NullTime     `db:"deleted_at" json:"deleted_at,omitempty"`
+	EditedAt              sql.NullTime     `db:"edited_at" json:"edited_at,omitempty"`
+	ReplyToMessageID      uuid.NullUUID    `db:"reply_to_message_id" json:"reply_to_message_id,omitempty"`
 	Attachments           []FileAttachment `db:"-" json:"attachments,omitempty"` // Загружаются отдельно
+
+	// Цитата исходного сообщения и реакции загружаются отдельно
+	ReplyTo   *MessagePreview          `db:"-" json:"reply_to,omitempty"`
+	Reactions []MessageReactionSummary `db:"-" json:"reactions,omitempty"`
 }
 
 // FileAttachment представляет файл, прикрепленный к сообщению
@@ -51,6 +120,21 @@ type FileAttachment struct {
 	FileSize   int64     `db:"file_size" json:"file_size"`
 	MimeType   string    `db:"mime_type" json:"mime_type"`
 	UploadedAt time.Time `db:"uploaded_at" json:"uploaded_at"`
+
+	// Ключ в BlobStore и SHA-256 содержимого; NULL - файл загружен до перехода на BlobStore и лежит по FilePath
+	StorageKey  sql.NullString `db:"storage_key" json:"-"`
+	ContentHash sql.NullString `db:"content_hash" json:"-"`
+	// Ключ превью в BlobStore; NULL - превью еще не построено или для типа файла не строится
+	ThumbnailKey sql.NullString `db:"thumbnail_key" json:"-"`
+}
+
+// MarshalJSON добавляет признак has_thumbnail: превью доступно по /files/{id}/thumbnail
+func (a FileAttachment) MarshalJSON() ([]byte, error) {
+	type attachmentAlias FileAttachment
+	return json.Marshal(struct {
+		attachmentAlias
+		HasThumbnail bool `json:"has_thumbnail"`
+	}{attachmentAlias(a), a.ThumbnailKey.Valid})
 }
 
 // BlockedMessage type moved to chat_message.go to avoid duplication
@@ -64,8 +148,9 @@ type GetOrCreateRoomRequest struct {
 
 // SendMessageRequest представляет запрос на отправку сообщения
 type SendMessageRequest struct {
-	RoomID      uuid.UUID `json:"room_id"`
-	MessageText string    `json:"message_text"`
+	RoomID           uuid.UUID  `json:"room_id"`
+	MessageText      string     `json:"message_text"`
+	ReplyToMessageID *uuid.UUID `json:"reply_to_message_id,omitempty"`
 }
 
 // GetMessagesRequest представляет запрос на получение сообщений
@@ -77,6 +162,29 @@ type GetMessagesRequest struct {
 
 // ==================== Validation Methods ====================
 
+// CreateChannelRequest запрос на создание канала объявлений
+type CreateChannelRequest struct {
+	Title          string      `json:"title"`
+	Description    string      `json:"description"`
+	MemberIDs      []uuid.UUID `json:"member_ids"`
+	StudentGroupID *uuid.UUID  `json:"student_group_id,omitempty"`
+}
+
+// ChatParticipantsRequest запрос на добавление участников в групповую комнату
+type ChatParticipantsRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+// ChatMuteRequest запрос на мьют участника групповой комнаты
+type ChatMuteRequest struct {
+	Minutes int `json:"minutes"`
+}
+
+// ChatParticipantRoleRequest запрос на смену роли участника групповой комнаты
+type ChatParticipantRoleRequest struct {
+	Role string `json:"role"`
+}
+
 // Validate валидирует GetOrCreateRoomRequest
 func (r *GetOrCreateRoomRequest) Validate() error {
 	if r.OtherUserID == uuid.Nil {
@@ -100,6 +208,10 @@ func (r *SendMessageRequest) Validate() error {
 		return ErrMessageContentTooLong
 	}
 
+	if r.ReplyToMessageID != nil && *r.ReplyToMessageID == uuid.Nil {
+		return ErrInvalidReplyTarget
+	}
+
 	return nil
 }
 
@@ -127,13 +239,113 @@ func (r *GetMessagesRequest) Validate() error {
 	return nil
 }
 
+// Validate валидирует CreateChannelRequest
+func (r *CreateChannelRequest) Validate() error {
+	r.Title = strings.TrimSpace(r.Title)
+	r.Description = strings.TrimSpace(r.Description)
+	if r.Title == "" || len([]rune(r.Title)) > MaxChatRoomTitleLength ||
+		len([]rune(r.Description)) > MaxChatRoomDescriptionLength {
+		return ErrInvalidChatChannel
+	}
+	if len(r.MemberIDs) > MaxChatParticipantsPerRequest || containsNilUUID(r.MemberIDs) {
+		return ErrInvalidChatParticipants
+	}
+	if r.StudentGroupID != nil && *r.StudentGroupID == uuid.Nil {
+		return ErrInvalidChatChannel
+	}
+	return nil
+}
+
+// Validate валидирует ChatParticipantsRequest
+func (r *ChatParticipantsRequest) Validate() error {
+	if len(r.UserIDs) == 0 || len(r.UserIDs) > MaxChatParticipantsPerRequest || containsNilUUID(r.UserIDs) {
+		return ErrInvalidChatParticipants
+	}
+	return nil
+}
+
+// Validate валидирует ChatMuteRequest
+func (r *ChatMuteRequest) Validate() error {
+	if r.Minutes <= 0 || r.Minutes > MaxChatMuteMinutes {
+		return ErrInvalidChatMute
+	}
+	return nil
+}
+
+// Validate валидирует ChatParticipantRoleRequest. Роль владельца не назначается
+func (r *ChatParticipantRoleRequest) Validate() error {
+	if r.Role != ChatRoleModerator && r.Role != ChatRoleMember {
+		return ErrInvalidChatParticipantRole
+	}
+	return nil
+}
+
+// containsNilUUID проверяет наличие пустого UUID в списке
+func containsNilUUID(ids []uuid.UUID) bool {
+	for _, id := range ids {
+		if id == uuid.Nil {
+			return true
+		}
+	}
+	return false
+}
+
 // ==================== Helper Methods ====================
 
+// IsGroup проверяет, что комната групповая (участники хранятся в chat_room_participants)
+func (r *ChatRoom) IsGroup() bool {
+	return r.Type == ChatRoomTypeLessonGroup || r.Type == ChatRoomTypeChannel
+}
+
 // IsParticipant проверяет, является ли пользователь участником комнаты
 func (r *ChatRoom) IsParticipant(userID uuid.UUID) bool {
+	if r.IsGroup() {
+		return r.GetParticipant(userID) != nil
+	}
 	return r.TeacherID == userID || r.StudentID == userID
 }
 
+// GetParticipant возвращает участника групповой комнаты или nil
+func (r *ChatRoom) GetParticipant(userID uuid.UUID) *ChatRoomParticipant {
+	for i := range r.Participants {
+		if r.Participants[i].UserID == userID {
+			return &r.Participants[i]
+		}
+	}
+	return nil
+}
+
+// ParticipantIDs возвращает ID всех участников комнаты
+func (r *ChatRoom) ParticipantIDs() []uuid.UUID {
+	if !r.IsGroup() {
+		return []uuid.UUID{r.TeacherID, r.StudentID}
+	}
+	ids := make([]uuid.UUID, 0, len(r.Participants))
+	for _, p := range r.Participants {
+		ids = append(ids, p.UserID)
+	}
+	return ids
+}
+
+// CanPost проверяет, может ли участник писать в комнату: заглушенные участники не пишут,
+// в канал пишут только владельцы и модераторы
+func (r *ChatRoom) CanPost(userID uuid.UUID, now time.Time) error {
+	if !r.IsGroup() {
+		return nil
+	}
+	p := r.GetParticipant(userID)
+	if p == nil {
+		return nil
+	}
+	if p.IsMuted(now) {
+		return ErrChatParticipantMuted
+	}
+	if r.Type == ChatRoomTypeChannel && !p.IsStaff() {
+		return ErrChatChannelReadOnly
+	}
+	return nil
+}
+
 // GetOtherParticipant возвращает ID другого участника комнаты
 func (r *ChatRoom) GetOtherParticipant(userID uuid.UUID) uuid.UUID {
 	if r.TeacherID == userID {
@@ -214,5 +426,19 @@ func (m *Message) MarshalJSON() ([]byte, error) {
 		data["attachments"] = m.Attachments
 	}
 
+	// Handle edit, reply and reactions
+	if m.EditedAt.Valid {
+		data["edited_at"] = m.EditedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if m.ReplyToMessageID.Valid {
+		data["reply_to_message_id"] = m.ReplyToMessageID.UUID
+	}
+	if m.ReplyTo != nil {
+		data["reply_to"] = m.ReplyTo
+	}
+	if len(m.Reactions) > 0 {
+		data["reactions"] = m.Reactions
+	}
+
 	return json.Marshal(data)
 }
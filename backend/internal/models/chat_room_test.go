@@ -0,0 +1,126 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestChatRoomDirectParticipants(t *testing.T) {
+	teacherID, studentID := uuid.New(), uuid.New()
+	// Комнаты, загруженные до появления room_type, тоже считаются direct
+	for _, roomType := range []string{ChatRoomTypeDirect, ""} {
+		room := &ChatRoom{Type: roomType, TeacherID: teacherID, StudentID: studentID}
+		if room.IsGroup() {
+			t.Errorf("type %q: IsGroup() = true", roomType)
+		}
+		if !room.IsParticipant(teacherID) || !room.IsParticipant(studentID) || room.IsParticipant(uuid.New()) {
+			t.Errorf("type %q: IsParticipant mismatch", roomType)
+		}
+		if ids := room.ParticipantIDs(); len(ids) != 2 || ids[0] != teacherID || ids[1] != studentID {
+			t.Errorf("type %q: ParticipantIDs() = %v", roomType, ids)
+		}
+		if err := room.CanPost(studentID, time.Now()); err != nil {
+			t.Errorf("type %q: CanPost() error = %v", roomType, err)
+		}
+	}
+}
+
+func TestChatRoomGroupCanPost(t *testing.T) {
+	now := time.Now()
+	ownerID, moderatorID, memberID, mutedID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	participants := []ChatRoomParticipant{
+		{UserID: ownerID, Role: ChatRoleOwner},
+		{UserID: moderatorID, Role: ChatRoleModerator},
+		{UserID: memberID, Role: ChatRoleMember, MutedUntil: sql.NullTime{Time: now.Add(-time.Minute), Valid: true}},
+		{UserID: mutedID, Role: ChatRoleMember, MutedUntil: sql.NullTime{Time: now.Add(time.Hour), Valid: true}},
+	}
+
+	group := &ChatRoom{Type: ChatRoomTypeLessonGroup, Participants: participants}
+	if !group.IsParticipant(memberID) || group.IsParticipant(uuid.New()) {
+		t.Error("lesson group: IsParticipant mismatch")
+	}
+	if len(group.ParticipantIDs()) != len(participants) {
+		t.Errorf("ParticipantIDs() = %v", group.ParticipantIDs())
+	}
+	if err := group.CanPost(memberID, now); err != nil {
+		t.Errorf("member with expired mute: CanPost() error = %v", err)
+	}
+	if err := group.CanPost(mutedID, now); !errors.Is(err, ErrChatParticipantMuted) {
+		t.Errorf("muted member: CanPost() error = %v, want ErrChatParticipantMuted", err)
+	}
+
+	channel := &ChatRoom{Type: ChatRoomTypeChannel, Participants: participants}
+	for _, id := range []uuid.UUID{ownerID, moderatorID} {
+		if err := channel.CanPost(id, now); err != nil {
+			t.Errorf("channel staff: CanPost() error = %v", err)
+		}
+	}
+	if err := channel.CanPost(memberID, now); !errors.Is(err, ErrChatChannelReadOnly) {
+		t.Errorf("channel member: CanPost() error = %v, want ErrChatChannelReadOnly", err)
+	}
+}
+
+func TestChatGroupRequestsValidate(t *testing.T) {
+	channel := &CreateChannelRequest{Title: "  Объявления  ", MemberIDs: []uuid.UUID{uuid.New()}}
+	if err := channel.Validate(); err != nil {
+		t.Fatalf("CreateChannelRequest.Validate() error = %v", err)
+	}
+	if channel.Title != "Объявления" {
+		t.Errorf("Title = %q, want trimmed", channel.Title)
+	}
+
+	nilGroup := uuid.Nil
+	invalidChannels := []*CreateChannelRequest{
+		{Title: " "},
+		{Title: strings.Repeat("я", MaxChatRoomTitleLength+1)},
+		{Title: "ok", StudentGroupID: &nilGroup},
+	}
+	for _, req := range invalidChannels {
+		if err := req.Validate(); !errors.Is(err, ErrInvalidChatChannel) {
+			t.Errorf("CreateChannelRequest.Validate(%q) error = %v, want ErrInvalidChatChannel", req.Title, err)
+		}
+	}
+	if err := (&CreateChannelRequest{Title: "ok", MemberIDs: []uuid.UUID{uuid.Nil}}).Validate(); !errors.Is(err, ErrInvalidChatParticipants) {
+		t.Errorf("nil member: error = %v, want ErrInvalidChatParticipants", err)
+	}
+
+	if err := (&ChatParticipantsRequest{}).Validate(); !errors.Is(err, ErrInvalidChatParticipants) {
+		t.Errorf("empty participants: error = %v, want ErrInvalidChatParticipants", err)
+	}
+
+	for _, minutes := range []int{0, -5, MaxChatMuteMinutes + 1} {
+		if err := (&ChatMuteRequest{Minutes: minutes}).Validate(); !errors.Is(err, ErrInvalidChatMute) {
+			t.Errorf("mute %d minutes: error = %v, want ErrInvalidChatMute", minutes, err)
+		}
+	}
+
+	if err := (&ChatParticipantRoleRequest{Role: ChatRoleModerator}).Validate(); err != nil {
+		t.Errorf("moderator role: error = %v", err)
+	}
+	if err := (&ChatParticipantRoleRequest{Role: ChatRoleOwner}).Validate(); !errors.Is(err, ErrInvalidChatParticipantRole) {
+		t.Errorf("owner role: error = %v, want ErrInvalidChatParticipantRole", err)
+	}
+}
+
+func TestNewChatUnreadSummary(t *testing.T) {
+	summary := NewChatUnreadSummary(map[uuid.UUID]int{uuid.New(): 3, uuid.New(): 2, uuid.New(): 0})
+	if summary.Total != 5 || summary.Rooms != 2 {
+		t.Errorf("NewChatUnreadSummary() = %+v, want total 5 in 2 rooms", summary)
+	}
+	if empty := NewChatUnreadSummary(nil); empty.Total != 0 || empty.Rooms != 0 {
+		t.Errorf("NewChatUnreadSummary(nil) = %+v, want zero", empty)
+	}
+
+	nilID := uuid.Nil
+	if err := (&MarkReadRequest{MessageID: &nilID}).Validate(); !errors.Is(err, ErrInvalidMessageID) {
+		t.Errorf("MarkReadRequest with nil message_id: error = %v, want ErrInvalidMessageID", err)
+	}
+	if err := (&MarkReadRequest{}).Validate(); err != nil {
+		t.Errorf("MarkReadRequest without message_id: error = %v", err)
+	}
+}
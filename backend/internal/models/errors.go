@@ -45,6 +45,17 @@ var (
 	ErrInvalidTelegram    = errors.New("telegram должен быть от 3 до 50 символов")
 	ErrInvalidEmailFormat = errors.New("некорректный формат email")
 
+	// Ошибки CRM воронки заявок
+	ErrInvalidTrialRequestStatus     = errors.New("некорректный статус заявки (допустимы: new, contacted, scheduled, converted, lost)")
+	ErrInvalidTrialRequestTransition = errors.New("недопустимый переход статуса заявки")
+	ErrTrialRequestConvertViaAction  = errors.New("статус converted устанавливается только конвертацией заявки")
+	ErrTrialRequestAlreadyConverted  = errors.New("заявка уже конвертирована")
+	ErrTrialRequestClosed            = errors.New("заявка закрыта: верните её в работу, чтобы продолжить")
+	ErrInvalidTrialRequestLostReason = errors.New("причина потери указывается только для статуса lost и не должна превышать 500 символов")
+	ErrInvalidTrialRequestNote       = errors.New("заметка должна быть от 1 до 2000 символов")
+	ErrTrialRequestFollowUpInPast    = errors.New("срок следующего контакта должен быть в будущем")
+	ErrInvalidTrialRequestAssignee   = errors.New("ответственным за заявку может быть только администратор")
+
 	// Ошибки Telegram
 	ErrInvalidToken            = errors.New("некорректный токен привязки")
 	ErrInvalidTelegramID       = errors.New("некорректный Telegram ID")
@@ -70,10 +81,27 @@ var (
 	ErrMessageLimitTooHigh   = errors.New("максимальный лимит 100 сообщений за раз")
 	ErrInvalidMessageOffset  = errors.New("смещение сообщений не может быть отрицательным")
 
+	// Ошибки редактирования, ответов и реакций
+	ErrMessageNotEditable       = errors.New("изменить можно только свое доставленное сообщение")
+	ErrMessageEditWindowExpired = errors.New("время редактирования сообщения истекло (15 минут после отправки)")
+	ErrInvalidReplyTarget       = errors.New("ответить можно только на доставленное сообщение этой комнаты")
+	ErrInvalidReaction          = errors.New("реакция не поддерживается")
+
 	// Ошибки чатов
 	ErrCannotChatWithSelf = errors.New("невозможно создать чат с самим собой")
 	ErrInvalidChatRoomID  = errors.New("некорректный ID комнаты чата")
 
+	// Ошибки групповых комнат чата
+	ErrInvalidChatChannel         = errors.New("название канала должно быть от 1 до 200 символов, описание - не длиннее 2000 символов")
+	ErrInvalidChatParticipants    = errors.New("укажите от 1 до 500 участников")
+	ErrInvalidChatMute            = errors.New("длительность мьюта должна быть от 1 минуты до 30 дней")
+	ErrInvalidChatParticipantRole = errors.New("роль участника должна быть moderator или member")
+	ErrChatParticipantMuted       = errors.New("вы не можете писать в эту комнату до окончания мьюта")
+	ErrChatChannelReadOnly        = errors.New("в канал могут писать только владельцы и модераторы")
+	ErrChatNotGroupRoom           = errors.New("действие доступно только для групповых комнат")
+	ErrChatOwnerLocked            = errors.New("владельца комнаты нельзя удалить, заглушить или сменить ему роль")
+	ErrChatNotRoomStaff           = errors.New("действие доступно только владельцам и модераторам комнаты")
+
 	// Ошибки статуса сообщений
 	ErrInvalidMessageStatus = errors.New("некорректный статус сообщения (разрешены: delivered, blocked)")
 
@@ -101,4 +129,148 @@ var (
 	ErrMimeTypeNotAllowed     = errors.New("данный тип файла не разрешен для загрузки")
 	ErrFileStorageFailed      = errors.New("не удалось сохранить файл на сервере")
 	ErrHomeworkContentTooLong = errors.New("описание домашнего задания не должно превышать 10000 символов")
+
+	// Ошибки проверки загружаемых файлов
+	ErrFileTypeMismatch        = errors.New("содержимое файла не соответствует его типу или расширению")
+	ErrFileInfected            = errors.New("файл не прошел антивирусную проверку")
+	ErrFileScanFailed          = errors.New("антивирусная проверка файла временно недоступна")
+	ErrImageTooLarge           = errors.New("разрешение изображения слишком велико")
+	ErrInvalidQuarantineFilter = errors.New("некорректные параметры списка карантина")
+
+	// Ошибки квот хранилища файлов
+	ErrStorageQuotaExceeded      = errors.New("превышена квота хранилища файлов")
+	ErrInvalidStorageUsageFilter = errors.New("некорректные параметры отчета об использовании хранилища")
+	ErrInvalidStorageQuota       = errors.New("квота должна быть неотрицательным числом байт")
+
+	// Ошибки академического календаря
+	ErrInvalidCalendarEntryType = errors.New("некорректный тип записи календаря (разрешены: term, holiday, break)")
+	ErrInvalidCalendarEntryName = errors.New("название записи календаря должно быть от 1 до 200 символов")
+	ErrInvalidCalendarDates     = errors.New("дата окончания не может быть раньше даты начала")
+	ErrInvalidHolidayPolicy     = errors.New("некорректная политика для праздничных дней (разрешены: skip, shift, ignore)")
+	ErrInvalidICSFile           = errors.New("некорректный ICS файл")
+	ErrLessonAlreadyStarted     = errors.New("нельзя отменить уже начавшееся или прошедшее занятие")
+	ErrLessonNotOnBlockedDate   = errors.New("занятие не попадает на праздник или каникулы")
+
+	// Ошибки отката массовых изменений
+	ErrModificationAlreadyReverted = errors.New("изменение уже отменено")
+	ErrModificationNotRevertible   = errors.New("изменение не содержит снимков состояния и не может быть отменено")
+	ErrModificationRevertConflict  = errors.New("занятия были изменены после применения изменения, откат невозможен")
+	ErrPreviewTokenMismatch        = errors.New("набор занятий изменился после предпросмотра, выполните предпросмотр повторно")
+
+	// Ошибки учебных групп
+	ErrInvalidGroupName         = errors.New("название группы должно быть от 1 до 200 символов")
+	ErrGroupDescriptionTooLong  = errors.New("описание группы не должно превышать 1000 символов")
+	ErrInvalidRecurringGroupID  = errors.New("некорректный ID серии занятий")
+	ErrAlreadyGroupMember       = errors.New("студент уже состоит в группе")
+	ErrNotGroupMember           = errors.New("студент не состоит в группе")
+	ErrSeriesAlreadyLinked      = errors.New("серия уже привязана к группе")
+	ErrSeriesNotLinked          = errors.New("серия не привязана к группе")
+	ErrSeriesHasNoFutureLessons = errors.New("в серии нет будущих занятий")
+	ErrGroupHasMembers          = errors.New("нельзя удалить группу с активными участниками: сначала исключите студентов")
+
+	// Ошибки подписок
+	ErrInvalidPlanName              = errors.New("название тарифа должно быть от 1 до 200 символов")
+	ErrPlanDescriptionTooLong       = errors.New("описание тарифа не должно превышать 1000 символов")
+	ErrInvalidPlanCredits           = errors.New("количество кредитов за период должно быть от 1 до 100")
+	ErrInvalidPlanPrice             = errors.New("стоимость тарифа должна быть больше нуля")
+	ErrInvalidPlanPeriod            = errors.New("период тарифа должен быть от 1 до 12 месяцев")
+	ErrInvalidPlanID                = errors.New("некорректный ID тарифа")
+	ErrPlanNotActive                = errors.New("тариф недоступен для оформления")
+	ErrSubscriptionAlreadyExists    = errors.New("у пользователя уже есть действующая подписка")
+	ErrSubscriptionNotActive        = errors.New("подписка не активна")
+	ErrSubscriptionSamePlan         = errors.New("подписка уже оформлена на этот тариф")
+	ErrSubscriptionNoPaymentMethod  = errors.New("для подписки не сохранён способ оплаты")
+	ErrSubscriptionPaymentInProcess = errors.New("по подписке уже есть неоплаченный счёт")
+
+	// Ошибки шаблонов, аудиторий и планирования рассылок
+	ErrInvalidBroadcastTemplateName = errors.New("название шаблона рассылки должно быть от 1 до 200 символов")
+	ErrInvalidBroadcastAudienceName = errors.New("название аудитории должно быть от 1 до 200 символов")
+	ErrUnknownBroadcastPlaceholder  = errors.New("сообщение содержит неизвестную подстановку (разрешены: {first_name}, {last_name}, {balance}, {next_lesson})")
+	ErrInvalidAudienceRole          = errors.New("некорректная роль в фильтре аудитории")
+	ErrInvalidAudienceMaxBalance    = errors.New("порог баланса в фильтре аудитории не может быть отрицательным")
+	ErrInvalidAudienceInactiveDays  = errors.New("период неактивности в фильтре аудитории должен быть от 1 до 365 дней")
+	ErrBroadcastAudienceConflict    = errors.New("audience_id нельзя указывать вместе с list_id или user_ids")
+	ErrBroadcastScheduledInPast     = errors.New("время отложенной рассылки должно быть в будущем")
+	ErrBroadcastMessageOrTemplate   = errors.New("укажите либо текст сообщения, либо template_id")
+
+	// Ошибки аналитики
+	ErrInvalidAnalyticsGranularity = errors.New("шаг агрегации должен быть day, week или month")
+	ErrInvalidAnalyticsPeriod      = errors.New("начало периода должно быть раньше окончания")
+	ErrAnalyticsPeriodTooLong      = errors.New("период аналитики не должен превышать двух лет")
+
+	// Ошибки форматирования, кнопок и вложений рассылок
+	ErrInvalidBroadcastParseMode = errors.New("parse_mode должен быть пустым, HTML или MarkdownV2")
+	ErrInvalidBroadcastHTML      = errors.New("сообщение содержит недопустимые или незакрытые HTML-теги")
+	ErrInvalidBroadcastButtons   = errors.New("некорректные кнопки рассылки (не более 8 рядов по 8 кнопок, текст до 64 символов)")
+	ErrInvalidBroadcastButton    = errors.New("у кнопки рассылки должна быть ровно одна ссылка (http, https, tg) или callback_data до 64 байт")
+	ErrInvalidBroadcastFileType  = errors.New("недопустимый тип вложения рассылки")
+
+	// Ошибки расчета оплаты преподавателей
+	ErrInvalidPayRateType         = errors.New("тип ставки должен быть per_lesson, per_student_hour или credit_percentage")
+	ErrInvalidPayRateAmount       = errors.New("ставка должна быть положительной (процент не более 100)")
+	ErrInvalidPayRateCreditValue  = errors.New("стоимость кредита должна быть положительной")
+	ErrInvalidPayrollDates        = errors.New("некорректные даты, ожидается формат YYYY-MM-DD и начало не позже окончания")
+	ErrPayrollPeriodTooLong       = errors.New("расчетный период не должен превышать 92 дней")
+	ErrPayrollPeriodNotFinished   = errors.New("нельзя закрыть период, который еще не закончился")
+	ErrPayrollPeriodClosed        = errors.New("расчетный период на эту дату уже закрыт")
+	ErrInvalidAdjustmentAmount    = errors.New("сумма корректировки не может быть нулевой")
+	ErrInvalidAdjustmentReason    = errors.New("причина корректировки должна быть от 1 до 500 символов")
+	ErrInvalidPayrollExportFormat = errors.New("формат выгрузки должен быть csv или xlsx")
+
+	// Ошибки массового импорта
+	ErrInvalidImportKind        = errors.New("тип импорта должен быть users, lessons или bookings")
+	ErrImportUnsupportedFormat  = errors.New("файл импорта должен быть в формате CSV или XLSX")
+	ErrImportFileTooLarge       = errors.New("файл импорта не должен превышать 10 МБ")
+	ErrImportEmptyFile          = errors.New("файл импорта не содержит строк данных")
+	ErrImportMissingColumns     = errors.New("в файле импорта отсутствуют обязательные колонки")
+	ErrImportTooManyRows        = errors.New("файл импорта не должен содержать более 5000 строк")
+	ErrImportHasErrors          = errors.New("файл импорта содержит ошибки, изменения не применены")
+	ErrInvalidImportTimezone    = errors.New("некорректный часовой пояс импорта")
+	ErrInvalidImportExternalID  = errors.New("external_id не должен превышать 255 символов")
+	ErrInvalidImportLessonType  = errors.New("lesson_type должен быть individual или group")
+	ErrImportInvalidTime        = errors.New("некорректное время, ожидается YYYY-MM-DD HH:MM или RFC3339")
+	ErrImportInvalidNumber      = errors.New("ожидается целое число")
+	ErrImportLessonRequired     = errors.New("укажите lesson_id или lesson_external_id")
+	ErrImportDuplicateRow       = errors.New("строка повторяет одну из предыдущих строк файла")
+	ErrImportUserNotFound       = errors.New("пользователь с таким email не найден")
+	ErrImportLessonNotFound     = errors.New("занятие не найдено")
+	ErrImportTeacherOverlap     = errors.New("занятие пересекается с другим занятием преподавателя")
+	ErrImportNotStudent         = errors.New("записать на занятие можно только студента")
+	ErrImportExternalIDConflict = errors.New("external_id уже используется другой сущностью")
+
+	// Ошибки выгрузки и удаления персональных данных
+	ErrInvalidErasureComment    = errors.New("комментарий не должен превышать 1000 символов, при отказе комментарий обязателен")
+	ErrInvalidErasureStatus     = errors.New("статус должен быть pending, rejected или completed")
+	ErrErasureRequestExists     = errors.New("запрос на удаление аккаунта уже ожидает рассмотрения")
+	ErrErasureRequestNotPending = errors.New("запрос на удаление аккаунта уже рассмотрен")
+	ErrErasureAdminAccount      = errors.New("аккаунт администратора нельзя удалить через запрос на удаление данных")
+	ErrAccountAlreadyErased     = errors.New("персональные данные аккаунта уже удалены")
+
+	// Ошибки журнала аудита
+	ErrInvalidAuditFilter = errors.New("некорректный фильтр журнала аудита: проверьте даты, limit (1-500) и offset")
+
+	// Ошибки имперсонации
+	ErrInvalidImpersonationRequest = errors.New("длительность имперсонации должна быть от 1 до 60 минут, причина - не длиннее 500 символов")
+	ErrImpersonateAdmin            = errors.New("нельзя войти от имени другого администратора")
+	ErrImpersonateHigherRole       = errors.New("нельзя войти от имени пользователя, роль которого дает права, которых нет у вас")
+	ErrImpersonateSelf             = errors.New("нельзя войти от имени самого себя")
+	ErrImpersonationNested         = errors.New("нельзя начать имперсонацию из сессии имперсонации")
+	ErrNotImpersonating            = errors.New("текущая сессия не является сессией имперсонации")
+
+	// Ошибки ролей и прав
+	ErrInvalidRoleRequest = errors.New("имя роли должно состоять из 2-50 строчных латинских букв, цифр и _, описание - не длиннее 500 символов")
+	ErrUnknownPermission  = errors.New("право отсутствует в реестре прав")
+	ErrSystemRoleLocked   = errors.New("права роли администратора нельзя изменить, встроенные роли нельзя удалить")
+	ErrRoleAssignDenied   = errors.New("назначать роли можно только с правом roles.manage и только роли с меньшими правами, чем у вас")
+	ErrUserOutranksActor  = errors.New("нельзя изменять пользователя, роль которого дает права, которых нет у вас")
+
+	// Ошибки модерации
+	ErrInvalidModerationFilter   = errors.New("некорректный фильтр очереди модерации")
+	ErrInvalidModerationReview   = errors.New("комментарий модератора не должен превышать 1000 символов")
+	ErrInvalidModerationAppeal   = errors.New("текст апелляции обязателен и не должен превышать 1000 символов")
+	ErrModerationAlreadyReviewed = errors.New("блокировка уже рассмотрена")
+	ErrModerationAppealExists    = errors.New("апелляция на это сообщение уже подана")
+
+	// Ошибки поиска
+	ErrInvalidSearchQuery = errors.New("поисковый запрос должен содержать от 2 до 200 символов, type - messages, homework или reports, limit - не больше 50")
 )
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// GroupMembershipAction определяет тип события в истории участников группы
+type GroupMembershipAction string
+
+const (
+	// GroupMemberAdded студент добавлен в группу
+	GroupMemberAdded GroupMembershipAction = "added"
+	// GroupMemberRemoved студент исключён из группы
+	GroupMemberRemoved GroupMembershipAction = "removed"
+	// GroupMemberEnrolled участник записан на занятия новой серии, привязанной к группе
+	GroupMemberEnrolled GroupMembershipAction = "enrolled"
+	// GroupMemberUnenrolled участник выписан из занятий серии, отвязанной от группы
+	GroupMemberUnenrolled GroupMembershipAction = "unenrolled"
+)
+
+// Group представляет учебную группу (когорту): преподаватель, предмет и участники
+// Группа привязывается к одной или нескольким сериям повторяющихся занятий
+type Group struct {
+	ID          uuid.UUID     `db:"id" json:"id"`
+	Name        string        `db:"name" json:"name"`
+	Description string        `db:"description" json:"description"`
+	TeacherID   uuid.UUID     `db:"teacher_id" json:"teacher_id"`
+	SubjectID   uuid.NullUUID `db:"subject_id" json:"subject_id,omitempty"`
+	CreatedBy   uuid.NullUUID `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at" json:"updated_at"`
+	DeletedAt   sql.NullTime  `db:"deleted_at" json:"-"`
+}
+
+// GroupWithDetails представляет группу с именем преподавателя, предметом, сериями и количеством участников
+type GroupWithDetails struct {
+	Group
+	TeacherName string      `db:"teacher_name" json:"teacher_name"`
+	SubjectName string      `db:"subject_name" json:"subject_name,omitempty"`
+	MemberCount int         `db:"member_count" json:"member_count"`
+	SeriesIDs   []uuid.UUID `db:"-" json:"series_ids"`
+}
+
+// GroupMember представляет период участия студента в группе
+type GroupMember struct {
+	ID          uuid.UUID     `db:"id" json:"id"`
+	GroupID     uuid.UUID     `db:"group_id" json:"group_id"`
+	StudentID   uuid.UUID     `db:"student_id" json:"student_id"`
+	StudentName string        `db:"student_name" json:"student_name"`
+	JoinedAt    time.Time     `db:"joined_at" json:"joined_at"`
+	LeftAt      sql.NullTime  `db:"left_at" json:"left_at,omitempty"`
+	AddedBy     uuid.NullUUID `db:"added_by" json:"added_by,omitempty"`
+	RemovedBy   uuid.NullUUID `db:"removed_by" json:"removed_by,omitempty"`
+}
+
+// IsActive проверяет, является ли студент текущим участником группы
+func (m *GroupMember) IsActive() bool {
+	return !m.LeftAt.Valid
+}
+
+// GroupMembershipEvent представляет запись аудита изменения состава группы
+type GroupMembershipEvent struct {
+	ID              uuid.UUID             `db:"id" json:"id"`
+	GroupID         uuid.UUID             `db:"group_id" json:"group_id"`
+	StudentID       uuid.UUID             `db:"student_id" json:"student_id"`
+	StudentName     string                `db:"student_name" json:"student_name"`
+	Action          GroupMembershipAction `db:"action" json:"action"`
+	LessonsAffected int                   `db:"lessons_affected" json:"lessons_affected"`
+	CreditsDelta    int                   `db:"credits_delta" json:"credits_delta"` // отрицательное - списано, положительное - возвращено
+	PerformedBy     uuid.NullUUID         `db:"performed_by" json:"performed_by,omitempty"`
+	CreatedAt       time.Time             `db:"created_at" json:"created_at"`
+}
+
+// CreateGroupRequest представляет запрос на создание группы
+type CreateGroupRequest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	TeacherID   uuid.UUID  `json:"teacher_id"`
+	SubjectID   *uuid.UUID `json:"subject_id,omitempty"`
+}
+
+// UpdateGroupRequest представляет запрос на обновление группы
+// Смена преподавателя не переносит существующие занятия - для этого используется массовое редактирование серии
+type UpdateGroupRequest struct {
+	Name        *string    `json:"name,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	TeacherID   *uuid.UUID `json:"teacher_id,omitempty"`
+	SubjectID   *uuid.UUID `json:"subject_id,omitempty"`
+}
+
+// AddGroupMemberRequest представляет запрос на добавление студента в группу
+type AddGroupMemberRequest struct {
+	StudentID uuid.UUID `json:"student_id"`
+}
+
+// LinkGroupSeriesRequest представляет запрос на привязку серии повторяющихся занятий к группе
+type LinkGroupSeriesRequest struct {
+	RecurringGroupID uuid.UUID `json:"recurring_group_id"`
+}
+
+// GroupEnrollmentResult представляет результат записи/выписки студентов на занятия группы
+type GroupEnrollmentResult struct {
+	GroupID         uuid.UUID `json:"group_id"`
+	StudentsCount   int       `json:"students_count"`
+	LessonsAffected int       `json:"lessons_affected"`
+	CreditsDelta    int       `json:"credits_delta"` // отрицательное - списано, положительное - возвращено
+}
+
+// Validate выполняет валидацию CreateGroupRequest
+func (r *CreateGroupRequest) Validate() error {
+	if err := validateGroupName(r.Name); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(r.Description) > 1000 {
+		return ErrGroupDescriptionTooLong
+	}
+	if r.TeacherID == uuid.Nil {
+		return ErrInvalidTeacherID
+	}
+	if r.SubjectID != nil && *r.SubjectID == uuid.Nil {
+		return ErrInvalidSubjectID
+	}
+	return nil
+}
+
+// Validate выполняет валидацию UpdateGroupRequest
+func (r *UpdateGroupRequest) Validate() error {
+	if r.Name != nil {
+		if err := validateGroupName(*r.Name); err != nil {
+			return err
+		}
+	}
+	if r.Description != nil && utf8.RuneCountInString(*r.Description) > 1000 {
+		return ErrGroupDescriptionTooLong
+	}
+	if r.TeacherID != nil && *r.TeacherID == uuid.Nil {
+		return ErrInvalidTeacherID
+	}
+	if r.SubjectID != nil && *r.SubjectID == uuid.Nil {
+		return ErrInvalidSubjectID
+	}
+	return nil
+}
+
+// Validate выполняет валидацию AddGroupMemberRequest
+func (r *AddGroupMemberRequest) Validate() error {
+	if r.StudentID == uuid.Nil {
+		return ErrInvalidStudentID
+	}
+	return nil
+}
+
+// Validate выполняет валидацию LinkGroupSeriesRequest
+func (r *LinkGroupSeriesRequest) Validate() error {
+	if r.RecurringGroupID == uuid.Nil {
+		return ErrInvalidRecurringGroupID
+	}
+	return nil
+}
+
+func validateGroupName(name string) error {
+	length := utf8.RuneCountInString(strings.TrimSpace(name))
+	if length < 1 || length > 200 {
+		return ErrInvalidGroupName
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateGroupRequest_Validate(t *testing.T) {
+	nilSubject := uuid.Nil
+
+	tests := []struct {
+		name    string
+		req     CreateGroupRequest
+		wantErr error
+	}{
+		{
+			name: "valid",
+			req:  CreateGroupRequest{Name: "Математика 9А", TeacherID: uuid.New()},
+		},
+		{
+			name:    "blank name",
+			req:     CreateGroupRequest{Name: "   ", TeacherID: uuid.New()},
+			wantErr: ErrInvalidGroupName,
+		},
+		{
+			name:    "name too long",
+			req:     CreateGroupRequest{Name: strings.Repeat("я", 201), TeacherID: uuid.New()},
+			wantErr: ErrInvalidGroupName,
+		},
+		{
+			name:    "description too long",
+			req:     CreateGroupRequest{Name: "x", Description: strings.Repeat("a", 1001), TeacherID: uuid.New()},
+			wantErr: ErrGroupDescriptionTooLong,
+		},
+		{
+			name:    "missing teacher",
+			req:     CreateGroupRequest{Name: "x"},
+			wantErr: ErrInvalidTeacherID,
+		},
+		{
+			name:    "nil subject",
+			req:     CreateGroupRequest{Name: "x", TeacherID: uuid.New(), SubjectID: &nilSubject},
+			wantErr: ErrInvalidSubjectID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); err != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateGroupRequest_Validate(t *testing.T) {
+	empty := ""
+	name := "Английский B1"
+
+	if err := (&UpdateGroupRequest{}).Validate(); err != nil {
+		t.Errorf("empty update should be valid, got %v", err)
+	}
+	if err := (&UpdateGroupRequest{Name: &name}).Validate(); err != nil {
+		t.Errorf("Validate() unexpected error = %v", err)
+	}
+	if err := (&UpdateGroupRequest{Name: &empty}).Validate(); err != ErrInvalidGroupName {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalidGroupName)
+	}
+}
+
+func TestGroupMember_IsActive(t *testing.T) {
+	member := &GroupMember{}
+	if !member.IsActive() {
+		t.Error("member without left_at should be active")
+	}
+
+	member.LeftAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if member.IsActive() {
+		t.Error("member with left_at should not be active")
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +19,21 @@ type LessonHomework struct {
 	TextContent string    `db:"text_content" json:"text_content"` // Текстовое описание домашнего задания
 	CreatedBy   uuid.UUID `db:"created_by" json:"created_by"`     // Кто загрузил файл (teacher/admin)
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+
+	// Ключ в BlobStore и SHA-256 содержимого; NULL - файл загружен до перехода на BlobStore и лежит по FilePath
+	StorageKey  sql.NullString `db:"storage_key" json:"-"`
+	ContentHash sql.NullString `db:"content_hash" json:"-"`
+	// Ключ превью в BlobStore; NULL - превью еще не построено или для типа файла не строится
+	ThumbnailKey sql.NullString `db:"thumbnail_key" json:"-"`
+}
+
+// MarshalJSON добавляет признак has_thumbnail: превью доступно по /homework/{file_id}/thumbnail
+func (h LessonHomework) MarshalJSON() ([]byte, error) {
+	type homeworkAlias LessonHomework
+	return json.Marshal(struct {
+		homeworkAlias
+		HasThumbnail bool `json:"has_thumbnail"`
+	}{homeworkAlias(h), h.ThumbnailKey.Valid})
 }
 
 // CreateHomeworkRequest представляет запрос на создание домашнего задания
@@ -0,0 +1,406 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tutoring-platform/pkg/xlsx"
+
+	"github.com/google/uuid"
+)
+
+// ImportKind определяет тип сущностей в файле импорта
+type ImportKind string
+
+const (
+	// ImportKindUsers импорт пользователей
+	ImportKindUsers ImportKind = "users"
+	// ImportKindLessons импорт занятий
+	ImportKindLessons ImportKind = "lessons"
+	// ImportKindBookings импорт записей студентов на занятия
+	ImportKindBookings ImportKind = "bookings"
+)
+
+// IsValid проверяет, что тип импорта поддерживается
+func (k ImportKind) IsValid() bool {
+	switch k {
+	case ImportKindUsers, ImportKindLessons, ImportKindBookings:
+		return true
+	default:
+		return false
+	}
+}
+
+// ImportRowStatus результат обработки строки файла импорта
+type ImportRowStatus string
+
+const (
+	// ImportRowValid строка прошла проверку и будет создана (пробный запуск)
+	ImportRowValid ImportRowStatus = "valid"
+	// ImportRowCreated строка создана
+	ImportRowCreated ImportRowStatus = "created"
+	// ImportRowSkipped сущность уже существует (повторный импорт), строка пропущена
+	ImportRowSkipped ImportRowStatus = "skipped"
+	// ImportRowError строка содержит ошибки
+	ImportRowError ImportRowStatus = "error"
+)
+
+const (
+	// MaxImportRows максимальное количество строк данных в одном файле
+	MaxImportRows = 5000
+	// MaxImportFileSize максимальный размер файла импорта в байтах
+	MaxImportFileSize = 10 << 20
+	// maxImportExternalIDLength максимальная длина внешнего идентификатора
+	maxImportExternalIDLength = 255
+)
+
+// importRequiredColumns обязательные колонки для каждого типа импорта.
+// Для записей на занятия занятие задается одной из колонок lesson_id или lesson_external_id
+var importRequiredColumns = map[ImportKind][]string{
+	ImportKindUsers:    {"email"},
+	ImportKindLessons:  {"teacher_email", "start_time", "end_time"},
+	ImportKindBookings: {"student_email"},
+}
+
+// ImportRowResult результат проверки или применения строки файла
+type ImportRowResult struct {
+	Row               int             `json:"row"`
+	Key               string          `json:"key"`
+	Status            ImportRowStatus `json:"status"`
+	Errors            []string        `json:"errors,omitempty"`
+	EntityID          *uuid.UUID      `json:"entity_id,omitempty"`
+	TemporaryPassword string          `json:"temporary_password,omitempty"`
+}
+
+// AddError добавляет ошибку строки и переводит ее в статус error
+func (r *ImportRowResult) AddError(err error) {
+	r.Status = ImportRowError
+	r.Errors = append(r.Errors, err.Error())
+}
+
+// ImportReport построчный отчет об импорте
+type ImportReport struct {
+	ImportID *uuid.UUID         `json:"import_id,omitempty"`
+	Kind     ImportKind         `json:"kind"`
+	FileName string             `json:"file_name"`
+	DryRun   bool               `json:"dry_run"`
+	Total    int                `json:"total"`
+	Valid    int                `json:"valid"`
+	Created  int                `json:"created"`
+	Skipped  int                `json:"skipped"`
+	Failed   int                `json:"failed"`
+	Rows     []*ImportRowResult `json:"rows"`
+}
+
+// Count пересчитывает итоги отчета по статусам строк
+func (r *ImportReport) Count() {
+	r.Total = len(r.Rows)
+	r.Valid, r.Created, r.Skipped, r.Failed = 0, 0, 0, 0
+	for _, row := range r.Rows {
+		switch row.Status {
+		case ImportRowValid:
+			r.Valid++
+		case ImportRowCreated:
+			r.Created++
+		case ImportRowSkipped:
+			r.Skipped++
+		case ImportRowError:
+			r.Failed++
+		}
+	}
+}
+
+// HasErrors проверяет, есть ли в отчете строки с ошибками
+func (r *ImportReport) HasErrors() bool {
+	for _, row := range r.Rows {
+		if row.Status == ImportRowError {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportRun запись о примененном импорте
+type ImportRun struct {
+	ID          uuid.UUID     `db:"id" json:"id"`
+	Kind        ImportKind    `db:"kind" json:"kind"`
+	FileName    string        `db:"file_name" json:"file_name"`
+	RowsTotal   int           `db:"rows_total" json:"rows_total"`
+	RowsCreated int           `db:"rows_created" json:"rows_created"`
+	RowsSkipped int           `db:"rows_skipped" json:"rows_skipped"`
+	CreatedBy   uuid.NullUUID `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+}
+
+// ImportRecord строка данных файла импорта
+type ImportRecord struct {
+	// Line номер строки в файле (заголовок - строка 1)
+	Line   int
+	values map[string]string
+}
+
+// Get возвращает значение колонки без пробелов по краям (пустую строку, если колонки нет)
+func (r ImportRecord) Get(column string) string {
+	return strings.TrimSpace(r.values[column])
+}
+
+// ParseImportTable разбирает строки файла: первая строка - заголовок с именами колонок,
+// пустые строки пропускаются. Имена колонок приводятся к нижнему регистру, пробелы заменяются на "_"
+func ParseImportTable(kind ImportKind, rows [][]string) ([]ImportRecord, error) {
+	if !kind.IsValid() {
+		return nil, ErrInvalidImportKind
+	}
+
+	headerIdx := -1
+	for i, row := range rows {
+		if !isBlankImportRow(row) {
+			headerIdx = i
+			break
+		}
+	}
+	if headerIdx < 0 {
+		return nil, ErrImportEmptyFile
+	}
+
+	header := make([]string, len(rows[headerIdx]))
+	present := make(map[string]bool, len(header))
+	for i, name := range rows[headerIdx] {
+		header[i] = normalizeImportColumn(name)
+		present[header[i]] = true
+	}
+
+	var missing []string
+	for _, column := range importRequiredColumns[kind] {
+		if !present[column] {
+			missing = append(missing, column)
+		}
+	}
+	if kind == ImportKindBookings && !present["lesson_id"] && !present["lesson_external_id"] {
+		missing = append(missing, "lesson_id|lesson_external_id")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrImportMissingColumns, strings.Join(missing, ", "))
+	}
+
+	records := make([]ImportRecord, 0, len(rows)-headerIdx-1)
+	for i := headerIdx + 1; i < len(rows); i++ {
+		if isBlankImportRow(rows[i]) {
+			continue
+		}
+		if len(records) == MaxImportRows {
+			return nil, ErrImportTooManyRows
+		}
+		values := make(map[string]string, len(header))
+		for j, value := range rows[i] {
+			if j < len(header) && header[j] != "" {
+				values[header[j]] = value
+			}
+		}
+		records = append(records, ImportRecord{Line: i + 1, values: values})
+	}
+	if len(records) == 0 {
+		return nil, ErrImportEmptyFile
+	}
+	return records, nil
+}
+
+// UserImportRow строка импорта пользователей
+type UserImportRow struct {
+	Request    CreateUserRequest
+	ExternalID string
+	// GeneratedPassword true, если пароль в файле не указан и должен быть сгенерирован
+	GeneratedPassword bool
+}
+
+// ParseUserImportRow разбирает строку импорта пользователей и проверяет все поля, кроме роли. Роль по умолчанию - student.
+// Если пароль не указан, подставляется временный пароль tempPassword
+func ParseUserImportRow(rec ImportRecord, tempPassword string) (*UserImportRow, []error) {
+	row := &UserImportRow{
+		Request: CreateUserRequest{
+			Email:     rec.Get("email"),
+			Password:  rec.Get("password"),
+			FirstName: rec.Get("first_name"),
+			LastName:  rec.Get("last_name"),
+			FullName:  rec.Get("full_name"),
+			Role:      UserRole(strings.ToLower(rec.Get("role"))),
+		},
+		ExternalID: rec.Get("external_id"),
+	}
+	if row.Request.Role == "" {
+		row.Request.Role = RoleStudent
+	}
+	if row.Request.Password == "" {
+		row.Request.Password = tempPassword
+		row.GeneratedPassword = true
+	}
+
+	var errs []error
+	if err := validateImportExternalID(row.ExternalID); err != nil {
+		errs = append(errs, err)
+	}
+	// Роль проверяет UserService.ValidateCreateUser: с учетом пользовательских ролей и прав импортирующего
+	anyRole := func(UserRole) bool { return true }
+	if err := row.Request.ValidateWithRoles(anyRole); err != nil {
+		errs = append(errs, err)
+	}
+	return row, errs
+}
+
+// LessonImportRow строка импорта занятий. TeacherID запроса заполняется по TeacherEmail
+type LessonImportRow struct {
+	Request      CreateLessonRequest
+	TeacherEmail string
+	ExternalID   string
+}
+
+// ParseLessonImportRow разбирает строку импорта занятий. Время без часового пояса
+// трактуется в location loc. Значения по умолчанию: max_students = 1, credits_cost = 1,
+// color = DefaultTrialLessonColor. Проверка запроса (Validate) выполняется после заполнения TeacherID
+func ParseLessonImportRow(rec ImportRecord, loc *time.Location) (*LessonImportRow, []error) {
+	row := &LessonImportRow{
+		TeacherEmail: strings.ToLower(rec.Get("teacher_email")),
+		ExternalID:   rec.Get("external_id"),
+		Request: CreateLessonRequest{
+			MaxStudents: 1,
+			CreditsCost: 1,
+			Color:       rec.Get("color"),
+		},
+	}
+	if row.Request.Color == "" {
+		row.Request.Color = DefaultTrialLessonColor
+	}
+
+	var errs []error
+	if err := validateImportExternalID(row.ExternalID); err != nil {
+		errs = append(errs, err)
+	}
+	if row.TeacherEmail == "" {
+		errs = append(errs, ErrInvalidTeacherID)
+	}
+
+	var err error
+	if row.Request.StartTime, err = ParseImportTime(rec.Get("start_time"), loc); err != nil {
+		errs = append(errs, fmt.Errorf("start_time: %w", err))
+	}
+	if row.Request.EndTime, err = ParseImportTime(rec.Get("end_time"), loc); err != nil {
+		errs = append(errs, fmt.Errorf("end_time: %w", err))
+	}
+	if v := rec.Get("max_students"); v != "" {
+		if row.Request.MaxStudents, err = parseImportInt(v); err != nil {
+			errs = append(errs, fmt.Errorf("max_students: %w", err))
+		}
+	}
+	if v := rec.Get("credits_cost"); v != "" {
+		if row.Request.CreditsCost, err = parseImportInt(v); err != nil {
+			errs = append(errs, fmt.Errorf("credits_cost: %w", err))
+		}
+	}
+	if v := strings.ToLower(rec.Get("lesson_type")); v != "" {
+		lessonType := LessonType(v)
+		if lessonType != LessonTypeIndividual && lessonType != LessonTypeGroup {
+			errs = append(errs, ErrInvalidImportLessonType)
+		}
+		row.Request.LessonType = &lessonType
+	}
+	if v := rec.Get("subject"); v != "" {
+		row.Request.Subject = &v
+	}
+	if v := rec.Get("homework_text"); v != "" {
+		row.Request.HomeworkText = &v
+	}
+	if v := rec.Get("link"); v != "" {
+		row.Request.Link = &v
+	}
+	return row, errs
+}
+
+// BookingImportRow строка импорта записей на занятия. Занятие задается либо ID,
+// либо внешним идентификатором из ранее импортированного файла занятий
+type BookingImportRow struct {
+	StudentEmail     string
+	LessonID         uuid.UUID
+	LessonExternalID string
+}
+
+// ParseBookingImportRow разбирает строку импорта записей на занятия
+func ParseBookingImportRow(rec ImportRecord) (*BookingImportRow, []error) {
+	row := &BookingImportRow{
+		StudentEmail:     strings.ToLower(rec.Get("student_email")),
+		LessonExternalID: rec.Get("lesson_external_id"),
+	}
+
+	var errs []error
+	if row.StudentEmail == "" {
+		errs = append(errs, ErrInvalidStudentID)
+	}
+	if v := rec.Get("lesson_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			errs = append(errs, ErrInvalidLessonID)
+		}
+		row.LessonID = id
+	}
+	if row.LessonID == uuid.Nil && row.LessonExternalID == "" && len(errs) == 0 {
+		errs = append(errs, ErrImportLessonRequired)
+	}
+	return row, errs
+}
+
+// ParseImportTime разбирает дату и время из файла импорта: RFC3339, "2006-01-02 15:04[:05]",
+// "02.01.2006 15:04" или сериальное число даты Excel. Время без часового пояса трактуется в loc
+func ParseImportTime(value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, ErrImportInvalidTime
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04", "02.01.2006 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		return xlsx.SerialToTime(serial, loc), nil
+	}
+	return time.Time{}, ErrImportInvalidTime
+}
+
+// parseImportInt разбирает целое число; XLSX хранит числа как "4" или "4.0"
+func parseImportInt(value string) (int, error) {
+	if n, err := strconv.Atoi(value); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f != float64(int(f)) {
+		return 0, ErrImportInvalidNumber
+	}
+	return int(f), nil
+}
+
+// validateImportExternalID проверяет длину внешнего идентификатора
+func validateImportExternalID(id string) error {
+	if len(id) > maxImportExternalIDLength {
+		return ErrInvalidImportExternalID
+	}
+	return nil
+}
+
+// normalizeImportColumn приводит имя колонки к виду snake_case (с удалением BOM)
+func normalizeImportColumn(name string) string {
+	name = strings.TrimPrefix(name, "\ufeff")
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.Fields(name), "_")
+}
+
+// isBlankImportRow проверяет, что в строке нет заполненных ячеек
+func isBlankImportRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
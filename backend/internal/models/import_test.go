@@ -0,0 +1,158 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseImportTable(t *testing.T) {
+	rows := [][]string{
+		{},
+		{"\ufeffEmail", " First Name ", "Role"},
+		{"a@example.com", "Анна", "student"},
+		{"", " ", ""},
+		{"b@example.com"},
+	}
+
+	records, err := ParseImportTable(ImportKindUsers, rows)
+	if err != nil {
+		t.Fatalf("ParseImportTable() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].Line != 3 || records[0].Get("email") != "a@example.com" || records[0].Get("first_name") != "Анна" {
+		t.Errorf("record 0 = %+v", records[0])
+	}
+	if records[1].Line != 5 || records[1].Get("role") != "" {
+		t.Errorf("record 1 = %+v", records[1])
+	}
+}
+
+func TestParseImportTable_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		kind ImportKind
+		rows [][]string
+		want error
+	}{
+		{name: "invalid kind", kind: "groups", rows: [][]string{{"email"}}, want: ErrInvalidImportKind},
+		{name: "empty file", kind: ImportKindUsers, rows: [][]string{{""}}, want: ErrImportEmptyFile},
+		{name: "header only", kind: ImportKindUsers, rows: [][]string{{"email"}}, want: ErrImportEmptyFile},
+		{name: "missing lesson columns", kind: ImportKindLessons, rows: [][]string{{"teacher_email", "start_time"}, {"t@example.com", "2026-05-04 10:00"}}, want: ErrImportMissingColumns},
+		{name: "booking without lesson column", kind: ImportKindBookings, rows: [][]string{{"student_email"}, {"s@example.com"}}, want: ErrImportMissingColumns},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseImportTable(tt.kind, tt.rows); !errors.Is(err, tt.want) {
+				t.Errorf("ParseImportTable() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	rows := make([][]string, MaxImportRows+2)
+	rows[0] = []string{"email"}
+	for i := 1; i < len(rows); i++ {
+		rows[i] = []string{"a@example.com"}
+	}
+	if _, err := ParseImportTable(ImportKindUsers, rows); !errors.Is(err, ErrImportTooManyRows) {
+		t.Errorf("ParseImportTable() error = %v, want ErrImportTooManyRows", err)
+	}
+}
+
+func TestParseUserImportRow(t *testing.T) {
+	records, err := ParseImportTable(ImportKindUsers, [][]string{
+		{"email", "full_name", "role", "password"},
+		{" Anna@Example.com ", "Анна Иванова", "", ""},
+		{"broken", "A", "guest", "short"},
+	})
+	if err != nil {
+		t.Fatalf("ParseImportTable() error = %v", err)
+	}
+
+	row, errs := ParseUserImportRow(records[0], "temporary-pass")
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if row.Request.Email != "anna@example.com" || row.Request.Role != RoleStudent || !row.GeneratedPassword {
+		t.Errorf("row = %+v", row)
+	}
+
+	_, errs = ParseUserImportRow(records[1], "temporary-pass")
+	if len(errs) != 1 || !errors.Is(errs[0], ErrInvalidEmail) {
+		t.Errorf("errors = %v, want [ErrInvalidEmail]", errs)
+	}
+}
+
+func TestParseLessonImportRow(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*3600)
+	records, err := ParseImportTable(ImportKindLessons, [][]string{
+		{"teacher_email", "start_time", "end_time", "max_students", "subject", "lesson_type"},
+		{"T@Example.com", "2026-05-04 10:00", "46146.5", "4.0", "Алгебра", "group"},
+		{"t@example.com", "tomorrow", "", "two", "", "webinar"},
+	})
+	if err != nil {
+		t.Fatalf("ParseImportTable() error = %v", err)
+	}
+
+	row, errs := ParseLessonImportRow(records[0], loc)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if row.TeacherEmail != "t@example.com" || row.Request.MaxStudents != 4 || row.Request.CreditsCost != 1 ||
+		row.Request.Color != DefaultTrialLessonColor || *row.Request.Subject != "Алгебра" {
+		t.Errorf("row = %+v", row)
+	}
+	if want := time.Date(2026, 5, 4, 10, 0, 0, 0, loc); !row.Request.StartTime.Equal(want) {
+		t.Errorf("StartTime = %v, want %v", row.Request.StartTime, want)
+	}
+	if want := time.Date(2026, 5, 4, 12, 0, 0, 0, loc); !row.Request.EndTime.Equal(want) {
+		t.Errorf("EndTime = %v, want %v", row.Request.EndTime, want)
+	}
+
+	_, errs = ParseLessonImportRow(records[1], loc)
+	if len(errs) != 4 {
+		t.Errorf("got %d errors, want 4 (start_time, end_time, max_students, lesson_type): %v", len(errs), errs)
+	}
+}
+
+func TestParseBookingImportRow(t *testing.T) {
+	records, err := ParseImportTable(ImportKindBookings, [][]string{
+		{"student_email", "lesson_id", "lesson_external_id"},
+		{"s@example.com", "", "L-1"},
+		{"s@example.com", "", ""},
+		{"", "not-a-uuid", ""},
+	})
+	if err != nil {
+		t.Fatalf("ParseImportTable() error = %v", err)
+	}
+
+	if row, errs := ParseBookingImportRow(records[0]); len(errs) != 0 || row.LessonExternalID != "L-1" {
+		t.Errorf("row = %+v, errors = %v", row, errs)
+	}
+	if _, errs := ParseBookingImportRow(records[1]); len(errs) != 1 || !errors.Is(errs[0], ErrImportLessonRequired) {
+		t.Errorf("errors = %v, want [ErrImportLessonRequired]", errs)
+	}
+	if _, errs := ParseBookingImportRow(records[2]); len(errs) != 2 {
+		t.Errorf("got %d errors, want 2: %v", len(errs), errs)
+	}
+}
+
+func TestImportReport_Count(t *testing.T) {
+	report := &ImportReport{Rows: []*ImportRowResult{
+		{Row: 2, Status: ImportRowValid},
+		{Row: 3, Status: ImportRowSkipped},
+		{Row: 4, Status: ImportRowCreated},
+	}}
+	report.Count()
+	if report.HasErrors() || report.Total != 3 || report.Valid != 1 || report.Skipped != 1 || report.Created != 1 {
+		t.Errorf("report = %+v", report)
+	}
+
+	report.Rows[0].AddError(ErrImportDuplicateRow)
+	report.Count()
+	if !report.HasErrors() || report.Failed != 1 || report.Valid != 0 {
+		t.Errorf("report = %+v", report)
+	}
+}
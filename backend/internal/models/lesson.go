@@ -191,6 +191,7 @@ type CreateLessonRequest struct {
 	StudentIDs       []uuid.UUID `json:"student_ids,omitempty"`        // Optional: students to enroll on creation
 	IsRecurring      bool        `json:"is_recurring,omitempty"`       // Optional: создать повторяющееся занятие еженедельно
 	RecurringEndDate *time.Time  `json:"recurring_end_date,omitempty"` // Optional: дата окончания повторений
+	HolidayPolicy    string      `json:"holiday_policy,omitempty"`     // Optional: skip (default), shift или ignore для праздников и каникул
 }
 
 // UpdateLessonRequest представляет запрос на обновление урока
@@ -419,6 +420,11 @@ func (r *CreateLessonRequest) Validate() error {
 		}
 	}
 
+	// Validate HolidayPolicy
+	if _, err := ParseHolidayPolicy(r.HolidayPolicy); err != nil {
+		return err
+	}
+
 	return nil
 }
 
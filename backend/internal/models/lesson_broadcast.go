@@ -1,6 +1,7 @@
 package models
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,6 +31,10 @@ type BroadcastFile struct {
 	FileSize    int64     `db:"file_size" json:"file_size"`
 	MimeType    string    `db:"mime_type" json:"mime_type"`
 	UploadedAt  time.Time `db:"uploaded_at" json:"uploaded_at"`
+
+	// Ключ в BlobStore и SHA-256 содержимого; NULL - файл загружен до перехода на BlobStore и лежит по FilePath
+	StorageKey  sql.NullString `db:"storage_key" json:"-"`
+	ContentHash sql.NullString `db:"content_hash" json:"-"`
 }
 
 // CreateLessonBroadcastRequest представляет запрос на создание рассылки урока
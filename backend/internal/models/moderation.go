@@ -0,0 +1,141 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Правила модерации, по которым блокируется сообщение. Используются в статистике по правилам
+const (
+	ModerationRuleAI              = "ai"
+	ModerationRulePhone           = "phone"
+	ModerationRuleEmail           = "email"
+	ModerationRuleSocial          = "social"
+	ModerationRuleVideoPlatform   = "video_platform"
+	ModerationRuleObfuscatedPhone = "obfuscated_phone"
+	ModerationRuleUnknown         = "unknown"
+)
+
+// Статусы рассмотрения блокировки администратором
+const (
+	// ModerationReviewPending блокировка ожидает рассмотрения (в том числе после апелляции)
+	ModerationReviewPending = "pending"
+	// ModerationReviewReleased блокировка снята, сообщение доставлено
+	ModerationReviewReleased = "released"
+	// ModerationReviewConfirmed блокировка подтверждена
+	ModerationReviewConfirmed = "confirmed"
+)
+
+const (
+	// DefaultModerationPageSize размер страницы очереди модерации по умолчанию
+	DefaultModerationPageSize = 50
+	// MaxModerationPageSize максимальный размер страницы очереди модерации
+	MaxModerationPageSize = 200
+	// MaxModerationTextLength ограничивает длину комментария модератора и текста апелляции
+	MaxModerationTextLength = 1000
+)
+
+// BlockedMessageReview заблокированное сообщение в очереди модерации
+type BlockedMessageReview struct {
+	ID           uuid.UUID       `db:"id" json:"id"`
+	MessageID    uuid.UUID       `db:"message_id" json:"message_id"`
+	RoomID       uuid.UUID       `db:"room_id" json:"room_id"`
+	SenderID     uuid.UUID       `db:"sender_id" json:"sender_id"`
+	SenderName   string          `db:"sender_name" json:"sender_name"`
+	MessageText  string          `db:"message_text" json:"message_text"`
+	Reason       string          `db:"reason" json:"reason"`
+	Rule         string          `db:"rule" json:"rule"`
+	AIResponse   json.RawMessage `db:"ai_response" json:"ai_response,omitempty"`
+	BlockedAt    time.Time       `db:"blocked_at" json:"blocked_at"`
+	ReviewStatus string          `db:"review_status" json:"review_status"`
+	ReviewedBy   uuid.NullUUID   `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewerName string          `db:"reviewer_name" json:"reviewer_name,omitempty"`
+	ReviewedAt   sql.NullTime    `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	ReviewNote   string          `db:"review_note" json:"review_note,omitempty"`
+	AppealReason sql.NullString  `db:"appeal_reason" json:"appeal_reason,omitempty"`
+	AppealedAt   sql.NullTime    `db:"appealed_at" json:"appealed_at,omitempty"`
+}
+
+// IsAppealed проверяет, подана ли апелляция
+func (b *BlockedMessageReview) IsAppealed() bool {
+	return b.AppealedAt.Valid
+}
+
+// ModerationQueueFilter фильтр очереди модерации
+type ModerationQueueFilter struct {
+	Status       string
+	Rule         string
+	AppealedOnly bool
+	Limit        int
+	Offset       int
+}
+
+// Validate проверяет фильтр и подставляет размер страницы по умолчанию
+func (f *ModerationQueueFilter) Validate() error {
+	switch f.Status {
+	case "", ModerationReviewPending, ModerationReviewReleased, ModerationReviewConfirmed:
+	default:
+		return ErrInvalidModerationFilter
+	}
+	if f.Limit < 0 || f.Limit > MaxModerationPageSize || f.Offset < 0 {
+		return ErrInvalidModerationFilter
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultModerationPageSize
+	}
+	return nil
+}
+
+// ModerationReviewRequest решение администратора по блокировке
+type ModerationReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// Validate проверяет комментарий модератора
+func (r *ModerationReviewRequest) Validate() error {
+	r.Note = strings.TrimSpace(r.Note)
+	if len([]rune(r.Note)) > MaxModerationTextLength {
+		return ErrInvalidModerationReview
+	}
+	return nil
+}
+
+// ModerationAppealRequest апелляция отправителя на блокировку сообщения
+type ModerationAppealRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Validate проверяет текст апелляции
+func (r *ModerationAppealRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" || len([]rune(r.Reason)) > MaxModerationTextLength {
+		return ErrInvalidModerationAppeal
+	}
+	return nil
+}
+
+// ModerationRuleStats статистика блокировок по правилу модерации
+type ModerationRuleStats struct {
+	Rule      string `db:"rule" json:"rule"`
+	Total     int    `db:"total" json:"total"`
+	Pending   int    `db:"pending" json:"pending"`
+	Released  int    `db:"released" json:"released"`
+	Confirmed int    `db:"confirmed" json:"confirmed"`
+	Appealed  int    `db:"appealed" json:"appealed"`
+	// ReleaseRate доля снятых блокировок среди рассмотренных - оценка ложных срабатываний правила
+	ReleaseRate float64 `db:"-" json:"release_rate"`
+}
+
+// CalculateReleaseRate вычисляет долю снятых блокировок среди рассмотренных
+func (s *ModerationRuleStats) CalculateReleaseRate() {
+	reviewed := s.Released + s.Confirmed
+	if reviewed == 0 {
+		s.ReleaseRate = 0
+		return
+	}
+	s.ReleaseRate = float64(s.Released) / float64(reviewed)
+}
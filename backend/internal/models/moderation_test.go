@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestModerationQueueFilterValidate(t *testing.T) {
+	filter := &ModerationQueueFilter{Status: ModerationReviewPending}
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if filter.Limit != DefaultModerationPageSize {
+		t.Errorf("Limit = %d, want default %d", filter.Limit, DefaultModerationPageSize)
+	}
+
+	invalid := []*ModerationQueueFilter{
+		{Status: "blocked"},
+		{Limit: MaxModerationPageSize + 1},
+		{Limit: -1},
+		{Offset: -1},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); !errors.Is(err, ErrInvalidModerationFilter) {
+			t.Errorf("Validate(%+v) error = %v, want ErrInvalidModerationFilter", f, err)
+		}
+	}
+}
+
+func TestModerationRequestsValidate(t *testing.T) {
+	review := &ModerationReviewRequest{Note: "  номер урока, не телефон  "}
+	if err := review.Validate(); err != nil || review.Note != "номер урока, не телефон" {
+		t.Errorf("Validate() = %v, note %q", err, review.Note)
+	}
+	if err := (&ModerationReviewRequest{}).Validate(); err != nil {
+		t.Errorf("empty note must be allowed, got %v", err)
+	}
+	long := &ModerationReviewRequest{Note: strings.Repeat("я", MaxModerationTextLength+1)}
+	if err := long.Validate(); !errors.Is(err, ErrInvalidModerationReview) {
+		t.Errorf("Validate(long note) error = %v, want ErrInvalidModerationReview", err)
+	}
+
+	for _, reason := range []string{"", "   ", strings.Repeat("я", MaxModerationTextLength+1)} {
+		req := &ModerationAppealRequest{Reason: reason}
+		if err := req.Validate(); !errors.Is(err, ErrInvalidModerationAppeal) {
+			t.Errorf("Validate(%q) error = %v, want ErrInvalidModerationAppeal", reason, err)
+		}
+	}
+}
+
+func TestModerationRuleStatsReleaseRate(t *testing.T) {
+	stats := &ModerationRuleStats{Rule: ModerationRulePhone, Total: 10, Pending: 2, Released: 2, Confirmed: 6}
+	stats.CalculateReleaseRate()
+	if stats.ReleaseRate != 0.25 {
+		t.Errorf("ReleaseRate = %v, want 0.25", stats.ReleaseRate)
+	}
+
+	empty := &ModerationRuleStats{Rule: ModerationRuleAI, Total: 3, Pending: 3}
+	empty.CalculateReleaseRate()
+	if empty.ReleaseRate != 0 {
+		t.Errorf("ReleaseRate without reviews = %v, want 0", empty.ReleaseRate)
+	}
+}
@@ -81,8 +81,17 @@ type YooKassaPaymentObject struct {
 		Currency string `json:"currency"`
 	} `json:"amount"`
 	Metadata struct {
-		PaymentID string `json:"payment_id"`
+		PaymentID             string `json:"payment_id"`
+		SubscriptionInvoiceID string `json:"subscription_invoice_id"` // Для платежей по подпискам
 	} `json:"metadata"`
+	PaymentMethod struct {
+		ID    string `json:"id"`
+		Saved bool   `json:"saved"`
+	} `json:"payment_method"`
+	CancellationDetails struct {
+		Party  string `json:"party"`
+		Reason string `json:"reason"`
+	} `json:"cancellation_details"`
 }
 
 // PaymentHistoryFilter представляет фильтры для истории платежей
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/google/uuid"
@@ -201,3 +202,35 @@ func TestPaymentHistoryFilter_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestYooKassaWebhookRequest_SubscriptionInvoiceID(t *testing.T) {
+	body := `{
+		"type": "notification",
+		"event": "payment.succeeded",
+		"object": {
+			"id": "2d5f-000f",
+			"status": "succeeded",
+			"paid": true,
+			"amount": {"value": "2800.00", "currency": "RUB"},
+			"metadata": {"subscription_invoice_id": "inv-1"},
+			"payment_method": {"id": "pm-1", "saved": true}
+		}
+	}`
+
+	var webhook YooKassaWebhookRequest
+	if err := json.Unmarshal([]byte(body), &webhook); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if webhook.Object.ID != "2d5f-000f" || webhook.Object.Amount.Value != "2800.00" {
+		t.Errorf("payment fields not decoded: %+v", webhook.Object)
+	}
+	if webhook.Object.Metadata.SubscriptionInvoiceID != "inv-1" {
+		t.Errorf("SubscriptionInvoiceID = %q, want inv-1", webhook.Object.Metadata.SubscriptionInvoiceID)
+	}
+	if webhook.Object.Metadata.PaymentID != "" {
+		t.Errorf("PaymentID = %q, want empty", webhook.Object.Metadata.PaymentID)
+	}
+	if !webhook.Object.PaymentMethod.Saved || webhook.Object.PaymentMethod.ID != "pm-1" {
+		t.Errorf("PaymentMethod = %+v, want saved pm-1", webhook.Object.PaymentMethod)
+	}
+}
@@ -0,0 +1,399 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PayRateType определяет способ расчета оплаты преподавателя
+type PayRateType string
+
+const (
+	// PayRatePerLesson фиксированная сумма за проведенное занятие
+	PayRatePerLesson PayRateType = "per_lesson"
+	// PayRatePerStudentHour сумма за каждый час занятия с каждым студентом
+	PayRatePerStudentHour PayRateType = "per_student_hour"
+	// PayRateCreditPercentage процент от стоимости кредитов, списанных за занятие
+	PayRateCreditPercentage PayRateType = "credit_percentage"
+)
+
+const (
+	// MaxPayrollPeriodDays ограничивает длину закрываемого периода
+	MaxPayrollPeriodDays = 92
+	// MaxAdjustmentReasonLength ограничивает длину причины корректировки
+	MaxAdjustmentReasonLength = 500
+)
+
+// IsValid проверяет тип ставки
+func (t PayRateType) IsValid() bool {
+	switch t {
+	case PayRatePerLesson, PayRatePerStudentHour, PayRateCreditPercentage:
+		return true
+	}
+	return false
+}
+
+// TeacherPayRate представляет ставку преподавателя, действующую с даты EffectiveFrom
+// до даты следующей ставки
+type TeacherPayRate struct {
+	ID            uuid.UUID     `db:"id" json:"id"`
+	TeacherID     uuid.UUID     `db:"teacher_id" json:"teacher_id"`
+	TeacherName   string        `db:"teacher_name" json:"teacher_name,omitempty"`
+	RateType      PayRateType   `db:"rate_type" json:"rate_type"`
+	Amount        float64       `db:"amount" json:"amount"`
+	CreditValue   float64       `db:"credit_value" json:"credit_value"`
+	EffectiveFrom time.Time     `db:"effective_from" json:"effective_from"`
+	CreatedBy     uuid.NullUUID `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+}
+
+// LessonAmount рассчитывает оплату за занятие по ставке
+func (r *TeacherPayRate) LessonAmount(l *PayrollLesson) float64 {
+	switch r.RateType {
+	case PayRatePerLesson:
+		return roundMoney(r.Amount)
+	case PayRatePerStudentHour:
+		return roundMoney(r.Amount * l.StudentHours())
+	case PayRateCreditPercentage:
+		return roundMoney(float64(l.CreditsConsumed()) * r.CreditValue * r.Amount / 100)
+	}
+	return 0
+}
+
+// PayrollLesson проведенное занятие, учитываемое в расчете оплаты.
+// Проведенным считается неудаленное завершившееся занятие хотя бы с одной активной записью
+type PayrollLesson struct {
+	LessonID      uuid.UUID `db:"lesson_id"`
+	TeacherID     uuid.UUID `db:"teacher_id"`
+	TeacherName   string    `db:"teacher_name"`
+	StartTime     time.Time `db:"start_time"`
+	EndTime       time.Time `db:"end_time"`
+	Subject       string    `db:"subject"`
+	StudentsCount int       `db:"students_count"`
+	CreditsCost   int       `db:"credits_cost"`
+}
+
+// Hours возвращает длительность занятия в часах
+func (l *PayrollLesson) Hours() float64 {
+	return l.EndTime.Sub(l.StartTime).Hours()
+}
+
+// StudentHours возвращает количество студенто-часов занятия
+func (l *PayrollLesson) StudentHours() float64 {
+	return float64(l.StudentsCount) * l.Hours()
+}
+
+// CreditsConsumed возвращает количество кредитов, списанных со студентов за занятие
+func (l *PayrollLesson) CreditsConsumed() int {
+	return l.CreditsCost * l.StudentsCount
+}
+
+// PayrollPeriod представляет закрытый расчетный период. Обе даты включительные
+type PayrollPeriod struct {
+	ID            uuid.UUID     `db:"id" json:"id"`
+	PeriodStart   time.Time     `db:"period_start" json:"period_start"`
+	PeriodEnd     time.Time     `db:"period_end" json:"period_end"`
+	ClosedBy      uuid.NullUUID `db:"closed_by" json:"closed_by,omitempty"`
+	ClosedAt      time.Time     `db:"closed_at" json:"closed_at"`
+	TeachersCount int           `db:"teachers_count" json:"teachers_count"`
+	TotalAmount   float64       `db:"total_amount" json:"total_amount"`
+}
+
+// EarningsLine строка ведомости: оплата за одно занятие.
+// RateType пустой, если на дату занятия у преподавателя не было ставки
+type EarningsLine struct {
+	ID              uuid.UUID     `db:"id" json:"id"`
+	StatementID     uuid.UUID     `db:"statement_id" json:"-"`
+	LessonID        uuid.NullUUID `db:"lesson_id" json:"lesson_id,omitempty"`
+	StartTime       time.Time     `db:"start_time" json:"start_time"`
+	EndTime         time.Time     `db:"end_time" json:"end_time"`
+	Subject         string        `db:"subject" json:"subject"`
+	StudentsCount   int           `db:"students_count" json:"students_count"`
+	StudentHours    float64       `db:"student_hours" json:"student_hours"`
+	CreditsConsumed int           `db:"credits_consumed" json:"credits_consumed"`
+	RateType        *PayRateType  `db:"rate_type" json:"rate_type,omitempty"`
+	RateAmount      float64       `db:"rate_amount" json:"rate_amount"`
+	Amount          float64       `db:"amount" json:"amount"`
+}
+
+// EarningsAdjustment ручная корректировка выплаты: премия (положительная сумма) или удержание (отрицательная).
+// PeriodID пуст, пока период, в который попадает AppliesOn, не закрыт
+type EarningsAdjustment struct {
+	ID          uuid.UUID     `db:"id" json:"id"`
+	TeacherID   uuid.UUID     `db:"teacher_id" json:"teacher_id"`
+	TeacherName string        `db:"teacher_name" json:"teacher_name,omitempty"`
+	PeriodID    uuid.NullUUID `db:"period_id" json:"period_id,omitempty"`
+	Amount      float64       `db:"amount" json:"amount"`
+	Reason      string        `db:"reason" json:"reason"`
+	AppliesOn   time.Time     `db:"applies_on" json:"applies_on"`
+	CreatedBy   uuid.NullUUID `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+}
+
+// EarningsStatement ведомость начислений преподавателя за период.
+// Для закрытого периода хранится в БД, для открытого рассчитывается на лету (PeriodID пуст)
+type EarningsStatement struct {
+	ID                uuid.UUID             `db:"id" json:"id,omitempty"`
+	PeriodID          uuid.NullUUID         `db:"period_id" json:"period_id,omitempty"`
+	TeacherID         uuid.UUID             `db:"teacher_id" json:"teacher_id"`
+	TeacherName       string                `db:"teacher_name" json:"teacher_name"`
+	PeriodStart       time.Time             `db:"period_start" json:"period_start"`
+	PeriodEnd         time.Time             `db:"period_end" json:"period_end"`
+	LessonsCount      int                   `db:"lessons_count" json:"lessons_count"`
+	UnratedLessons    int                   `db:"unrated_lessons" json:"unrated_lessons"`
+	StudentHours      float64               `db:"student_hours" json:"student_hours"`
+	CreditsConsumed   int                   `db:"credits_consumed" json:"credits_consumed"`
+	LessonsAmount     float64               `db:"lessons_amount" json:"lessons_amount"`
+	AdjustmentsAmount float64               `db:"adjustments_amount" json:"adjustments_amount"`
+	TotalAmount       float64               `db:"total_amount" json:"total_amount"`
+	CreatedAt         time.Time             `db:"created_at" json:"created_at,omitempty"`
+	Lines             []*EarningsLine       `db:"-" json:"lines,omitempty"`
+	Adjustments       []*EarningsAdjustment `db:"-" json:"adjustments,omitempty"`
+}
+
+// RateForDate выбирает ставку, действующую на календарную дату day:
+// последнюю ставку с EffectiveFrom не позже day. rates должны относиться к одному преподавателю
+func RateForDate(rates []*TeacherPayRate, day time.Time) *TeacherPayRate {
+	day = civilDate(day)
+	var current *TeacherPayRate
+	for _, rate := range rates {
+		if civilDate(rate.EffectiveFrom).After(day) {
+			continue
+		}
+		if current == nil || rate.EffectiveFrom.After(current.EffectiveFrom) {
+			current = rate
+		}
+	}
+	return current
+}
+
+// BuildEarningsStatements рассчитывает ведомости преподавателей за период [periodStart, periodEnd]:
+// по каждому проведенному занятию применяется ставка на дату его начала, затем добавляются корректировки.
+// Ведомость формируется для каждого преподавателя, у которого есть занятия или корректировки
+func BuildEarningsStatements(periodStart, periodEnd time.Time, lessons []*PayrollLesson, rates []*TeacherPayRate, adjustments []*EarningsAdjustment) []*EarningsStatement {
+	ratesByTeacher := make(map[uuid.UUID][]*TeacherPayRate)
+	for _, rate := range rates {
+		ratesByTeacher[rate.TeacherID] = append(ratesByTeacher[rate.TeacherID], rate)
+	}
+
+	statements := make(map[uuid.UUID]*EarningsStatement)
+	statementFor := func(teacherID uuid.UUID, teacherName string) *EarningsStatement {
+		st, ok := statements[teacherID]
+		if !ok {
+			st = &EarningsStatement{
+				TeacherID:   teacherID,
+				PeriodStart: periodStart,
+				PeriodEnd:   periodEnd,
+				Lines:       []*EarningsLine{},
+				Adjustments: []*EarningsAdjustment{},
+			}
+			statements[teacherID] = st
+		}
+		if st.TeacherName == "" {
+			st.TeacherName = teacherName
+		}
+		return st
+	}
+
+	for _, lesson := range lessons {
+		st := statementFor(lesson.TeacherID, lesson.TeacherName)
+		line := &EarningsLine{
+			LessonID:        uuid.NullUUID{UUID: lesson.LessonID, Valid: true},
+			StartTime:       lesson.StartTime,
+			EndTime:         lesson.EndTime,
+			Subject:         lesson.Subject,
+			StudentsCount:   lesson.StudentsCount,
+			StudentHours:    roundMoney(lesson.StudentHours()),
+			CreditsConsumed: lesson.CreditsConsumed(),
+		}
+
+		if rate := RateForDate(ratesByTeacher[lesson.TeacherID], lesson.StartTime); rate != nil {
+			rateType := rate.RateType
+			line.RateType = &rateType
+			line.RateAmount = rate.Amount
+			line.Amount = rate.LessonAmount(lesson)
+		} else {
+			st.UnratedLessons++
+		}
+
+		st.Lines = append(st.Lines, line)
+		st.LessonsCount++
+		st.StudentHours += line.StudentHours
+		st.CreditsConsumed += line.CreditsConsumed
+		st.LessonsAmount += line.Amount
+	}
+
+	for _, adj := range adjustments {
+		st := statementFor(adj.TeacherID, adj.TeacherName)
+		st.Adjustments = append(st.Adjustments, adj)
+		st.AdjustmentsAmount += adj.Amount
+	}
+
+	result := make([]*EarningsStatement, 0, len(statements))
+	for _, st := range statements {
+		sort.Slice(st.Lines, func(i, j int) bool { return st.Lines[i].StartTime.Before(st.Lines[j].StartTime) })
+		st.StudentHours = roundMoney(st.StudentHours)
+		st.LessonsAmount = roundMoney(st.LessonsAmount)
+		st.AdjustmentsAmount = roundMoney(st.AdjustmentsAmount)
+		st.TotalAmount = roundMoney(st.LessonsAmount + st.AdjustmentsAmount)
+		result = append(result, st)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TeacherName != result[j].TeacherName {
+			return result[i].TeacherName < result[j].TeacherName
+		}
+		return result[i].TeacherID.String() < result[j].TeacherID.String()
+	})
+
+	return result
+}
+
+// PayrollPeriodDetails закрытый период с ведомостями преподавателей
+type PayrollPeriodDetails struct {
+	Period     *PayrollPeriod       `json:"period"`
+	Statements []*EarningsStatement `json:"statements"`
+}
+
+// TeacherEarnings сводка начислений для преподавателя: предварительный расчет текущего
+// незакрытого периода и зафиксированные ведомости прошлых периодов
+type TeacherEarnings struct {
+	Current    *EarningsStatement   `json:"current"`
+	Statements []*EarningsStatement `json:"statements"`
+}
+
+// PayrollExportFormat формат выгрузки ведомостей
+type PayrollExportFormat string
+
+const (
+	// PayrollExportCSV выгрузка в CSV (UTF-8, разделитель ;)
+	PayrollExportCSV PayrollExportFormat = "csv"
+	// PayrollExportXLSX выгрузка в XLSX
+	PayrollExportXLSX PayrollExportFormat = "xlsx"
+)
+
+// IsValid проверяет формат выгрузки
+func (f PayrollExportFormat) IsValid() bool {
+	return f == PayrollExportCSV || f == PayrollExportXLSX
+}
+
+// roundMoney округляет сумму до копеек
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// CreatePayRateRequest представляет запрос на установку ставки преподавателя
+type CreatePayRateRequest struct {
+	TeacherID     uuid.UUID   `json:"teacher_id"`
+	RateType      PayRateType `json:"rate_type"`
+	Amount        float64     `json:"amount"`
+	CreditValue   *float64    `json:"credit_value,omitempty"` // Optional: по умолчанию CreditPrice
+	EffectiveFrom string      `json:"effective_from"`         // YYYY-MM-DD
+}
+
+// Validate выполняет валидацию CreatePayRateRequest
+func (r *CreatePayRateRequest) Validate() error {
+	if r.TeacherID == uuid.Nil {
+		return ErrInvalidTeacherID
+	}
+	if !r.RateType.IsValid() {
+		return ErrInvalidPayRateType
+	}
+	if r.Amount <= 0 || (r.RateType == PayRateCreditPercentage && r.Amount > 100) {
+		return ErrInvalidPayRateAmount
+	}
+	if r.CreditValue != nil && *r.CreditValue <= 0 {
+		return ErrInvalidPayRateCreditValue
+	}
+	if _, err := time.Parse(CalendarDateLayout, r.EffectiveFrom); err != nil {
+		return ErrInvalidPayrollDates
+	}
+	return nil
+}
+
+// ToRate преобразует запрос в ставку (после Validate)
+func (r *CreatePayRateRequest) ToRate() *TeacherPayRate {
+	effectiveFrom, _ := time.Parse(CalendarDateLayout, r.EffectiveFrom)
+	creditValue := CreditPrice
+	if r.CreditValue != nil {
+		creditValue = *r.CreditValue
+	}
+	return &TeacherPayRate{
+		TeacherID:     r.TeacherID,
+		RateType:      r.RateType,
+		Amount:        r.Amount,
+		CreditValue:   creditValue,
+		EffectiveFrom: effectiveFrom,
+	}
+}
+
+// PayrollPeriodRequest задает расчетный период (обе даты включительные)
+type PayrollPeriodRequest struct {
+	PeriodStart string `json:"period_start"` // YYYY-MM-DD
+	PeriodEnd   string `json:"period_end"`   // YYYY-MM-DD
+}
+
+// Dates разбирает и проверяет даты периода
+func (r *PayrollPeriodRequest) Dates() (time.Time, time.Time, error) {
+	start, err := time.Parse(CalendarDateLayout, r.PeriodStart)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidPayrollDates
+	}
+	end, err := time.Parse(CalendarDateLayout, r.PeriodEnd)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidPayrollDates
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, ErrInvalidPayrollDates
+	}
+	if end.Sub(start) >= MaxPayrollPeriodDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrPayrollPeriodTooLong
+	}
+	return start, end, nil
+}
+
+// CreateEarningsAdjustmentRequest представляет запрос на создание корректировки выплаты
+type CreateEarningsAdjustmentRequest struct {
+	TeacherID uuid.UUID `json:"teacher_id"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	AppliesOn string    `json:"applies_on,omitempty"` // Optional: YYYY-MM-DD, по умолчанию сегодня
+}
+
+// Validate выполняет валидацию CreateEarningsAdjustmentRequest
+func (r *CreateEarningsAdjustmentRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+
+	if r.TeacherID == uuid.Nil {
+		return ErrInvalidTeacherID
+	}
+	if r.Amount == 0 || math.IsNaN(r.Amount) || math.IsInf(r.Amount, 0) {
+		return ErrInvalidAdjustmentAmount
+	}
+	if r.Reason == "" || len([]rune(r.Reason)) > MaxAdjustmentReasonLength {
+		return ErrInvalidAdjustmentReason
+	}
+	if r.AppliesOn != "" {
+		if _, err := time.Parse(CalendarDateLayout, r.AppliesOn); err != nil {
+			return ErrInvalidPayrollDates
+		}
+	}
+	return nil
+}
+
+// ToAdjustment преобразует запрос в корректировку (после Validate)
+func (r *CreateEarningsAdjustmentRequest) ToAdjustment(now time.Time) *EarningsAdjustment {
+	appliesOn := civilDate(now)
+	if r.AppliesOn != "" {
+		appliesOn, _ = time.Parse(CalendarDateLayout, r.AppliesOn)
+	}
+	return &EarningsAdjustment{
+		TeacherID: r.TeacherID,
+		Amount:    roundMoney(r.Amount),
+		Reason:    r.Reason,
+		AppliesOn: appliesOn,
+	}
+}
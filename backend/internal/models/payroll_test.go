@@ -0,0 +1,181 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTeacherPayRate_LessonAmount(t *testing.T) {
+	start := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	lesson := &PayrollLesson{
+		StartTime:     start,
+		EndTime:       start.Add(90 * time.Minute),
+		StudentsCount: 3,
+		CreditsCost:   1,
+	}
+
+	tests := []struct {
+		name string
+		rate TeacherPayRate
+		want float64
+	}{
+		{name: "per lesson", rate: TeacherPayRate{RateType: PayRatePerLesson, Amount: 1500}, want: 1500},
+		{name: "per student hour", rate: TeacherPayRate{RateType: PayRatePerStudentHour, Amount: 700}, want: 3150},
+		{name: "credit percentage", rate: TeacherPayRate{RateType: PayRateCreditPercentage, Amount: 40, CreditValue: 2800}, want: 3360},
+		{name: "rounded to kopecks", rate: TeacherPayRate{RateType: PayRateCreditPercentage, Amount: 33.333, CreditValue: 1000}, want: 999.99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rate.LessonAmount(lesson); got != tt.want {
+				t.Errorf("LessonAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateForDate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 0, 0, 0, 0, time.UTC) }
+	april := &TeacherPayRate{EffectiveFrom: day(1).AddDate(0, -1, 0), Amount: 1000}
+	mid := &TeacherPayRate{EffectiveFrom: day(15), Amount: 1200}
+	rates := []*TeacherPayRate{mid, april}
+
+	if got := RateForDate(rates, day(14).Add(23*time.Hour)); got != april {
+		t.Errorf("before change got %+v, want april rate", got)
+	}
+	if got := RateForDate(rates, day(15).Add(8*time.Hour)); got != mid {
+		t.Errorf("on change day got %+v, want new rate", got)
+	}
+	if got := RateForDate(rates, day(1).AddDate(0, -2, 0)); got != nil {
+		t.Errorf("before any rate got %+v, want nil", got)
+	}
+}
+
+func TestBuildEarningsStatements(t *testing.T) {
+	periodStart := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC)
+	anna, boris, vera := uuid.New(), uuid.New(), uuid.New()
+
+	lesson := func(teacher uuid.UUID, name string, d, students int) *PayrollLesson {
+		start := time.Date(2026, 5, d, 10, 0, 0, 0, time.UTC)
+		return &PayrollLesson{LessonID: uuid.New(), TeacherID: teacher, TeacherName: name,
+			StartTime: start, EndTime: start.Add(time.Hour), StudentsCount: students, CreditsCost: 1}
+	}
+
+	lessons := []*PayrollLesson{
+		lesson(anna, "Анна", 20, 1),
+		lesson(anna, "Анна", 5, 2),
+		lesson(boris, "Борис", 6, 4),
+	}
+	rates := []*TeacherPayRate{
+		{TeacherID: anna, RateType: PayRatePerLesson, Amount: 1000, EffectiveFrom: periodStart},
+		{TeacherID: anna, RateType: PayRatePerLesson, Amount: 1500, EffectiveFrom: periodStart.AddDate(0, 0, 14)},
+	}
+	adjustments := []*EarningsAdjustment{
+		{TeacherID: anna, TeacherName: "Анна", Amount: -200, Reason: "опоздание"},
+		{TeacherID: vera, TeacherName: "Вера", Amount: 500, Reason: "премия"},
+	}
+
+	statements := BuildEarningsStatements(periodStart, periodEnd, lessons, rates, adjustments)
+	if len(statements) != 3 {
+		t.Fatalf("got %d statements, want 3", len(statements))
+	}
+
+	a := statements[0]
+	if a.TeacherID != anna || a.LessonsCount != 2 || a.UnratedLessons != 0 {
+		t.Fatalf("anna statement = %+v", a)
+	}
+	if a.LessonsAmount != 2500 || a.AdjustmentsAmount != -200 || a.TotalAmount != 2300 {
+		t.Errorf("anna amounts = %v / %v / %v", a.LessonsAmount, a.AdjustmentsAmount, a.TotalAmount)
+	}
+	if a.StudentHours != 3 || a.CreditsConsumed != 3 {
+		t.Errorf("anna student hours / credits = %v / %d", a.StudentHours, a.CreditsConsumed)
+	}
+	if !a.Lines[0].StartTime.Before(a.Lines[1].StartTime) || a.Lines[0].Amount != 1000 || a.Lines[1].Amount != 1500 {
+		t.Errorf("anna lines not sorted or rated by date: %+v %+v", a.Lines[0], a.Lines[1])
+	}
+
+	b := statements[1]
+	if b.TeacherID != boris || b.UnratedLessons != 1 || b.TotalAmount != 0 || b.Lines[0].RateType != nil {
+		t.Errorf("boris statement without rate = %+v", b)
+	}
+
+	v := statements[2]
+	if v.TeacherID != vera || v.LessonsCount != 0 || v.TotalAmount != 500 || len(v.Adjustments) != 1 {
+		t.Errorf("vera adjustment-only statement = %+v", v)
+	}
+}
+
+func TestPayrollRequests_Validate(t *testing.T) {
+	teacher := uuid.New()
+	negative := -1.0
+
+	rateTests := []struct {
+		name    string
+		req     CreatePayRateRequest
+		wantErr error
+	}{
+		{name: "valid", req: CreatePayRateRequest{TeacherID: teacher, RateType: PayRatePerLesson, Amount: 1500, EffectiveFrom: "2026-05-01"}},
+		{name: "no teacher", req: CreatePayRateRequest{RateType: PayRatePerLesson, Amount: 1, EffectiveFrom: "2026-05-01"}, wantErr: ErrInvalidTeacherID},
+		{name: "bad type", req: CreatePayRateRequest{TeacherID: teacher, RateType: "hourly", Amount: 1, EffectiveFrom: "2026-05-01"}, wantErr: ErrInvalidPayRateType},
+		{name: "percent over 100", req: CreatePayRateRequest{TeacherID: teacher, RateType: PayRateCreditPercentage, Amount: 120, EffectiveFrom: "2026-05-01"}, wantErr: ErrInvalidPayRateAmount},
+		{name: "negative credit value", req: CreatePayRateRequest{TeacherID: teacher, RateType: PayRateCreditPercentage, Amount: 50, CreditValue: &negative, EffectiveFrom: "2026-05-01"}, wantErr: ErrInvalidPayRateCreditValue},
+		{name: "bad date", req: CreatePayRateRequest{TeacherID: teacher, RateType: PayRatePerLesson, Amount: 1, EffectiveFrom: "01.05.2026"}, wantErr: ErrInvalidPayrollDates},
+	}
+	for _, tt := range rateTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	rate := (&CreatePayRateRequest{TeacherID: teacher, RateType: PayRateCreditPercentage, Amount: 40, EffectiveFrom: "2026-05-01"}).ToRate()
+	if rate.CreditValue != CreditPrice || rate.EffectiveFrom.Day() != 1 {
+		t.Errorf("ToRate() = %+v", rate)
+	}
+
+	periodTests := []struct {
+		name    string
+		req     PayrollPeriodRequest
+		wantErr error
+	}{
+		{name: "month", req: PayrollPeriodRequest{PeriodStart: "2026-05-01", PeriodEnd: "2026-05-31"}},
+		{name: "single day", req: PayrollPeriodRequest{PeriodStart: "2026-05-01", PeriodEnd: "2026-05-01"}},
+		{name: "reversed", req: PayrollPeriodRequest{PeriodStart: "2026-05-31", PeriodEnd: "2026-05-01"}, wantErr: ErrInvalidPayrollDates},
+		{name: "too long", req: PayrollPeriodRequest{PeriodStart: "2026-01-01", PeriodEnd: "2026-06-30"}, wantErr: ErrPayrollPeriodTooLong},
+	}
+	for _, tt := range periodTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.req.Dates(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Dates() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	adjTests := []struct {
+		name    string
+		req     CreateEarningsAdjustmentRequest
+		wantErr error
+	}{
+		{name: "deduction", req: CreateEarningsAdjustmentRequest{TeacherID: teacher, Amount: -500, Reason: " штраф "}},
+		{name: "zero", req: CreateEarningsAdjustmentRequest{TeacherID: teacher, Amount: 0, Reason: "x"}, wantErr: ErrInvalidAdjustmentAmount},
+		{name: "no reason", req: CreateEarningsAdjustmentRequest{TeacherID: teacher, Amount: 1, Reason: "  "}, wantErr: ErrInvalidAdjustmentReason},
+		{name: "bad date", req: CreateEarningsAdjustmentRequest{TeacherID: teacher, Amount: 1, Reason: "x", AppliesOn: "2026-13-01"}, wantErr: ErrInvalidPayrollDates},
+	}
+	for _, tt := range adjTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	now := time.Date(2026, 5, 20, 18, 45, 0, 0, time.UTC)
+	adj := (&CreateEarningsAdjustmentRequest{TeacherID: teacher, Amount: 100.005, Reason: "премия"}).ToAdjustment(now)
+	if !adj.AppliesOn.Equal(time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC)) || adj.Amount != 100.01 {
+		t.Errorf("ToAdjustment() = %+v", adj)
+	}
+}
@@ -0,0 +1,246 @@
+package models
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Permission право доступа в формате "<область>.<действие>[.own|.all]".
+// Суффикс .own ограничивает действие собственными объектами (проверяется OwnershipChecker),
+// .all разрешает действие над любыми объектами
+type Permission string
+
+const (
+	PermUsersRead        Permission = "users.read"
+	PermUsersListAll     Permission = "users.list.all"
+	PermUsersCreate      Permission = "users.create"
+	PermUsersEdit        Permission = "users.edit"
+	PermUsersDelete      Permission = "users.delete"
+	PermUsersImpersonate Permission = "users.impersonate"
+
+	PermCreditsReadAll Permission = "credits.read.all"
+	PermCreditsGrant   Permission = "credits.grant"
+
+	PermSubjectsManage Permission = "subjects.manage"
+
+	PermLessonsCreate             Permission = "lessons.create"
+	PermLessonsEditOwn            Permission = "lessons.edit.own"
+	PermLessonsEditAll            Permission = "lessons.edit.all"
+	PermLessonsDeleteOwn          Permission = "lessons.delete.own"
+	PermLessonsDeleteAll          Permission = "lessons.delete.all"
+	PermLessonsBroadcastOwn       Permission = "lessons.broadcast.own"
+	PermLessonsBroadcastAll       Permission = "lessons.broadcast.all"
+	PermLessonModificationsRevert Permission = "lessons.modifications.revert"
+
+	PermBroadcastsManage    Permission = "broadcasts.manage"
+	PermTrialRequestsRead   Permission = "trial_requests.read"
+	PermTrialRequestsManage Permission = "trial_requests.manage"
+	PermCalendarManage      Permission = "calendar.manage"
+	PermAnalyticsRead       Permission = "analytics.read"
+	PermPayrollManage       Permission = "payroll.manage"
+	PermImportRun           Permission = "import.run"
+	PermAuditRead           Permission = "audit.read"
+	PermErasureReview       Permission = "erasure.review"
+	PermGroupsManage        Permission = "groups.manage"
+	PermSubscriptionsManage Permission = "subscriptions.manage"
+	PermPaymentsManage      Permission = "payments.manage"
+	PermChatReadAll         Permission = "chat.read.all"
+	PermChatChannelsManage  Permission = "chat.channels.manage"
+	PermModerationReview    Permission = "moderation.review"
+	PermStorageManage       Permission = "storage.manage"
+	PermTeachingSchedule    Permission = "teaching.schedule"
+	PermRolesManage         Permission = "roles.manage"
+)
+
+// PermissionDefinition описание права в реестре
+type PermissionDefinition struct {
+	Name        Permission `json:"name"`
+	Description string     `json:"description"`
+}
+
+// PermissionRegistry реестр всех прав системы. Права, которых нет в реестре,
+// нельзя назначить роли
+var PermissionRegistry = []PermissionDefinition{
+	{PermUsersRead, "Просмотр карточки пользователя"},
+	{PermUsersListAll, "Просмотр списка всех пользователей без фильтра по роли"},
+	{PermUsersCreate, "Создание пользователей"},
+	{PermUsersEdit, "Изменение пользователей и их привязки к Telegram"},
+	{PermUsersDelete, "Удаление пользователей"},
+	{PermUsersImpersonate, "Вход от имени пользователя"},
+	{PermCreditsReadAll, "Просмотр балансов всех пользователей"},
+	{PermCreditsGrant, "Начисление и списание кредитов"},
+	{PermSubjectsManage, "Управление предметами и их назначением преподавателям"},
+	{PermLessonsCreate, "Создание занятий"},
+	{PermLessonsEditOwn, "Изменение своих занятий"},
+	{PermLessonsEditAll, "Изменение любых занятий"},
+	{PermLessonsDeleteOwn, "Удаление своих занятий"},
+	{PermLessonsDeleteAll, "Удаление любых занятий"},
+	{PermLessonsBroadcastOwn, "Рассылка студентам своих занятий"},
+	{PermLessonsBroadcastAll, "Рассылка студентам любых занятий"},
+	{PermLessonModificationsRevert, "Просмотр и откат массовых изменений занятий"},
+	{PermBroadcastsManage, "Рассылки в Telegram, списки, шаблоны и аудитории"},
+	{PermTrialRequestsRead, "Просмотр заявок на пробное занятие"},
+	{PermTrialRequestsManage, "Обработка заявок на пробное занятие"},
+	{PermCalendarManage, "Управление академическим календарем"},
+	{PermAnalyticsRead, "Просмотр аналитики"},
+	{PermPayrollManage, "Расчет оплаты преподавателей"},
+	{PermImportRun, "Массовый импорт данных"},
+	{PermAuditRead, "Просмотр журнала аудита"},
+	{PermErasureReview, "Рассмотрение запросов на удаление персональных данных"},
+	{PermGroupsManage, "Управление учебными группами"},
+	{PermSubscriptionsManage, "Управление подписками и тарифами"},
+	{PermPaymentsManage, "Управление настройками платежей студентов"},
+	{PermChatReadAll, "Просмотр всех чатов"},
+	{PermChatChannelsManage, "Каналы объявлений и управление участниками групповых чатов"},
+	{PermModerationReview, "Очередь модерации, снятие и подтверждение блокировок сообщений"},
+	{PermStorageManage, "Использование хранилища файлов, квоты и очистка неиспользуемых файлов"},
+	{PermTeachingSchedule, "Расписание и начисления преподавателя"},
+	{PermRolesManage, "Управление ролями и правами"},
+}
+
+// IsKnown проверяет, что право есть в реестре
+func (p Permission) IsKnown() bool {
+	for _, def := range PermissionRegistry {
+		if def.Name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionSet набор прав роли
+type PermissionSet map[Permission]struct{}
+
+// NewPermissionSet создает набор из списка прав
+func NewPermissionSet(perms ...Permission) PermissionSet {
+	set := make(PermissionSet, len(perms))
+	for _, p := range perms {
+		set[p] = struct{}{}
+	}
+	return set
+}
+
+// Has проверяет наличие права в наборе
+func (s PermissionSet) Has(p Permission) bool {
+	_, ok := s[p]
+	return ok
+}
+
+// HasAny проверяет наличие хотя бы одного из прав
+func (s PermissionSet) HasAny(perms ...Permission) bool {
+	for _, p := range perms {
+		if s.Has(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains проверяет, что набор включает все права другого набора
+func (s PermissionSet) Contains(other PermissionSet) bool {
+	for p := range other {
+		if !s.Has(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// List возвращает права набора в отсортированном порядке
+func (s PermissionSet) List() []Permission {
+	list := make([]Permission, 0, len(s))
+	for p := range s {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
+
+// allPermissions возвращает все права реестра
+func allPermissions() []Permission {
+	perms := make([]Permission, 0, len(PermissionRegistry))
+	for _, def := range PermissionRegistry {
+		perms = append(perms, def.Name)
+	}
+	return perms
+}
+
+// DefaultRolePermissions права встроенных ролей. Совпадают с данными миграции и используются,
+// когда права из БД недоступны (например, в тестах обработчиков без middleware прав)
+var DefaultRolePermissions = map[UserRole]PermissionSet{
+	RoleAdmin: NewPermissionSet(withoutPermission(allPermissions(), PermTeachingSchedule)...),
+	RoleTeacher: NewPermissionSet(
+		PermUsersListAll,
+		PermCreditsReadAll,
+		PermSubjectsManage,
+		PermLessonsCreate,
+		PermLessonsEditOwn,
+		PermLessonsDeleteAll,
+		PermLessonsBroadcastOwn,
+		PermBroadcastsManage,
+		PermTrialRequestsRead,
+		PermTeachingSchedule,
+	),
+	RoleStudent: NewPermissionSet(),
+}
+
+// withoutPermission возвращает список прав без указанного
+func withoutPermission(perms []Permission, excluded Permission) []Permission {
+	result := make([]Permission, 0, len(perms))
+	for _, p := range perms {
+		if p != excluded {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// IsBuiltin проверяет, является ли роль встроенной (student, teacher, admin)
+func (r UserRole) IsBuiltin() bool {
+	return r == RoleStudent || r == RoleTeacher || r == RoleAdmin
+}
+
+// roleNamePattern допустимое имя пользовательской роли
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// MaxRoleDescriptionLength ограничивает длину описания роли
+const MaxRoleDescriptionLength = 500
+
+// Role роль с набором прав. Встроенные роли (is_system) нельзя удалить, а права admin - изменить
+type Role struct {
+	Name        UserRole     `db:"name" json:"name"`
+	Description string       `db:"description" json:"description"`
+	IsSystem    bool         `db:"is_system" json:"is_system"`
+	UsersCount  int          `db:"users_count" json:"users_count"`
+	Permissions []Permission `db:"-" json:"permissions"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`
+}
+
+// RoleRequest запрос на создание или изменение роли (имя при изменении берется из URL)
+type RoleRequest struct {
+	Name        UserRole     `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Validate проверяет имя, описание и права роли, убирая повторы прав
+func (r *RoleRequest) Validate() error {
+	r.Name = UserRole(strings.TrimSpace(string(r.Name)))
+	r.Description = strings.TrimSpace(r.Description)
+	if !roleNamePattern.MatchString(string(r.Name)) || len([]rune(r.Description)) > MaxRoleDescriptionLength {
+		return ErrInvalidRoleRequest
+	}
+
+	set := make(PermissionSet, len(r.Permissions))
+	for _, p := range r.Permissions {
+		if !p.IsKnown() {
+			return ErrUnknownPermission
+		}
+		set[p] = struct{}{}
+	}
+	r.Permissions = set.List()
+	return nil
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoleRequestValidate(t *testing.T) {
+	req := &RoleRequest{
+		Name:        " manager ",
+		Description: "  Менеджер  ",
+		Permissions: []Permission{PermUsersRead, PermAnalyticsRead, PermUsersRead},
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Name != "manager" || req.Description != "Менеджер" {
+		t.Errorf("Name/Description = %q/%q, want trimmed", req.Name, req.Description)
+	}
+	want := []Permission{PermAnalyticsRead, PermUsersRead}
+	if !reflect.DeepEqual(req.Permissions, want) {
+		t.Errorf("Permissions = %v, want %v", req.Permissions, want)
+	}
+
+	invalid := []*RoleRequest{
+		{Name: ""},
+		{Name: "m"},
+		{Name: "Manager"},
+		{Name: "1manager"},
+		{Name: "head-teacher"},
+		{Name: "manager", Description: strings.Repeat("я", MaxRoleDescriptionLength+1)},
+	}
+	for _, req := range invalid {
+		if err := req.Validate(); !errors.Is(err, ErrInvalidRoleRequest) {
+			t.Errorf("Validate(%+v) error = %v, want ErrInvalidRoleRequest", req, err)
+		}
+	}
+
+	req = &RoleRequest{Name: "manager", Permissions: []Permission{"users.fly"}}
+	if err := req.Validate(); !errors.Is(err, ErrUnknownPermission) {
+		t.Errorf("Validate(unknown permission) error = %v, want ErrUnknownPermission", err)
+	}
+}
+
+func TestPermissionSet(t *testing.T) {
+	set := NewPermissionSet(PermLessonsEditOwn, PermLessonsCreate)
+	if !set.Has(PermLessonsCreate) || set.Has(PermLessonsEditAll) {
+		t.Errorf("Has() mismatch for %v", set.List())
+	}
+	if !set.HasAny(PermLessonsEditAll, PermLessonsEditOwn) {
+		t.Error("HasAny() = false, want true")
+	}
+	if set.HasAny() || set.HasAny(PermRolesManage) {
+		t.Error("HasAny() = true, want false")
+	}
+	if !set.Contains(NewPermissionSet(PermLessonsCreate)) || !set.Contains(NewPermissionSet()) {
+		t.Error("Contains(subset) = false, want true")
+	}
+	if set.Contains(NewPermissionSet(PermLessonsCreate, PermRolesManage)) {
+		t.Error("Contains(superset) = true, want false")
+	}
+	if got := set.List(); !reflect.DeepEqual(got, []Permission{PermLessonsCreate, PermLessonsEditOwn}) {
+		t.Errorf("List() = %v, want sorted", got)
+	}
+}
+
+func TestDefaultRolePermissions(t *testing.T) {
+	for role, set := range DefaultRolePermissions {
+		if !role.IsBuiltin() {
+			t.Errorf("role %q is not builtin", role)
+		}
+		for p := range set {
+			if !p.IsKnown() {
+				t.Errorf("role %q has unregistered permission %q", role, p)
+			}
+		}
+	}
+
+	admin := DefaultRolePermissions[RoleAdmin]
+	if !admin.Has(PermRolesManage) || admin.Has(PermTeachingSchedule) {
+		t.Error("admin must manage roles and must not have the teacher schedule")
+	}
+	teacher := DefaultRolePermissions[RoleTeacher]
+	if teacher.Has(PermLessonsEditAll) || !teacher.Has(PermLessonsEditOwn) {
+		t.Error("teacher must edit only own lessons")
+	}
+	if len(DefaultRolePermissions[RoleStudent]) != 0 {
+		t.Error("student must have no permissions")
+	}
+	if UserRole("manager").IsBuiltin() {
+		t.Error("custom role reported as builtin")
+	}
+}
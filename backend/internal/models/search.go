@@ -0,0 +1,102 @@
+package models
+
+import (
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Типы результатов полнотекстового поиска
+const (
+	SearchTypeMessages = "messages"
+	SearchTypeHomework = "homework"
+	SearchTypeReports  = "reports"
+)
+
+const (
+	// MinSearchQueryLength минимальная длина поискового запроса (в символах)
+	MinSearchQueryLength = 2
+	// MaxSearchQueryLength максимальная длина поискового запроса (в символах)
+	MaxSearchQueryLength = 200
+	// DefaultSearchLimit количество результатов по умолчанию
+	DefaultSearchLimit = 20
+	// MaxSearchLimit максимальное количество результатов за один запрос
+	MaxSearchLimit = 50
+
+	// SearchHighlightStart и SearchHighlightStop маркеры совпадений, которые PostgreSQL
+	// расставляет в ts_headline. Перед отдачей клиенту фрагмент экранируется, а маркеры
+	// заменяются на <mark>, чтобы текст пользователей не мог внедрить разметку
+	SearchHighlightStart = "\x02"
+	SearchHighlightStop  = "\x03"
+)
+
+// SearchTypes все типы результатов поиска
+var SearchTypes = []string{SearchTypeMessages, SearchTypeHomework, SearchTypeReports}
+
+// SearchQuery параметры поиска
+type SearchQuery struct {
+	Query string
+	Type  string
+	Limit int
+}
+
+// Validate нормализует запрос и проверяет тип и лимит. Пустой тип означает поиск по всем типам
+func (q *SearchQuery) Validate() error {
+	q.Query = strings.TrimSpace(q.Query)
+	if n := utf8.RuneCountInString(q.Query); n < MinSearchQueryLength || n > MaxSearchQueryLength {
+		return ErrInvalidSearchQuery
+	}
+	if q.Type != "" && !IsValidSearchType(q.Type) {
+		return ErrInvalidSearchQuery
+	}
+	if q.Limit < 0 || q.Limit > MaxSearchLimit {
+		return ErrInvalidSearchQuery
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	return nil
+}
+
+// Types возвращает типы результатов, по которым выполняется поиск
+func (q *SearchQuery) Types() []string {
+	if q.Type == "" {
+		return SearchTypes
+	}
+	return []string{q.Type}
+}
+
+// IsValidSearchType проверяет, что searchType - известный тип результатов
+func IsValidSearchType(searchType string) bool {
+	for _, t := range SearchTypes {
+		if t == searchType {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchResult результат поиска: сообщение чата, домашнее задание или отчет по занятию.
+// Snippet - фрагмент текста с совпадениями, выделенными <mark>
+type SearchResult struct {
+	Type     string        `db:"type" json:"type"`
+	ID       uuid.UUID     `db:"id" json:"id"`
+	RoomID   uuid.NullUUID `db:"room_id" json:"room_id"`
+	LessonID uuid.NullUUID `db:"lesson_id" json:"lesson_id"`
+	Title    string        `db:"title" json:"title"`
+	Snippet  string        `db:"snippet" json:"snippet"`
+	Rank     float64       `db:"rank" json:"rank"`
+	Date     time.Time     `db:"date" json:"date"`
+}
+
+// HighlightSnippet экранирует HTML во фрагменте и заменяет маркеры совпадений на <mark>
+func (r *SearchResult) HighlightSnippet() {
+	escaped := html.EscapeString(r.Snippet)
+	r.Snippet = strings.NewReplacer(
+		SearchHighlightStart, "<mark>",
+		SearchHighlightStop, "</mark>",
+	).Replace(escaped)
+}
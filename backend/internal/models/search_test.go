@@ -0,0 +1,53 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSearchQueryValidate(t *testing.T) {
+	q := &SearchQuery{Query: "  домашнее задание  "}
+	if err := q.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if q.Query != "домашнее задание" || q.Limit != DefaultSearchLimit {
+		t.Errorf("Validate() = %+v, want trimmed query and default limit", q)
+	}
+	if types := q.Types(); len(types) != len(SearchTypes) {
+		t.Errorf("Types() = %v, want all types", types)
+	}
+
+	reports := &SearchQuery{Query: "отчет", Type: SearchTypeReports, Limit: 5}
+	if err := reports.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if types := reports.Types(); len(types) != 1 || types[0] != SearchTypeReports {
+		t.Errorf("Types() = %v, want [reports]", types)
+	}
+
+	invalid := []*SearchQuery{
+		{Query: "я"},
+		{Query: strings.Repeat("я", MaxSearchQueryLength+1)},
+		{Query: "урок", Type: "lessons"},
+		{Query: "урок", Limit: MaxSearchLimit + 1},
+		{Query: "урок", Limit: -1},
+	}
+	for _, q := range invalid {
+		if err := q.Validate(); !errors.Is(err, ErrInvalidSearchQuery) {
+			t.Errorf("Validate(%q, type %q, limit %d) error = %v, want ErrInvalidSearchQuery", q.Query, q.Type, q.Limit, err)
+		}
+	}
+}
+
+func TestSearchResultHighlightSnippet(t *testing.T) {
+	result := &SearchResult{
+		Snippet: "<script>x</script> решить " + SearchHighlightStart + "задачи" + SearchHighlightStop + " & повторить",
+	}
+	result.HighlightSnippet()
+
+	want := "&lt;script&gt;x&lt;/script&gt; решить <mark>задачи</mark> &amp; повторить"
+	if result.Snippet != want {
+		t.Errorf("HighlightSnippet() = %q, want %q", result.Snippet, want)
+	}
+}
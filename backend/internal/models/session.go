@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,6 +29,10 @@ type Session struct {
 	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
 	IPAddress string    `db:"ip_address" json:"ip_address,omitempty"`
 	UserAgent string    `db:"user_agent" json:"user_agent,omitempty"`
+	// ImpersonatorID администратор, открывший сессию от имени пользователя (NULL для обычных сессий)
+	ImpersonatorID uuid.NullUUID `db:"impersonator_id" json:"impersonator_id,omitempty"`
+	// ReadOnly запрещает изменяющие запросы в сессии
+	ReadOnly bool `db:"read_only" json:"read_only"`
 }
 
 // SessionWithUser представляет сессию с информацией о пользователе
@@ -36,6 +41,8 @@ type SessionWithUser struct {
 	UserEmail string   `db:"user_email" json:"user_email"`
 	UserName  string   `db:"user_name" json:"user_name"`
 	UserRole  UserRole `db:"user_role" json:"user_role"`
+	// ImpersonatorName имя администратора для сессий имперсонации
+	ImpersonatorName string `db:"impersonator_name" json:"impersonator_name,omitempty"`
 }
 
 // CreateSessionRequest представляет запрос на создание новой сессии
@@ -60,3 +67,68 @@ func (s *Session) IsValid() bool {
 func (s *Session) TimeUntilExpiry() time.Duration {
 	return time.Until(s.ExpiresAt)
 }
+
+// IsImpersonation проверяет, открыта ли сессия администратором от имени пользователя
+func (s *Session) IsImpersonation() bool {
+	return s.ImpersonatorID.Valid
+}
+
+const (
+	// DefaultImpersonationDuration длительность сессии имперсонации по умолчанию
+	DefaultImpersonationDuration = 30 * time.Minute
+	// MaxImpersonationDuration максимальная длительность сессии имперсонации
+	MaxImpersonationDuration = time.Hour
+	// MaxImpersonationReasonLength ограничивает длину причины имперсонации
+	MaxImpersonationReasonLength = 500
+)
+
+// StartImpersonationRequest запрос администратора на вход от имени пользователя
+type StartImpersonationRequest struct {
+	// DurationMinutes длительность сессии в минутах (0 - по умолчанию 30)
+	DurationMinutes int `json:"duration_minutes"`
+	// AllowWrite разрешает изменяющие запросы (по умолчанию сессия только для чтения)
+	AllowWrite bool `json:"allow_write"`
+	// Reason причина входа от имени пользователя, попадает в журнал аудита
+	Reason string `json:"reason"`
+}
+
+// Validate проверяет запрос на имперсонацию
+func (r *StartImpersonationRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if len([]rune(r.Reason)) > MaxImpersonationReasonLength {
+		return ErrInvalidImpersonationRequest
+	}
+	if r.DurationMinutes < 0 || time.Duration(r.DurationMinutes)*time.Minute > MaxImpersonationDuration {
+		return ErrInvalidImpersonationRequest
+	}
+	return nil
+}
+
+// Duration возвращает длительность сессии имперсонации
+func (r *StartImpersonationRequest) Duration() time.Duration {
+	if r.DurationMinutes == 0 {
+		return DefaultImpersonationDuration
+	}
+	return time.Duration(r.DurationMinutes) * time.Minute
+}
+
+// ImpersonationInfo сведения о текущей имперсонации для GET /auth/me
+type ImpersonationInfo struct {
+	ImpersonatorID   uuid.UUID `json:"impersonator_id"`
+	ImpersonatorName string    `json:"impersonator_name"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	ReadOnly         bool      `json:"read_only"`
+}
+
+// ImpersonationInfo возвращает сведения об имперсонации или nil для обычной сессии
+func (s *SessionWithUser) ImpersonationInfo() *ImpersonationInfo {
+	if !s.IsImpersonation() {
+		return nil
+	}
+	return &ImpersonationInfo{
+		ImpersonatorID:   s.ImpersonatorID.UUID,
+		ImpersonatorName: s.ImpersonatorName,
+		ExpiresAt:        s.ExpiresAt,
+		ReadOnly:         s.ReadOnly,
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestStartImpersonationRequestValidate(t *testing.T) {
+	req := &StartImpersonationRequest{Reason: "  проверка расписания  "}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Reason != "проверка расписания" {
+		t.Errorf("Reason = %q, want trimmed", req.Reason)
+	}
+	if req.Duration() != DefaultImpersonationDuration {
+		t.Errorf("Duration() = %v, want default %v", req.Duration(), DefaultImpersonationDuration)
+	}
+
+	req = &StartImpersonationRequest{DurationMinutes: 60}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate(60 min) error = %v", err)
+	}
+	if req.Duration() != time.Hour {
+		t.Errorf("Duration() = %v, want 1h", req.Duration())
+	}
+
+	invalid := []*StartImpersonationRequest{
+		{DurationMinutes: -1},
+		{DurationMinutes: 61},
+		{Reason: strings.Repeat("я", MaxImpersonationReasonLength+1)},
+	}
+	for _, req := range invalid {
+		if err := req.Validate(); !errors.Is(err, ErrInvalidImpersonationRequest) {
+			t.Errorf("Validate(%+v) error = %v, want ErrInvalidImpersonationRequest", req, err)
+		}
+	}
+}
+
+func TestSessionImpersonationInfo(t *testing.T) {
+	session := &SessionWithUser{Session: Session{UserID: uuid.New()}}
+	if session.IsImpersonation() || session.ImpersonationInfo() != nil {
+		t.Fatal("regular session must not be marked as impersonation")
+	}
+
+	adminID := uuid.New()
+	expiresAt := time.Now().Add(DefaultImpersonationDuration)
+	session.ImpersonatorID = uuid.NullUUID{UUID: adminID, Valid: true}
+	session.ImpersonatorName = "Анна Админ"
+	session.ExpiresAt = expiresAt
+	session.ReadOnly = true
+
+	info := session.ImpersonationInfo()
+	if info == nil {
+		t.Fatal("ImpersonationInfo() = nil for impersonation session")
+	}
+	if info.ImpersonatorID != adminID || info.ImpersonatorName != "Анна Админ" ||
+		!info.ExpiresAt.Equal(expiresAt) || !info.ReadOnly {
+		t.Errorf("ImpersonationInfo() = %+v", info)
+	}
+}
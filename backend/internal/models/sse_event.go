@@ -11,6 +11,10 @@ const (
 	SSEEventNewMessage           = "new_message"
 	SSEEventMessageDeleted       = "message_deleted"
 	SSEEventMessageStatusUpdated = "message_status_updated"
+	SSEEventMessageRead          = "message_read"
+	SSEEventTyping               = "typing"
+	SSEEventMessageEdited        = "message_edited"
+	SSEEventReactionUpdated      = "reaction_updated"
 )
 
 type SSEEvent struct {
@@ -43,6 +47,32 @@ type MessageStatusUpdatedPayload struct {
 	Status    string    `json:"status"`
 }
 
+type MessageReadPayload struct {
+	ChatID    uuid.UUID `json:"chat_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	MessageID uuid.UUID `json:"message_id"`
+	ReadAt    time.Time `json:"read_at"`
+}
+
+type TypingPayload struct {
+	ChatID   uuid.UUID `json:"chat_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	UserName string    `json:"user_name"`
+}
+
+type MessageEditedPayload struct {
+	ChatID      uuid.UUID `json:"chat_id"`
+	MessageID   uuid.UUID `json:"message_id"`
+	MessageText string    `json:"message_text"`
+	EditedAt    time.Time `json:"edited_at"`
+}
+
+type ReactionUpdatedPayload struct {
+	ChatID    uuid.UUID                `json:"chat_id"`
+	MessageID uuid.UUID                `json:"message_id"`
+	Reactions []MessageReactionSummary `json:"reactions"`
+}
+
 func NewMessageEvent(chatID uuid.UUID, message *ChatMessageWithSender) SSEEvent {
 	return SSEEvent{
 		Type: SSEEventNewMessage,
@@ -98,6 +128,52 @@ func MessageStatusUpdatedEvent(chatID uuid.UUID, messageID uuid.UUID, status str
 	}
 }
 
+func MessageReadEvent(state *ChatReadState) SSEEvent {
+	return SSEEvent{
+		Type: SSEEventMessageRead,
+		Data: MessageReadPayload{
+			ChatID:    state.RoomID,
+			UserID:    state.UserID,
+			MessageID: state.LastReadMessageID.UUID,
+			ReadAt:    state.ReadAt,
+		},
+	}
+}
+
+func TypingEvent(chatID, userID uuid.UUID, userName string) SSEEvent {
+	return SSEEvent{
+		Type: SSEEventTyping,
+		Data: TypingPayload{
+			ChatID:   chatID,
+			UserID:   userID,
+			UserName: userName,
+		},
+	}
+}
+
+func MessageEditedEvent(message *Message) SSEEvent {
+	return SSEEvent{
+		Type: SSEEventMessageEdited,
+		Data: MessageEditedPayload{
+			ChatID:      message.RoomID,
+			MessageID:   message.ID,
+			MessageText: message.MessageText,
+			EditedAt:    message.EditedAt.Time,
+		},
+	}
+}
+
+func ReactionUpdatedEvent(chatID, messageID uuid.UUID, reactions []MessageReactionSummary) SSEEvent {
+	return SSEEvent{
+		Type: SSEEventReactionUpdated,
+		Data: ReactionUpdatedPayload{
+			ChatID:    chatID,
+			MessageID: messageID,
+			Reactions: reactions,
+		},
+	}
+}
+
 func (e *SSEEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Источники загруженных файлов; совпадают с префиксами ключей в BlobStore
+const (
+	StoredFileSourceChat             = "chat"
+	StoredFileSourceHomework         = "homework"
+	StoredFileSourceLessonBroadcasts = "lesson_broadcasts"
+	StoredFileSourceBroadcasts       = "broadcasts"
+)
+
+// ThumbnailPrefix префикс ключей BlobStore для превью изображений и PDF
+const ThumbnailPrefix = "thumbnails"
+
+// QuarantinePrefix префикс ключей BlobStore для файлов, отклоненных антивирусной проверкой
+const QuarantinePrefix = "quarantine"
+
+// StoredFileSources все источники загруженных файлов
+var StoredFileSources = []string{
+	StoredFileSourceChat,
+	StoredFileSourceHomework,
+	StoredFileSourceLessonBroadcasts,
+	StoredFileSourceBroadcasts,
+}
+
+// LegacyFile запись о файле, загруженном до перехода на BlobStore (storage_key IS NULL).
+// FilePath - путь на локальном диске в том виде, в каком его сохранил исходный обработчик загрузки
+type LegacyFile struct {
+	Source   string    `db:"-"`
+	ID       uuid.UUID `db:"id"`
+	FileName string    `db:"file_name"`
+	FilePath string    `db:"file_path"`
+	MimeType string    `db:"mime_type"`
+}
+
+// QuarantinedFile загрузка, отклоненная антивирусной проверкой. Содержимое хранится в BlobStore
+// под префиксом QuarantinePrefix и пользователям не выдается
+type QuarantinedFile struct {
+	ID          uuid.UUID      `db:"id" json:"id"`
+	Source      string         `db:"source" json:"source"`
+	FileName    string         `db:"file_name" json:"file_name"`
+	MimeType    string         `db:"mime_type" json:"mime_type"`
+	FileSize    int64          `db:"file_size" json:"file_size"`
+	ContentHash string         `db:"content_hash" json:"content_hash"`
+	StorageKey  sql.NullString `db:"storage_key" json:"-"`
+	Signature   string         `db:"signature" json:"signature"`
+	Scanner     string         `db:"scanner" json:"scanner"`
+	UploadedBy  uuid.NullUUID  `db:"uploaded_by" json:"uploaded_by"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+}
+
+// QuarantineFilter параметры страницы списка карантина
+type QuarantineFilter struct {
+	Limit  int
+	Offset int
+}
+
+// Validate проверяет параметры страницы и подставляет размер страницы по умолчанию
+func (f *QuarantineFilter) Validate() error {
+	if f.Limit < 0 || f.Limit > MaxModerationPageSize || f.Offset < 0 {
+		return ErrInvalidQuarantineFilter
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultModerationPageSize
+	}
+	return nil
+}
+
+// Группировка отчета об использовании хранилища
+const (
+	StorageUsageByUser    = "user"    // по пользователю, загрузившему файлы
+	StorageUsageByTeacher = "teacher" // по преподавателю занятия или личного чата, к которому относятся файлы
+)
+
+// StorageUsage использование хранилища одним пользователем: число и суммарный размер файлов,
+// записи о которых не удалены. Одинаковые файлы учитываются каждый раз (как загружены), без учета дедупликации
+type StorageUsage struct {
+	UserID        uuid.UUID     `db:"user_id" json:"user_id"`
+	FirstName     string        `db:"first_name" json:"first_name"`
+	LastName      string        `db:"last_name" json:"last_name"`
+	Role          string        `db:"role" json:"role"`
+	FileCount     int           `db:"file_count" json:"file_count"`
+	TotalBytes    int64         `db:"total_bytes" json:"total_bytes"`
+	QuotaOverride sql.NullInt64 `db:"quota_bytes" json:"-"`
+	// QuotaBytes действующая квота пользователя; nil - без ограничения
+	QuotaBytes *int64 `db:"-" json:"quota_bytes"`
+}
+
+// StorageUsageFilter параметры отчета об использовании хранилища
+type StorageUsageFilter struct {
+	GroupBy string
+	Limit   int
+	Offset  int
+}
+
+// Validate проверяет параметры отчета и подставляет группировку и размер страницы по умолчанию
+func (f *StorageUsageFilter) Validate() error {
+	switch f.GroupBy {
+	case "":
+		f.GroupBy = StorageUsageByUser
+	case StorageUsageByUser, StorageUsageByTeacher:
+	default:
+		return ErrInvalidStorageUsageFilter
+	}
+	if f.Limit < 0 || f.Limit > MaxModerationPageSize || f.Offset < 0 {
+		return ErrInvalidStorageUsageFilter
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultModerationPageSize
+	}
+	return nil
+}
+
+// StorageQuotaStatus занятое место и квота пользователя
+type StorageQuotaStatus struct {
+	UserID    uuid.UUID `json:"user_id"`
+	UsedBytes int64     `json:"used_bytes"`
+	// QuotaBytes действующая квота; nil - без ограничения
+	QuotaBytes *int64 `json:"quota_bytes"`
+	// Override квота задана пользователю индивидуально, а не взята из настроек по умолчанию
+	Override bool `json:"override"`
+}
+
+// SetStorageQuotaRequest запрос на установку индивидуальной квоты пользователя
+type SetStorageQuotaRequest struct {
+	QuotaBytes *int64 `json:"quota_bytes"`
+}
+
+// Validate проверяет, что квота задана и не отрицательна
+func (r *SetStorageQuotaRequest) Validate() error {
+	if r.QuotaBytes == nil || *r.QuotaBytes < 0 {
+		return ErrInvalidStorageQuota
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStorageUsageFilterValidate(t *testing.T) {
+	filter := &StorageUsageFilter{}
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if filter.GroupBy != StorageUsageByUser {
+		t.Errorf("GroupBy = %q, want default %q", filter.GroupBy, StorageUsageByUser)
+	}
+	if filter.Limit != DefaultModerationPageSize {
+		t.Errorf("Limit = %d, want default %d", filter.Limit, DefaultModerationPageSize)
+	}
+
+	invalid := []*StorageUsageFilter{
+		{GroupBy: "room"},
+		{Limit: MaxModerationPageSize + 1},
+		{Limit: -1},
+		{Offset: -1},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); !errors.Is(err, ErrInvalidStorageUsageFilter) {
+			t.Errorf("Validate(%+v) error = %v, want ErrInvalidStorageUsageFilter", f, err)
+		}
+	}
+}
+
+func TestSetStorageQuotaRequestValidate(t *testing.T) {
+	zero, negative := int64(0), int64(-1)
+	if err := (&SetStorageQuotaRequest{QuotaBytes: &zero}).Validate(); err != nil {
+		t.Errorf("zero quota must be allowed, got %v", err)
+	}
+	for _, req := range []*SetStorageQuotaRequest{{}, {QuotaBytes: &negative}} {
+		if err := req.Validate(); !errors.Is(err, ErrInvalidStorageQuota) {
+			t.Errorf("Validate(%+v) error = %v, want ErrInvalidStorageQuota", req, err)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionStatus определяет статус подписки
+type SubscriptionStatus string
+
+const (
+	// SubscriptionStatusPending подписка ожидает первой оплаты
+	SubscriptionStatusPending SubscriptionStatus = "pending"
+	// SubscriptionStatusActive подписка оплачена за текущий период
+	SubscriptionStatusActive SubscriptionStatus = "active"
+	// SubscriptionStatusPastDue продление не удалось, идут повторные попытки списания
+	SubscriptionStatusPastDue SubscriptionStatus = "past_due"
+	// SubscriptionStatusCancelled подписка отменена пользователем или администратором
+	SubscriptionStatusCancelled SubscriptionStatus = "cancelled"
+	// SubscriptionStatusExpired повторные попытки списания исчерпаны или первая оплата не прошла
+	SubscriptionStatusExpired SubscriptionStatus = "expired"
+)
+
+// SubscriptionInvoiceKind определяет тип счёта подписки
+type SubscriptionInvoiceKind string
+
+const (
+	// SubscriptionInvoiceInitial первый платёж с сохранением способа оплаты
+	SubscriptionInvoiceInitial SubscriptionInvoiceKind = "initial"
+	// SubscriptionInvoiceRenewal автоматическое продление сохранённым способом оплаты
+	SubscriptionInvoiceRenewal SubscriptionInvoiceKind = "renewal"
+	// SubscriptionInvoiceProration доплата за переход на более дорогой тариф в середине периода
+	SubscriptionInvoiceProration SubscriptionInvoiceKind = "proration"
+)
+
+// SubscriptionInvoiceStatus определяет статус счёта подписки
+type SubscriptionInvoiceStatus string
+
+const (
+	// SubscriptionInvoicePending счёт ожидает оплаты
+	SubscriptionInvoicePending SubscriptionInvoiceStatus = "pending"
+	// SubscriptionInvoiceSucceeded счёт оплачен, кредиты начислены
+	SubscriptionInvoiceSucceeded SubscriptionInvoiceStatus = "succeeded"
+	// SubscriptionInvoiceFailed оплата отклонена
+	SubscriptionInvoiceFailed SubscriptionInvoiceStatus = "failed"
+)
+
+// SubscriptionDunningSchedule задаёт задержки повторных попыток списания после неудачного продления
+// После исчерпания расписания подписка переходит в статус expired
+var SubscriptionDunningSchedule = []time.Duration{
+	24 * time.Hour,
+	72 * time.Hour,
+	120 * time.Hour,
+}
+
+// SubscriptionPlan представляет тариф подписки: сколько кредитов начисляется за период и за какую цену
+type SubscriptionPlan struct {
+	ID               uuid.UUID `db:"id" json:"id"`
+	Name             string    `db:"name" json:"name"`
+	Description      string    `db:"description" json:"description"`
+	CreditsPerPeriod int       `db:"credits_per_period" json:"credits_per_period"`
+	Price            float64   `db:"price" json:"price"`
+	PeriodMonths     int       `db:"period_months" json:"period_months"`
+	IsActive         bool      `db:"is_active" json:"is_active"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Subscription представляет подписку пользователя на тариф
+type Subscription struct {
+	ID                 uuid.UUID          `db:"id" json:"id"`
+	UserID             uuid.UUID          `db:"user_id" json:"user_id"`
+	PlanID             uuid.UUID          `db:"plan_id" json:"plan_id"`
+	PendingPlanID      uuid.NullUUID      `db:"pending_plan_id" json:"pending_plan_id,omitempty"`
+	Status             SubscriptionStatus `db:"status" json:"status"`
+	PaymentMethodID    sql.NullString     `db:"payment_method_id" json:"-"`
+	CurrentPeriodStart sql.NullTime       `db:"current_period_start" json:"current_period_start,omitempty"`
+	CurrentPeriodEnd   sql.NullTime       `db:"current_period_end" json:"current_period_end,omitempty"`
+	NextBillingAt      sql.NullTime       `db:"next_billing_at" json:"next_billing_at,omitempty"`
+	CancelAtPeriodEnd  bool               `db:"cancel_at_period_end" json:"cancel_at_period_end"`
+	CancelledAt        sql.NullTime       `db:"cancelled_at" json:"cancelled_at,omitempty"`
+	FailedAttempts     int                `db:"failed_attempts" json:"failed_attempts"`
+	LastFailureReason  string             `db:"last_failure_reason" json:"last_failure_reason,omitempty"`
+	CreatedAt          time.Time          `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time          `db:"updated_at" json:"updated_at"`
+}
+
+// SubscriptionWithPlan представляет подписку с параметрами текущего тарифа
+type SubscriptionWithPlan struct {
+	Subscription
+	PlanName         string  `db:"plan_name" json:"plan_name"`
+	CreditsPerPeriod int     `db:"credits_per_period" json:"credits_per_period"`
+	Price            float64 `db:"price" json:"price"`
+	UserEmail        string  `db:"user_email" json:"user_email,omitempty"`
+	HasPaymentMethod bool    `db:"has_payment_method" json:"has_payment_method"`
+}
+
+// SubscriptionInvoice представляет попытку списания по подписке
+type SubscriptionInvoice struct {
+	ID                uuid.UUID                 `db:"id" json:"id"`
+	SubscriptionID    uuid.UUID                 `db:"subscription_id" json:"subscription_id"`
+	UserID            uuid.UUID                 `db:"user_id" json:"user_id"`
+	PlanID            uuid.UUID                 `db:"plan_id" json:"plan_id"`
+	Kind              SubscriptionInvoiceKind   `db:"kind" json:"kind"`
+	PeriodStart       time.Time                 `db:"period_start" json:"period_start"`
+	PeriodEnd         time.Time                 `db:"period_end" json:"period_end"`
+	Amount            float64                   `db:"amount" json:"amount"`
+	Credits           int                       `db:"credits" json:"credits"`
+	Status            SubscriptionInvoiceStatus `db:"status" json:"status"`
+	Attempt           int                       `db:"attempt" json:"attempt"`
+	YooKassaPaymentID sql.NullString            `db:"yookassa_payment_id" json:"-"`
+	ConfirmationURL   sql.NullString            `db:"confirmation_url" json:"-"`
+	FailureReason     string                    `db:"failure_reason" json:"failure_reason,omitempty"`
+	ProcessedAt       sql.NullTime              `db:"processed_at" json:"processed_at,omitempty"`
+	CreatedAt         time.Time                 `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time                 `db:"updated_at" json:"updated_at"`
+}
+
+// CreateSubscriptionPlanRequest представляет запрос на создание тарифа
+type CreateSubscriptionPlanRequest struct {
+	Name             string  `json:"name"`
+	Description      string  `json:"description"`
+	CreditsPerPeriod int     `json:"credits_per_period"`
+	Price            float64 `json:"price"`
+	PeriodMonths     int     `json:"period_months"`
+}
+
+// UpdateSubscriptionPlanRequest представляет запрос на обновление тарифа
+// Изменение цены и кредитов действует для существующих подписок со следующего периода
+type UpdateSubscriptionPlanRequest struct {
+	Name             *string  `json:"name,omitempty"`
+	Description      *string  `json:"description,omitempty"`
+	CreditsPerPeriod *int     `json:"credits_per_period,omitempty"`
+	Price            *float64 `json:"price,omitempty"`
+	IsActive         *bool    `json:"is_active,omitempty"`
+}
+
+// SubscribeRequest представляет запрос на оформление подписки
+type SubscribeRequest struct {
+	PlanID uuid.UUID `json:"plan_id"`
+}
+
+// ChangeSubscriptionPlanRequest представляет запрос на смену тарифа подписки
+type ChangeSubscriptionPlanRequest struct {
+	PlanID uuid.UUID `json:"plan_id"`
+}
+
+// CancelSubscriptionRequest представляет запрос на отмену подписки
+// Immediately = false - подписка действует до конца оплаченного периода
+type CancelSubscriptionRequest struct {
+	Immediately bool `json:"immediately"`
+}
+
+// SubscribeResponse представляет ответ на оформление подписки
+type SubscribeResponse struct {
+	SubscriptionID  uuid.UUID `json:"subscription_id"`
+	InvoiceID       uuid.UUID `json:"invoice_id"`
+	Amount          float64   `json:"amount"`
+	ConfirmationURL string    `json:"confirmation_url"`
+}
+
+// ChangeSubscriptionPlanResponse представляет результат смены тарифа
+type ChangeSubscriptionPlanResponse struct {
+	Subscription    *SubscriptionWithPlan `json:"subscription"`
+	Immediate       bool                  `json:"immediate"`                 // true - тариф сменён сразу (повышение с доплатой)
+	ProratedAmount  float64               `json:"prorated_amount,omitempty"` // сумма доплаты
+	ProratedCredits int                   `json:"prorated_credits,omitempty"`
+	EffectiveFrom   *time.Time            `json:"effective_from,omitempty"` // начало действия нового тарифа при понижении
+}
+
+// Validate выполняет валидацию CreateSubscriptionPlanRequest
+func (r *CreateSubscriptionPlanRequest) Validate() error {
+	if err := validatePlanName(r.Name); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(r.Description) > 1000 {
+		return ErrPlanDescriptionTooLong
+	}
+	if r.CreditsPerPeriod < 1 || r.CreditsPerPeriod > 100 {
+		return ErrInvalidPlanCredits
+	}
+	if r.Price <= 0 {
+		return ErrInvalidPlanPrice
+	}
+	if r.PeriodMonths == 0 {
+		r.PeriodMonths = 1
+	}
+	if r.PeriodMonths < 1 || r.PeriodMonths > 12 {
+		return ErrInvalidPlanPeriod
+	}
+	return nil
+}
+
+// Validate выполняет валидацию UpdateSubscriptionPlanRequest
+func (r *UpdateSubscriptionPlanRequest) Validate() error {
+	if r.Name != nil {
+		if err := validatePlanName(*r.Name); err != nil {
+			return err
+		}
+	}
+	if r.Description != nil && utf8.RuneCountInString(*r.Description) > 1000 {
+		return ErrPlanDescriptionTooLong
+	}
+	if r.CreditsPerPeriod != nil && (*r.CreditsPerPeriod < 1 || *r.CreditsPerPeriod > 100) {
+		return ErrInvalidPlanCredits
+	}
+	if r.Price != nil && *r.Price <= 0 {
+		return ErrInvalidPlanPrice
+	}
+	return nil
+}
+
+// Validate выполняет валидацию SubscribeRequest
+func (r *SubscribeRequest) Validate() error {
+	if r.PlanID == uuid.Nil {
+		return ErrInvalidPlanID
+	}
+	return nil
+}
+
+// Validate выполняет валидацию ChangeSubscriptionPlanRequest
+func (r *ChangeSubscriptionPlanRequest) Validate() error {
+	if r.PlanID == uuid.Nil {
+		return ErrInvalidPlanID
+	}
+	return nil
+}
+
+// IsLive проверяет, что подписка ещё не завершена (ожидает оплаты, активна или в просрочке)
+func (s *Subscription) IsLive() bool {
+	return s.Status == SubscriptionStatusPending ||
+		s.Status == SubscriptionStatusActive ||
+		s.Status == SubscriptionStatusPastDue
+}
+
+// AddBillingPeriod возвращает конец периода длиной months месяцев, начинающегося в start
+// День месяца ограничивается последним днём целевого месяца (31 января + 1 месяц = 28/29 февраля),
+// чтобы период не "перескакивал" в следующий месяц, как time.AddDate
+func AddBillingPeriod(start time.Time, months int) time.Time {
+	year, month, day := start.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, start.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	hour, min, sec := start.Clock()
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day, hour, min, sec, start.Nanosecond(), start.Location())
+}
+
+// ProrationFraction возвращает неиспользованную долю периода [periodStart, periodEnd) на момент now (от 0 до 1)
+func ProrationFraction(periodStart, periodEnd, now time.Time) float64 {
+	total := periodEnd.Sub(periodStart)
+	if total <= 0 || !now.Before(periodEnd) {
+		return 0
+	}
+	if now.Before(periodStart) {
+		return 1
+	}
+	return float64(periodEnd.Sub(now)) / float64(total)
+}
+
+// ProrateUpgrade рассчитывает доплату и дополнительные кредиты при переходе с тарифа from на тариф to
+// в середине периода. Сумма округляется до копеек, кредиты - вниз до целого
+func ProrateUpgrade(from, to *SubscriptionPlan, fraction float64) (amount float64, credits int) {
+	if fraction <= 0 || to.Price <= from.Price {
+		return 0, 0
+	}
+	amount = math.Round((to.Price-from.Price)*fraction*100) / 100
+	if to.CreditsPerPeriod > from.CreditsPerPeriod {
+		credits = int(math.Floor(float64(to.CreditsPerPeriod-from.CreditsPerPeriod) * fraction))
+	}
+	return amount, credits
+}
+
+// DunningRetryDelay возвращает задержку до следующей попытки списания после failedAttempts неудачных попыток
+// ok = false означает, что попытки исчерпаны и подписку нужно завершить
+func DunningRetryDelay(failedAttempts int) (delay time.Duration, ok bool) {
+	if failedAttempts < 1 || failedAttempts > len(SubscriptionDunningSchedule) {
+		return 0, false
+	}
+	return SubscriptionDunningSchedule[failedAttempts-1], true
+}
+
+// SubscriptionCreditReason формирует причину начисления кредитов по подписке со ссылкой на период и счёт
+// Отличается от причин ручных начислений и разовых платежей, чтобы начисления по подписке можно было отфильтровать
+func SubscriptionCreditReason(planName string, invoice *SubscriptionInvoice) string {
+	prefix := "Подписка"
+	if invoice.Kind == SubscriptionInvoiceProration {
+		prefix = "Подписка (доплата за смену тарифа)"
+	}
+	return fmt.Sprintf("%s «%s»: период %s – %s (счёт #%s)",
+		prefix,
+		planName,
+		invoice.PeriodStart.Format("02.01.2006"),
+		invoice.PeriodEnd.Format("02.01.2006"),
+		invoice.ID.String(),
+	)
+}
+
+func validatePlanName(name string) error {
+	length := utf8.RuneCountInString(strings.TrimSpace(name))
+	if length < 1 || length > 200 {
+		return ErrInvalidPlanName
+	}
+	return nil
+}
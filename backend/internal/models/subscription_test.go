@@ -0,0 +1,200 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateSubscriptionPlanRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     CreateSubscriptionPlanRequest
+		wantErr error
+	}{
+		{
+			name: "valid",
+			req:  CreateSubscriptionPlanRequest{Name: "8 занятий в месяц", CreditsPerPeriod: 8, Price: 20000},
+		},
+		{
+			name:    "blank name",
+			req:     CreateSubscriptionPlanRequest{Name: " ", CreditsPerPeriod: 8, Price: 20000},
+			wantErr: ErrInvalidPlanName,
+		},
+		{
+			name:    "description too long",
+			req:     CreateSubscriptionPlanRequest{Name: "x", Description: strings.Repeat("a", 1001), CreditsPerPeriod: 8, Price: 1},
+			wantErr: ErrPlanDescriptionTooLong,
+		},
+		{
+			name:    "zero credits",
+			req:     CreateSubscriptionPlanRequest{Name: "x", Price: 1},
+			wantErr: ErrInvalidPlanCredits,
+		},
+		{
+			name:    "credits above single grant limit",
+			req:     CreateSubscriptionPlanRequest{Name: "x", CreditsPerPeriod: 101, Price: 1},
+			wantErr: ErrInvalidPlanCredits,
+		},
+		{
+			name:    "zero price",
+			req:     CreateSubscriptionPlanRequest{Name: "x", CreditsPerPeriod: 1},
+			wantErr: ErrInvalidPlanPrice,
+		},
+		{
+			name:    "period too long",
+			req:     CreateSubscriptionPlanRequest{Name: "x", CreditsPerPeriod: 1, Price: 1, PeriodMonths: 13},
+			wantErr: ErrInvalidPlanPeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); err != tt.wantErr {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateSubscriptionPlanRequest_DefaultPeriod(t *testing.T) {
+	req := CreateSubscriptionPlanRequest{Name: "x", CreditsPerPeriod: 4, Price: 10000}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if req.PeriodMonths != 1 {
+		t.Errorf("PeriodMonths = %d, want 1", req.PeriodMonths)
+	}
+}
+
+func TestSubscribeRequest_Validate(t *testing.T) {
+	if err := (&SubscribeRequest{}).Validate(); err != ErrInvalidPlanID {
+		t.Errorf("Validate() = %v, want %v", err, ErrInvalidPlanID)
+	}
+	if err := (&SubscribeRequest{PlanID: uuid.New()}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestAddBillingPeriod(t *testing.T) {
+	loc := time.UTC
+	tests := []struct {
+		name   string
+		start  time.Time
+		months int
+		want   time.Time
+	}{
+		{
+			name:   "regular month",
+			start:  time.Date(2026, 3, 15, 10, 30, 0, 0, loc),
+			months: 1,
+			want:   time.Date(2026, 4, 15, 10, 30, 0, 0, loc),
+		},
+		{
+			name:   "end of January clamps to February",
+			start:  time.Date(2026, 1, 31, 9, 0, 0, 0, loc),
+			months: 1,
+			want:   time.Date(2026, 2, 28, 9, 0, 0, 0, loc),
+		},
+		{
+			name:   "leap year February",
+			start:  time.Date(2028, 1, 30, 9, 0, 0, 0, loc),
+			months: 1,
+			want:   time.Date(2028, 2, 29, 9, 0, 0, 0, loc),
+		},
+		{
+			name:   "crosses year",
+			start:  time.Date(2026, 11, 30, 0, 0, 0, 0, loc),
+			months: 3,
+			want:   time.Date(2027, 2, 28, 0, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddBillingPeriod(tt.start, tt.months); !got.Equal(tt.want) {
+				t.Errorf("AddBillingPeriod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProrationFraction(t *testing.T) {
+	start := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	if got := ProrationFraction(start, end, start.Add(-time.Hour)); got != 1 {
+		t.Errorf("before period = %v, want 1", got)
+	}
+	if got := ProrationFraction(start, end, end); got != 0 {
+		t.Errorf("at period end = %v, want 0", got)
+	}
+	if got := ProrationFraction(start, end, start.AddDate(0, 0, 15)); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("mid period = %v, want 0.5", got)
+	}
+	if got := ProrationFraction(end, start, start); got != 0 {
+		t.Errorf("inverted period = %v, want 0", got)
+	}
+}
+
+func TestProrateUpgrade(t *testing.T) {
+	basic := &SubscriptionPlan{CreditsPerPeriod: 4, Price: 10000}
+	pro := &SubscriptionPlan{CreditsPerPeriod: 9, Price: 21000}
+
+	amount, credits := ProrateUpgrade(basic, pro, 0.5)
+	if amount != 5500 {
+		t.Errorf("amount = %v, want 5500", amount)
+	}
+	if credits != 2 {
+		t.Errorf("credits = %d, want 2 (rounded down)", credits)
+	}
+
+	amount, credits = ProrateUpgrade(pro, basic, 0.5)
+	if amount != 0 || credits != 0 {
+		t.Errorf("downgrade = (%v, %d), want (0, 0)", amount, credits)
+	}
+
+	amount, _ = ProrateUpgrade(basic, pro, 1.0/3)
+	if amount != 3666.67 {
+		t.Errorf("amount = %v, want rounded to kopecks 3666.67", amount)
+	}
+}
+
+func TestDunningRetryDelay(t *testing.T) {
+	for i, want := range SubscriptionDunningSchedule {
+		got, ok := DunningRetryDelay(i + 1)
+		if !ok || got != want {
+			t.Errorf("DunningRetryDelay(%d) = (%v, %v), want (%v, true)", i+1, got, ok, want)
+		}
+	}
+	if _, ok := DunningRetryDelay(len(SubscriptionDunningSchedule) + 1); ok {
+		t.Error("expected retries to be exhausted")
+	}
+	if _, ok := DunningRetryDelay(0); ok {
+		t.Error("expected no delay before any failure")
+	}
+}
+
+func TestSubscriptionCreditReason(t *testing.T) {
+	invoice := &SubscriptionInvoice{
+		ID:          uuid.New(),
+		Kind:        SubscriptionInvoiceRenewal,
+		PeriodStart: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	reason := SubscriptionCreditReason("Базовый", invoice)
+	for _, part := range []string{"Подписка «Базовый»", "01.04.2026 – 01.05.2026", invoice.ID.String()} {
+		if !strings.Contains(reason, part) {
+			t.Errorf("reason %q does not contain %q", reason, part)
+		}
+	}
+
+	invoice.Kind = SubscriptionInvoiceProration
+	if reason := SubscriptionCreditReason("Про", invoice); !strings.Contains(reason, "доплата") {
+		t.Errorf("proration reason %q should mention доплата", reason)
+	}
+}
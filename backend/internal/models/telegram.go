@@ -125,6 +125,15 @@ type Broadcast struct {
 	CreatedBy   uuid.UUID  `db:"created_by" json:"created_by"`
 	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
 	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+	ScheduledAt *time.Time `db:"scheduled_at" json:"scheduled_at,omitempty"`
+	TemplateID  *uuid.UUID `db:"template_id" json:"template_id,omitempty"`
+	AudienceID  *uuid.UUID `db:"audience_id" json:"audience_id,omitempty"`
+
+	ParseMode   string                 `db:"parse_mode" json:"parse_mode,omitempty"`
+	Buttons     BroadcastKeyboard      `db:"buttons" json:"buttons,omitempty"`
+	EditedAt    *time.Time             `db:"edited_at" json:"edited_at,omitempty"`
+	RecalledAt  *time.Time             `db:"recalled_at" json:"recalled_at,omitempty"`
+	Attachments []*BroadcastAttachment `db:"-" json:"attachments,omitempty"`
 }
 
 // BroadcastLog представляет лог отправки сообщения конкретному пользователю
@@ -136,13 +145,24 @@ type BroadcastLog struct {
 	Status      string    `db:"status" json:"status"`
 	Error       string    `db:"error" json:"error,omitempty"`
 	SentAt      time.Time `db:"sent_at" json:"sent_at"`
+
+	// Идентификаторы доставленных сообщений для editMessageText / deleteMessage
+	ChatID               *int64        `db:"chat_id" json:"chat_id,omitempty"`
+	MessageID            *int64        `db:"message_id" json:"message_id,omitempty"`
+	AttachmentMessageIDs pq.Int64Array `db:"attachment_message_ids" json:"attachment_message_ids,omitempty"`
 }
 
 // SendBroadcastRequest представляет запрос на отправку массовой рассылки
 type SendBroadcastRequest struct {
-	ListID  *uuid.UUID  `json:"list_id,omitempty"`  // Optional: broadcast list ID
-	UserIDs []uuid.UUID `json:"user_ids,omitempty"` // Optional: specific user IDs
-	Message string      `json:"message" validate:"required,min=1,max=4096"`
+	ListID      *uuid.UUID  `json:"list_id,omitempty"`      // Optional: broadcast list ID
+	UserIDs     []uuid.UUID `json:"user_ids,omitempty"`     // Optional: specific user IDs
+	AudienceID  *uuid.UUID  `json:"audience_id,omitempty"`  // Optional: saved audience resolved at send time
+	TemplateID  *uuid.UUID  `json:"template_id,omitempty"`  // Optional: template used instead of message
+	ScheduledAt *time.Time  `json:"scheduled_at,omitempty"` // Optional: send later instead of immediately
+	Message     string      `json:"message" validate:"max=4096"`
+
+	ParseMode string            `json:"parse_mode,omitempty"` // Optional: HTML or MarkdownV2
+	Buttons   BroadcastKeyboard `json:"buttons,omitempty"`    // Optional: inline URL/callback buttons
 }
 
 // TeacherLessonBroadcastRequest представляет запрос на отправку рассылки студентам занятия (от преподавателя)
@@ -152,6 +172,7 @@ type TeacherLessonBroadcastRequest struct {
 
 // Константы статусов рассылки
 const (
+	BroadcastStatusScheduled  = "scheduled"
 	BroadcastStatusPending    = "pending"
 	BroadcastStatusInProgress = "in_progress"
 	BroadcastStatusCompleted  = "completed"
@@ -199,23 +220,40 @@ func (r *UpdateBroadcastListRequest) Validate() error {
 
 // Validate выполняет валидацию SendBroadcastRequest
 func (r *SendBroadcastRequest) Validate() error {
-	// Требуем ЛИБО list_id ЛИБО user_ids (не оба, не ни один)
+	// Требуем ровно один источник получателей: list_id, user_ids или audience_id
 	hasListID := r.ListID != nil && *r.ListID != uuid.Nil
 	hasUserIDs := len(r.UserIDs) > 0
+	hasAudienceID := r.AudienceID != nil && *r.AudienceID != uuid.Nil
 
-	if !hasListID && !hasUserIDs {
+	if hasAudienceID && (hasListID || hasUserIDs) {
+		return ErrBroadcastAudienceConflict
+	}
+	if !hasListID && !hasUserIDs && !hasAudienceID {
 		return errors.New("either list_id or user_ids must be provided")
 	}
 	if hasListID && hasUserIDs {
 		return errors.New("cannot specify both list_id and user_ids")
 	}
 
-	// Валидация сообщения
-	if r.Message == "" {
-		return ErrInvalidBroadcastMessage
+	// Текст берётся либо из запроса, либо из шаблона
+	if r.TemplateID != nil {
+		if r.Message != "" {
+			return ErrBroadcastMessageOrTemplate
+		}
+	} else if err := ValidateBroadcastMessage(r.Message); err != nil {
+		return err
 	}
-	if len(r.Message) > 4096 {
-		return ErrBroadcastMessageTooLong
+
+	// Разметку шаблона сервис проверяет после его загрузки
+	if err := ValidateBroadcastFormatting(r.Message, r.ParseMode); err != nil {
+		return err
+	}
+	if err := r.Buttons.Validate(); err != nil {
+		return err
+	}
+
+	if r.ScheduledAt != nil && !r.ScheduledAt.After(time.Now()) {
+		return ErrBroadcastScheduledInPast
 	}
 
 	return nil
@@ -239,6 +277,11 @@ func (bl *BroadcastList) IsDeleted() bool {
 	return bl.DeletedAt != nil
 }
 
+// IsScheduled проверяет, ожидает ли рассылка запланированного времени отправки
+func (b *Broadcast) IsScheduled() bool {
+	return b.Status == BroadcastStatusScheduled
+}
+
 // IsPending проверяет, находится ли рассылка в состоянии ожидания
 func (b *Broadcast) IsPending() bool {
 	return b.Status == BroadcastStatusPending
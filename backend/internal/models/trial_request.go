@@ -4,6 +4,8 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/google/uuid"
+
 	"tutoring-platform/pkg/sanitize"
 )
 
@@ -15,6 +17,19 @@ type TrialRequest struct {
 	Telegram  string    `db:"telegram" json:"telegram"`
 	Email     *string   `db:"email" json:"email,omitempty"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
+
+	// Поля CRM воронки
+	Status             TrialRequestStatus `db:"status" json:"status"`
+	AssignedTo         *uuid.UUID         `db:"assigned_to" json:"assigned_to,omitempty"`
+	AssignedToName     *string            `db:"assigned_to_name" json:"assigned_to_name,omitempty"` // Заполняется JOIN-ом
+	FollowUpAt         *time.Time         `db:"follow_up_at" json:"follow_up_at,omitempty"`
+	FollowUpRemindedAt *time.Time         `db:"follow_up_reminded_at" json:"follow_up_reminded_at,omitempty"`
+	LostReason         *string            `db:"lost_reason" json:"lost_reason,omitempty"`
+	ConvertedUserID    *uuid.UUID         `db:"converted_user_id" json:"converted_user_id,omitempty"`
+	ConvertedLessonID  *uuid.UUID         `db:"converted_lesson_id" json:"converted_lesson_id,omitempty"`
+	ConvertedBookingID *uuid.UUID         `db:"converted_booking_id" json:"converted_booking_id,omitempty"`
+	ConvertedAt        *time.Time         `db:"converted_at" json:"converted_at,omitempty"`
+	UpdatedAt          time.Time          `db:"updated_at" json:"updated_at"`
 }
 
 // CreateTrialRequestInput представляет входные данные для создания запроса на пробный урок
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"tutoring-platform/pkg/sanitize"
+)
+
+// TrialRequestStatus статус заявки в CRM воронке
+type TrialRequestStatus string
+
+// Статусы заявки на пробное занятие
+const (
+	TrialRequestStatusNew       TrialRequestStatus = "new"
+	TrialRequestStatusContacted TrialRequestStatus = "contacted"
+	TrialRequestStatusScheduled TrialRequestStatus = "scheduled"
+	TrialRequestStatusConverted TrialRequestStatus = "converted"
+	TrialRequestStatusLost      TrialRequestStatus = "lost"
+)
+
+// Типы записей в журнале активности заявки
+const (
+	TrialRequestActivityNote         = "note"
+	TrialRequestActivityStatusChange = "status_change"
+	TrialRequestActivityAssignment   = "assignment"
+	TrialRequestActivityFollowUp     = "follow_up"
+	TrialRequestActivityConversion   = "conversion"
+)
+
+// Ограничения CRM заявок
+const (
+	MaxTrialRequestNoteLength       = 2000
+	MaxTrialRequestLostReasonLength = 500
+	DefaultTrialLessonColor         = "#3B82F6"
+)
+
+// trialRequestTransitions допустимые ручные переходы между статусами.
+// В converted заявка попадает только через конвертацию, закрытую (lost) заявку можно вернуть в работу
+var trialRequestTransitions = map[TrialRequestStatus][]TrialRequestStatus{
+	TrialRequestStatusNew:       {TrialRequestStatusContacted, TrialRequestStatusScheduled, TrialRequestStatusLost},
+	TrialRequestStatusContacted: {TrialRequestStatusScheduled, TrialRequestStatusLost},
+	TrialRequestStatusScheduled: {TrialRequestStatusContacted, TrialRequestStatusLost},
+	TrialRequestStatusLost:      {TrialRequestStatusNew, TrialRequestStatusContacted},
+}
+
+// IsValid проверяет, что статус известен
+func (s TrialRequestStatus) IsValid() bool {
+	switch s {
+	case TrialRequestStatusNew, TrialRequestStatusContacted, TrialRequestStatusScheduled,
+		TrialRequestStatusConverted, TrialRequestStatusLost:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanTransitionTo проверяет, можно ли вручную перевести заявку в статус to
+func (s TrialRequestStatus) CanTransitionTo(to TrialRequestStatus) bool {
+	for _, allowed := range trialRequestTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen возвращает true, пока заявка находится в работе
+func (s TrialRequestStatus) IsOpen() bool {
+	return s != TrialRequestStatusConverted && s != TrialRequestStatusLost
+}
+
+// TrialRequestActivity запись журнала активности заявки: заметка или изменение
+type TrialRequestActivity struct {
+	ID             uuid.UUID           `db:"id" json:"id"`
+	TrialRequestID int64               `db:"trial_request_id" json:"trial_request_id"`
+	ActorID        *uuid.UUID          `db:"actor_id" json:"actor_id,omitempty"`
+	ActorName      *string             `db:"actor_name" json:"actor_name,omitempty"` // Заполняется JOIN-ом
+	Kind           string              `db:"kind" json:"kind"`
+	Body           string              `db:"body" json:"body"`
+	FromStatus     *TrialRequestStatus `db:"from_status" json:"from_status,omitempty"`
+	ToStatus       *TrialRequestStatus `db:"to_status" json:"to_status,omitempty"`
+	CreatedAt      time.Time           `db:"created_at" json:"created_at"`
+}
+
+// TrialRequestDetails заявка вместе с журналом активности
+type TrialRequestDetails struct {
+	*TrialRequest
+	Activities []*TrialRequestActivity `json:"activities"`
+}
+
+// TrialRequestFilter фильтр списка заявок
+type TrialRequestFilter struct {
+	Status     *TrialRequestStatus
+	AssignedTo *uuid.UUID
+	Unassigned bool
+}
+
+// UpdateTrialRequestStatusRequest запрос на смену статуса заявки
+type UpdateTrialRequestStatusRequest struct {
+	Status     TrialRequestStatus `json:"status"`
+	LostReason *string            `json:"lost_reason,omitempty"` // Только для статуса lost
+}
+
+// Validate выполняет валидацию UpdateTrialRequestStatusRequest
+func (r *UpdateTrialRequestStatusRequest) Validate() error {
+	if !r.Status.IsValid() {
+		return ErrInvalidTrialRequestStatus
+	}
+	if r.Status == TrialRequestStatusConverted {
+		return ErrTrialRequestConvertViaAction
+	}
+	if r.LostReason != nil {
+		reason := strings.TrimSpace(sanitize.Input(*r.LostReason))
+		if r.Status != TrialRequestStatusLost || utf8.RuneCountInString(reason) > MaxTrialRequestLostReasonLength {
+			return ErrInvalidTrialRequestLostReason
+		}
+		r.LostReason = &reason
+	}
+	return nil
+}
+
+// AssignTrialRequestRequest запрос на назначение ответственного (nil - снять назначение)
+type AssignTrialRequestRequest struct {
+	AssignedTo *uuid.UUID `json:"assigned_to"`
+}
+
+// Validate выполняет валидацию AssignTrialRequestRequest
+func (r *AssignTrialRequestRequest) Validate() error {
+	if r.AssignedTo != nil && *r.AssignedTo == uuid.Nil {
+		return ErrInvalidUserID
+	}
+	return nil
+}
+
+// SetTrialRequestFollowUpRequest запрос на установку срока следующего контакта (nil - снять)
+type SetTrialRequestFollowUpRequest struct {
+	FollowUpAt *time.Time `json:"follow_up_at"`
+	Note       string     `json:"note,omitempty"`
+}
+
+// Validate выполняет валидацию SetTrialRequestFollowUpRequest
+func (r *SetTrialRequestFollowUpRequest) Validate(now time.Time) error {
+	if r.FollowUpAt != nil && !r.FollowUpAt.After(now) {
+		return ErrTrialRequestFollowUpInPast
+	}
+	r.Note = strings.TrimSpace(sanitize.Input(r.Note))
+	if utf8.RuneCountInString(r.Note) > MaxTrialRequestNoteLength {
+		return ErrInvalidTrialRequestNote
+	}
+	return nil
+}
+
+// AddTrialRequestNoteRequest запрос на добавление заметки к заявке
+type AddTrialRequestNoteRequest struct {
+	Body string `json:"body"`
+}
+
+// Validate выполняет валидацию AddTrialRequestNoteRequest
+func (r *AddTrialRequestNoteRequest) Validate() error {
+	r.Body = strings.TrimSpace(sanitize.Input(r.Body))
+	if r.Body == "" || utf8.RuneCountInString(r.Body) > MaxTrialRequestNoteLength {
+		return ErrInvalidTrialRequestNote
+	}
+	return nil
+}
+
+// ConvertTrialRequestRequest запрос на конвертацию заявки: создание студента,
+// пробного занятия и записи на него одним действием
+type ConvertTrialRequestRequest struct {
+	Email     string    `json:"email"`                // Optional: по умолчанию email из заявки
+	FirstName string    `json:"first_name,omitempty"` // Optional: по умолчанию из имени в заявке
+	LastName  string    `json:"last_name,omitempty"`
+	TeacherID uuid.UUID `json:"teacher_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Subject   *string   `json:"subject,omitempty"`
+	Color     string    `json:"color,omitempty"` // Optional: по умолчанию DefaultTrialLessonColor
+	Link      *string   `json:"link,omitempty"`
+}
+
+// ApplyDefaults заполняет незаданные поля данными заявки
+func (r *ConvertTrialRequestRequest) ApplyDefaults(tr *TrialRequest) {
+	if strings.TrimSpace(r.Email) == "" && tr.Email != nil {
+		r.Email = *tr.Email
+	}
+	if r.FirstName == "" && r.LastName == "" {
+		parts := strings.SplitN(strings.TrimSpace(tr.Name), " ", 2)
+		r.FirstName = parts[0]
+		if len(parts) > 1 {
+			r.LastName = strings.TrimSpace(parts[1])
+		}
+	}
+	if r.Color == "" {
+		r.Color = DefaultTrialLessonColor
+	}
+}
+
+// Validate выполняет валидацию ConvertTrialRequestRequest (после ApplyDefaults)
+func (r *ConvertTrialRequestRequest) Validate() error {
+	r.Email = sanitize.Email(r.Email)
+	r.FirstName = sanitize.Name(r.FirstName)
+	r.LastName = sanitize.Name(r.LastName)
+
+	if r.Email == "" || !isValidEmail(r.Email) {
+		return ErrInvalidEmail
+	}
+	if r.FirstName == "" && r.LastName == "" {
+		return ErrInvalidFullName
+	}
+	if r.TeacherID == uuid.Nil {
+		return ErrInvalidTeacherID
+	}
+	if r.StartTime.IsZero() || r.EndTime.IsZero() || !r.EndTime.After(r.StartTime) {
+		return ErrInvalidLessonTime
+	}
+	return nil
+}
+
+// ConvertTrialRequestResult результат конвертации заявки.
+// TemporaryPassword возвращается один раз, чтобы администратор передал его студенту
+type ConvertTrialRequestResult struct {
+	TrialRequest      *TrialRequest `json:"trial_request"`
+	Student           *User         `json:"student"`
+	Lesson            *Lesson       `json:"lesson"`
+	BookingID         uuid.UUID     `json:"booking_id"`
+	TemporaryPassword string        `json:"temporary_password"`
+}
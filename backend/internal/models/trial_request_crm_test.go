@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTrialRequestStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from TrialRequestStatus
+		to   TrialRequestStatus
+		want bool
+	}{
+		{TrialRequestStatusNew, TrialRequestStatusContacted, true},
+		{TrialRequestStatusNew, TrialRequestStatusScheduled, true},
+		{TrialRequestStatusNew, TrialRequestStatusLost, true},
+		{TrialRequestStatusContacted, TrialRequestStatusNew, false},
+		{TrialRequestStatusScheduled, TrialRequestStatusContacted, true},
+		{TrialRequestStatusLost, TrialRequestStatusNew, true},
+		{TrialRequestStatusLost, TrialRequestStatusScheduled, false},
+		{TrialRequestStatusNew, TrialRequestStatusConverted, false},
+		{TrialRequestStatusScheduled, TrialRequestStatusConverted, false},
+		{TrialRequestStatusConverted, TrialRequestStatusNew, false},
+		{TrialRequestStatusConverted, TrialRequestStatusLost, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s -> %s: got %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateTrialRequestStatusRequest_Validate(t *testing.T) {
+	reason := "  дорого  "
+	longReason := strings.Repeat("я", MaxTrialRequestLostReasonLength+1)
+
+	tests := []struct {
+		name    string
+		req     UpdateTrialRequestStatusRequest
+		wantErr error
+	}{
+		{name: "contacted", req: UpdateTrialRequestStatusRequest{Status: TrialRequestStatusContacted}},
+		{name: "lost with reason", req: UpdateTrialRequestStatusRequest{Status: TrialRequestStatusLost, LostReason: &reason}},
+		{name: "unknown status", req: UpdateTrialRequestStatusRequest{Status: "archived"}, wantErr: ErrInvalidTrialRequestStatus},
+		{name: "converted manually", req: UpdateTrialRequestStatusRequest{Status: TrialRequestStatusConverted}, wantErr: ErrTrialRequestConvertViaAction},
+		{name: "reason for non-lost status", req: UpdateTrialRequestStatusRequest{Status: TrialRequestStatusContacted, LostReason: &reason}, wantErr: ErrInvalidTrialRequestLostReason},
+		{name: "reason too long", req: UpdateTrialRequestStatusRequest{Status: TrialRequestStatusLost, LostReason: &longReason}, wantErr: ErrInvalidTrialRequestLostReason},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && tt.req.LostReason != nil && *tt.req.LostReason != "дорого" {
+				t.Errorf("lost reason not trimmed: %q", *tt.req.LostReason)
+			}
+		})
+	}
+}
+
+func TestSetTrialRequestFollowUpRequest_Validate(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Minute)
+
+	if err := (&SetTrialRequestFollowUpRequest{FollowUpAt: &future}).Validate(now); err != nil {
+		t.Errorf("future follow-up: unexpected error %v", err)
+	}
+	if err := (&SetTrialRequestFollowUpRequest{}).Validate(now); err != nil {
+		t.Errorf("clearing follow-up: unexpected error %v", err)
+	}
+	if err := (&SetTrialRequestFollowUpRequest{FollowUpAt: &past}).Validate(now); !errors.Is(err, ErrTrialRequestFollowUpInPast) {
+		t.Errorf("past follow-up: got %v, want %v", err, ErrTrialRequestFollowUpInPast)
+	}
+	longNote := strings.Repeat("a", MaxTrialRequestNoteLength+1)
+	if err := (&SetTrialRequestFollowUpRequest{FollowUpAt: &future, Note: longNote}).Validate(now); !errors.Is(err, ErrInvalidTrialRequestNote) {
+		t.Errorf("long note: got %v, want %v", err, ErrInvalidTrialRequestNote)
+	}
+}
+
+func TestAddTrialRequestNoteRequest_Validate(t *testing.T) {
+	if err := (&AddTrialRequestNoteRequest{Body: "Перезвонить вечером"}).Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := (&AddTrialRequestNoteRequest{Body: "   "}).Validate(); !errors.Is(err, ErrInvalidTrialRequestNote) {
+		t.Errorf("blank note: got %v, want %v", err, ErrInvalidTrialRequestNote)
+	}
+}
+
+func TestConvertTrialRequestRequest_ApplyDefaultsAndValidate(t *testing.T) {
+	email := "ivan@example.com"
+	tr := &TrialRequest{Name: "Иван Петров", Email: &email}
+	start := time.Date(2026, 3, 12, 15, 0, 0, 0, time.UTC)
+
+	req := ConvertTrialRequestRequest{TeacherID: uuid.New(), StartTime: start, EndTime: start.Add(time.Hour)}
+	req.ApplyDefaults(tr)
+
+	if req.Email != email || req.FirstName != "Иван" || req.LastName != "Петров" || req.Color != DefaultTrialLessonColor {
+		t.Fatalf("defaults not applied: %+v", req)
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error %v", err)
+	}
+
+	explicit := ConvertTrialRequestRequest{Email: "other@example.com", FirstName: "Анна", TeacherID: uuid.New(), StartTime: start, EndTime: start.Add(time.Hour)}
+	explicit.ApplyDefaults(tr)
+	if explicit.Email != "other@example.com" || explicit.FirstName != "Анна" || explicit.LastName != "" {
+		t.Errorf("explicit values overwritten: %+v", explicit)
+	}
+
+	noEmail := ConvertTrialRequestRequest{TeacherID: uuid.New(), StartTime: start, EndTime: start.Add(time.Hour)}
+	noEmail.ApplyDefaults(&TrialRequest{Name: "Иван"})
+	if err := noEmail.Validate(); !errors.Is(err, ErrInvalidEmail) {
+		t.Errorf("missing email: got %v, want %v", err, ErrInvalidEmail)
+	}
+
+	badTime := ConvertTrialRequestRequest{TeacherID: uuid.New(), StartTime: start, EndTime: start}
+	badTime.ApplyDefaults(tr)
+	if err := badTime.Validate(); !errors.Is(err, ErrInvalidLessonTime) {
+		t.Errorf("empty interval: got %v, want %v", err, ErrInvalidLessonTime)
+	}
+
+	noTeacher := ConvertTrialRequestRequest{StartTime: start, EndTime: start.Add(time.Hour)}
+	noTeacher.ApplyDefaults(tr)
+	if err := noTeacher.Validate(); !errors.Is(err, ErrInvalidTeacherID) {
+		t.Errorf("missing teacher: got %v, want %v", err, ErrInvalidTeacherID)
+	}
+}
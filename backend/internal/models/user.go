@@ -150,8 +150,14 @@ func (u *User) CanBeAssignedAsTeacher() bool {
 	return u.IsAdmin() || u.IsTeacher()
 }
 
-// Validate выполняет валидацию CreateUserRequest
+// Validate выполняет валидацию CreateUserRequest (допускаются только встроенные роли)
 func (r *CreateUserRequest) Validate() error {
+	return r.ValidateWithRoles(UserRole.IsBuiltin)
+}
+
+// ValidateWithRoles выполняет валидацию CreateUserRequest, проверяя роль через roleExists
+// (позволяет создавать пользователей с пользовательскими ролями из БД)
+func (r *CreateUserRequest) ValidateWithRoles(roleExists func(UserRole) bool) error {
 	// Санитизация входных данных
 	r.Email = sanitize.Email(r.Email)
 	r.FirstName = sanitize.Name(r.FirstName)
@@ -187,7 +193,7 @@ func (r *CreateUserRequest) Validate() error {
 	}
 
 	// Валидация роли
-	if r.Role != RoleStudent && r.Role != RoleAdmin && r.Role != RoleTeacher {
+	if !roleExists(r.Role) {
 		return ErrInvalidRole
 	}
 
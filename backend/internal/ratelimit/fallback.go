@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"context"
+
+	"tutoring-platform/internal/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FallbackStore оборачивает общее хранилище: при его ошибке лимиты с OnStoreError = memory считаются
+// в памяти процесса, остальные получают ошибку и решение принимает вызывающий
+type FallbackStore struct {
+	shared Store
+	local  *MemoryStore
+}
+
+// NewFallbackStore создает FallbackStore над общим хранилищем
+func NewFallbackStore(shared Store) *FallbackStore {
+	return &FallbackStore{
+		shared: shared,
+		local:  NewMemoryStore(),
+	}
+}
+
+// Allow учитывает запрос в общем хранилище, а если оно недоступно - по политике лимита
+func (s *FallbackStore) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	result, err := s.shared.Allow(ctx, key, limit)
+	if err == nil || limit.OnStoreError != config.RateLimitOnStoreErrorMemory {
+		return result, err
+	}
+	log.Warn().Err(err).Str("key", key).Msg("Rate limit store unavailable, using in-memory counters")
+	return s.local.Allow(ctx, key, limit)
+}
+
+// Shared возвращает общее хранилище
+func (s *FallbackStore) Shared() Store {
+	return s.shared
+}
+
+// Stop останавливает оба хранилища
+func (s *FallbackStore) Stop() {
+	s.shared.Stop()
+	s.local.Stop()
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// memoryCleanupInterval как часто MemoryStore удаляет восстановившиеся ключи
+const memoryCleanupInterval = 5 * time.Minute
+
+// MemoryStore хранит счетчики в памяти процесса: лимиты не делятся между репликами и сбрасываются при перезапуске
+type MemoryStore struct {
+	mu       sync.Mutex
+	tats     map[string]time.Time
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMemoryStore создает MemoryStore и запускает фоновую очистку
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		tats:     make(map[string]time.Time),
+		stopChan: make(chan struct{}),
+	}
+	go s.cleanupLoop()
+	return s
+}
+
+// Allow учитывает запрос по ключу
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	tat, allowed := gcra(now, s.tats[key], limit)
+	if allowed {
+		s.tats[key] = tat
+	}
+	return newResult(now, tat, allowed, limit), nil
+}
+
+// CleanupExpired удаляет ключи, лимит которых полностью восстановился
+func (s *MemoryStore) CleanupExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for key, tat := range s.tats {
+		if !tat.After(now) {
+			delete(s.tats, key)
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		log.Debug().Int("removed_entries", removed).Int("remaining_entries", len(s.tats)).
+			Msg("Rate limit store cleanup: expired entries removed")
+	}
+}
+
+// cleanupLoop периодически вызывает CleanupExpired
+func (s *MemoryStore) cleanupLoop() {
+	ticker := time.NewTicker(memoryCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.CleanupExpired()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// Stop останавливает фоновую очистку
+func (s *MemoryStore) Stop() {
+	s.stopOnce.Do(func() { close(s.stopChan) })
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// postgresCleanupInterval как часто PostgresStore удаляет восстановившиеся ключи
+const postgresCleanupInterval = 10 * time.Minute
+
+// PostgresStore хранит счетчики в таблице rate_limit_buckets. Время берется из часов базы данных,
+// поэтому расхождение часов реплик не влияет на лимиты
+type PostgresStore struct {
+	db       *sqlx.DB
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPostgresStore создает PostgresStore и запускает фоновую очистку
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	s := &PostgresStore{
+		db:       db,
+		stopChan: make(chan struct{}),
+	}
+	go s.cleanupLoop()
+	return s
+}
+
+// Allow учитывает запрос по ключу. Строка ключа блокируется до конца транзакции,
+// поэтому одновременные запросы разных реплик учитываются по очереди
+func (s *PostgresStore) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin rate limit transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Новый ключ создается с TAT в прошлом, то есть с полным лимитом
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (key, tat) VALUES ($1, to_timestamp(0))
+		ON CONFLICT (key) DO NOTHING
+	`, key); err != nil {
+		return nil, fmt.Errorf("failed to create rate limit bucket: %w", err)
+	}
+
+	var tat, now time.Time
+	if err := tx.QueryRowxContext(ctx, `
+		SELECT tat, clock_timestamp() FROM rate_limit_buckets WHERE key = $1 FOR UPDATE
+	`, key).Scan(&tat, &now); err != nil {
+		return nil, fmt.Errorf("failed to lock rate limit bucket: %w", err)
+	}
+
+	tat, allowed := gcra(now, tat, limit)
+	if allowed {
+		if _, err := tx.ExecContext(ctx, `UPDATE rate_limit_buckets SET tat = $2 WHERE key = $1`, key, tat); err != nil {
+			return nil, fmt.Errorf("failed to update rate limit bucket: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit rate limit transaction: %w", err)
+	}
+	return newResult(now, tat, allowed, limit), nil
+}
+
+// CleanupExpired удаляет ключи, лимит которых полностью восстановился
+func (s *PostgresStore) CleanupExpired(ctx context.Context) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE tat <= clock_timestamp()`)
+	if err != nil {
+		return fmt.Errorf("failed to clean up rate limit buckets: %w", err)
+	}
+	if removed, _ := result.RowsAffected(); removed > 0 {
+		log.Debug().Int64("removed_entries", removed).Msg("Rate limit store cleanup: expired entries removed")
+	}
+	return nil
+}
+
+// cleanupLoop периодически вызывает CleanupExpired
+func (s *PostgresStore) cleanupLoop() {
+	ticker := time.NewTicker(postgresCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.CleanupExpired(context.Background()); err != nil {
+				log.Warn().Err(err).Msg("Rate limit store cleanup failed")
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// Stop останавливает фоновую очистку
+func (s *PostgresStore) Stop() {
+	s.stopOnce.Do(func() { close(s.stopChan) })
+}
@@ -0,0 +1,101 @@
+// Package ratelimit содержит хранилища счетчиков ограничения частоты запросов (Store): в памяти процесса,
+// в PostgreSQL и в Redis. Общие хранилища делят лимиты между репликами сервера и не сбрасываются при перезапуске.
+// Лимит считается алгоритмом GCRA (эквивалент token bucket): на ключ хранится одно значение -
+// теоретическое время следующего запроса (TAT), поэтому проверка в общем хранилище - одна атомарная операция
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/config"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Limit не больше Requests запросов за Window; допускается всплеск до Requests запросов подряд.
+// OnStoreError - поведение при недоступном хранилище (config.RateLimitOnStoreError*, пусто - allow)
+type Limit struct {
+	Requests     int
+	Window       time.Duration
+	OnStoreError string
+}
+
+// interval промежуток, за который восстанавливается один запрос
+func (l Limit) interval() time.Duration {
+	return l.Window / time.Duration(l.Requests)
+}
+
+// Result решение по запросу и состояние лимита после него
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int           // Сколько запросов можно сделать прямо сейчас
+	Reset     time.Duration // Через сколько лимит восстановится полностью
+	// RetryAfter через сколько будет разрешен следующий запрос (0, если запрос разрешен)
+	RetryAfter time.Duration
+}
+
+// Store хранилище счетчиков. Ключи разных лимитов не должны совпадать
+type Store interface {
+	// Allow учитывает запрос по ключу и возвращает решение; отклоненный запрос не расходует лимит
+	Allow(ctx context.Context, key string, limit Limit) (*Result, error)
+	// Stop останавливает фоновую очистку и закрывает соединения
+	Stop()
+}
+
+// New создает хранилище по конфигурации: MemoryStore (по умолчанию), PostgresStore или RedisStore.
+// Общие хранилища оборачиваются в FallbackStore
+func New(cfg *config.RateLimitConfig, db *sqlx.DB) (Store, error) {
+	switch cfg.Store {
+	case "", config.RateLimitStoreMemory:
+		return NewMemoryStore(), nil
+	case config.RateLimitStorePostgres:
+		return NewFallbackStore(NewPostgresStore(db)), nil
+	case config.RateLimitStoreRedis:
+		return NewFallbackStore(NewRedisStore(RedisConfig{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})), nil
+	default:
+		return nil, fmt.Errorf("ratelimit: unknown store %q", cfg.Store)
+	}
+}
+
+// gcra применяет запрос к сохраненному TAT. Возвращает TAT после решения (при отказе - не меньше now)
+// и разрешен ли запрос
+func gcra(now, tat time.Time, limit Limit) (time.Time, bool) {
+	if tat.Before(now) {
+		tat = now
+	}
+	next := tat.Add(limit.interval())
+	if next.Add(-limit.Window).After(now) {
+		return tat, false
+	}
+	return next, true
+}
+
+// newResult строит Result по TAT после решения
+func newResult(now, tat time.Time, allowed bool, limit Limit) *Result {
+	interval := limit.interval()
+	used := tat.Sub(now)
+	if used < 0 {
+		used = 0
+	}
+
+	result := &Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int((limit.Window - used) / interval),
+		Reset:     used,
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if !allowed {
+		result.RetryAfter = used + interval - limit.Window
+	}
+	return result
+}
@@ -0,0 +1,176 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tutoring-platform/internal/config"
+
+	"github.com/google/uuid"
+)
+
+func TestGCRA(t *testing.T) {
+	limit := Limit{Requests: 10, Window: time.Minute}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var tat time.Time
+	for i := 0; i < 10; i++ {
+		var allowed bool
+		tat, allowed = gcra(now, tat, limit)
+		if !allowed {
+			t.Fatalf("request %d denied, want burst of %d allowed", i+1, limit.Requests)
+		}
+		result := newResult(now, tat, allowed, limit)
+		if result.Remaining != 9-i {
+			t.Errorf("request %d: Remaining = %d, want %d", i+1, result.Remaining, 9-i)
+		}
+	}
+
+	denied, allowed := gcra(now, tat, limit)
+	if allowed {
+		t.Fatal("request over the burst allowed")
+	}
+	result := newResult(now, denied, allowed, limit)
+	if result.RetryAfter != 6*time.Second || result.Reset != time.Minute || result.Remaining != 0 {
+		t.Errorf("denied result = %+v, want RetryAfter 6s, Reset 1m, Remaining 0", result)
+	}
+
+	// Через интервал восстанавливается ровно один запрос
+	later := now.Add(6 * time.Second)
+	if tat, allowed = gcra(later, tat, limit); !allowed {
+		t.Fatal("request after one interval denied")
+	}
+	if _, allowed = gcra(later, tat, limit); allowed {
+		t.Error("second request after one interval allowed")
+	}
+
+	// За окно лимит восстанавливается полностью
+	if result := newResult(now.Add(2*time.Minute), tat, true, limit); result.Remaining != 10 || result.Reset != 0 {
+		t.Errorf("result after window = %+v, want full limit", result)
+	}
+}
+
+// testStoreLimits проверяет общий контракт Store
+func testStoreLimits(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	limit := Limit{Requests: 3, Window: time.Minute}
+	key := "test:" + uuid.NewString()
+
+	for i := 0; i < limit.Requests; i++ {
+		result, err := store.Allow(ctx, key, limit)
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		if !result.Allowed || result.Limit != 3 || result.Remaining != 2-i {
+			t.Fatalf("request %d: result = %+v, want allowed with %d remaining", i+1, result, 2-i)
+		}
+	}
+
+	result, err := store.Allow(ctx, key, limit)
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if result.Allowed || result.Remaining != 0 {
+		t.Fatalf("request over the limit: result = %+v, want denied", result)
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > 20*time.Second {
+		t.Errorf("RetryAfter = %v, want (0, 20s]", result.RetryAfter)
+	}
+	if result.Reset <= 40*time.Second || result.Reset > time.Minute {
+		t.Errorf("Reset = %v, want (40s, 1m]", result.Reset)
+	}
+
+	other, err := store.Allow(ctx, key+":other", limit)
+	if err != nil {
+		t.Fatalf("Allow(other key) error = %v", err)
+	}
+	if !other.Allowed {
+		t.Error("other key must have its own limit")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Stop()
+	testStoreLimits(t, store)
+}
+
+func TestMemoryStoreCleanupExpired(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Stop()
+
+	store.tats["expired"] = time.Now().Add(-time.Second)
+	store.tats["active"] = time.Now().Add(time.Minute)
+	store.CleanupExpired()
+
+	if _, ok := store.tats["expired"]; ok {
+		t.Error("expired key was not removed")
+	}
+	if _, ok := store.tats["active"]; !ok {
+		t.Error("active key was removed")
+	}
+}
+
+func TestNew(t *testing.T) {
+	store, err := New(&config.RateLimitConfig{}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	store.Stop()
+	if _, ok := store.(*MemoryStore); !ok {
+		t.Errorf("New() = %T, want *MemoryStore by default", store)
+	}
+
+	store, err = New(&config.RateLimitConfig{Store: config.RateLimitStoreRedis, RedisAddr: "localhost:6379"}, nil)
+	if err != nil {
+		t.Fatalf("New(redis) error = %v", err)
+	}
+	fallback, ok := store.(*FallbackStore)
+	if !ok {
+		t.Fatalf("New(redis) = %T, want *FallbackStore", store)
+	}
+	if _, ok := fallback.Shared().(*RedisStore); !ok {
+		t.Errorf("New(redis) shared store = %T, want *RedisStore", fallback.Shared())
+	}
+	store.Stop()
+
+	if _, err := New(&config.RateLimitConfig{Store: "memcached"}, nil); err == nil {
+		t.Error("New(unknown store) error = nil")
+	}
+}
+
+// unavailableStore имитирует недоступное общее хранилище
+type unavailableStore struct{}
+
+func (unavailableStore) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (unavailableStore) Stop() {}
+
+func TestFallbackStore(t *testing.T) {
+	available := NewFallbackStore(NewMemoryStore())
+	defer available.Stop()
+	testStoreLimits(t, available)
+
+	store := NewFallbackStore(unavailableStore{})
+	defer store.Stop()
+
+	if _, err := store.Allow(context.Background(), "allow", Limit{Requests: 1, Window: time.Minute}); err == nil {
+		t.Error("Allow() without memory policy error = nil")
+	}
+
+	limit := Limit{Requests: 1, Window: time.Minute, OnStoreError: config.RateLimitOnStoreErrorMemory}
+	for i, want := range []bool{true, false} {
+		result, err := store.Allow(context.Background(), "memory", limit)
+		if err != nil {
+			t.Fatalf("request %d: Allow() error = %v", i+1, err)
+		}
+		if result.Allowed != want {
+			t.Errorf("request %d: Allowed = %v, want %v", i+1, result.Allowed, want)
+		}
+	}
+}
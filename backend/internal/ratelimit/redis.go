@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix общий префикс ключей лимитов в Redis
+const redisKeyPrefix = "ratelimit:"
+
+// redisMaxIdleConns сколько соединений RedisStore держит открытыми между запросами
+const redisMaxIdleConns = 16
+
+// redisDefaultTimeout ограничение на одну команду, если в контексте нет дедлайна
+const redisDefaultTimeout = 2 * time.Second
+
+// redisGCRAScript атомарно применяет GCRA к ключу по часам Redis (общим для всех реплик).
+// ARGV: интервал восстановления одного запроса и окно в миллисекундах.
+// Возвращает {разрешен, TAT после решения, текущее время} в миллисекундах.
+// replicate_commands нужен Redis до 5.0, чтобы после TIME разрешалась запись
+const redisGCRAScript = `
+redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local interval = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+  tat = now
+end
+local next_tat = tat + interval
+if next_tat - window > now then
+  return {0, tat, now}
+end
+redis.call('SET', KEYS[1], next_tat, 'PX', next_tat - now)
+return {1, next_tat, now}
+`
+
+// RedisConfig параметры подключения к Redis
+type RedisConfig struct {
+	Addr     string // host:port
+	Password string
+	DB       int
+}
+
+// RedisStore хранит счетчики в Redis. Каждый запрос - один вызов Lua-скрипта, поэтому проверка атомарна
+// и не требует блокировок. Ключи удаляет сам Redis по истечении срока (PX)
+type RedisStore struct {
+	client *redis.Client
+	script *redis.Script
+}
+
+// NewRedisStore создает RedisStore; соединения открываются при первом запросе
+func NewRedisStore(cfg RedisConfig) *RedisStore {
+	return &RedisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.Addr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+			// RESP2 поддерживается всеми версиями Redis; ответ скрипта в RESP3 не отличается
+			Protocol:        2,
+			DisableIdentity: true,
+			DialTimeout:     redisDefaultTimeout,
+			ReadTimeout:     redisDefaultTimeout,
+			WriteTimeout:    redisDefaultTimeout,
+			MaxIdleConns:    redisMaxIdleConns,
+		}),
+		script: redis.NewScript(redisGCRAScript),
+	}
+}
+
+// Allow учитывает запрос по ключу. Скрипт вызывается по SHA1 и загружается в Redis при первом обращении
+func (s *RedisStore) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	intervalMs := limit.interval().Milliseconds()
+	if intervalMs < 1 {
+		intervalMs = 1
+	}
+
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+
+	values, err := s.script.Run(ctx, s.client, []string{redisKeyPrefix + key}, intervalMs, limit.Window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("redis: rate limit script failed: %w", err)
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("redis: unexpected rate limit reply %v", values)
+	}
+
+	now := time.UnixMilli(values[2])
+	return newResult(now, time.UnixMilli(values[1]), values[0] == 1, limit), nil
+}
+
+// Ping проверяет доступность Redis
+func (s *RedisStore) Ping(ctx context.Context) error {
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis: ping failed: %w", err)
+	}
+	return nil
+}
+
+// Stop закрывает открытые соединения
+func (s *RedisStore) Stop() {
+	s.client.Close()
+}
+
+// withRedisTimeout ограничивает команду redisDefaultTimeout, если в контексте нет своего дедлайна
+func withRedisTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, redisDefaultTimeout)
+}
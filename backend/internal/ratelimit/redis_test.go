@@ -0,0 +1,212 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis минимальный сервер RESP: PING, AUTH, SELECT, EVALSHA и EVAL скрипта GCRA (выполняется на Go).
+// HELLO не поддерживается, как в Redis до 6.0
+type fakeRedis struct {
+	t        *testing.T
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	loaded   bool
+	tats     map[string]time.Time
+	commands []string
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	f := &fakeRedis{t: t, listener: listener, password: password, tats: make(map[string]time.Time)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		args, err := readRESPCommand(r)
+		if err != nil || len(args) == 0 {
+			return
+		}
+		name := strings.ToUpper(args[0])
+
+		f.mu.Lock()
+		f.commands = append(f.commands, name)
+		f.mu.Unlock()
+
+		switch {
+		case name == "AUTH":
+			if args[len(args)-1] != f.password {
+				fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+			authed = true
+			fmt.Fprint(conn, "+OK\r\n")
+		case !authed:
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+		case name == "PING":
+			fmt.Fprint(conn, "+PONG\r\n")
+		case name == "SELECT":
+			fmt.Fprint(conn, "+OK\r\n")
+		case name == "EVALSHA" || name == "EVAL":
+			fmt.Fprint(conn, f.eval(name, args))
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", name)
+		}
+	}
+}
+
+// eval выполняет скрипт GCRA: EVALSHA до первого EVAL отвечает NOSCRIPT, как Redis после перезапуска
+func (f *fakeRedis) eval(name string, args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if name == "EVAL" {
+		if args[1] != redisGCRAScript {
+			return "-ERR unexpected script\r\n"
+		}
+		f.loaded = true
+	} else if !f.loaded {
+		return "-NOSCRIPT No matching script. Please use EVAL.\r\n"
+	}
+
+	key := args[3]
+	intervalMs, _ := strconv.ParseInt(args[4], 10, 64)
+	windowMs, _ := strconv.ParseInt(args[5], 10, 64)
+	limit := Limit{Requests: int(windowMs / intervalMs), Window: time.Duration(windowMs) * time.Millisecond}
+
+	now := time.UnixMilli(time.Now().UnixMilli())
+	tat, allowed := gcra(now, f.tats[key], limit)
+	allowedInt := 0
+	if allowed {
+		f.tats[key] = tat
+		allowedInt = 1
+	}
+	return fmt.Sprintf("*3\r\n:%d\r\n:%d\r\n:%d\r\n", allowedInt, tat.UnixMilli(), now.UnixMilli())
+}
+
+// readRESPCommand читает команду клиента: массив bulk-строк
+func readRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readRESPLine(r, '*')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid array length %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := readRESPLine(r, '$')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk length %q", line)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+// readRESPLine читает строку с ожидаемым типом и возвращает ее без типа и \r\n
+func readRESPLine(r *bufio.Reader, kind byte) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" || line[0] != kind {
+		return "", fmt.Errorf("unexpected line %q", line)
+	}
+	return line[1:], nil
+}
+
+func TestRedisStore(t *testing.T) {
+	f := newFakeRedis(t, "secret")
+	store := NewRedisStore(RedisConfig{Addr: f.listener.Addr().String(), Password: "secret", DB: 2})
+	defer store.Stop()
+
+	if err := store.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	testStoreLimits(t, store)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// HELLO без поддержки сервером клиент заменяет на AUTH и SELECT
+	commands := f.commands
+	if len(commands) > 0 && commands[0] == "HELLO" {
+		commands = commands[1:]
+	}
+	if len(commands) < 4 || commands[0] != "AUTH" || commands[1] != "SELECT" {
+		t.Errorf("commands = %v, want AUTH and SELECT on connect", f.commands)
+	}
+	evals := 0
+	for _, name := range f.commands {
+		if name == "EVAL" {
+			evals++
+		}
+	}
+	if evals != 1 {
+		t.Errorf("EVAL sent %d times, want once after NOSCRIPT", evals)
+	}
+}
+
+func TestRedisStoreWrongPassword(t *testing.T) {
+	f := newFakeRedis(t, "secret")
+	store := NewRedisStore(RedisConfig{Addr: f.listener.Addr().String(), Password: "wrong"})
+	defer store.Stop()
+
+	if _, err := store.Allow(context.Background(), "key", Limit{Requests: 1, Window: time.Minute}); err == nil {
+		t.Error("Allow() with wrong password error = nil")
+	}
+}
+
+// TestRedisStoreLive проверяет скрипт на настоящем Redis: REDIS_TEST_ADDR=localhost:6379 go test ./internal/ratelimit
+func TestRedisStoreLive(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR not set")
+	}
+	store := NewRedisStore(RedisConfig{Addr: addr, Password: os.Getenv("REDIS_TEST_PASSWORD")})
+	defer store.Stop()
+
+	if err := store.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	testStoreLimits(t, store)
+}
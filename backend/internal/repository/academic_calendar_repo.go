@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// AcademicCalendarSelectFields - поля таблицы academic_calendar_entries
+const AcademicCalendarSelectFields = `
+	id, entry_type, name, start_date, end_date, source, external_uid,
+	created_by, created_at, updated_at, deleted_at
+`
+
+// AcademicCalendarRepository управляет записями академического календаря
+type AcademicCalendarRepository struct {
+	db *sqlx.DB
+}
+
+// NewAcademicCalendarRepository создает новый AcademicCalendarRepository
+func NewAcademicCalendarRepository(db *sqlx.DB) *AcademicCalendarRepository {
+	return &AcademicCalendarRepository{db: db}
+}
+
+// Create создает новую запись календаря
+func (r *AcademicCalendarRepository) Create(ctx context.Context, entry *models.AcademicCalendarEntry) error {
+	query := `
+		INSERT INTO academic_calendar_entries (id, entry_type, name, start_date, end_date, source, external_uid, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.Source == "" {
+		entry.Source = models.CalendarSourceManual
+	}
+	entry.CreatedAt = time.Now()
+	entry.UpdatedAt = entry.CreatedAt
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.ID,
+		entry.EntryType,
+		entry.Name,
+		entry.StartDate,
+		entry.EndDate,
+		entry.Source,
+		entry.ExternalUID,
+		entry.CreatedBy,
+		entry.CreatedAt,
+		entry.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create calendar entry: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertByExternalUID создает или обновляет запись, импортированную из ICS, по UID события
+// Возвращает true, если запись была создана (false - обновлена)
+func (r *AcademicCalendarRepository) UpsertByExternalUID(ctx context.Context, entry *models.AcademicCalendarEntry) (bool, error) {
+	query := `
+		INSERT INTO academic_calendar_entries (id, entry_type, name, start_date, end_date, source, external_uid, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		ON CONFLICT (external_uid) WHERE external_uid IS NOT NULL AND deleted_at IS NULL
+		DO UPDATE SET
+			entry_type = EXCLUDED.entry_type,
+			name = EXCLUDED.name,
+			start_date = EXCLUDED.start_date,
+			end_date = EXCLUDED.end_date,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at, (xmax = 0) AS inserted
+	`
+
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	entry.Source = models.CalendarSourceICS
+
+	var inserted bool
+	err := r.db.QueryRowContext(ctx, query,
+		entry.ID,
+		entry.EntryType,
+		entry.Name,
+		entry.StartDate,
+		entry.EndDate,
+		entry.Source,
+		entry.ExternalUID,
+		entry.CreatedBy,
+	).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt, &inserted)
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert calendar entry: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// GetByID получает запись календаря по ID
+func (r *AcademicCalendarRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AcademicCalendarEntry, error) {
+	query := `
+		SELECT ` + AcademicCalendarSelectFields + `
+		FROM academic_calendar_entries
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	var entry models.AcademicCalendarEntry
+	if err := r.db.GetContext(ctx, &entry, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCalendarEntryNotFound
+		}
+		return nil, fmt.Errorf("failed to get calendar entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// GetByIDs получает записи календаря по списку ID
+func (r *AcademicCalendarRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.AcademicCalendarEntry, error) {
+	if len(ids) == 0 {
+		return []*models.AcademicCalendarEntry{}, nil
+	}
+
+	query := `
+		SELECT ` + AcademicCalendarSelectFields + `
+		FROM academic_calendar_entries
+		WHERE id = ANY($1) AND deleted_at IS NULL
+		ORDER BY start_date ASC
+	`
+
+	var entries []*models.AcademicCalendarEntry
+	if err := r.db.SelectContext(ctx, &entries, query, ids); err != nil {
+		return nil, fmt.Errorf("failed to get calendar entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// List получает записи календаря, пересекающиеся с периодом [from, to]
+// Нулевые границы означают отсутствие ограничения
+func (r *AcademicCalendarRepository) List(ctx context.Context, from, to time.Time) ([]*models.AcademicCalendarEntry, error) {
+	query := `
+		SELECT ` + AcademicCalendarSelectFields + `
+		FROM academic_calendar_entries
+		WHERE deleted_at IS NULL
+	`
+	args := []interface{}{}
+	argIndex := 1
+
+	if !from.IsZero() {
+		query += fmt.Sprintf(` AND end_date >= $%d`, argIndex)
+		args = append(args, from)
+		argIndex++
+	}
+	if !to.IsZero() {
+		query += fmt.Sprintf(` AND start_date <= $%d`, argIndex)
+		args = append(args, to)
+	}
+
+	query += ` ORDER BY start_date ASC, name ASC`
+
+	var entries []*models.AcademicCalendarEntry
+	if err := r.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list calendar entries: %w", err)
+	}
+
+	if entries == nil {
+		entries = []*models.AcademicCalendarEntry{}
+	}
+
+	return entries, nil
+}
+
+// ListBlocking получает праздники и каникулы, пересекающиеся с периодом [from, to]
+func (r *AcademicCalendarRepository) ListBlocking(ctx context.Context, from, to time.Time) (models.AcademicCalendar, error) {
+	query := `
+		SELECT ` + AcademicCalendarSelectFields + `
+		FROM academic_calendar_entries
+		WHERE deleted_at IS NULL
+		  AND entry_type IN ('holiday', 'break')
+		  AND end_date >= $1
+		  AND start_date <= $2
+		ORDER BY start_date ASC
+	`
+
+	var entries []*models.AcademicCalendarEntry
+	if err := r.db.SelectContext(ctx, &entries, query, from, to); err != nil {
+		return nil, fmt.Errorf("failed to list blocking calendar entries: %w", err)
+	}
+
+	return models.AcademicCalendar(entries), nil
+}
+
+// Update обновляет запись календаря
+func (r *AcademicCalendarRepository) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	updates["updated_at"] = time.Now()
+
+	query := "UPDATE academic_calendar_entries SET "
+	args := []interface{}{}
+	paramCount := 1
+
+	for key, value := range updates {
+		if key == "id" || key == "created_at" || key == "deleted_at" {
+			continue
+		}
+		if paramCount > 1 {
+			query += ", "
+		}
+		query += fmt.Sprintf("%s = $%d", key, paramCount)
+		args = append(args, value)
+		paramCount++
+	}
+
+	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL", paramCount)
+	args = append(args, id)
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update calendar entry: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrCalendarEntryNotFound
+	}
+
+	return nil
+}
+
+// Delete выполняет мягкое удаление записи календаря
+func (r *AcademicCalendarRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE academic_calendar_entries
+		SET deleted_at = $1, updated_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar entry: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrCalendarEntryNotFound
+	}
+
+	return nil
+}
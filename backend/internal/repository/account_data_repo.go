@@ -0,0 +1,439 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// erasureRequestSelectQuery выбирает запросы на удаление с именем и email пользователя
+	erasureRequestSelectQuery = `
+		SELECT r.id, r.user_id, CONCAT(u.first_name, ' ', u.last_name) AS user_name, u.email AS user_email,
+			r.status, r.reason, r.review_note, r.reviewed_by, r.reviewed_at, r.created_at
+		FROM account_erasure_requests r
+		JOIN users u ON u.id = r.user_id
+	`
+
+	// userRoomsCondition ограничивает сообщения комнатами чата, где пользователь - участник
+	userRoomsCondition = `
+		m.room_id IN (SELECT id FROM chat_rooms WHERE teacher_id = $1 OR student_id = $1)
+			AND m.deleted_at IS NULL
+	`
+)
+
+// AccountDataRepository собирает персональные данные пользователя для выгрузки
+// и обезличивает их по одобренному запросу на удаление
+type AccountDataRepository struct {
+	db *sqlx.DB
+}
+
+// NewAccountDataRepository создает новый AccountDataRepository
+func NewAccountDataRepository(db *sqlx.DB) *AccountDataRepository {
+	return &AccountDataRepository{db: db}
+}
+
+// GetTelegramLink получает привязку Telegram пользователя (nil, если не привязан)
+func (r *AccountDataRepository) GetTelegramLink(ctx context.Context, userID uuid.UUID) (*models.ExportTelegramLink, error) {
+	query := `
+		SELECT telegram_id, username, COALESCE(subscribed, false) AS subscribed, created_at
+		FROM telegram_users
+		WHERE user_id = $1
+	`
+
+	var link models.ExportTelegramLink
+	if err := r.db.GetContext(ctx, &link, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get telegram link: %w", err)
+	}
+	return &link, nil
+}
+
+// ListBookings получает все записи студента на занятия, включая отмененные
+func (r *AccountDataRepository) ListBookings(ctx context.Context, userID uuid.UUID) ([]*models.ExportBooking, error) {
+	query := `
+		SELECT b.id, b.lesson_id, b.status, b.booked_at, b.cancelled_at,
+			l.start_time, l.end_time, COALESCE(l.subject, '') AS subject,
+			CONCAT(t.first_name, ' ', t.last_name) AS teacher_name
+		FROM bookings b
+		JOIN lessons l ON l.id = b.lesson_id
+		JOIN users t ON t.id = l.teacher_id
+		WHERE b.student_id = $1
+		ORDER BY l.start_time
+	`
+
+	bookings := []*models.ExportBooking{}
+	if err := r.db.SelectContext(ctx, &bookings, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list bookings for export: %w", err)
+	}
+	return bookings, nil
+}
+
+// ListCreditTransactions получает историю операций с кредитами пользователя
+func (r *AccountDataRepository) ListCreditTransactions(ctx context.Context, userID uuid.UUID) ([]*models.ExportCreditTransaction, error) {
+	query := `
+		SELECT id, amount, operation_type, COALESCE(reason, '') AS reason, booking_id,
+			balance_before, balance_after, created_at
+		FROM credit_transactions
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	transactions := []*models.ExportCreditTransaction{}
+	if err := r.db.SelectContext(ctx, &transactions, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list credit transactions for export: %w", err)
+	}
+	return transactions, nil
+}
+
+// ListMessages получает сообщения всех комнат чата, в которых участвует пользователь
+func (r *AccountDataRepository) ListMessages(ctx context.Context, userID uuid.UUID) ([]*models.ExportMessage, error) {
+	query := `
+		SELECT m.id, m.room_id, m.sender_id, COALESCE(CONCAT(s.first_name, ' ', s.last_name), '') AS sender_name,
+			m.message_text, m.status, m.created_at, m.edited_at
+		FROM messages m
+		LEFT JOIN users s ON s.id = m.sender_id
+		WHERE ` + userRoomsCondition + `
+		ORDER BY m.room_id, m.created_at
+	`
+
+	messages := []*models.ExportMessage{}
+	if err := r.db.SelectContext(ctx, &messages, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list messages for export: %w", err)
+	}
+	return messages, nil
+}
+
+// ListMessageEdits получает историю правок сообщений комнат чата, в которых участвует пользователь
+func (r *AccountDataRepository) ListMessageEdits(ctx context.Context, userID uuid.UUID) ([]*models.ExportMessageEdit, error) {
+	query := `
+		SELECT e.message_id, e.previous_text, e.edited_by, e.edited_at
+		FROM message_edits e
+		JOIN messages m ON m.id = e.message_id
+		WHERE ` + userRoomsCondition + `
+		ORDER BY e.message_id, e.edited_at
+	`
+
+	edits := []*models.ExportMessageEdit{}
+	if err := r.db.SelectContext(ctx, &edits, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list message edits for export: %w", err)
+	}
+	return edits, nil
+}
+
+// ListAttachments получает вложения сообщений комнат чата, в которых участвует пользователь
+func (r *AccountDataRepository) ListAttachments(ctx context.Context, userID uuid.UUID) ([]*models.ExportAttachment, error) {
+	query := `
+		SELECT a.id, a.message_id, a.file_name, a.file_path, a.file_size, a.mime_type, a.uploaded_at, a.storage_key
+		FROM file_attachments a
+		JOIN messages m ON m.id = a.message_id
+		WHERE ` + userRoomsCondition + `
+		ORDER BY a.uploaded_at
+	`
+
+	attachments := []*models.ExportAttachment{}
+	if err := r.db.SelectContext(ctx, &attachments, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list attachments for export: %w", err)
+	}
+	return attachments, nil
+}
+
+// ListHomework получает домашние задания занятий, на которые пользователь записан или которые ведет
+func (r *AccountDataRepository) ListHomework(ctx context.Context, userID uuid.UUID) ([]*models.ExportHomework, error) {
+	query := `
+		SELECT l.id AS lesson_id, l.start_time, COALESCE(l.subject, '') AS subject,
+			COALESCE(l.homework_text, '') AS homework_text
+		FROM lessons l
+		WHERE (l.teacher_id = $1 OR EXISTS (
+				SELECT 1 FROM bookings b WHERE b.lesson_id = l.id AND b.student_id = $1 AND b.status = 'active'
+			))
+			AND (l.homework_text IS NOT NULL OR EXISTS (SELECT 1 FROM lesson_homework h WHERE h.lesson_id = l.id))
+		ORDER BY l.start_time
+	`
+
+	homework := []*models.ExportHomework{}
+	if err := r.db.SelectContext(ctx, &homework, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list homework for export: %w", err)
+	}
+	if len(homework) == 0 {
+		return homework, nil
+	}
+
+	lessonIDs := make([]uuid.UUID, len(homework))
+	byLesson := make(map[uuid.UUID]*models.ExportHomework, len(homework))
+	for i, hw := range homework {
+		lessonIDs[i] = hw.LessonID
+		byLesson[hw.LessonID] = hw
+	}
+
+	filesQuery := `
+		SELECT lesson_id, file_name
+		FROM lesson_homework
+		WHERE lesson_id = ANY($1)
+		ORDER BY created_at
+	`
+	var files []*models.ExportHomeworkFile
+	if err := r.db.SelectContext(ctx, &files, filesQuery, lessonIDs); err != nil {
+		return nil, fmt.Errorf("failed to list homework files for export: %w", err)
+	}
+	for _, f := range files {
+		byLesson[f.LessonID].Files = append(byLesson[f.LessonID].Files, f.FileName)
+	}
+	return homework, nil
+}
+
+// ListSwaps получает переносы записей студента
+func (r *AccountDataRepository) ListSwaps(ctx context.Context, userID uuid.UUID) ([]*models.ExportSwap, error) {
+	query := `
+		SELECT s.id, s.old_lesson_id, ol.start_time AS old_lesson_start,
+			s.new_lesson_id, nl.start_time AS new_lesson_start, s.created_at
+		FROM swaps s
+		JOIN lessons ol ON ol.id = s.old_lesson_id
+		JOIN lessons nl ON nl.id = s.new_lesson_id
+		WHERE s.student_id = $1
+		ORDER BY s.created_at
+	`
+
+	swaps := []*models.ExportSwap{}
+	if err := r.db.SelectContext(ctx, &swaps, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list swaps for export: %w", err)
+	}
+	return swaps, nil
+}
+
+// CreateErasureRequest создает запрос на удаление. У пользователя может быть только один открытый запрос
+func (r *AccountDataRepository) CreateErasureRequest(ctx context.Context, req *models.AccountErasureRequest) error {
+	query := `
+		INSERT INTO account_erasure_requests (id, user_id, status, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	req.ID = uuid.New()
+	req.Status = models.ErasureRequestPending
+	req.CreatedAt = time.Now()
+
+	if _, err := r.db.ExecContext(ctx, query, req.ID, req.UserID, req.Status, req.Reason, req.CreatedAt); err != nil {
+		if IsUniqueViolationError(err) {
+			return models.ErrErasureRequestExists
+		}
+		return fmt.Errorf("failed to create erasure request: %w", err)
+	}
+	return nil
+}
+
+// GetLatestErasureRequest получает последний запрос на удаление пользователя
+func (r *AccountDataRepository) GetLatestErasureRequest(ctx context.Context, userID uuid.UUID) (*models.AccountErasureRequest, error) {
+	query := erasureRequestSelectQuery + `
+		WHERE r.user_id = $1
+		ORDER BY r.created_at DESC
+		LIMIT 1
+	`
+
+	var req models.AccountErasureRequest
+	if err := r.db.GetContext(ctx, &req, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrErasureRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get erasure request: %w", err)
+	}
+	return &req, nil
+}
+
+// ListErasureRequests получает запросы на удаление, открытые сверху
+func (r *AccountDataRepository) ListErasureRequests(ctx context.Context, status *models.ErasureRequestStatus) ([]*models.AccountErasureRequest, error) {
+	query := erasureRequestSelectQuery + `
+		WHERE ($1::varchar IS NULL OR r.status = $1)
+		ORDER BY (r.status = 'pending') DESC, r.created_at DESC
+	`
+
+	requests := []*models.AccountErasureRequest{}
+	if err := r.db.SelectContext(ctx, &requests, query, status); err != nil {
+		return nil, fmt.Errorf("failed to list erasure requests: %w", err)
+	}
+	return requests, nil
+}
+
+// GetErasureRequestForUpdateTx получает запрос на удаление с блокировкой строки
+func (r *AccountDataRepository) GetErasureRequestForUpdateTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.AccountErasureRequest, error) {
+	query := erasureRequestSelectQuery + `
+		WHERE r.id = $1
+		FOR UPDATE OF r
+	`
+
+	var req models.AccountErasureRequest
+	err := tx.QueryRow(ctx, query, id).Scan(
+		&req.ID, &req.UserID, &req.UserName, &req.UserEmail,
+		&req.Status, &req.Reason, &req.ReviewNote, &req.ReviewedBy, &req.ReviewedAt, &req.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrErasureRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get erasure request: %w", err)
+	}
+	return &req, nil
+}
+
+// ReviewErasureRequestTx фиксирует решение администратора по запросу
+func (r *AccountDataRepository) ReviewErasureRequestTx(ctx context.Context, tx pgx.Tx, req *models.AccountErasureRequest) error {
+	query := `
+		UPDATE account_erasure_requests
+		SET status = $2, review_note = $3, reviewed_by = $4, reviewed_at = $5
+		WHERE id = $1
+	`
+
+	if _, err := tx.Exec(ctx, query, req.ID, req.Status, req.ReviewNote, req.ReviewedBy, req.ReviewedAt); err != nil {
+		return fmt.Errorf("failed to review erasure request: %w", err)
+	}
+	return nil
+}
+
+// LockUserTx блокирует строку пользователя и возвращает его до обезличивания
+func (r *AccountDataRepository) LockUserTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (*models.User, error) {
+	query := `
+		SELECT id, email, role, telegram_username
+		FROM users
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var user models.User
+	if err := tx.QueryRow(ctx, query, userID).Scan(&user.ID, &user.Email, &user.Role, &user.TelegramUsername); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to lock user: %w", err)
+	}
+	return &user, nil
+}
+
+// AnonymizeUserTx заменяет персональные данные пользователя, делает вход невозможным
+// и помечает пользователя удаленным. Строка сохраняется для финансовых записей
+func (r *AccountDataRepository) AnonymizeUserTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID, now time.Time) error {
+	query := `
+		UPDATE users
+		SET email = $2, password_hash = '', first_name = $3, last_name = $4,
+			telegram_username = NULL, parent_telegram_username = NULL, parent_chat_id = NULL,
+			payment_enabled = false, updated_at = $5, deleted_at = COALESCE(deleted_at, $5)
+		WHERE id = $1
+	`
+
+	_, err := tx.Exec(ctx, query, userID, models.ErasedEmail(userID), models.ErasedFirstName, models.ErasedLastName, now)
+	if err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	return nil
+}
+
+// AnonymizeTrialRequestsTx обезличивает заявки на пробное занятие пользователя
+// (конвертированные в него или оставленные с его email или Telegram) и заметки по ним
+func (r *AccountDataRepository) AnonymizeTrialRequestsTx(ctx context.Context, tx pgx.Tx, user *models.User) (int64, error) {
+	query := `
+		WITH erased AS (
+			UPDATE trial_requests
+			SET name = $4, phone = '', telegram = '', email = NULL, lost_reason = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE converted_user_id = $1
+				OR LOWER(email) = LOWER($2)
+				OR ($3::text <> '' AND LOWER(LTRIM(telegram, '@')) = LOWER(LTRIM($3, '@')))
+			RETURNING id
+		), notes AS (
+			UPDATE trial_request_activities
+			SET body = ''
+			WHERE trial_request_id IN (SELECT id FROM erased)
+		)
+		SELECT COUNT(*) FROM erased
+	`
+
+	var count int64
+	err := tx.QueryRow(ctx, query, user.ID, user.Email, user.TelegramUsername.String, models.ErasedText).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize trial requests: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteTelegramLinkTx удаляет привязку Telegram и токены привязки пользователя
+func (r *AccountDataRepository) DeleteTelegramLinkTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (bool, error) {
+	if _, err := tx.Exec(ctx, `DELETE FROM telegram_tokens WHERE user_id = $1`, userID); err != nil {
+		return false, fmt.Errorf("failed to delete telegram tokens: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM telegram_users WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete telegram link: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RedactMessagesTx заменяет текст сообщений пользователя, удаляет историю их правок (предыдущие версии текста)
+// и ответы модерации с их содержимым
+func (r *AccountDataRepository) RedactMessagesTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (int64, error) {
+	editsQuery := `
+		DELETE FROM message_edits
+		WHERE message_id IN (SELECT id FROM messages WHERE sender_id = $1)
+	`
+	if _, err := tx.Exec(ctx, editsQuery, userID); err != nil {
+		return 0, fmt.Errorf("failed to delete message edits: %w", err)
+	}
+
+	blockedQuery := `
+		UPDATE blocked_messages
+		SET reason = $2, ai_response = NULL
+		WHERE message_id IN (SELECT id FROM messages WHERE sender_id = $1)
+	`
+	if _, err := tx.Exec(ctx, blockedQuery, userID, models.ErasedText); err != nil {
+		return 0, fmt.Errorf("failed to redact blocked messages: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE messages SET message_text = $2 WHERE sender_id = $1`, userID, models.ErasedText)
+	if err != nil {
+		return 0, fmt.Errorf("failed to redact messages: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteAttachmentsTx удаляет вложения сообщений пользователя и возвращает их пути на диске
+// и ключи в BlobStore (заполнены только FilePath и StorageKey)
+func (r *AccountDataRepository) DeleteAttachmentsTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]*models.FileAttachment, error) {
+	query := `
+		DELETE FROM file_attachments
+		WHERE message_id IN (SELECT id FROM messages WHERE sender_id = $1)
+		RETURNING file_path, storage_key
+	`
+
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*models.FileAttachment
+	for rows.Next() {
+		att := &models.FileAttachment{}
+		if err := rows.Scan(&att.FilePath, &att.StorageKey); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment path: %w", err)
+		}
+		attachments = append(attachments, att)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to delete attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// DeleteSessionsTx завершает все сессии пользователя
+func (r *AccountDataRepository) DeleteSessionsTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// AnalyticsViews материализованные представления аналитики в порядке обновления
+var AnalyticsViews = []string{
+	"analytics_daily_revenue",
+	"analytics_daily_credit_flow",
+	"analytics_daily_utilization",
+	"analytics_daily_booking_activity",
+	"analytics_student_monthly_activity",
+}
+
+// AnalyticsRepository читает агрегаты аналитики из материализованных представлений
+type AnalyticsRepository struct {
+	db *sqlx.DB
+}
+
+// NewAnalyticsRepository создает новый AnalyticsRepository
+func NewAnalyticsRepository(db *sqlx.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// GetRevenue получает выручку по периодам и источникам
+func (r *AnalyticsRepository) GetRevenue(ctx context.Context, q *models.AnalyticsQuery) ([]*models.RevenuePoint, error) {
+	query := `
+		SELECT date_trunc($1, day::timestamp)::date AS period, source,
+			SUM(payments_count)::bigint AS payments_count,
+			SUM(amount_total)::float8 AS amount_total,
+			SUM(credits_total)::bigint AS credits_total
+		FROM analytics_daily_revenue
+		WHERE day >= $2 AND day < $3
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+
+	points := []*models.RevenuePoint{}
+	if err := r.db.SelectContext(ctx, &points, query, q.Granularity, q.From, q.To); err != nil {
+		return nil, fmt.Errorf("failed to get revenue analytics: %w", err)
+	}
+
+	return points, nil
+}
+
+// GetCreditFlow получает начисления, списания и возвраты кредитов по периодам
+func (r *AnalyticsRepository) GetCreditFlow(ctx context.Context, q *models.AnalyticsQuery) ([]*models.CreditFlowPoint, error) {
+	query := `
+		SELECT date_trunc($1, day::timestamp)::date AS period,
+			COALESCE(SUM(credits_total) FILTER (WHERE operation_type = 'add'), 0)::bigint AS added,
+			COALESCE(SUM(credits_total) FILTER (WHERE operation_type = 'deduct'), 0)::bigint AS deducted,
+			COALESCE(SUM(credits_total) FILTER (WHERE operation_type = 'refund'), 0)::bigint AS refunded
+		FROM analytics_daily_credit_flow
+		WHERE day >= $2 AND day < $3
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	points := []*models.CreditFlowPoint{}
+	if err := r.db.SelectContext(ctx, &points, query, q.Granularity, q.From, q.To); err != nil {
+		return nil, fmt.Errorf("failed to get credit flow analytics: %w", err)
+	}
+
+	return points, nil
+}
+
+// GetUtilization получает заполняемость мест за период по преподавателям и предметам
+func (r *AnalyticsRepository) GetUtilization(ctx context.Context, q *models.AnalyticsQuery, teacherID *uuid.UUID) ([]*models.UtilizationRow, error) {
+	query := `
+		SELECT u.teacher_id, CONCAT(t.first_name, ' ', t.last_name) AS teacher_name, u.subject,
+			SUM(u.lessons_count)::bigint AS lessons_count,
+			SUM(u.seats_booked)::bigint AS seats_booked,
+			SUM(u.seats_total)::bigint AS seats_total
+		FROM analytics_daily_utilization u
+		JOIN users t ON t.id = u.teacher_id
+		WHERE u.day >= $1 AND u.day < $2 AND ($3::uuid IS NULL OR u.teacher_id = $3)
+		GROUP BY u.teacher_id, t.first_name, t.last_name, u.subject
+		ORDER BY teacher_name, u.subject
+	`
+
+	rows := []*models.UtilizationRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, q.From, q.To, teacherID); err != nil {
+		return nil, fmt.Errorf("failed to get utilization analytics: %w", err)
+	}
+
+	return rows, nil
+}
+
+// GetBookingActivity получает записи, отмены и переносы по периодам
+func (r *AnalyticsRepository) GetBookingActivity(ctx context.Context, q *models.AnalyticsQuery) ([]*models.BookingActivityPoint, error) {
+	query := `
+		SELECT date_trunc($1, day::timestamp)::date AS period,
+			SUM(bookings_count)::bigint AS bookings_count,
+			SUM(cancellations_count)::bigint AS cancellations_count,
+			SUM(swaps_count)::bigint AS swaps_count
+		FROM analytics_daily_booking_activity
+		WHERE day >= $2 AND day < $3
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	points := []*models.BookingActivityPoint{}
+	if err := r.db.SelectContext(ctx, &points, query, q.Granularity, q.From, q.To); err != nil {
+		return nil, fmt.Errorf("failed to get booking activity analytics: %w", err)
+	}
+
+	return points, nil
+}
+
+// GetCohortActivity получает месяцы активности студентов, впервые записавшихся на занятие в [from, to).
+// Активность учитывается до месяца until (не включительно)
+func (r *AnalyticsRepository) GetCohortActivity(ctx context.Context, from, to, until time.Time) ([]*models.StudentMonthActivity, error) {
+	query := `
+		SELECT a.student_id, a.activity_month
+		FROM analytics_student_monthly_activity a
+		JOIN (
+			SELECT student_id
+			FROM analytics_student_monthly_activity
+			GROUP BY student_id
+			HAVING MIN(activity_month) >= $1 AND MIN(activity_month) < $2
+		) c ON c.student_id = a.student_id
+		WHERE a.activity_month < $3
+	`
+
+	activity := []*models.StudentMonthActivity{}
+	if err := r.db.SelectContext(ctx, &activity, query, from, to, until); err != nil {
+		return nil, fmt.Errorf("failed to get cohort activity: %w", err)
+	}
+
+	return activity, nil
+}
+
+// RefreshView обновляет материализованное представление без блокировки чтения и запоминает время обновления.
+// Имя представления должно быть из AnalyticsViews
+func (r *AnalyticsRepository) RefreshView(ctx context.Context, view string) (time.Duration, error) {
+	if !isAnalyticsView(view) {
+		return 0, fmt.Errorf("unknown analytics view %q", view)
+	}
+
+	start := time.Now()
+	if _, err := r.db.ExecContext(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY "+view); err != nil {
+		return 0, fmt.Errorf("failed to refresh %s: %w", view, err)
+	}
+	duration := time.Since(start)
+
+	query := `
+		INSERT INTO analytics_refresh_state (view_name, refreshed_at, duration_ms)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (view_name) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at, duration_ms = EXCLUDED.duration_ms
+	`
+	if _, err := r.db.ExecContext(ctx, query, view, time.Now(), duration.Milliseconds()); err != nil {
+		return duration, fmt.Errorf("failed to save refresh state of %s: %w", view, err)
+	}
+
+	return duration, nil
+}
+
+// GetRefreshState получает время последнего обновления представлений
+func (r *AnalyticsRepository) GetRefreshState(ctx context.Context) ([]*models.AnalyticsRefreshState, error) {
+	query := `
+		SELECT view_name, refreshed_at, duration_ms
+		FROM analytics_refresh_state
+		ORDER BY view_name
+	`
+
+	states := []*models.AnalyticsRefreshState{}
+	if err := r.db.SelectContext(ctx, &states, query); err != nil {
+		return nil, fmt.Errorf("failed to get analytics refresh state: %w", err)
+	}
+
+	return states, nil
+}
+
+// isAnalyticsView проверяет имя представления по белому списку
+func isAnalyticsView(view string) bool {
+	for _, v := range AnalyticsViews {
+		if v == view {
+			return true
+		}
+	}
+	return false
+}
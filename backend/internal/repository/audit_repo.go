@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// auditHashExpr вычисляет хеш записи журнала из ее полей. Вычисляется в PostgreSQL, чтобы
+	// канонический вид JSONB и времени совпадал при записи и при проверке цепочки
+	auditHashExpr = `encode(sha256(convert_to(jsonb_build_array(
+		prev_hash, actor_id, actor_role, action, target_type, target_id, before_data, after_data,
+		ip, request_id, method, path, to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+	)::text, 'UTF8')), 'hex')`
+
+	// auditChainLockKey ключ advisory lock, сериализующий добавление записей в цепочку
+	auditChainLockKey = 727001
+
+	// auditVerifyBatchSize количество записей, проверяемых за один запрос
+	auditVerifyBatchSize = 1000
+)
+
+// AuditRepository хранит журнал аудита. Записи только добавляются (UPDATE и DELETE запрещены триггером)
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuditRepository создает новый AuditRepository
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Append добавляет запись в конец цепочки: берет хеш последней записи как prev_hash
+// и вычисляет хеш новой записи. Конкурентные вставки сериализуются advisory lock
+func (r *AuditRepository) Append(ctx context.Context, event *models.AuditEvent) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLockKey); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	query := `
+		WITH v AS (
+			SELECT $1::uuid AS actor_id, $2::varchar AS actor_role, $3::varchar AS action,
+				$4::varchar AS target_type, $5::varchar AS target_id, $6::jsonb AS before_data, $7::jsonb AS after_data,
+				$8::varchar AS ip, $9::varchar AS request_id, $10::varchar AS method, $11::varchar AS path,
+				$12::timestamptz AS created_at,
+				COALESCE((SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1), $13) AS prev_hash
+		)
+		INSERT INTO audit_events (
+			actor_id, actor_role, action, target_type, target_id, before_data, after_data,
+			ip, request_id, method, path, prev_hash, hash, created_at
+		)
+		SELECT actor_id, actor_role, action, target_type, target_id, before_data, after_data,
+			ip, request_id, method, path, prev_hash, ` + auditHashExpr + `, created_at
+		FROM v
+		RETURNING id, prev_hash, hash
+	`
+
+	err = tx.QueryRowxContext(ctx, query,
+		event.ActorID, event.ActorRole, event.Action, event.TargetType, event.TargetID,
+		nullableJSON(event.Before), nullableJSON(event.After),
+		event.IP, event.RequestID, event.Method, event.Path, event.CreatedAt, models.AuditGenesisHash,
+	).Scan(&event.ID, &event.PrevHash, &event.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to append audit event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit event: %w", err)
+	}
+	return nil
+}
+
+// List получает записи журнала по фильтру, новые сверху
+func (r *AuditRepository) List(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEvent, int, error) {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorID != nil {
+		addCondition("e.actor_id = $%d", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		addCondition("e.action = $%d", filter.Action)
+	}
+	if filter.TargetType != "" {
+		addCondition("e.target_type = $%d", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		addCondition("e.target_id = $%d", filter.TargetID)
+	}
+	if filter.From != nil {
+		addCondition("e.created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("e.created_at < $%d", *filter.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM audit_events e `+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT e.id, e.actor_id, e.actor_role, COALESCE(CONCAT(u.first_name, ' ', u.last_name), '') AS actor_name,
+			e.action, e.target_type, e.target_id, e.before_data, e.after_data,
+			e.ip, e.request_id, e.method, e.path, e.prev_hash, e.hash, e.created_at
+		FROM audit_events e
+		LEFT JOIN users u ON u.id = e.actor_id
+		%s
+		ORDER BY e.id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.Offset)
+
+	events := []*models.AuditEvent{}
+	if err := r.db.SelectContext(ctx, &events, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	return events, total, nil
+}
+
+// auditChainRow запись цепочки с пересчитанным хешем
+type auditChainRow struct {
+	ID       int64  `db:"id"`
+	PrevHash string `db:"prev_hash"`
+	Hash     string `db:"hash"`
+	Computed string `db:"computed"`
+}
+
+// Verify проходит всю цепочку по порядку и проверяет, что хеш каждой записи совпадает
+// с пересчитанным, а prev_hash - с хешем предыдущей записи
+func (r *AuditRepository) Verify(ctx context.Context) (*models.AuditVerifyResult, error) {
+	query := `
+		SELECT id, prev_hash, hash, ` + auditHashExpr + ` AS computed
+		FROM audit_events
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	result := &models.AuditVerifyResult{Valid: true}
+	lastID := int64(0)
+	prevHash := models.AuditGenesisHash
+	for {
+		var rows []auditChainRow
+		if err := r.db.SelectContext(ctx, &rows, query, lastID, auditVerifyBatchSize); err != nil {
+			return nil, fmt.Errorf("failed to read audit chain: %w", err)
+		}
+
+		for _, row := range rows {
+			result.Checked++
+			switch {
+			case row.PrevHash != prevHash:
+				return brokenAuditChain(result, row.ID, "prev_hash does not match the previous event"), nil
+			case row.Hash != row.Computed:
+				return brokenAuditChain(result, row.ID, "event content does not match its hash"), nil
+			}
+			prevHash = row.Hash
+			lastID = row.ID
+		}
+
+		if len(rows) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// brokenAuditChain отмечает результат проверки как нарушенный на записи id
+func brokenAuditChain(result *models.AuditVerifyResult, id int64, reason string) *models.AuditVerifyResult {
+	result.Valid = false
+	result.BrokenAtID = &id
+	result.Reason = reason
+	return result
+}
+
+// nullableJSON возвращает nil для пустого JSON, чтобы в БД записался NULL
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// storedFileTables таблицы с записями о загруженных файлах по источнику
+var storedFileTables = map[string]string{
+	models.StoredFileSourceChat:             "file_attachments",
+	models.StoredFileSourceHomework:         "lesson_homework",
+	models.StoredFileSourceLessonBroadcasts: "broadcast_files",
+	models.StoredFileSourceBroadcasts:       "broadcast_attachments",
+}
+
+// blobLockClass первый ключ advisory lock объектов BlobStore (второй - хеш ключа объекта)
+const blobLockClass = 727002
+
+// BlobRepository управляет ссылками записей о файлах на объекты BlobStore
+type BlobRepository struct {
+	db *sqlx.DB
+}
+
+// NewBlobRepository создает новый BlobRepository
+func NewBlobRepository(db *sqlx.DB) *BlobRepository {
+	return &BlobRepository{db: db}
+}
+
+// LockKey выполняет fn в транзакции под advisory lock ключа объекта: сохранение, резервирование
+// и удаление объекта с одним ключом не пересекаются между запросами и репликами
+func (r *BlobRepository) LockKey(ctx context.Context, key string, fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, blobLockClass, key); err != nil {
+		return fmt.Errorf("failed to lock storage key: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit storage key transaction: %w", err)
+	}
+	return nil
+}
+
+// ReserveTx резервирует объект за загрузкой, запись о которой еще не создана.
+// Повторная загрузка того же содержимого продлевает резерв
+func (r *BlobRepository) ReserveTx(ctx context.Context, tx *sqlx.Tx, key string) error {
+	query := `
+		INSERT INTO blob_reservations (storage_key, reserved_at)
+		VALUES ($1, CURRENT_TIMESTAMP)
+		ON CONFLICT (storage_key) DO UPDATE SET reserved_at = EXCLUDED.reserved_at
+	`
+
+	if _, err := tx.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("failed to reserve storage key: %w", err)
+	}
+	return nil
+}
+
+// IsBlobReferencedTx проверяет, ссылается ли на объект хотя бы одна запись о файле или резерв загрузки,
+// сделанный позже reservedAfter. Одинаковое содержимое хранится одним объектом, поэтому объект удаляется только без ссылок
+func (r *BlobRepository) IsBlobReferencedTx(ctx context.Context, tx *sqlx.Tx, key string, reservedAfter time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM file_attachments WHERE storage_key = $1)
+			OR EXISTS (SELECT 1 FROM lesson_homework WHERE storage_key = $1)
+			OR EXISTS (SELECT 1 FROM broadcast_files WHERE storage_key = $1)
+			OR EXISTS (SELECT 1 FROM broadcast_attachments WHERE storage_key = $1)
+			OR EXISTS (SELECT 1 FROM blob_reservations WHERE storage_key = $1 AND reserved_at > $2)
+	`
+
+	var referenced bool
+	if err := tx.GetContext(ctx, &referenced, query, key, reservedAfter); err != nil {
+		return false, fmt.Errorf("failed to check blob references: %w", err)
+	}
+	return referenced, nil
+}
+
+// ListLegacyFiles получает записи источника, файлы которых еще лежат на локальном диске
+func (r *BlobRepository) ListLegacyFiles(ctx context.Context, source string) ([]*models.LegacyFile, error) {
+	table, ok := storedFileTables[source]
+	if !ok {
+		return nil, fmt.Errorf("unknown file source: %s", source)
+	}
+
+	query := `
+		SELECT id, file_name, file_path, mime_type
+		FROM ` + table + `
+		WHERE storage_key IS NULL
+		ORDER BY id
+	`
+
+	files := []*models.LegacyFile{}
+	if err := r.db.SelectContext(ctx, &files, query); err != nil {
+		return nil, fmt.Errorf("failed to list legacy files: %w", err)
+	}
+	for _, f := range files {
+		f.Source = source
+	}
+	return files, nil
+}
+
+// SetStorageKey привязывает запись к объекту BlobStore после переноса файла.
+// Возвращает false, если запись удалена или уже перенесена
+func (r *BlobRepository) SetStorageKey(ctx context.Context, source string, id uuid.UUID, key, hash string) (bool, error) {
+	table, ok := storedFileTables[source]
+	if !ok {
+		return false, fmt.Errorf("unknown file source: %s", source)
+	}
+
+	query := `
+		UPDATE ` + table + `
+		SET storage_key = $2, content_hash = $3
+		WHERE id = $1 AND storage_key IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, key, hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to set storage key: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// thumbnailTables таблицы, записи которых ссылаются на превью
+var thumbnailTables = map[string]string{
+	models.StoredFileSourceChat:     "file_attachments",
+	models.StoredFileSourceHomework: "lesson_homework",
+}
+
+// SetThumbnailKey привязывает к записи построенное превью.
+// Возвращает false, если запись уже удалена
+func (r *BlobRepository) SetThumbnailKey(ctx context.Context, source string, id uuid.UUID, key string) (bool, error) {
+	table, ok := thumbnailTables[source]
+	if !ok {
+		return false, fmt.Errorf("thumbnails are not supported for file source: %s", source)
+	}
+
+	query := `UPDATE ` + table + ` SET thumbnail_key = $2 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to set thumbnail key: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// IsThumbnailReferenced проверяет, ссылается ли на превью хотя бы одна запись о файле.
+// Превью строится по хешу содержимого и общее для одинаковых файлов из разных источников
+func (r *BlobRepository) IsThumbnailReferenced(ctx context.Context, key string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM file_attachments WHERE thumbnail_key = $1)
+			OR EXISTS (SELECT 1 FROM lesson_homework WHERE thumbnail_key = $1)
+	`
+
+	var referenced bool
+	if err := r.db.GetContext(ctx, &referenced, query, key); err != nil {
+		return false, fmt.Errorf("failed to check thumbnail references: %w", err)
+	}
+	return referenced, nil
+}
+
+// liveStoredFilesQuery записи о файлах всех источников, которые еще используются: сообщение, чат или занятие
+// не удалены либо удалены позже $1; вложения админских рассылок используются, пока существует рассылка.
+// teacher_id - преподаватель занятия или личного чата (NULL для групповых чатов и админских рассылок)
+const liveStoredFilesQuery = `
+	SELECT fa.storage_key, fa.thumbnail_key, fa.file_path, fa.file_size,
+		m.sender_id AS uploaded_by, cr.teacher_id, 'chat' AS source
+	FROM file_attachments fa
+	JOIN messages m ON m.id = fa.message_id
+	JOIN chat_rooms cr ON cr.id = m.room_id
+	WHERE (m.deleted_at IS NULL OR m.deleted_at > $1)
+		AND (cr.deleted_at IS NULL OR cr.deleted_at > $1)
+	UNION ALL
+	SELECT lh.storage_key, lh.thumbnail_key, lh.file_path, lh.file_size,
+		lh.created_by AS uploaded_by, l.teacher_id, 'homework' AS source
+	FROM lesson_homework lh
+	JOIN lessons l ON l.id = lh.lesson_id
+	WHERE l.deleted_at IS NULL OR l.deleted_at > $1
+	UNION ALL
+	SELECT bf.storage_key, NULL::VARCHAR AS thumbnail_key, bf.file_path, bf.file_size,
+		lb.sender_id AS uploaded_by, l.teacher_id, 'lesson_broadcasts' AS source
+	FROM broadcast_files bf
+	JOIN lesson_broadcasts lb ON lb.id = bf.broadcast_id
+	JOIN lessons l ON l.id = lb.lesson_id
+	WHERE l.deleted_at IS NULL OR l.deleted_at > $1
+	UNION ALL
+	SELECT ba.storage_key, NULL::VARCHAR AS thumbnail_key, ba.file_path, ba.file_size,
+		b.created_by AS uploaded_by, NULL::UUID AS teacher_id, 'broadcasts' AS source
+	FROM broadcast_attachments ba
+	JOIN broadcasts b ON b.id = ba.broadcast_id
+`
+
+// ListLiveKeys получает ключи объектов и превью, на которые ссылаются используемые записи.
+// Записи сообщений, чатов и занятий, удаленных раньше deletedBefore, не учитываются
+func (r *BlobRepository) ListLiveKeys(ctx context.Context, deletedBefore time.Time) (map[string]struct{}, error) {
+	query := `
+		SELECT storage_key FROM (` + liveStoredFilesQuery + `) f WHERE storage_key IS NOT NULL
+		UNION
+		SELECT thumbnail_key FROM (` + liveStoredFilesQuery + `) f WHERE thumbnail_key IS NOT NULL
+	`
+
+	var keys []string
+	if err := r.db.SelectContext(ctx, &keys, query, deletedBefore); err != nil {
+		return nil, fmt.Errorf("failed to list live storage keys: %w", err)
+	}
+	live := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		live[key] = struct{}{}
+	}
+	return live, nil
+}
+
+// IsLiveReferencedTx проверяет, ссылается ли на объект или превью хотя бы одна используемая запись
+// или резерв загрузки, сделанный позже reservedAfter
+func (r *BlobRepository) IsLiveReferencedTx(ctx context.Context, tx *sqlx.Tx, key string, deletedBefore, reservedAfter time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM (` + liveStoredFilesQuery + `) f
+			WHERE f.storage_key = $2 OR f.thumbnail_key = $2
+		) OR EXISTS (
+			SELECT 1 FROM blob_reservations WHERE storage_key = $2 AND reserved_at > $3
+		)
+	`
+
+	var referenced bool
+	if err := tx.GetContext(ctx, &referenced, query, deletedBefore, key, reservedAfter); err != nil {
+		return false, fmt.Errorf("failed to check live references: %w", err)
+	}
+	return referenced, nil
+}
+
+// PurgeReservations удаляет резервы загрузок, сделанные раньше before
+func (r *BlobRepository) PurgeReservations(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM blob_reservations WHERE reserved_at <= $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge blob reservations: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ListLiveLegacyPaths получает пути на локальном диске файлов источника, которые еще не перенесены в BlobStore
+// и используются
+func (r *BlobRepository) ListLiveLegacyPaths(ctx context.Context, source string, deletedBefore time.Time) ([]string, error) {
+	if _, ok := storedFileTables[source]; !ok {
+		return nil, fmt.Errorf("unknown file source: %s", source)
+	}
+
+	query := `
+		SELECT f.file_path FROM (` + liveStoredFilesQuery + `) f
+		WHERE f.source = $2 AND f.storage_key IS NULL
+	`
+
+	paths := []string{}
+	if err := r.db.SelectContext(ctx, &paths, query, deletedBefore, source); err != nil {
+		return nil, fmt.Errorf("failed to list legacy file paths: %w", err)
+	}
+	return paths, nil
+}
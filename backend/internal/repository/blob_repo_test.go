@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBlobRepository_Reservation резерв загрузки считается ссылкой на объект, пока не истек
+func TestBlobRepository_Reservation(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	repo := NewBlobRepository(db)
+	ctx := context.Background()
+	key := "chat/ab/" + uuid.NewString() + ".txt"
+
+	isReferenced := func(reservedAfter time.Time) bool {
+		var referenced bool
+		err := repo.LockKey(ctx, key, func(tx *sqlx.Tx) error {
+			var err error
+			referenced, err = repo.IsBlobReferencedTx(ctx, tx, key, reservedAfter)
+			return err
+		})
+		require.NoError(t, err)
+		return referenced
+	}
+
+	assert.False(t, isReferenced(time.Now().Add(-time.Hour)), "key without rows and reservations")
+
+	require.NoError(t, repo.LockKey(ctx, key, func(tx *sqlx.Tx) error {
+		return repo.ReserveTx(ctx, tx, key)
+	}))
+	// Повторный резерв продлевает существующий
+	require.NoError(t, repo.LockKey(ctx, key, func(tx *sqlx.Tx) error {
+		return repo.ReserveTx(ctx, tx, key)
+	}))
+
+	assert.True(t, isReferenced(time.Now().Add(-time.Hour)), "fresh reservation must count as a reference")
+	assert.False(t, isReferenced(time.Now().Add(time.Minute)), "expired reservation must not count as a reference")
+}
+
+// TestBlobRepository_LiveReferenceReservation очистка хранилища не удаляет старый объект без записей,
+// на который только что попала дедупликацией новая загрузка
+func TestBlobRepository_LiveReferenceReservation(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	repo := NewBlobRepository(db)
+	ctx := context.Background()
+	key := "homework/cd/" + uuid.NewString() + ".pdf"
+	cutoff := time.Now().Add(-72 * time.Hour)
+
+	isLive := func(reservedAfter time.Time) bool {
+		var referenced bool
+		err := repo.LockKey(ctx, key, func(tx *sqlx.Tx) error {
+			var err error
+			referenced, err = repo.IsLiveReferencedTx(ctx, tx, key, cutoff, reservedAfter)
+			return err
+		})
+		require.NoError(t, err)
+		return referenced
+	}
+
+	assert.False(t, isLive(time.Now().Add(-time.Hour)), "orphaned object must be collectable")
+
+	require.NoError(t, repo.LockKey(ctx, key, func(tx *sqlx.Tx) error {
+		return repo.ReserveTx(ctx, tx, key)
+	}))
+	assert.True(t, isLive(time.Now().Add(-time.Hour)), "dedup target reserved by an upload must not be collected")
+
+	_, err := repo.PurgeReservations(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.False(t, isLive(time.Now().Add(-time.Hour)), "purged reservation must not keep the object")
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// BroadcastAudienceRepository управляет шаблонами рассылок и сохранёнными аудиториями,
+// а также вычисляет получателей аудитории в момент отправки
+type BroadcastAudienceRepository struct {
+	db *sqlx.DB
+}
+
+// NewBroadcastAudienceRepository создает новый BroadcastAudienceRepository
+func NewBroadcastAudienceRepository(db *sqlx.DB) *BroadcastAudienceRepository {
+	return &BroadcastAudienceRepository{db: db}
+}
+
+// CreateTemplate создает шаблон рассылки
+func (r *BroadcastAudienceRepository) CreateTemplate(ctx context.Context, template *models.BroadcastTemplate) error {
+	query := `
+		INSERT INTO broadcast_templates (id, name, body, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	template.ID = uuid.New()
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+
+	_, err := r.db.ExecContext(ctx, query,
+		template.ID,
+		template.Name,
+		template.Body,
+		template.CreatedBy,
+		template.CreatedAt,
+		template.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create broadcast template: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplateByID получает активный шаблон рассылки по ID
+func (r *BroadcastAudienceRepository) GetTemplateByID(ctx context.Context, id uuid.UUID) (*models.BroadcastTemplate, error) {
+	query := `
+		SELECT id, name, body, created_by, created_at, updated_at, deleted_at
+		FROM broadcast_templates
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	var template models.BroadcastTemplate
+	if err := r.db.GetContext(ctx, &template, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBroadcastTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get broadcast template: %w", err)
+	}
+
+	return &template, nil
+}
+
+// ListTemplates получает все активные шаблоны рассылок
+func (r *BroadcastAudienceRepository) ListTemplates(ctx context.Context) ([]*models.BroadcastTemplate, error) {
+	query := `
+		SELECT id, name, body, created_by, created_at, updated_at, deleted_at
+		FROM broadcast_templates
+		WHERE deleted_at IS NULL
+		ORDER BY name
+	`
+
+	templates := []*models.BroadcastTemplate{}
+	if err := r.db.SelectContext(ctx, &templates, query); err != nil {
+		return nil, fmt.Errorf("failed to list broadcast templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// UpdateTemplate обновляет название и текст шаблона
+func (r *BroadcastAudienceRepository) UpdateTemplate(ctx context.Context, id uuid.UUID, name, body string) error {
+	query := `
+		UPDATE broadcast_templates
+		SET name = $1, body = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, name, body, id)
+	if err != nil {
+		return fmt.Errorf("failed to update broadcast template: %w", err)
+	}
+
+	return expectAffected(result, ErrBroadcastTemplateNotFound)
+}
+
+// DeleteTemplate выполняет мягкое удаление шаблона
+func (r *BroadcastAudienceRepository) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE broadcast_templates
+		SET deleted_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete broadcast template: %w", err)
+	}
+
+	return expectAffected(result, ErrBroadcastTemplateNotFound)
+}
+
+// CreateAudience создает сохранённую аудиторию
+func (r *BroadcastAudienceRepository) CreateAudience(ctx context.Context, audience *models.BroadcastAudience) error {
+	query := `
+		INSERT INTO broadcast_audiences (id, name, description, filter, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	audience.ID = uuid.New()
+	audience.CreatedAt = time.Now()
+	audience.UpdatedAt = audience.CreatedAt
+
+	_, err := r.db.ExecContext(ctx, query,
+		audience.ID,
+		audience.Name,
+		audience.Description,
+		audience.Filter,
+		audience.CreatedBy,
+		audience.CreatedAt,
+		audience.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create broadcast audience: %w", err)
+	}
+
+	return nil
+}
+
+// GetAudienceByID получает активную аудиторию по ID
+func (r *BroadcastAudienceRepository) GetAudienceByID(ctx context.Context, id uuid.UUID) (*models.BroadcastAudience, error) {
+	query := `
+		SELECT id, name, description, filter, created_by, created_at, updated_at, deleted_at
+		FROM broadcast_audiences
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	var audience models.BroadcastAudience
+	if err := r.db.GetContext(ctx, &audience, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBroadcastAudienceNotFound
+		}
+		return nil, fmt.Errorf("failed to get broadcast audience: %w", err)
+	}
+
+	return &audience, nil
+}
+
+// ListAudiences получает все активные аудитории
+func (r *BroadcastAudienceRepository) ListAudiences(ctx context.Context) ([]*models.BroadcastAudience, error) {
+	query := `
+		SELECT id, name, description, filter, created_by, created_at, updated_at, deleted_at
+		FROM broadcast_audiences
+		WHERE deleted_at IS NULL
+		ORDER BY name
+	`
+
+	audiences := []*models.BroadcastAudience{}
+	if err := r.db.SelectContext(ctx, &audiences, query); err != nil {
+		return nil, fmt.Errorf("failed to list broadcast audiences: %w", err)
+	}
+
+	return audiences, nil
+}
+
+// UpdateAudience обновляет название, описание и фильтр аудитории
+func (r *BroadcastAudienceRepository) UpdateAudience(ctx context.Context, id uuid.UUID, name, description string, filter models.AudienceFilter) error {
+	query := `
+		UPDATE broadcast_audiences
+		SET name = $1, description = $2, filter = $3
+		WHERE id = $4 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, name, description, filter, id)
+	if err != nil {
+		return fmt.Errorf("failed to update broadcast audience: %w", err)
+	}
+
+	return expectAffected(result, ErrBroadcastAudienceNotFound)
+}
+
+// DeleteAudience выполняет мягкое удаление аудитории
+func (r *BroadcastAudienceRepository) DeleteAudience(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE broadcast_audiences
+		SET deleted_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete broadcast audience: %w", err)
+	}
+
+	return expectAffected(result, ErrBroadcastAudienceNotFound)
+}
+
+// ResolveAudience возвращает ID активных пользователей с подпиской на Telegram-уведомления,
+// подходящих под фильтр на момент now
+func (r *BroadcastAudienceRepository) ResolveAudience(ctx context.Context, filter models.AudienceFilter, now time.Time) ([]uuid.UUID, error) {
+	conditions := []string{"u.deleted_at IS NULL"}
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.Roles) > 0 {
+		roles := make([]string, len(filter.Roles))
+		for i, role := range filter.Roles {
+			roles[i] = string(role)
+		}
+		conditions = append(conditions, "u.role = ANY("+arg(roles)+")")
+	}
+
+	if len(filter.SubjectIDs) > 0 {
+		// Предмет занятия хранится текстом, поэтому сопоставляем по названию из справочника subjects
+		p := arg(filter.SubjectIDs)
+		conditions = append(conditions, `(
+			EXISTS (
+				SELECT 1 FROM bookings b
+				JOIN lessons l ON l.id = b.lesson_id AND l.deleted_at IS NULL
+				JOIN subjects s ON LOWER(s.name) = LOWER(l.subject)
+				WHERE b.student_id = u.id AND b.status = 'active' AND s.id = ANY(`+p+`)
+			)
+			OR EXISTS (
+				SELECT 1 FROM teacher_subjects ts
+				WHERE ts.teacher_id = u.id AND ts.subject_id = ANY(`+p+`)
+			)
+		)`)
+	}
+
+	if len(filter.TeacherIDs) > 0 {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM bookings b
+			JOIN lessons l ON l.id = b.lesson_id AND l.deleted_at IS NULL
+			WHERE b.student_id = u.id AND b.status = 'active' AND l.teacher_id = ANY(`+arg(filter.TeacherIDs)+`)
+		)`)
+	}
+
+	if filter.MaxBalance != nil {
+		conditions = append(conditions, "COALESCE(c.balance, 0) <= "+arg(*filter.MaxBalance))
+	}
+
+	if filter.InactiveDays != nil {
+		since := arg(now.AddDate(0, 0, -*filter.InactiveDays))
+		until := arg(now)
+		conditions = append(conditions, `NOT EXISTS (
+			SELECT 1 FROM bookings b
+			JOIN lessons l ON l.id = b.lesson_id AND l.deleted_at IS NULL
+			WHERE b.student_id = u.id AND b.status = 'active'
+				AND l.start_time >= `+since+` AND l.start_time <= `+until+`
+		)`, `NOT EXISTS (
+			SELECT 1 FROM lessons l
+			WHERE l.teacher_id = u.id AND l.deleted_at IS NULL
+				AND l.start_time >= `+since+` AND l.start_time <= `+until+`
+		)`)
+	}
+
+	query := `
+		SELECT u.id
+		FROM users u
+		JOIN telegram_users tu ON tu.user_id = u.id AND tu.subscribed = true
+		LEFT JOIN credits c ON c.user_id = u.id
+		WHERE ` + strings.Join(conditions, "\n\t\t\tAND ") + `
+		ORDER BY u.id
+	`
+
+	userIDs := []uuid.UUID{}
+	if err := r.db.SelectContext(ctx, &userIDs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to resolve broadcast audience: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// GetRecipients получает данные для рендеринга подстановок одним запросом:
+// имя, баланс кредитов и ближайшее будущее занятие (как студента или как преподавателя)
+func (r *BroadcastAudienceRepository) GetRecipients(ctx context.Context, userIDs []uuid.UUID, now time.Time) ([]*models.BroadcastRecipient, error) {
+	recipients := []*models.BroadcastRecipient{}
+	if len(userIDs) == 0 {
+		return recipients, nil
+	}
+
+	query := `
+		SELECT u.id AS user_id, u.first_name, u.last_name,
+			COALESCE(c.balance, 0) AS balance,
+			LEAST(
+				(SELECT MIN(l.start_time) FROM bookings b
+					JOIN lessons l ON l.id = b.lesson_id AND l.deleted_at IS NULL
+					WHERE b.student_id = u.id AND b.status = 'active' AND l.start_time > $2),
+				(SELECT MIN(l.start_time) FROM lessons l
+					WHERE l.teacher_id = u.id AND l.deleted_at IS NULL AND l.start_time > $2)
+			) AS next_lesson_at
+		FROM users u
+		LEFT JOIN credits c ON c.user_id = u.id
+		WHERE u.id = ANY($1)
+	`
+
+	if err := r.db.SelectContext(ctx, &recipients, query, userIDs, now); err != nil {
+		return nil, fmt.Errorf("failed to get broadcast recipients: %w", err)
+	}
+
+	return recipients, nil
+}
+
+// expectAffected возвращает notFound, если запрос не затронул ни одной строки
+func expectAffected(result sql.Result, notFound error) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // BroadcastRepository интерфейс для работы с массовыми рассылками
@@ -25,6 +26,16 @@ type BroadcastRepository interface {
 	GetLogByBroadcastAndUser(ctx context.Context, broadcastID, userID uuid.UUID) (*models.BroadcastLog, error)
 	HasSuccessfulDelivery(ctx context.Context, broadcastID, userID uuid.UUID) (bool, error)
 	UpdateLogStatus(ctx context.Context, logID uuid.UUID, status, errorMsg string) error
+	// Scheduling
+	ClaimDueScheduled(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+	// Rich media: вложения и идентификаторы доставленных сообщений
+	CreateAttachment(ctx context.Context, attachment *models.BroadcastAttachment) error
+	GetAttachments(ctx context.Context, broadcastID uuid.UUID) ([]*models.BroadcastAttachment, error)
+	SetAttachmentFileID(ctx context.Context, id uuid.UUID, fileID string) error
+	SetLogDelivery(ctx context.Context, logID uuid.UUID, chatID, messageID int64, attachmentMessageIDs []int64) error
+	GetDeliveredLogs(ctx context.Context, broadcastID uuid.UUID) ([]*models.BroadcastLog, error)
+	UpdateMessage(ctx context.Context, id uuid.UUID, message string) error
+	MarkRecalled(ctx context.Context, id uuid.UUID) (bool, error)
 }
 
 // BroadcastRepo реализация BroadcastRepository
@@ -40,9 +51,11 @@ func NewBroadcastRepository(db *sqlx.DB) BroadcastRepository {
 // Create создает новую рассылку
 func (r *BroadcastRepo) Create(ctx context.Context, broadcast *models.Broadcast) (*models.Broadcast, error) {
 	query := `
-		INSERT INTO broadcasts (id, list_id, message, sent_count, failed_count, status, created_by, created_at, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, list_id, message, sent_count, failed_count, status, created_by, created_at, completed_at
+		INSERT INTO broadcasts (id, list_id, message, sent_count, failed_count, status, created_by, created_at, completed_at,
+			scheduled_at, template_id, audience_id, parse_mode, buttons)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, list_id, message, sent_count, failed_count, status, created_by, created_at, completed_at, scheduled_at, template_id, audience_id,
+			parse_mode, buttons, edited_at, recalled_at
 	`
 
 	broadcast.ID = uuid.New()
@@ -63,6 +76,11 @@ func (r *BroadcastRepo) Create(ctx context.Context, broadcast *models.Broadcast)
 		broadcast.CreatedBy,
 		broadcast.CreatedAt,
 		broadcast.CompletedAt,
+		broadcast.ScheduledAt,
+		broadcast.TemplateID,
+		broadcast.AudienceID,
+		broadcast.ParseMode,
+		broadcast.Buttons,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create broadcast: %w", err)
@@ -74,7 +92,8 @@ func (r *BroadcastRepo) Create(ctx context.Context, broadcast *models.Broadcast)
 // GetByID получает рассылку по ID
 func (r *BroadcastRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Broadcast, error) {
 	query := `
-		SELECT id, list_id, message, sent_count, failed_count, status, created_by, created_at, completed_at
+		SELECT id, list_id, message, sent_count, failed_count, status, created_by, created_at, completed_at, scheduled_at, template_id, audience_id,
+			parse_mode, buttons, edited_at, recalled_at
 		FROM broadcasts
 		WHERE id = $1
 	`
@@ -103,7 +122,8 @@ func (r *BroadcastRepo) GetAll(ctx context.Context, limit, offset int) ([]*model
 
 	// Получаем список рассылок с пагинацией
 	query := `
-		SELECT id, list_id, message, sent_count, failed_count, status, created_by, created_at, completed_at
+		SELECT id, list_id, message, sent_count, failed_count, status, created_by, created_at, completed_at, scheduled_at, template_id, audience_id,
+			parse_mode, buttons, edited_at, recalled_at
 		FROM broadcasts
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -158,6 +178,36 @@ func (r *BroadcastRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status s
 	return nil
 }
 
+// ClaimDueScheduled атомарно переводит наступившие отложенные рассылки в pending и возвращает их ID.
+// SKIP LOCKED не дает нескольким экземплярам сервера забрать одну и ту же рассылку.
+func (r *BroadcastRepo) ClaimDueScheduled(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		UPDATE broadcasts
+		SET status = $1
+		WHERE id IN (
+			SELECT id FROM broadcasts
+			WHERE status = $2 AND scheduled_at <= $3
+			ORDER BY scheduled_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`
+
+	var ids []uuid.UUID
+	err := r.db.SelectContext(ctx, &ids, query,
+		models.BroadcastStatusPending,
+		models.BroadcastStatusScheduled,
+		now,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim scheduled broadcasts: %w", err)
+	}
+
+	return ids, nil
+}
+
 // UpdateCounts обновляет счетчики отправленных и неудавшихся сообщений
 func (r *BroadcastRepo) UpdateCounts(ctx context.Context, id uuid.UUID, sentCount, failedCount int) error {
 	query := `
@@ -218,7 +268,10 @@ func (r *BroadcastRepo) GetLogsByBroadcastID(ctx context.Context, broadcastID uu
 			bl.telegram_id,
 			bl.status,
 			bl.error,
-			bl.sent_at
+			bl.sent_at,
+			bl.chat_id,
+			bl.message_id,
+			bl.attachment_message_ids
 		FROM broadcast_logs bl
 		WHERE bl.broadcast_id = $1
 		ORDER BY bl.sent_at DESC
@@ -244,7 +297,10 @@ func (r *BroadcastRepo) GetLogByBroadcastAndUser(ctx context.Context, broadcastI
 			bl.telegram_id,
 			bl.status,
 			bl.error,
-			bl.sent_at
+			bl.sent_at,
+			bl.chat_id,
+			bl.message_id,
+			bl.attachment_message_ids
 		FROM broadcast_logs bl
 		WHERE bl.broadcast_id = $1 AND bl.user_id = $2
 		LIMIT 1
@@ -304,3 +360,135 @@ func (r *BroadcastRepo) UpdateLogStatus(ctx context.Context, logID uuid.UUID, st
 
 	return nil
 }
+
+// CreateAttachment сохраняет вложение рассылки
+func (r *BroadcastRepo) CreateAttachment(ctx context.Context, attachment *models.BroadcastAttachment) error {
+	query := `
+		INSERT INTO broadcast_attachments (id, broadcast_id, kind, file_name, file_path, file_size, mime_type,
+			storage_key, content_hash, position, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	attachment.ID = uuid.New()
+	attachment.CreatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, query,
+		attachment.ID,
+		attachment.BroadcastID,
+		attachment.Kind,
+		attachment.FileName,
+		attachment.FilePath,
+		attachment.FileSize,
+		attachment.MimeType,
+		attachment.StorageKey,
+		attachment.ContentHash,
+		attachment.Position,
+		attachment.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create broadcast attachment: %w", err)
+	}
+
+	return nil
+}
+
+// GetAttachments получает вложения рассылки в порядке отправки
+func (r *BroadcastRepo) GetAttachments(ctx context.Context, broadcastID uuid.UUID) ([]*models.BroadcastAttachment, error) {
+	query := `
+		SELECT id, broadcast_id, kind, file_name, file_path, file_size, mime_type, telegram_file_id,
+			storage_key, content_hash, position, created_at
+		FROM broadcast_attachments
+		WHERE broadcast_id = $1
+		ORDER BY position
+	`
+
+	attachments := []*models.BroadcastAttachment{}
+	if err := r.db.SelectContext(ctx, &attachments, query, broadcastID); err != nil {
+		return nil, fmt.Errorf("failed to get broadcast attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// SetAttachmentFileID сохраняет file_id, выданный Telegram после первой загрузки файла
+func (r *BroadcastRepo) SetAttachmentFileID(ctx context.Context, id uuid.UUID, fileID string) error {
+	query := `UPDATE broadcast_attachments SET telegram_file_id = $1 WHERE id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, fileID, id); err != nil {
+		return fmt.Errorf("failed to set attachment file id: %w", err)
+	}
+
+	return nil
+}
+
+// SetLogDelivery сохраняет идентификаторы доставленных сообщений для последующего редактирования и удаления
+func (r *BroadcastRepo) SetLogDelivery(ctx context.Context, logID uuid.UUID, chatID, messageID int64, attachmentMessageIDs []int64) error {
+	query := `
+		UPDATE broadcast_logs
+		SET chat_id = $1, message_id = $2, attachment_message_ids = $3
+		WHERE id = $4
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, chatID, messageID, pq.Int64Array(attachmentMessageIDs), logID); err != nil {
+		return fmt.Errorf("failed to set log delivery: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeliveredLogs получает успешные доставки рассылки с сохранёнными message_id
+func (r *BroadcastRepo) GetDeliveredLogs(ctx context.Context, broadcastID uuid.UUID) ([]*models.BroadcastLog, error) {
+	query := `
+		SELECT
+			bl.id,
+			bl.broadcast_id,
+			bl.user_id,
+			bl.telegram_id,
+			bl.status,
+			bl.error,
+			bl.sent_at,
+			bl.chat_id,
+			bl.message_id,
+			bl.attachment_message_ids
+		FROM broadcast_logs bl
+		WHERE bl.broadcast_id = $1 AND bl.status = $2 AND bl.message_id IS NOT NULL
+		ORDER BY bl.sent_at
+	`
+
+	logs := []*models.BroadcastLog{}
+	if err := r.db.SelectContext(ctx, &logs, query, broadcastID, models.BroadcastLogStatusSuccess); err != nil {
+		return nil, fmt.Errorf("failed to get delivered broadcast logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// UpdateMessage заменяет текст рассылки и отмечает время редактирования
+func (r *BroadcastRepo) UpdateMessage(ctx context.Context, id uuid.UUID, message string) error {
+	query := `UPDATE broadcasts SET message = $1, edited_at = $2 WHERE id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, message, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update broadcast message: %w", err)
+	}
+
+	return expectAffected(result, ErrBroadcastNotFound)
+}
+
+// MarkRecalled атомарно отмечает рассылку отозванной.
+// Возвращает false, если рассылка уже была отозвана ранее
+func (r *BroadcastRepo) MarkRecalled(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE broadcasts SET recalled_at = $1 WHERE id = $2 AND recalled_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark broadcast recalled: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
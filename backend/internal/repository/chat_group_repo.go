@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ==================== Group Rooms ====================
+
+// chatParticipantSelect выборка участников групповой комнаты с именем и ролью пользователя
+const chatParticipantSelect = `
+	SELECT p.room_id, p.user_id,
+		COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email, '') AS name,
+		COALESCE(u.role, '') AS user_role,
+		p.role, p.muted_until, p.added_manually, p.joined_at
+	FROM chat_room_participants p
+	JOIN users u ON u.id = p.user_id
+`
+
+// ListRoomParticipants получает участников групповой комнаты: сначала владельцы и модераторы
+func (r *ChatRepository) ListRoomParticipants(ctx context.Context, roomID uuid.UUID) ([]models.ChatRoomParticipant, error) {
+	participants := []models.ChatRoomParticipant{}
+	query := chatParticipantSelect + `
+		WHERE p.room_id = $1 AND u.deleted_at IS NULL
+		ORDER BY CASE p.role WHEN 'owner' THEN 0 WHEN 'moderator' THEN 1 ELSE 2 END, p.joined_at
+	`
+	if err := r.db.SelectContext(ctx, &participants, query, roomID); err != nil {
+		return nil, fmt.Errorf("failed to list room participants: %w", err)
+	}
+	return participants, nil
+}
+
+// ListGroupRoomsByUser получает групповые комнаты, в которых состоит пользователь
+func (r *ChatRepository) ListGroupRoomsByUser(ctx context.Context, userID uuid.UUID) ([]*models.ChatRoom, error) {
+	query := `
+		SELECT
+			cr.id, cr.room_type, cr.title, cr.description, cr.recurring_group_id, cr.student_group_id,
+			cr.last_message_at, cr.created_at, cr.updated_at, cr.deleted_at
+		FROM chat_rooms cr
+		JOIN chat_room_participants p ON p.room_id = cr.id
+		WHERE p.user_id = $1 AND cr.room_type <> 'direct' AND cr.deleted_at IS NULL
+		ORDER BY cr.last_message_at DESC NULLS LAST, cr.created_at DESC
+	`
+
+	rooms := make([]*models.ChatRoom, 0)
+	if err := r.db.SelectContext(ctx, &rooms, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list group rooms by user: %w", err)
+	}
+	return rooms, nil
+}
+
+// CreateGroupRoom создает групповую комнату с участниками. Если у комнаты указана учебная группа,
+// ее текущие студенты добавляются участниками в той же транзакции
+func (r *ChatRepository) CreateGroupRoom(ctx context.Context, room *models.ChatRoom, createdBy uuid.UUID, participants []models.ChatRoomParticipant) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.GetContext(ctx, room, `
+		INSERT INTO chat_rooms (id, room_type, title, description, student_group_id, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING id, room_type, title, description, recurring_group_id, student_group_id,
+			last_message_at, created_at, updated_at, deleted_at
+	`, room.ID, room.Type, room.Title, room.Description, room.StudentGroupID, createdBy)
+	if err != nil {
+		return fmt.Errorf("failed to create group room: %w", err)
+	}
+
+	for _, p := range participants {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO chat_room_participants (room_id, user_id, role)
+			SELECT $1, u.id, $3 FROM users u WHERE u.id = $2 AND u.deleted_at IS NULL
+			ON CONFLICT (room_id, user_id) DO NOTHING
+		`, room.ID, p.UserID, p.Role); err != nil {
+			return fmt.Errorf("failed to add room participant: %w", err)
+		}
+	}
+
+	if room.StudentGroupID.Valid {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO chat_room_participants (room_id, user_id, role)
+			SELECT $1, m.student_id, $3
+			FROM student_group_members m
+			JOIN users u ON u.id = m.student_id
+			WHERE m.group_id = $2 AND m.left_at IS NULL AND u.deleted_at IS NULL
+			ON CONFLICT (room_id, user_id) DO NOTHING
+		`, room.ID, room.StudentGroupID.UUID, models.ChatRoleMember); err != nil {
+			return fmt.Errorf("failed to add student group members: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit group room: %w", err)
+	}
+	return nil
+}
+
+// AddRoomParticipants добавляет пользователей в групповую комнату вручную: синхронизация комнаты серии занятий
+// не удаляет их без активной записи. Уже состоящие в комнате и удаленные пользователи пропускаются.
+// Возвращает число добавленных
+func (r *ChatRepository) AddRoomParticipants(ctx context.Context, roomID uuid.UUID, userIDs []uuid.UUID, role string) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO chat_room_participants (room_id, user_id, role, added_manually)
+		SELECT $1, u.id, $3, TRUE FROM users u WHERE u.id = ANY($2) AND u.deleted_at IS NULL
+		ON CONFLICT (room_id, user_id) DO NOTHING
+	`, roomID, userIDs, role)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add room participants: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rows), nil
+}
+
+// RemoveRoomParticipant удаляет участника из групповой комнаты
+func (r *ChatRepository) RemoveRoomParticipant(ctx context.Context, roomID, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM chat_room_participants WHERE room_id = $1 AND user_id = $2
+	`, roomID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove room participant: %w", err)
+	}
+	return requireParticipantAffected(result)
+}
+
+// SetParticipantRole меняет роль участника групповой комнаты
+func (r *ChatRepository) SetParticipantRole(ctx context.Context, roomID, userID uuid.UUID, role string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE chat_room_participants SET role = $3 WHERE room_id = $1 AND user_id = $2
+	`, roomID, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to set participant role: %w", err)
+	}
+	return requireParticipantAffected(result)
+}
+
+// SetParticipantMute устанавливает или снимает (mutedUntil.Valid = false) мьют участника
+func (r *ChatRepository) SetParticipantMute(ctx context.Context, roomID, userID uuid.UUID, mutedUntil sql.NullTime) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE chat_room_participants SET muted_until = $3 WHERE room_id = $1 AND user_id = $2
+	`, roomID, userID, mutedUntil)
+	if err != nil {
+		return fmt.Errorf("failed to set participant mute: %w", err)
+	}
+	return requireParticipantAffected(result)
+}
+
+// SyncLessonGroupRoom создает комнату серии групповых занятий и синхронизирует участников
+// с активными записями (sync_lesson_group_chat). Возвращает ErrChatRoomNotFound, если серия
+// не найдена или не групповая
+func (r *ChatRepository) SyncLessonGroupRoom(ctx context.Context, recurringGroupID uuid.UUID) (uuid.UUID, error) {
+	var roomID uuid.NullUUID
+	if err := r.db.GetContext(ctx, &roomID, `SELECT sync_lesson_group_chat($1)`, recurringGroupID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to sync lesson group room: %w", err)
+	}
+	if !roomID.Valid {
+		return uuid.Nil, ErrChatRoomNotFound
+	}
+	return roomID.UUID, nil
+}
+
+// requireParticipantAffected возвращает ErrChatParticipantNotFound, если запрос не изменил ни одной строки
+func requireParticipantAffected(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrChatParticipantNotFound
+	}
+	return nil
+}
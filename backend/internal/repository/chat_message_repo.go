@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ==================== Edits, Replies and Reactions ====================
+
+// EditMessage сохраняет прежний текст в истории правок и обновляет сообщение.
+// Редактируется только доставленное неудаленное сообщение; status - статус после правки
+// (pending_moderation, если правка пойдет на повторную модерацию)
+func (r *ChatRepository) EditMessage(ctx context.Context, msgID, editorID uuid.UUID, text, status string) (time.Time, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO message_edits (message_id, previous_text, edited_by)
+		SELECT id, message_text, $2 FROM messages
+		WHERE id = $1 AND deleted_at IS NULL AND status = $3
+	`, msgID, editorID, models.MessageStatusDelivered)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to save message edit: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get rows affected: %w", err)
+	} else if rows == 0 {
+		return time.Time{}, ErrMessageNotFound
+	}
+
+	var editedAt time.Time
+	err = tx.GetContext(ctx, &editedAt, `
+		UPDATE messages
+		SET message_text = $2, status = $3, edited_at = CURRENT_TIMESTAMP,
+			moderation_completed_at = CASE WHEN $3 = $4 THEN CURRENT_TIMESTAMP ELSE NULL END
+		WHERE id = $1
+		RETURNING edited_at
+	`, msgID, text, status, models.MessageStatusDelivered)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to edit message: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit message edit: %w", err)
+	}
+	return editedAt, nil
+}
+
+// ListMessageEdits получает историю правок сообщения, новые сверху
+func (r *ChatRepository) ListMessageEdits(ctx context.Context, msgID uuid.UUID) ([]*models.MessageEdit, error) {
+	edits := []*models.MessageEdit{}
+	err := r.db.SelectContext(ctx, &edits, `
+		SELECT id, message_id, previous_text, edited_by, edited_at
+		FROM message_edits
+		WHERE message_id = $1
+		ORDER BY edited_at DESC
+	`, msgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message edits: %w", err)
+	}
+	return edits, nil
+}
+
+// GetMessagePreviews получает цитаты доставленных неудаленных сообщений по ID.
+// Недоступные сообщения (удаленные, заблокированные, на модерации) в результат не попадают
+func (r *ChatRepository) GetMessagePreviews(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.MessagePreview, error) {
+	previews := make(map[uuid.UUID]*models.MessagePreview, len(ids))
+	if len(ids) == 0 {
+		return previews, nil
+	}
+
+	var rows []*models.MessagePreview
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT m.id, m.sender_id,
+			COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email, 'Пользователь') AS sender_name,
+			m.message_text
+		FROM messages m
+		LEFT JOIN users u ON u.id = m.sender_id
+		WHERE m.id = ANY($1) AND m.deleted_at IS NULL AND m.status = $2
+	`, ids, models.MessageStatusDelivered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message previews: %w", err)
+	}
+
+	for _, p := range rows {
+		p.Available = true
+		p.Truncate()
+		previews[p.ID] = p
+	}
+	return previews, nil
+}
+
+// ListReplies получает доставленные ответы на сообщение в хронологическом порядке
+func (r *ChatRepository) ListReplies(ctx context.Context, msgID uuid.UUID, limit int) ([]*models.Message, error) {
+	messages := []*models.Message{}
+	err := r.db.SelectContext(ctx, &messages, `
+		SELECT
+			m.id, m.room_id, m.sender_id, m.message_text, m.status,
+			m.moderation_completed_at, m.created_at, m.deleted_at,
+			m.edited_at, m.reply_to_message_id,
+			COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email, 'Пользователь') AS sender_name
+		FROM messages m
+		LEFT JOIN users u ON u.id = m.sender_id
+		WHERE m.reply_to_message_id = $1 AND m.status = $2 AND m.deleted_at IS NULL
+		ORDER BY m.created_at ASC
+		LIMIT $3
+	`, msgID, models.MessageStatusDelivered, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replies: %w", err)
+	}
+	return messages, nil
+}
+
+// AddReaction добавляет реакцию пользователя. Возвращает false, если такая реакция уже есть
+func (r *ChatRepository) AddReaction(ctx context.Context, msgID, userID uuid.UUID, emoji string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO message_reactions (message_id, user_id, emoji)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (message_id, user_id, emoji) DO NOTHING
+	`, msgID, userID, emoji)
+	if err != nil {
+		return false, fmt.Errorf("failed to add reaction: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// RemoveReaction снимает реакцию пользователя. Возвращает false, если реакции не было
+func (r *ChatRepository) RemoveReaction(ctx context.Context, msgID, userID uuid.UUID, emoji string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3
+	`, msgID, userID, emoji)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetReactionsByMessages получает реакции на сообщения, сгруппированные по эмодзи
+func (r *ChatRepository) GetReactionsByMessages(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]models.MessageReactionSummary, error) {
+	result := make(map[uuid.UUID][]models.MessageReactionSummary, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var reactions []models.MessageReaction
+	err := r.db.SelectContext(ctx, &reactions, `
+		SELECT message_id, user_id, emoji, created_at
+		FROM message_reactions
+		WHERE message_id = ANY($1)
+		ORDER BY created_at ASC
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+
+	byMessage := make(map[uuid.UUID][]models.MessageReaction)
+	for _, reaction := range reactions {
+		byMessage[reaction.MessageID] = append(byMessage[reaction.MessageID], reaction)
+	}
+	for msgID, list := range byMessage {
+		result[msgID] = models.SummarizeReactions(list)
+	}
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ==================== Read Receipts ====================
+
+// MarkRoomRead сдвигает отметку прочитанного участника до сообщения messageID (или до последнего
+// доставленного сообщения, если messageID не задан). Отметка только сдвигается вперед:
+// advanced = false, если участник уже прочитал это сообщение или более позднее
+func (r *ChatRepository) MarkRoomRead(ctx context.Context, roomID, userID uuid.UUID, messageID *uuid.UUID) (state *models.ChatReadState, advanced bool, err error) {
+	var target struct {
+		ID        uuid.UUID    `db:"id"`
+		CreatedAt sql.NullTime `db:"created_at"`
+	}
+	query := `
+		SELECT id, created_at FROM messages
+		WHERE room_id = $1 AND deleted_at IS NULL AND status = $2
+	`
+	args := []interface{}{roomID, models.MessageStatusDelivered}
+	if messageID != nil {
+		query += ` AND id = $3`
+		args = append(args, *messageID)
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT 1`
+
+	if err := r.db.GetContext(ctx, &target, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if messageID != nil {
+				return nil, false, ErrMessageNotFound
+			}
+			// В комнате нет сообщений - отмечать нечего
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get message to mark read: %w", err)
+	}
+
+	var updated models.ChatReadState
+	err = r.db.GetContext(ctx, &updated, `
+		INSERT INTO chat_read_states (room_id, user_id, last_read_message_id, last_read_at, updated_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (room_id, user_id) DO UPDATE
+		SET last_read_message_id = EXCLUDED.last_read_message_id,
+			last_read_at = EXCLUDED.last_read_at,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE chat_read_states.last_read_at < EXCLUDED.last_read_at
+		RETURNING room_id, user_id, last_read_message_id, last_read_at, updated_at
+	`, roomID, userID, target.ID, target.CreatedAt.Time)
+	if err == nil {
+		return &updated, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, fmt.Errorf("failed to mark room read: %w", err)
+	}
+
+	// Отметка не сдвинулась: возвращаем текущее состояние
+	current, err := r.getReadState(ctx, roomID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	return current, false, nil
+}
+
+// getReadState получает отметку прочитанного участника комнаты
+func (r *ChatRepository) getReadState(ctx context.Context, roomID, userID uuid.UUID) (*models.ChatReadState, error) {
+	var state models.ChatReadState
+	err := r.db.GetContext(ctx, &state, `
+		SELECT room_id, user_id, last_read_message_id, last_read_at, updated_at
+		FROM chat_read_states
+		WHERE room_id = $1 AND user_id = $2
+	`, roomID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get read state: %w", err)
+	}
+	return &state, nil
+}
+
+// ListReadStates получает отметки прочитанного всех участников комнаты
+func (r *ChatRepository) ListReadStates(ctx context.Context, roomID uuid.UUID) ([]*models.ChatReadState, error) {
+	states := []*models.ChatReadState{}
+	err := r.db.SelectContext(ctx, &states, `
+		SELECT room_id, user_id, last_read_message_id, last_read_at, updated_at
+		FROM chat_read_states
+		WHERE room_id = $1
+		ORDER BY last_read_at DESC
+	`, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list read states: %w", err)
+	}
+	return states, nil
+}
+
+// CountUnreadByRoom считает непрочитанные пользователем доставленные сообщения по комнатам,
+// в которых он состоит. Комнаты без непрочитанных в результат не попадают
+func (r *ChatRepository) CountUnreadByRoom(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		RoomID uuid.UUID `db:"room_id"`
+		Count  int       `db:"unread_count"`
+	}
+	err := r.db.SelectContext(ctx, &rows, `
+		WITH my_rooms AS (
+			SELECT cr.id AS room_id
+			FROM chat_rooms cr
+			WHERE cr.deleted_at IS NULL AND cr.room_type = 'direct'
+				AND (cr.teacher_id = $1 OR cr.student_id = $1)
+			UNION
+			SELECT p.room_id
+			FROM chat_room_participants p
+			JOIN chat_rooms cr ON cr.id = p.room_id AND cr.deleted_at IS NULL
+			WHERE p.user_id = $1
+		)
+		SELECT mr.room_id, COUNT(*) AS unread_count
+		FROM my_rooms mr
+		JOIN messages m ON m.room_id = mr.room_id
+		LEFT JOIN chat_read_states rs ON rs.room_id = mr.room_id AND rs.user_id = $1
+		WHERE m.deleted_at IS NULL
+			AND m.status = $2
+			AND m.sender_id <> $1
+			AND (rs.last_read_at IS NULL OR m.created_at > rs.last_read_at)
+		GROUP BY mr.room_id
+	`, userID, models.MessageStatusDelivered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.RoomID] = row.Count
+	}
+	return counts, nil
+}
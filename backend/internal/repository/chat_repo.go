@@ -46,6 +46,7 @@ func (r *ChatRepository) GetOrCreateRoom(ctx context.Context, teacherID, student
 
 	room := &models.ChatRoom{
 		ID:        uuid.New(),
+		Type:      models.ChatRoomTypeDirect,
 		TeacherID: teacherID,
 		StudentID: studentID,
 		CreatedAt: time.Now(),
@@ -80,14 +81,19 @@ func (r *ChatRepository) getRoomByParticipants(ctx context.Context, teacherID, s
 	query := `
 		SELECT ` + ChatRoomSelectFields + `
 		FROM chat_rooms
-		WHERE teacher_id = $1 AND student_id = $2 AND deleted_at IS NULL
+		WHERE teacher_id = $1 AND student_id = $2 AND room_type = 'direct' AND deleted_at IS NULL
 	`
 
 	var room models.ChatRoom
 	err := r.db.QueryRowContext(ctx, query, teacherID, studentID).Scan(
 		&room.ID,
+		&room.Type,
 		&room.TeacherID,
 		&room.StudentID,
+		&room.Title,
+		&room.Description,
+		&room.RecurringGroupID,
+		&room.StudentGroupID,
 		&room.LastMessageAt,
 		&room.CreatedAt,
 		&room.UpdatedAt,
@@ -115,8 +121,13 @@ func (r *ChatRepository) GetRoomByID(ctx context.Context, roomID uuid.UUID) (*mo
 	var room models.ChatRoom
 	err := r.db.QueryRowContext(ctx, query, roomID).Scan(
 		&room.ID,
+		&room.Type,
 		&room.TeacherID,
 		&room.StudentID,
+		&room.Title,
+		&room.Description,
+		&room.RecurringGroupID,
+		&room.StudentGroupID,
 		&room.LastMessageAt,
 		&room.CreatedAt,
 		&room.UpdatedAt,
@@ -130,6 +141,14 @@ func (r *ChatRepository) GetRoomByID(ctx context.Context, roomID uuid.UUID) (*mo
 		return nil, fmt.Errorf("failed to get room by ID: %w", err)
 	}
 
+	// Для групповых комнат загружаем участников: на них строится проверка доступа
+	if room.IsGroup() {
+		room.Participants, err = r.ListRoomParticipants(ctx, room.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &room, nil
 }
 
@@ -137,7 +156,7 @@ func (r *ChatRepository) GetRoomByID(ctx context.Context, roomID uuid.UUID) (*mo
 func (r *ChatRepository) ListRoomsByTeacher(ctx context.Context, teacherID uuid.UUID) ([]*models.ChatRoom, error) {
 	query := `
 		SELECT
-			cr.id, cr.teacher_id, cr.student_id, cr.last_message_at,
+			cr.id, cr.room_type, cr.teacher_id, cr.student_id, cr.last_message_at,
 			cr.created_at, cr.updated_at, cr.deleted_at,
 			u.id AS participant_id,
 			COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email) AS participant_name,
@@ -161,7 +180,7 @@ func (r *ChatRepository) ListRoomsByTeacher(ctx context.Context, teacherID uuid.
 func (r *ChatRepository) ListRoomsByStudent(ctx context.Context, studentID uuid.UUID) ([]*models.ChatRoom, error) {
 	query := `
 		SELECT
-			cr.id, cr.teacher_id, cr.student_id, cr.last_message_at,
+			cr.id, cr.room_type, cr.teacher_id, cr.student_id, cr.last_message_at,
 			cr.created_at, cr.updated_at, cr.deleted_at,
 			u.id AS participant_id,
 			COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email) AS participant_name,
@@ -210,8 +229,8 @@ func (r *ChatRepository) UpdateLastMessageAt(ctx context.Context, roomID uuid.UU
 // CreateMessage создает новое сообщение
 func (r *ChatRepository) CreateMessage(ctx context.Context, msg *models.Message) error {
 	query := `
-		INSERT INTO messages (id, room_id, sender_id, message_text, status, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO messages (id, room_id, sender_id, message_text, status, created_at, reply_to_message_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	msg.ID = uuid.New()
@@ -224,6 +243,7 @@ func (r *ChatRepository) CreateMessage(ctx context.Context, msg *models.Message)
 		msg.MessageText,
 		msg.Status,
 		msg.CreatedAt,
+		msg.ReplyToMessageID,
 	)
 
 	if err != nil {
@@ -273,6 +293,7 @@ func (r *ChatRepository) GetMessagesByRoom(ctx context.Context, roomID uuid.UUID
 		SELECT
 			m.id, m.room_id, m.sender_id, m.message_text, m.status,
 			m.moderation_completed_at, m.created_at, m.deleted_at,
+			m.edited_at, m.reply_to_message_id,
 			CASE
 				WHEN COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), '') != '' THEN
 					CASE u.role
@@ -308,6 +329,7 @@ func (r *ChatRepository) GetPendingMessages(ctx context.Context) ([]*models.Mess
 		SELECT
 			m.id, m.room_id, m.sender_id, m.message_text, m.status,
 			m.moderation_completed_at, m.created_at, m.deleted_at,
+			m.edited_at, m.reply_to_message_id,
 			CASE
 				WHEN COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), '') != '' THEN
 					CASE u.role
@@ -364,6 +386,7 @@ func (r *ChatRepository) GetMessageByID(ctx context.Context, msgID uuid.UUID) (*
 		SELECT
 			m.id, m.room_id, m.sender_id, m.message_text, m.status,
 			m.moderation_completed_at, m.created_at, m.deleted_at,
+			m.edited_at, m.reply_to_message_id,
 			CASE
 				WHEN COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), '') != '' THEN
 					CASE u.role
@@ -389,6 +412,8 @@ func (r *ChatRepository) GetMessageByID(ctx context.Context, msgID uuid.UUID) (*
 		&msg.ModerationCompletedAt,
 		&msg.CreatedAt,
 		&msg.DeletedAt,
+		&msg.EditedAt,
+		&msg.ReplyToMessageID,
 		&msg.SenderName,
 	)
 
@@ -407,8 +432,8 @@ func (r *ChatRepository) GetMessageByID(ctx context.Context, msgID uuid.UUID) (*
 // CreateAttachment создает запись о вложенном файле
 func (r *ChatRepository) CreateAttachment(ctx context.Context, att *models.FileAttachment) error {
 	query := `
-		INSERT INTO file_attachments (id, message_id, file_name, file_path, file_size, mime_type, uploaded_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO file_attachments (id, message_id, file_name, file_path, file_size, mime_type, uploaded_at, storage_key, content_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	att.ID = uuid.New()
@@ -422,6 +447,8 @@ func (r *ChatRepository) CreateAttachment(ctx context.Context, att *models.FileA
 		att.FileSize,
 		att.MimeType,
 		att.UploadedAt,
+		att.StorageKey,
+		att.ContentHash,
 	)
 
 	if err != nil {
@@ -466,6 +493,9 @@ func (r *ChatRepository) GetAttachmentByID(ctx context.Context, attachmentID uui
 		&att.FileSize,
 		&att.MimeType,
 		&att.UploadedAt,
+		&att.StorageKey,
+		&att.ContentHash,
+		&att.ThumbnailKey,
 	)
 
 	if err == sql.ErrNoRows {
@@ -531,10 +561,15 @@ func (r *ChatRepository) UpdateLastMessageAtTx(ctx context.Context, tx pgx.Tx, r
 // CreateBlockedMessage сохраняет заблокированное сообщение
 func (r *ChatRepository) CreateBlockedMessage(ctx context.Context, blockedMsg *models.BlockedMessage) error {
 	query := `
-		INSERT INTO blocked_messages (id, message_id, reason, ai_response, blocked_at, admin_notified, admin_reviewed)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO blocked_messages (id, message_id, reason, ai_response, blocked_at, admin_notified, admin_reviewed, rule)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
+	rule := blockedMsg.Rule
+	if rule == "" {
+		rule = models.ModerationRuleUnknown
+	}
+
 	_, err := r.db.ExecContext(ctx, query,
 		blockedMsg.ID,
 		blockedMsg.MessageID,
@@ -543,6 +578,7 @@ func (r *ChatRepository) CreateBlockedMessage(ctx context.Context, blockedMsg *m
 		blockedMsg.BlockedAt,
 		blockedMsg.AdminNotified,
 		blockedMsg.AdminReviewed,
+		rule,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create blocked message: %w", err)
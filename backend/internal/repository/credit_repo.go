@@ -331,6 +331,26 @@ func (r *CreditRepository) CreateCredit(ctx context.Context, userID uuid.UUID, i
 	return nil
 }
 
+// CreateCreditTx создает счет кредитов пользователя в рамках транзакции.
+// Возвращает ErrDuplicateCredit если кредиты для пользователя уже существуют
+func (r *CreditRepository) CreateCreditTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID, initialBalance int) error {
+	query := `
+		INSERT INTO credits (id, user_id, balance, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+
+	tag, err := tx.Exec(ctx, query, uuid.New(), userID, initialBalance, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to create credit account: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDuplicateCredit
+	}
+
+	return nil
+}
+
 // GetAllStudentCredits получает все балансы кредитов для студентов
 // Returns: slice of {user_id, email, full_name, balance}
 func (r *CreditRepository) GetAllStudentCredits(ctx context.Context) ([]map[string]interface{}, error) {
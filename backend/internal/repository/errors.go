@@ -47,17 +47,25 @@ var (
 	ErrTelegramUsernameInUse     = errors.New("это Telegram имя уже используется другим пользователем")
 	ErrBroadcastListNotFound     = errors.New("список рассылки не найден")
 	ErrBroadcastNotFound         = errors.New("рассылка не найдена")
+	ErrBroadcastTemplateNotFound = errors.New("шаблон рассылки не найден")
+	ErrBroadcastAudienceNotFound = errors.New("аудитория рассылки не найдена")
 
 	// Ошибки чата
-	ErrChatRoomNotFound   = errors.New("комната чата не найдена")
-	ErrMessageNotFound    = errors.New("сообщение не найдено")
-	ErrAttachmentNotFound = errors.New("вложение не найдено")
+	ErrChatRoomNotFound        = errors.New("комната чата не найдена")
+	ErrChatParticipantNotFound = errors.New("участник комнаты чата не найден")
+	ErrMessageNotFound         = errors.New("сообщение не найдено")
+	ErrAttachmentNotFound      = errors.New("вложение не найдено")
 
 	// Ошибки платежей
 	ErrPaymentNotFound        = errors.New("платеж не найден")
 	ErrPaymentDisabledForUser = errors.New("платежи отключены для пользователя")
 	ErrInvalidUserRole        = errors.New("некорректная роль пользователя")
 
+	// Ошибки подписок
+	ErrSubscriptionPlanNotFound    = errors.New("тариф подписки не найден")
+	ErrSubscriptionNotFound        = errors.New("подписка не найдена")
+	ErrSubscriptionInvoiceNotFound = errors.New("счёт подписки не найден")
+
 	// Ошибки отменённых бронирований
 	ErrCancelledNotFound = errors.New("отменённое бронирование не найдено")
 
@@ -83,6 +91,52 @@ var (
 	ErrHardDeleteRequiresForce = errors.New("жёсткое удаление требует force=true")
 )
 
+// Ошибки массовых изменений занятий
+var (
+	ErrLessonModificationNotFound = errors.New("изменение занятий не найдено")
+)
+
+// Ошибки академического календаря
+var (
+	ErrCalendarEntryNotFound = errors.New("запись календаря не найдена")
+)
+
+// Ошибки учебных групп
+var (
+	ErrGroupNotFound = errors.New("группа не найдена")
+)
+
+// Ошибки заявок на пробное занятие
+var (
+	ErrTrialRequestNotFound = errors.New("заявка на пробное занятие не найдена")
+)
+
+// Ошибки расчета оплаты преподавателей
+var (
+	ErrPayRateExists              = errors.New("ставка преподавателя с этой даты уже задана")
+	ErrPayrollPeriodNotFound      = errors.New("расчетный период не найден")
+	ErrPayrollPeriodOverlap       = errors.New("расчетный период пересекается с уже закрытым")
+	ErrEarningsStatementNotFound  = errors.New("ведомость начислений не найдена")
+	ErrEarningsAdjustmentNotFound = errors.New("корректировка не найдена или уже учтена в закрытом периоде")
+)
+
+// Ошибки удаления персональных данных
+var (
+	ErrErasureRequestNotFound = errors.New("запрос на удаление аккаунта не найден")
+)
+
+// Ошибки ролей
+var (
+	ErrRoleNotFound = errors.New("роль не найдена")
+	ErrRoleExists   = errors.New("роль с таким именем уже существует")
+	ErrRoleInUse    = errors.New("роль назначена пользователям, сначала смените им роль")
+)
+
+// Ошибки модерации
+var (
+	ErrBlockedMessageNotFound = errors.New("заблокированное сообщение не найдено")
+)
+
 // IsUniqueViolationError проверяет, вызвана ли ошибка нарушением UNIQUE constraint в PostgreSQL
 // Это используется для преобразования database-level ошибок в domain-level ошибки
 // Код ошибки 23505 = UNIQUE constraint violation in PostgreSQL
@@ -0,0 +1,433 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// groupWithDetailsQuery выбирает группы с именем преподавателя, названием предмета и числом активных участников
+const groupWithDetailsQuery = `
+	SELECT g.id, g.name, g.description, g.teacher_id, g.subject_id, g.created_by,
+		g.created_at, g.updated_at, g.deleted_at,
+		CONCAT(t.first_name, ' ', t.last_name) AS teacher_name,
+		COALESCE(s.name, '') AS subject_name,
+		(SELECT COUNT(*) FROM student_group_members m WHERE m.group_id = g.id AND m.left_at IS NULL) AS member_count
+	FROM student_groups g
+	JOIN users t ON t.id = g.teacher_id
+	LEFT JOIN subjects s ON s.id = g.subject_id
+`
+
+// GroupRepository управляет учебными группами, их сериями занятий и участниками
+type GroupRepository struct {
+	db *sqlx.DB
+}
+
+// NewGroupRepository создает новый GroupRepository
+func NewGroupRepository(db *sqlx.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+// Create создает новую группу
+func (r *GroupRepository) Create(ctx context.Context, group *models.Group) error {
+	query := `
+		INSERT INTO student_groups (id, name, description, teacher_id, subject_id, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	if group.ID == uuid.Nil {
+		group.ID = uuid.New()
+	}
+	group.CreatedAt = time.Now()
+	group.UpdatedAt = group.CreatedAt
+
+	_, err := r.db.ExecContext(ctx, query,
+		group.ID,
+		group.Name,
+		group.Description,
+		group.TeacherID,
+		group.SubjectID,
+		group.CreatedBy,
+		group.CreatedAt,
+		group.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID получает группу с деталями и привязанными сериями
+func (r *GroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.GroupWithDetails, error) {
+	query := groupWithDetailsQuery + `WHERE g.id = $1 AND g.deleted_at IS NULL`
+
+	var group models.GroupWithDetails
+	if err := r.db.GetContext(ctx, &group, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	seriesIDs, err := r.GetSeriesIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	group.SeriesIDs = seriesIDs
+
+	return &group, nil
+}
+
+// List получает список групп (teacherID != nil - только группы преподавателя)
+func (r *GroupRepository) List(ctx context.Context, teacherID *uuid.UUID) ([]*models.GroupWithDetails, error) {
+	query := groupWithDetailsQuery + `WHERE g.deleted_at IS NULL`
+	args := []interface{}{}
+	if teacherID != nil {
+		query += ` AND g.teacher_id = $1`
+		args = append(args, *teacherID)
+	}
+	query += ` ORDER BY g.name ASC`
+
+	var groups []*models.GroupWithDetails
+	if err := r.db.SelectContext(ctx, &groups, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+	if len(groups) == 0 {
+		return []*models.GroupWithDetails{}, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(groups))
+	for _, group := range groups {
+		ids = append(ids, group.ID)
+		group.SeriesIDs = []uuid.UUID{}
+	}
+
+	type seriesRow struct {
+		GroupID          uuid.UUID `db:"group_id"`
+		RecurringGroupID uuid.UUID `db:"recurring_group_id"`
+	}
+	var rows []seriesRow
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT group_id, recurring_group_id
+		FROM student_group_series
+		WHERE group_id = ANY($1)
+		ORDER BY linked_at ASC
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group series: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*models.GroupWithDetails, len(groups))
+	for _, group := range groups {
+		byID[group.ID] = group
+	}
+	for _, row := range rows {
+		byID[row.GroupID].SeriesIDs = append(byID[row.GroupID].SeriesIDs, row.RecurringGroupID)
+	}
+
+	return groups, nil
+}
+
+// Update обновляет группу
+func (r *GroupRepository) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	updates["updated_at"] = time.Now()
+
+	query := "UPDATE student_groups SET "
+	args := []interface{}{}
+	paramCount := 1
+
+	for key, value := range updates {
+		if key == "id" || key == "created_at" || key == "deleted_at" {
+			continue
+		}
+		if paramCount > 1 {
+			query += ", "
+		}
+		query += fmt.Sprintf("%s = $%d", key, paramCount)
+		args = append(args, value)
+		paramCount++
+	}
+
+	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL", paramCount)
+	args = append(args, id)
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update group: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrGroupNotFound
+	}
+
+	return nil
+}
+
+// Delete выполняет мягкое удаление группы
+func (r *GroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE student_groups
+		SET deleted_at = $1, updated_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrGroupNotFound
+	}
+
+	return nil
+}
+
+// GetByIDForUpdate получает группу с блокировкой строки, сериализуя изменения состава группы
+func (r *GroupRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.Group, error) {
+	query := `
+		SELECT id, name, description, teacher_id, subject_id, created_by, created_at, updated_at, deleted_at
+		FROM student_groups
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	var group models.Group
+	err := tx.QueryRow(ctx, query, id).Scan(
+		&group.ID,
+		&group.Name,
+		&group.Description,
+		&group.TeacherID,
+		&group.SubjectID,
+		&group.CreatedBy,
+		&group.CreatedAt,
+		&group.UpdatedAt,
+		&group.DeletedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get group for update: %w", err)
+	}
+
+	return &group, nil
+}
+
+// GetSeriesIDs получает серии занятий, привязанные к группе
+func (r *GroupRepository) GetSeriesIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT recurring_group_id
+		FROM student_group_series
+		WHERE group_id = $1
+		ORDER BY linked_at ASC
+	`
+
+	ids := []uuid.UUID{}
+	if err := r.db.SelectContext(ctx, &ids, query, groupID); err != nil {
+		return nil, fmt.Errorf("failed to get group series: %w", err)
+	}
+
+	return ids, nil
+}
+
+// GetSeriesIDsTx получает серии занятий, привязанные к группе, в транзакции
+func (r *GroupRepository) GetSeriesIDsTx(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, `SELECT recurring_group_id FROM student_group_series WHERE group_id = $1`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group series: %w", err)
+	}
+	return collectUUIDs(rows)
+}
+
+// LinkSeriesTx привязывает серию повторяющихся занятий к группе
+func (r *GroupRepository) LinkSeriesTx(ctx context.Context, tx pgx.Tx, groupID, recurringGroupID, linkedBy uuid.UUID) error {
+	query := `
+		INSERT INTO student_group_series (group_id, recurring_group_id, linked_by, linked_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (group_id, recurring_group_id) DO NOTHING
+	`
+
+	result, err := tx.Exec(ctx, query, groupID, recurringGroupID, linkedBy, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to link series: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return models.ErrSeriesAlreadyLinked
+	}
+
+	return nil
+}
+
+// UnlinkSeriesTx отвязывает серию повторяющихся занятий от группы
+func (r *GroupRepository) UnlinkSeriesTx(ctx context.Context, tx pgx.Tx, groupID, recurringGroupID uuid.UUID) error {
+	result, err := tx.Exec(ctx,
+		`DELETE FROM student_group_series WHERE group_id = $1 AND recurring_group_id = $2`,
+		groupID, recurringGroupID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to unlink series: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return models.ErrSeriesNotLinked
+	}
+
+	return nil
+}
+
+// GetMembers получает участников группы (includeFormer - включая исключённых)
+func (r *GroupRepository) GetMembers(ctx context.Context, groupID uuid.UUID, includeFormer bool) ([]*models.GroupMember, error) {
+	query := `
+		SELECT m.id, m.group_id, m.student_id, CONCAT(u.first_name, ' ', u.last_name) AS student_name,
+			m.joined_at, m.left_at, m.added_by, m.removed_by
+		FROM student_group_members m
+		JOIN users u ON u.id = m.student_id
+		WHERE m.group_id = $1
+	`
+	if !includeFormer {
+		query += ` AND m.left_at IS NULL`
+	}
+	query += ` ORDER BY m.joined_at ASC`
+
+	members := []*models.GroupMember{}
+	if err := r.db.SelectContext(ctx, &members, query, groupID); err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+
+	return members, nil
+}
+
+// GetActiveMemberIDsTx получает ID активных участников группы в транзакции
+func (r *GroupRepository) GetActiveMemberIDsTx(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT student_id FROM student_group_members WHERE group_id = $1 AND left_at IS NULL`,
+		groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	return collectUUIDs(rows)
+}
+
+// AddMemberTx добавляет студента в группу
+func (r *GroupRepository) AddMemberTx(ctx context.Context, tx pgx.Tx, groupID, studentID, addedBy uuid.UUID) error {
+	query := `
+		INSERT INTO student_group_members (id, group_id, student_id, joined_at, added_by)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := tx.Exec(ctx, query, uuid.New(), groupID, studentID, time.Now(), addedBy)
+	if err != nil {
+		if IsUniqueViolationError(err) {
+			return models.ErrAlreadyGroupMember
+		}
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMemberTx завершает участие студента в группе (история участия сохраняется)
+func (r *GroupRepository) RemoveMemberTx(ctx context.Context, tx pgx.Tx, groupID, studentID, removedBy uuid.UUID) error {
+	query := `
+		UPDATE student_group_members
+		SET left_at = $1, removed_by = $2
+		WHERE group_id = $3 AND student_id = $4 AND left_at IS NULL
+	`
+
+	result, err := tx.Exec(ctx, query, time.Now(), removedBy, groupID, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return models.ErrNotGroupMember
+	}
+
+	return nil
+}
+
+// LogEventTx записывает событие в историю состава группы
+func (r *GroupRepository) LogEventTx(ctx context.Context, tx pgx.Tx, event *models.GroupMembershipEvent) error {
+	query := `
+		INSERT INTO student_group_membership_events
+		(id, group_id, student_id, action, lessons_affected, credits_delta, performed_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	event.ID = uuid.New()
+	event.CreatedAt = time.Now()
+
+	_, err := tx.Exec(ctx, query,
+		event.ID,
+		event.GroupID,
+		event.StudentID,
+		event.Action,
+		event.LessonsAffected,
+		event.CreditsDelta,
+		event.PerformedBy,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to log group membership event: %w", err)
+	}
+
+	return nil
+}
+
+// ListEvents получает историю состава группы (новые события первыми)
+func (r *GroupRepository) ListEvents(ctx context.Context, groupID uuid.UUID, limit int) ([]*models.GroupMembershipEvent, error) {
+	query := `
+		SELECT e.id, e.group_id, e.student_id, CONCAT(u.first_name, ' ', u.last_name) AS student_name,
+			e.action, e.lessons_affected, e.credits_delta, e.performed_by, e.created_at
+		FROM student_group_membership_events e
+		JOIN users u ON u.id = e.student_id
+		WHERE e.group_id = $1
+		ORDER BY e.created_at DESC
+		LIMIT $2
+	`
+
+	events := []*models.GroupMembershipEvent{}
+	if err := r.db.SelectContext(ctx, &events, query, groupID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list group membership events: %w", err)
+	}
+
+	return events, nil
+}
+
+func collectUUIDs(rows pgx.Rows) ([]uuid.UUID, error) {
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ids: %w", err)
+	}
+
+	return ids, nil
+}
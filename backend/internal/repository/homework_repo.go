@@ -24,7 +24,8 @@ func NewHomeworkRepository(db *sqlx.DB) *HomeworkRepository {
 
 // HomeworkSelectFields определяет поля для SELECT запросов
 const HomeworkSelectFields = `
-	id, lesson_id, file_name, file_path, file_size, mime_type, created_by, created_at
+	id, lesson_id, file_name, file_path, file_size, mime_type, created_by, created_at,
+	storage_key, content_hash, thumbnail_key
 `
 
 // CreateHomework создает новую запись домашнего задания
@@ -35,9 +36,9 @@ func (r *HomeworkRepository) CreateHomework(ctx context.Context, homework *model
 	}
 
 	query := `
-		INSERT INTO lesson_homework (id, lesson_id, file_name, file_path, file_size, mime_type, created_by, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, lesson_id, file_name, file_path, file_size, mime_type, created_by, created_at
+		INSERT INTO lesson_homework (id, lesson_id, file_name, file_path, file_size, mime_type, created_by, created_at, storage_key, content_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + HomeworkSelectFields + `
 	`
 
 	// Генерируем новый ID если не задан
@@ -57,6 +58,8 @@ func (r *HomeworkRepository) CreateHomework(ctx context.Context, homework *model
 		homework.MimeType,
 		homework.CreatedBy,
 		homework.CreatedAt,
+		homework.StorageKey,
+		homework.ContentHash,
 	).Scan(
 		&homework.ID,
 		&homework.LessonID,
@@ -66,6 +69,9 @@ func (r *HomeworkRepository) CreateHomework(ctx context.Context, homework *model
 		&homework.MimeType,
 		&homework.CreatedBy,
 		&homework.CreatedAt,
+		&homework.StorageKey,
+		&homework.ContentHash,
+		&homework.ThumbnailKey,
 	)
 
 	if err != nil {
@@ -116,6 +122,9 @@ func (r *HomeworkRepository) GetHomeworkByID(ctx context.Context, homeworkID uui
 		&homework.MimeType,
 		&homework.CreatedBy,
 		&homework.CreatedAt,
+		&homework.StorageKey,
+		&homework.ContentHash,
+		&homework.ThumbnailKey,
 	)
 
 	if err == sql.ErrNoRows {
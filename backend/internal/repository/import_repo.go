@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// ImportRepository хранит журнал импортов и соответствие внешних идентификаторов
+// созданным сущностям, а также выполняет вставки массового импорта в общей транзакции
+type ImportRepository struct {
+	db *sqlx.DB
+}
+
+// NewImportRepository создает новый ImportRepository
+func NewImportRepository(db *sqlx.DB) *ImportRepository {
+	return &ImportRepository{db: db}
+}
+
+// GetExternalIDs получает ID сущностей, ранее импортированных с указанными внешними идентификаторами
+func (r *ImportRepository) GetExternalIDs(ctx context.Context, kind models.ImportKind, externalIDs []string) (map[string]uuid.UUID, error) {
+	result := make(map[string]uuid.UUID, len(externalIDs))
+	if len(externalIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT external_id, entity_id
+		FROM import_external_ids
+		WHERE entity_type = $1 AND external_id = ANY($2)
+	`
+
+	var rows []struct {
+		ExternalID string    `db:"external_id"`
+		EntityID   uuid.UUID `db:"entity_id"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, kind, externalIDs); err != nil {
+		return nil, fmt.Errorf("failed to get import external ids: %w", err)
+	}
+	for _, row := range rows {
+		result[row.ExternalID] = row.EntityID
+	}
+	return result, nil
+}
+
+// GetUsersByEmails получает неудаленных пользователей по списку email (ключ - email в нижнем регистре)
+func (r *ImportRepository) GetUsersByEmails(ctx context.Context, emails []string) (map[string]*models.User, error) {
+	result := make(map[string]*models.User, len(emails))
+	if len(emails) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT ` + UserSelectFields + `
+		FROM users
+		WHERE LOWER(email) = ANY($1) AND deleted_at IS NULL
+	`
+
+	var users []*models.User
+	if err := r.db.SelectContext(ctx, &users, query, emails); err != nil {
+		return nil, fmt.Errorf("failed to get users by emails: %w", err)
+	}
+	for _, u := range users {
+		result[u.Email] = u
+	}
+	return result, nil
+}
+
+// ClearCancelledBookingTx удаляет отметку об отписке студента от занятия,
+// чтобы администратор мог повторно записать его (как при ручной записи)
+func (r *ImportRepository) ClearCancelledBookingTx(ctx context.Context, tx pgx.Tx, studentID, lessonID uuid.UUID) error {
+	query := `
+		DELETE FROM cancelled_bookings
+		WHERE student_id = $1 AND lesson_id = $2
+	`
+
+	if _, err := tx.Exec(ctx, query, studentID, lessonID); err != nil {
+		return fmt.Errorf("failed to delete cancelled booking: %w", err)
+	}
+	return nil
+}
+
+// CreateRunTx записывает примененный импорт в журнал
+func (r *ImportRepository) CreateRunTx(ctx context.Context, tx pgx.Tx, run *models.ImportRun) error {
+	query := `
+		INSERT INTO import_runs (id, kind, file_name, rows_total, rows_created, rows_skipped, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	run.ID = uuid.New()
+	run.CreatedAt = time.Now()
+
+	_, err := tx.Exec(ctx, query,
+		run.ID, run.Kind, run.FileName, run.RowsTotal, run.RowsCreated, run.RowsSkipped, run.CreatedBy, run.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create import run: %w", err)
+	}
+	return nil
+}
+
+// SaveExternalIDTx сохраняет соответствие внешнего идентификатора созданной сущности
+func (r *ImportRepository) SaveExternalIDTx(ctx context.Context, tx pgx.Tx, kind models.ImportKind, externalID string, entityID, importID uuid.UUID) error {
+	query := `
+		INSERT INTO import_external_ids (entity_type, external_id, entity_id, import_id)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := tx.Exec(ctx, query, kind, externalID, entityID, importID); err != nil {
+		if IsUniqueViolationError(err) {
+			return models.ErrImportExternalIDConflict
+		}
+		return fmt.Errorf("failed to save import external id: %w", err)
+	}
+	return nil
+}
+
+// ListRuns получает журнал импортов, новые сверху
+func (r *ImportRepository) ListRuns(ctx context.Context, limit int) ([]*models.ImportRun, error) {
+	query := `
+		SELECT id, kind, file_name, rows_total, rows_created, rows_skipped, created_by, created_at
+		FROM import_runs
+		ORDER BY created_at DESC
+		LIMIT $1
+	`
+
+	runs := []*models.ImportRun{}
+	if err := r.db.SelectContext(ctx, &runs, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to list import runs: %w", err)
+	}
+	return runs, nil
+}
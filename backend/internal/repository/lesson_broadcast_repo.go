@@ -194,8 +194,8 @@ func (r *LessonBroadcastRepo) AddBroadcastFile(ctx context.Context, file *models
 	}
 
 	query := `
-		INSERT INTO broadcast_files (id, broadcast_id, file_name, file_path, file_size, mime_type, uploaded_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO broadcast_files (id, broadcast_id, file_name, file_path, file_size, mime_type, uploaded_at, storage_key, content_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	file.ID = uuid.New()
@@ -209,6 +209,8 @@ func (r *LessonBroadcastRepo) AddBroadcastFile(ctx context.Context, file *models
 		file.FileSize,
 		file.MimeType,
 		file.UploadedAt,
+		file.StorageKey,
+		file.ContentHash,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to add broadcast file: %w", err)
@@ -220,7 +222,7 @@ func (r *LessonBroadcastRepo) AddBroadcastFile(ctx context.Context, file *models
 // GetBroadcastFiles получает все файлы рассылки
 func (r *LessonBroadcastRepo) GetBroadcastFiles(ctx context.Context, broadcastID uuid.UUID) ([]*models.BroadcastFile, error) {
 	query := `
-		SELECT id, broadcast_id, file_name, file_path, file_size, mime_type, uploaded_at
+		SELECT id, broadcast_id, file_name, file_path, file_size, mime_type, uploaded_at, storage_key, content_hash
 		FROM broadcast_files
 		WHERE broadcast_id = $1
 		ORDER BY uploaded_at ASC
@@ -238,7 +240,7 @@ func (r *LessonBroadcastRepo) GetBroadcastFiles(ctx context.Context, broadcastID
 // GetBroadcastFile получает один файл рассылки по ID
 func (r *LessonBroadcastRepo) GetBroadcastFile(ctx context.Context, fileID uuid.UUID) (*models.BroadcastFile, error) {
 	query := `
-		SELECT id, broadcast_id, file_name, file_path, file_size, mime_type, uploaded_at
+		SELECT id, broadcast_id, file_name, file_path, file_size, mime_type, uploaded_at, storage_key, content_hash
 		FROM broadcast_files
 		WHERE id = $1
 	`
@@ -69,6 +69,7 @@ func (r *LessonModificationRepository) GetModificationsByLesson(ctx context.Cont
 		SELECT
 			lm.id, lm.original_lesson_id, lm.modification_type, lm.applied_by_id,
 			lm.applied_at, lm.affected_lessons_count, lm.changes_json, lm.notes,
+			lm.reverted_at, lm.reverted_by_id,
 			COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email) as applied_by_name
 		FROM lesson_modifications lm
 		JOIN users u ON lm.applied_by_id = u.id
@@ -91,6 +92,7 @@ func (r *LessonModificationRepository) GetModificationsByType(ctx context.Contex
 		SELECT
 			lm.id, lm.original_lesson_id, lm.modification_type, lm.applied_by_id,
 			lm.applied_at, lm.affected_lessons_count, lm.changes_json, lm.notes,
+			lm.reverted_at, lm.reverted_by_id,
 			COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email) as applied_by_name
 		FROM lesson_modifications lm
 		JOIN users u ON lm.applied_by_id = u.id
@@ -113,6 +115,7 @@ func (r *LessonModificationRepository) GetRecentModifications(ctx context.Contex
 		SELECT
 			lm.id, lm.original_lesson_id, lm.modification_type, lm.applied_by_id,
 			lm.applied_at, lm.affected_lessons_count, lm.changes_json, lm.notes,
+			lm.reverted_at, lm.reverted_by_id,
 			COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email) as applied_by_name
 		FROM lesson_modifications lm
 		JOIN users u ON lm.applied_by_id = u.id
@@ -135,6 +138,7 @@ func (r *LessonModificationRepository) GetModificationByID(ctx context.Context,
 		SELECT
 			lm.id, lm.original_lesson_id, lm.modification_type, lm.applied_by_id,
 			lm.applied_at, lm.affected_lessons_count, lm.changes_json, lm.notes,
+			lm.reverted_at, lm.reverted_by_id,
 			COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email) as applied_by_name
 		FROM lesson_modifications lm
 		JOIN users u ON lm.applied_by_id = u.id
@@ -145,7 +149,7 @@ func (r *LessonModificationRepository) GetModificationByID(ctx context.Context,
 	err := r.db.GetContext(ctx, &modification, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("modification not found")
+			return nil, ErrLessonModificationNotFound
 		}
 		return nil, fmt.Errorf("failed to get modification: %w", err)
 	}
@@ -159,6 +163,7 @@ func (r *LessonModificationRepository) GetModificationsByDateRange(ctx context.C
 		SELECT
 			lm.id, lm.original_lesson_id, lm.modification_type, lm.applied_by_id,
 			lm.applied_at, lm.affected_lessons_count, lm.changes_json, lm.notes,
+			lm.reverted_at, lm.reverted_by_id,
 			COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email) as applied_by_name
 		FROM lesson_modifications lm
 		JOIN users u ON lm.applied_by_id = u.id
@@ -181,6 +186,7 @@ func (r *LessonModificationRepository) GetModificationsByAdmin(ctx context.Conte
 		SELECT
 			lm.id, lm.original_lesson_id, lm.modification_type, lm.applied_by_id,
 			lm.applied_at, lm.affected_lessons_count, lm.changes_json, lm.notes,
+			lm.reverted_at, lm.reverted_by_id,
 			COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email) as applied_by_name
 		FROM lesson_modifications lm
 		JOIN users u ON lm.applied_by_id = u.id
@@ -270,3 +276,188 @@ func (r *LessonModificationRepository) LogModificationTx(ctx context.Context, tx
 
 	return nil
 }
+
+// GetModificationByIDForUpdateTx retrieves and locks a modification within a transaction
+func (r *LessonModificationRepository) GetModificationByIDForUpdateTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.LessonModification, error) {
+	query := `
+		SELECT
+			id, original_lesson_id, modification_type, applied_by_id,
+			applied_at, affected_lessons_count, changes_json, notes,
+			reverted_at, reverted_by_id
+		FROM lesson_modifications
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var modification models.LessonModification
+	err := tx.QueryRow(ctx, query, id).Scan(
+		&modification.ID,
+		&modification.OriginalLessonID,
+		&modification.ModificationType,
+		&modification.AppliedByID,
+		&modification.AppliedAt,
+		&modification.AffectedLessonsCount,
+		&modification.ChangesJSON,
+		&modification.Notes,
+		&modification.RevertedAt,
+		&modification.RevertedByID,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrLessonModificationNotFound
+		}
+		return nil, fmt.Errorf("failed to get modification for update: %w", err)
+	}
+
+	return &modification, nil
+}
+
+// MarkRevertedTx marks a modification as reverted within a transaction
+func (r *LessonModificationRepository) MarkRevertedTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, revertedByID uuid.UUID) error {
+	query := `
+		UPDATE lesson_modifications
+		SET reverted_at = $1, reverted_by_id = $2
+		WHERE id = $3 AND reverted_at IS NULL
+	`
+
+	result, err := tx.Exec(ctx, query, time.Now(), revertedByID, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark modification as reverted: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrLessonModificationNotFound
+	}
+
+	return nil
+}
+
+// SaveSnapshotsTx stores per-lesson before/after snapshots of a modification within a transaction
+func (r *LessonModificationRepository) SaveSnapshotsTx(ctx context.Context, tx pgx.Tx, snapshots []*models.LessonModificationSnapshot) error {
+	query := `
+		INSERT INTO lesson_modification_snapshots
+		(id, modification_id, lesson_id, before_state, after_state, credit_movements, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	now := time.Now()
+	for _, snapshot := range snapshots {
+		before, err := json.Marshal(snapshot.Before)
+		if err != nil {
+			return fmt.Errorf("failed to marshal before state: %w", err)
+		}
+		after, err := json.Marshal(snapshot.After)
+		if err != nil {
+			return fmt.Errorf("failed to marshal after state: %w", err)
+		}
+		movements := snapshot.CreditMovements
+		if movements == nil {
+			movements = []models.CreditMovement{}
+		}
+		movementsJSON, err := json.Marshal(movements)
+		if err != nil {
+			return fmt.Errorf("failed to marshal credit movements: %w", err)
+		}
+
+		snapshot.ID = uuid.New()
+		snapshot.CreatedAt = now
+
+		if _, err := tx.Exec(ctx, query,
+			snapshot.ID,
+			snapshot.ModificationID,
+			snapshot.LessonID,
+			before,
+			after,
+			movementsJSON,
+			snapshot.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to save snapshot for lesson %s: %w", snapshot.LessonID, err)
+		}
+	}
+
+	return nil
+}
+
+// GetSnapshotsTx retrieves all lesson snapshots of a modification within a transaction
+func (r *LessonModificationRepository) GetSnapshotsTx(ctx context.Context, tx pgx.Tx, modificationID uuid.UUID) ([]*models.LessonModificationSnapshot, error) {
+	rows, err := tx.Query(ctx, snapshotSelectQuery, modificationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get modification snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*models.LessonModificationSnapshot
+	for rows.Next() {
+		snapshot, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading snapshot rows: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// GetSnapshots retrieves all lesson snapshots of a modification
+func (r *LessonModificationRepository) GetSnapshots(ctx context.Context, modificationID uuid.UUID) ([]*models.LessonModificationSnapshot, error) {
+	rows, err := r.db.QueryContext(ctx, snapshotSelectQuery, modificationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get modification snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []*models.LessonModificationSnapshot{}
+	for rows.Next() {
+		snapshot, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading snapshot rows: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+const snapshotSelectQuery = `
+	SELECT id, modification_id, lesson_id, before_state, after_state, credit_movements, created_at
+	FROM lesson_modification_snapshots
+	WHERE modification_id = $1
+	ORDER BY created_at ASC, lesson_id ASC
+`
+
+// scanSnapshot scans a snapshot row and decodes JSON states
+func scanSnapshot(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.LessonModificationSnapshot, error) {
+	var snapshot models.LessonModificationSnapshot
+	var before, after, movements []byte
+
+	if err := row.Scan(
+		&snapshot.ID,
+		&snapshot.ModificationID,
+		&snapshot.LessonID,
+		&before,
+		&after,
+		&movements,
+		&snapshot.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("failed to scan snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal(before, &snapshot.Before); err != nil {
+		return nil, fmt.Errorf("failed to decode before state: %w", err)
+	}
+	if err := json.Unmarshal(after, &snapshot.After); err != nil {
+		return nil, fmt.Errorf("failed to decode after state: %w", err)
+	}
+	if err := json.Unmarshal(movements, &snapshot.CreditMovements); err != nil {
+		return nil, fmt.Errorf("failed to decode credit movements: %w", err)
+	}
+
+	return &snapshot, nil
+}
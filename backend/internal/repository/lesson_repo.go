@@ -508,6 +508,34 @@ func (r *LessonRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*mo
 	return lessons, nil
 }
 
+// GetUpcomingInRange получает будущие занятия, начинающиеся в периоде [from, to]
+// Используется для поиска занятий, попадающих на праздники и каникулы
+func (r *LessonRepository) GetUpcomingInRange(ctx context.Context, from, to time.Time) ([]*models.LessonWithTeacher, error) {
+	query := `
+		SELECT
+			l.id, l.teacher_id, l.start_time, l.end_time,
+			l.max_students, l.current_students, l.credits_cost, l.color, l.subject, l.homework_text, l.report_text, l.link,
+			l.is_recurring, l.recurring_group_id, l.recurring_end_date,
+			l.created_at, l.updated_at, l.deleted_at,
+			CONCAT(u.first_name, ' ', u.last_name) as teacher_name
+		FROM lessons l
+		JOIN users u ON l.teacher_id = u.id
+		WHERE l.deleted_at IS NULL
+		  AND l.start_time > NOW()
+		  AND l.start_time >= $1
+		  AND l.start_time < $2
+		ORDER BY l.start_time ASC
+	`
+
+	var lessons []*models.LessonWithTeacher
+	err := r.db.SelectContext(ctx, &lessons, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upcoming lessons in range: %w", err)
+	}
+
+	return lessons, nil
+}
+
 // Delete выполняет мягкое удаление занятия с проверками целостности данных
 func (r *LessonRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	var hasActiveBookings bool
@@ -668,6 +696,83 @@ func (r *LessonRepository) GetLessonsByTimePattern(ctx context.Context, teacherI
 	return lessons, nil
 }
 
+// GetOverlappingTeacherLessons returns teacher's lessons overlapping the [start, end) interval
+func (r *LessonRepository) GetOverlappingTeacherLessons(ctx context.Context, teacherID uuid.UUID, start, end time.Time) ([]*models.Lesson, error) {
+	query := `
+		SELECT ` + LessonSelectFields + `
+		FROM lessons
+		WHERE teacher_id = $1
+		  AND start_time < $3
+		  AND end_time > $2
+		  AND deleted_at IS NULL
+		ORDER BY start_time ASC
+	`
+
+	var lessons []*models.Lesson
+	err := r.db.SelectContext(ctx, &lessons, query, teacherID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overlapping teacher lessons: %w", err)
+	}
+
+	return lessons, nil
+}
+
+// GetFutureSeriesLessonsForUpdate получает будущие занятия указанных серий с блокировкой строк (SELECT FOR UPDATE)
+func (r *LessonRepository) GetFutureSeriesLessonsForUpdate(ctx context.Context, tx pgx.Tx, recurringGroupIDs []uuid.UUID, after time.Time) ([]*models.Lesson, error) {
+	if len(recurringGroupIDs) == 0 {
+		return []*models.Lesson{}, nil
+	}
+
+	query := `
+		SELECT ` + LessonSelectFields + `
+		FROM lessons
+		WHERE recurring_group_id = ANY($1)
+		  AND start_time > $2
+		  AND deleted_at IS NULL
+		ORDER BY start_time ASC
+		FOR UPDATE
+	`
+
+	rows, err := tx.Query(ctx, query, recurringGroupIDs, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get future series lessons: %w", err)
+	}
+	defer rows.Close()
+
+	lessons := make([]*models.Lesson, 0)
+	for rows.Next() {
+		var lesson models.Lesson
+		if err := rows.Scan(
+			&lesson.ID,
+			&lesson.TeacherID,
+			&lesson.StartTime,
+			&lesson.EndTime,
+			&lesson.MaxStudents,
+			&lesson.CurrentStudents,
+			&lesson.CreditsCost,
+			&lesson.Color,
+			&lesson.Subject,
+			&lesson.HomeworkText,
+			&lesson.ReportText,
+			&lesson.Link,
+			&lesson.IsRecurring,
+			&lesson.RecurringGroupID,
+			&lesson.RecurringEndDate,
+			&lesson.CreatedAt,
+			&lesson.UpdatedAt,
+			&lesson.DeletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan series lesson: %w", err)
+		}
+		lessons = append(lessons, &lesson)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate series lessons: %w", err)
+	}
+
+	return lessons, nil
+}
+
 // IsStudentBookedForLessonTx checks if a student is already booked for a lesson within a transaction
 func (r *LessonRepository) IsStudentBookedForLessonTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, studentID uuid.UUID) (bool, error) {
 	query := `
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ModerationRepository работает с очередью рассмотрения заблокированных сообщений
+type ModerationRepository struct {
+	db *sqlx.DB
+}
+
+// NewModerationRepository создает новый ModerationRepository
+func NewModerationRepository(db *sqlx.DB) *ModerationRepository {
+	return &ModerationRepository{db: db}
+}
+
+// blockedMessageSelect выборка блокировок с текстом сообщения, отправителем и модератором
+const blockedMessageSelect = `
+	SELECT b.id, b.message_id, m.room_id, m.sender_id,
+		COALESCE(NULLIF(TRIM(CONCAT(s.first_name, ' ', s.last_name)), ''), s.email, '') AS sender_name,
+		m.message_text, b.reason, b.rule, b.ai_response, b.blocked_at,
+		b.review_status, b.reviewed_by,
+		COALESCE(NULLIF(TRIM(CONCAT(rv.first_name, ' ', rv.last_name)), ''), rv.email, '') AS reviewer_name,
+		b.reviewed_at, b.review_note, b.appeal_reason, b.appealed_at
+	FROM blocked_messages b
+	JOIN messages m ON m.id = b.message_id
+	LEFT JOIN users s ON s.id = m.sender_id
+	LEFT JOIN users rv ON rv.id = b.reviewed_by
+`
+
+// ListBlocked получает блокировки по фильтру: сначала с апелляцией, затем новые
+func (r *ModerationRepository) ListBlocked(ctx context.Context, filter *models.ModerationQueueFilter) ([]*models.BlockedMessageReview, int, error) {
+	conditions := []string{"m.deleted_at IS NULL"}
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Status != "" {
+		addCondition("b.review_status = $%d", filter.Status)
+	}
+	if filter.Rule != "" {
+		addCondition("b.rule = $%d", filter.Rule)
+	}
+	if filter.AppealedOnly {
+		conditions = append(conditions, "b.appealed_at IS NOT NULL")
+	}
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM blocked_messages b JOIN messages m ON m.id = b.message_id ` + where
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count blocked messages: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s %s
+		ORDER BY b.appealed_at DESC NULLS LAST, b.blocked_at DESC
+		LIMIT $%d OFFSET $%d
+	`, blockedMessageSelect, where, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.Offset)
+
+	items := []*models.BlockedMessageReview{}
+	if err := r.db.SelectContext(ctx, &items, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list blocked messages: %w", err)
+	}
+	return items, total, nil
+}
+
+// ListBlockedBySender получает блокировки сообщений отправителя, новые сверху
+func (r *ModerationRepository) ListBlockedBySender(ctx context.Context, senderID uuid.UUID) ([]*models.BlockedMessageReview, error) {
+	items := []*models.BlockedMessageReview{}
+	query := blockedMessageSelect + ` WHERE m.sender_id = $1 AND m.deleted_at IS NULL ORDER BY b.blocked_at DESC LIMIT $2`
+	if err := r.db.SelectContext(ctx, &items, query, senderID, models.MaxModerationPageSize); err != nil {
+		return nil, fmt.Errorf("failed to list blocked messages by sender: %w", err)
+	}
+	return items, nil
+}
+
+// GetBlocked получает блокировку по ID
+func (r *ModerationRepository) GetBlocked(ctx context.Context, id uuid.UUID) (*models.BlockedMessageReview, error) {
+	var item models.BlockedMessageReview
+	if err := r.db.GetContext(ctx, &item, blockedMessageSelect+` WHERE b.id = $1 AND m.deleted_at IS NULL`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBlockedMessageNotFound
+		}
+		return nil, fmt.Errorf("failed to get blocked message: %w", err)
+	}
+	return &item, nil
+}
+
+// Review сохраняет решение по блокировке, ожидающей рассмотрения. При снятии блокировки
+// сообщение переводится в delivered в той же транзакции
+func (r *ModerationRepository) Review(ctx context.Context, id, reviewerID uuid.UUID, status, note string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var messageID uuid.UUID
+	err = tx.GetContext(ctx, &messageID, `
+		UPDATE blocked_messages
+		SET review_status = $2, admin_reviewed = true, reviewed_by = $3,
+			reviewed_at = CURRENT_TIMESTAMP, review_note = $4
+		WHERE id = $1 AND review_status = $5
+		RETURNING message_id
+	`, id, status, reviewerID, note, models.ModerationReviewPending)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrModerationAlreadyReviewed
+		}
+		return fmt.Errorf("failed to review blocked message: %w", err)
+	}
+
+	if status == models.ModerationReviewReleased {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE messages SET status = $2, moderation_completed_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND deleted_at IS NULL
+		`, messageID, models.MessageStatusDelivered)
+		if err != nil {
+			return fmt.Errorf("failed to release message: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		} else if rows == 0 {
+			return ErrMessageNotFound
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit review: %w", err)
+	}
+	return nil
+}
+
+// Appeal сохраняет апелляцию отправителя. Подтвержденная блокировка возвращается в очередь.
+// Возвращает false, если апелляция уже подана или блокировка снята
+func (r *ModerationRepository) Appeal(ctx context.Context, id uuid.UUID, reason string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE blocked_messages
+		SET appeal_reason = $2, appealed_at = CURRENT_TIMESTAMP,
+			review_status = $3, admin_reviewed = false
+		WHERE id = $1 AND appealed_at IS NULL AND review_status <> $4
+	`, id, reason, models.ModerationReviewPending, models.ModerationReviewReleased)
+	if err != nil {
+		return false, fmt.Errorf("failed to appeal blocked message: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// RuleStats получает статистику блокировок по правилам модерации
+func (r *ModerationRepository) RuleStats(ctx context.Context) ([]*models.ModerationRuleStats, error) {
+	stats := []*models.ModerationRuleStats{}
+	err := r.db.SelectContext(ctx, &stats, `
+		SELECT rule,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE review_status = $1) AS pending,
+			COUNT(*) FILTER (WHERE review_status = $2) AS released,
+			COUNT(*) FILTER (WHERE review_status = $3) AS confirmed,
+			COUNT(*) FILTER (WHERE appealed_at IS NOT NULL) AS appealed
+		FROM blocked_messages
+		GROUP BY rule
+		ORDER BY total DESC, rule
+	`, models.ModerationReviewPending, models.ModerationReviewReleased, models.ModerationReviewConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation rule stats: %w", err)
+	}
+
+	for _, s := range stats {
+		s.CalculateReleaseRate()
+	}
+	return stats, nil
+}
@@ -0,0 +1,529 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// conductedLessonsQuery выбирает проведенные занятия: неудаленные, начавшиеся в [$1, $2),
+	// завершившиеся не позже $3 и имеющие хотя бы одну активную запись
+	conductedLessonsQuery = `
+		SELECT l.id AS lesson_id, l.teacher_id, CONCAT(t.first_name, ' ', t.last_name) AS teacher_name,
+			l.start_time, l.end_time, COALESCE(l.subject, '') AS subject,
+			COUNT(b.id)::int AS students_count, l.credits_cost
+		FROM lessons l
+		JOIN users t ON t.id = l.teacher_id
+		JOIN bookings b ON b.lesson_id = l.id AND b.status = 'active'
+		WHERE l.deleted_at IS NULL
+			AND l.start_time >= $1 AND l.start_time < $2
+			AND l.end_time <= $3
+			AND ($4::uuid IS NULL OR l.teacher_id = $4)
+		GROUP BY l.id, t.first_name, t.last_name
+		ORDER BY l.start_time
+	`
+
+	// payRatesQuery выбирает ставки, вступившие в силу не позже $1 (все ставки, если $1 NULL)
+	payRatesQuery = `
+		SELECT r.id, r.teacher_id, CONCAT(t.first_name, ' ', t.last_name) AS teacher_name,
+			r.rate_type, r.amount::float8 AS amount, r.credit_value::float8 AS credit_value,
+			r.effective_from, r.created_by, r.created_at
+		FROM teacher_pay_rates r
+		JOIN users t ON t.id = r.teacher_id
+		WHERE ($1::date IS NULL OR r.effective_from <= $1)
+			AND ($2::uuid IS NULL OR r.teacher_id = $2)
+		ORDER BY t.last_name, t.first_name, r.effective_from DESC
+	`
+
+	adjustmentSelectQuery = `
+		SELECT a.id, a.teacher_id, CONCAT(t.first_name, ' ', t.last_name) AS teacher_name,
+			a.period_id, a.amount::float8 AS amount, a.reason, a.applies_on, a.created_by, a.created_at
+		FROM teacher_earnings_adjustments a
+		JOIN users t ON t.id = a.teacher_id
+	`
+
+	statementSelectQuery = `
+		SELECT s.id, s.period_id, s.teacher_id, CONCAT(t.first_name, ' ', t.last_name) AS teacher_name,
+			p.period_start, p.period_end, s.lessons_count, s.unrated_lessons,
+			s.student_hours::float8 AS student_hours, s.credits_consumed,
+			s.lessons_amount::float8 AS lessons_amount, s.adjustments_amount::float8 AS adjustments_amount,
+			s.total_amount::float8 AS total_amount, s.created_at
+		FROM teacher_earnings_statements s
+		JOIN payroll_periods p ON p.id = s.period_id
+		JOIN users t ON t.id = s.teacher_id
+	`
+
+	periodSelectQuery = `
+		SELECT p.id, p.period_start, p.period_end, p.closed_by, p.closed_at,
+			COUNT(s.id)::int AS teachers_count, COALESCE(SUM(s.total_amount), 0)::float8 AS total_amount
+		FROM payroll_periods p
+		LEFT JOIN teacher_earnings_statements s ON s.period_id = p.id
+	`
+)
+
+// AdjustmentFilter фильтр списка корректировок
+type AdjustmentFilter struct {
+	TeacherID   *uuid.UUID
+	PeriodID    *uuid.UUID
+	PendingOnly bool
+}
+
+// PayrollRepository управляет ставками преподавателей, расчетными периодами, ведомостями и корректировками
+type PayrollRepository struct {
+	db *sqlx.DB
+}
+
+// NewPayrollRepository создает новый PayrollRepository
+func NewPayrollRepository(db *sqlx.DB) *PayrollRepository {
+	return &PayrollRepository{db: db}
+}
+
+// CreateRate сохраняет новую ставку преподавателя
+func (r *PayrollRepository) CreateRate(ctx context.Context, rate *models.TeacherPayRate) error {
+	query := `
+		INSERT INTO teacher_pay_rates (id, teacher_id, rate_type, amount, credit_value, effective_from, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	rate.ID = uuid.New()
+	rate.CreatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, query,
+		rate.ID,
+		rate.TeacherID,
+		rate.RateType,
+		rate.Amount,
+		rate.CreditValue,
+		rate.EffectiveFrom,
+		rate.CreatedBy,
+		rate.CreatedAt,
+	)
+	if err != nil {
+		if IsUniqueViolationError(err) {
+			return ErrPayRateExists
+		}
+		return fmt.Errorf("failed to create pay rate: %w", err)
+	}
+
+	return nil
+}
+
+// ListRates получает историю ставок (все преподаватели или один), новые ставки первыми
+func (r *PayrollRepository) ListRates(ctx context.Context, teacherID *uuid.UUID) ([]*models.TeacherPayRate, error) {
+	rates := []*models.TeacherPayRate{}
+	if err := r.db.SelectContext(ctx, &rates, payRatesQuery, nil, teacherID); err != nil {
+		return nil, fmt.Errorf("failed to list pay rates: %w", err)
+	}
+	return rates, nil
+}
+
+// GetRatesEffectiveBy получает ставки, вступившие в силу не позже даты day
+func (r *PayrollRepository) GetRatesEffectiveBy(ctx context.Context, day time.Time, teacherID *uuid.UUID) ([]*models.TeacherPayRate, error) {
+	rates := []*models.TeacherPayRate{}
+	if err := r.db.SelectContext(ctx, &rates, payRatesQuery, day, teacherID); err != nil {
+		return nil, fmt.Errorf("failed to get pay rates: %w", err)
+	}
+	return rates, nil
+}
+
+// GetRatesEffectiveByTx получает ставки, вступившие в силу не позже даты day, в транзакции
+func (r *PayrollRepository) GetRatesEffectiveByTx(ctx context.Context, tx pgx.Tx, day time.Time) ([]*models.TeacherPayRate, error) {
+	rows, err := tx.Query(ctx, payRatesQuery, day, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pay rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []*models.TeacherPayRate
+	for rows.Next() {
+		var rate models.TeacherPayRate
+		if err := rows.Scan(
+			&rate.ID,
+			&rate.TeacherID,
+			&rate.TeacherName,
+			&rate.RateType,
+			&rate.Amount,
+			&rate.CreditValue,
+			&rate.EffectiveFrom,
+			&rate.CreatedBy,
+			&rate.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan pay rate: %w", err)
+		}
+		rates = append(rates, &rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading pay rate rows: %w", err)
+	}
+
+	return rates, nil
+}
+
+// GetConductedLessons получает проведенные занятия, начавшиеся в [from, to) и завершившиеся к моменту now
+func (r *PayrollRepository) GetConductedLessons(ctx context.Context, from, to, now time.Time, teacherID *uuid.UUID) ([]*models.PayrollLesson, error) {
+	lessons := []*models.PayrollLesson{}
+	if err := r.db.SelectContext(ctx, &lessons, conductedLessonsQuery, from, to, now, teacherID); err != nil {
+		return nil, fmt.Errorf("failed to get conducted lessons: %w", err)
+	}
+	return lessons, nil
+}
+
+// GetConductedLessonsTx получает проведенные занятия периода в транзакции закрытия периода
+func (r *PayrollRepository) GetConductedLessonsTx(ctx context.Context, tx pgx.Tx, from, to, now time.Time) ([]*models.PayrollLesson, error) {
+	rows, err := tx.Query(ctx, conductedLessonsQuery, from, to, now, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conducted lessons: %w", err)
+	}
+	defer rows.Close()
+
+	var lessons []*models.PayrollLesson
+	for rows.Next() {
+		var lesson models.PayrollLesson
+		if err := rows.Scan(
+			&lesson.LessonID,
+			&lesson.TeacherID,
+			&lesson.TeacherName,
+			&lesson.StartTime,
+			&lesson.EndTime,
+			&lesson.Subject,
+			&lesson.StudentsCount,
+			&lesson.CreditsCost,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan conducted lesson: %w", err)
+		}
+		lessons = append(lessons, &lesson)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading conducted lesson rows: %w", err)
+	}
+
+	return lessons, nil
+}
+
+// LockAdjustmentsTx блокирует создание и удаление корректировок до конца транзакции закрытия периода,
+// чтобы корректировка не осталась непривязанной внутри уже закрытого периода
+func (r *PayrollRepository) LockAdjustmentsTx(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, `LOCK TABLE teacher_earnings_adjustments IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock earnings adjustments: %w", err)
+	}
+	return nil
+}
+
+// CreatePeriodTx создает закрытый расчетный период
+func (r *PayrollRepository) CreatePeriodTx(ctx context.Context, tx pgx.Tx, period *models.PayrollPeriod) error {
+	query := `
+		INSERT INTO payroll_periods (id, period_start, period_end, closed_by, closed_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	period.ID = uuid.New()
+	period.ClosedAt = time.Now()
+
+	_, err := tx.Exec(ctx, query, period.ID, period.PeriodStart, period.PeriodEnd, period.ClosedBy, period.ClosedAt)
+	if err != nil {
+		if IsExclusionViolationError(err) {
+			return ErrPayrollPeriodOverlap
+		}
+		return fmt.Errorf("failed to create payroll period: %w", err)
+	}
+
+	return nil
+}
+
+// AttachAdjustmentsTx привязывает к периоду непривязанные корректировки с датой внутри периода
+// и возвращает их
+func (r *PayrollRepository) AttachAdjustmentsTx(ctx context.Context, tx pgx.Tx, period *models.PayrollPeriod) ([]*models.EarningsAdjustment, error) {
+	update := `
+		UPDATE teacher_earnings_adjustments
+		SET period_id = $1
+		WHERE period_id IS NULL AND applies_on BETWEEN $2 AND $3
+	`
+	if _, err := tx.Exec(ctx, update, period.ID, period.PeriodStart, period.PeriodEnd); err != nil {
+		return nil, fmt.Errorf("failed to attach earnings adjustments: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, adjustmentSelectQuery+` WHERE a.period_id = $1 ORDER BY a.applies_on, a.created_at`, period.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attached adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	var adjustments []*models.EarningsAdjustment
+	for rows.Next() {
+		var adj models.EarningsAdjustment
+		if err := rows.Scan(
+			&adj.ID,
+			&adj.TeacherID,
+			&adj.TeacherName,
+			&adj.PeriodID,
+			&adj.Amount,
+			&adj.Reason,
+			&adj.AppliesOn,
+			&adj.CreatedBy,
+			&adj.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan adjustment: %w", err)
+		}
+		adjustments = append(adjustments, &adj)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading adjustment rows: %w", err)
+	}
+
+	return adjustments, nil
+}
+
+// CreateStatementTx сохраняет ведомость преподавателя и ее строки
+func (r *PayrollRepository) CreateStatementTx(ctx context.Context, tx pgx.Tx, st *models.EarningsStatement) error {
+	query := `
+		INSERT INTO teacher_earnings_statements (id, period_id, teacher_id, lessons_count, unrated_lessons,
+			student_hours, credits_consumed, lessons_amount, adjustments_amount, total_amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	st.ID = uuid.New()
+	st.CreatedAt = time.Now()
+
+	_, err := tx.Exec(ctx, query,
+		st.ID,
+		st.PeriodID,
+		st.TeacherID,
+		st.LessonsCount,
+		st.UnratedLessons,
+		st.StudentHours,
+		st.CreditsConsumed,
+		st.LessonsAmount,
+		st.AdjustmentsAmount,
+		st.TotalAmount,
+		st.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create earnings statement: %w", err)
+	}
+
+	lineQuery := `
+		INSERT INTO teacher_earnings_lines (id, statement_id, lesson_id, start_time, end_time, subject,
+			students_count, student_hours, credits_consumed, rate_type, rate_amount, amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	for _, line := range st.Lines {
+		line.ID = uuid.New()
+		line.StatementID = st.ID
+		if _, err := tx.Exec(ctx, lineQuery,
+			line.ID,
+			line.StatementID,
+			line.LessonID,
+			line.StartTime,
+			line.EndTime,
+			line.Subject,
+			line.StudentsCount,
+			line.StudentHours,
+			line.CreditsConsumed,
+			line.RateType,
+			line.RateAmount,
+			line.Amount,
+		); err != nil {
+			return fmt.Errorf("failed to create earnings line for lesson %s: %w", line.LessonID.UUID, err)
+		}
+	}
+
+	return nil
+}
+
+// ListPeriods получает закрытые периоды с итогами, новые первыми
+func (r *PayrollRepository) ListPeriods(ctx context.Context) ([]*models.PayrollPeriod, error) {
+	query := periodSelectQuery + `
+		GROUP BY p.id
+		ORDER BY p.period_start DESC
+	`
+
+	periods := []*models.PayrollPeriod{}
+	if err := r.db.SelectContext(ctx, &periods, query); err != nil {
+		return nil, fmt.Errorf("failed to list payroll periods: %w", err)
+	}
+	return periods, nil
+}
+
+// GetPeriod получает закрытый период по ID
+func (r *PayrollRepository) GetPeriod(ctx context.Context, id uuid.UUID) (*models.PayrollPeriod, error) {
+	query := periodSelectQuery + `
+		WHERE p.id = $1
+		GROUP BY p.id
+	`
+
+	var period models.PayrollPeriod
+	if err := r.db.GetContext(ctx, &period, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPayrollPeriodNotFound
+		}
+		return nil, fmt.Errorf("failed to get payroll period: %w", err)
+	}
+	return &period, nil
+}
+
+// GetLastPeriodEnd получает дату окончания последнего закрытого периода (nil, если периодов нет)
+func (r *PayrollRepository) GetLastPeriodEnd(ctx context.Context) (*time.Time, error) {
+	var end sql.NullTime
+	if err := r.db.GetContext(ctx, &end, `SELECT MAX(period_end) FROM payroll_periods`); err != nil {
+		return nil, fmt.Errorf("failed to get last payroll period: %w", err)
+	}
+	if !end.Valid {
+		return nil, nil
+	}
+	return &end.Time, nil
+}
+
+// IsDateClosed проверяет, попадает ли дата в закрытый период
+func (r *PayrollRepository) IsDateClosed(ctx context.Context, day time.Time) (bool, error) {
+	var closed bool
+	query := `SELECT EXISTS(SELECT 1 FROM payroll_periods WHERE $1::date BETWEEN period_start AND period_end)`
+	if err := r.db.GetContext(ctx, &closed, query, day); err != nil {
+		return false, fmt.Errorf("failed to check payroll period: %w", err)
+	}
+	return closed, nil
+}
+
+// ListStatements получает ведомости периода или преподавателя
+func (r *PayrollRepository) ListStatements(ctx context.Context, periodID, teacherID *uuid.UUID) ([]*models.EarningsStatement, error) {
+	query := statementSelectQuery + `
+		WHERE ($1::uuid IS NULL OR s.period_id = $1)
+			AND ($2::uuid IS NULL OR s.teacher_id = $2)
+		ORDER BY p.period_start DESC, t.last_name, t.first_name
+	`
+
+	statements := []*models.EarningsStatement{}
+	if err := r.db.SelectContext(ctx, &statements, query, periodID, teacherID); err != nil {
+		return nil, fmt.Errorf("failed to list earnings statements: %w", err)
+	}
+	return statements, nil
+}
+
+// GetStatement получает ведомость преподавателя за период вместе со строками и корректировками
+func (r *PayrollRepository) GetStatement(ctx context.Context, periodID, teacherID uuid.UUID) (*models.EarningsStatement, error) {
+	query := statementSelectQuery + `
+		WHERE s.period_id = $1 AND s.teacher_id = $2
+	`
+
+	var st models.EarningsStatement
+	if err := r.db.GetContext(ctx, &st, query, periodID, teacherID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEarningsStatementNotFound
+		}
+		return nil, fmt.Errorf("failed to get earnings statement: %w", err)
+	}
+
+	lines, err := r.GetStatementLines(ctx, st.ID)
+	if err != nil {
+		return nil, err
+	}
+	st.Lines = lines
+
+	adjustments, err := r.ListAdjustments(ctx, AdjustmentFilter{TeacherID: &teacherID, PeriodID: &periodID})
+	if err != nil {
+		return nil, err
+	}
+	st.Adjustments = adjustments
+
+	return &st, nil
+}
+
+// GetStatementLines получает строки ведомости в порядке проведения занятий
+func (r *PayrollRepository) GetStatementLines(ctx context.Context, statementID uuid.UUID) ([]*models.EarningsLine, error) {
+	query := `
+		SELECT id, statement_id, lesson_id, start_time, end_time, subject, students_count,
+			student_hours::float8 AS student_hours, credits_consumed, rate_type,
+			rate_amount::float8 AS rate_amount, amount::float8 AS amount
+		FROM teacher_earnings_lines
+		WHERE statement_id = $1
+		ORDER BY start_time
+	`
+
+	lines := []*models.EarningsLine{}
+	if err := r.db.SelectContext(ctx, &lines, query, statementID); err != nil {
+		return nil, fmt.Errorf("failed to get earnings lines: %w", err)
+	}
+	return lines, nil
+}
+
+// CreateAdjustment сохраняет корректировку. Вставка не выполняется, если дата уже попала
+// в закрытый период (проверка атомарна относительно закрытия периода благодаря LockAdjustmentsTx)
+func (r *PayrollRepository) CreateAdjustment(ctx context.Context, adj *models.EarningsAdjustment) error {
+	query := `
+		INSERT INTO teacher_earnings_adjustments (id, teacher_id, amount, reason, applies_on, created_by, created_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE NOT EXISTS (SELECT 1 FROM payroll_periods WHERE $5::date BETWEEN period_start AND period_end)
+	`
+
+	adj.ID = uuid.New()
+	adj.CreatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		adj.ID,
+		adj.TeacherID,
+		adj.Amount,
+		adj.Reason,
+		adj.AppliesOn,
+		adj.CreatedBy,
+		adj.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create earnings adjustment: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return models.ErrPayrollPeriodClosed
+	}
+
+	return nil
+}
+
+// ListAdjustments получает корректировки по фильтру
+func (r *PayrollRepository) ListAdjustments(ctx context.Context, filter AdjustmentFilter) ([]*models.EarningsAdjustment, error) {
+	query := adjustmentSelectQuery + `
+		WHERE ($1::uuid IS NULL OR a.teacher_id = $1)
+			AND ($2::uuid IS NULL OR a.period_id = $2)
+			AND (NOT $3::boolean OR a.period_id IS NULL)
+		ORDER BY a.applies_on DESC, a.created_at DESC
+	`
+
+	adjustments := []*models.EarningsAdjustment{}
+	if err := r.db.SelectContext(ctx, &adjustments, query, filter.TeacherID, filter.PeriodID, filter.PendingOnly); err != nil {
+		return nil, fmt.Errorf("failed to list earnings adjustments: %w", err)
+	}
+	return adjustments, nil
+}
+
+// GetPendingAdjustments получает непривязанные корректировки с датой в [from, to] для предварительного расчета
+func (r *PayrollRepository) GetPendingAdjustments(ctx context.Context, from, to time.Time, teacherID *uuid.UUID) ([]*models.EarningsAdjustment, error) {
+	query := adjustmentSelectQuery + `
+		WHERE a.period_id IS NULL AND a.applies_on BETWEEN $1 AND $2
+			AND ($3::uuid IS NULL OR a.teacher_id = $3)
+		ORDER BY a.applies_on, a.created_at
+	`
+
+	adjustments := []*models.EarningsAdjustment{}
+	if err := r.db.SelectContext(ctx, &adjustments, query, from, to, teacherID); err != nil {
+		return nil, fmt.Errorf("failed to get pending earnings adjustments: %w", err)
+	}
+	return adjustments, nil
+}
+
+// DeletePendingAdjustment удаляет корректировку, еще не учтенную в закрытом периоде
+func (r *PayrollRepository) DeletePendingAdjustment(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM teacher_earnings_adjustments WHERE id = $1 AND period_id IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete earnings adjustment: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrEarningsAdjustmentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// PermissionRepository хранит роли, их права и отвечает на вопросы о владении объектами
+type PermissionRepository struct {
+	db *sqlx.DB
+}
+
+// NewPermissionRepository создает новый PermissionRepository
+func NewPermissionRepository(db *sqlx.DB) *PermissionRepository {
+	return &PermissionRepository{db: db}
+}
+
+// rolePermissionRow строка связи роли и права
+type rolePermissionRow struct {
+	Role       models.UserRole   `db:"role"`
+	Permission models.Permission `db:"permission"`
+}
+
+// LoadRolePermissions загружает права всех ролей
+func (r *PermissionRepository) LoadRolePermissions(ctx context.Context) (map[models.UserRole]models.PermissionSet, error) {
+	var rows []rolePermissionRow
+	if err := r.db.SelectContext(ctx, &rows, `SELECT role, permission FROM role_permissions`); err != nil {
+		return nil, fmt.Errorf("failed to load role permissions: %w", err)
+	}
+
+	var roles []models.UserRole
+	if err := r.db.SelectContext(ctx, &roles, `SELECT name FROM roles`); err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+
+	result := make(map[models.UserRole]models.PermissionSet, len(roles))
+	for _, role := range roles {
+		result[role] = models.NewPermissionSet()
+	}
+	for _, row := range rows {
+		if set, ok := result[row.Role]; ok {
+			set[row.Permission] = struct{}{}
+		}
+	}
+	return result, nil
+}
+
+// roleSelect выборка ролей с количеством активных пользователей
+const roleSelect = `
+	SELECT r.name, r.description, r.is_system, r.created_at, r.updated_at,
+		(SELECT COUNT(*) FROM users u WHERE u.role = r.name AND u.deleted_at IS NULL) AS users_count
+	FROM roles r
+`
+
+// ListRoles получает все роли с правами: сначала встроенные, затем пользовательские
+func (r *PermissionRepository) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	roles := []*models.Role{}
+	if err := r.db.SelectContext(ctx, &roles, roleSelect+` ORDER BY r.is_system DESC, r.name`); err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	perms, err := r.LoadRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		role.Permissions = perms[role.Name].List()
+	}
+	return roles, nil
+}
+
+// GetRole получает роль с правами
+func (r *PermissionRepository) GetRole(ctx context.Context, name models.UserRole) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.GetContext(ctx, &role, roleSelect+` WHERE r.name = $1`, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	role.Permissions = []models.Permission{}
+	if err := r.db.SelectContext(ctx, &role.Permissions,
+		`SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission`, name); err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	return &role, nil
+}
+
+// RoleExists проверяет существование роли
+func (r *PermissionRepository) RoleExists(ctx context.Context, name models.UserRole) (bool, error) {
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM roles WHERE name = $1)`, name); err != nil {
+		return false, fmt.Errorf("failed to check role: %w", err)
+	}
+	return exists, nil
+}
+
+// CreateRole создает пользовательскую роль с правами
+func (r *PermissionRepository) CreateRole(ctx context.Context, req *models.RoleRequest) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO roles (name, description, is_system) VALUES ($1, $2, false)`,
+		req.Name, req.Description); err != nil {
+		if IsUniqueViolationError(err) {
+			return ErrRoleExists
+		}
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+
+	if err := replaceRolePermissionsTx(ctx, tx, req.Name, req.Permissions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role: %w", err)
+	}
+	return nil
+}
+
+// UpdateRole заменяет описание и права роли
+func (r *PermissionRepository) UpdateRole(ctx context.Context, req *models.RoleRequest) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE roles SET description = $2, updated_at = CURRENT_TIMESTAMP WHERE name = $1`,
+		req.Name, req.Description)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	} else if rows == 0 {
+		return ErrRoleNotFound
+	}
+
+	if err := replaceRolePermissionsTx(ctx, tx, req.Name, req.Permissions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role: %w", err)
+	}
+	return nil
+}
+
+// replaceRolePermissionsTx заменяет набор прав роли
+func replaceRolePermissionsTx(ctx context.Context, tx *sqlx.Tx, role models.UserRole, perms []models.Permission) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role = $1`, role); err != nil {
+		return fmt.Errorf("failed to clear role permissions: %w", err)
+	}
+	if len(perms) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(perms))
+	for i, p := range perms {
+		names[i] = string(p)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO role_permissions (role, permission) SELECT $1, unnest($2::text[])`,
+		role, names); err != nil {
+		return fmt.Errorf("failed to save role permissions: %w", err)
+	}
+	return nil
+}
+
+// DeleteRole удаляет пользовательскую роль. Роль, назначенную пользователям, удалить нельзя
+func (r *PermissionRepository) DeleteRole(ctx context.Context, name models.UserRole) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE name = $1 AND NOT is_system`, name)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		// 23503 - foreign_key_violation: роль назначена пользователям (в том числе удаленным)
+		if errors.As(err, &pgErr) && pgErr.SQLState() == "23503" {
+			return ErrRoleInUse
+		}
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrRoleNotFound
+	}
+	return nil
+}
+
+// IsLessonTeacher проверяет, ведет ли пользователь занятие
+func (r *PermissionRepository) IsLessonTeacher(ctx context.Context, userID, lessonID uuid.UUID) (bool, error) {
+	var owner bool
+	err := r.db.GetContext(ctx, &owner,
+		`SELECT EXISTS(SELECT 1 FROM lessons WHERE id = $1 AND teacher_id = $2 AND deleted_at IS NULL)`,
+		lessonID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check lesson ownership: %w", err)
+	}
+	return owner, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QuarantineRepository управляет записями о загрузках, отклоненных антивирусной проверкой
+type QuarantineRepository struct {
+	db *sqlx.DB
+}
+
+// NewQuarantineRepository создает новый QuarantineRepository
+func NewQuarantineRepository(db *sqlx.DB) *QuarantineRepository {
+	return &QuarantineRepository{db: db}
+}
+
+// Create сохраняет запись о файле в карантине
+func (r *QuarantineRepository) Create(ctx context.Context, file *models.QuarantinedFile) error {
+	query := `
+		INSERT INTO upload_quarantine (
+			source, file_name, mime_type, file_size, content_hash, storage_key, signature, scanner, uploaded_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		file.Source, file.FileName, file.MimeType, file.FileSize, file.ContentHash,
+		file.StorageKey, file.Signature, file.Scanner, file.UploadedBy,
+	).Scan(&file.ID, &file.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create quarantine record: %w", err)
+	}
+	return nil
+}
+
+// List получает записи карантина, новые сверху, и общее количество записей
+func (r *QuarantineRepository) List(ctx context.Context, limit, offset int) ([]*models.QuarantinedFile, int, error) {
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM upload_quarantine`); err != nil {
+		return nil, 0, fmt.Errorf("failed to count quarantined files: %w", err)
+	}
+
+	query := `
+		SELECT id, source, file_name, mime_type, file_size, content_hash, storage_key,
+			signature, scanner, uploaded_by, created_at
+		FROM upload_quarantine
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	items := []*models.QuarantinedFile{}
+	if err := r.db.SelectContext(ctx, &items, query, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list quarantined files: %w", err)
+	}
+	return items, total, nil
+}
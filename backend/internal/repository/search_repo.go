@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// searchQueryCTE разбирает запрос пользователя ($1) в русской и английской конфигурациях.
+	// websearch_to_tsquery не падает на произвольном вводе (кавычки, "or", "-слово")
+	searchQueryCTE = `
+		WITH query AS (
+			SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS q
+		)`
+
+	// visibleRoomCondition - пользователь ($2) участник комнаты cr (личной или групповой)
+	visibleRoomCondition = `(cr.teacher_id = $2 OR cr.student_id = $2 OR EXISTS (
+		SELECT 1 FROM chat_room_participants p WHERE p.room_id = cr.id AND p.user_id = $2
+	))`
+)
+
+// searchHeadlineOptions параметры ts_headline: маркеры совпадений и размер фрагмента
+var searchHeadlineOptions = fmt.Sprintf(
+	`StartSel="%s", StopSel="%s", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`,
+	models.SearchHighlightStart, models.SearchHighlightStop,
+)
+
+// SearchRepository выполняет полнотекстовый поиск по сообщениям чатов, домашним заданиям и отчетам
+type SearchRepository struct {
+	db *sqlx.DB
+}
+
+// NewSearchRepository создает новый SearchRepository
+func NewSearchRepository(db *sqlx.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// SearchMessages ищет доставленные сообщения в комнатах, участником которых является пользователь.
+// Заголовок результата - название групповой комнаты или имя собеседника в личном чате
+func (r *SearchRepository) SearchMessages(ctx context.Context, userID uuid.UUID, text string, limit int) ([]*models.SearchResult, error) {
+	// Статус задан литералом, чтобы запрос использовал частичный индекс idx_messages_search
+	query := searchQueryCTE + `
+		SELECT 'messages' AS type, m.id, m.room_id, NULL::uuid AS lesson_id,
+			CASE WHEN cr.room_type = 'direct'
+				THEN COALESCE(NULLIF(TRIM(CONCAT(o.first_name, ' ', o.last_name)), ''), o.email, 'Чат')
+				ELSE COALESCE(cr.title, 'Чат')
+			END AS title,
+			ts_headline('russian', m.message_text, query.q, $3) AS snippet,
+			ts_rank(m.search_vector, query.q) AS rank,
+			m.created_at AS date
+		FROM messages m
+		CROSS JOIN query
+		JOIN chat_rooms cr ON cr.id = m.room_id AND cr.deleted_at IS NULL
+		LEFT JOIN users o ON o.id = CASE WHEN cr.teacher_id = $2 THEN cr.student_id ELSE cr.teacher_id END
+		WHERE m.search_vector @@ query.q
+		  AND m.deleted_at IS NULL AND m.status = 'delivered'
+		  AND ` + visibleRoomCondition + `
+		ORDER BY rank DESC, m.created_at DESC
+		LIMIT $4
+	`
+
+	results := []*models.SearchResult{}
+	if err := r.db.SelectContext(ctx, &results, query, text, userID, searchHeadlineOptions, limit); err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	return results, nil
+}
+
+// SearchHomework ищет по текстам домашних заданий (lesson_homework.text_content и lessons.homework_text)
+// в занятиях, видимых пользователю по правилам GetVisibleLessons
+func (r *SearchRepository) SearchHomework(ctx context.Context, userID uuid.UUID, userRole, text string, limit int) ([]*models.SearchResult, error) {
+	visible, ok := visibleLessonCondition(userRole)
+	if !ok {
+		return []*models.SearchResult{}, nil
+	}
+
+	query := searchQueryCTE + `
+		SELECT * FROM (
+			SELECT 'homework' AS type, lh.id, NULL::uuid AS room_id, l.id AS lesson_id,
+				COALESCE(l.subject, '') AS title,
+				ts_headline('russian', lh.text_content, query.q, $3) AS snippet,
+				ts_rank(lh.search_vector, query.q) AS rank,
+				l.start_time AS date
+			FROM lesson_homework lh
+			CROSS JOIN query
+			JOIN lessons l ON l.id = lh.lesson_id AND l.deleted_at IS NULL
+			WHERE lh.search_vector @@ query.q AND ` + visible + `
+
+			UNION ALL
+
+			SELECT 'homework' AS type, l.id, NULL::uuid AS room_id, l.id AS lesson_id,
+				COALESCE(l.subject, '') AS title,
+				ts_headline('russian', l.homework_text, query.q, $3) AS snippet,
+				ts_rank(l.homework_search_vector, query.q) AS rank,
+				l.start_time AS date
+			FROM lessons l
+			CROSS JOIN query
+			WHERE l.homework_search_vector @@ query.q
+			  AND l.deleted_at IS NULL AND l.homework_text IS NOT NULL
+			  AND ` + visible + `
+		) results
+		ORDER BY rank DESC, date DESC
+		LIMIT $4
+	`
+
+	results := []*models.SearchResult{}
+	if err := r.db.SelectContext(ctx, &results, query, text, userID, searchHeadlineOptions, limit); err != nil {
+		return nil, fmt.Errorf("failed to search homework: %w", err)
+	}
+	return results, nil
+}
+
+// SearchReports ищет по отчетам о занятиях (lessons.report_text), видимым пользователю
+func (r *SearchRepository) SearchReports(ctx context.Context, userID uuid.UUID, userRole, text string, limit int) ([]*models.SearchResult, error) {
+	visible, ok := visibleLessonCondition(userRole)
+	if !ok {
+		return []*models.SearchResult{}, nil
+	}
+
+	query := searchQueryCTE + `
+		SELECT 'reports' AS type, l.id, NULL::uuid AS room_id, l.id AS lesson_id,
+			COALESCE(l.subject, '') AS title,
+			ts_headline('russian', l.report_text, query.q, $3) AS snippet,
+			ts_rank(l.report_search_vector, query.q) AS rank,
+			l.start_time AS date
+		FROM lessons l
+		CROSS JOIN query
+		WHERE l.report_search_vector @@ query.q
+		  AND l.deleted_at IS NULL AND l.report_text IS NOT NULL
+		  AND ` + visible + `
+		ORDER BY rank DESC, l.start_time DESC
+		LIMIT $4
+	`
+
+	results := []*models.SearchResult{}
+	if err := r.db.SelectContext(ctx, &results, query, text, userID, searchHeadlineOptions, limit); err != nil {
+		return nil, fmt.Errorf("failed to search reports: %w", err)
+	}
+	return results, nil
+}
+
+// visibleLessonCondition возвращает условие видимости занятия l для пользователя $2.
+// Правила совпадают с LessonRepository.GetVisibleLessons: администратор видит все занятия,
+// преподаватель - свои, студент - групповые и индивидуальные, на которые записан.
+// Для остальных ролей занятия не видны (ok = false)
+func visibleLessonCondition(userRole string) (string, bool) {
+	switch userRole {
+	case string(models.RoleAdmin):
+		// $2 должен встречаться в запросе, иначе PostgreSQL не определит тип параметра
+		return `$2::uuid IS NOT NULL`, true
+	case string(models.RoleTeacher):
+		return `l.teacher_id = $2`, true
+	case string(models.RoleStudent):
+		return `(l.max_students > 1 OR EXISTS (
+			SELECT 1 FROM bookings b WHERE b.lesson_id = l.id AND b.student_id = $2
+		))`, true
+	default:
+		return "", false
+	}
+}
@@ -25,8 +25,8 @@ func NewSessionRepository(db *sqlx.DB) *SessionRepository {
 // Create создает новую сессию
 func (r *SessionRepository) Create(ctx context.Context, session *models.Session) error {
 	query := `
-		INSERT INTO sessions (id, user_id, created_at, expires_at, ip_address, user_agent)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO sessions (id, user_id, created_at, expires_at, ip_address, user_agent, impersonator_id, read_only)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	session.ID = uuid.New()
@@ -39,6 +39,8 @@ func (r *SessionRepository) Create(ctx context.Context, session *models.Session)
 		session.ExpiresAt,
 		session.IPAddress,
 		session.UserAgent,
+		session.ImpersonatorID,
+		session.ReadOnly,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
@@ -50,7 +52,7 @@ func (r *SessionRepository) Create(ctx context.Context, session *models.Session)
 // GetByID получает сессию по ID
 func (r *SessionRepository) GetByID(ctx context.Context, sessionID uuid.UUID) (*models.Session, error) {
 	query := `
-		SELECT id, user_id, created_at, expires_at, ip_address, user_agent
+		SELECT id, user_id, created_at, expires_at, ip_address, user_agent, impersonator_id, read_only
 		FROM sessions
 		WHERE id = $1
 	`
@@ -71,10 +73,12 @@ func (r *SessionRepository) GetByID(ctx context.Context, sessionID uuid.UUID) (*
 func (r *SessionRepository) GetWithUser(ctx context.Context, sessionID uuid.UUID) (*models.SessionWithUser, error) {
 	query := `
 		SELECT
-			s.id, s.user_id, s.created_at, s.expires_at, s.ip_address, s.user_agent,
-			u.email as user_email, COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email) as user_name, u.role as user_role
+			s.id, s.user_id, s.created_at, s.expires_at, s.ip_address, s.user_agent, s.impersonator_id, s.read_only,
+			u.email as user_email, COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email) as user_name, u.role as user_role,
+			COALESCE(NULLIF(TRIM(CONCAT(imp.first_name, ' ', imp.last_name)), ''), imp.email, '') as impersonator_name
 		FROM sessions s
 		JOIN users u ON s.user_id = u.id
+		LEFT JOIN users imp ON s.impersonator_id = imp.id
 		WHERE s.id = $1 AND u.deleted_at IS NULL
 	`
 
@@ -137,7 +141,7 @@ func (r *SessionRepository) DeleteExpired(ctx context.Context) error {
 // ListByUserID получает все активные сессии пользователя
 func (r *SessionRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
 	query := `
-		SELECT id, user_id, created_at, expires_at, ip_address, user_agent
+		SELECT id, user_id, created_at, expires_at, ip_address, user_agent, impersonator_id, read_only
 		FROM sessions
 		WHERE user_id = $1 AND expires_at > $2
 		ORDER BY created_at DESC
@@ -44,13 +44,16 @@ const (
 
 	// ChatRoomSelectFields - поля таблицы chat_rooms
 	ChatRoomSelectFields = `
-		id, teacher_id, student_id, last_message_at,
+		id, room_type, COALESCE(teacher_id, '00000000-0000-0000-0000-000000000000'::uuid),
+		COALESCE(student_id, '00000000-0000-0000-0000-000000000000'::uuid),
+		title, description, recurring_group_id, student_group_id, last_message_at,
 		created_at, updated_at, deleted_at
 	`
 
 	// FileAttachmentSelectFields - поля таблицы file_attachments
 	FileAttachmentSelectFields = `
-		id, message_id, file_name, file_path, file_size, mime_type, uploaded_at
+		id, message_id, file_name, file_path, file_size, mime_type, uploaded_at,
+		storage_key, content_hash, thumbnail_key
 	`
 
 	// SwapSelectFields - поля таблицы swaps
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// StorageQuotaRepository считает использование хранилища файлов и управляет индивидуальными квотами пользователей
+type StorageQuotaRepository struct {
+	db *sqlx.DB
+}
+
+// NewStorageQuotaRepository создает новый StorageQuotaRepository
+func NewStorageQuotaRepository(db *sqlx.DB) *StorageQuotaRepository {
+	return &StorageQuotaRepository{db: db}
+}
+
+// storageUsageColumns колонка, по которой группируется отчет об использовании хранилища
+var storageUsageColumns = map[string]string{
+	models.StorageUsageByUser:    "uploaded_by",
+	models.StorageUsageByTeacher: "teacher_id",
+}
+
+// GetUsage возвращает суммарный размер файлов, загруженных пользователем, записи о которых не удалены
+func (r *StorageQuotaRepository) GetUsage(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(f.file_size), 0)
+		FROM (` + liveStoredFilesQuery + `) f
+		WHERE f.uploaded_by = $2
+	`
+
+	var used int64
+	if err := r.db.GetContext(ctx, &used, query, time.Now(), userID); err != nil {
+		return 0, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+	return used, nil
+}
+
+// ListUsage получает использование хранилища по пользователям или преподавателям, больше всего места сверху,
+// и общее количество строк отчета
+func (r *StorageQuotaRepository) ListUsage(ctx context.Context, groupBy string, limit, offset int) ([]*models.StorageUsage, int, error) {
+	column, ok := storageUsageColumns[groupBy]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown storage usage grouping: %s", groupBy)
+	}
+	now := time.Now()
+
+	countQuery := `
+		SELECT COUNT(DISTINCT f.` + column + `)
+		FROM (` + liveStoredFilesQuery + `) f
+	`
+	var total int
+	if err := r.db.GetContext(ctx, &total, countQuery, now); err != nil {
+		return nil, 0, fmt.Errorf("failed to count storage usage: %w", err)
+	}
+
+	query := `
+		SELECT u.id AS user_id, u.first_name, u.last_name, u.role,
+			COUNT(*) AS file_count, SUM(f.file_size) AS total_bytes, q.quota_bytes
+		FROM (` + liveStoredFilesQuery + `) f
+		JOIN users u ON u.id = f.` + column + `
+		LEFT JOIN user_storage_quotas q ON q.user_id = u.id
+		GROUP BY u.id, q.quota_bytes
+		ORDER BY total_bytes DESC, u.id
+		LIMIT $2 OFFSET $3
+	`
+
+	items := []*models.StorageUsage{}
+	if err := r.db.SelectContext(ctx, &items, query, now, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list storage usage: %w", err)
+	}
+	return items, total, nil
+}
+
+// GetQuota возвращает индивидуальную квоту пользователя; Valid = false - квота не задана
+func (r *StorageQuotaRepository) GetQuota(ctx context.Context, userID uuid.UUID) (sql.NullInt64, error) {
+	var quota sql.NullInt64
+	err := r.db.GetContext(ctx, &quota, `SELECT quota_bytes FROM user_storage_quotas WHERE user_id = $1`, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sql.NullInt64{}, nil
+	}
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("failed to get storage quota: %w", err)
+	}
+	return quota, nil
+}
+
+// SetQuota задает или меняет индивидуальную квоту пользователя
+func (r *StorageQuotaRepository) SetQuota(ctx context.Context, userID uuid.UUID, quotaBytes int64, updatedBy uuid.UUID) error {
+	query := `
+		INSERT INTO user_storage_quotas (user_id, quota_bytes, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET quota_bytes = EXCLUDED.quota_bytes, updated_by = EXCLUDED.updated_by, updated_at = CURRENT_TIMESTAMP
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, quotaBytes, updatedBy); err != nil {
+		var pgErr *pgconn.PgError
+		// 23503 - foreign_key_violation: пользователя не существует
+		if errors.As(err, &pgErr) && pgErr.SQLState() == "23503" {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to set storage quota: %w", err)
+	}
+	return nil
+}
+
+// DeleteQuota удаляет индивидуальную квоту пользователя; отсутствие квоты не считается ошибкой
+func (r *StorageQuotaRepository) DeleteQuota(ctx context.Context, userID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM user_storage_quotas WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete storage quota: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,606 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+const subscriptionPlanColumns = `
+	id, name, description, credits_per_period, price, period_months, is_active, created_at, updated_at
+`
+
+const subscriptionColumns = `
+	s.id, s.user_id, s.plan_id, s.pending_plan_id, s.status, s.payment_method_id,
+	s.current_period_start, s.current_period_end, s.next_billing_at,
+	s.cancel_at_period_end, s.cancelled_at, s.failed_attempts, s.last_failure_reason,
+	s.created_at, s.updated_at
+`
+
+// subscriptionWithPlanQuery выбирает подписки с параметрами текущего тарифа и email пользователя
+const subscriptionWithPlanQuery = `
+	SELECT ` + subscriptionColumns + `,
+		p.name AS plan_name, p.credits_per_period, p.price,
+		u.email AS user_email,
+		(s.payment_method_id IS NOT NULL) AS has_payment_method
+	FROM subscriptions s
+	JOIN subscription_plans p ON p.id = s.plan_id
+	JOIN users u ON u.id = s.user_id
+`
+
+const subscriptionInvoiceColumns = `
+	id, subscription_id, user_id, plan_id, kind, period_start, period_end, amount, credits,
+	status, attempt, yookassa_payment_id, confirmation_url, failure_reason, processed_at,
+	created_at, updated_at
+`
+
+// SubscriptionRepository управляет тарифами, подписками и счетами подписок
+type SubscriptionRepository struct {
+	db *sqlx.DB
+}
+
+// NewSubscriptionRepository создает новый SubscriptionRepository
+func NewSubscriptionRepository(db *sqlx.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db}
+}
+
+// CreatePlan создает новый тариф
+func (r *SubscriptionRepository) CreatePlan(ctx context.Context, plan *models.SubscriptionPlan) error {
+	query := `
+		INSERT INTO subscription_plans (id, name, description, credits_per_period, price, period_months, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	if plan.ID == uuid.Nil {
+		plan.ID = uuid.New()
+	}
+	plan.CreatedAt = time.Now()
+	plan.UpdatedAt = plan.CreatedAt
+
+	_, err := r.db.ExecContext(ctx, query,
+		plan.ID,
+		plan.Name,
+		plan.Description,
+		plan.CreditsPerPeriod,
+		plan.Price,
+		plan.PeriodMonths,
+		plan.IsActive,
+		plan.CreatedAt,
+		plan.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create subscription plan: %w", err)
+	}
+
+	return nil
+}
+
+// GetPlanByID получает тариф по ID
+func (r *SubscriptionRepository) GetPlanByID(ctx context.Context, id uuid.UUID) (*models.SubscriptionPlan, error) {
+	query := `SELECT ` + subscriptionPlanColumns + ` FROM subscription_plans WHERE id = $1`
+
+	var plan models.SubscriptionPlan
+	if err := r.db.GetContext(ctx, &plan, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSubscriptionPlanNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription plan: %w", err)
+	}
+
+	return &plan, nil
+}
+
+// GetPlanByIDTx получает тариф по ID в рамках транзакции
+func (r *SubscriptionRepository) GetPlanByIDTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.SubscriptionPlan, error) {
+	query := `SELECT ` + subscriptionPlanColumns + ` FROM subscription_plans WHERE id = $1`
+
+	var plan models.SubscriptionPlan
+	err := tx.QueryRow(ctx, query, id).Scan(
+		&plan.ID,
+		&plan.Name,
+		&plan.Description,
+		&plan.CreditsPerPeriod,
+		&plan.Price,
+		&plan.PeriodMonths,
+		&plan.IsActive,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrSubscriptionPlanNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription plan: %w", err)
+	}
+
+	return &plan, nil
+}
+
+// ListPlans получает список тарифов (activeOnly - только доступные для оформления)
+func (r *SubscriptionRepository) ListPlans(ctx context.Context, activeOnly bool) ([]*models.SubscriptionPlan, error) {
+	query := `SELECT ` + subscriptionPlanColumns + ` FROM subscription_plans`
+	if activeOnly {
+		query += ` WHERE is_active = TRUE`
+	}
+	query += ` ORDER BY price ASC, name ASC`
+
+	plans := []*models.SubscriptionPlan{}
+	if err := r.db.SelectContext(ctx, &plans, query); err != nil {
+		return nil, fmt.Errorf("failed to list subscription plans: %w", err)
+	}
+
+	return plans, nil
+}
+
+// UpdatePlan обновляет тариф
+func (r *SubscriptionRepository) UpdatePlan(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	updates["updated_at"] = time.Now()
+
+	query := "UPDATE subscription_plans SET "
+	args := []interface{}{}
+	paramCount := 1
+
+	for key, value := range updates {
+		if key == "id" || key == "created_at" {
+			continue
+		}
+		if paramCount > 1 {
+			query += ", "
+		}
+		query += fmt.Sprintf("%s = $%d", key, paramCount)
+		args = append(args, value)
+		paramCount++
+	}
+
+	query += fmt.Sprintf(" WHERE id = $%d", paramCount)
+	args = append(args, id)
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription plan: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrSubscriptionPlanNotFound
+	}
+
+	return nil
+}
+
+// CreateWithInvoiceTx создает подписку и её первый счёт в рамках транзакции
+func (r *SubscriptionRepository) CreateWithInvoiceTx(ctx context.Context, tx pgx.Tx, sub *models.Subscription, invoice *models.SubscriptionInvoice) error {
+	query := `
+		INSERT INTO subscriptions (id, user_id, plan_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	if sub.ID == uuid.Nil {
+		sub.ID = uuid.New()
+	}
+	sub.CreatedAt = time.Now()
+	sub.UpdatedAt = sub.CreatedAt
+
+	if _, err := tx.Exec(ctx, query, sub.ID, sub.UserID, sub.PlanID, sub.Status, sub.CreatedAt, sub.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	invoice.SubscriptionID = sub.ID
+	return r.CreateInvoiceTx(ctx, tx, invoice)
+}
+
+// GetLiveByUser получает незавершённую подписку пользователя (pending, active, past_due)
+func (r *SubscriptionRepository) GetLiveByUser(ctx context.Context, userID uuid.UUID) (*models.SubscriptionWithPlan, error) {
+	query := subscriptionWithPlanQuery + `
+		WHERE s.user_id = $1 AND s.status IN ('pending', 'active', 'past_due')
+	`
+
+	var sub models.SubscriptionWithPlan
+	if err := r.db.GetContext(ctx, &sub, query, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get user subscription: %w", err)
+	}
+
+	return &sub, nil
+}
+
+// GetByID получает подписку с параметрами тарифа
+func (r *SubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SubscriptionWithPlan, error) {
+	query := subscriptionWithPlanQuery + `WHERE s.id = $1`
+
+	var sub models.SubscriptionWithPlan
+	if err := r.db.GetContext(ctx, &sub, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	return &sub, nil
+}
+
+// List получает список подписок (status != nil - только с указанным статусом)
+func (r *SubscriptionRepository) List(ctx context.Context, status *models.SubscriptionStatus) ([]*models.SubscriptionWithPlan, error) {
+	query := subscriptionWithPlanQuery
+	args := []interface{}{}
+	if status != nil {
+		query += ` WHERE s.status = $1`
+		args = append(args, *status)
+	}
+	query += ` ORDER BY s.created_at DESC`
+
+	subs := []*models.SubscriptionWithPlan{}
+	if err := r.db.SelectContext(ctx, &subs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// GetByIDForUpdate получает подписку с блокировкой строки, сериализуя биллинг и действия пользователя
+func (r *SubscriptionRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions s WHERE s.id = $1 FOR UPDATE`
+
+	var sub models.Subscription
+	err := tx.QueryRow(ctx, query, id).Scan(
+		&sub.ID,
+		&sub.UserID,
+		&sub.PlanID,
+		&sub.PendingPlanID,
+		&sub.Status,
+		&sub.PaymentMethodID,
+		&sub.CurrentPeriodStart,
+		&sub.CurrentPeriodEnd,
+		&sub.NextBillingAt,
+		&sub.CancelAtPeriodEnd,
+		&sub.CancelledAt,
+		&sub.FailedAttempts,
+		&sub.LastFailureReason,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription for update: %w", err)
+	}
+
+	return &sub, nil
+}
+
+// UpdateTx обновляет подписку в рамках транзакции
+func (r *SubscriptionRepository) UpdateTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	updates["updated_at"] = time.Now()
+
+	query := "UPDATE subscriptions SET "
+	args := []interface{}{}
+	paramCount := 1
+
+	for key, value := range updates {
+		if key == "id" || key == "created_at" {
+			continue
+		}
+		if paramCount > 1 {
+			query += ", "
+		}
+		query += fmt.Sprintf("%s = $%d", key, paramCount)
+		args = append(args, value)
+		paramCount++
+	}
+
+	query += fmt.Sprintf(" WHERE id = $%d", paramCount)
+	args = append(args, id)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// ListDueForBilling получает ID подписок, по которым наступил срок списания
+// Подписки с незавершённым счётом пропускаются - по ним ожидается ответ YooKassa
+func (r *SubscriptionRepository) ListDueForBilling(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT s.id
+		FROM subscriptions s
+		WHERE s.status IN ('active', 'past_due')
+			AND s.cancel_at_period_end = FALSE
+			AND s.next_billing_at <= $1
+			AND NOT EXISTS (
+				SELECT 1 FROM subscription_invoices i
+				WHERE i.subscription_id = s.id AND i.status = 'pending'
+			)
+		ORDER BY s.next_billing_at ASC
+		LIMIT $2
+	`
+
+	ids := []uuid.UUID{}
+	if err := r.db.SelectContext(ctx, &ids, query, now, limit); err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions due for billing: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListEndingAtPeriodEnd получает ID подписок, отменённых на конец периода, у которых период истёк
+func (r *SubscriptionRepository) ListEndingAtPeriodEnd(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT id
+		FROM subscriptions
+		WHERE status IN ('active', 'past_due')
+			AND cancel_at_period_end = TRUE
+			AND current_period_end <= $1
+		ORDER BY current_period_end ASC
+		LIMIT $2
+	`
+
+	ids := []uuid.UUID{}
+	if err := r.db.SelectContext(ctx, &ids, query, now, limit); err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions ending at period end: %w", err)
+	}
+
+	return ids, nil
+}
+
+// CreateInvoiceTx создает счёт подписки в рамках транзакции
+func (r *SubscriptionRepository) CreateInvoiceTx(ctx context.Context, tx pgx.Tx, invoice *models.SubscriptionInvoice) error {
+	query := `
+		INSERT INTO subscription_invoices (id, subscription_id, user_id, plan_id, kind, period_start, period_end,
+			amount, credits, status, attempt, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	if invoice.ID == uuid.Nil {
+		invoice.ID = uuid.New()
+	}
+	if invoice.Status == "" {
+		invoice.Status = models.SubscriptionInvoicePending
+	}
+	if invoice.Attempt == 0 {
+		invoice.Attempt = 1
+	}
+	invoice.CreatedAt = time.Now()
+	invoice.UpdatedAt = invoice.CreatedAt
+
+	_, err := tx.Exec(ctx, query,
+		invoice.ID,
+		invoice.SubscriptionID,
+		invoice.UserID,
+		invoice.PlanID,
+		invoice.Kind,
+		invoice.PeriodStart,
+		invoice.PeriodEnd,
+		invoice.Amount,
+		invoice.Credits,
+		invoice.Status,
+		invoice.Attempt,
+		invoice.CreatedAt,
+		invoice.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create subscription invoice: %w", err)
+	}
+
+	return nil
+}
+
+// GetInvoiceByID получает счёт подписки по ID
+func (r *SubscriptionRepository) GetInvoiceByID(ctx context.Context, id uuid.UUID) (*models.SubscriptionInvoice, error) {
+	query := `SELECT ` + subscriptionInvoiceColumns + ` FROM subscription_invoices WHERE id = $1`
+
+	var invoice models.SubscriptionInvoice
+	if err := r.db.GetContext(ctx, &invoice, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSubscriptionInvoiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription invoice: %w", err)
+	}
+
+	return &invoice, nil
+}
+
+// GetInvoiceForUpdate получает счёт подписки с блокировкой строки
+func (r *SubscriptionRepository) GetInvoiceForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.SubscriptionInvoice, error) {
+	query := `SELECT ` + subscriptionInvoiceColumns + ` FROM subscription_invoices WHERE id = $1 FOR UPDATE`
+
+	var invoice models.SubscriptionInvoice
+	err := tx.QueryRow(ctx, query, id).Scan(
+		&invoice.ID,
+		&invoice.SubscriptionID,
+		&invoice.UserID,
+		&invoice.PlanID,
+		&invoice.Kind,
+		&invoice.PeriodStart,
+		&invoice.PeriodEnd,
+		&invoice.Amount,
+		&invoice.Credits,
+		&invoice.Status,
+		&invoice.Attempt,
+		&invoice.YooKassaPaymentID,
+		&invoice.ConfirmationURL,
+		&invoice.FailureReason,
+		&invoice.ProcessedAt,
+		&invoice.CreatedAt,
+		&invoice.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrSubscriptionInvoiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription invoice for update: %w", err)
+	}
+
+	return &invoice, nil
+}
+
+// GetLastInvoiceTx получает последний счёт подписки в рамках транзакции
+func (r *SubscriptionRepository) GetLastInvoiceTx(ctx context.Context, tx pgx.Tx, subscriptionID uuid.UUID) (*models.SubscriptionInvoice, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM subscription_invoices
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, subscriptionID).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrSubscriptionInvoiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get last subscription invoice: %w", err)
+	}
+
+	return r.GetInvoiceForUpdate(ctx, tx, id)
+}
+
+// HasPendingInvoiceTx проверяет наличие неоплаченного счёта по подписке
+func (r *SubscriptionRepository) HasPendingInvoiceTx(ctx context.Context, tx pgx.Tx, subscriptionID uuid.UUID) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM subscription_invoices WHERE subscription_id = $1 AND status = 'pending')
+	`, subscriptionID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pending subscription invoice: %w", err)
+	}
+
+	return exists, nil
+}
+
+// SetInvoicePayment сохраняет ID платежа YooKassa и URL подтверждения для счёта
+func (r *SubscriptionRepository) SetInvoicePayment(ctx context.Context, id uuid.UUID, yookassaPaymentID, confirmationURL string) error {
+	query := `
+		UPDATE subscription_invoices
+		SET yookassa_payment_id = $1, confirmation_url = NULLIF($2, ''), updated_at = $3
+		WHERE id = $4
+	`
+
+	result, err := r.db.ExecContext(ctx, query, yookassaPaymentID, confirmationURL, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to set subscription invoice payment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrSubscriptionInvoiceNotFound
+	}
+
+	return nil
+}
+
+// UpdateInvoiceTx обновляет счёт подписки в рамках транзакции
+func (r *SubscriptionRepository) UpdateInvoiceTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	updates["updated_at"] = time.Now()
+
+	query := "UPDATE subscription_invoices SET "
+	args := []interface{}{}
+	paramCount := 1
+
+	for key, value := range updates {
+		if key == "id" || key == "created_at" {
+			continue
+		}
+		if paramCount > 1 {
+			query += ", "
+		}
+		query += fmt.Sprintf("%s = $%d", key, paramCount)
+		args = append(args, value)
+		paramCount++
+	}
+
+	query += fmt.Sprintf(" WHERE id = $%d", paramCount)
+	args = append(args, id)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription invoice: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrSubscriptionInvoiceNotFound
+	}
+
+	return nil
+}
+
+// ListInvoices получает счета подписки, новые первыми
+func (r *SubscriptionRepository) ListInvoices(ctx context.Context, subscriptionID uuid.UUID) ([]*models.SubscriptionInvoice, error) {
+	query := `
+		SELECT ` + subscriptionInvoiceColumns + `
+		FROM subscription_invoices
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+	`
+
+	invoices := []*models.SubscriptionInvoice{}
+	if err := r.db.SelectContext(ctx, &invoices, query, subscriptionID); err != nil {
+		return nil, fmt.Errorf("failed to list subscription invoices: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// ListStalePendingInvoices получает неоплаченные автоматические счета, не обновлявшиеся с момента olderThan:
+// либо не отправленные в YooKassa (сбой сети), либо оставшиеся без webhook
+func (r *SubscriptionRepository) ListStalePendingInvoices(ctx context.Context, olderThan time.Time, limit int) ([]*models.SubscriptionInvoice, error) {
+	query := `
+		SELECT ` + subscriptionInvoiceColumns + `
+		FROM subscription_invoices
+		WHERE status = 'pending'
+			AND kind IN ('renewal', 'proration')
+			AND updated_at <= $1
+		ORDER BY updated_at ASC
+		LIMIT $2
+	`
+
+	invoices := []*models.SubscriptionInvoice{}
+	if err := r.db.SelectContext(ctx, &invoices, query, olderThan, limit); err != nil {
+		return nil, fmt.Errorf("failed to list stale subscription invoices: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// GetInvoiceIDByYooKassaID получает ID счёта подписки по ID платежа YooKassa
+func (r *SubscriptionRepository) GetInvoiceIDByYooKassaID(ctx context.Context, yookassaPaymentID string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.GetContext(ctx, &id, `SELECT id FROM subscription_invoices WHERE yookassa_payment_id = $1`, yookassaPaymentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, ErrSubscriptionInvoiceNotFound
+		}
+		return uuid.Nil, fmt.Errorf("failed to get subscription invoice by YooKassa ID: %w", err)
+	}
+
+	return id, nil
+}
@@ -2,21 +2,43 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"tutoring-platform/internal/models"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jmoiron/sqlx"
 )
 
+// trialRequestFields колонки заявки, включая поля CRM воронки
+const trialRequestFields = `
+	id, name, phone, telegram, email, created_at, status, assigned_to, follow_up_at, follow_up_reminded_at,
+	lost_reason, converted_user_id, converted_lesson_id, converted_booking_id, converted_at, updated_at
+`
+
+// trialRequestWithAssigneeQuery выбирает заявки с именем ответственного администратора
+const trialRequestWithAssigneeQuery = `
+	SELECT tr.id, tr.name, tr.phone, tr.telegram, tr.email, tr.created_at, tr.status, tr.assigned_to,
+		NULLIF(CONCAT(a.first_name, ' ', a.last_name), ' ') AS assigned_to_name,
+		tr.follow_up_at, tr.follow_up_reminded_at, tr.lost_reason, tr.converted_user_id,
+		tr.converted_lesson_id, tr.converted_booking_id, tr.converted_at, tr.updated_at
+	FROM trial_requests tr
+	LEFT JOIN users a ON a.id = tr.assigned_to
+`
+
 // TrialRequestRepository управляет операциями с базой данных для заявок на пробное занятие
 type TrialRequestRepository struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	users UserRepository
 }
 
-// NewTrialRequestRepository создает новый TrialRequestRepository
-func NewTrialRequestRepository(db *sqlx.DB) *TrialRequestRepository {
-	return &TrialRequestRepository{db: db}
+// NewTrialRequestRepository создает новый TrialRequestRepository; users создает студента при конвертации заявки
+func NewTrialRequestRepository(db *sqlx.DB, users UserRepository) *TrialRequestRepository {
+	return &TrialRequestRepository{db: db, users: users}
 }
 
 // Create создает новую заявку на пробное занятие
@@ -24,22 +46,14 @@ func (r *TrialRequestRepository) Create(ctx context.Context, input *models.Creat
 	query := `
 		INSERT INTO trial_requests (name, phone, telegram, email)
 		VALUES ($1, $2, $3, $4)
-		RETURNING id, name, phone, telegram, email, created_at
-	`
+		RETURNING ` + trialRequestFields
 
 	var trialRequest models.TrialRequest
-	err := r.db.QueryRowContext(ctx, query,
+	err := r.db.GetContext(ctx, &trialRequest, query,
 		input.Name,
 		input.Phone,
 		input.Telegram,
 		input.Email,
-	).Scan(
-		&trialRequest.ID,
-		&trialRequest.Name,
-		&trialRequest.Phone,
-		&trialRequest.Telegram,
-		&trialRequest.Email,
-		&trialRequest.CreatedAt,
 	)
 
 	if err != nil {
@@ -51,14 +65,34 @@ func (r *TrialRequestRepository) Create(ctx context.Context, input *models.Creat
 
 // GetAll получает все заявки на пробное занятие, упорядоченные по дате создания (новые первыми)
 func (r *TrialRequestRepository) GetAll(ctx context.Context) ([]*models.TrialRequest, error) {
-	query := `
-		SELECT id, name, phone, telegram, email, created_at
-		FROM trial_requests
-		ORDER BY created_at DESC
-	`
+	return r.List(ctx, models.TrialRequestFilter{})
+}
+
+// List получает заявки по фильтру статуса и ответственного (новые первыми)
+func (r *TrialRequestRepository) List(ctx context.Context, filter models.TrialRequestFilter) ([]*models.TrialRequest, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		conditions = append(conditions, fmt.Sprintf("tr.status = $%d", len(args)))
+	}
+	if filter.AssignedTo != nil {
+		args = append(args, *filter.AssignedTo)
+		conditions = append(conditions, fmt.Sprintf("tr.assigned_to = $%d", len(args)))
+	} else if filter.Unassigned {
+		conditions = append(conditions, "tr.assigned_to IS NULL")
+	}
 
-	var requests []*models.TrialRequest
-	err := r.db.SelectContext(ctx, &requests, query)
+	query := trialRequestWithAssigneeQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY tr.created_at DESC"
+
+	requests := []*models.TrialRequest{}
+	err := r.db.SelectContext(ctx, &requests, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get trial requests: %w", err)
 	}
@@ -68,17 +102,183 @@ func (r *TrialRequestRepository) GetAll(ctx context.Context) ([]*models.TrialReq
 
 // GetByID получает заявку на пробное занятие по ID
 func (r *TrialRequestRepository) GetByID(ctx context.Context, id int64) (*models.TrialRequest, error) {
-	query := `
-		SELECT id, name, phone, telegram, email, created_at
-		FROM trial_requests
-		WHERE id = $1
-	`
+	query := trialRequestWithAssigneeQuery + `WHERE tr.id = $1`
 
 	var trialRequest models.TrialRequest
 	err := r.db.GetContext(ctx, &trialRequest, query, id)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTrialRequestNotFound
+		}
 		return nil, fmt.Errorf("failed to get trial request by ID: %w", err)
 	}
 
 	return &trialRequest, nil
 }
+
+// GetByIDForUpdate получает заявку с блокировкой строки, сериализуя изменения воронки
+func (r *TrialRequestRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id int64) (*models.TrialRequest, error) {
+	query := `SELECT ` + trialRequestFields + ` FROM trial_requests WHERE id = $1 FOR UPDATE`
+
+	var tr models.TrialRequest
+	err := tx.QueryRow(ctx, query, id).Scan(
+		&tr.ID,
+		&tr.Name,
+		&tr.Phone,
+		&tr.Telegram,
+		&tr.Email,
+		&tr.CreatedAt,
+		&tr.Status,
+		&tr.AssignedTo,
+		&tr.FollowUpAt,
+		&tr.FollowUpRemindedAt,
+		&tr.LostReason,
+		&tr.ConvertedUserID,
+		&tr.ConvertedLessonID,
+		&tr.ConvertedBookingID,
+		&tr.ConvertedAt,
+		&tr.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrTrialRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get trial request for update: %w", err)
+	}
+
+	return &tr, nil
+}
+
+// UpdatePipelineTx сохраняет статус, ответственного, срок следующего контакта и причину потери заявки
+func (r *TrialRequestRepository) UpdatePipelineTx(ctx context.Context, tx pgx.Tx, tr *models.TrialRequest) error {
+	query := `
+		UPDATE trial_requests
+		SET status = $2, assigned_to = $3, follow_up_at = $4, follow_up_reminded_at = $5, lost_reason = $6
+		WHERE id = $1
+	`
+
+	result, err := tx.Exec(ctx, query,
+		tr.ID,
+		tr.Status,
+		tr.AssignedTo,
+		tr.FollowUpAt,
+		tr.FollowUpRemindedAt,
+		tr.LostReason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update trial request: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTrialRequestNotFound
+	}
+
+	return nil
+}
+
+// MarkConvertedTx переводит заявку в converted и сохраняет ссылки на созданные студента, занятие и запись
+func (r *TrialRequestRepository) MarkConvertedTx(ctx context.Context, tx pgx.Tx, id int64, userID, lessonID, bookingID uuid.UUID, convertedAt time.Time) error {
+	query := `
+		UPDATE trial_requests
+		SET status = 'converted', converted_user_id = $2, converted_lesson_id = $3, converted_booking_id = $4,
+			converted_at = $5, follow_up_at = NULL, follow_up_reminded_at = NULL
+		WHERE id = $1
+	`
+
+	result, err := tx.Exec(ctx, query, id, userID, lessonID, bookingID, convertedAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark trial request converted: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTrialRequestNotFound
+	}
+
+	return nil
+}
+
+// CreateStudentTx создает аккаунт студента в транзакции конвертации.
+// Кредитный счет студента создает триггер БД
+func (r *TrialRequestRepository) CreateStudentTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	user.Role = models.RoleStudent
+	return r.users.CreateTx(ctx, tx, user)
+}
+
+// AddActivityTx записывает событие в журнал активности заявки
+func (r *TrialRequestRepository) AddActivityTx(ctx context.Context, tx pgx.Tx, activity *models.TrialRequestActivity) error {
+	query := `
+		INSERT INTO trial_request_activities (id, trial_request_id, actor_id, kind, body, from_status, to_status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	activity.ID = uuid.New()
+	activity.CreatedAt = time.Now()
+
+	_, err := tx.Exec(ctx, query,
+		activity.ID,
+		activity.TrialRequestID,
+		activity.ActorID,
+		activity.Kind,
+		activity.Body,
+		activity.FromStatus,
+		activity.ToStatus,
+		activity.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add trial request activity: %w", err)
+	}
+
+	return nil
+}
+
+// ListActivities получает журнал активности заявки (новые записи первыми)
+func (r *TrialRequestRepository) ListActivities(ctx context.Context, trialRequestID int64) ([]*models.TrialRequestActivity, error) {
+	query := `
+		SELECT a.id, a.trial_request_id, a.actor_id,
+			NULLIF(CONCAT(u.first_name, ' ', u.last_name), ' ') AS actor_name,
+			a.kind, a.body, a.from_status, a.to_status, a.created_at
+		FROM trial_request_activities a
+		LEFT JOIN users u ON u.id = a.actor_id
+		WHERE a.trial_request_id = $1
+		ORDER BY a.created_at DESC
+	`
+
+	activities := []*models.TrialRequestActivity{}
+	if err := r.db.SelectContext(ctx, &activities, query, trialRequestID); err != nil {
+		return nil, fmt.Errorf("failed to list trial request activities: %w", err)
+	}
+
+	return activities, nil
+}
+
+// GetDueFollowUps получает открытые заявки, срок контакта по которым наступил, а напоминание еще не отправлено
+func (r *TrialRequestRepository) GetDueFollowUps(ctx context.Context, now time.Time, limit int) ([]*models.TrialRequest, error) {
+	query := trialRequestWithAssigneeQuery + `
+		WHERE tr.follow_up_at IS NOT NULL AND tr.follow_up_at <= $1
+			AND tr.follow_up_reminded_at IS NULL
+			AND tr.status NOT IN ('converted', 'lost')
+		ORDER BY tr.follow_up_at
+		LIMIT $2
+	`
+
+	requests := []*models.TrialRequest{}
+	if err := r.db.SelectContext(ctx, &requests, query, now, limit); err != nil {
+		return nil, fmt.Errorf("failed to get due trial request follow-ups: %w", err)
+	}
+
+	return requests, nil
+}
+
+// MarkFollowUpReminded отмечает отправку напоминания. Условие на follow_up_at не дает
+// пометить напоминание, если срок успели перенести во время отправки
+func (r *TrialRequestRepository) MarkFollowUpReminded(ctx context.Context, id int64, followUpAt time.Time, remindedAt time.Time) error {
+	query := `
+		UPDATE trial_requests
+		SET follow_up_reminded_at = $3
+		WHERE id = $1 AND follow_up_at = $2 AND follow_up_reminded_at IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, followUpAt, remindedAt); err != nil {
+		return fmt.Errorf("failed to mark trial request follow-up reminded: %w", err)
+	}
+
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"tutoring-platform/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -17,6 +18,7 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	Create(ctx context.Context, user *models.User) error
+	CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error
 	Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error
 	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -39,18 +41,19 @@ func NewUserRepository(db *sqlx.DB) UserRepository {
 	return &UserRepo{db: db}
 }
 
-// Create создает нового пользователя
-func (r *UserRepo) Create(ctx context.Context, user *models.User) error {
-	query := `
-		INSERT INTO users (id, email, password_hash, first_name, last_name, role, payment_enabled, telegram_username, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	`
+// createUserQuery вставка нового пользователя; аргументы - createUserArgs
+const createUserQuery = `
+	INSERT INTO users (id, email, password_hash, first_name, last_name, role, payment_enabled, telegram_username, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
 
+// createUserArgs назначает новому пользователю ID и время создания и возвращает аргументы createUserQuery
+func createUserArgs(user *models.User) []interface{} {
 	user.ID = uuid.New()
 	user.CreatedAt = time.Now()
-	user.UpdatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
 
-	_, err := r.db.ExecContext(ctx, query,
+	return []interface{}{
 		user.ID,
 		user.Email,
 		user.PasswordHash,
@@ -61,8 +64,25 @@ func (r *UserRepo) Create(ctx context.Context, user *models.User) error {
 		user.TelegramUsername,
 		user.CreatedAt,
 		user.UpdatedAt,
-	)
-	if err != nil {
+	}
+}
+
+// Create создает нового пользователя
+func (r *UserRepo) Create(ctx context.Context, user *models.User) error {
+	if _, err := r.db.ExecContext(ctx, createUserQuery, createUserArgs(user)...); err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
+// CreateTx создает нового пользователя в транзакции вызывающего (импорт, конвертация заявки).
+// Занятый email возвращается как ErrUserExists
+func (r *UserRepo) CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	if _, err := tx.Exec(ctx, createUserQuery, createUserArgs(user)...); err != nil {
+		if IsUniqueViolationError(err) {
+			return ErrUserExists
+		}
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/pkg/ical"
+	"tutoring-platform/pkg/metrics"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// AcademicCalendarService управляет академическим календарём (периоды, праздники, каникулы)
+// и отменой занятий, попадающих на заблокированные даты
+type AcademicCalendarService struct {
+	pool         *pgxpool.Pool
+	calendarRepo *repository.AcademicCalendarRepository
+	lessonRepo   *repository.LessonRepository
+	creditRepo   *repository.CreditRepository
+}
+
+// NewAcademicCalendarService создает новый AcademicCalendarService
+func NewAcademicCalendarService(
+	pool *pgxpool.Pool,
+	calendarRepo *repository.AcademicCalendarRepository,
+	lessonRepo *repository.LessonRepository,
+	creditRepo *repository.CreditRepository,
+) *AcademicCalendarService {
+	return &AcademicCalendarService{
+		pool:         pool,
+		calendarRepo: calendarRepo,
+		lessonRepo:   lessonRepo,
+		creditRepo:   creditRepo,
+	}
+}
+
+// ListEntries возвращает записи календаря, пересекающиеся с периодом (нулевые границы - без ограничения)
+func (s *AcademicCalendarService) ListEntries(ctx context.Context, from, to time.Time) ([]*models.AcademicCalendarEntry, error) {
+	return s.calendarRepo.List(ctx, from, to)
+}
+
+// GetEntry возвращает запись календаря по ID
+func (s *AcademicCalendarService) GetEntry(ctx context.Context, id uuid.UUID) (*models.AcademicCalendarEntry, error) {
+	return s.calendarRepo.GetByID(ctx, id)
+}
+
+// CreateEntry создает запись календаря вручную
+func (s *AcademicCalendarService) CreateEntry(ctx context.Context, adminID uuid.UUID, req *models.CreateCalendarEntryRequest) (*models.AcademicCalendarEntry, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	start, end, err := req.Dates()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.AcademicCalendarEntry{
+		EntryType: req.EntryType,
+		Name:      req.Name,
+		StartDate: start,
+		EndDate:   end,
+		Source:    models.CalendarSourceManual,
+		CreatedBy: uuid.NullUUID{UUID: adminID, Valid: true},
+	}
+
+	if err := s.calendarRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("entry_id", entry.ID.String()).
+		Str("entry_type", string(entry.EntryType)).
+		Str("admin_id", adminID.String()).
+		Msg("Academic calendar entry created")
+
+	return entry, nil
+}
+
+// UpdateEntry обновляет запись календаря
+func (s *AcademicCalendarService) UpdateEntry(ctx context.Context, id uuid.UUID, req *models.UpdateCalendarEntryRequest) (*models.AcademicCalendarEntry, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.calendarRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	start, end := existing.StartDate, existing.EndDate
+
+	if req.EntryType != nil {
+		updates["entry_type"] = *req.EntryType
+	}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.StartDate != nil {
+		start, _ = time.Parse(models.CalendarDateLayout, *req.StartDate)
+		updates["start_date"] = start
+	}
+	if req.EndDate != nil {
+		end, _ = time.Parse(models.CalendarDateLayout, *req.EndDate)
+		updates["end_date"] = end
+	}
+
+	// Проверяем итоговый период с учётом неизменённой границы
+	if end.Before(start) {
+		return nil, models.ErrInvalidCalendarDates
+	}
+
+	if err := s.calendarRepo.Update(ctx, id, updates); err != nil {
+		return nil, err
+	}
+
+	return s.calendarRepo.GetByID(ctx, id)
+}
+
+// DeleteEntry удаляет запись календаря
+func (s *AcademicCalendarService) DeleteEntry(ctx context.Context, id uuid.UUID) error {
+	return s.calendarRepo.Delete(ctx, id)
+}
+
+// GetBlockingCalendar возвращает праздники и каникулы, пересекающиеся с периодом
+// Реализует BlockedDatesProvider для LessonService
+func (s *AcademicCalendarService) GetBlockingCalendar(ctx context.Context, from, to time.Time) (models.AcademicCalendar, error) {
+	return s.calendarRepo.ListBlocking(ctx, from, to)
+}
+
+// ImportICS импортирует записи календаря из ICS файла
+// Тип записи определяется по CATEGORIES события, иначе используется defaultType
+// Повторный импорт того же файла обновляет записи по UID вместо создания дубликатов
+func (s *AcademicCalendarService) ImportICS(ctx context.Context, adminID uuid.UUID, r io.Reader, defaultType models.CalendarEntryType) (*models.ICSImportResult, error) {
+	if defaultType == "" {
+		defaultType = models.CalendarEntryHoliday
+	}
+	if !models.IsValidCalendarEntryType(defaultType) {
+		return nil, models.ErrInvalidCalendarEntryType
+	}
+
+	events, err := ical.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidICSFile, err)
+	}
+
+	result := &models.ICSImportResult{Entries: []*models.AcademicCalendarEntry{}}
+
+	for i := range events {
+		event := &events[i]
+
+		name := strings.TrimSpace(event.Summary)
+		if name == "" || len(name) > 200 {
+			result.Skipped++
+			continue
+		}
+
+		start := time.Date(event.Start.Year(), event.Start.Month(), event.Start.Day(), 0, 0, 0, 0, time.UTC)
+		last := event.LastDay()
+		end := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+
+		entry := &models.AcademicCalendarEntry{
+			EntryType: calendarEntryTypeFromCategories(event.Categories, defaultType),
+			Name:      name,
+			StartDate: start,
+			EndDate:   end,
+			CreatedBy: uuid.NullUUID{UUID: adminID, Valid: true},
+		}
+
+		if event.UID != "" {
+			entry.ExternalUID = sql.NullString{String: event.UID, Valid: true}
+			inserted, err := s.calendarRepo.UpsertByExternalUID(ctx, entry)
+			if err != nil {
+				return nil, err
+			}
+			if inserted {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+		} else {
+			entry.Source = models.CalendarSourceICS
+			if err := s.calendarRepo.Create(ctx, entry); err != nil {
+				return nil, err
+			}
+			result.Created++
+		}
+
+		result.Entries = append(result.Entries, entry)
+	}
+
+	log.Info().
+		Str("admin_id", adminID.String()).
+		Int("created", result.Created).
+		Int("updated", result.Updated).
+		Int("skipped", result.Skipped).
+		Msg("Academic calendar imported from ICS")
+
+	return result, nil
+}
+
+// calendarEntryTypeFromCategories определяет тип записи по CATEGORIES ICS события
+func calendarEntryTypeFromCategories(categories []string, defaultType models.CalendarEntryType) models.CalendarEntryType {
+	for _, category := range categories {
+		c := strings.ToLower(category)
+		switch {
+		case strings.Contains(c, "break"), strings.Contains(c, "vacation"), strings.Contains(c, "каникул"):
+			return models.CalendarEntryBreak
+		case strings.Contains(c, "holiday"), strings.Contains(c, "праздни"), strings.Contains(c, "выходн"):
+			return models.CalendarEntryHoliday
+		case strings.Contains(c, "term"), strings.Contains(c, "semester"), strings.Contains(c, "четверт"), strings.Contains(c, "семестр"):
+			return models.CalendarEntryTerm
+		}
+	}
+	return defaultType
+}
+
+// PreviewConflicts возвращает будущие занятия, попадающие на праздники и каникулы
+// Если entryIDs пусто, проверяются все блокирующие записи в периоде [from, to]
+func (s *AcademicCalendarService) PreviewConflicts(ctx context.Context, entryIDs []uuid.UUID, from, to time.Time) ([]*models.CalendarConflict, error) {
+	var calendar models.AcademicCalendar
+
+	if len(entryIDs) > 0 {
+		entries, err := s.calendarRepo.GetByIDs(ctx, entryIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.BlocksLessons() {
+				calendar = append(calendar, entry)
+			}
+		}
+	} else {
+		if from.IsZero() {
+			from = time.Now()
+		}
+		if to.IsZero() {
+			to = from.AddDate(1, 0, 0)
+		}
+		entries, err := s.calendarRepo.ListBlocking(ctx, from, to)
+		if err != nil {
+			return nil, err
+		}
+		calendar = entries
+	}
+
+	conflicts := []*models.CalendarConflict{}
+	if len(calendar) == 0 {
+		return conflicts, nil
+	}
+
+	// Ищем занятия в объединённом периоде записей (с запасом в сутки на часовые пояса)
+	rangeStart, rangeEnd := calendar[0].StartDate, calendar[0].EndDate
+	for _, entry := range calendar[1:] {
+		if entry.StartDate.Before(rangeStart) {
+			rangeStart = entry.StartDate
+		}
+		if entry.EndDate.After(rangeEnd) {
+			rangeEnd = entry.EndDate
+		}
+	}
+
+	lessons, err := s.lessonRepo.GetUpcomingInRange(ctx, rangeStart.AddDate(0, 0, -1), rangeEnd.AddDate(0, 0, 2))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lesson := range lessons {
+		entry := calendar.BlockingEntry(lesson.StartTime)
+		if entry == nil {
+			continue
+		}
+
+		conflict := &models.CalendarConflict{
+			LessonID:         lesson.ID,
+			TeacherID:        lesson.TeacherID,
+			TeacherName:      lesson.TeacherName,
+			StartTime:        lesson.StartTime,
+			EndTime:          lesson.EndTime,
+			CurrentStudents:  lesson.CurrentStudents,
+			CreditsCost:      lesson.CreditsCost,
+			RecurringGroupID: lesson.RecurringGroupID,
+			EntryID:          entry.ID,
+			EntryName:        entry.Name,
+			EntryType:        string(entry.EntryType),
+		}
+		if lesson.Subject.Valid {
+			conflict.Subject = lesson.Subject.String
+		}
+		conflicts = append(conflicts, conflict)
+	}
+
+	return conflicts, nil
+}
+
+// CancelConflictingLessons отменяет выбранные занятия, попадающие на праздники и каникулы
+// Все активные бронирования отменяются с возвратом кредитов, занятия удаляются (soft delete)
+// Операция атомарна: при любой ошибке ничего не изменяется
+func (s *AcademicCalendarService) CancelConflictingLessons(ctx context.Context, adminID uuid.UUID, req *models.CancelCalendarConflictsRequest) (*models.CancelCalendarConflictsResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Failed to rollback calendar cancellation transaction")
+		}
+	}()
+
+	result := &models.CancelCalendarConflictsResult{}
+	seen := make(map[uuid.UUID]bool, len(req.LessonIDs))
+
+	for _, lessonID := range req.LessonIDs {
+		if seen[lessonID] {
+			continue
+		}
+		seen[lessonID] = true
+
+		lesson, err := s.lessonRepo.GetByIDForUpdate(ctx, tx, lessonID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock lesson %s: %w", lessonID, err)
+		}
+
+		if !lesson.StartTime.After(time.Now()) {
+			return nil, fmt.Errorf("lesson %s: %w", lessonID, models.ErrLessonAlreadyStarted)
+		}
+
+		// Отменять разрешено только занятия, которые действительно попадают на праздник или каникулы
+		day := lesson.StartTime
+		calendar, err := s.calendarRepo.ListBlocking(ctx, day.AddDate(0, 0, -1), day.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+		entry := calendar.BlockingEntry(day)
+		if entry == nil {
+			return nil, fmt.Errorf("lesson %s: %w", lessonID, models.ErrLessonNotOnBlockedDate)
+		}
+
+		bookings, err := s.lessonRepo.GetBookingsByLessonTx(ctx, tx, lessonID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, booking := range bookings {
+			if err := s.lessonRepo.CancelBookingTx(ctx, tx, lessonID, booking.StudentID); err != nil {
+				return nil, fmt.Errorf("failed to cancel booking %s: %w", booking.ID, err)
+			}
+			if err := s.lessonRepo.DecrementStudents(ctx, tx, lessonID); err != nil {
+				return nil, fmt.Errorf("failed to decrement students for lesson %s: %w", lessonID, err)
+			}
+			result.CancelledBookings++
+
+			if lesson.CreditsCost <= 0 {
+				continue
+			}
+
+			credit, err := s.creditRepo.GetBalanceForUpdate(ctx, tx, booking.StudentID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get balance for student %s: %w", booking.StudentID, err)
+			}
+
+			newBalance := credit.Balance + lesson.CreditsCost
+			if err := s.creditRepo.UpdateBalance(ctx, tx, booking.StudentID, newBalance); err != nil {
+				return nil, fmt.Errorf("failed to refund credits: %w", err)
+			}
+
+			creditTx := &models.CreditTransaction{
+				UserID:        booking.StudentID,
+				Amount:        lesson.CreditsCost,
+				OperationType: models.OperationTypeRefund,
+				Reason:        fmt.Sprintf("Занятие отменено: %s", entry.Name),
+				BookingID:     uuid.NullUUID{UUID: booking.ID, Valid: true},
+				PerformedBy:   uuid.NullUUID{UUID: adminID, Valid: true},
+				BalanceBefore: credit.Balance,
+				BalanceAfter:  newBalance,
+			}
+			if err := s.creditRepo.CreateTransaction(ctx, tx, creditTx); err != nil {
+				return nil, fmt.Errorf("failed to record refund transaction: %w", err)
+			}
+			result.RefundedCredits += lesson.CreditsCost
+		}
+
+		if err := s.lessonRepo.DeleteLessonTx(ctx, tx, lessonID); err != nil {
+			return nil, fmt.Errorf("failed to cancel lesson %s: %w", lessonID, err)
+		}
+		result.CancelledLessons++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	metrics.BookingsCancelled.Add(float64(result.CancelledBookings))
+	metrics.CreditsRefunded.Add(float64(result.RefundedCredits))
+
+	log.Info().
+		Str("admin_id", adminID.String()).
+		Int("cancelled_lessons", result.CancelledLessons).
+		Int("cancelled_bookings", result.CancelledBookings).
+		Int("refunded_credits", result.RefundedCredits).
+		Msg("Lessons on blocked calendar dates cancelled")
+
+	return result, nil
+}
@@ -0,0 +1,542 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// uploadsRootDir корневая директория загруженных файлов; файлы вне нее не читаются и не удаляются
+const uploadsRootDir = "uploads"
+
+// AccountDataService выгружает персональные данные пользователя и обезличивает аккаунт
+// по одобренному администратором запросу. Финансовые записи (платежи, кредиты, записи на занятия)
+// при обезличивании сохраняются и продолжают ссылаться на строку пользователя
+type AccountDataService struct {
+	pool        txBeginner
+	repo        accountDataRepository
+	userRepo    repository.UserRepository
+	creditRepo  *repository.CreditRepository
+	paymentRepo *repository.PaymentRepository
+	fileStorage *FileStorageService
+}
+
+// accountDataRepository - часть AccountDataRepository, используемая AccountDataService
+type accountDataRepository interface {
+	GetTelegramLink(ctx context.Context, userID uuid.UUID) (*models.ExportTelegramLink, error)
+	ListBookings(ctx context.Context, userID uuid.UUID) ([]*models.ExportBooking, error)
+	ListCreditTransactions(ctx context.Context, userID uuid.UUID) ([]*models.ExportCreditTransaction, error)
+	ListMessages(ctx context.Context, userID uuid.UUID) ([]*models.ExportMessage, error)
+	ListMessageEdits(ctx context.Context, userID uuid.UUID) ([]*models.ExportMessageEdit, error)
+	ListAttachments(ctx context.Context, userID uuid.UUID) ([]*models.ExportAttachment, error)
+	ListHomework(ctx context.Context, userID uuid.UUID) ([]*models.ExportHomework, error)
+	ListSwaps(ctx context.Context, userID uuid.UUID) ([]*models.ExportSwap, error)
+	CreateErasureRequest(ctx context.Context, req *models.AccountErasureRequest) error
+	GetLatestErasureRequest(ctx context.Context, userID uuid.UUID) (*models.AccountErasureRequest, error)
+	ListErasureRequests(ctx context.Context, status *models.ErasureRequestStatus) ([]*models.AccountErasureRequest, error)
+	GetErasureRequestForUpdateTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.AccountErasureRequest, error)
+	ReviewErasureRequestTx(ctx context.Context, tx pgx.Tx, req *models.AccountErasureRequest) error
+	LockUserTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (*models.User, error)
+	AnonymizeTrialRequestsTx(ctx context.Context, tx pgx.Tx, user *models.User) (int64, error)
+	DeleteTelegramLinkTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (bool, error)
+	RedactMessagesTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (int64, error)
+	DeleteAttachmentsTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]*models.FileAttachment, error)
+	DeleteSessionsTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error
+	AnonymizeUserTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID, now time.Time) error
+}
+
+// NewAccountDataService создает новый AccountDataService
+func NewAccountDataService(
+	pool txBeginner,
+	repo accountDataRepository,
+	userRepo repository.UserRepository,
+	creditRepo *repository.CreditRepository,
+	paymentRepo *repository.PaymentRepository,
+) *AccountDataService {
+	return &AccountDataService{
+		pool:        pool,
+		repo:        repo,
+		userRepo:    userRepo,
+		creditRepo:  creditRepo,
+		paymentRepo: paymentRepo,
+	}
+}
+
+// SetFileStorage подключает BlobStore: вложения из него попадают в выгрузку и удаляются при обезличивании
+func (s *AccountDataService) SetFileStorage(fileStorage *FileStorageService) {
+	s.fileStorage = fileStorage
+}
+
+// AccountExportProfile профиль пользователя в выгрузке данных
+type AccountExportProfile struct {
+	ID                     uuid.UUID                  `json:"id"`
+	Email                  string                     `json:"email"`
+	FirstName              string                     `json:"first_name"`
+	LastName               string                     `json:"last_name"`
+	Role                   models.UserRole            `json:"role"`
+	TelegramUsername       string                     `json:"telegram_username,omitempty"`
+	ParentTelegramUsername string                     `json:"parent_telegram_username,omitempty"`
+	Telegram               *models.ExportTelegramLink `json:"telegram,omitempty"`
+	CreatedAt              time.Time                  `json:"created_at"`
+}
+
+// AccountExport собранные данные пользователя, готовые к записи в ZIP архив
+type AccountExport struct {
+	Profile      *AccountExportProfile
+	Bookings     []*models.ExportBooking
+	Credits      *models.ExportCredits
+	Payments     []*models.Payment
+	Messages     []*models.ExportMessage
+	MessageEdits []*models.ExportMessageEdit
+	Attachments  []*models.ExportAttachment
+	Homework     []*models.ExportHomework
+	Swaps        []*models.ExportSwap
+	GeneratedAt  time.Time
+
+	// openObject открывает вложение из BlobStore (nil - хранилище не подключено)
+	openObject func(key string) (io.ReadCloser, error)
+}
+
+// FileName возвращает имя файла архива выгрузки
+func (e *AccountExport) FileName() string {
+	return fmt.Sprintf("account-export-%s.zip", e.GeneratedAt.Format("2006-01-02"))
+}
+
+// Export собирает все персональные данные пользователя
+func (s *AccountDataService) Export(ctx context.Context, userID uuid.UUID) (*AccountExport, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &AccountExport{
+		Profile: &AccountExportProfile{
+			ID:                     user.ID,
+			Email:                  user.Email,
+			FirstName:              user.FirstName,
+			LastName:               user.LastName,
+			Role:                   user.Role,
+			TelegramUsername:       user.TelegramUsername.String,
+			ParentTelegramUsername: user.ParentTelegramUsername.String,
+			CreatedAt:              user.CreatedAt,
+		},
+		GeneratedAt: time.Now(),
+	}
+	if s.fileStorage != nil {
+		export.openObject = func(key string) (io.ReadCloser, error) {
+			body, _, err := s.fileStorage.Open(ctx, key)
+			return body, err
+		}
+	}
+
+	if export.Profile.Telegram, err = s.repo.GetTelegramLink(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Bookings, err = s.repo.ListBookings(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	balance, err := s.creditRepo.GetBalance(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.repo.ListCreditTransactions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Credits = &models.ExportCredits{Balance: balance.Balance, Transactions: transactions}
+
+	if export.Payments, err = s.paymentRepo.ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Messages, err = s.repo.ListMessages(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.MessageEdits, err = s.repo.ListMessageEdits(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Attachments, err = s.repo.ListAttachments(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Homework, err = s.repo.ListHomework(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Swaps, err = s.repo.ListSwaps(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	export.attachEdits()
+	export.attachFiles()
+	return export, nil
+}
+
+// attachEdits привязывает историю правок к сообщениям
+func (e *AccountExport) attachEdits() {
+	byMessage := make(map[uuid.UUID]*models.ExportMessage, len(e.Messages))
+	for _, msg := range e.Messages {
+		byMessage[msg.ID] = msg
+	}
+
+	for _, edit := range e.MessageEdits {
+		if msg, ok := byMessage[edit.MessageID]; ok {
+			msg.Edits = append(msg.Edits, edit)
+		}
+	}
+}
+
+// attachFiles привязывает вложения к сообщениям и назначает путь в архиве файлам, найденным на диске
+// или сохраненным в BlobStore
+func (e *AccountExport) attachFiles() {
+	byMessage := make(map[uuid.UUID]*models.ExportMessage, len(e.Messages))
+	for _, msg := range e.Messages {
+		byMessage[msg.ID] = msg
+	}
+
+	for _, att := range e.Attachments {
+		if (att.StorageKey.Valid && e.openObject != nil) || localUploadPath(att.FilePath) != "" {
+			att.ArchivePath = path.Join("chat", "attachments", att.ID.String()+"_"+filepath.Base(att.FileName))
+		}
+		if msg, ok := byMessage[att.MessageID]; ok {
+			msg.Attachments = append(msg.Attachments, att)
+		}
+	}
+}
+
+// WriteZip записывает выгрузку в ZIP архив: JSON файлы по разделам, файлы вложений чата и manifest.json
+func (e *AccountExport) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	manifest := &models.AccountExportManifest{
+		UserID:      e.Profile.ID,
+		GeneratedAt: e.GeneratedAt,
+	}
+
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", e.Profile},
+		{"bookings.json", e.Bookings},
+		{"credits.json", e.Credits},
+		{"payments.json", e.Payments},
+		{"chat/messages.json", e.Messages},
+		{"homework.json", e.Homework},
+		{"swaps.json", e.Swaps},
+	}
+	for _, section := range sections {
+		if err := writeZipJSON(zw, section.name, section.data, e.GeneratedAt); err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, section.name)
+	}
+
+	for _, att := range e.Attachments {
+		if att.ArchivePath == "" {
+			continue
+		}
+		if att.StorageKey.Valid {
+			if err := writeZipObject(zw, att.ArchivePath, e.openObject, att.StorageKey.String, att.UploadedAt); err != nil {
+				return err
+			}
+		} else if err := writeZipFile(zw, att.ArchivePath, localUploadPath(att.FilePath), att.UploadedAt); err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, att.ArchivePath)
+	}
+
+	if err := writeZipJSON(zw, "manifest.json", manifest, e.GeneratedAt); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeZipJSON записывает значение в архив как JSON с отступами
+func writeZipJSON(zw *zip.Writer, name string, data interface{}, modified time.Time) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("failed to create %s in archive: %w", name, err)
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+// writeZipFile копирует файл с диска в архив. Файл, удаленный после сбора выгрузки, пропускается
+func writeZipFile(zw *zip.Writer, name, filePath string, modified time.Time) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		log.Warn().Err(err).Str("path", filePath).Msg("Attachment file is missing, skipped in export")
+		return nil
+	}
+	defer f.Close()
+
+	return copyToZip(zw, name, f, modified)
+}
+
+// writeZipObject копирует объект из BlobStore в архив. Отсутствующий объект пропускается
+func writeZipObject(zw *zip.Writer, name string, openObject func(string) (io.ReadCloser, error), key string, modified time.Time) error {
+	body, err := openObject(key)
+	if err != nil {
+		log.Warn().Err(err).Str("storage_key", key).Msg("Attachment object is unavailable, skipped in export")
+		return nil
+	}
+	defer body.Close()
+
+	return copyToZip(zw, name, body, modified)
+}
+
+// copyToZip записывает содержимое r в архив под именем name
+func copyToZip(zw *zip.Writer, name string, r io.Reader, modified time.Time) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("failed to create %s in archive: %w", name, err)
+	}
+	if _, err := io.Copy(fw, r); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+// localUploadPath возвращает очищенный путь файла, если он лежит внутри директории загрузок
+// и существует на диске, иначе пустую строку
+func localUploadPath(filePath string) string {
+	clean := filepath.Clean(filePath)
+	if !strings.HasPrefix(clean, uploadsRootDir+string(filepath.Separator)) {
+		return ""
+	}
+	info, err := os.Stat(clean)
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	return clean
+}
+
+// RequestErasure создает запрос пользователя на удаление аккаунта
+func (s *AccountDataService) RequestErasure(ctx context.Context, userID uuid.UUID, req *models.CreateErasureRequest) (*models.AccountErasureRequest, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAdmin() {
+		return nil, models.ErrErasureAdminAccount
+	}
+
+	erasure := &models.AccountErasureRequest{
+		UserID:    user.ID,
+		UserName:  user.GetFullName(),
+		UserEmail: user.Email,
+		Reason:    req.Reason,
+	}
+	if err := s.repo.CreateErasureRequest(ctx, erasure); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("request_id", erasure.ID.String()).
+		Str("user_id", userID.String()).
+		Msg("Account erasure requested")
+
+	return erasure, nil
+}
+
+// GetMyErasureRequest возвращает последний запрос пользователя на удаление
+func (s *AccountDataService) GetMyErasureRequest(ctx context.Context, userID uuid.UUID) (*models.AccountErasureRequest, error) {
+	return s.repo.GetLatestErasureRequest(ctx, userID)
+}
+
+// ListErasureRequests возвращает запросы на удаление для администратора
+func (s *AccountDataService) ListErasureRequests(ctx context.Context, status *models.ErasureRequestStatus) ([]*models.AccountErasureRequest, error) {
+	if status != nil && !status.IsValid() {
+		return nil, models.ErrInvalidErasureStatus
+	}
+	return s.repo.ListErasureRequests(ctx, status)
+}
+
+// ApproveErasure одобряет запрос и в одной транзакции обезличивает пользователя, его заявки
+// на пробное занятие, привязку Telegram и сообщения чата, завершает сессии.
+// Файлы удаленных вложений стираются с диска после фиксации транзакции
+func (s *AccountDataService) ApproveErasure(ctx context.Context, adminID, requestID uuid.UUID, req *models.ReviewErasureRequest) (*models.ErasureResult, error) {
+	if err := req.Validate(false); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Failed to rollback account erasure transaction")
+		}
+	}()
+
+	erasure, err := s.getPendingRequestTx(ctx, tx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.LockUserTx(ctx, tx, erasure.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAdmin() {
+		return nil, models.ErrErasureAdminAccount
+	}
+	if models.IsErasedEmail(user.Email) {
+		return nil, models.ErrAccountAlreadyErased
+	}
+
+	result := &models.ErasureResult{Request: erasure}
+	now := time.Now()
+
+	if result.TrialRequests, err = s.repo.AnonymizeTrialRequestsTx(ctx, tx, user); err != nil {
+		return nil, err
+	}
+	if result.TelegramLinkRemoved, err = s.repo.DeleteTelegramLinkTx(ctx, tx, user.ID); err != nil {
+		return nil, err
+	}
+	if result.MessagesRedacted, err = s.repo.RedactMessagesTx(ctx, tx, user.ID); err != nil {
+		return nil, err
+	}
+	attachments, err := s.repo.DeleteAttachmentsTx(ctx, tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.AttachmentsRemoved = len(attachments)
+	if err := s.repo.DeleteSessionsTx(ctx, tx, user.ID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.AnonymizeUserTx(ctx, tx, user.ID, now); err != nil {
+		return nil, err
+	}
+
+	erasure.Status = models.ErasureRequestCompleted
+	erasure.ReviewNote = req.Note
+	erasure.ReviewedBy = uuid.NullUUID{UUID: adminID, Valid: true}
+	erasure.ReviewedAt = &now
+	if err := s.repo.ReviewErasureRequestTx(ctx, tx, erasure); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	erasure.UserName = models.ErasedFirstName + " " + models.ErasedLastName
+	erasure.UserEmail = models.ErasedEmail(user.ID)
+	s.removeAttachmentFiles(ctx, attachments)
+
+	log.Info().
+		Str("request_id", erasure.ID.String()).
+		Str("user_id", user.ID.String()).
+		Str("admin_id", adminID.String()).
+		Int64("trial_requests", result.TrialRequests).
+		Int64("messages", result.MessagesRedacted).
+		Int("attachments", result.AttachmentsRemoved).
+		Msg("Account erased")
+
+	return result, nil
+}
+
+// RejectErasure отклоняет запрос на удаление с обязательным комментарием
+func (s *AccountDataService) RejectErasure(ctx context.Context, adminID, requestID uuid.UUID, req *models.ReviewErasureRequest) (*models.AccountErasureRequest, error) {
+	if err := req.Validate(true); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Failed to rollback erasure rejection transaction")
+		}
+	}()
+
+	erasure, err := s.getPendingRequestTx(ctx, tx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	erasure.Status = models.ErasureRequestRejected
+	erasure.ReviewNote = req.Note
+	erasure.ReviewedBy = uuid.NullUUID{UUID: adminID, Valid: true}
+	erasure.ReviewedAt = &now
+	if err := s.repo.ReviewErasureRequestTx(ctx, tx, erasure); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Info().
+		Str("request_id", erasure.ID.String()).
+		Str("admin_id", adminID.String()).
+		Msg("Account erasure rejected")
+
+	return erasure, nil
+}
+
+// getPendingRequestTx блокирует запрос на удаление и проверяет, что решение по нему еще не принято
+func (s *AccountDataService) getPendingRequestTx(ctx context.Context, tx pgx.Tx, requestID uuid.UUID) (*models.AccountErasureRequest, error) {
+	erasure, err := s.repo.GetErasureRequestForUpdateTx(ctx, tx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if erasure.Status != models.ErasureRequestPending {
+		return nil, models.ErrErasureRequestNotPending
+	}
+	return erasure, nil
+}
+
+// removeAttachmentFiles удаляет файлы удаленных вложений: объекты BlobStore без других ссылок
+// и файлы на диске; ошибки только логируются, так как записи о файлах уже удалены из БД
+func (s *AccountDataService) removeAttachmentFiles(ctx context.Context, attachments []*models.FileAttachment) {
+	var filePaths []string
+	for _, att := range attachments {
+		if !att.StorageKey.Valid {
+			filePaths = append(filePaths, att.FilePath)
+		} else if s.fileStorage != nil {
+			s.fileStorage.ReleaseQuietly(ctx, att.StorageKey.String)
+		}
+	}
+	removeUploadedFiles(filePaths)
+}
+
+// removeUploadedFiles удаляет файлы вложений с диска; ошибки только логируются,
+// так как записи о файлах уже удалены из БД
+func removeUploadedFiles(filePaths []string) {
+	for _, filePath := range filePaths {
+		clean := filepath.Clean(filePath)
+		if !strings.HasPrefix(clean, uploadsRootDir+string(filepath.Separator)) {
+			log.Warn().Str("path", filePath).Msg("Attachment path outside uploads directory, not removed")
+			continue
+		}
+		if err := os.Remove(clean); err != nil && !os.IsNotExist(err) {
+			log.Warn().Err(err).Str("path", clean).Msg("Failed to remove erased attachment file")
+		}
+	}
+}
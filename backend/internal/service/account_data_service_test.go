@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAccountDataRepo хранит один запрос на удаление и пользователя в памяти,
+// запоминает шаги обезличивания. failStep имитирует ошибку на указанном шаге
+type fakeAccountDataRepo struct {
+	accountDataRepository
+	erasure    *models.AccountErasureRequest
+	user       *models.User
+	failStep   string
+	steps      []string
+	reviewed   *models.AccountErasureRequest
+	anonymized time.Time
+}
+
+var errFakeErasureStep = errors.New("erasure step failed")
+
+func (r *fakeAccountDataRepo) step(name string, userID uuid.UUID) error {
+	if userID != r.user.ID {
+		return errors.New("unexpected user id in " + name)
+	}
+	r.steps = append(r.steps, name)
+	if name == r.failStep {
+		return errFakeErasureStep
+	}
+	return nil
+}
+
+func (r *fakeAccountDataRepo) GetErasureRequestForUpdateTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.AccountErasureRequest, error) {
+	erasure := *r.erasure
+	return &erasure, nil
+}
+
+func (r *fakeAccountDataRepo) LockUserTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (*models.User, error) {
+	return r.user, r.step("lock_user", userID)
+}
+
+func (r *fakeAccountDataRepo) AnonymizeTrialRequestsTx(ctx context.Context, tx pgx.Tx, user *models.User) (int64, error) {
+	return 2, r.step("trial_requests", user.ID)
+}
+
+func (r *fakeAccountDataRepo) DeleteTelegramLinkTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (bool, error) {
+	return true, r.step("telegram_link", userID)
+}
+
+func (r *fakeAccountDataRepo) RedactMessagesTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (int64, error) {
+	return 5, r.step("messages", userID)
+}
+
+func (r *fakeAccountDataRepo) DeleteAttachmentsTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]*models.FileAttachment, error) {
+	// Вложения из BlobStore: при отсутствии fileStorage файлы на диске не трогаются
+	attachments := []*models.FileAttachment{
+		{ID: uuid.New(), StorageKey: sql.NullString{String: "blob-1", Valid: true}},
+		{ID: uuid.New(), StorageKey: sql.NullString{String: "blob-2", Valid: true}},
+	}
+	return attachments, r.step("attachments", userID)
+}
+
+func (r *fakeAccountDataRepo) DeleteSessionsTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	return r.step("sessions", userID)
+}
+
+func (r *fakeAccountDataRepo) AnonymizeUserTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID, now time.Time) error {
+	r.anonymized = now
+	return r.step("user", userID)
+}
+
+func (r *fakeAccountDataRepo) ReviewErasureRequestTx(ctx context.Context, tx pgx.Tx, req *models.AccountErasureRequest) error {
+	reviewed := *req
+	r.reviewed = &reviewed
+	return nil
+}
+
+// newErasureFixture создает ожидающий запрос на удаление аккаунта студента
+func newErasureFixture() (*fakeTxBeginner, *fakeAccountDataRepo, *AccountDataService) {
+	user := &models.User{ID: uuid.New(), Email: "anna@example.com", Role: models.RoleStudent}
+	pool := &fakeTxBeginner{}
+	repo := &fakeAccountDataRepo{
+		erasure: &models.AccountErasureRequest{ID: uuid.New(), UserID: user.ID, Status: models.ErasureRequestPending},
+		user:    user,
+	}
+	svc := NewAccountDataService(pool, repo, nil, nil, nil)
+	return pool, repo, svc
+}
+
+func TestAccountDataService_ApproveErasure_AnonymizesAccount(t *testing.T) {
+	pool, repo, svc := newErasureFixture()
+	adminID := uuid.New()
+
+	result, err := svc.ApproveErasure(context.Background(), adminID, repo.erasure.ID, &models.ReviewErasureRequest{Note: "  confirmed by phone  "})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"lock_user", "trial_requests", "telegram_link", "messages", "attachments", "sessions", "user"}, repo.steps)
+	assert.Equal(t, int64(2), result.TrialRequests)
+	assert.Equal(t, int64(5), result.MessagesRedacted)
+	assert.Equal(t, 2, result.AttachmentsRemoved)
+	assert.True(t, result.TelegramLinkRemoved)
+
+	require.NotNil(t, repo.reviewed)
+	assert.Equal(t, models.ErasureRequestCompleted, repo.reviewed.Status)
+	assert.Equal(t, "confirmed by phone", repo.reviewed.ReviewNote)
+	assert.Equal(t, uuid.NullUUID{UUID: adminID, Valid: true}, repo.reviewed.ReviewedBy)
+	require.NotNil(t, repo.reviewed.ReviewedAt)
+	assert.True(t, repo.reviewed.ReviewedAt.Equal(repo.anonymized))
+
+	// В ответе не должно остаться исходных персональных данных
+	assert.Equal(t, models.ErasedEmail(repo.user.ID), result.Request.UserEmail)
+	assert.Equal(t, models.ErasedFirstName+" "+models.ErasedLastName, result.Request.UserName)
+	assert.NotContains(t, result.Request.UserEmail, "anna")
+
+	require.Len(t, pool.txs, 1)
+	assert.True(t, pool.last().committed)
+}
+
+func TestAccountDataService_ApproveErasure_RejectsAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(user *models.User)
+		wantErr error
+	}{
+		{
+			name:    "admin account",
+			prepare: func(user *models.User) { user.Role = models.RoleAdmin },
+			wantErr: models.ErrErasureAdminAccount,
+		},
+		{
+			name:    "already erased",
+			prepare: func(user *models.User) { user.Email = models.ErasedEmail(user.ID) },
+			wantErr: models.ErrAccountAlreadyErased,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, repo, svc := newErasureFixture()
+			tt.prepare(repo.user)
+
+			result, err := svc.ApproveErasure(context.Background(), uuid.New(), repo.erasure.ID, &models.ReviewErasureRequest{})
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, result)
+
+			assert.Equal(t, []string{"lock_user"}, repo.steps, "no data should be anonymized")
+			assert.Nil(t, repo.reviewed)
+			assert.False(t, pool.last().committed)
+			assert.True(t, pool.last().rolledBack)
+		})
+	}
+}
+
+func TestAccountDataService_ApproveErasure_RollsBackOnFailedStep(t *testing.T) {
+	pool, repo, svc := newErasureFixture()
+	repo.failStep = "messages"
+
+	result, err := svc.ApproveErasure(context.Background(), uuid.New(), repo.erasure.ID, &models.ReviewErasureRequest{})
+	assert.ErrorIs(t, err, errFakeErasureStep)
+	assert.Nil(t, result)
+
+	assert.NotContains(t, repo.steps, "user", "user must not be anonymized after a failed step")
+	assert.Nil(t, repo.reviewed, "request must stay pending")
+	assert.False(t, pool.last().committed)
+	assert.True(t, pool.last().rolledBack)
+}
+
+func TestAccountDataService_ApproveErasure_NotPending(t *testing.T) {
+	pool, repo, svc := newErasureFixture()
+	repo.erasure.Status = models.ErasureRequestRejected
+
+	_, err := svc.ApproveErasure(context.Background(), uuid.New(), repo.erasure.ID, &models.ReviewErasureRequest{})
+	assert.ErrorIs(t, err, models.ErrErasureRequestNotPending)
+	assert.Empty(t, repo.steps)
+	assert.True(t, pool.last().rolledBack)
+}
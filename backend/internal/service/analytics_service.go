@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/pkg/metrics"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// analyticsRefreshInterval период обновления материализованных представлений аналитики
+	analyticsRefreshInterval = 15 * time.Minute
+	// analyticsRefreshTimeout ограничивает обновление одного представления
+	analyticsRefreshTimeout = 5 * time.Minute
+)
+
+// ErrAnalyticsRefreshInProgress возвращается, если обновление аналитики уже выполняется
+var ErrAnalyticsRefreshInProgress = errors.New("analytics refresh already in progress")
+
+// AnalyticsService отдает агрегированную аналитику для админки: выручку, расход кредитов,
+// заполняемость, отмены и переносы, удержание когорт студентов.
+// Данные читаются из материализованных представлений, которые обновляются фоновым воркером
+type AnalyticsService struct {
+	repo *repository.AnalyticsRepository
+
+	refreshMu   sync.Mutex
+	stopRefresh chan struct{}
+	refreshDone chan struct{}
+}
+
+// NewAnalyticsService создает новый AnalyticsService
+func NewAnalyticsService(repo *repository.AnalyticsRepository) *AnalyticsService {
+	return &AnalyticsService{repo: repo}
+}
+
+// GetRevenue получает выручку по периодам и источникам
+func (s *AnalyticsService) GetRevenue(ctx context.Context, q *models.AnalyticsQuery) (*models.RevenueReport, error) {
+	if err := s.prepareQuery(q); err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.GetRevenue(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.RevenueReport{Points: points}
+	report.Finalize()
+	return report, nil
+}
+
+// GetCreditFlow получает начисления, списания, возвраты и расход кредитов по периодам
+func (s *AnalyticsService) GetCreditFlow(ctx context.Context, q *models.AnalyticsQuery) ([]*models.CreditFlowPoint, error) {
+	if err := s.prepareQuery(q); err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.GetCreditFlow(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range points {
+		p.Finalize()
+	}
+	return points, nil
+}
+
+// GetUtilization получает заполняемость мест по преподавателям и предметам
+func (s *AnalyticsService) GetUtilization(ctx context.Context, q *models.AnalyticsQuery, teacherID *uuid.UUID) ([]*models.UtilizationRow, error) {
+	if err := s.prepareQuery(q); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.GetUtilization(ctx, q, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		row.Finalize()
+	}
+	return rows, nil
+}
+
+// GetBookingActivity получает записи, отмены, переносы и их доли по периодам
+func (s *AnalyticsService) GetBookingActivity(ctx context.Context, q *models.AnalyticsQuery) ([]*models.BookingActivityPoint, error) {
+	if err := s.prepareQuery(q); err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.GetBookingActivity(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range points {
+		p.Finalize()
+	}
+	return points, nil
+}
+
+// GetRetention строит удержание когорт студентов, впервые записавшихся на занятие в периоде запроса.
+// Удержание считается помесячно до конца периода, но не более months месяцев
+func (s *AnalyticsService) GetRetention(ctx context.Context, q *models.AnalyticsQuery, months int) ([]*models.CohortRetention, error) {
+	if err := s.prepareQuery(q); err != nil {
+		return nil, err
+	}
+	if months <= 0 || months > models.MaxRetentionMonths {
+		months = models.MaxRetentionMonths
+	}
+
+	activity, err := s.repo.GetCohortActivity(ctx, q.From, q.To, q.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.BuildCohortRetention(activity, q.From, q.To, months), nil
+}
+
+// GetRefreshState получает время последнего обновления представлений
+func (s *AnalyticsService) GetRefreshState(ctx context.Context) ([]*models.AnalyticsRefreshState, error) {
+	return s.repo.GetRefreshState(ctx)
+}
+
+// RefreshViews обновляет все представления аналитики. Одновременно выполняется только одно обновление.
+// Ошибка обновления одного представления не останавливает обновление остальных
+func (s *AnalyticsService) RefreshViews(ctx context.Context) error {
+	if !s.refreshMu.TryLock() {
+		return ErrAnalyticsRefreshInProgress
+	}
+	defer s.refreshMu.Unlock()
+
+	var firstErr error
+	for _, view := range repository.AnalyticsViews {
+		viewCtx, cancel := context.WithTimeout(ctx, analyticsRefreshTimeout)
+		duration, err := s.repo.RefreshView(viewCtx, view)
+		cancel()
+
+		if err != nil {
+			metrics.AnalyticsRefreshErrors.WithLabelValues(view).Inc()
+			log.Error().Err(err).Str("view", view).Msg("Failed to refresh analytics view")
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		metrics.AnalyticsRefreshDuration.WithLabelValues(view).Observe(duration.Seconds())
+		log.Debug().Str("view", view).Dur("duration", duration).Msg("Analytics view refreshed")
+	}
+
+	return firstErr
+}
+
+// StartRefreshWorker запускает фоновое обновление представлений: сразу и затем по расписанию
+func (s *AnalyticsService) StartRefreshWorker() {
+	s.stopRefresh = make(chan struct{})
+	s.refreshDone = make(chan struct{})
+	go s.refreshLoop()
+}
+
+// Shutdown останавливает фоновое обновление аналитики (для graceful shutdown)
+func (s *AnalyticsService) Shutdown() {
+	if s.stopRefresh == nil {
+		return
+	}
+	close(s.stopRefresh)
+	<-s.refreshDone
+	log.Info().Msg("Analytics refresh worker shutdown complete")
+}
+
+// refreshLoop периодически обновляет представления аналитики
+func (s *AnalyticsService) refreshLoop() {
+	ticker := time.NewTicker(analyticsRefreshInterval)
+	defer ticker.Stop()
+	defer close(s.refreshDone)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		// Прерываем долгое обновление при остановке сервера
+		select {
+		case <-s.stopRefresh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s.runScheduledRefresh(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			s.runScheduledRefresh(ctx)
+		case <-s.stopRefresh:
+			log.Info().Msg("Analytics refresh worker shutting down")
+			return
+		}
+	}
+}
+
+// runScheduledRefresh выполняет плановое обновление, пропуская его, если идет ручное
+func (s *AnalyticsService) runScheduledRefresh(ctx context.Context) {
+	if err := s.RefreshViews(ctx); err != nil && !errors.Is(err, ErrAnalyticsRefreshInProgress) && ctx.Err() == nil {
+		log.Warn().Err(err).Msg("Scheduled analytics refresh finished with errors")
+	}
+}
+
+// prepareQuery заполняет значения по умолчанию и проверяет запрос
+func (s *AnalyticsService) prepareQuery(q *models.AnalyticsQuery) error {
+	q.ApplyDefaults(time.Now())
+	return q.Validate()
+}
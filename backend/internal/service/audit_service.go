@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// auditRequestKey ключ контекста для метаданных запроса журнала аудита
+type auditRequestKey struct{}
+
+// AuditRequest метаданные HTTP запроса, которые попадают в каждую запись журнала аудита.
+// Заполняется middleware до вызова обработчика
+type AuditRequest struct {
+	ActorID   uuid.UUID
+	ActorRole models.UserRole
+	IP        string
+	RequestID string
+	Method    string
+	Path      string
+
+	// recorded отмечает, что сервис уже записал подробное событие по этому запросу
+	recorded atomic.Bool
+}
+
+// Recorded сообщает, было ли по запросу записано событие аудита
+func (r *AuditRequest) Recorded() bool {
+	return r.recorded.Load()
+}
+
+// WithAuditRequest добавляет метаданные запроса в контекст
+func WithAuditRequest(ctx context.Context, req *AuditRequest) context.Context {
+	return context.WithValue(ctx, auditRequestKey{}, req)
+}
+
+// AuditRequestFromContext получает метаданные запроса из контекста
+func AuditRequestFromContext(ctx context.Context) (*AuditRequest, bool) {
+	req, ok := ctx.Value(auditRequestKey{}).(*AuditRequest)
+	return req, ok && req != nil
+}
+
+// AuditService ведет единый журнал аудита привилегированных изменений.
+// Сервисы вызывают Record с состоянием объекта до и после изменения; автор, IP и request ID
+// берутся из контекста запроса. Ошибка записи журнала не прерывает основную операцию
+type AuditService struct {
+	repo *repository.AuditRepository
+}
+
+// NewAuditService создает новый AuditService
+func NewAuditService(repo *repository.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record записывает событие журнала с разницей состояний before/after (nil - объект создан или удален).
+// Безопасен для вызова на nil сервисе, чтобы хуки в сервисах работали и без настроенного журнала
+func (s *AuditService) Record(ctx context.Context, action models.AuditAction, targetType, targetID string, before, after interface{}) {
+	if s == nil {
+		return
+	}
+
+	beforeJSON, afterJSON, err := models.AuditDiff(before, after)
+	if err != nil {
+		log.Error().Err(err).Str("action", string(action)).Msg("Failed to build audit diff")
+		return
+	}
+
+	event := &models.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		CreatedAt:  time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if req, ok := AuditRequestFromContext(ctx); ok {
+		req.recorded.Store(true)
+		if req.ActorID != uuid.Nil {
+			event.ActorID = uuid.NullUUID{UUID: req.ActorID, Valid: true}
+		}
+		event.ActorRole = string(req.ActorRole)
+		event.IP = req.IP
+		event.RequestID = req.RequestID
+		event.Method = req.Method
+		event.Path = req.Path
+	}
+
+	// Запись журнала не должна откатываться вместе с отменой контекста запроса после изменения
+	if err := s.repo.Append(context.WithoutCancel(ctx), event); err != nil {
+		log.Error().Err(err).
+			Str("action", string(action)).
+			Str("target_type", targetType).
+			Str("target_id", targetID).
+			Msg("Failed to write audit event")
+	}
+}
+
+// List возвращает записи журнала по фильтру и общее количество
+func (s *AuditService) List(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEvent, int, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Verify проверяет целостность цепочки хешей журнала
+func (s *AuditService) Verify(ctx context.Context) (*models.AuditVerifyResult, error) {
+	result, err := s.repo.Verify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Valid {
+		log.Error().
+			Int64("broken_at_id", *result.BrokenAtID).
+			Str("reason", result.Reason).
+			Msg("Audit log hash chain is broken")
+	}
+	return result, nil
+}
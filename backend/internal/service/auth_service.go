@@ -50,6 +50,8 @@ type AuthService struct {
 	sessionRepo   SessionRepositoryInterface
 	sessionMgr    *auth.SessionManager
 	sessionMaxAge time.Duration
+	auditService  *AuditService
+	permissions   *PermissionService
 }
 
 // NewAuthService создает новый AuthService
@@ -67,6 +69,17 @@ func NewAuthService(
 	}
 }
 
+// SetAuditService подключает журнал аудита для записи начала и окончания имперсонации
+func (s *AuthService) SetAuditService(auditService *AuditService) {
+	s.auditService = auditService
+}
+
+// SetPermissionService подключает права ролей для проверки права users.impersonate;
+// без него проверяются права встроенных ролей
+func (s *AuthService) SetPermissionService(permissions *PermissionService) {
+	s.permissions = permissions
+}
+
 // isSessionValid проверяет, не истекла ли сессия
 // Используется когда нужна точная проверка (без буфера)
 // ПРИМЕЧАНИЕ: Эту функцию нужно использовать с осторожностью
@@ -444,6 +457,11 @@ func (s *AuthService) RefreshSessionToken(ctx context.Context, token string) (st
 		return "", auth.ErrExpiredSession
 	}
 
+	// Сессия имперсонации ограничена по времени и не продлевается
+	if session.IsImpersonation() {
+		return token, nil
+	}
+
 	// Проверяем, нужно ли продлевать сессию
 	timeUntilExpiry := time.Until(session.ExpiresAt)
 
@@ -468,3 +486,94 @@ func (s *AuthService) RefreshSessionToken(ctx context.Context, token string) (st
 
 	return newToken, nil
 }
+
+// StartImpersonation создает для пользователя с правом users.impersonate отдельную сессию от имени другого пользователя.
+// Сессия помечается impersonator_id, ограничена по времени, не продлевается и по умолчанию
+// доступна только для чтения. Входить от имени администраторов, пользователей с правами, которых нет
+// у инициатора, и из сессии имперсонации запрещено
+func (s *AuthService) StartImpersonation(ctx context.Context, adminSession *models.SessionWithUser, targetID uuid.UUID, req *models.StartImpersonationRequest, ipAddress, userAgent string) (*LoginResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if adminSession.IsImpersonation() {
+		return nil, models.ErrImpersonationNested
+	}
+	adminPerms := s.permissions.PermissionsFor(ctx, adminSession.UserRole)
+	if !adminPerms.Has(models.PermUsersImpersonate) {
+		return nil, repository.ErrUnauthorized
+	}
+	if targetID == adminSession.UserID {
+		return nil, models.ErrImpersonateSelf
+	}
+
+	target, err := s.userRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target.IsDeleted() {
+		return nil, repository.ErrUserNotFound
+	}
+	if target.IsAdmin() {
+		return nil, models.ErrImpersonateAdmin
+	}
+	if !adminPerms.Contains(s.permissions.PermissionsFor(ctx, target.Role)) {
+		return nil, models.ErrImpersonateHigherRole
+	}
+
+	session := &models.Session{
+		UserID:         target.ID,
+		ExpiresAt:      time.Now().Add(req.Duration()),
+		IPAddress:      ipAddress,
+		UserAgent:      userAgent,
+		ImpersonatorID: uuid.NullUUID{UUID: adminSession.UserID, Valid: true},
+		ReadOnly:       !req.AllowWrite,
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to save impersonation session: %w", err)
+	}
+
+	token, err := s.sessionMgr.CreateSessionToken(session.ID, target.ID, session.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create impersonation session token: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditActionImpersonationStart, models.AuditTargetUser, target.ID.String(), nil, map[string]interface{}{
+		"session_id": session.ID,
+		"expires_at": session.ExpiresAt,
+		"read_only":  session.ReadOnly,
+		"reason":     req.Reason,
+	})
+
+	return &LoginResponse{
+		User:         target,
+		SessionToken: token,
+		Session:      session,
+	}, nil
+}
+
+// StopImpersonation завершает сессию имперсонации. Если impersonatorToken - действующая сессия
+// того же администратора, она возвращается для восстановления; иначе возвращается nil
+func (s *AuthService) StopImpersonation(ctx context.Context, session *models.SessionWithUser, impersonatorToken string) (*models.SessionWithUser, error) {
+	if !session.IsImpersonation() {
+		return nil, models.ErrNotImpersonating
+	}
+
+	if err := s.sessionRepo.Delete(ctx, session.ID); err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		return nil, fmt.Errorf("failed to delete impersonation session: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditActionImpersonationStop, models.AuditTargetUser, session.UserID.String(), map[string]interface{}{
+		"session_id": session.ID,
+		"expires_at": session.ExpiresAt,
+		"read_only":  session.ReadOnly,
+	}, nil)
+
+	if impersonatorToken == "" {
+		return nil, nil
+	}
+	adminSession, err := s.ValidateSessionWithBuffer(ctx, impersonatorToken)
+	if err != nil || adminSession.UserID != session.ImpersonatorID.UUID || adminSession.IsImpersonation() {
+		return nil, nil
+	}
+	return adminSession, nil
+}
@@ -117,15 +117,50 @@ func (s *BookingService) CreateBooking(ctx context.Context, req *models.CreateBo
 		}
 	}()
 
-	// Получаем урок для определения стоимости в кредитах (нужно для обоих путей)
-	lesson, err := s.lessonRepo.GetByIDForUpdate(ctx, tx, req.LessonID)
+	booking, lesson, err := s.bookTx(ctx, tx, req.StudentID, req.LessonID, req.IsAdmin, req.AdminID, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get lesson: %w", err)
+		return nil, err
+	}
+
+	// Фиксируем транзакцию
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Обновляем метрики успешного бронирования
+	metrics.BookingsCreated.Inc()
+	metrics.CreditsDeducted.Inc()
+
+	// Отправляем уведомление в Telegram (неблокирующая операция)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		s.sendBookingNotification(ctx, booking.ID, req.StudentID, lesson)
+	}()
+
+	return booking, nil
+}
+
+// bookTx записывает студента на занятие в транзакции вызывающего: блокирует занятие, проверяет свободные места,
+// для администратора реактивирует отмененную запись (иначе создает новую), списывает стоимость занятия
+// и увеличивает счетчик студентов. Пустой reason - причина списания по умолчанию для записи студентом или администратором
+func (s *BookingService) bookTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	studentID, lessonID uuid.UUID,
+	isAdmin bool,
+	adminID uuid.UUID,
+	reason string,
+) (*models.Booking, *models.Lesson, error) {
+	// Получаем урок для определения стоимости в кредитах
+	lesson, err := s.lessonRepo.GetByIDForUpdate(ctx, tx, lessonID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get lesson: %w", err)
 	}
 
 	// Verify lesson was found (safety check)
 	if lesson == nil {
-		return nil, fmt.Errorf("lesson not found")
+		return nil, nil, fmt.Errorf("lesson not found")
 	}
 
 	creditsCost := lesson.CreditsCost
@@ -133,177 +168,97 @@ func (s *BookingService) CreateBooking(ctx context.Context, req *models.CreateBo
 		creditsCost = 0 // Исправляем отрицательные значения на 0
 	}
 
-	// Для админа: попробовать реактивировать существующее cancelled бронирование
-	// ВАЖНО: этот путь изолирован с помощью раннего return на строке 183
-	// Это гарантирует что кредит списывается ровно 1 раз и путь 2 не выполнится
-	if req.IsAdmin {
-		// Check capacity before reactivation (lesson already locked via SELECT FOR UPDATE at line 114)
-		// This ensures we don't reactivate if lesson is already at capacity
-		if lesson.IsFull() {
-			return nil, repository.ErrLessonFull
-		}
+	// Проверяем доступность урока (после блокировки - это авторитетная проверка)
+	if lesson.IsFull() {
+		log.Warn().
+			Str("lesson_id", lesson.ID.String()).
+			Int("current_students", lesson.CurrentStudents).
+			Int("max_students", lesson.MaxStudents).
+			Msg("Lesson is full - race condition detected")
+		return nil, nil, repository.ErrLessonFull
+	}
 
-		reactivated, reactivateErr := s.bookingRepo.ReactivateBooking(ctx, tx, req.StudentID, req.LessonID)
-		if reactivateErr == nil && reactivated != nil {
+	// Для админа: сначала пробуем реактивировать отмененное бронирование, иначе создаем новое
+	var booking *models.Booking
+	reactivated := false
+	if isAdmin {
+		existing, reactivateErr := s.bookingRepo.ReactivateBooking(ctx, tx, studentID, lessonID)
+		if reactivateErr == nil && existing != nil {
 			log.Info().
-				Str("booking_id", reactivated.ID.String()).
-				Str("student_id", utils.MaskUserID(req.StudentID)).
-				Str("lesson_id", req.LessonID.String()).
+				Str("booking_id", existing.ID.String()).
+				Str("student_id", utils.MaskUserID(studentID)).
+				Str("lesson_id", lessonID.String()).
 				Msg("Capacity check passed - admin reactivating cancelled booking")
+			booking = existing
+			reactivated = true
+		} else if reactivateErr != nil && reactivateErr != repository.ErrBookingNotFound {
+			log.Warn().Err(reactivateErr).Msg("Unexpected error during reactivation attempt, proceeding with new booking")
+		}
+	}
 
-			// Списываем кредиты только если урок платный (creditsCost > 0)
-			if creditsCost > 0 {
-				// Блокируем и получаем баланс кредитов для списания
-				credit, creditErr := s.creditRepo.GetBalanceForUpdate(ctx, tx, req.StudentID)
-				if creditErr != nil {
-					return nil, fmt.Errorf("failed to get credit balance: %w", creditErr)
-				}
-
-				if !credit.HasSufficientBalance(creditsCost) {
-					return nil, repository.ErrInsufficientCredits
-				}
-
-				// Списываем кредиты
-				newBalance := credit.Balance - creditsCost
-				if err := s.creditRepo.UpdateBalance(ctx, tx, req.StudentID, newBalance); err != nil {
-					return nil, fmt.Errorf("failed to update credit balance: %w", err)
-				}
-
-				log.Info().
-					Str("student_id", utils.MaskUserID(req.StudentID)).
-					Str("balance", utils.MaskAmount(newBalance)).
-					Bool("is_reactivation", true).
-					Msg("Credit deducted for reactivated booking")
-
-				// Записываем транзакцию кредитов
-				reason := "Admin re-booking (reactivation)"
-				var performedBy uuid.NullUUID
-				if req.AdminID != uuid.Nil {
-					performedBy = uuid.NullUUID{UUID: req.AdminID, Valid: true}
-				}
-
-				transaction := &models.CreditTransaction{
-					UserID:        req.StudentID,
-					Amount:        -creditsCost,
-					OperationType: models.OperationTypeDeduct,
-					Reason:        reason,
-					PerformedBy:   performedBy,
-					BookingID:     uuid.NullUUID{UUID: reactivated.ID, Valid: true},
-					BalanceBefore: credit.Balance,
-					BalanceAfter:  newBalance,
-				}
-				if err := s.creditRepo.CreateTransaction(ctx, tx, transaction); err != nil {
-					return nil, fmt.Errorf("failed to create credit transaction: %w", err)
-				}
-			}
-
-			// Увеличиваем счётчик студентов в уроке
-			if err := s.lessonRepo.IncrementStudents(ctx, tx, req.LessonID); err != nil {
-				log.Error().
-					Str("lesson_id", req.LessonID.String()).
-					Err(err).
-					Msg("Failed to increment students for reactivated booking")
-				return nil, fmt.Errorf("failed to increment students: %w", err)
+	if booking == nil {
+		// Если при INSERT-е произойдет нарушение UNIQUE constraint (concurrent booking),
+		// это будет перехвачено и преобразовано в ErrAlreadyBooked в Create методе
+		booking = &models.Booking{
+			StudentID: studentID,
+			LessonID:  lessonID,
+		}
+		if err := s.bookingRepo.Create(ctx, tx, booking); err != nil {
+			// ErrDuplicateBooking уже содержит пользовательское сообщение об ошибке
+			// которое возвращается из репозитория при нарушении UNIQUE constraint
+			if err == repository.ErrDuplicateBooking {
+				return nil, nil, repository.ErrDuplicateBooking
 			}
-
-			// Фиксируем транзакцию
-			if err := tx.Commit(ctx); err != nil {
-				return nil, fmt.Errorf("failed to commit transaction: %w", err)
+			// Для совместимости, также проверяем IsUniqueViolationError (на случай других UNIQUE constraints)
+			if repository.IsUniqueViolationError(err) {
+				return nil, nil, repository.ErrAlreadyBooked
 			}
-
-			metrics.BookingsCreated.Inc()
-			metrics.CreditsDeducted.Inc()
-
-			// Отправляем уведомление в Telegram (неблокирующая операция)
-			go func() {
-				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-				defer cancel()
-				s.sendBookingNotification(ctx, reactivated.ID, req.StudentID, lesson)
-			}()
-
-			// РАННИЙ ВЫХОД: гарантирует что путь 2 (создание нового бронирования ниже) не выполнится
-			// Это предотвращает двойное списание кредитов
-			return reactivated, nil
-		}
-		// Если ErrBookingNotFound - продолжаем создание нового бронирования (путь 2)
-		if reactivateErr != nil && reactivateErr != repository.ErrBookingNotFound {
-			log.Warn().Err(reactivateErr).Msg("Unexpected error during reactivation attempt, proceeding with new booking")
+			return nil, nil, fmt.Errorf("failed to create booking: %w", err)
 		}
 	}
 
-	// Путь 2: Создание нового бронирования (выполняется если путь 1 не сработал)
 	// Списываем кредиты только если урок платный (creditsCost > 0)
-	var credit *models.Credit
-	var newBalance int
 	if creditsCost > 0 {
 		// Блокируем и получаем баланс кредитов
-		var err error
-		credit, err = s.creditRepo.GetBalanceForUpdate(ctx, tx, req.StudentID)
+		// Database trigger provides additional safety net to prevent negative balance
+		credit, err := s.creditRepo.GetBalanceForUpdate(ctx, tx, studentID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get credit balance: %w", err)
+			return nil, nil, fmt.Errorf("failed to get credit balance: %w", err)
 		}
-
-		// Calculate final balance after deduction (safety check after SELECT FOR UPDATE)
-		// This is the ONLY balance check - performed after acquiring lock via SELECT FOR UPDATE
-		// Database trigger provides additional safety net to prevent negative balance
-		newBalance = credit.Balance - creditsCost
-		if newBalance < 0 {
-			return nil, repository.ErrInsufficientCredits
+		if !credit.HasSufficientBalance(creditsCost) {
+			return nil, nil, repository.ErrInsufficientCredits
 		}
-		if err := s.creditRepo.UpdateBalance(ctx, tx, req.StudentID, newBalance); err != nil {
-			return nil, fmt.Errorf("failed to update credit balance: %w", err)
+
+		newBalance := credit.Balance - creditsCost
+		if err := s.creditRepo.UpdateBalance(ctx, tx, studentID, newBalance); err != nil {
+			return nil, nil, fmt.Errorf("failed to update credit balance: %w", err)
 		}
 
-		// Log credit deduction
 		log.Info().
-			Str("student_id", utils.MaskUserID(req.StudentID)).
+			Str("student_id", utils.MaskUserID(studentID)).
 			Str("balance", utils.MaskAmount(newBalance)).
-			Bool("is_admin_booking", req.IsAdmin).
+			Bool("is_admin_booking", isAdmin).
+			Bool("is_reactivation", reactivated).
 			Msg("Credit deducted for booking")
-	}
-
-	// Проверяем доступность урока (после блокировки - это авторитетная проверка)
-	if lesson.IsFull() {
-		log.Warn().
-			Str("lesson_id", lesson.ID.String()).
-			Int("current_students", lesson.CurrentStudents).
-			Int("max_students", lesson.MaxStudents).
-			Msg("Lesson is full - race condition detected")
-		return nil, repository.ErrLessonFull
-	}
-
-	// Создаем бронирование
-	// Если при INSERT-е произойдет нарушение UNIQUE constraint (concurrent booking),
-	// это будет перехвачено и преобразовано в ErrAlreadyBooked в Create методе
-	booking := &models.Booking{
-		StudentID: req.StudentID,
-		LessonID:  req.LessonID,
-	}
-	if err := s.bookingRepo.Create(ctx, tx, booking); err != nil {
-		// ErrDuplicateBooking уже содержит пользовательское сообщение об ошибке
-		// которое возвращается из репозитория при нарушении UNIQUE constraint
-		if err == repository.ErrDuplicateBooking {
-			return nil, repository.ErrDuplicateBooking
-		}
-		// Для совместимости, также проверяем IsUniqueViolationError (на случай других UNIQUE constraints)
-		if repository.IsUniqueViolationError(err) {
-			return nil, repository.ErrAlreadyBooked
-		}
-		return nil, fmt.Errorf("failed to create booking: %w", err)
-	}
 
-	// Записываем транзакцию кредитов только если урок платный (creditsCost > 0)
-	if creditsCost > 0 && credit != nil {
 		// Determine transaction reason and performed_by based on who created the booking
-		reason := "Booking lesson"
 		var performedBy uuid.NullUUID
-		if req.IsAdmin && req.AdminID != uuid.Nil {
-			reason = "Admin booking for student"
-			performedBy = uuid.NullUUID{UUID: req.AdminID, Valid: true}
+		if isAdmin && adminID != uuid.Nil {
+			performedBy = uuid.NullUUID{UUID: adminID, Valid: true}
+		}
+		if reason == "" {
+			switch {
+			case reactivated:
+				reason = "Admin re-booking (reactivation)"
+			case performedBy.Valid:
+				reason = "Admin booking for student"
+			default:
+				reason = "Booking lesson"
+			}
 		}
 
 		transaction := &models.CreditTransaction{
-			UserID:        req.StudentID,
+			UserID:        studentID,
 			Amount:        -creditsCost,
 			OperationType: models.OperationTypeDeduct,
 			Reason:        reason,
@@ -313,11 +268,11 @@ func (s *BookingService) CreateBooking(ctx context.Context, req *models.CreateBo
 			BalanceAfter:  newBalance,
 		}
 		if err := s.creditRepo.CreateTransaction(ctx, tx, transaction); err != nil {
-			return nil, fmt.Errorf("failed to create credit transaction: %w", err)
+			return nil, nil, fmt.Errorf("failed to create credit transaction: %w", err)
 		}
 
 		log.Info().
-			Str("student_id", req.StudentID.String()).
+			Str("student_id", studentID.String()).
 			Str("reason", reason).
 			Str("performed_by", performedBy.UUID.String()).
 			Msg("Credit transaction created")
@@ -326,31 +281,15 @@ func (s *BookingService) CreateBooking(ctx context.Context, req *models.CreateBo
 	// Увеличиваем счетчик студентов в уроке
 	// IncrementStudents has additional safety: WHERE current_students < max_students
 	// This is a final defense against race condition (should never fail if IsFull check passed)
-	if err := s.lessonRepo.IncrementStudents(ctx, tx, req.LessonID); err != nil {
+	if err := s.lessonRepo.IncrementStudents(ctx, tx, lessonID); err != nil {
 		log.Error().
-			Str("lesson_id", req.LessonID.String()).
+			Str("lesson_id", lessonID.String()).
 			Err(err).
 			Msg("Failed to increment students - race condition detected at DB level")
-		return nil, fmt.Errorf("failed to increment students: %w", err)
-	}
-
-	// Фиксируем транзакцию
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, nil, fmt.Errorf("failed to increment students: %w", err)
 	}
 
-	// Обновляем метрики успешного бронирования
-	metrics.BookingsCreated.Inc()
-	metrics.CreditsDeducted.Inc()
-
-	// Отправляем уведомление в Telegram (неблокирующая операция)
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		s.sendBookingNotification(ctx, booking.ID, req.StudentID, lesson)
-	}()
-
-	return booking, nil
+	return booking, lesson, nil
 }
 
 // CancelBooking отменяет бронирование и возвращает кредит (атомарная операция)
@@ -500,6 +439,11 @@ func (s *BookingService) CancelBooking(ctx context.Context, req *models.CancelBo
 	}, nil
 }
 
+// GetLesson получает занятие (для проверки прав на запись студентов на чужое занятие)
+func (s *BookingService) GetLesson(ctx context.Context, lessonID uuid.UUID) (*models.Lesson, error) {
+	return s.lessonRepo.GetByID(ctx, lessonID)
+}
+
 // GetBooking получает бронирование с деталями
 func (s *BookingService) GetBooking(ctx context.Context, bookingID uuid.UUID) (*models.BookingWithDetails, error) {
 	return s.bookingRepo.GetWithDetails(ctx, bookingID)
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"path/filepath"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/upload"
+	"tutoring-platform/pkg/telegram"
+
+	"github.com/google/uuid"
+)
+
+// broadcastMessageOptions собирает parse_mode и inline клавиатуру рассылки
+func broadcastMessageOptions(broadcast *models.Broadcast) *telegram.MessageOptions {
+	options := &telegram.MessageOptions{ParseMode: broadcast.ParseMode}
+	if len(broadcast.Buttons) == 0 {
+		return options
+	}
+
+	keyboard := make([][]telegram.InlineKeyboardButton, len(broadcast.Buttons))
+	for i, row := range broadcast.Buttons {
+		keyboard[i] = make([]telegram.InlineKeyboardButton, len(row))
+		for j, button := range row {
+			keyboard[i][j] = telegram.InlineKeyboardButton{
+				Text:         button.Text,
+				URL:          button.URL,
+				CallbackData: button.CallbackData,
+			}
+		}
+	}
+	options.ReplyMarkup = &telegram.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	return options
+}
+
+// deliverContent отправляет получателю вложения (по одному, без подписи), затем текст с кнопками.
+// Уже доставленные части записаны в delivery и при повторной попытке не отправляются снова
+func (s *BroadcastService) deliverContent(ctx context.Context, chatID int64, content *broadcastContent, delivery *broadcastDelivery) error {
+	for i := len(delivery.attachmentMessageIDs); i < len(content.attachments); i++ {
+		attachment := content.attachments[i]
+
+		file := telegram.InputFile{FileName: attachment.FileName}
+		if attachment.TelegramFileID != nil {
+			file.FileID = *attachment.TelegramFileID
+		} else {
+			filePath, cleanup, err := s.attachmentLocalPath(ctx, attachment)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			file.FilePath = filePath
+		}
+
+		msg, err := s.telegramClient.SendFile(chatID, telegram.MediaKind(attachment.Kind), file)
+		if err != nil {
+			return err
+		}
+		delivery.attachmentMessageIDs = append(delivery.attachmentMessageIDs, msg.MessageID)
+
+		// Запоминаем file_id после первой загрузки, остальным получателям файл не загружается повторно
+		if attachment.TelegramFileID == nil {
+			if fileID := telegram.FileIDOf(msg); fileID != "" {
+				attachment.TelegramFileID = &fileID
+				if err := s.broadcastRepo.SetAttachmentFileID(ctx, attachment.ID, fileID); err != nil {
+					log.Printf("[WARN] Failed to save file_id for attachment %s: %v\n", attachment.ID, err)
+				}
+			}
+		}
+	}
+
+	if delivery.messageID == 0 {
+		msg, err := s.telegramClient.SendTextMessage(chatID, content.text, content.options)
+		if err != nil {
+			return err
+		}
+		delivery.messageID = msg.MessageID
+	}
+
+	return nil
+}
+
+// validateAttachmentFiles проверяет количество, размер и тип вложений до создания рассылки.
+// Возвращает тип отправки (photo/document) для каждого файла
+func validateAttachmentFiles(files []*multipart.FileHeader) ([]string, error) {
+	if len(files) > models.MaxBroadcastFiles {
+		return nil, models.ErrTooManyFiles
+	}
+
+	kinds := make([]string, len(files))
+	for i, fileHeader := range files {
+		if fileHeader.Size <= 0 || fileHeader.Size > models.MaxBroadcastFileSize {
+			return nil, models.ErrFileTooLarge
+		}
+
+		kind, err := models.BroadcastAttachmentKind(fileHeader.Header.Get("Content-Type"))
+		if err != nil {
+			return nil, err
+		}
+
+		// Content-Type задает клиент, поэтому фото сверяем с содержимым до создания рассылки:
+		// Telegram принимает как фото только изображения. Остальные проверки содержимого выполняет FileStorageService.Save
+		if kind == models.BroadcastAttachmentPhoto {
+			file, err := fileHeader.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to open file %s: %w", fileHeader.Filename, err)
+			}
+			detected := upload.DetectContentType(file, fileHeader.Size)
+			file.Close()
+			if detected != fileHeader.Header.Get("Content-Type") {
+				return nil, models.ErrInvalidBroadcastFileType
+			}
+		}
+		kinds[i] = kind
+	}
+
+	return kinds, nil
+}
+
+// attachmentLocalPath возвращает путь к файлу вложения на диске. Вложение из BlobStore копируется
+// во временный файл (для LocalStore используется сам объект), cleanup удаляет копию.
+// Вложения, загруженные до перехода на BlobStore, лежат в uploadDir
+func (s *BroadcastService) attachmentLocalPath(ctx context.Context, attachment *models.BroadcastAttachment) (string, func(), error) {
+	if !attachment.StorageKey.Valid {
+		if err := ValidateBroadcastFilePath(attachment.FilePath, s.uploadDir); err != nil {
+			return "", nil, err
+		}
+		return filepath.Join(s.uploadDir, attachment.FilePath), func() {}, nil
+	}
+	if s.fileStorage == nil {
+		return "", nil, models.ErrFileStorageFailed
+	}
+	return s.fileStorage.LocalCopy(ctx, attachment.StorageKey.String)
+}
+
+// saveAttachments сохраняет файлы рассылки в BlobStore и создает записи вложений
+func (s *BroadcastService) saveAttachments(
+	ctx context.Context,
+	uploadedBy, broadcastID uuid.UUID,
+	files []*multipart.FileHeader,
+	kinds []string,
+) ([]*models.BroadcastAttachment, error) {
+	if s.fileStorage == nil {
+		return nil, models.ErrFileStorageFailed
+	}
+
+	attachments := make([]*models.BroadcastAttachment, 0, len(files))
+	for i, fileHeader := range files {
+		attachment, err := s.saveAttachment(ctx, uploadedBy, broadcastID, fileHeader, kinds[i], i)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
+
+// saveAttachment сохраняет один файл через FileStorageService (проверка типа по содержимому,
+// антивирус, квота, удаление метаданных изображений) и создает запись вложения
+func (s *BroadcastService) saveAttachment(
+	ctx context.Context,
+	uploadedBy, broadcastID uuid.UUID,
+	fileHeader *multipart.FileHeader,
+	kind string,
+	position int,
+) (*models.BroadcastAttachment, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", fileHeader.Filename, err)
+	}
+	defer file.Close()
+
+	contentType := fileHeader.Header.Get("Content-Type")
+	blob, err := s.fileStorage.Save(ctx, models.StoredFileSourceBroadcasts, uploadedBy, fileHeader.Filename, file, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save file %s: %w", fileHeader.Filename, err)
+	}
+
+	attachment := &models.BroadcastAttachment{
+		BroadcastID: broadcastID,
+		Kind:        kind,
+		FileName:    filepath.Base(fileHeader.Filename),
+		FilePath:    blob.Key,
+		FileSize:    blob.Size,
+		MimeType:    contentType,
+		StorageKey:  sql.NullString{String: blob.Key, Valid: true},
+		ContentHash: sql.NullString{String: blob.Hash, Valid: true},
+		Position:    position,
+	}
+	if err := s.broadcastRepo.CreateAttachment(ctx, attachment); err != nil {
+		s.fileStorage.ReleaseQuietly(ctx, blob.Key)
+		return nil, fmt.Errorf("failed to add broadcast attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// EditBroadcastMessage меняет текст уже доставленной рассылки в чатах получателей (editMessageText).
+// Новый текст сохраняется сразу, сообщения обновляются в фоне с соблюдением rate limit
+func (s *BroadcastService) EditBroadcastMessage(ctx context.Context, id uuid.UUID, req *models.EditBroadcastMessageRequest) (*models.Broadcast, error) {
+	if s.telegramClient == nil {
+		return nil, ErrTelegramNotConfigured
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	broadcast, err := s.getDeliveredBroadcast(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ValidateBroadcastFormatting(req.Message, broadcast.ParseMode); err != nil {
+		return nil, err
+	}
+
+	if err := s.broadcastRepo.UpdateMessage(ctx, id, req.Message); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	broadcast.Message = req.Message
+	broadcast.EditedAt = &now
+
+	options := broadcastMessageOptions(broadcast)
+	err = s.updateDeliveredMessages(broadcast, "edit", func(ctx context.Context, logs []*models.BroadcastLog) func(*models.BroadcastLog) error {
+		userIDs := make([]uuid.UUID, len(logs))
+		for i, entry := range logs {
+			userIDs[i] = entry.UserID
+		}
+		recipients, err := s.loadRecipients(ctx, broadcast.Message, userIDs)
+		if err != nil {
+			log.Printf("[WARN] Failed to load recipients for broadcast %s edit: %v\n", broadcast.ID, err)
+		}
+
+		return func(entry *models.BroadcastLog) error {
+			text := broadcast.Message
+			if recipient, ok := recipients[entry.UserID]; ok {
+				text = models.RenderBroadcastMessage(text, recipient, broadcast.ParseMode)
+			}
+			err := s.telegramClient.EditMessageText(*entry.ChatID, *entry.MessageID, text, options)
+			if telegram.IsMessageNotModified(err) {
+				return nil
+			}
+			return err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[INFO] Broadcast edit initiated: id=%s", id)
+	return broadcast, nil
+}
+
+// RecallBroadcast удаляет доставленные сообщения и вложения рассылки из чатов получателей (deleteMessage).
+// Telegram позволяет удалять сообщения бота только в течение 48 часов после отправки
+func (s *BroadcastService) RecallBroadcast(ctx context.Context, id uuid.UUID) error {
+	if s.telegramClient == nil {
+		return ErrTelegramNotConfigured
+	}
+
+	broadcast, err := s.getDeliveredBroadcast(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	claimed, err := s.broadcastRepo.MarkRecalled(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrBroadcastRecalled
+	}
+
+	err = s.updateDeliveredMessages(broadcast, "recall", func(ctx context.Context, logs []*models.BroadcastLog) func(*models.BroadcastLog) error {
+		return func(entry *models.BroadcastLog) error {
+			var lastErr error
+			for _, messageID := range append([]int64(entry.AttachmentMessageIDs), *entry.MessageID) {
+				if err := s.telegramClient.DeleteMessage(*entry.ChatID, messageID); err != nil {
+					lastErr = err
+				}
+			}
+			return lastErr
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Broadcast recall initiated: id=%s", id)
+	return nil
+}
+
+// getDeliveredBroadcast возвращает рассылку, отправка которой завершена и которая не отозвана
+func (s *BroadcastService) getDeliveredBroadcast(ctx context.Context, id uuid.UUID) (*models.Broadcast, error) {
+	broadcast, err := s.broadcastRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch broadcast.Status {
+	case models.BroadcastStatusCompleted, models.BroadcastStatusFailed, models.BroadcastStatusCancelled:
+	default:
+		return nil, ErrBroadcastNotDelivered
+	}
+	if broadcast.RecalledAt != nil {
+		return nil, ErrBroadcastRecalled
+	}
+	return broadcast, nil
+}
+
+// updateDeliveredMessages в фоне применяет действие ко всем доставленным сообщениям рассылки.
+// prepare получает список доставок и возвращает функцию обработки одной доставки
+func (s *BroadcastService) updateDeliveredMessages(
+	broadcast *models.Broadcast,
+	action string,
+	prepare func(ctx context.Context, logs []*models.BroadcastLog) func(*models.BroadcastLog) error,
+) error {
+	key := action + ":" + broadcast.ID.String()
+
+	s.contextsMu.Lock()
+	if _, exists := s.activeContexts[key]; exists {
+		s.contextsMu.Unlock()
+		return ErrBroadcastUpdateInProgress
+	}
+	updateCtx, cancel := context.WithTimeout(context.Background(), 1*time.Hour)
+	s.activeContexts[key] = cancel
+	s.contextsMu.Unlock()
+
+	go func() {
+		defer func() {
+			cancel()
+			s.contextsMu.Lock()
+			delete(s.activeContexts, key)
+			s.contextsMu.Unlock()
+		}()
+
+		logs, err := s.broadcastRepo.GetDeliveredLogs(updateCtx, broadcast.ID)
+		if err != nil {
+			log.Printf("[ERROR] Failed to get delivered messages for broadcast %s: %v\n", broadcast.ID, err)
+			return
+		}
+
+		apply := prepare(updateCtx, logs)
+		var updated, failed int
+		for _, entry := range logs {
+			select {
+			case <-updateCtx.Done():
+				log.Printf("[INFO] Broadcast %s %s interrupted: updated=%d, failed=%d\n", broadcast.ID, action, updated, failed)
+				return
+			case <-s.rateLimiter.C:
+			}
+
+			if err := apply(entry); err != nil {
+				failed++
+				log.Printf("[WARN] Broadcast %s %s failed for user %s: %v\n", broadcast.ID, action, entry.UserID, err)
+				continue
+			}
+			updated++
+		}
+
+		log.Printf("[INFO] Broadcast %s %s completed: updated=%d, failed=%d\n", broadcast.ID, action, updated, failed)
+	}()
+
+	return nil
+}
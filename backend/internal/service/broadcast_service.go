@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"log"
+	"mime/multipart"
 	"net/url"
 	"path/filepath"
 	"strings"
@@ -28,26 +29,62 @@ var (
 	ErrTelegramNotConfigured = errors.New("telegram bot is not configured")
 	// ErrInvalidFilePath возвращается при попытке использовать небезопасный путь к файлу
 	ErrInvalidFilePath = errors.New("invalid file path: contains path traversal attempts or absolute paths")
+	// ErrBroadcastNotDelivered возвращается при попытке изменить рассылку, которая еще не отправлена
+	ErrBroadcastNotDelivered = errors.New("broadcast has not finished sending")
+	// ErrBroadcastRecalled возвращается при попытке изменить или повторно отозвать отозванную рассылку
+	ErrBroadcastRecalled = errors.New("broadcast messages have been recalled")
+	// ErrBroadcastUpdateInProgress возвращается если изменение доставленных сообщений уже выполняется
+	ErrBroadcastUpdateInProgress = errors.New("broadcast messages are already being updated")
 )
 
+const (
+	// broadcastSchedulerInterval период проверки наступивших отложенных рассылок
+	broadcastSchedulerInterval = 30 * time.Second
+	// broadcastSchedulerBatch максимум рассылок, запускаемых за один проход планировщика
+	broadcastSchedulerBatch = 20
+	// broadcastUploadDir директория вложений админских рассылок, загруженных до перехода на BlobStore
+	broadcastUploadDir = "./uploads/broadcasts"
+)
+
+// broadcastContent содержимое рассылки для одного получателя
+type broadcastContent struct {
+	text        string
+	options     *telegram.MessageOptions
+	attachments []*models.BroadcastAttachment
+}
+
+// broadcastDelivery идентификаторы уже доставленных получателю сообщений.
+// Сохраняется между повторными попытками, чтобы не отправлять вложения дважды
+type broadcastDelivery struct {
+	attachmentMessageIDs []int64
+	messageID            int64
+}
+
 // BroadcastService управляет массовыми рассылками через Telegram
 type BroadcastService struct {
 	broadcastRepo     repository.BroadcastRepository
 	broadcastListRepo repository.BroadcastListRepository
+	audienceRepo      *repository.BroadcastAudienceRepository
 	telegramUserRepo  repository.TelegramUserRepository
 	userRepo          repository.UserRepository
 	telegramClient    *telegram.Client
+	fileStorage       *FileStorageService
+	uploadDir         string
 	rateLimiter       *time.Ticker
 	mu                sync.Mutex
 	// Отслеживаем контексты запущенных горутин для возможности отмены
 	activeContexts map[string]context.CancelFunc
 	contextsMu     sync.RWMutex
+	// Планировщик отложенных рассылок
+	stopScheduler chan struct{}
+	schedulerDone chan struct{}
 }
 
 // NewBroadcastService создает новый BroadcastService с настройкой rate limiting
 func NewBroadcastService(
 	broadcastRepo repository.BroadcastRepository,
 	broadcastListRepo repository.BroadcastListRepository,
+	audienceRepo *repository.BroadcastAudienceRepository,
 	telegramUserRepo repository.TelegramUserRepository,
 	userRepo repository.UserRepository,
 	telegramClient *telegram.Client,
@@ -59,16 +96,29 @@ func NewBroadcastService(
 	return &BroadcastService{
 		broadcastRepo:     broadcastRepo,
 		broadcastListRepo: broadcastListRepo,
+		audienceRepo:      audienceRepo,
 		telegramUserRepo:  telegramUserRepo,
 		userRepo:          userRepo,
 		telegramClient:    telegramClient,
+		uploadDir:         broadcastUploadDir,
 		rateLimiter:       rateLimiter,
 		activeContexts:    make(map[string]context.CancelFunc),
 	}
 }
 
-// Shutdown останавливает rate limiter и отменяет все активные рассылки
+// SetFileStorage включает хранение вложений рассылок в BlobStore; без него рассылки с вложениями не создаются
+func (s *BroadcastService) SetFileStorage(fileStorage *FileStorageService) {
+	s.fileStorage = fileStorage
+}
+
+// Shutdown останавливает планировщик, rate limiter и отменяет все активные рассылки
 func (s *BroadcastService) Shutdown() {
+	if s.stopScheduler != nil {
+		close(s.stopScheduler)
+		<-s.schedulerDone
+		s.stopScheduler = nil
+	}
+
 	s.rateLimiter.Stop()
 
 	// Отменяем все активные контексты горутин рассылок
@@ -241,29 +291,109 @@ func (s *BroadcastService) CreateBroadcast(
 	message string,
 	createdBy uuid.UUID,
 ) (*models.Broadcast, error) {
-	// Валидация сообщения
-	if message == "" {
-		return nil, models.ErrInvalidBroadcastMessage
+	return s.CreateBroadcastFromRequest(ctx, &models.SendBroadcastRequest{
+		ListID:  &listID,
+		Message: message,
+	}, nil, createdBy)
+}
+
+// CreateBroadcastForUsers создает новую рассылку для конкретных пользователей (без списка рассылки)
+// Пользователи без привязки к Telegram будут пропущены при отправке, но рассылка будет создана
+func (s *BroadcastService) CreateBroadcastForUsers(
+	ctx context.Context,
+	userIDs []uuid.UUID,
+	message string,
+	createdBy uuid.UUID,
+) (*models.Broadcast, error) {
+	return s.CreateBroadcastFromRequest(ctx, &models.SendBroadcastRequest{
+		UserIDs: userIDs,
+		Message: message,
+	}, nil, createdBy)
+}
+
+// CreateBroadcastFromRequest создает рассылку по запросу администратора:
+// - текст берется из запроса или копируется из шаблона (template_id)
+// - получатели задаются списком, явными user_ids или сохраненной аудиторией (вычисляется при отправке)
+// - при указании scheduled_at рассылка получает статус "scheduled" и запускается планировщиком
+// - files сохраняются как вложения и отправляются перед текстом сообщения
+func (s *BroadcastService) CreateBroadcastFromRequest(
+	ctx context.Context,
+	req *models.SendBroadcastRequest,
+	files []*multipart.FileHeader,
+	createdBy uuid.UUID,
+) (*models.Broadcast, error) {
+	message := req.Message
+	if req.TemplateID != nil {
+		if s.audienceRepo == nil {
+			return nil, repository.ErrBroadcastTemplateNotFound
+		}
+		template, err := s.audienceRepo.GetTemplateByID(ctx, *req.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		// Копируем текст, чтобы последующее редактирование шаблона не меняло уже созданную рассылку
+		message = template.Body
+	}
+
+	// Валидация сообщения, разметки и кнопок
+	if err := models.ValidateBroadcastMessage(message); err != nil {
+		return nil, err
 	}
-	if len(message) > 4096 {
-		return nil, models.ErrBroadcastMessageTooLong
+	if err := models.ValidateBroadcastFormatting(message, req.ParseMode); err != nil {
+		return nil, err
+	}
+	if err := req.Buttons.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Проверяем существование списка
-	_, err := s.broadcastListRepo.GetByID(ctx, listID)
+	attachmentKinds, err := validateAttachmentFiles(files)
 	if err != nil {
-		if errors.Is(err, repository.ErrBroadcastListNotFound) {
-			return nil, repository.ErrBroadcastListNotFound
-		}
-		return nil, fmt.Errorf("failed to check broadcast list: %w", err)
+		return nil, err
 	}
 
-	// Создаем запись broadcast
 	broadcast := &models.Broadcast{
-		ListID:    &listID,
-		Message:   message,
-		Status:    models.BroadcastStatusPending,
-		CreatedBy: createdBy,
+		Message:    message,
+		Status:     models.BroadcastStatusPending,
+		CreatedBy:  createdBy,
+		TemplateID: req.TemplateID,
+		ParseMode:  req.ParseMode,
+		Buttons:    req.Buttons,
+	}
+
+	if req.ScheduledAt != nil {
+		if !req.ScheduledAt.After(time.Now()) {
+			return nil, models.ErrBroadcastScheduledInPast
+		}
+		broadcast.Status = models.BroadcastStatusScheduled
+		broadcast.ScheduledAt = req.ScheduledAt
+	}
+
+	switch {
+	case req.AudienceID != nil:
+		if s.audienceRepo == nil {
+			return nil, repository.ErrBroadcastAudienceNotFound
+		}
+		if _, err := s.audienceRepo.GetAudienceByID(ctx, *req.AudienceID); err != nil {
+			return nil, err
+		}
+		broadcast.AudienceID = req.AudienceID
+
+	case req.ListID != nil:
+		// Проверяем существование списка
+		if _, err := s.broadcastListRepo.GetByID(ctx, *req.ListID); err != nil {
+			if errors.Is(err, repository.ErrBroadcastListNotFound) {
+				return nil, repository.ErrBroadcastListNotFound
+			}
+			return nil, fmt.Errorf("failed to check broadcast list: %w", err)
+		}
+		broadcast.ListID = req.ListID
+
+	default:
+		listID, err := s.createDirectList(ctx, req.UserIDs, createdBy)
+		if err != nil {
+			return nil, err
+		}
+		broadcast.ListID = &listID
 	}
 
 	createdBroadcast, err := s.broadcastRepo.Create(ctx, broadcast)
@@ -271,29 +401,28 @@ func (s *BroadcastService) CreateBroadcast(
 		return nil, fmt.Errorf("failed to create broadcast: %w", err)
 	}
 
-	log.Printf("[INFO] Broadcast created: id=%s, list_id=%s", createdBroadcast.ID, listID)
+	if len(files) > 0 {
+		attachments, err := s.saveAttachments(ctx, createdBy, createdBroadcast.ID, files, attachmentKinds)
+		if err != nil {
+			// Без вложений рассылка неполная - не даем ее отправить
+			if statusErr := s.broadcastRepo.UpdateStatus(ctx, createdBroadcast.ID, models.BroadcastStatusFailed); statusErr != nil {
+				log.Printf("[ERROR] Failed to mark broadcast %s as failed: %v", createdBroadcast.ID, statusErr)
+			}
+			return nil, err
+		}
+		createdBroadcast.Attachments = attachments
+	}
+
+	log.Printf("[INFO] Broadcast created: id=%s, status=%s, attachments=%d", createdBroadcast.ID, createdBroadcast.Status, len(files))
 	return createdBroadcast, nil
 }
 
-// CreateBroadcastForUsers создает новую рассылку для конкретных пользователей (без списка рассылки)
-// Пользователи без привязки к Telegram будут пропущены при отправке, но рассылка будет создана
-func (s *BroadcastService) CreateBroadcastForUsers(
-	ctx context.Context,
-	userIDs []uuid.UUID,
-	message string,
-	createdBy uuid.UUID,
-) (*models.Broadcast, error) {
-	// Валидация сообщения
-	if message == "" {
-		return nil, models.ErrInvalidBroadcastMessage
-	}
-	if len(message) > 4096 {
-		return nil, models.ErrBroadcastMessageTooLong
-	}
-
+// createDirectList создает временный список рассылки для явно указанных пользователей,
+// пропуская несуществующих, удаленных и не привязавших Telegram
+func (s *BroadcastService) createDirectList(ctx context.Context, userIDs []uuid.UUID, createdBy uuid.UUID) (uuid.UUID, error) {
 	// Валидация user_ids
 	if len(userIDs) == 0 {
-		return nil, models.ErrInvalidBroadcastUsers
+		return uuid.Nil, models.ErrInvalidBroadcastUsers
 	}
 
 	// Фильтруем только пользователей с привязанным Telegram
@@ -306,7 +435,7 @@ func (s *BroadcastService) CreateBroadcastForUsers(
 				log.Printf("[WARN] User %s not found, skipping", userID)
 				continue
 			}
-			return nil, fmt.Errorf("failed to check user %s: %w", userID, err)
+			return uuid.Nil, fmt.Errorf("failed to check user %s: %w", userID, err)
 		}
 
 		// Проверяем что пользователь не удален
@@ -322,7 +451,7 @@ func (s *BroadcastService) CreateBroadcastForUsers(
 				log.Printf("[WARN] User %s is not linked to Telegram, skipping", userID)
 				continue
 			}
-			return nil, fmt.Errorf("failed to check telegram link for user %s: %w", userID, err)
+			return uuid.Nil, fmt.Errorf("failed to check telegram link for user %s: %w", userID, err)
 		}
 
 		validUserIDs = append(validUserIDs, userID)
@@ -330,7 +459,7 @@ func (s *BroadcastService) CreateBroadcastForUsers(
 
 	// Если ни один пользователь не имеет привязки к Telegram
 	if len(validUserIDs) == 0 {
-		return nil, ErrUsersNotLinkedToTelegram
+		return uuid.Nil, ErrUsersNotLinkedToTelegram
 	}
 
 	// Создаем временный список рассылки для этих пользователей
@@ -344,24 +473,11 @@ func (s *BroadcastService) CreateBroadcastForUsers(
 
 	createdList, err := s.broadcastListRepo.Create(ctx, tempList)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temporary broadcast list: %w", err)
-	}
-
-	// Создаем запись broadcast с временным списком
-	broadcast := &models.Broadcast{
-		ListID:    &createdList.ID,
-		Message:   message,
-		Status:    models.BroadcastStatusPending,
-		CreatedBy: createdBy,
-	}
-
-	createdBroadcast, err := s.broadcastRepo.Create(ctx, broadcast)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create broadcast: %w", err)
+		return uuid.Nil, fmt.Errorf("failed to create temporary broadcast list: %w", err)
 	}
 
-	log.Printf("[INFO] Broadcast created for %d users (out of %d requested)", len(validUserIDs), len(userIDs))
-	return createdBroadcast, nil
+	log.Printf("[INFO] Direct broadcast list created for %d users (out of %d requested)", len(validUserIDs), len(userIDs))
+	return createdList.ID, nil
 }
 
 // SendBroadcast запускает процесс отправки рассылки в горутине
@@ -385,20 +501,13 @@ func (s *BroadcastService) SendBroadcast(ctx context.Context, broadcastID uuid.U
 		return ErrBroadcastAlreadyInProgress
 	}
 
-	// Получаем список пользователей
-	if broadcast.ListID == nil {
-		return fmt.Errorf("broadcast has no list ID")
-	}
-
-	list, err := s.broadcastListRepo.GetByID(ctx, *broadcast.ListID)
+	// Определяем получателей: аудитория вычисляется в момент отправки, список берется как есть
+	userIDs, err := s.resolveRecipients(ctx, broadcast)
 	if err != nil {
-		if errors.Is(err, repository.ErrBroadcastListNotFound) {
-			return repository.ErrBroadcastListNotFound
-		}
-		return fmt.Errorf("failed to get broadcast list: %w", err)
+		return err
 	}
 
-	log.Printf("[INFO] SendBroadcast: got list %s with %d users", list.ID, len(list.UserIDs))
+	log.Printf("[INFO] SendBroadcast: resolved %d recipients for broadcast %s", len(userIDs), broadcast.ID)
 
 	// Обновляем статус на "in_progress"
 	if err := s.broadcastRepo.UpdateStatus(ctx, broadcastID, models.BroadcastStatusInProgress); err != nil {
@@ -415,21 +524,21 @@ func (s *BroadcastService) SendBroadcast(ctx context.Context, broadcastID uuid.U
 	s.contextsMu.Unlock()
 
 	// Запускаем отправку в горутине с отменяемым контекстом
-	go s.processBroadcast(broadcastCtx, broadcast, list)
+	go s.processBroadcast(broadcastCtx, broadcast, userIDs)
 
 	log.Printf("[INFO] Broadcast send initiated: id=%s", broadcastID)
 	return nil
 }
 
 // processBroadcast обрабатывает отправку рассылки с rate limiting
-func (s *BroadcastService) processBroadcast(ctx context.Context, broadcast *models.Broadcast, list *models.BroadcastList) {
+func (s *BroadcastService) processBroadcast(ctx context.Context, broadcast *models.Broadcast, userIDs []uuid.UUID) {
 	var sentCount, failedCount int64
 
 	// Добавляем timeout для всей операции рассылки (не более 1 часа)
 	processCtx, cancel := context.WithTimeout(ctx, 1*time.Hour)
 	defer cancel()
 
-	log.Printf("[INFO] processBroadcast started: broadcast_id=%s, list_id=%s, user_count=%d", broadcast.ID, list.ID, len(list.UserIDs))
+	log.Printf("[INFO] processBroadcast started: broadcast_id=%s, user_count=%d", broadcast.ID, len(userIDs))
 
 	// Убеждаемся что контекст очищен после завершения горутины
 	defer func() {
@@ -439,13 +548,30 @@ func (s *BroadcastService) processBroadcast(ctx context.Context, broadcast *mode
 	}()
 
 	// Получаем список подписанных пользователей одним запросом (исправление N+1)
-	subscribedUserIDs, err := s.telegramUserRepo.GetSubscribedUserIDs(processCtx, list.UserIDs)
+	subscribedUserIDs, err := s.telegramUserRepo.GetSubscribedUserIDs(processCtx, userIDs)
 	if err != nil {
 		log.Printf("[ERROR] Failed to get subscribed user IDs: %v\n", err)
 		// Завершаем рассылку с ошибкой
-		s.finalizeBroadcast(processCtx, broadcast.ID, 0, len(list.UserIDs), models.BroadcastStatusFailed)
+		s.finalizeBroadcast(processCtx, broadcast.ID, 0, len(userIDs), models.BroadcastStatusFailed)
+		return
+	}
+
+	// Для сообщений с подстановками заранее получаем данные всех получателей одним запросом
+	recipients, err := s.loadRecipients(processCtx, broadcast.Message, subscribedUserIDs)
+	if err != nil {
+		log.Printf("[ERROR] Failed to load broadcast recipients: %v\n", err)
+		s.finalizeBroadcast(processCtx, broadcast.ID, 0, len(subscribedUserIDs), models.BroadcastStatusFailed)
+		return
+	}
+
+	// Вложения загружаются в Telegram один раз, дальше переиспользуется file_id
+	attachments, err := s.broadcastRepo.GetAttachments(processCtx, broadcast.ID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to load broadcast attachments: %v\n", err)
+		s.finalizeBroadcast(processCtx, broadcast.ID, 0, len(subscribedUserIDs), models.BroadcastStatusFailed)
 		return
 	}
+	options := broadcastMessageOptions(broadcast)
 
 	// Создаем map для быстрой проверки подписки
 	subscribedMap := make(map[uuid.UUID]bool, len(subscribedUserIDs))
@@ -506,18 +632,28 @@ func (s *BroadcastService) processBroadcast(ctx context.Context, broadcast *mode
 			continue
 		}
 
+		content := &broadcastContent{
+			text:        broadcast.Message,
+			options:     options,
+			attachments: attachments,
+		}
+		if recipient, ok := recipients[userID]; ok {
+			content.text = models.RenderBroadcastMessage(broadcast.Message, recipient, broadcast.ParseMode)
+		}
+
 		// Ждем rate limiter
 		<-s.rateLimiter.C
 
 		// Отправляем сообщение с retry логикой с поддержкой идемпотентности
-		if err := s.sendMessageWithIdempotency(processCtx, broadcast.ID, userID, telegramUser.ChatID, telegramUser.TelegramID, broadcast.Message, 3); err != nil {
+		if err := s.sendMessageWithIdempotency(processCtx, broadcast.ID, userID, telegramUser.ChatID, telegramUser.TelegramID, content, 3); err != nil {
 			atomic.AddInt64(&failedCount, 1)
 
 			// Логируем ошибку
 			s.logBroadcastMessage(processCtx, broadcast.ID, userID, telegramUser.TelegramID, models.BroadcastLogStatusFailed, err.Error())
 
 			// Проверяем код ошибки
-			if telegramErr, ok := err.(*telegram.TelegramError); ok {
+			var telegramErr *telegram.TelegramError
+			if errors.As(err, &telegramErr) {
 				if telegramErr.ErrorCode == 403 {
 					// Бот заблокирован пользователем - отписываем от уведомлений
 					log.Printf("[INFO] Bot blocked by user %s, unsubscribing\n", userID)
@@ -552,6 +688,53 @@ func (s *BroadcastService) processBroadcast(ctx context.Context, broadcast *mode
 	log.Printf("[INFO] Broadcast %s completed: sent=%d, failed=%d, status=%s\n", broadcast.ID, sentVal, failedVal, status)
 }
 
+// resolveRecipients возвращает ID получателей рассылки:
+// для аудитории фильтр применяется к текущему состоянию БД, для списка берутся сохраненные user_ids
+func (s *BroadcastService) resolveRecipients(ctx context.Context, broadcast *models.Broadcast) ([]uuid.UUID, error) {
+	if broadcast.AudienceID != nil {
+		if s.audienceRepo == nil {
+			return nil, repository.ErrBroadcastAudienceNotFound
+		}
+		audience, err := s.audienceRepo.GetAudienceByID(ctx, *broadcast.AudienceID)
+		if err != nil {
+			return nil, err
+		}
+		return s.audienceRepo.ResolveAudience(ctx, audience.Filter, time.Now())
+	}
+
+	if broadcast.ListID == nil {
+		return nil, fmt.Errorf("broadcast has no list ID")
+	}
+
+	list, err := s.broadcastListRepo.GetByID(ctx, *broadcast.ListID)
+	if err != nil {
+		if errors.Is(err, repository.ErrBroadcastListNotFound) {
+			return nil, repository.ErrBroadcastListNotFound
+		}
+		return nil, fmt.Errorf("failed to get broadcast list: %w", err)
+	}
+
+	return list.UserIDs, nil
+}
+
+// loadRecipients загружает данные для подстановок; для сообщений без подстановок возвращает nil
+func (s *BroadcastService) loadRecipients(ctx context.Context, message string, userIDs []uuid.UUID) (map[uuid.UUID]*models.BroadcastRecipient, error) {
+	if s.audienceRepo == nil || !models.HasBroadcastPlaceholders(message) {
+		return nil, nil
+	}
+
+	recipients, err := s.audienceRepo.GetRecipients(ctx, userIDs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID]*models.BroadcastRecipient, len(recipients))
+	for _, recipient := range recipients {
+		result[recipient.UserID] = recipient
+	}
+	return result, nil
+}
+
 // sendMessageWithRetry отправляет сообщение с retry логикой для обработки 429
 func (s *BroadcastService) sendMessageWithRetry(ctx context.Context, chatID int64, message string, maxRetries int) error {
 	// Проверяем что Telegram клиент инициализирован
@@ -623,7 +806,7 @@ func (s *BroadcastService) sendMessageWithRetry(ctx context.Context, chatID int6
 // - userID: ID пользователя (для идемпотентности)
 // - chatID: Telegram chat ID для отправки
 // - telegramID: Telegram user ID для логирования
-// - message: текст сообщения
+// - content: текст, параметры форматирования и вложения
 // - maxRetries: максимальное количество попыток
 func (s *BroadcastService) sendMessageWithIdempotency(
 	ctx context.Context,
@@ -631,7 +814,7 @@ func (s *BroadcastService) sendMessageWithIdempotency(
 	userID uuid.UUID,
 	chatID int64,
 	telegramID int64,
-	message string,
+	content *broadcastContent,
 	maxRetries int,
 ) error {
 	// Проверяем что Telegram клиент инициализирован
@@ -672,6 +855,7 @@ func (s *BroadcastService) sendMessageWithIdempotency(
 
 	// Отправляем сообщение с retry логикой
 	var lastErr error
+	delivery := &broadcastDelivery{}
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		// Проверяем контекст перед каждой попыткой
 		select {
@@ -680,20 +864,25 @@ func (s *BroadcastService) sendMessageWithIdempotency(
 		default:
 		}
 
-		err := s.telegramClient.SendMessage(chatID, message)
+		err := s.deliverContent(ctx, chatID, content, delivery)
 		if err == nil {
 			// Успешно отправлено - обновляем статус
 			if updateErr := s.broadcastRepo.UpdateLogStatus(ctx, logID, models.BroadcastLogStatusSuccess, ""); updateErr != nil {
 				log.Printf("[WARN] Failed to update success status: %v\n", updateErr)
 				// Продолжаем несмотря на ошибку обновления (сообщение отправлено)
 			}
+			// Сохраняем message_id для последующего редактирования и отзыва
+			if updateErr := s.broadcastRepo.SetLogDelivery(ctx, logID, chatID, delivery.messageID, delivery.attachmentMessageIDs); updateErr != nil {
+				log.Printf("[WARN] Failed to save delivered message ids: %v\n", updateErr)
+			}
 			return nil
 		}
 
 		lastErr = err
 
 		// Проверяем тип ошибки
-		if telegramErr, ok := err.(*telegram.TelegramError); ok {
+		var telegramErr *telegram.TelegramError
+		if errors.As(err, &telegramErr) {
 			switch telegramErr.ErrorCode {
 			case 403:
 				// Forbidden - бот заблокирован, не ретраим
@@ -821,6 +1010,12 @@ func (s *BroadcastService) GetBroadcastDetails(ctx context.Context, id uuid.UUID
 		return nil, nil, fmt.Errorf("failed to get broadcast logs: %w", err)
 	}
 
+	attachments, err := s.broadcastRepo.GetAttachments(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get broadcast attachments: %w", err)
+	}
+	broadcast.Attachments = attachments
+
 	return broadcast, logs, nil
 }
 
@@ -835,8 +1030,8 @@ func (s *BroadcastService) CancelBroadcast(ctx context.Context, id uuid.UUID) er
 		return fmt.Errorf("failed to get broadcast: %w", err)
 	}
 
-	// Проверяем статус - можно отменить только pending или in_progress
-	if !broadcast.IsPending() && !broadcast.IsInProgress() {
+	// Проверяем статус - можно отменить только scheduled, pending или in_progress
+	if !broadcast.IsScheduled() && !broadcast.IsPending() && !broadcast.IsInProgress() {
 		return ErrBroadcastCannotCancel
 	}
 
@@ -857,6 +1052,148 @@ func (s *BroadcastService) CancelBroadcast(ctx context.Context, id uuid.UUID) er
 	return nil
 }
 
+// StartScheduler запускает фоновый планировщик отложенных рассылок
+func (s *BroadcastService) StartScheduler() {
+	s.stopScheduler = make(chan struct{})
+	s.schedulerDone = make(chan struct{})
+	go s.schedulerLoop()
+}
+
+// schedulerLoop периодически запускает наступившие отложенные рассылки
+func (s *BroadcastService) schedulerLoop() {
+	ticker := time.NewTicker(broadcastSchedulerInterval)
+	defer ticker.Stop()
+	defer close(s.schedulerDone)
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), broadcastSchedulerInterval)
+			s.RunScheduledBroadcasts(ctx, time.Now())
+			cancel()
+		case <-s.stopScheduler:
+			log.Println("[INFO] Broadcast scheduler shutting down")
+			return
+		}
+	}
+}
+
+// RunScheduledBroadcasts запускает отложенные рассылки, время которых наступило.
+// Рассылка переводится в pending атомарно, поэтому повторный запуск невозможен;
+// если отправку начать не удалось, рассылка помечается как failed
+func (s *BroadcastService) RunScheduledBroadcasts(ctx context.Context, now time.Time) {
+	ids, err := s.broadcastRepo.ClaimDueScheduled(ctx, now, broadcastSchedulerBatch)
+	if err != nil {
+		log.Printf("[ERROR] Failed to claim scheduled broadcasts: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := s.SendBroadcast(ctx, id); err != nil {
+			log.Printf("[ERROR] Failed to start scheduled broadcast %s: %v", id, err)
+			if updateErr := s.broadcastRepo.UpdateStatus(ctx, id, models.BroadcastStatusFailed); updateErr != nil {
+				log.Printf("[ERROR] Failed to mark scheduled broadcast %s as failed: %v", id, updateErr)
+			}
+			continue
+		}
+		log.Printf("[INFO] Scheduled broadcast started: id=%s", id)
+	}
+}
+
+// CreateTemplate создает шаблон рассылки
+func (s *BroadcastService) CreateTemplate(ctx context.Context, req *models.SaveBroadcastTemplateRequest, createdBy uuid.UUID) (*models.BroadcastTemplate, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	template := &models.BroadcastTemplate{
+		Name:      req.Name,
+		Body:      req.Body,
+		CreatedBy: createdBy,
+	}
+	if err := s.audienceRepo.CreateTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[INFO] Broadcast template created: id=%s, name=%s", template.ID, template.Name)
+	return template, nil
+}
+
+// GetTemplates получает все шаблоны рассылок
+func (s *BroadcastService) GetTemplates(ctx context.Context) ([]*models.BroadcastTemplate, error) {
+	return s.audienceRepo.ListTemplates(ctx)
+}
+
+// UpdateTemplate обновляет шаблон рассылки (уже созданные рассылки хранят свою копию текста)
+func (s *BroadcastService) UpdateTemplate(ctx context.Context, id uuid.UUID, req *models.SaveBroadcastTemplateRequest) (*models.BroadcastTemplate, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.audienceRepo.UpdateTemplate(ctx, id, req.Name, req.Body); err != nil {
+		return nil, err
+	}
+	return s.audienceRepo.GetTemplateByID(ctx, id)
+}
+
+// DeleteTemplate выполняет мягкое удаление шаблона рассылки
+func (s *BroadcastService) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	return s.audienceRepo.DeleteTemplate(ctx, id)
+}
+
+// CreateAudience создает сохраненную аудиторию
+func (s *BroadcastService) CreateAudience(ctx context.Context, req *models.SaveBroadcastAudienceRequest, createdBy uuid.UUID) (*models.BroadcastAudience, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	audience := &models.BroadcastAudience{
+		Name:        req.Name,
+		Description: req.Description,
+		Filter:      req.Filter,
+		CreatedBy:   createdBy,
+	}
+	if err := s.audienceRepo.CreateAudience(ctx, audience); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[INFO] Broadcast audience created: id=%s, name=%s", audience.ID, audience.Name)
+	return audience, nil
+}
+
+// GetAudiences получает все сохраненные аудитории
+func (s *BroadcastService) GetAudiences(ctx context.Context) ([]*models.BroadcastAudience, error) {
+	return s.audienceRepo.ListAudiences(ctx)
+}
+
+// UpdateAudience обновляет сохраненную аудиторию
+func (s *BroadcastService) UpdateAudience(ctx context.Context, id uuid.UUID, req *models.SaveBroadcastAudienceRequest) (*models.BroadcastAudience, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.audienceRepo.UpdateAudience(ctx, id, req.Name, req.Description, req.Filter); err != nil {
+		return nil, err
+	}
+	return s.audienceRepo.GetAudienceByID(ctx, id)
+}
+
+// DeleteAudience выполняет мягкое удаление аудитории
+func (s *BroadcastService) DeleteAudience(ctx context.Context, id uuid.UUID) error {
+	return s.audienceRepo.DeleteAudience(ctx, id)
+}
+
+// PreviewAudience возвращает количество получателей, подходящих под фильтр прямо сейчас
+func (s *BroadcastService) PreviewAudience(ctx context.Context, filter models.AudienceFilter) (int, error) {
+	if err := filter.Validate(); err != nil {
+		return 0, err
+	}
+
+	userIDs, err := s.audienceRepo.ResolveAudience(ctx, filter, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return len(userIDs), nil
+}
+
 // ValidateBroadcastFilePath проверяет безопасность пути к файлу для рассылок
 // Предотвращает path traversal атаки и доступ к файлам вне разрешенной директории
 // Параметры:
@@ -10,6 +10,7 @@ import (
 	"tutoring-platform/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -84,6 +85,55 @@ func (m *MockBroadcastRepository) UpdateLogStatus(ctx context.Context, logID uui
 	return args.Error(0)
 }
 
+func (m *MockBroadcastRepository) ClaimDueScheduled(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockBroadcastRepository) CreateAttachment(ctx context.Context, attachment *models.BroadcastAttachment) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+func (m *MockBroadcastRepository) GetAttachments(ctx context.Context, broadcastID uuid.UUID) ([]*models.BroadcastAttachment, error) {
+	args := m.Called(ctx, broadcastID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.BroadcastAttachment), args.Error(1)
+}
+
+func (m *MockBroadcastRepository) SetAttachmentFileID(ctx context.Context, id uuid.UUID, fileID string) error {
+	args := m.Called(ctx, id, fileID)
+	return args.Error(0)
+}
+
+func (m *MockBroadcastRepository) SetLogDelivery(ctx context.Context, logID uuid.UUID, chatID, messageID int64, attachmentMessageIDs []int64) error {
+	args := m.Called(ctx, logID, chatID, messageID, attachmentMessageIDs)
+	return args.Error(0)
+}
+
+func (m *MockBroadcastRepository) GetDeliveredLogs(ctx context.Context, broadcastID uuid.UUID) ([]*models.BroadcastLog, error) {
+	args := m.Called(ctx, broadcastID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.BroadcastLog), args.Error(1)
+}
+
+func (m *MockBroadcastRepository) UpdateMessage(ctx context.Context, id uuid.UUID, message string) error {
+	args := m.Called(ctx, id, message)
+	return args.Error(0)
+}
+
+func (m *MockBroadcastRepository) MarkRecalled(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
 // MockBroadcastListRepository - мок для репозитория списков рассылки
 type MockBroadcastListRepository struct {
 	mock.Mock
@@ -138,6 +188,11 @@ func (m *MockUserRepository) Create(ctx context.Context, user *models.User) erro
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	args := m.Called(ctx, tx, user)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -375,6 +430,7 @@ func TestBroadcastService_CreateBroadcastList_Success(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -410,6 +466,7 @@ func TestBroadcastService_CreateBroadcastList_InvalidName(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -439,6 +496,7 @@ func TestBroadcastService_CreateBroadcastList_NoUsers(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -481,6 +539,7 @@ func TestBroadcastService_CreateBroadcastList_UserNotLinked(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -535,6 +594,7 @@ func TestBroadcastService_CreateBroadcast_Success(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -567,6 +627,7 @@ func TestBroadcastService_CreateBroadcast_EmptyMessage(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -596,6 +657,7 @@ func TestBroadcastService_CreateBroadcast_MessageTooLong(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -627,6 +689,7 @@ func TestBroadcastService_CreateBroadcast_ListNotFound(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -655,6 +718,7 @@ func TestBroadcastService_SendBroadcast_Success(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil, // Telegram client == nil
@@ -681,6 +745,7 @@ func TestBroadcastService_SendBroadcast_NotPending(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil, // Telegram не настроен
@@ -706,6 +771,7 @@ func TestBroadcastService_SendBroadcast_NotFound(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil, // Telegram не настроен
@@ -742,6 +808,7 @@ func TestBroadcastService_CancelBroadcast_Success(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -776,6 +843,7 @@ func TestBroadcastService_CancelBroadcast_CannotCancel(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -805,6 +873,7 @@ func TestBroadcastService_GetBroadcasts_PaginationDefaults(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -835,6 +904,7 @@ func TestBroadcastService_GetBroadcasts_PaginationMaxLimit(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -865,6 +935,7 @@ func TestBroadcastService_GetBroadcasts_PaginationNegativeOffset(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -906,6 +977,7 @@ func TestBroadcastService_GetBroadcasts_ValidPagination(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -1255,6 +1327,7 @@ func TestBroadcastService_ContextStorageAndCleanup(t *testing.T) {
 			svc := NewBroadcastService(
 				mockBroadcastRepo,
 				mockBroadcastListRepo,
+				nil,
 				mockTelegramUserRepo,
 				mockUserRepo,
 				nil,
@@ -1270,6 +1343,7 @@ func TestBroadcastService_ShutdownCancelsAllContexts(t *testing.T) {
 	svc := NewBroadcastService(
 		new(MockBroadcastRepository),
 		new(MockBroadcastListRepository),
+		nil,
 		new(MockTelegramUserRepository),
 		new(MockUserRepository),
 		nil,
@@ -1333,6 +1407,7 @@ func TestBroadcastService_CancelBroadcastRemovesContext(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -1386,6 +1461,7 @@ func TestBroadcastService_ContextCancellation_RequestContextPropagates(t *testin
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -1450,6 +1526,7 @@ func TestSendMessageWithRetry_ContextCancellation(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -1484,6 +1561,7 @@ func TestSendMessageWithRetry_ContextCancellationDuringBackoff(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -1541,7 +1619,8 @@ func TestBroadcastService_ProcessBroadcast_ConcurrentCounterUpdates(t *testing.T
 		CreatedBy: uuid.New(),
 	}
 
-	// Mock получение telegram пользователей - все неудачны (GetByUserID возвращает ошибку)
+	// Все пользователи подписаны, но получение telegram пользователей неудачно (GetByUserID возвращает ошибку)
+	mockTelegramUserRepo.On("GetSubscribedUserIDs", mock.Anything, userIDs).Return(userIDs, nil)
 	for _, userID := range userIDs {
 		mockTelegramUserRepo.On("GetByUserID", mock.Anything, userID).Return(nil, repository.ErrTelegramUserNotFound)
 		// Mock идемпотентности - ни один не доставлен еще
@@ -1550,6 +1629,7 @@ func TestBroadcastService_ProcessBroadcast_ConcurrentCounterUpdates(t *testing.T
 
 	// Mock логирования
 	mockBroadcastRepo.On("CreateLog", mock.Anything, mock.Anything).Return(nil)
+	mockBroadcastRepo.On("GetAttachments", mock.Anything, broadcastID).Return([]*models.BroadcastAttachment{}, nil)
 
 	// Mock финализации - все 100 считаются failed
 	mockBroadcastRepo.On("UpdateCounts", mock.Anything, broadcastID, 0, 100).Return(nil)
@@ -1558,6 +1638,7 @@ func TestBroadcastService_ProcessBroadcast_ConcurrentCounterUpdates(t *testing.T
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -1565,7 +1646,7 @@ func TestBroadcastService_ProcessBroadcast_ConcurrentCounterUpdates(t *testing.T
 	defer svc.Shutdown()
 
 	// Запускаем processBroadcast
-	svc.processBroadcast(ctx, broadcast, list)
+	svc.processBroadcast(ctx, broadcast, list.UserIDs)
 
 	// Проверяем что UpdateCounts была вызвана с правильными значениями
 	mockBroadcastRepo.AssertCalled(t, "UpdateCounts", mock.Anything, broadcastID, 0, 100)
@@ -1607,6 +1688,7 @@ func TestBroadcastService_ProcessBroadcast_AtomicCounterAccuracy(t *testing.T) {
 	}
 
 	// Все пользователи не привязаны к Telegram
+	mockTelegramUserRepo.On("GetSubscribedUserIDs", mock.Anything, userIDs).Return(userIDs, nil)
 	for _, userID := range userIDs {
 		mockTelegramUserRepo.On("GetByUserID", mock.Anything, userID).Return(nil, repository.ErrTelegramUserNotFound)
 		// Mock идемпотентности
@@ -1615,6 +1697,7 @@ func TestBroadcastService_ProcessBroadcast_AtomicCounterAccuracy(t *testing.T) {
 
 	// Mock логирования
 	mockBroadcastRepo.On("CreateLog", mock.Anything, mock.Anything).Return(nil)
+	mockBroadcastRepo.On("GetAttachments", mock.Anything, broadcastID).Return([]*models.BroadcastAttachment{}, nil)
 
 	// Ожидаем что все 10 будут counted как failed (failedCount = 10)
 	mockBroadcastRepo.On("UpdateCounts", mock.Anything, broadcastID, 0, 10).Return(nil)
@@ -1623,6 +1706,7 @@ func TestBroadcastService_ProcessBroadcast_AtomicCounterAccuracy(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -1630,7 +1714,7 @@ func TestBroadcastService_ProcessBroadcast_AtomicCounterAccuracy(t *testing.T) {
 	defer svc.Shutdown()
 
 	// Запускаем processBroadcast
-	svc.processBroadcast(ctx, broadcast, list)
+	svc.processBroadcast(ctx, broadcast, list.UserIDs)
 
 	// Проверяем что counts корректны
 	mockBroadcastRepo.AssertCalled(t, "UpdateCounts", mock.Anything, broadcastID, 0, 10)
@@ -1657,6 +1741,7 @@ func TestBroadcastService_SendMessageWithIdempotency_NoClientError(t *testing.T)
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil, // No telegram client
@@ -1664,7 +1749,7 @@ func TestBroadcastService_SendMessageWithIdempotency_NoClientError(t *testing.T)
 	defer svc.Shutdown()
 
 	// Отправляем сообщение
-	err := svc.sendMessageWithIdempotency(ctx, broadcastID, userID, chatID, telegramID, message, 3)
+	err := svc.sendMessageWithIdempotency(ctx, broadcastID, userID, chatID, telegramID, &broadcastContent{text: message}, 3)
 
 	// Должна быть ошибка что Telegram не настроен
 	assert.Error(t, err)
@@ -1689,6 +1774,7 @@ func TestBroadcastService_HasSuccessfulDelivery_ChecksDuplicates(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -1733,6 +1819,7 @@ func TestBroadcastService_SendMessageWithIdempotency_AlreadyDeliveredError(t *te
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil, // nil client causes early error but still checks log first
@@ -1741,7 +1828,7 @@ func TestBroadcastService_SendMessageWithIdempotency_AlreadyDeliveredError(t *te
 
 	// Пытаемся отправить еще раз
 	// Должна быть ошибка что Telegram не настроен (проверяется раньше чем проверка лога)
-	err := svc.sendMessageWithIdempotency(ctx, broadcastID, userID, chatID, telegramID, message, 3)
+	err := svc.sendMessageWithIdempotency(ctx, broadcastID, userID, chatID, telegramID, &broadcastContent{text: message}, 3)
 
 	// Должна быть ошибка что Telegram не настроен
 	assert.Error(t, err)
@@ -1780,10 +1867,12 @@ func TestBroadcastService_ProcessBroadcast_ChecksIdempotency(t *testing.T) {
 	mockBroadcastRepo.On("HasSuccessfulDelivery", mock.Anything, broadcastID, userID).Return(false, nil)
 
 	// Пользователь не привязан к Telegram
+	mockTelegramUserRepo.On("GetSubscribedUserIDs", mock.Anything, []uuid.UUID{userID}).Return([]uuid.UUID{userID}, nil)
 	mockTelegramUserRepo.On("GetByUserID", mock.Anything, userID).Return(nil, repository.ErrTelegramUserNotFound)
 
 	// Mock логирования
 	mockBroadcastRepo.On("CreateLog", mock.Anything, mock.Anything).Return(nil)
+	mockBroadcastRepo.On("GetAttachments", mock.Anything, broadcastID).Return([]*models.BroadcastAttachment{}, nil)
 
 	// Финализация
 	mockBroadcastRepo.On("UpdateCounts", mock.Anything, broadcastID, 0, 1).Return(nil)
@@ -1792,6 +1881,7 @@ func TestBroadcastService_ProcessBroadcast_ChecksIdempotency(t *testing.T) {
 	svc := NewBroadcastService(
 		mockBroadcastRepo,
 		mockBroadcastListRepo,
+		nil,
 		mockTelegramUserRepo,
 		mockUserRepo,
 		nil,
@@ -1799,7 +1889,7 @@ func TestBroadcastService_ProcessBroadcast_ChecksIdempotency(t *testing.T) {
 	defer svc.Shutdown()
 
 	// Запускаем processBroadcast
-	svc.processBroadcast(ctx, broadcast, list)
+	svc.processBroadcast(ctx, broadcast, list.UserIDs)
 
 	// Проверяем что HasSuccessfulDelivery был вызван для проверки идемпотентности
 	mockBroadcastRepo.AssertCalled(t, "HasSuccessfulDelivery", mock.Anything, broadcastID, userID)
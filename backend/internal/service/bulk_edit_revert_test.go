@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBulkEditLessonRepo хранит занятия и активные записи в памяти
+// Неиспользуемые методы bulkEditLessonRepository не реализованы
+type fakeBulkEditLessonRepo struct {
+	bulkEditLessonRepository
+	lessons        map[uuid.UUID]*models.Lesson
+	students       map[uuid.UUID][]uuid.UUID
+	teacherUpdates int
+}
+
+func (r *fakeBulkEditLessonRepo) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.Lesson, error) {
+	lesson, ok := r.lessons[id]
+	if !ok {
+		return nil, repository.ErrLessonNotFound
+	}
+	return lesson, nil
+}
+
+func (r *fakeBulkEditLessonRepo) GetBookingsByLessonTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) ([]*models.Booking, error) {
+	bookings := make([]*models.Booking, 0, len(r.students[lessonID]))
+	for _, studentID := range r.students[lessonID] {
+		bookings = append(bookings, &models.Booking{ID: uuid.New(), LessonID: lessonID, StudentID: studentID})
+	}
+	return bookings, nil
+}
+
+func (r *fakeBulkEditLessonRepo) UpdateTeacherTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, newTeacherID uuid.UUID) error {
+	r.lessons[lessonID].TeacherID = newTeacherID
+	r.teacherUpdates++
+	return nil
+}
+
+// fakeBulkEditModificationRepo хранит одну модификацию и ее снимки
+type fakeBulkEditModificationRepo struct {
+	bulkEditModificationRepository
+	modification *models.LessonModification
+	snapshots    []*models.LessonModificationSnapshot
+	reverted     bool
+}
+
+func (r *fakeBulkEditModificationRepo) GetModificationByIDForUpdateTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.LessonModification, error) {
+	return r.modification, nil
+}
+
+func (r *fakeBulkEditModificationRepo) GetSnapshotsTx(ctx context.Context, tx pgx.Tx, modificationID uuid.UUID) ([]*models.LessonModificationSnapshot, error) {
+	return r.snapshots, nil
+}
+
+func (r *fakeBulkEditModificationRepo) MarkRevertedTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, revertedByID uuid.UUID) error {
+	r.reverted = true
+	return nil
+}
+
+// newRevertFixture создает модификацию change_teacher над одним будущим занятием
+func newRevertFixture() (*fakeTxBeginner, *fakeBulkEditLessonRepo, *fakeBulkEditModificationRepo, *BulkEditService, *models.Lesson, uuid.UUID) {
+	oldTeacher := uuid.New()
+	newTeacher := uuid.New()
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Minute)
+
+	lesson := &models.Lesson{
+		ID:          uuid.New(),
+		TeacherID:   newTeacher,
+		StartTime:   start,
+		EndTime:     start.Add(2 * time.Hour),
+		MaxStudents: 4,
+	}
+	before := models.LessonSnapshot{TeacherID: oldTeacher, StartTime: lesson.StartTime, EndTime: lesson.EndTime, MaxStudents: 4, StudentIDs: []uuid.UUID{}}
+	after := before
+	after.TeacherID = newTeacher
+
+	modification := &models.LessonModification{ID: uuid.New(), ModificationType: "change_teacher"}
+	pool := &fakeTxBeginner{}
+	lessons := &fakeBulkEditLessonRepo{
+		lessons:  map[uuid.UUID]*models.Lesson{lesson.ID: lesson},
+		students: map[uuid.UUID][]uuid.UUID{},
+	}
+	modifications := &fakeBulkEditModificationRepo{
+		modification: modification,
+		snapshots: []*models.LessonModificationSnapshot{{
+			ModificationID: modification.ID,
+			LessonID:       lesson.ID,
+			Before:         before,
+			After:          after,
+		}},
+	}
+
+	svc := NewBulkEditService(pool, lessons, modifications, nil, nil)
+	return pool, lessons, modifications, svc, lesson, oldTeacher
+}
+
+func TestRevertModification_RestoresUnchangedLessons(t *testing.T) {
+	pool, lessons, modifications, svc, lesson, oldTeacher := newRevertFixture()
+
+	result, err := svc.RevertModification(context.Background(), uuid.New(), modifications.modification.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.RevertedLessons)
+	assert.Equal(t, oldTeacher, lesson.TeacherID, "teacher should be restored")
+	assert.True(t, modifications.reverted, "modification should be marked as reverted")
+	assert.True(t, pool.last().committed)
+	assert.Equal(t, 1, lessons.teacherUpdates)
+}
+
+func TestRevertModification_DetectsConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(lessons *fakeBulkEditLessonRepo, lesson *models.Lesson)
+		reasons []string
+	}{
+		{
+			name: "teacher changed after modification",
+			mutate: func(lessons *fakeBulkEditLessonRepo, lesson *models.Lesson) {
+				lesson.TeacherID = uuid.New()
+			},
+			reasons: []string{"teacher changed"},
+		},
+		{
+			name: "student enrolled after modification",
+			mutate: func(lessons *fakeBulkEditLessonRepo, lesson *models.Lesson) {
+				lessons.students[lesson.ID] = []uuid.UUID{uuid.New()}
+			},
+			reasons: []string{"enrolled students changed"},
+		},
+		{
+			name: "lesson already started",
+			mutate: func(lessons *fakeBulkEditLessonRepo, lesson *models.Lesson) {
+				lesson.StartTime = time.Now().Add(-time.Hour)
+				lesson.EndTime = time.Now().Add(time.Hour)
+			},
+			reasons: []string{"time changed", "lesson already started"},
+		},
+		{
+			name: "lesson deleted",
+			mutate: func(lessons *fakeBulkEditLessonRepo, lesson *models.Lesson) {
+				delete(lessons.lessons, lesson.ID)
+			},
+			reasons: []string{"lesson deleted"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, lessons, modifications, svc, lesson, _ := newRevertFixture()
+			tt.mutate(lessons, lesson)
+
+			result, err := svc.RevertModification(context.Background(), uuid.New(), modifications.modification.ID)
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.True(t, errors.Is(err, models.ErrModificationRevertConflict))
+
+			var conflictErr *RevertConflictError
+			require.True(t, errors.As(err, &conflictErr))
+			require.Len(t, conflictErr.Conflicts, 1)
+			assert.Equal(t, lesson.ID, conflictErr.Conflicts[0].LessonID)
+			assert.Equal(t, tt.reasons, conflictErr.Conflicts[0].Reasons)
+
+			assert.Zero(t, lessons.teacherUpdates, "nothing should be restored on conflict")
+			assert.False(t, modifications.reverted)
+			assert.False(t, pool.last().committed)
+			assert.True(t, pool.last().rolledBack)
+		})
+	}
+}
+
+func TestRevertModification_AlreadyReverted(t *testing.T) {
+	pool, _, modifications, svc, _, _ := newRevertFixture()
+	revertedAt := time.Now()
+	modifications.modification.RevertedAt = &revertedAt
+
+	_, err := svc.RevertModification(context.Background(), uuid.New(), modifications.modification.ID)
+	assert.ErrorIs(t, err, models.ErrModificationAlreadyReverted)
+	assert.False(t, pool.last().committed)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,25 +11,63 @@ import (
 	"tutoring-platform/internal/repository"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
 )
 
 // BulkEditService handles bulk editing operations on lessons and subsequent occurrences
 type BulkEditService struct {
-	pool                   *pgxpool.Pool
-	lessonRepo             *repository.LessonRepository
-	lessonModificationRepo *repository.LessonModificationRepository
+	pool                   txBeginner
+	lessonRepo             bulkEditLessonRepository
+	lessonModificationRepo bulkEditModificationRepository
 	userRepo               repository.UserRepository
-	creditRepo             *repository.CreditRepository
+	creditRepo             bulkEditCreditRepository
+}
+
+// bulkEditLessonRepository is the part of LessonRepository used by BulkEditService
+type bulkEditLessonRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Lesson, error)
+	GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.Lesson, error)
+	GetLessonsByTimePattern(ctx context.Context, teacherID uuid.UUID, dayOfWeek int, hour int, minute int, afterDate time.Time) ([]*models.Lesson, error)
+	GetOverlappingTeacherLessons(ctx context.Context, teacherID uuid.UUID, start, end time.Time) ([]*models.Lesson, error)
+	GetLessonBookingsForLessons(ctx context.Context, lessonIDs []uuid.UUID) (map[uuid.UUID][]models.BookingInfo, error)
+	GetBookingsByLessonTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) ([]*models.Booking, error)
+	IsStudentBookedForLessonTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, studentID uuid.UUID) (bool, error)
+	CreateBookingTx(ctx context.Context, tx pgx.Tx, booking *models.Booking) error
+	CancelBookingTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, studentID uuid.UUID) error
+	IncrementStudents(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) error
+	DecrementStudents(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) error
+	UpdateTeacherTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, newTeacherID uuid.UUID) error
+	UpdateTimeTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, newStartTime time.Time, newEndTime time.Time) error
+	UpdateMaxStudentsTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, newMaxStudents int) error
+}
+
+// bulkEditModificationRepository is the part of LessonModificationRepository used by BulkEditService
+type bulkEditModificationRepository interface {
+	GetModificationByID(ctx context.Context, id uuid.UUID) (*models.LessonModification, error)
+	GetModificationByIDForUpdateTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.LessonModification, error)
+	GetSnapshots(ctx context.Context, modificationID uuid.UUID) ([]*models.LessonModificationSnapshot, error)
+	GetSnapshotsTx(ctx context.Context, tx pgx.Tx, modificationID uuid.UUID) ([]*models.LessonModificationSnapshot, error)
+	LogModificationTx(ctx context.Context, tx pgx.Tx, modification *models.LessonModification) error
+	SaveSnapshotsTx(ctx context.Context, tx pgx.Tx, snapshots []*models.LessonModificationSnapshot) error
+	MarkRevertedTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, revertedByID uuid.UUID) error
+}
+
+// bulkEditCreditRepository is the part of CreditRepository used by BulkEditService
+type bulkEditCreditRepository interface {
+	GetBalance(ctx context.Context, userID uuid.UUID) (*models.Credit, error)
+	GetBalanceForUpdate(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (*models.Credit, error)
+	UpdateBalance(ctx context.Context, tx pgx.Tx, userID uuid.UUID, newBalance int) error
+	CreateTransaction(ctx context.Context, tx pgx.Tx, transaction *models.CreditTransaction) error
 }
 
 // NewBulkEditService creates a new BulkEditService
 func NewBulkEditService(
-	pool *pgxpool.Pool,
-	lessonRepo *repository.LessonRepository,
-	lessonModificationRepo *repository.LessonModificationRepository,
+	pool txBeginner,
+	lessonRepo bulkEditLessonRepository,
+	lessonModificationRepo bulkEditModificationRepository,
 	userRepo repository.UserRepository,
-	creditRepo *repository.CreditRepository,
+	creditRepo bulkEditCreditRepository,
 ) *BulkEditService {
 	return &BulkEditService{
 		pool:                   pool,
@@ -46,18 +85,24 @@ func (s *BulkEditService) ApplyToAllSubsequent(ctx context.Context, adminID uuid
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	// Confirmation of a dry run: each operation verifies the target set inside its transaction
+	var preview *models.ApplyToAllSubsequentRequest
+	if req.PreviewToken != "" {
+		preview = req
+	}
+
 	// Route to appropriate handler based on modification type
 	switch req.ModificationType {
 	case "add_student":
-		return s.AddStudentToAllSubsequent(ctx, adminID, req.LessonID, *req.StudentID)
+		return s.AddStudentToAllSubsequent(ctx, adminID, req.LessonID, *req.StudentID, preview)
 	case "remove_student":
-		return s.RemoveStudentFromAllSubsequent(ctx, adminID, req.LessonID, *req.StudentID)
+		return s.RemoveStudentFromAllSubsequent(ctx, adminID, req.LessonID, *req.StudentID, preview)
 	case "change_teacher":
-		return s.ChangeTeacherForAllSubsequent(ctx, adminID, req.LessonID, *req.TeacherID)
+		return s.ChangeTeacherForAllSubsequent(ctx, adminID, req.LessonID, *req.TeacherID, preview)
 	case "change_time":
-		return s.ChangeTimeForAllSubsequent(ctx, adminID, req.LessonID, *req.NewStartTime)
+		return s.ChangeTimeForAllSubsequent(ctx, adminID, req.LessonID, *req.NewStartTime, preview)
 	case "change_capacity":
-		return s.ChangeCapacityForAllSubsequent(ctx, adminID, req.LessonID, *req.NewMaxStudents)
+		return s.ChangeCapacityForAllSubsequent(ctx, adminID, req.LessonID, *req.NewMaxStudents, preview)
 	default:
 		return nil, fmt.Errorf("unsupported modification type: %s", req.ModificationType)
 	}
@@ -93,7 +138,7 @@ func (s *BulkEditService) FindSubsequentMatchingLessons(ctx context.Context, sou
 }
 
 // AddStudentToAllSubsequent adds a student to the source lesson and all subsequent matching lessons
-func (s *BulkEditService) AddStudentToAllSubsequent(ctx context.Context, adminID uuid.UUID, sourceLessonID uuid.UUID, studentID uuid.UUID) (*models.LessonModification, error) {
+func (s *BulkEditService) AddStudentToAllSubsequent(ctx context.Context, adminID uuid.UUID, sourceLessonID uuid.UUID, studentID uuid.UUID, preview *models.ApplyToAllSubsequentRequest) (*models.LessonModification, error) {
 	// Validate student exists and has student role
 	student, err := s.userRepo.GetByID(ctx, studentID)
 	if err != nil {
@@ -134,6 +179,13 @@ func (s *BulkEditService) AddStudentToAllSubsequent(ctx context.Context, adminID
 
 	// Pre-check: Validate all lessons and student not already booked
 	allLessons := append([]*models.Lesson{sourceLesson}, futureMatches...)
+	if err := verifyPreviewToken(preview, allLessons); err != nil {
+		return nil, err
+	}
+	before, err := s.captureSnapshotsTx(ctx, tx, allLessons)
+	if err != nil {
+		return nil, err
+	}
 	for _, lesson := range allLessons {
 		if lesson.CurrentStudents >= lesson.MaxStudents {
 			return nil, fmt.Errorf("lesson %s (at %s) is full, cannot add student", lesson.ID, lesson.StartTime.Format("2006-01-02 15:04"))
@@ -166,6 +218,7 @@ func (s *BulkEditService) AddStudentToAllSubsequent(ctx context.Context, adminID
 
 	// Add student to all lessons (source + future matches)
 	now := time.Now()
+	movements := make(map[uuid.UUID][]models.CreditMovement, len(allLessons))
 	for _, lesson := range allLessons {
 		booking := &models.Booking{
 			ID:        uuid.New(),
@@ -202,6 +255,7 @@ func (s *BulkEditService) AddStudentToAllSubsequent(ctx context.Context, adminID
 		if err := s.creditRepo.CreateTransaction(ctx, tx, creditTx); err != nil {
 			return nil, fmt.Errorf("failed to record credit transaction: %w", err)
 		}
+		movements[lesson.ID] = []models.CreditMovement{{StudentID: studentID, Amount: -1}}
 	}
 
 	// Create modification record for audit trail
@@ -226,6 +280,10 @@ func (s *BulkEditService) AddStudentToAllSubsequent(ctx context.Context, adminID
 		return nil, fmt.Errorf("failed to log modification: %w", err)
 	}
 
+	if err := s.saveSnapshotsTx(ctx, tx, modification.ID, before, movements); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -235,7 +293,7 @@ func (s *BulkEditService) AddStudentToAllSubsequent(ctx context.Context, adminID
 }
 
 // RemoveStudentFromAllSubsequent removes a student from the source lesson and all subsequent matching lessons
-func (s *BulkEditService) RemoveStudentFromAllSubsequent(ctx context.Context, adminID uuid.UUID, sourceLessonID uuid.UUID, studentID uuid.UUID) (*models.LessonModification, error) {
+func (s *BulkEditService) RemoveStudentFromAllSubsequent(ctx context.Context, adminID uuid.UUID, sourceLessonID uuid.UUID, studentID uuid.UUID, preview *models.ApplyToAllSubsequentRequest) (*models.LessonModification, error) {
 	// Validate student exists
 	student, err := s.userRepo.GetByID(ctx, studentID)
 	if err != nil {
@@ -272,7 +330,12 @@ func (s *BulkEditService) RemoveStudentFromAllSubsequent(ctx context.Context, ad
 
 	// Remove student from all lessons (source + future matches)
 	allLessons := append([]*models.Lesson{sourceLesson}, futureMatches...)
+	if err := verifyPreviewToken(preview, allLessons); err != nil {
+		return nil, err
+	}
 	removedCount := 0
+	before := make(map[uuid.UUID]*models.LessonSnapshot)
+	movements := make(map[uuid.UUID][]models.CreditMovement)
 
 	// Get student's credit balance for refunds
 	credit, err := s.creditRepo.GetBalanceForUpdate(ctx, tx, studentID)
@@ -288,6 +351,14 @@ func (s *BulkEditService) RemoveStudentFromAllSubsequent(ctx context.Context, ad
 		}
 
 		if isBooked {
+			// Snapshot only lessons that are actually changed
+			snapshot, err := s.captureSnapshotTx(ctx, tx, lesson.ID)
+			if err != nil {
+				return nil, err
+			}
+			before[lesson.ID] = snapshot
+			movements[lesson.ID] = []models.CreditMovement{{StudentID: studentID, Amount: 1}}
+
 			// Cancel the booking
 			if err := s.lessonRepo.CancelBookingTx(ctx, tx, lesson.ID, studentID); err != nil {
 				return nil, fmt.Errorf("failed to cancel booking for lesson %s: %w", lesson.ID, err)
@@ -349,6 +420,10 @@ func (s *BulkEditService) RemoveStudentFromAllSubsequent(ctx context.Context, ad
 		return nil, fmt.Errorf("failed to log modification: %w", err)
 	}
 
+	if err := s.saveSnapshotsTx(ctx, tx, modification.ID, before, movements); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -358,7 +433,7 @@ func (s *BulkEditService) RemoveStudentFromAllSubsequent(ctx context.Context, ad
 }
 
 // ChangeTeacherForAllSubsequent changes the teacher for the source lesson and all subsequent matching lessons
-func (s *BulkEditService) ChangeTeacherForAllSubsequent(ctx context.Context, adminID uuid.UUID, sourceLessonID uuid.UUID, newTeacherID uuid.UUID) (*models.LessonModification, error) {
+func (s *BulkEditService) ChangeTeacherForAllSubsequent(ctx context.Context, adminID uuid.UUID, sourceLessonID uuid.UUID, newTeacherID uuid.UUID, preview *models.ApplyToAllSubsequentRequest) (*models.LessonModification, error) {
 	// Validate new teacher exists and has teacher or admin role
 	newTeacher, err := s.userRepo.GetByID(ctx, newTeacherID)
 	if err != nil {
@@ -400,6 +475,14 @@ func (s *BulkEditService) ChangeTeacherForAllSubsequent(ctx context.Context, adm
 
 	// Update teacher for all lessons (source + future matches)
 	allLessons := append([]*models.Lesson{sourceLesson}, futureMatches...)
+	if err := verifyPreviewToken(preview, allLessons); err != nil {
+		return nil, err
+	}
+	before, err := s.captureSnapshotsTx(ctx, tx, allLessons)
+	if err != nil {
+		return nil, err
+	}
+	var movements map[uuid.UUID][]models.CreditMovement
 	for _, lesson := range allLessons {
 		if err := s.lessonRepo.UpdateTeacherTx(ctx, tx, lesson.ID, newTeacherID); err != nil {
 			return nil, fmt.Errorf("failed to update teacher for lesson %s: %w", lesson.ID, err)
@@ -428,6 +511,10 @@ func (s *BulkEditService) ChangeTeacherForAllSubsequent(ctx context.Context, adm
 		return nil, fmt.Errorf("failed to log modification: %w", err)
 	}
 
+	if err := s.saveSnapshotsTx(ctx, tx, modification.ID, before, movements); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -437,7 +524,7 @@ func (s *BulkEditService) ChangeTeacherForAllSubsequent(ctx context.Context, adm
 }
 
 // ChangeTimeForAllSubsequent changes the time for the source lesson and all subsequent matching lessons
-func (s *BulkEditService) ChangeTimeForAllSubsequent(ctx context.Context, adminID uuid.UUID, sourceLessonID uuid.UUID, newStartTimeStr string) (*models.LessonModification, error) {
+func (s *BulkEditService) ChangeTimeForAllSubsequent(ctx context.Context, adminID uuid.UUID, sourceLessonID uuid.UUID, newStartTimeStr string, preview *models.ApplyToAllSubsequentRequest) (*models.LessonModification, error) {
 	// Parse new start time
 	newStartTime, err := time.Parse(time.RFC3339, newStartTimeStr)
 	if err != nil {
@@ -478,11 +565,17 @@ func (s *BulkEditService) ChangeTimeForAllSubsequent(ctx context.Context, adminI
 	// Note: This changes the time but preserves the day, so it may create scheduling conflicts
 	// A production implementation would need conflict checking
 	allLessons := append([]*models.Lesson{sourceLesson}, futureMatches...)
+	if err := verifyPreviewToken(preview, allLessons); err != nil {
+		return nil, err
+	}
+	before, err := s.captureSnapshotsTx(ctx, tx, allLessons)
+	if err != nil {
+		return nil, err
+	}
+	var movements map[uuid.UUID][]models.CreditMovement
 	for _, lesson := range allLessons {
 		// Calculate new time preserving the original date
-		lessonDate := lesson.StartTime.Truncate(24 * time.Hour)
-		adjustedStartTime := lessonDate.Add(time.Duration(newStartTime.Hour())*time.Hour + time.Duration(newStartTime.Minute())*time.Minute)
-		adjustedEndTime := adjustedStartTime.Add(2 * time.Hour)
+		adjustedStartTime, adjustedEndTime := adjustedLessonTime(lesson.StartTime, newStartTime)
 
 		if err := s.lessonRepo.UpdateTimeTx(ctx, tx, lesson.ID, adjustedStartTime, adjustedEndTime); err != nil {
 			return nil, fmt.Errorf("failed to update time for lesson %s: %w", lesson.ID, err)
@@ -510,6 +603,10 @@ func (s *BulkEditService) ChangeTimeForAllSubsequent(ctx context.Context, adminI
 		return nil, fmt.Errorf("failed to log modification: %w", err)
 	}
 
+	if err := s.saveSnapshotsTx(ctx, tx, modification.ID, before, movements); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -519,7 +616,7 @@ func (s *BulkEditService) ChangeTimeForAllSubsequent(ctx context.Context, adminI
 }
 
 // ChangeCapacityForAllSubsequent changes max_students for the source lesson and all subsequent matching lessons
-func (s *BulkEditService) ChangeCapacityForAllSubsequent(ctx context.Context, adminID uuid.UUID, sourceLessonID uuid.UUID, newMaxStudents int) (*models.LessonModification, error) {
+func (s *BulkEditService) ChangeCapacityForAllSubsequent(ctx context.Context, adminID uuid.UUID, sourceLessonID uuid.UUID, newMaxStudents int, preview *models.ApplyToAllSubsequentRequest) (*models.LessonModification, error) {
 	if newMaxStudents < 1 {
 		return nil, fmt.Errorf("new_max_students must be >= 1")
 	}
@@ -556,12 +653,21 @@ func (s *BulkEditService) ChangeCapacityForAllSubsequent(ctx context.Context, ad
 
 	// Pre-check: ensure new capacity doesn't conflict with current_students
 	allLessons := append([]*models.Lesson{sourceLesson}, futureMatches...)
+	if err := verifyPreviewToken(preview, allLessons); err != nil {
+		return nil, err
+	}
 	for _, lesson := range allLessons {
 		if lesson.CurrentStudents > newMaxStudents {
 			return nil, fmt.Errorf("cannot set max_students to %d for lesson %s: already has %d students enrolled", newMaxStudents, lesson.ID, lesson.CurrentStudents)
 		}
 	}
 
+	before, err := s.captureSnapshotsTx(ctx, tx, allLessons)
+	if err != nil {
+		return nil, err
+	}
+	var movements map[uuid.UUID][]models.CreditMovement
+
 	// Update max_students for all lessons
 	for _, lesson := range allLessons {
 		if err := s.lessonRepo.UpdateMaxStudentsTx(ctx, tx, lesson.ID, newMaxStudents); err != nil {
@@ -590,6 +696,10 @@ func (s *BulkEditService) ChangeCapacityForAllSubsequent(ctx context.Context, ad
 		return nil, fmt.Errorf("failed to log modification: %w", err)
 	}
 
+	if err := s.saveSnapshotsTx(ctx, tx, modification.ID, before, movements); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -598,6 +708,305 @@ func (s *BulkEditService) ChangeCapacityForAllSubsequent(ctx context.Context, ad
 	return modification, nil
 }
 
+// adjustedLessonTime returns the new start/end of a lesson moved to newStartTime's clock time on the same date
+func adjustedLessonTime(lessonStart, newStartTime time.Time) (time.Time, time.Time) {
+	lessonDate := lessonStart.Truncate(24 * time.Hour)
+	start := lessonDate.Add(time.Duration(newStartTime.Hour())*time.Hour + time.Duration(newStartTime.Minute())*time.Minute)
+	return start, start.Add(2 * time.Hour)
+}
+
+// verifyPreviewToken ensures the target lessons are the same set that was shown in the dry run.
+// preview is the request confirmed with a preview token; nil skips the check
+func verifyPreviewToken(preview *models.ApplyToAllSubsequentRequest, lessons []*models.Lesson) error {
+	if preview == nil || preview.PreviewToken == "" {
+		return nil
+	}
+	if preview.ComputePreviewToken(lessonIDs(lessons)) != preview.PreviewToken {
+		return models.ErrPreviewTokenMismatch
+	}
+	return nil
+}
+
+func lessonIDs(lessons []*models.Lesson) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(lessons))
+	for _, lesson := range lessons {
+		ids = append(ids, lesson.ID)
+	}
+	return ids
+}
+
+// PreviewApplyToAllSubsequent computes the effect of ApplyToAllSubsequent without writing anything.
+// The returned preview token must be passed back to apply the same modification to the same lessons.
+func (s *BulkEditService) PreviewApplyToAllSubsequent(ctx context.Context, req *models.ApplyToAllSubsequentRequest) (*models.BulkEditPreview, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	sourceLesson, err := s.lessonRepo.GetByID(ctx, req.LessonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load source lesson: %w", err)
+	}
+
+	futureMatches, err := s.FindSubsequentMatchingLessons(ctx, req.LessonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find matching lessons: %w", err)
+	}
+
+	allLessons := append([]*models.Lesson{sourceLesson}, futureMatches...)
+	ids := lessonIDs(allLessons)
+
+	bookings, err := s.lessonRepo.GetLessonBookingsForLessons(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bookings: %w", err)
+	}
+
+	preview := &models.BulkEditPreview{
+		SourceLessonID:     req.LessonID,
+		ModificationType:   req.ModificationType,
+		Lessons:            make([]*models.BulkEditLessonPreview, 0, len(allLessons)),
+		CapacityViolations: []models.BulkEditCapacityViolation{},
+		TeacherOverlaps:    []models.BulkEditTeacherOverlap{},
+		CreditDeltas:       []models.StudentCreditDelta{},
+		ValidationErrors:   []string{},
+		PreviewToken:       req.ComputePreviewToken(ids),
+	}
+
+	if err := s.ValidateModificationApplicability(ctx, req.LessonID, req.ModificationType, allLessons); err != nil {
+		preview.ValidationErrors = append(preview.ValidationErrors, err.Error())
+	}
+
+	for _, lesson := range allLessons {
+		preview.Lessons = append(preview.Lessons, &models.BulkEditLessonPreview{
+			LessonID:        lesson.ID,
+			TeacherID:       lesson.TeacherID,
+			StartTime:       lesson.StartTime,
+			EndTime:         lesson.EndTime,
+			CurrentStudents: lesson.CurrentStudents,
+			MaxStudents:     lesson.MaxStudents,
+		})
+	}
+
+	switch req.ModificationType {
+	case "add_student":
+		err = s.previewAddStudent(ctx, preview, allLessons, bookings, *req.StudentID)
+	case "remove_student":
+		err = s.previewRemoveStudent(ctx, preview, bookings, *req.StudentID)
+	case "change_teacher":
+		err = s.previewChangeTeacher(ctx, preview, allLessons, *req.TeacherID)
+	case "change_time":
+		err = s.previewChangeTime(ctx, preview, allLessons, *req.NewStartTime)
+	case "change_capacity":
+		s.previewChangeCapacity(preview, allLessons, *req.NewMaxStudents)
+	default:
+		return nil, fmt.Errorf("unsupported modification type: %s", req.ModificationType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lessonPreview := range preview.Lessons {
+		if !lessonPreview.Unchanged {
+			preview.AffectedLessonsCount++
+		}
+	}
+
+	insufficientCredits := false
+	for _, delta := range preview.CreditDeltas {
+		if delta.Insufficient {
+			insufficientCredits = true
+		}
+	}
+	preview.CanApply = len(preview.ValidationErrors) == 0 &&
+		len(preview.CapacityViolations) == 0 &&
+		!insufficientCredits &&
+		preview.AffectedLessonsCount > 0
+
+	return preview, nil
+}
+
+func (s *BulkEditService) previewAddStudent(ctx context.Context, preview *models.BulkEditPreview, lessons []*models.Lesson, bookings map[uuid.UUID][]models.BookingInfo, studentID uuid.UUID) error {
+	student, err := s.userRepo.GetByID(ctx, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to load student: %w", err)
+	}
+	if !student.IsStudent() {
+		preview.ValidationErrors = append(preview.ValidationErrors, fmt.Sprintf("user %s is not a student (role: %s)", studentID, student.Role))
+	}
+	info := models.BookingInfo{StudentID: studentID, StudentName: student.GetFullName()}
+
+	for i, lesson := range lessons {
+		lessonPreview := preview.Lessons[i]
+		lessonPreview.StudentsAdded = []models.BookingInfo{info}
+
+		if lesson.CurrentStudents >= lesson.MaxStudents {
+			preview.CapacityViolations = append(preview.CapacityViolations, models.BulkEditCapacityViolation{
+				LessonID:        lesson.ID,
+				StartTime:       lesson.StartTime,
+				CurrentStudents: lesson.CurrentStudents,
+				MaxStudents:     lesson.MaxStudents,
+				Reason:          "lesson is full",
+			})
+			lessonPreview.Issues = append(lessonPreview.Issues, "lesson is full")
+		}
+		if hasBooking(bookings[lesson.ID], studentID) {
+			preview.ValidationErrors = append(preview.ValidationErrors,
+				fmt.Sprintf("student is already booked for lesson at %s", lesson.StartTime.Format("2006-01-02 15:04")))
+			lessonPreview.Issues = append(lessonPreview.Issues, "student is already booked")
+		}
+	}
+
+	return s.addCreditDelta(ctx, preview, info, -len(lessons))
+}
+
+func (s *BulkEditService) previewRemoveStudent(ctx context.Context, preview *models.BulkEditPreview, bookings map[uuid.UUID][]models.BookingInfo, studentID uuid.UUID) error {
+	student, err := s.userRepo.GetByID(ctx, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to load student: %w", err)
+	}
+	info := models.BookingInfo{StudentID: studentID, StudentName: student.GetFullName()}
+
+	removed := 0
+	for _, lessonPreview := range preview.Lessons {
+		if !hasBooking(bookings[lessonPreview.LessonID], studentID) {
+			lessonPreview.Unchanged = true
+			continue
+		}
+		lessonPreview.StudentsRemoved = []models.BookingInfo{info}
+		removed++
+	}
+
+	if removed == 0 {
+		preview.ValidationErrors = append(preview.ValidationErrors,
+			fmt.Sprintf("student %s is not booked for any of the matching lessons", studentID))
+		return nil
+	}
+
+	return s.addCreditDelta(ctx, preview, info, removed)
+}
+
+func (s *BulkEditService) previewChangeTeacher(ctx context.Context, preview *models.BulkEditPreview, lessons []*models.Lesson, newTeacherID uuid.UUID) error {
+	newTeacher, err := s.userRepo.GetByID(ctx, newTeacherID)
+	if err != nil {
+		return fmt.Errorf("failed to load new teacher: %w", err)
+	}
+	if !newTeacher.CanBeAssignedAsTeacher() {
+		preview.ValidationErrors = append(preview.ValidationErrors,
+			fmt.Sprintf("user %s cannot be assigned as teacher (role: %s)", newTeacherID, newTeacher.Role))
+	}
+
+	targets := make(map[uuid.UUID]bool, len(lessons))
+	for _, lesson := range lessons {
+		targets[lesson.ID] = true
+	}
+
+	for i, lesson := range lessons {
+		preview.Lessons[i].NewTeacherID = &newTeacherID
+		if err := s.collectTeacherOverlaps(ctx, preview, preview.Lessons[i], newTeacherID, lesson.StartTime, lesson.EndTime, targets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *BulkEditService) previewChangeTime(ctx context.Context, preview *models.BulkEditPreview, lessons []*models.Lesson, newStartTimeStr string) error {
+	newStartTime, err := time.Parse(time.RFC3339, newStartTimeStr)
+	if err != nil {
+		preview.ValidationErrors = append(preview.ValidationErrors, fmt.Sprintf("invalid time format (expected RFC3339): %v", err))
+		return nil
+	}
+
+	targets := make(map[uuid.UUID]bool, len(lessons))
+	for _, lesson := range lessons {
+		targets[lesson.ID] = true
+	}
+
+	now := time.Now()
+	for i, lesson := range lessons {
+		lessonPreview := preview.Lessons[i]
+		start, end := adjustedLessonTime(lesson.StartTime, newStartTime)
+		lessonPreview.NewStartTime = &start
+		lessonPreview.NewEndTime = &end
+
+		if start.Before(now) {
+			lessonPreview.Issues = append(lessonPreview.Issues, "new time is in the past")
+		}
+		if err := s.collectTeacherOverlaps(ctx, preview, lessonPreview, lesson.TeacherID, start, end, targets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *BulkEditService) previewChangeCapacity(preview *models.BulkEditPreview, lessons []*models.Lesson, newMaxStudents int) {
+	for i, lesson := range lessons {
+		lessonPreview := preview.Lessons[i]
+		lessonPreview.NewMaxStudents = &newMaxStudents
+
+		if lesson.CurrentStudents > newMaxStudents {
+			reason := fmt.Sprintf("already has %d students enrolled", lesson.CurrentStudents)
+			preview.CapacityViolations = append(preview.CapacityViolations, models.BulkEditCapacityViolation{
+				LessonID:        lesson.ID,
+				StartTime:       lesson.StartTime,
+				CurrentStudents: lesson.CurrentStudents,
+				MaxStudents:     newMaxStudents,
+				Reason:          reason,
+			})
+			lessonPreview.Issues = append(lessonPreview.Issues, reason)
+		}
+	}
+}
+
+// collectTeacherOverlaps records teacher's other lessons overlapping [start, end), ignoring the target lessons themselves
+func (s *BulkEditService) collectTeacherOverlaps(ctx context.Context, preview *models.BulkEditPreview, lessonPreview *models.BulkEditLessonPreview, teacherID uuid.UUID, start, end time.Time, targets map[uuid.UUID]bool) error {
+	overlapping, err := s.lessonRepo.GetOverlappingTeacherLessons(ctx, teacherID, start, end)
+	if err != nil {
+		return err
+	}
+	for _, other := range overlapping {
+		if targets[other.ID] {
+			continue
+		}
+		preview.TeacherOverlaps = append(preview.TeacherOverlaps, models.BulkEditTeacherOverlap{
+			LessonID:             lessonPreview.LessonID,
+			TeacherID:            teacherID,
+			StartTime:            start,
+			EndTime:              end,
+			ConflictingLessonID:  other.ID,
+			ConflictingStartTime: other.StartTime,
+			ConflictingEndTime:   other.EndTime,
+		})
+		lessonPreview.Issues = append(lessonPreview.Issues,
+			fmt.Sprintf("teacher overlaps with lesson at %s", other.StartTime.Format("2006-01-02 15:04")))
+	}
+	return nil
+}
+
+// addCreditDelta records the credit change of a student against the current balance
+func (s *BulkEditService) addCreditDelta(ctx context.Context, preview *models.BulkEditPreview, student models.BookingInfo, delta int) error {
+	credit, err := s.creditRepo.GetBalance(ctx, student.StudentID)
+	if err != nil {
+		return fmt.Errorf("failed to get student credit balance: %w", err)
+	}
+	preview.CreditDeltas = append(preview.CreditDeltas, models.StudentCreditDelta{
+		StudentID:      student.StudentID,
+		StudentName:    student.StudentName,
+		CurrentBalance: credit.Balance,
+		Delta:          delta,
+		BalanceAfter:   credit.Balance + delta,
+		Insufficient:   credit.Balance+delta < 0,
+	})
+	return nil
+}
+
+func hasBooking(bookings []models.BookingInfo, studentID uuid.UUID) bool {
+	for _, booking := range bookings {
+		if booking.StudentID == studentID {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateModificationApplicability validates if a modification can be applied to target lessons
 func (s *BulkEditService) ValidateModificationApplicability(ctx context.Context, sourceLessonID uuid.UUID, modificationType string, targetLessons []*models.Lesson) error {
 	switch modificationType {
@@ -625,3 +1034,299 @@ func (s *BulkEditService) ValidateModificationApplicability(ctx context.Context,
 	}
 	return nil
 }
+
+// RevertConflictError is returned when lessons changed after a modification was applied
+type RevertConflictError struct {
+	Conflicts []models.RevertConflict
+}
+
+func (e *RevertConflictError) Error() string {
+	return fmt.Sprintf("%s (%d lesson(s))", models.ErrModificationRevertConflict.Error(), len(e.Conflicts))
+}
+
+func (e *RevertConflictError) Unwrap() error {
+	return models.ErrModificationRevertConflict
+}
+
+// GetModificationWithSnapshots returns a modification together with its per-lesson snapshots
+func (s *BulkEditService) GetModificationWithSnapshots(ctx context.Context, modificationID uuid.UUID) (*models.LessonModification, []*models.LessonModificationSnapshot, error) {
+	modification, err := s.lessonModificationRepo.GetModificationByID(ctx, modificationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snapshots, err := s.lessonModificationRepo.GetSnapshots(ctx, modificationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return modification, snapshots, nil
+}
+
+// RevertModification restores all lessons affected by a bulk modification to their state before it was applied.
+// Bookings added by the modification are cancelled, removed bookings are re-created and credit movements are reversed.
+// The whole revert is atomic; if any affected lesson changed since the modification, nothing is reverted
+// and a *RevertConflictError listing the conflicting lessons is returned.
+func (s *BulkEditService) RevertModification(ctx context.Context, adminID uuid.UUID, modificationID uuid.UUID) (*models.RevertModificationResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Failed to rollback revert transaction")
+		}
+	}()
+
+	_, err = tx.Exec(ctx, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to set transaction isolation level: %w", err)
+	}
+
+	modification, err := s.lessonModificationRepo.GetModificationByIDForUpdateTx(ctx, tx, modificationID)
+	if err != nil {
+		return nil, err
+	}
+	if modification.IsReverted() {
+		return nil, models.ErrModificationAlreadyReverted
+	}
+
+	snapshots, err := s.lessonModificationRepo.GetSnapshotsTx(ctx, tx, modificationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, models.ErrModificationNotRevertible
+	}
+
+	// Phase 1: conflict detection - every lesson must still be exactly in its post-modification state
+	now := time.Now()
+	var conflicts []models.RevertConflict
+	for _, snapshot := range snapshots {
+		current, err := s.captureSnapshotTx(ctx, tx, snapshot.LessonID)
+		if err != nil {
+			if errors.Is(err, repository.ErrLessonNotFound) {
+				conflicts = append(conflicts, models.RevertConflict{
+					LessonID:  snapshot.LessonID,
+					StartTime: snapshot.After.StartTime,
+					Reasons:   []string{"lesson deleted"},
+				})
+				continue
+			}
+			return nil, err
+		}
+
+		reasons := snapshot.After.Diff(current)
+		if !current.StartTime.After(now) || !snapshot.Before.StartTime.After(now) {
+			reasons = append(reasons, "lesson already started")
+		}
+		if len(reasons) > 0 {
+			conflicts = append(conflicts, models.RevertConflict{
+				LessonID:  snapshot.LessonID,
+				StartTime: current.StartTime,
+				Reasons:   reasons,
+			})
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, &RevertConflictError{Conflicts: conflicts}
+	}
+
+	// Phase 2: restore each lesson to its "before" state
+	result := &models.RevertModificationResult{ModificationID: modificationID}
+	reason := fmt.Sprintf("Revert of bulk %s modification %s", modification.ModificationType, modificationID)
+
+	for _, snapshot := range snapshots {
+		lessonID := snapshot.LessonID
+
+		// Cancel bookings created by the modification first to free capacity
+		for _, studentID := range snapshot.After.StudentIDs {
+			if snapshot.Before.HasStudent(studentID) {
+				continue
+			}
+			if err := s.lessonRepo.CancelBookingTx(ctx, tx, lessonID, studentID); err != nil {
+				return nil, fmt.Errorf("failed to cancel booking for lesson %s: %w", lessonID, err)
+			}
+			if err := s.lessonRepo.DecrementStudents(ctx, tx, lessonID); err != nil {
+				return nil, fmt.Errorf("failed to decrement student count for lesson %s: %w", lessonID, err)
+			}
+			result.CancelledBookings++
+		}
+
+		if snapshot.Before.TeacherID != snapshot.After.TeacherID {
+			if err := s.lessonRepo.UpdateTeacherTx(ctx, tx, lessonID, snapshot.Before.TeacherID); err != nil {
+				return nil, fmt.Errorf("failed to restore teacher for lesson %s: %w", lessonID, err)
+			}
+		}
+		if !snapshot.Before.StartTime.Equal(snapshot.After.StartTime) || !snapshot.Before.EndTime.Equal(snapshot.After.EndTime) {
+			if err := s.lessonRepo.UpdateTimeTx(ctx, tx, lessonID, snapshot.Before.StartTime, snapshot.Before.EndTime); err != nil {
+				return nil, fmt.Errorf("failed to restore time for lesson %s: %w", lessonID, err)
+			}
+		}
+		if snapshot.Before.MaxStudents != snapshot.After.MaxStudents {
+			if err := s.lessonRepo.UpdateMaxStudentsTx(ctx, tx, lessonID, snapshot.Before.MaxStudents); err != nil {
+				return nil, fmt.Errorf("failed to restore capacity for lesson %s: %w", lessonID, err)
+			}
+		}
+
+		// Re-create bookings removed by the modification
+		for _, studentID := range snapshot.Before.StudentIDs {
+			if snapshot.After.HasStudent(studentID) {
+				continue
+			}
+			booking := &models.Booking{
+				ID:        uuid.New(),
+				StudentID: studentID,
+				LessonID:  lessonID,
+				Status:    models.BookingStatusActive,
+				BookedAt:  now,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if err := s.lessonRepo.CreateBookingTx(ctx, tx, booking); err != nil {
+				return nil, fmt.Errorf("failed to restore booking for lesson %s: %w", lessonID, err)
+			}
+			if err := s.lessonRepo.IncrementStudents(ctx, tx, lessonID); err != nil {
+				return nil, fmt.Errorf("failed to increment student count for lesson %s: %w", lessonID, err)
+			}
+			result.RestoredBookings++
+		}
+
+		// Reverse credit movements
+		for _, movement := range snapshot.CreditMovements {
+			if err := s.adjustCreditsTx(ctx, tx, adminID, movement.StudentID, -movement.Amount, reason); err != nil {
+				return nil, err
+			}
+			if movement.Amount < 0 {
+				result.CreditsRefunded += -movement.Amount
+			} else {
+				result.CreditsDeducted += movement.Amount
+			}
+		}
+
+		result.RevertedLessons++
+	}
+
+	if err := s.lessonModificationRepo.MarkRevertedTx(ctx, tx, modificationID, adminID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Info().
+		Str("modification_id", modificationID.String()).
+		Str("admin_id", adminID.String()).
+		Int("reverted_lessons", result.RevertedLessons).
+		Int("restored_bookings", result.RestoredBookings).
+		Int("cancelled_bookings", result.CancelledBookings).
+		Msg("Bulk lesson modification reverted")
+
+	return result, nil
+}
+
+// adjustCreditsTx changes a student's balance by delta (positive = refund, negative = deduct) and records the transaction
+func (s *BulkEditService) adjustCreditsTx(ctx context.Context, tx pgx.Tx, adminID uuid.UUID, studentID uuid.UUID, delta int, reason string) error {
+	if delta == 0 {
+		return nil
+	}
+
+	credit, err := s.creditRepo.GetBalanceForUpdate(ctx, tx, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to get student credit balance: %w", err)
+	}
+
+	newBalance := credit.Balance + delta
+	if newBalance < 0 {
+		return fmt.Errorf("student %s has %d credits, needs %d: %w", studentID, credit.Balance, -delta, repository.ErrInsufficientCredits)
+	}
+
+	if err := s.creditRepo.UpdateBalance(ctx, tx, studentID, newBalance); err != nil {
+		return fmt.Errorf("failed to update credit balance: %w", err)
+	}
+
+	operationType := models.OperationTypeRefund
+	amount := delta
+	if delta < 0 {
+		operationType = models.OperationTypeDeduct
+		amount = -delta
+	}
+
+	creditTx := &models.CreditTransaction{
+		UserID:        studentID,
+		Amount:        amount,
+		OperationType: operationType,
+		Reason:        reason,
+		PerformedBy:   uuid.NullUUID{UUID: adminID, Valid: true},
+		BalanceBefore: credit.Balance,
+		BalanceAfter:  newBalance,
+	}
+	if err := s.creditRepo.CreateTransaction(ctx, tx, creditTx); err != nil {
+		return fmt.Errorf("failed to record credit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// captureSnapshotTx locks a lesson and captures its current state including active bookings
+func (s *BulkEditService) captureSnapshotTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) (*models.LessonSnapshot, error) {
+	lesson, err := s.lessonRepo.GetByIDForUpdate(ctx, tx, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.lessonRepo.GetBookingsByLessonTx(ctx, tx, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.LessonSnapshot{
+		TeacherID:   lesson.TeacherID,
+		StartTime:   lesson.StartTime,
+		EndTime:     lesson.EndTime,
+		MaxStudents: lesson.MaxStudents,
+		StudentIDs:  make([]uuid.UUID, 0, len(bookings)),
+	}
+	for _, booking := range bookings {
+		snapshot.StudentIDs = append(snapshot.StudentIDs, booking.StudentID)
+	}
+
+	return snapshot, nil
+}
+
+// captureSnapshotsTx captures the state of all lessons before a bulk modification
+func (s *BulkEditService) captureSnapshotsTx(ctx context.Context, tx pgx.Tx, lessons []*models.Lesson) (map[uuid.UUID]*models.LessonSnapshot, error) {
+	snapshots := make(map[uuid.UUID]*models.LessonSnapshot, len(lessons))
+	for _, lesson := range lessons {
+		snapshot, err := s.captureSnapshotTx(ctx, tx, lesson.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture state of lesson %s: %w", lesson.ID, err)
+		}
+		snapshots[lesson.ID] = snapshot
+	}
+	return snapshots, nil
+}
+
+// saveSnapshotsTx captures the post-modification state of every lesson in before and stores both snapshots
+func (s *BulkEditService) saveSnapshotsTx(ctx context.Context, tx pgx.Tx, modificationID uuid.UUID, before map[uuid.UUID]*models.LessonSnapshot, movements map[uuid.UUID][]models.CreditMovement) error {
+	snapshots := make([]*models.LessonModificationSnapshot, 0, len(before))
+	for lessonID, beforeState := range before {
+		after, err := s.captureSnapshotTx(ctx, tx, lessonID)
+		if err != nil {
+			return fmt.Errorf("failed to capture state of lesson %s: %w", lessonID, err)
+		}
+		snapshots = append(snapshots, &models.LessonModificationSnapshot{
+			ModificationID:  modificationID,
+			LessonID:        lessonID,
+			Before:          *beforeState,
+			After:           *after,
+			CreditMovements: movements[lessonID],
+		})
+	}
+
+	if err := s.lessonModificationRepo.SaveSnapshotsTx(ctx, tx, snapshots); err != nil {
+		return fmt.Errorf("failed to save modification snapshots: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+)
+
+// chatGroupRepository интерфейс групповых комнат чата: каналы и комнаты серий групповых занятий
+type chatGroupRepository interface {
+	ListGroupRoomsByUser(ctx context.Context, userID uuid.UUID) ([]*models.ChatRoom, error)
+	CreateGroupRoom(ctx context.Context, room *models.ChatRoom, createdBy uuid.UUID, participants []models.ChatRoomParticipant) error
+	AddRoomParticipants(ctx context.Context, roomID uuid.UUID, userIDs []uuid.UUID, role string) (int, error)
+	RemoveRoomParticipant(ctx context.Context, roomID, userID uuid.UUID) error
+	SetParticipantRole(ctx context.Context, roomID, userID uuid.UUID, role string) error
+	SetParticipantMute(ctx context.Context, roomID, userID uuid.UUID, mutedUntil sql.NullTime) error
+	SyncLessonGroupRoom(ctx context.Context, recurringGroupID uuid.UUID) (uuid.UUID, error)
+}
+
+// SetGroupRepository подключает групповые комнаты. Без него доступны только комнаты 1-на-1
+func (s *ChatService) SetGroupRepository(repo chatGroupRepository) {
+	s.groupRepo = repo
+}
+
+// appendGroupRooms добавляет к комнатам 1-на-1 групповые комнаты пользователя
+// и сортирует список по времени последнего сообщения
+func (s *ChatService) appendGroupRooms(ctx context.Context, userID uuid.UUID, rooms []*models.ChatRoom) ([]*models.ChatRoom, error) {
+	if s.groupRepo == nil {
+		return rooms, nil
+	}
+	groupRooms, err := s.groupRepo.ListGroupRoomsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(groupRooms) == 0 {
+		return rooms, nil
+	}
+
+	rooms = append(rooms, groupRooms...)
+	sort.SliceStable(rooms, func(i, j int) bool {
+		return roomActivity(rooms[i]).After(roomActivity(rooms[j]))
+	})
+	return rooms, nil
+}
+
+// roomActivity время последней активности комнаты для сортировки списка
+func roomActivity(room *models.ChatRoom) time.Time {
+	if room.LastMessageAt.Valid {
+		return room.LastMessageAt.Time
+	}
+	return room.CreatedAt
+}
+
+// GetRoomParticipants возвращает участников комнаты. Доступно участникам и тем, кто может читать все чаты
+func (s *ChatService) GetRoomParticipants(ctx context.Context, roomID, userID uuid.UUID, canReadAll bool) ([]models.ChatRoomParticipant, error) {
+	room, err := s.chatRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if !canReadAll && !room.IsParticipant(userID) {
+		return nil, repository.ErrUnauthorized
+	}
+	if room.IsGroup() {
+		return room.Participants, nil
+	}
+
+	// Для комнаты 1-на-1 участники - преподаватель и студент
+	participants := make([]models.ChatRoomParticipant, 0, 2)
+	for _, id := range room.ParticipantIDs() {
+		user, err := s.userRepo.GetByID(ctx, id)
+		if err != nil {
+			continue
+		}
+		participants = append(participants, models.ChatRoomParticipant{
+			RoomID:   room.ID,
+			UserID:   id,
+			Name:     user.GetFullName(),
+			UserRole: string(user.Role),
+			Role:     models.ChatRoleMember,
+			JoinedAt: room.CreatedAt,
+		})
+	}
+	return participants, nil
+}
+
+// CreateChannel создает канал объявлений. Создатель становится владельцем,
+// перечисленные пользователи и студенты учебной группы - участниками
+func (s *ChatService) CreateChannel(ctx context.Context, creatorID uuid.UUID, req *models.CreateChannelRequest) (*models.ChatRoom, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if s.groupRepo == nil {
+		return nil, models.ErrChatNotGroupRoom
+	}
+
+	room := &models.ChatRoom{
+		ID:          uuid.New(),
+		Type:        models.ChatRoomTypeChannel,
+		Title:       req.Title,
+		Description: req.Description,
+	}
+	if req.StudentGroupID != nil {
+		room.StudentGroupID = uuid.NullUUID{UUID: *req.StudentGroupID, Valid: true}
+	}
+
+	participants := []models.ChatRoomParticipant{{UserID: creatorID, Role: models.ChatRoleOwner}}
+	for _, id := range req.MemberIDs {
+		if id != creatorID {
+			participants = append(participants, models.ChatRoomParticipant{UserID: id, Role: models.ChatRoleMember})
+		}
+	}
+
+	if err := s.groupRepo.CreateGroupRoom(ctx, room, creatorID, participants); err != nil {
+		return nil, fmt.Errorf("failed to create channel: %w", err)
+	}
+
+	s.audit.Record(ctx, models.AuditActionChatChannelCreate, models.AuditTargetChatRoom, room.ID.String(), nil,
+		map[string]interface{}{"title": room.Title, "member_ids": req.MemberIDs, "student_group_id": req.StudentGroupID})
+
+	return s.chatRepo.GetRoomByID(ctx, room.ID)
+}
+
+// AddParticipants добавляет участников в групповую комнату. В комнате серии занятий добавленные вручную
+// остаются и без активной записи на занятия серии
+func (s *ChatService) AddParticipants(ctx context.Context, roomID uuid.UUID, req *models.ChatParticipantsRequest) (*models.ChatRoom, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.getGroupRoom(ctx, roomID); err != nil {
+		return nil, err
+	}
+
+	added, err := s.groupRepo.AddRoomParticipants(ctx, roomID, req.UserIDs, models.ChatRoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, models.AuditActionChatParticipantsAdd, models.AuditTargetChatRoom, roomID.String(), nil,
+		map[string]interface{}{"user_ids": req.UserIDs, "added": added})
+
+	return s.chatRepo.GetRoomByID(ctx, roomID)
+}
+
+// RemoveParticipant удаляет участника из групповой комнаты. Участник комнаты серии занятий
+// с активной записью вернется в комнату при следующей синхронизации
+func (s *ChatService) RemoveParticipant(ctx context.Context, roomID, userID uuid.UUID) error {
+	room, err := s.getGroupRoom(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	target := room.GetParticipant(userID)
+	if target == nil {
+		return repository.ErrChatParticipantNotFound
+	}
+	if target.Role == models.ChatRoleOwner {
+		return models.ErrChatOwnerLocked
+	}
+
+	if err := s.groupRepo.RemoveRoomParticipant(ctx, roomID, userID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditActionChatParticipantRemove, models.AuditTargetChatRoom, roomID.String(),
+		map[string]interface{}{"user_id": userID, "role": target.Role}, nil)
+	return nil
+}
+
+// SetParticipantRole назначает или снимает модератора. Доступно владельцу комнаты
+// и управляющим каналами (canManage)
+func (s *ChatService) SetParticipantRole(ctx context.Context, actorID, roomID, userID uuid.UUID, canManage bool, req *models.ChatParticipantRoleRequest) (*models.ChatRoomParticipant, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	room, target, err := s.getManagedParticipant(ctx, actorID, roomID, userID, canManage)
+	if err != nil {
+		return nil, err
+	}
+	if actor := room.GetParticipant(actorID); !canManage && (actor == nil || actor.Role != models.ChatRoleOwner) {
+		return nil, models.ErrChatNotRoomStaff
+	}
+
+	if err := s.groupRepo.SetParticipantRole(ctx, roomID, userID, req.Role); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, models.AuditActionChatParticipantRole, models.AuditTargetChatRoom, roomID.String(),
+		map[string]interface{}{"user_id": userID, "role": target.Role},
+		map[string]interface{}{"user_id": userID, "role": req.Role})
+
+	target.Role = req.Role
+	return target, nil
+}
+
+// MuteParticipant запрещает участнику писать в комнату на указанное время. Модератор может
+// заглушить только обычного участника; владелец и управляющие каналами - также модератора
+func (s *ChatService) MuteParticipant(ctx context.Context, actorID, roomID, userID uuid.UUID, canManage bool, req *models.ChatMuteRequest) (*models.ChatRoomParticipant, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	mutedUntil := sql.NullTime{Time: time.Now().Add(time.Duration(req.Minutes) * time.Minute), Valid: true}
+	return s.setMute(ctx, actorID, roomID, userID, canManage, mutedUntil)
+}
+
+// UnmuteParticipant снимает мьют участника
+func (s *ChatService) UnmuteParticipant(ctx context.Context, actorID, roomID, userID uuid.UUID, canManage bool) (*models.ChatRoomParticipant, error) {
+	return s.setMute(ctx, actorID, roomID, userID, canManage, sql.NullTime{})
+}
+
+// setMute сохраняет мьют участника с проверкой прав действующего
+func (s *ChatService) setMute(ctx context.Context, actorID, roomID, userID uuid.UUID, canManage bool, mutedUntil sql.NullTime) (*models.ChatRoomParticipant, error) {
+	room, target, err := s.getManagedParticipant(ctx, actorID, roomID, userID, canManage)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage && target.Role == models.ChatRoleModerator {
+		if actor := room.GetParticipant(actorID); actor.Role != models.ChatRoleOwner {
+			return nil, models.ErrChatNotRoomStaff
+		}
+	}
+
+	if err := s.groupRepo.SetParticipantMute(ctx, roomID, userID, mutedUntil); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, models.AuditActionChatParticipantMute, models.AuditTargetChatRoom, roomID.String(),
+		map[string]interface{}{"user_id": userID, "muted_until": target.MutedUntil},
+		map[string]interface{}{"user_id": userID, "muted_until": mutedUntil})
+
+	target.MutedUntil = mutedUntil
+	return target, nil
+}
+
+// SyncLessonGroup создает комнату серии групповых занятий (если ее нет) и синхронизирует
+// участников с активными записями
+func (s *ChatService) SyncLessonGroup(ctx context.Context, recurringGroupID uuid.UUID) (*models.ChatRoom, error) {
+	if s.groupRepo == nil {
+		return nil, models.ErrChatNotGroupRoom
+	}
+	roomID, err := s.groupRepo.SyncLessonGroupRoom(ctx, recurringGroupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.chatRepo.GetRoomByID(ctx, roomID)
+}
+
+// getGroupRoom получает групповую комнату
+func (s *ChatService) getGroupRoom(ctx context.Context, roomID uuid.UUID) (*models.ChatRoom, error) {
+	if s.groupRepo == nil {
+		return nil, models.ErrChatNotGroupRoom
+	}
+	room, err := s.chatRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if !room.IsGroup() {
+		return nil, models.ErrChatNotGroupRoom
+	}
+	return room, nil
+}
+
+// getManagedParticipant получает участника, которым управляет actor: actor должен быть
+// владельцем или модератором комнаты (или управляющим каналами), владелец комнаты неизменяем
+func (s *ChatService) getManagedParticipant(ctx context.Context, actorID, roomID, userID uuid.UUID, canManage bool) (*models.ChatRoom, *models.ChatRoomParticipant, error) {
+	room, err := s.getGroupRoom(ctx, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canManage {
+		actor := room.GetParticipant(actorID)
+		if actor == nil || !actor.IsStaff() {
+			return nil, nil, models.ErrChatNotRoomStaff
+		}
+	}
+
+	target := room.GetParticipant(userID)
+	if target == nil {
+		return nil, nil, repository.ErrChatParticipantNotFound
+	}
+	if target.Role == models.ChatRoleOwner {
+		return nil, nil, models.ErrChatOwnerLocked
+	}
+	return room, target, nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/sse"
+)
+
+// chatMessageRepository интерфейс правок, ответов и реакций на сообщения
+type chatMessageRepository interface {
+	EditMessage(ctx context.Context, msgID, editorID uuid.UUID, text, status string) (time.Time, error)
+	ListMessageEdits(ctx context.Context, msgID uuid.UUID) ([]*models.MessageEdit, error)
+	GetMessagePreviews(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.MessagePreview, error)
+	ListReplies(ctx context.Context, msgID uuid.UUID, limit int) ([]*models.Message, error)
+	AddReaction(ctx context.Context, msgID, userID uuid.UUID, emoji string) (bool, error)
+	RemoveReaction(ctx context.Context, msgID, userID uuid.UUID, emoji string) (bool, error)
+	GetReactionsByMessages(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]models.MessageReactionSummary, error)
+}
+
+// SetMessageRepository подключает правки, ответы и реакции на сообщения
+func (s *ChatService) SetMessageRepository(repo chatMessageRepository) {
+	s.messageRepo = repo
+}
+
+// validateReplyTarget проверяет, что ответ ссылается на доставленное сообщение той же комнаты
+func (s *ChatService) validateReplyTarget(ctx context.Context, roomID uuid.UUID, replyToID *uuid.UUID) error {
+	if replyToID == nil {
+		return nil
+	}
+	target, err := s.chatRepo.GetMessageByID(ctx, *replyToID)
+	if err != nil {
+		return models.ErrInvalidReplyTarget
+	}
+	if target.RoomID != roomID || !target.IsDelivered() {
+		return models.ErrInvalidReplyTarget
+	}
+	return nil
+}
+
+// enrichMessages загружает цитаты исходных сообщений для ответов и реакции
+func (s *ChatService) enrichMessages(ctx context.Context, messages []*models.Message) error {
+	if s.messageRepo == nil || len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(messages))
+	var replyIDs []uuid.UUID
+	for _, msg := range messages {
+		ids = append(ids, msg.ID)
+		if msg.ReplyToMessageID.Valid {
+			replyIDs = append(replyIDs, msg.ReplyToMessageID.UUID)
+		}
+	}
+
+	previews, err := s.messageRepo.GetMessagePreviews(ctx, replyIDs)
+	if err != nil {
+		return err
+	}
+	reactions, err := s.messageRepo.GetReactionsByMessages(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if msg.ReplyToMessageID.Valid {
+			if preview, ok := previews[msg.ReplyToMessageID.UUID]; ok {
+				msg.ReplyTo = preview
+			} else {
+				msg.ReplyTo = models.UnavailableMessagePreview(msg.ReplyToMessageID.UUID)
+			}
+		}
+		msg.Reactions = reactions[msg.ID]
+	}
+	return nil
+}
+
+// EditMessage изменяет текст своего доставленного сообщения в течение MessageEditWindow.
+// Прежний текст сохраняется в истории. С включенной модерацией правка проходит ее заново:
+// до проверки сообщение скрыто (pending_moderation), после - участники получают message_edited
+func (s *ChatService) EditMessage(ctx context.Context, userID, messageID uuid.UUID, req *models.EditMessageRequest) (*models.Message, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if s.messageRepo == nil {
+		return nil, models.ErrMessageNotEditable
+	}
+
+	message, err := s.chatRepo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	room, err := s.GetRoomByID(ctx, message.RoomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := message.CanEdit(userID, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := room.CanPost(userID, time.Now()); err != nil {
+		return nil, err
+	}
+	if message.MessageText == req.MessageText {
+		return message, nil
+	}
+
+	status := models.MessageStatusDelivered
+	if s.moderationService != nil {
+		status = models.MessageStatusPendingModeration
+	}
+
+	editedAt, err := s.messageRepo.EditMessage(ctx, messageID, userID, req.MessageText, status)
+	if err != nil {
+		return nil, err
+	}
+	message.MessageText = req.MessageText
+	message.Status = status
+	message.EditedAt.Time, message.EditedAt.Valid = editedAt, true
+
+	if s.moderationService != nil {
+		// Участники скрывают сообщение до окончания повторной модерации
+		s.sendRoomEvent(room.ID, models.MessageStatusUpdatedEvent(room.ID, messageID, status), uuid.Nil)
+		s.moderationService.ModerateMessageAsync(ctx, messageID)
+		return message, nil
+	}
+
+	s.sendRoomEvent(room.ID, models.MessageEditedEvent(message), uuid.Nil)
+	return message, nil
+}
+
+// GetMessageEdits возвращает историю правок сообщения участнику комнаты
+func (s *ChatService) GetMessageEdits(ctx context.Context, userID, messageID uuid.UUID, canReadAll bool) ([]*models.MessageEdit, error) {
+	message, err := s.getVisibleMessage(ctx, userID, messageID, canReadAll)
+	if err != nil {
+		return nil, err
+	}
+	if s.messageRepo == nil {
+		return []*models.MessageEdit{}, nil
+	}
+	return s.messageRepo.ListMessageEdits(ctx, message.ID)
+}
+
+// GetReplies возвращает доставленные ответы на сообщение (ветку обсуждения)
+func (s *ChatService) GetReplies(ctx context.Context, userID, messageID uuid.UUID, canReadAll bool) ([]*models.Message, error) {
+	message, err := s.getVisibleMessage(ctx, userID, messageID, canReadAll)
+	if err != nil {
+		return nil, err
+	}
+	if s.messageRepo == nil {
+		return []*models.Message{}, nil
+	}
+
+	replies, err := s.messageRepo.ListReplies(ctx, message.ID, models.MaxRepliesPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.enrichMessages(ctx, replies); err != nil {
+		return nil, fmt.Errorf("failed to load replies details: %w", err)
+	}
+	return replies, nil
+}
+
+// AddReaction ставит реакцию на доставленное сообщение. Заглушенные участники реакции не ставят
+func (s *ChatService) AddReaction(ctx context.Context, userID, messageID uuid.UUID, req *models.ReactionRequest) ([]models.MessageReactionSummary, error) {
+	return s.changeReaction(ctx, userID, messageID, req, true)
+}
+
+// RemoveReaction снимает реакцию пользователя с сообщения
+func (s *ChatService) RemoveReaction(ctx context.Context, userID, messageID uuid.UUID, req *models.ReactionRequest) ([]models.MessageReactionSummary, error) {
+	return s.changeReaction(ctx, userID, messageID, req, false)
+}
+
+// changeReaction добавляет или снимает реакцию и рассылает участникам reaction_updated
+func (s *ChatService) changeReaction(ctx context.Context, userID, messageID uuid.UUID, req *models.ReactionRequest, add bool) ([]models.MessageReactionSummary, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if s.messageRepo == nil {
+		return nil, models.ErrInvalidReaction
+	}
+
+	message, err := s.getVisibleMessage(ctx, userID, messageID, false)
+	if err != nil {
+		return nil, err
+	}
+	room, err := s.chatRepo.GetRoomByID(ctx, message.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if p := room.GetParticipant(userID); add && p != nil && p.IsMuted(time.Now()) {
+		return nil, models.ErrChatParticipantMuted
+	}
+
+	var changed bool
+	if add {
+		changed, err = s.messageRepo.AddReaction(ctx, messageID, userID, req.Emoji)
+	} else {
+		changed, err = s.messageRepo.RemoveReaction(ctx, messageID, userID, req.Emoji)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, err := s.messageRepo.GetReactionsByMessages(ctx, []uuid.UUID{messageID})
+	if err != nil {
+		return nil, err
+	}
+	summary := reactions[messageID]
+	if summary == nil {
+		summary = []models.MessageReactionSummary{}
+	}
+
+	if changed {
+		s.sendRoomEvent(room.ID, models.ReactionUpdatedEvent(room.ID, messageID, summary), uuid.Nil)
+	}
+	return summary, nil
+}
+
+// getVisibleMessage получает доставленное сообщение с проверкой участия в комнате.
+// Сообщения на модерации и заблокированные недоступны так же, как в истории чата
+func (s *ChatService) getVisibleMessage(ctx context.Context, userID, messageID uuid.UUID, canReadAll bool) (*models.Message, error) {
+	message, err := s.chatRepo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if !message.IsDelivered() {
+		return nil, repository.ErrMessageNotFound
+	}
+	if canReadAll {
+		return message, nil
+	}
+	if _, err := s.GetRoomByID(ctx, message.RoomID, userID); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// sendRoomEvent отправляет SSE событие участникам комнаты
+func (s *ChatService) sendRoomEvent(roomID uuid.UUID, event models.SSEEvent, excludeUserID uuid.UUID) {
+	if s.sseManager == nil {
+		return
+	}
+	s.sseManager.SendToChat(roomID, sse.EventUUID{Type: event.Type, Data: event.Data}, excludeUserID)
+}
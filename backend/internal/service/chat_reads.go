@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/sse"
+)
+
+// chatReadRepository интерфейс отметок прочитанного и счетчиков непрочитанных сообщений
+type chatReadRepository interface {
+	MarkRoomRead(ctx context.Context, roomID, userID uuid.UUID, messageID *uuid.UUID) (*models.ChatReadState, bool, error)
+	ListReadStates(ctx context.Context, roomID uuid.UUID) ([]*models.ChatReadState, error)
+	CountUnreadByRoom(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int, error)
+}
+
+// SetReadRepository подключает отметки прочитанного. Без него счетчики непрочитанных равны нулю
+func (s *ChatService) SetReadRepository(repo chatReadRepository) {
+	s.readRepo = repo
+}
+
+// fillUnreadCounts проставляет комнатам число непрочитанных пользователем сообщений
+func (s *ChatService) fillUnreadCounts(ctx context.Context, userID uuid.UUID, rooms []*models.ChatRoom) error {
+	if s.readRepo == nil || len(rooms) == 0 {
+		return nil
+	}
+	counts, err := s.readRepo.CountUnreadByRoom(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, room := range rooms {
+		room.UnreadCount = counts[room.ID]
+	}
+	return nil
+}
+
+// GetUnreadSummary возвращает общее число непрочитанных сообщений пользователя для бейджа
+func (s *ChatService) GetUnreadSummary(ctx context.Context, userID uuid.UUID) (models.ChatUnreadSummary, error) {
+	if s.readRepo == nil {
+		return models.ChatUnreadSummary{}, nil
+	}
+	counts, err := s.readRepo.CountUnreadByRoom(ctx, userID)
+	if err != nil {
+		return models.ChatUnreadSummary{}, err
+	}
+	return models.NewChatUnreadSummary(counts), nil
+}
+
+// MarkRoomRead отмечает комнату прочитанной участником и рассылает остальным участникам
+// SSE событие message_read, если отметка сдвинулась. Возвращает nil, если в комнате нет сообщений
+func (s *ChatService) MarkRoomRead(ctx context.Context, userID, roomID uuid.UUID, req *models.MarkReadRequest) (*models.ChatReadState, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if s.readRepo == nil {
+		return nil, nil
+	}
+	if _, err := s.GetRoomByID(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	state, advanced, err := s.readRepo.MarkRoomRead(ctx, roomID, userID, req.MessageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if advanced && s.sseManager != nil {
+		event := models.MessageReadEvent(state)
+		s.sseManager.SendToChat(roomID, sse.EventUUID{Type: event.Type, Data: event.Data}, userID)
+	}
+	return state, nil
+}
+
+// ListReadStates возвращает отметки прочитанного участников комнаты (read receipts)
+func (s *ChatService) ListReadStates(ctx context.Context, roomID, userID uuid.UUID, canReadAll bool) ([]*models.ChatReadState, error) {
+	room, err := s.chatRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if !canReadAll && !room.IsParticipant(userID) {
+		return nil, repository.ErrUnauthorized
+	}
+	if s.readRepo == nil {
+		return []*models.ChatReadState{}, nil
+	}
+	return s.readRepo.ListReadStates(ctx, roomID)
+}
+
+// SendTyping рассылает участникам комнаты эфемерное SSE событие typing. Событие не сохраняется;
+// заглушенные участники и читатели каналов индикатор не отправляют
+func (s *ChatService) SendTyping(ctx context.Context, userID, roomID uuid.UUID) error {
+	room, err := s.GetRoomByID(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if err := room.CanPost(userID, time.Now()); err != nil {
+		return err
+	}
+	if s.sseManager == nil {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	event := models.TypingEvent(roomID, userID, user.GetFullName())
+	s.sseManager.SendToChat(roomID, sse.EventUUID{Type: event.Type, Data: event.Data}, userID)
+	return nil
+}
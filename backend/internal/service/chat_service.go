@@ -15,10 +15,14 @@ import (
 // ChatService обрабатывает бизнес-логику для чатов и сообщений
 type ChatService struct {
 	chatRepo          chatServiceRepository
+	groupRepo         chatGroupRepository
+	readRepo          chatReadRepository
+	messageRepo       chatMessageRepository
 	userRepo          chatServiceUserRepository
 	moderationService *ModerationService
 	sseManager        *sse.ConnectionManagerUUID
 	telegramService   *TelegramService
+	audit             *AuditService
 }
 
 // chatServiceRepository - интерфейс для dependency injection в тестах
@@ -68,6 +72,11 @@ func (s *ChatService) SetTelegramService(service *TelegramService) {
 	s.telegramService = service
 }
 
+// SetAuditService устанавливает журнал аудита для действий администратора в чатах
+func (s *ChatService) SetAuditService(audit *AuditService) {
+	s.audit = audit
+}
+
 // ==================== Chat Room Methods ====================
 
 // GetOrCreateRoom получает существующую комнату или создает новую для текущего пользователя и другого участника
@@ -136,6 +145,15 @@ func (s *ChatService) GetUserChats(ctx context.Context, userID uuid.UUID, role s
 		return nil, fmt.Errorf("failed to list user chats: %w", err)
 	}
 
+	rooms, err = s.appendGroupRooms(ctx, userID, rooms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user group chats: %w", err)
+	}
+
+	if err := s.fillUnreadCounts(ctx, userID, rooms); err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
 	return rooms, nil
 }
 
@@ -183,6 +201,13 @@ func (s *ChatService) SendMessage(ctx context.Context, senderID uuid.UUID, req *
 	if !room.IsParticipant(senderID) {
 		return nil, repository.ErrUnauthorized
 	}
+	// В групповых комнатах заглушенные участники не пишут, в каналы пишут только владельцы и модераторы
+	if err := room.CanPost(senderID, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.validateReplyTarget(ctx, room.ID, req.ReplyToMessageID); err != nil {
+		return nil, err
+	}
 
 	// Получаем информацию об отправителе для уведомления
 	sender, err := s.userRepo.GetByID(ctx, senderID)
@@ -190,12 +215,20 @@ func (s *ChatService) SendMessage(ctx context.Context, senderID uuid.UUID, req *
 		return nil, fmt.Errorf("failed to get sender: %w", err)
 	}
 
-	// Создаем сообщение со статусом delivered (временно без модерации)
+	// С включенной модерацией сообщение ждет проверки и доставляется получателю после нее
+	status := models.MessageStatusDelivered
+	if s.moderationService != nil {
+		status = models.MessageStatusPendingModeration
+	}
+
 	message := &models.Message{
 		RoomID:      req.RoomID,
 		SenderID:    senderID,
 		MessageText: req.MessageText,
-		Status:      string(models.MessageStatusDelivered),
+		Status:      status,
+	}
+	if req.ReplyToMessageID != nil {
+		message.ReplyToMessageID = uuid.NullUUID{UUID: *req.ReplyToMessageID, Valid: true}
 	}
 
 	if err := s.chatRepo.CreateMessage(ctx, message); err != nil {
@@ -207,39 +240,75 @@ func (s *ChatService) SendMessage(ctx context.Context, senderID uuid.UUID, req *
 		fmt.Printf("[WARN] Failed to update last_message_at for room %s: %v\n", req.RoomID, err)
 	}
 
+	if s.moderationService != nil {
+		// Получатели будут уведомлены из DeliverMessage после модерации
+		s.moderateMessageAsync(message.ID, message.RoomID)
+		return message, nil
+	}
+
+	s.notifyRecipients(ctx, room, sender, message)
+	return message, nil
+}
+
+// DeliverMessage уведомляет получателей о сообщении, прошедшем модерацию
+// (или блокировка которого снята администратором). Используется как обработчик доставки ModerationService
+func (s *ChatService) DeliverMessage(ctx context.Context, message *models.Message) {
+	// Отредактированное сообщение уже было доставлено: обновляем его у участников без повторных уведомлений
+	if message.IsEdited() {
+		s.sendRoomEvent(message.RoomID, models.MessageEditedEvent(message), uuid.Nil)
+		return
+	}
+
+	room, err := s.chatRepo.GetRoomByID(ctx, message.RoomID)
+	if err != nil {
+		fmt.Printf("[ERROR] Failed to get room %s for message delivery: %v\n", message.RoomID, err)
+		return
+	}
+	sender, err := s.userRepo.GetByID(ctx, message.SenderID)
+	if err != nil {
+		fmt.Printf("[ERROR] Failed to get sender %s for message delivery: %v\n", message.SenderID, err)
+		return
+	}
+	s.notifyRecipients(ctx, room, sender, message)
+}
+
+// notifyRecipients отправляет SSE событие участникам комнаты (кроме отправителя) и уведомление в Telegram
+func (s *ChatService) notifyRecipients(ctx context.Context, room *models.ChatRoom, sender *models.User, message *models.Message) {
 	// SSE broadcast: отправляем участникам чата (кроме отправителя)
 	if s.sseManager != nil {
-		event := models.NewMessageEventFromMessage(req.RoomID, message)
+		event := models.NewMessageEventFromMessage(room.ID, message)
 		sseEvent := sse.EventUUID{
 			Type: event.Type,
 			Data: event.Data,
 		}
-		s.sseManager.SendToChat(req.RoomID, sseEvent, senderID)
+		s.sseManager.SendToChat(room.ID, sseEvent, sender.ID)
 	}
 
 	// Отправляем уведомление в Telegram получателю
 	if s.telegramService != nil {
-		var recipientID uuid.UUID
-		if room.StudentID == senderID {
-			recipientID = room.TeacherID
-		} else {
-			recipientID = room.StudentID
+		senderName := sender.GetFullName()
+		if !room.IsGroup() {
+			recipientID := room.GetOtherParticipant(sender.ID)
+			notificationText := fmt.Sprintf("💬 Новое сообщение от %s:\n\n%s", senderName, message.MessageText)
+			go s.telegramService.SendUserNotification(ctx, recipientID, notificationText)
+			return
 		}
 
-		senderName := sender.GetFullName()
-		notificationText := fmt.Sprintf("💬 Новое сообщение от %s:\n\n%s", senderName, req.MessageText)
-		go s.telegramService.SendUserNotification(ctx, recipientID, notificationText)
+		// Групповая комната: уведомляем всех участников кроме отправителя одной горутиной
+		notificationText := fmt.Sprintf("💬 %s, новое сообщение от %s:\n\n%s", room.Title, senderName, message.MessageText)
+		recipients := room.ParticipantIDs()
+		go func() {
+			for _, recipientID := range recipients {
+				if recipientID != sender.ID {
+					_ = s.telegramService.SendUserNotification(ctx, recipientID, notificationText)
+				}
+			}
+		}()
 	}
-
-	// Модерация временно отключена - все сообщения доставляются напрямую
-	// TODO: включить модерацию после стабилизации системы
-	// go s.moderateMessageAsync(message.ID, message.RoomID)
-
-	return message, nil
 }
 
 // moderateMessageAsync выполняет асинхронную модерацию сообщения
-// Вызывается в горутине из SendMessage
+// Вызывается из SendMessage, сама модерация выполняется в горутине ModerationService
 func (s *ChatService) moderateMessageAsync(messageID uuid.UUID, roomID uuid.UUID) {
 	ctx := context.Background()
 
@@ -286,6 +355,11 @@ func (s *ChatService) GetChatHistory(ctx context.Context, userID uuid.UUID, role
 	if !isAdmin && !room.IsParticipant(userID) {
 		return nil, repository.ErrUnauthorized
 	}
+	// Чтение чужой переписки администратором фиксируется в журнале (только первая страница)
+	if isAdmin && !room.IsParticipant(userID) && req.Offset == 0 {
+		s.audit.Record(ctx, models.AuditActionChatRoomView, models.AuditTargetChatRoom, room.ID.String(), nil,
+			map[string]interface{}{"teacher_id": room.TeacherID, "student_id": room.StudentID})
+	}
 
 	// Получаем сообщения (только delivered)
 	messages, err := s.chatRepo.GetMessagesByRoom(ctx, req.RoomID, req.Limit, req.Offset)
@@ -310,6 +384,10 @@ func (s *ChatService) GetChatHistory(ctx context.Context, userID uuid.UUID, role
 		}
 	}
 
+	if err := s.enrichMessages(ctx, messages); err != nil {
+		return nil, fmt.Errorf("failed to load replies and reactions: %w", err)
+	}
+
 	return messages, nil
 }
 
@@ -446,7 +524,6 @@ func (s *ChatService) GetMessageWithAttachments(ctx context.Context, userID, mes
 	if err != nil {
 		// Логируем ошибку но продолжаем (вложения необязательны)
 		fmt.Printf("[WARN] Failed to get attachments for message %s: %v\n", messageID, err)
-		return message, nil
 	}
 
 	// Конвертируем []*FileAttachment в []FileAttachment
@@ -456,6 +533,10 @@ func (s *ChatService) GetMessageWithAttachments(ctx context.Context, userID, mes
 		}
 	}
 
+	if err := s.enrichMessages(ctx, []*models.Message{message}); err != nil {
+		fmt.Printf("[WARN] Failed to get replies and reactions for message %s: %v\n", messageID, err)
+	}
+
 	return message, nil
 }
 
@@ -480,6 +561,11 @@ func (s *ChatService) DeleteMessage(ctx context.Context, userID, messageID uuid.
 	if err := s.chatRepo.SoftDeleteMessage(ctx, messageID); err != nil {
 		return fmt.Errorf("failed to delete message: %w", err)
 	}
+	// Удаление собственного сообщения не является привилегированным действием
+	if message.SenderID != userID {
+		s.audit.Record(ctx, models.AuditActionChatMessageDelete, models.AuditTargetMessage, messageID.String(),
+			map[string]interface{}{"room_id": message.RoomID, "sender_id": message.SenderID, "text": message.MessageText}, nil)
+	}
 
 	if s.sseManager != nil {
 		event := models.MessageDeletedEvent(message.RoomID, messageID)
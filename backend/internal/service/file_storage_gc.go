@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/storage"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultGCGracePeriod сколько хранятся файлы удаленных сообщений и занятий и новые объекты без записи о файле
+const defaultGCGracePeriod = 72 * time.Hour
+
+// StorageGCResult итог прохода очистки хранилища
+type StorageGCResult struct {
+	DryRun bool `json:"dry_run"`
+	// Scanned сколько объектов BlobStore и файлов в каталогах старых загрузок проверено
+	Scanned int `json:"scanned"`
+	// Orphaned сколько файлов без используемых записей удалено (при dry_run - было бы удалено)
+	Orphaned   int   `json:"orphaned"`
+	FreedBytes int64 `json:"freed_bytes"`
+	// Recent файлы без записей, которые моложе срока хранения и пока не удаляются
+	Recent int `json:"recent"`
+	Failed int `json:"failed"`
+}
+
+// SetGarbageCollection задает срок хранения файлов без используемых записей (0 - по умолчанию) и каталоги
+// старых загрузок (source -> каталог на диске), которые сверяются с записями о файлах наравне с BlobStore
+func (s *FileStorageService) SetGarbageCollection(gracePeriod time.Duration, legacyDirs map[string]string) {
+	if gracePeriod > 0 {
+		s.gcGracePeriod = gracePeriod
+	}
+	s.legacyDirs = legacyDirs
+}
+
+// StartGCWorker запускает периодическую очистку хранилища
+func (s *FileStorageService) StartGCWorker(interval time.Duration) {
+	s.stopGC = make(chan struct{})
+	s.gcDone = make(chan struct{})
+	go s.gcLoop(interval)
+}
+
+// Shutdown останавливает периодическую очистку хранилища (для graceful shutdown)
+func (s *FileStorageService) Shutdown() {
+	if s.stopGC == nil {
+		return
+	}
+	close(s.stopGC)
+	<-s.gcDone
+	s.stopGC = nil
+	log.Info().Msg("Storage garbage collector shutdown complete")
+}
+
+// gcLoop периодически запускает проход очистки
+func (s *FileStorageService) gcLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer close(s.gcDone)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		// Прерываем долгий проход при остановке сервера
+		select {
+		case <-s.stopGC:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			result, err := s.RunGC(ctx, false)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Error().Err(err).Msg("Storage garbage collection failed")
+				}
+				continue
+			}
+			if result.Orphaned > 0 || result.Failed > 0 {
+				log.Info().Int("orphaned", result.Orphaned).Int64("freed_bytes", result.FreedBytes).
+					Int("failed", result.Failed).Msg("Storage garbage collection completed")
+			}
+		case <-s.stopGC:
+			log.Info().Msg("Storage garbage collector shutting down")
+			return
+		}
+	}
+}
+
+// RunGC удаляет файлы, на которые не ссылается ни одна используемая запись о файле. Записи сообщений, чатов
+// и занятий, удаленных раньше срока хранения, не считаются используемыми, поэтому мягкое удаление освобождает место
+// по истечении срока. Объекты моложе срока хранения не удаляются: запись о только что загруженном файле
+// создается после сохранения объекта, а объект, на который загрузка попала дедупликацией, защищен ее резервом.
+// Карантин не очищается. С dryRun только подсчитывает файлы
+func (s *FileStorageService) RunGC(ctx context.Context, dryRun bool) (*StorageGCResult, error) {
+	cutoff := time.Now().Add(-s.gcGracePeriod)
+	result := &StorageGCResult{DryRun: dryRun}
+
+	if !dryRun {
+		if _, err := s.blobRepo.PurgeReservations(ctx, time.Now().Add(-blobReservationTTL)); err != nil {
+			return nil, err
+		}
+	}
+
+	live, err := s.blobRepo.ListLiveKeys(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	lister, ok := s.store.(storage.Lister)
+	if !ok {
+		log.Warn().Msg("File storage does not support listing, only legacy upload directories are collected")
+	} else {
+		prefixes := append(append([]string{}, models.StoredFileSources...), models.ThumbnailPrefix)
+		for _, prefix := range prefixes {
+			err := lister.List(ctx, prefix, func(info *storage.ObjectInfo) error {
+				result.Scanned++
+				if _, ok := live[info.Key]; ok {
+					return nil
+				}
+				if info.ModTime.After(cutoff) {
+					result.Recent++
+					return nil
+				}
+				s.collectObject(ctx, info, cutoff, result)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for source, dir := range s.legacyDirs {
+		if err := s.collectLegacyDir(ctx, source, dir, cutoff, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// collectObject удаляет объект без ссылок. Ссылки перепроверяются под блокировкой ключа: пока шел обход,
+// новая загрузка могла попасть дедупликацией на этот объект (резерв) или запись могла сослаться на него
+func (s *FileStorageService) collectObject(ctx context.Context, info *storage.ObjectInfo, cutoff time.Time, result *StorageGCResult) {
+	collected := false
+	err := s.blobRepo.LockKey(ctx, info.Key, func(tx *sqlx.Tx) error {
+		referenced, err := s.blobRepo.IsLiveReferencedTx(ctx, tx, info.Key, cutoff, time.Now().Add(-blobReservationTTL))
+		if err != nil || referenced {
+			return err
+		}
+		if !result.DryRun {
+			if err := s.store.Delete(ctx, info.Key); err != nil {
+				return err
+			}
+		}
+		collected = true
+		return nil
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("storage_key", info.Key).Msg("Failed to collect orphaned stored file")
+		result.Failed++
+		return
+	}
+	if collected {
+		result.Orphaned++
+		result.FreedBytes += info.Size
+	}
+}
+
+// collectLegacyDir удаляет из каталога старых загрузок файлы, которых нет среди используемых записей источника,
+// и оставшиеся пустыми подкаталоги. Файлы сверяются по имени: старые обработчики давали файлам уникальные имена
+func (s *FileStorageService) collectLegacyDir(ctx context.Context, source, dir string, cutoff time.Time, result *StorageGCResult) error {
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	paths, err := s.blobRepo.ListLiveLegacyPaths(ctx, source, cutoff)
+	if err != nil {
+		return err
+	}
+	live := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		live[filepath.Base(p)] = struct{}{}
+	}
+
+	var dirs []string
+	err = filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() {
+			if filePath != dir {
+				dirs = append(dirs, filePath)
+			}
+			return nil
+		}
+
+		result.Scanned++
+		if _, ok := live[entry.Name()]; ok {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil // удален во время обхода
+		}
+		if info.ModTime().After(cutoff) {
+			result.Recent++
+			return nil
+		}
+
+		if !result.DryRun {
+			if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Warn().Err(err).Str("path", filePath).Msg("Failed to delete orphaned legacy file")
+				result.Failed++
+				return nil
+			}
+		}
+		result.Orphaned++
+		result.FreedBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !result.DryRun {
+		// Вложенные каталоги идут в обходе после родительских, поэтому удаляются с конца
+		for i := len(dirs) - 1; i >= 0; i-- {
+			os.Remove(dirs[i]) // непустой каталог не удаляется
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// SetQuotas включает учет квот хранилища. defaultQuotaBytes - квота пользователей без индивидуальной квоты
+// (0 - без ограничения)
+func (s *FileStorageService) SetQuotas(quotaRepo *repository.StorageQuotaRepository, defaultQuotaBytes int64) {
+	s.quotaRepo = quotaRepo
+	s.defaultQuota = defaultQuotaBytes
+}
+
+// checkQuota проверяет, что после загрузки файла размером size пользователь не превысит квоту.
+// Одновременные загрузки одного пользователя могут превысить квоту на размер последнего файла
+func (s *FileStorageService) checkQuota(ctx context.Context, userID uuid.UUID, size int64) error {
+	if s.quotaRepo == nil || userID == uuid.Nil {
+		return nil
+	}
+
+	status, err := s.GetQuotaStatus(ctx, userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to check storage quota")
+		return models.ErrFileStorageFailed
+	}
+	if status.QuotaBytes != nil && status.UsedBytes+size > *status.QuotaBytes {
+		log.Info().Str("user_id", userID.String()).Int64("used_bytes", status.UsedBytes).
+			Int64("quota_bytes", *status.QuotaBytes).Int64("file_size", size).Msg("Upload rejected by storage quota")
+		return models.ErrStorageQuotaExceeded
+	}
+	return nil
+}
+
+// GetQuotaStatus возвращает занятое пользователем место и его действующую квоту
+func (s *FileStorageService) GetQuotaStatus(ctx context.Context, userID uuid.UUID) (*models.StorageQuotaStatus, error) {
+	status := &models.StorageQuotaStatus{UserID: userID}
+	if s.quotaRepo == nil {
+		return status, nil
+	}
+
+	used, err := s.quotaRepo.GetUsage(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	override, err := s.quotaRepo.GetQuota(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status.UsedBytes = used
+	status.Override = override.Valid
+	status.QuotaBytes = s.effectiveQuota(override.Int64, override.Valid)
+	return status, nil
+}
+
+// effectiveQuota возвращает индивидуальную квоту, если она задана, иначе квоту по умолчанию; nil - без ограничения
+func (s *FileStorageService) effectiveQuota(override int64, hasOverride bool) *int64 {
+	if hasOverride {
+		return &override
+	}
+	if s.defaultQuota > 0 {
+		quota := s.defaultQuota
+		return &quota
+	}
+	return nil
+}
+
+// ListUsage получает отчет об использовании хранилища по пользователям или преподавателям
+func (s *FileStorageService) ListUsage(ctx context.Context, filter *models.StorageUsageFilter) ([]*models.StorageUsage, int, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+	if s.quotaRepo == nil {
+		return []*models.StorageUsage{}, 0, nil
+	}
+
+	items, total, err := s.quotaRepo.ListUsage(ctx, filter.GroupBy, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, item := range items {
+		item.QuotaBytes = s.effectiveQuota(item.QuotaOverride.Int64, item.QuotaOverride.Valid)
+	}
+	return items, total, nil
+}
+
+// SetQuota задает пользователю индивидуальную квоту и возвращает его новый статус
+func (s *FileStorageService) SetQuota(ctx context.Context, userID uuid.UUID, req *models.SetStorageQuotaRequest, updatedBy uuid.UUID) (*models.StorageQuotaStatus, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if s.quotaRepo == nil {
+		return nil, models.ErrFileStorageFailed
+	}
+	if err := s.quotaRepo.SetQuota(ctx, userID, *req.QuotaBytes, updatedBy); err != nil {
+		return nil, err
+	}
+	return s.GetQuotaStatus(ctx, userID)
+}
+
+// ResetQuota удаляет индивидуальную квоту пользователя (действует квота по умолчанию) и возвращает его новый статус
+func (s *FileStorageService) ResetQuota(ctx context.Context, userID uuid.UUID) (*models.StorageQuotaStatus, error) {
+	if s.quotaRepo == nil {
+		return nil, models.ErrFileStorageFailed
+	}
+	if err := s.quotaRepo.DeleteQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.GetQuotaStatus(ctx, userID)
+}
@@ -0,0 +1,422 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/storage"
+	"tutoring-platform/internal/upload"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// FileStorageService сохраняет загруженные файлы в BlobStore с дедупликацией по хешу содержимого
+// и выдает их на скачивание: временной ссылкой на хранилище (S3) или потоком через API.
+// Перед сохранением файл проходит проверку: тип по содержимому, антивирус, очистка изображений от метаданных
+type FileStorageService struct {
+	store          storage.BlobStore
+	blobRepo       *repository.BlobRepository
+	presignTTL     time.Duration
+	scanner        upload.Scanner
+	quarantineRepo *repository.QuarantineRepository
+	thumbnailer    *upload.Thumbnailer
+	thumbnailSlots chan struct{}
+	quotaRepo      *repository.StorageQuotaRepository
+	defaultQuota   int64
+	gcGracePeriod  time.Duration
+	legacyDirs     map[string]string
+	stopGC         chan struct{}
+	gcDone         chan struct{}
+}
+
+// thumbnailTimeout ограничение на построение одного превью
+const thumbnailTimeout = 2 * time.Minute
+
+// blobReservationTTL сколько объект, сохраненный загрузкой, считается используемым до создания записи о файле
+const blobReservationTTL = time.Hour
+
+// maxConcurrentThumbnails сколько превью строится одновременно (декодирование изображений нагружает CPU и память)
+const maxConcurrentThumbnails = 2
+
+// NewFileStorageService создает новый FileStorageService.
+// presignTTL = 0 отключает прямые ссылки на хранилище: файлы всегда отдаются через API
+func NewFileStorageService(store storage.BlobStore, blobRepo *repository.BlobRepository, presignTTL time.Duration) *FileStorageService {
+	return &FileStorageService{
+		store:         store,
+		blobRepo:      blobRepo,
+		presignTTL:    presignTTL,
+		gcGracePeriod: defaultGCGracePeriod,
+	}
+}
+
+// SetScanner включает антивирусную проверку загрузок; зараженные файлы сохраняются в карантин
+func (s *FileStorageService) SetScanner(scanner upload.Scanner, quarantineRepo *repository.QuarantineRepository) {
+	s.scanner = scanner
+	s.quarantineRepo = quarantineRepo
+}
+
+// Save проверяет загруженный файл и сохраняет его под ключом "<source>/<ab>/<sha256><ext>".
+// Тип, определенный по содержимому, должен совпадать с заявленным contentType и расширением имени файла;
+// загрузка сверх квоты пользователя отклоняется (ErrStorageQuotaExceeded);
+// зараженный файл уходит в карантин (ErrFileInfected); изображения сохраняются без метаданных,
+// поэтому размер Blob может отличаться от размера загрузки.
+// Расширение берется из исходного имени файла; повторная загрузка того же содержимого не создает новый объект
+func (s *FileStorageService) Save(ctx context.Context, source string, uploadedBy uuid.UUID, fileName string, r io.Reader, contentType string) (*storage.Blob, error) {
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create temp file for upload")
+		return nil, models.ErrFileStorageFailed
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		log.Error().Err(err).Str("source", source).Msg("Failed to read uploaded file")
+		return nil, models.ErrFileStorageFailed
+	}
+
+	detected := upload.DetectContentType(tmp, size)
+	if err := upload.VerifyType(detected, contentType, fileName); err != nil {
+		log.Warn().Str("source", source).Str("declared", contentType).Str("detected", detected).
+			Str("file_name", fileName).Str("uploaded_by", uploadedBy.String()).Msg("Rejected upload with mismatched content type")
+		return nil, models.ErrFileTypeMismatch
+	}
+
+	if err := s.checkQuota(ctx, uploadedBy, size); err != nil {
+		return nil, err
+	}
+
+	if s.scanner != nil {
+		if err := s.scan(ctx, source, uploadedBy, fileName, contentType, tmp, size); err != nil {
+			return nil, err
+		}
+	}
+
+	body := io.NewSectionReader(tmp, 0, size)
+	if upload.IsSanitizableImage(detected) {
+		var clean bytes.Buffer
+		if err := upload.SanitizeImage(&clean, body, detected); err != nil {
+			switch {
+			case errors.Is(err, upload.ErrImageTooLarge):
+				return nil, models.ErrImageTooLarge
+			case errors.Is(err, upload.ErrInvalidImage):
+				return nil, models.ErrFileTypeMismatch
+			}
+			log.Error().Err(err).Str("source", source).Msg("Failed to sanitize uploaded image")
+			return nil, models.ErrFileStorageFailed
+		}
+		body = io.NewSectionReader(bytes.NewReader(clean.Bytes()), 0, int64(clean.Len()))
+	}
+
+	blob, err := storage.PutDeduplicated(ctx, s.store, source, filepath.Ext(filepath.Base(fileName)), body, contentType, s.reserveKey)
+	if err != nil {
+		log.Error().Err(err).Str("source", source).Msg("Failed to store uploaded file")
+		return nil, models.ErrFileStorageFailed
+	}
+	return blob, nil
+}
+
+// reserveKey сохраняет объект под блокировкой ключа и резервирует его до создания записи о файле,
+// чтобы параллельные Release и очистка хранилища не удалили объект, на который ссылается новая загрузка
+func (s *FileStorageService) reserveKey(ctx context.Context, key string, put func() error) error {
+	return s.blobRepo.LockKey(ctx, key, func(tx *sqlx.Tx) error {
+		if err := put(); err != nil {
+			return err
+		}
+		return s.blobRepo.ReserveTx(ctx, tx, key)
+	})
+}
+
+// scan проверяет файл антивирусом. Зараженный файл сохраняется в карантин и возвращается ErrFileInfected;
+// если проверку выполнить не удалось, загрузка отклоняется (ErrFileScanFailed)
+func (s *FileStorageService) scan(ctx context.Context, source string, uploadedBy uuid.UUID, fileName, contentType string, file *os.File, size int64) error {
+	result, err := s.scanner.Scan(ctx, io.NewSectionReader(file, 0, size))
+	if err != nil {
+		log.Error().Err(err).Str("scanner", s.scanner.Name()).Str("source", source).Msg("Failed to scan uploaded file")
+		return models.ErrFileScanFailed
+	}
+	if !result.Infected {
+		return nil
+	}
+
+	log.Warn().Str("scanner", s.scanner.Name()).Str("signature", result.Signature).Str("source", source).
+		Str("file_name", fileName).Str("uploaded_by", uploadedBy.String()).Msg("Infected upload quarantined")
+	s.quarantine(ctx, &models.QuarantinedFile{
+		Source:     source,
+		FileName:   fileName,
+		MimeType:   contentType,
+		FileSize:   size,
+		Signature:  result.Signature,
+		Scanner:    s.scanner.Name(),
+		UploadedBy: uuid.NullUUID{UUID: uploadedBy, Valid: uploadedBy != uuid.Nil},
+	}, file)
+	return models.ErrFileInfected
+}
+
+// quarantine сохраняет содержимое зараженного файла под префиксом карантина (без расширения) и запись о нем.
+// Ошибки только логируются: загрузка в любом случае отклонена
+func (s *FileStorageService) quarantine(ctx context.Context, record *models.QuarantinedFile, file *os.File) {
+	blob, err := storage.PutDeduplicated(ctx, s.store, models.QuarantinePrefix, "", io.NewSectionReader(file, 0, record.FileSize), "application/octet-stream", nil)
+	if err != nil {
+		// Запись о карантине нужна и без содержимого: хеш позволяет найти файл в других источниках
+		log.Error().Err(err).Msg("Failed to store quarantined file")
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, io.NewSectionReader(file, 0, record.FileSize)); err != nil {
+			log.Error().Err(err).Msg("Failed to hash quarantined file")
+			return
+		}
+		record.ContentHash = hex.EncodeToString(hasher.Sum(nil))
+	} else {
+		record.ContentHash = blob.Hash
+		record.StorageKey = sql.NullString{String: blob.Key, Valid: true}
+	}
+
+	if s.quarantineRepo == nil {
+		return
+	}
+	if err := s.quarantineRepo.Create(ctx, record); err != nil {
+		log.Error().Err(err).Str("content_hash", record.ContentHash).Msg("Failed to record quarantined file")
+	}
+}
+
+// SetThumbnailer включает фоновое построение превью для вложений чата и домашних заданий
+func (s *FileStorageService) SetThumbnailer(thumbnailer *upload.Thumbnailer) {
+	s.thumbnailer = thumbnailer
+	s.thumbnailSlots = make(chan struct{}, maxConcurrentThumbnails)
+}
+
+// ThumbnailKey возвращает ключ превью для содержимого с данным хешем
+func ThumbnailKey(hash string) string {
+	return storage.ContentKey(models.ThumbnailPrefix, hash, ".jpg")
+}
+
+// GenerateThumbnailAsync строит превью сохраненного файла в фоне и привязывает его к записи source/id.
+// Ничего не делает, если превью отключены или для типа файла не строятся
+func (s *FileStorageService) GenerateThumbnailAsync(ctx context.Context, source string, id uuid.UUID, blob *storage.Blob, mimeType string) {
+	if s.thumbnailer == nil || !s.thumbnailer.Supports(mimeType) {
+		return
+	}
+
+	// Контекст отделен от запроса: превью строится после ответа клиенту
+	bgCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), thumbnailTimeout)
+	go func() {
+		defer cancel()
+		select {
+		case s.thumbnailSlots <- struct{}{}:
+			defer func() { <-s.thumbnailSlots }()
+		case <-bgCtx.Done():
+			return
+		}
+
+		if err := s.generateThumbnail(bgCtx, source, id, blob, mimeType); err != nil {
+			log.Warn().Err(err).Str("source", source).Str("id", id.String()).Str("storage_key", blob.Key).Msg("Failed to generate thumbnail")
+		}
+	}()
+}
+
+// generateThumbnail строит превью (если объекта превью для этого содержимого еще нет) и сохраняет ссылку на него
+func (s *FileStorageService) generateThumbnail(ctx context.Context, source string, id uuid.UUID, blob *storage.Blob, mimeType string) error {
+	thumbKey := ThumbnailKey(blob.Hash)
+
+	if _, err := s.store.Stat(ctx, thumbKey); errors.Is(err, storage.ErrNotFound) {
+		localPath, cleanup, err := s.LocalCopy(ctx, blob.Key)
+		if err != nil {
+			return fmt.Errorf("failed to get local copy: %w", err)
+		}
+		defer cleanup()
+
+		var thumb bytes.Buffer
+		if err := s.thumbnailer.Generate(ctx, &thumb, localPath, mimeType); err != nil {
+			return err
+		}
+		if err := s.store.Put(ctx, thumbKey, &thumb, int64(thumb.Len()), upload.ThumbnailMimeType); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	updated, err := s.blobRepo.SetThumbnailKey(ctx, source, id, thumbKey)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		// Запись удалена, пока строилось превью
+		s.releaseThumbnail(ctx, thumbKey)
+	}
+	return nil
+}
+
+// ListQuarantine получает записи о файлах в карантине, новые сверху
+func (s *FileStorageService) ListQuarantine(ctx context.Context, filter *models.QuarantineFilter) ([]*models.QuarantinedFile, int, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+	if s.quarantineRepo == nil {
+		return []*models.QuarantinedFile{}, 0, nil
+	}
+	return s.quarantineRepo.List(ctx, filter.Limit, filter.Offset)
+}
+
+// Open открывает объект на чтение; вызывающий закрывает ReadCloser
+func (s *FileStorageService) Open(ctx context.Context, key string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	return s.store.Get(ctx, key)
+}
+
+// PresignURL возвращает временную ссылку на скачивание напрямую из хранилища.
+// Пустая строка без ошибки - хранилище не поддерживает ссылки или они отключены
+func (s *FileStorageService) PresignURL(ctx context.Context, key, fileName, contentType string) (string, error) {
+	presigner, ok := s.store.(storage.Presigner)
+	if !ok || s.presignTTL <= 0 {
+		return "", nil
+	}
+	return presigner.PresignGet(ctx, key, fileName, contentType, s.presignTTL)
+}
+
+// LocalCopy возвращает путь к локальному файлу с содержимым объекта (нужен для отправки в Telegram);
+// cleanup удаляет временную копию
+func (s *FileStorageService) LocalCopy(ctx context.Context, key string) (string, func(), error) {
+	return storage.LocalCopy(ctx, s.store, key)
+}
+
+// Release удаляет объект, если на него больше не ссылается ни одна запись о файле и его не зарезервировала
+// недавняя загрузка того же содержимого. Проверка и удаление выполняются под блокировкой ключа, поэтому
+// не пересекаются с Save. Вызывается после удаления записи из БД; оставшийся объект удалит очистка хранилища
+func (s *FileStorageService) Release(ctx context.Context, key string) error {
+	deleted := false
+	err := s.blobRepo.LockKey(ctx, key, func(tx *sqlx.Tx) error {
+		referenced, err := s.blobRepo.IsBlobReferencedTx(ctx, tx, key, time.Now().Add(-blobReservationTTL))
+		if err != nil || referenced {
+			return err
+		}
+		if err := s.store.Delete(ctx, key); err != nil {
+			return err
+		}
+		deleted = true
+		return nil
+	})
+	if err != nil || !deleted {
+		return err
+	}
+
+	// Превью общее для одинакового содержимого: ключ превью выводится из хеша в имени объекта
+	hash := strings.TrimSuffix(path.Base(key), path.Ext(key))
+	if len(hash) == 64 {
+		s.releaseThumbnail(ctx, ThumbnailKey(hash))
+	}
+	return nil
+}
+
+// releaseThumbnail удаляет превью, на которое больше не ссылается ни одна запись
+func (s *FileStorageService) releaseThumbnail(ctx context.Context, thumbKey string) {
+	referenced, err := s.blobRepo.IsThumbnailReferenced(ctx, thumbKey)
+	if err != nil {
+		log.Warn().Err(err).Str("thumbnail_key", thumbKey).Msg("Failed to check thumbnail references")
+		return
+	}
+	if referenced {
+		return
+	}
+	if err := s.store.Delete(ctx, thumbKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Warn().Err(err).Str("thumbnail_key", thumbKey).Msg("Failed to delete thumbnail")
+	}
+}
+
+// ReleaseQuietly вызывает Release и только логирует ошибку: запись о файле к этому моменту уже удалена
+func (s *FileStorageService) ReleaseQuietly(ctx context.Context, key string) {
+	if err := s.Release(ctx, key); err != nil {
+		log.Warn().Err(err).Str("storage_key", key).Msg("Failed to release stored file")
+	}
+}
+
+// LegacyMigrationResult итог переноса файлов одного источника в BlobStore
+type LegacyMigrationResult struct {
+	Source   string
+	Total    int
+	Migrated int
+	Missing  int
+	Failed   int
+}
+
+// MigrateLegacyFiles переносит в BlobStore файлы источника, записи о которых еще ссылаются на локальный диск.
+// resolvePath возвращает путь файла на диске по записи. Без apply только подсчитывает найденные файлы.
+// С deleteLocal локальный файл удаляется после успешного переноса
+func (s *FileStorageService) MigrateLegacyFiles(
+	ctx context.Context,
+	source string,
+	resolvePath func(*models.LegacyFile) string,
+	apply, deleteLocal bool,
+) (*LegacyMigrationResult, error) {
+	files, err := s.blobRepo.ListLegacyFiles(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LegacyMigrationResult{Source: source, Total: len(files)}
+	for _, file := range files {
+		localPath := resolvePath(file)
+		if _, err := os.Stat(localPath); err != nil {
+			log.Warn().Err(err).Str("source", source).Str("id", file.ID.String()).Str("path", localPath).Msg("Legacy file is missing")
+			result.Missing++
+			continue
+		}
+		if !apply {
+			result.Migrated++
+			continue
+		}
+
+		if err := s.migrateLegacyFile(ctx, file, localPath, deleteLocal); err != nil {
+			log.Error().Err(err).Str("source", source).Str("id", file.ID.String()).Str("path", localPath).Msg("Failed to migrate legacy file")
+			result.Failed++
+			continue
+		}
+		result.Migrated++
+	}
+	return result, nil
+}
+
+// migrateLegacyFile загружает один файл в хранилище и привязывает к нему запись
+func (s *FileStorageService) migrateLegacyFile(ctx context.Context, file *models.LegacyFile, localPath string, deleteLocal bool) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open legacy file: %w", err)
+	}
+	blob, err := storage.PutDeduplicated(ctx, s.store, file.Source, filepath.Ext(file.FileName), f, file.MimeType, s.reserveKey)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.blobRepo.SetStorageKey(ctx, file.Source, file.ID, blob.Key, blob.Hash)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		// Запись удалена или перенесена параллельно - объект мог остаться без ссылок
+		s.ReleaseQuietly(ctx, blob.Key)
+		return nil
+	}
+
+	if deleteLocal {
+		if err := os.Remove(localPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Warn().Err(err).Str("path", localPath).Msg("Failed to remove migrated legacy file")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,599 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/pkg/metrics"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// groupEventsLimit ограничивает размер истории состава группы в ответе API
+const groupEventsLimit = 500
+
+// GroupService управляет учебными группами (когортами)
+// Участники группы автоматически записываются на все будущие занятия привязанных серий
+// со списанием кредитов и выписываются из них с возвратом кредитов
+type GroupService struct {
+	pool        txBeginner
+	groupRepo   groupServiceGroupRepository
+	lessonRepo  groupServiceLessonRepository
+	userRepo    repository.UserRepository
+	subjectRepo repository.SubjectRepository
+	creditRepo  groupServiceCreditRepository
+}
+
+// groupServiceGroupRepository - часть GroupRepository, используемая GroupService
+type groupServiceGroupRepository interface {
+	List(ctx context.Context, teacherID *uuid.UUID) ([]*models.GroupWithDetails, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.GroupWithDetails, error)
+	GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.Group, error)
+	Create(ctx context.Context, group *models.Group) error
+	Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetMembers(ctx context.Context, groupID uuid.UUID, includeFormer bool) ([]*models.GroupMember, error)
+	GetActiveMemberIDsTx(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error)
+	AddMemberTx(ctx context.Context, tx pgx.Tx, groupID, studentID, addedBy uuid.UUID) error
+	RemoveMemberTx(ctx context.Context, tx pgx.Tx, groupID, studentID, removedBy uuid.UUID) error
+	GetSeriesIDsTx(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error)
+	LinkSeriesTx(ctx context.Context, tx pgx.Tx, groupID, recurringGroupID, linkedBy uuid.UUID) error
+	UnlinkSeriesTx(ctx context.Context, tx pgx.Tx, groupID, recurringGroupID uuid.UUID) error
+	ListEvents(ctx context.Context, groupID uuid.UUID, limit int) ([]*models.GroupMembershipEvent, error)
+	LogEventTx(ctx context.Context, tx pgx.Tx, event *models.GroupMembershipEvent) error
+}
+
+// groupServiceLessonRepository - часть LessonRepository, используемая GroupService
+type groupServiceLessonRepository interface {
+	GetFutureSeriesLessonsForUpdate(ctx context.Context, tx pgx.Tx, recurringGroupIDs []uuid.UUID, after time.Time) ([]*models.Lesson, error)
+	GetBookingsByLessonTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) ([]*models.Booking, error)
+	IsStudentBookedForLessonTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, studentID uuid.UUID) (bool, error)
+	CreateBookingTx(ctx context.Context, tx pgx.Tx, booking *models.Booking) error
+	CancelBookingTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, studentID uuid.UUID) error
+	IncrementStudents(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) error
+	DecrementStudents(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) error
+}
+
+// groupServiceCreditRepository - часть CreditRepository, используемая GroupService
+type groupServiceCreditRepository interface {
+	GetBalanceForUpdate(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (*models.Credit, error)
+	UpdateBalance(ctx context.Context, tx pgx.Tx, userID uuid.UUID, newBalance int) error
+	CreateTransaction(ctx context.Context, tx pgx.Tx, transaction *models.CreditTransaction) error
+}
+
+// NewGroupService создает новый GroupService
+func NewGroupService(
+	pool txBeginner,
+	groupRepo groupServiceGroupRepository,
+	lessonRepo groupServiceLessonRepository,
+	userRepo repository.UserRepository,
+	subjectRepo repository.SubjectRepository,
+	creditRepo groupServiceCreditRepository,
+) *GroupService {
+	return &GroupService{
+		pool:        pool,
+		groupRepo:   groupRepo,
+		lessonRepo:  lessonRepo,
+		userRepo:    userRepo,
+		subjectRepo: subjectRepo,
+		creditRepo:  creditRepo,
+	}
+}
+
+// ListGroups возвращает группы (teacherID != nil - только группы преподавателя)
+func (s *GroupService) ListGroups(ctx context.Context, teacherID *uuid.UUID) ([]*models.GroupWithDetails, error) {
+	return s.groupRepo.List(ctx, teacherID)
+}
+
+// GetGroup возвращает группу по ID
+func (s *GroupService) GetGroup(ctx context.Context, id uuid.UUID) (*models.GroupWithDetails, error) {
+	return s.groupRepo.GetByID(ctx, id)
+}
+
+// GetMembers возвращает участников группы (includeFormer - включая исключённых)
+func (s *GroupService) GetMembers(ctx context.Context, groupID uuid.UUID, includeFormer bool) ([]*models.GroupMember, error) {
+	if _, err := s.groupRepo.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetMembers(ctx, groupID, includeFormer)
+}
+
+// GetMembershipHistory возвращает историю изменений состава группы
+func (s *GroupService) GetMembershipHistory(ctx context.Context, groupID uuid.UUID) ([]*models.GroupMembershipEvent, error) {
+	if _, err := s.groupRepo.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.ListEvents(ctx, groupID, groupEventsLimit)
+}
+
+// CreateGroup создает группу
+func (s *GroupService) CreateGroup(ctx context.Context, adminID uuid.UUID, req *models.CreateGroupRequest) (*models.GroupWithDetails, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.validateTeacher(ctx, req.TeacherID); err != nil {
+		return nil, err
+	}
+
+	group := &models.Group{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		TeacherID:   req.TeacherID,
+		CreatedBy:   uuid.NullUUID{UUID: adminID, Valid: true},
+	}
+	if req.SubjectID != nil {
+		if _, err := s.subjectRepo.GetByID(ctx, *req.SubjectID); err != nil {
+			return nil, err
+		}
+		group.SubjectID = uuid.NullUUID{UUID: *req.SubjectID, Valid: true}
+	}
+
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return s.groupRepo.GetByID(ctx, group.ID)
+}
+
+// UpdateGroup обновляет группу
+func (s *GroupService) UpdateGroup(ctx context.Context, id uuid.UUID, req *models.UpdateGroupRequest) (*models.GroupWithDetails, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.TeacherID != nil {
+		if err := s.validateTeacher(ctx, *req.TeacherID); err != nil {
+			return nil, err
+		}
+		updates["teacher_id"] = *req.TeacherID
+	}
+	if req.SubjectID != nil {
+		if _, err := s.subjectRepo.GetByID(ctx, *req.SubjectID); err != nil {
+			return nil, err
+		}
+		updates["subject_id"] = *req.SubjectID
+	}
+
+	if err := s.groupRepo.Update(ctx, id, updates); err != nil {
+		return nil, err
+	}
+
+	return s.groupRepo.GetByID(ctx, id)
+}
+
+// DeleteGroup удаляет группу без активных участников
+func (s *GroupService) DeleteGroup(ctx context.Context, id uuid.UUID) error {
+	group, err := s.groupRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if group.MemberCount > 0 {
+		return models.ErrGroupHasMembers
+	}
+	return s.groupRepo.Delete(ctx, id)
+}
+
+// AddMember добавляет студента в группу и записывает его на все будущие занятия привязанных серий
+// Если хотя бы одно занятие заполнено или кредитов не хватает, ничего не изменяется
+func (s *GroupService) AddMember(ctx context.Context, adminID, groupID uuid.UUID, req *models.AddGroupMemberRequest) (*models.GroupEnrollmentResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	student, err := s.userRepo.GetByID(ctx, req.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	if !student.IsStudent() {
+		return nil, models.ErrInvalidStudentID
+	}
+
+	result := &models.GroupEnrollmentResult{GroupID: groupID, StudentsCount: 1}
+
+	err = s.inGroupTx(ctx, groupID, func(tx pgx.Tx, group *models.Group) error {
+		if err := s.groupRepo.AddMemberTx(ctx, tx, groupID, req.StudentID, adminID); err != nil {
+			return err
+		}
+
+		seriesIDs, err := s.groupRepo.GetSeriesIDsTx(ctx, tx, groupID)
+		if err != nil {
+			return err
+		}
+		lessons, err := s.lessonRepo.GetFutureSeriesLessonsForUpdate(ctx, tx, seriesIDs, time.Now())
+		if err != nil {
+			return err
+		}
+
+		enrolled, deducted, err := s.enrollStudentTx(ctx, tx, adminID, group, req.StudentID, lessons)
+		if err != nil {
+			return err
+		}
+		result.LessonsAffected = enrolled
+		result.CreditsDelta = -deducted
+
+		return s.groupRepo.LogEventTx(ctx, tx, &models.GroupMembershipEvent{
+			GroupID:         groupID,
+			StudentID:       req.StudentID,
+			Action:          models.GroupMemberAdded,
+			LessonsAffected: enrolled,
+			CreditsDelta:    -deducted,
+			PerformedBy:     uuid.NullUUID{UUID: adminID, Valid: true},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.BookingsCreated.Add(float64(result.LessonsAffected))
+	metrics.CreditsDeducted.Add(float64(-result.CreditsDelta))
+
+	log.Info().
+		Str("group_id", groupID.String()).
+		Str("student_id", req.StudentID.String()).
+		Int("lessons_enrolled", result.LessonsAffected).
+		Msg("Student added to group")
+
+	return result, nil
+}
+
+// RemoveMember исключает студента из группы и выписывает его из будущих занятий привязанных серий с возвратом кредитов
+func (s *GroupService) RemoveMember(ctx context.Context, adminID, groupID, studentID uuid.UUID) (*models.GroupEnrollmentResult, error) {
+	result := &models.GroupEnrollmentResult{GroupID: groupID, StudentsCount: 1}
+
+	err := s.inGroupTx(ctx, groupID, func(tx pgx.Tx, group *models.Group) error {
+		if err := s.groupRepo.RemoveMemberTx(ctx, tx, groupID, studentID, adminID); err != nil {
+			return err
+		}
+
+		seriesIDs, err := s.groupRepo.GetSeriesIDsTx(ctx, tx, groupID)
+		if err != nil {
+			return err
+		}
+		lessons, err := s.lessonRepo.GetFutureSeriesLessonsForUpdate(ctx, tx, seriesIDs, time.Now())
+		if err != nil {
+			return err
+		}
+
+		unenrolled, refunded, err := s.unenrollStudentTx(ctx, tx, adminID, group, studentID, lessons)
+		if err != nil {
+			return err
+		}
+		result.LessonsAffected = unenrolled
+		result.CreditsDelta = refunded
+
+		return s.groupRepo.LogEventTx(ctx, tx, &models.GroupMembershipEvent{
+			GroupID:         groupID,
+			StudentID:       studentID,
+			Action:          models.GroupMemberRemoved,
+			LessonsAffected: unenrolled,
+			CreditsDelta:    refunded,
+			PerformedBy:     uuid.NullUUID{UUID: adminID, Valid: true},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.BookingsCancelled.Add(float64(result.LessonsAffected))
+	metrics.CreditsRefunded.Add(float64(result.CreditsDelta))
+
+	log.Info().
+		Str("group_id", groupID.String()).
+		Str("student_id", studentID.String()).
+		Int("lessons_unenrolled", result.LessonsAffected).
+		Msg("Student removed from group")
+
+	return result, nil
+}
+
+// LinkSeries привязывает серию повторяющихся занятий к группе и записывает на неё всех текущих участников
+func (s *GroupService) LinkSeries(ctx context.Context, adminID, groupID uuid.UUID, req *models.LinkGroupSeriesRequest) (*models.GroupEnrollmentResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := &models.GroupEnrollmentResult{GroupID: groupID}
+
+	err := s.inGroupTx(ctx, groupID, func(tx pgx.Tx, group *models.Group) error {
+		if err := s.groupRepo.LinkSeriesTx(ctx, tx, groupID, req.RecurringGroupID, adminID); err != nil {
+			return err
+		}
+
+		lessons, err := s.lessonRepo.GetFutureSeriesLessonsForUpdate(ctx, tx, []uuid.UUID{req.RecurringGroupID}, time.Now())
+		if err != nil {
+			return err
+		}
+		if len(lessons) == 0 {
+			return models.ErrSeriesHasNoFutureLessons
+		}
+
+		memberIDs, err := s.groupRepo.GetActiveMemberIDsTx(ctx, tx, groupID)
+		if err != nil {
+			return err
+		}
+		result.StudentsCount = len(memberIDs)
+
+		for _, studentID := range memberIDs {
+			enrolled, deducted, err := s.enrollStudentTx(ctx, tx, adminID, group, studentID, lessons)
+			if err != nil {
+				return fmt.Errorf("student %s: %w", studentID, err)
+			}
+			result.LessonsAffected += enrolled
+			result.CreditsDelta -= deducted
+
+			if err := s.groupRepo.LogEventTx(ctx, tx, &models.GroupMembershipEvent{
+				GroupID:         groupID,
+				StudentID:       studentID,
+				Action:          models.GroupMemberEnrolled,
+				LessonsAffected: enrolled,
+				CreditsDelta:    -deducted,
+				PerformedBy:     uuid.NullUUID{UUID: adminID, Valid: true},
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.BookingsCreated.Add(float64(result.LessonsAffected))
+	metrics.CreditsDeducted.Add(float64(-result.CreditsDelta))
+
+	return result, nil
+}
+
+// UnlinkSeries отвязывает серию от группы и выписывает текущих участников из её будущих занятий с возвратом кредитов
+func (s *GroupService) UnlinkSeries(ctx context.Context, adminID, groupID, recurringGroupID uuid.UUID) (*models.GroupEnrollmentResult, error) {
+	result := &models.GroupEnrollmentResult{GroupID: groupID}
+
+	err := s.inGroupTx(ctx, groupID, func(tx pgx.Tx, group *models.Group) error {
+		if err := s.groupRepo.UnlinkSeriesTx(ctx, tx, groupID, recurringGroupID); err != nil {
+			return err
+		}
+
+		lessons, err := s.lessonRepo.GetFutureSeriesLessonsForUpdate(ctx, tx, []uuid.UUID{recurringGroupID}, time.Now())
+		if err != nil {
+			return err
+		}
+
+		memberIDs, err := s.groupRepo.GetActiveMemberIDsTx(ctx, tx, groupID)
+		if err != nil {
+			return err
+		}
+		result.StudentsCount = len(memberIDs)
+
+		for _, studentID := range memberIDs {
+			unenrolled, refunded, err := s.unenrollStudentTx(ctx, tx, adminID, group, studentID, lessons)
+			if err != nil {
+				return fmt.Errorf("student %s: %w", studentID, err)
+			}
+			result.LessonsAffected += unenrolled
+			result.CreditsDelta += refunded
+
+			if err := s.groupRepo.LogEventTx(ctx, tx, &models.GroupMembershipEvent{
+				GroupID:         groupID,
+				StudentID:       studentID,
+				Action:          models.GroupMemberUnenrolled,
+				LessonsAffected: unenrolled,
+				CreditsDelta:    refunded,
+				PerformedBy:     uuid.NullUUID{UUID: adminID, Valid: true},
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.BookingsCancelled.Add(float64(result.LessonsAffected))
+	metrics.CreditsRefunded.Add(float64(result.CreditsDelta))
+
+	return result, nil
+}
+
+// inGroupTx выполняет fn в транзакции с блокировкой строки группы
+func (s *GroupService) inGroupTx(ctx context.Context, groupID uuid.UUID, fn func(tx pgx.Tx, group *models.Group) error) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Failed to rollback group transaction")
+		}
+	}()
+
+	group, err := s.groupRepo.GetByIDForUpdate(ctx, tx, groupID)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx, group); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// enrollStudentTx записывает студента на занятия, на которые он ещё не записан, и списывает кредиты
+// Возвращает количество новых записей и списанные кредиты
+func (s *GroupService) enrollStudentTx(ctx context.Context, tx pgx.Tx, adminID uuid.UUID, group *models.Group, studentID uuid.UUID, lessons []*models.Lesson) (int, int, error) {
+	toEnroll := make([]*models.Lesson, 0, len(lessons))
+	creditsNeeded := 0
+	for _, lesson := range lessons {
+		booked, err := s.lessonRepo.IsStudentBookedForLessonTx(ctx, tx, lesson.ID, studentID)
+		if err != nil {
+			return 0, 0, err
+		}
+		if booked {
+			continue
+		}
+		if lesson.IsFull() {
+			return 0, 0, fmt.Errorf("lesson at %s: %w", lesson.StartTime.Format("2006-01-02 15:04"), repository.ErrLessonFull)
+		}
+		toEnroll = append(toEnroll, lesson)
+		if lesson.CreditsCost > 0 {
+			creditsNeeded += lesson.CreditsCost
+		}
+	}
+	if len(toEnroll) == 0 {
+		return 0, 0, nil
+	}
+
+	credit, err := s.creditRepo.GetBalanceForUpdate(ctx, tx, studentID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get student credit balance: %w", err)
+	}
+	if !credit.HasSufficientBalance(creditsNeeded) {
+		return 0, 0, repository.ErrInsufficientCredits
+	}
+
+	now := time.Now()
+	balance := credit.Balance
+	for _, lesson := range toEnroll {
+		booking := &models.Booking{
+			ID:        uuid.New(),
+			StudentID: studentID,
+			LessonID:  lesson.ID,
+			Status:    models.BookingStatusActive,
+			BookedAt:  now,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.lessonRepo.CreateBookingTx(ctx, tx, booking); err != nil {
+			return 0, 0, fmt.Errorf("failed to enroll student to lesson %s: %w", lesson.ID, err)
+		}
+		if err := s.lessonRepo.IncrementStudents(ctx, tx, lesson.ID); err != nil {
+			return 0, 0, fmt.Errorf("failed to increment student count for lesson %s: %w", lesson.ID, err)
+		}
+
+		if lesson.CreditsCost <= 0 {
+			continue
+		}
+		creditTx := &models.CreditTransaction{
+			UserID:        studentID,
+			Amount:        -lesson.CreditsCost,
+			OperationType: models.OperationTypeDeduct,
+			Reason:        fmt.Sprintf("Запись в группе «%s» на занятие %s", group.Name, lesson.StartTime.Format("2006-01-02 15:04")),
+			BookingID:     uuid.NullUUID{UUID: booking.ID, Valid: true},
+			PerformedBy:   uuid.NullUUID{UUID: adminID, Valid: true},
+			BalanceBefore: balance,
+			BalanceAfter:  balance - lesson.CreditsCost,
+		}
+		if err := s.creditRepo.CreateTransaction(ctx, tx, creditTx); err != nil {
+			return 0, 0, fmt.Errorf("failed to record credit transaction: %w", err)
+		}
+		balance -= lesson.CreditsCost
+	}
+
+	if balance != credit.Balance {
+		if err := s.creditRepo.UpdateBalance(ctx, tx, studentID, balance); err != nil {
+			return 0, 0, fmt.Errorf("failed to deduct credits: %w", err)
+		}
+	}
+
+	return len(toEnroll), creditsNeeded, nil
+}
+
+// unenrollStudentTx отменяет активные записи студента на занятия и возвращает кредиты
+// Возвращает количество отменённых записей и возвращённые кредиты
+func (s *GroupService) unenrollStudentTx(ctx context.Context, tx pgx.Tx, adminID uuid.UUID, group *models.Group, studentID uuid.UUID, lessons []*models.Lesson) (int, int, error) {
+	var credit *models.Credit
+	balance := 0
+	unenrolled := 0
+	refunded := 0
+
+	for _, lesson := range lessons {
+		bookings, err := s.lessonRepo.GetBookingsByLessonTx(ctx, tx, lesson.ID)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		var booking *models.Booking
+		for _, b := range bookings {
+			if b.StudentID == studentID {
+				booking = b
+				break
+			}
+		}
+		if booking == nil {
+			continue
+		}
+
+		if err := s.lessonRepo.CancelBookingTx(ctx, tx, lesson.ID, studentID); err != nil {
+			return 0, 0, fmt.Errorf("failed to cancel booking %s: %w", booking.ID, err)
+		}
+		if err := s.lessonRepo.DecrementStudents(ctx, tx, lesson.ID); err != nil {
+			return 0, 0, fmt.Errorf("failed to decrement student count for lesson %s: %w", lesson.ID, err)
+		}
+		unenrolled++
+
+		if lesson.CreditsCost <= 0 {
+			continue
+		}
+		if credit == nil {
+			credit, err = s.creditRepo.GetBalanceForUpdate(ctx, tx, studentID)
+			if err != nil {
+				return 0, 0, fmt.Errorf("failed to get student credit balance: %w", err)
+			}
+			balance = credit.Balance
+		}
+
+		creditTx := &models.CreditTransaction{
+			UserID:        studentID,
+			Amount:        lesson.CreditsCost,
+			OperationType: models.OperationTypeRefund,
+			Reason:        fmt.Sprintf("Выписка из группы «%s», занятие %s", group.Name, lesson.StartTime.Format("2006-01-02 15:04")),
+			BookingID:     uuid.NullUUID{UUID: booking.ID, Valid: true},
+			PerformedBy:   uuid.NullUUID{UUID: adminID, Valid: true},
+			BalanceBefore: balance,
+			BalanceAfter:  balance + lesson.CreditsCost,
+		}
+		if err := s.creditRepo.CreateTransaction(ctx, tx, creditTx); err != nil {
+			return 0, 0, fmt.Errorf("failed to record refund transaction: %w", err)
+		}
+		balance += lesson.CreditsCost
+		refunded += lesson.CreditsCost
+	}
+
+	if credit != nil && balance != credit.Balance {
+		if err := s.creditRepo.UpdateBalance(ctx, tx, studentID, balance); err != nil {
+			return 0, 0, fmt.Errorf("failed to refund credits: %w", err)
+		}
+	}
+
+	return unenrolled, refunded, nil
+}
+
+// validateTeacher проверяет, что пользователь может вести занятия группы
+func (s *GroupService) validateTeacher(ctx context.Context, teacherID uuid.UUID) error {
+	teacher, err := s.userRepo.GetByID(ctx, teacherID)
+	if err != nil {
+		return err
+	}
+	if !teacher.CanBeAssignedAsTeacher() {
+		return models.ErrInvalidTeacherID
+	}
+	return nil
+}
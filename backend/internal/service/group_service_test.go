@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGroupRepo хранит одну группу, ее участников и журнал событий в памяти
+type fakeGroupRepo struct {
+	groupServiceGroupRepository
+	group     *models.Group
+	seriesIDs []uuid.UUID
+	members   map[uuid.UUID]bool
+	events    []*models.GroupMembershipEvent
+}
+
+func (r *fakeGroupRepo) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.Group, error) {
+	return r.group, nil
+}
+
+func (r *fakeGroupRepo) AddMemberTx(ctx context.Context, tx pgx.Tx, groupID, studentID, addedBy uuid.UUID) error {
+	r.members[studentID] = true
+	return nil
+}
+
+func (r *fakeGroupRepo) RemoveMemberTx(ctx context.Context, tx pgx.Tx, groupID, studentID, removedBy uuid.UUID) error {
+	delete(r.members, studentID)
+	return nil
+}
+
+func (r *fakeGroupRepo) GetSeriesIDsTx(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error) {
+	return r.seriesIDs, nil
+}
+
+func (r *fakeGroupRepo) LogEventTx(ctx context.Context, tx pgx.Tx, event *models.GroupMembershipEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+// fakeGroupLessonRepo хранит занятия серии и записи на них в памяти
+type fakeGroupLessonRepo struct {
+	groupServiceLessonRepository
+	lessons  []*models.Lesson
+	bookings map[uuid.UUID][]*models.Booking
+}
+
+func (r *fakeGroupLessonRepo) GetFutureSeriesLessonsForUpdate(ctx context.Context, tx pgx.Tx, recurringGroupIDs []uuid.UUID, after time.Time) ([]*models.Lesson, error) {
+	return r.lessons, nil
+}
+
+func (r *fakeGroupLessonRepo) GetBookingsByLessonTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) ([]*models.Booking, error) {
+	return r.bookings[lessonID], nil
+}
+
+func (r *fakeGroupLessonRepo) IsStudentBookedForLessonTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, studentID uuid.UUID) (bool, error) {
+	for _, booking := range r.bookings[lessonID] {
+		if booking.StudentID == studentID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeGroupLessonRepo) CreateBookingTx(ctx context.Context, tx pgx.Tx, booking *models.Booking) error {
+	r.bookings[booking.LessonID] = append(r.bookings[booking.LessonID], booking)
+	return nil
+}
+
+func (r *fakeGroupLessonRepo) CancelBookingTx(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID, studentID uuid.UUID) error {
+	kept := r.bookings[lessonID][:0]
+	for _, booking := range r.bookings[lessonID] {
+		if booking.StudentID != studentID {
+			kept = append(kept, booking)
+		}
+	}
+	r.bookings[lessonID] = kept
+	return nil
+}
+
+func (r *fakeGroupLessonRepo) IncrementStudents(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) error {
+	r.lesson(lessonID).CurrentStudents++
+	return nil
+}
+
+func (r *fakeGroupLessonRepo) DecrementStudents(ctx context.Context, tx pgx.Tx, lessonID uuid.UUID) error {
+	r.lesson(lessonID).CurrentStudents--
+	return nil
+}
+
+func (r *fakeGroupLessonRepo) lesson(id uuid.UUID) *models.Lesson {
+	for _, lesson := range r.lessons {
+		if lesson.ID == id {
+			return lesson
+		}
+	}
+	return nil
+}
+
+// fakeCreditRepo хранит балансы и транзакции кредитов в памяти
+type fakeCreditRepo struct {
+	balances     map[uuid.UUID]int
+	transactions []*models.CreditTransaction
+}
+
+func (r *fakeCreditRepo) GetBalanceForUpdate(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (*models.Credit, error) {
+	return &models.Credit{UserID: userID, Balance: r.balances[userID]}, nil
+}
+
+func (r *fakeCreditRepo) UpdateBalance(ctx context.Context, tx pgx.Tx, userID uuid.UUID, newBalance int) error {
+	r.balances[userID] = newBalance
+	return nil
+}
+
+func (r *fakeCreditRepo) CreateTransaction(ctx context.Context, tx pgx.Tx, transaction *models.CreditTransaction) error {
+	r.transactions = append(r.transactions, transaction)
+	return nil
+}
+
+// fakeUserLookupRepo отдает пользователей по ID; остальные методы UserRepository не реализованы
+type fakeUserLookupRepo struct {
+	repository.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *fakeUserLookupRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return user, nil
+}
+
+type groupFixture struct {
+	pool    *fakeTxBeginner
+	groups  *fakeGroupRepo
+	lessons *fakeGroupLessonRepo
+	credits *fakeCreditRepo
+	svc     *GroupService
+	student *models.User
+}
+
+// newGroupFixture создает группу с серией из трех будущих занятий по 2 кредита
+func newGroupFixture(balance int) *groupFixture {
+	student := &models.User{ID: uuid.New(), Role: models.RoleStudent}
+	start := time.Now().Add(24 * time.Hour)
+
+	lessons := make([]*models.Lesson, 0, 3)
+	for i := 0; i < 3; i++ {
+		lessonStart := start.AddDate(0, 0, 7*i)
+		lessons = append(lessons, &models.Lesson{
+			ID:          uuid.New(),
+			StartTime:   lessonStart,
+			EndTime:     lessonStart.Add(time.Hour),
+			MaxStudents: 4,
+			CreditsCost: 2,
+		})
+	}
+
+	f := &groupFixture{
+		pool: &fakeTxBeginner{},
+		groups: &fakeGroupRepo{
+			group:     &models.Group{ID: uuid.New(), Name: "Math 7A"},
+			seriesIDs: []uuid.UUID{uuid.New()},
+			members:   map[uuid.UUID]bool{},
+		},
+		lessons: &fakeGroupLessonRepo{lessons: lessons, bookings: map[uuid.UUID][]*models.Booking{}},
+		credits: &fakeCreditRepo{balances: map[uuid.UUID]int{student.ID: balance}},
+		student: student,
+	}
+	users := &fakeUserLookupRepo{users: map[uuid.UUID]*models.User{student.ID: student}}
+	f.svc = NewGroupService(f.pool, f.groups, f.lessons, users, nil, f.credits)
+	return f
+}
+
+func (f *groupFixture) bookingsOf(studentID uuid.UUID) int {
+	count := 0
+	for _, bookings := range f.lessons.bookings {
+		for _, booking := range bookings {
+			if booking.StudentID == studentID {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestGroupService_AddMember_EnrollsIntoSeriesLessons(t *testing.T) {
+	f := newGroupFixture(10)
+	// На первое занятие студент уже записан - повторно не записываем и не списываем
+	already := f.lessons.lessons[0]
+	f.lessons.bookings[already.ID] = []*models.Booking{{ID: uuid.New(), LessonID: already.ID, StudentID: f.student.ID}}
+
+	result, err := f.svc.AddMember(context.Background(), uuid.New(), f.groups.group.ID, &models.AddGroupMemberRequest{StudentID: f.student.ID})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.LessonsAffected)
+	assert.Equal(t, -4, result.CreditsDelta)
+	assert.Equal(t, 6, f.credits.balances[f.student.ID])
+	assert.Equal(t, 3, f.bookingsOf(f.student.ID))
+	assert.Equal(t, 0, already.CurrentStudents)
+	assert.Equal(t, 1, f.lessons.lessons[1].CurrentStudents)
+	assert.Len(t, f.credits.transactions, 2)
+	assert.True(t, f.groups.members[f.student.ID])
+
+	require.Len(t, f.groups.events, 1)
+	assert.Equal(t, models.GroupMemberAdded, f.groups.events[0].Action)
+	assert.Equal(t, 2, f.groups.events[0].LessonsAffected)
+	assert.True(t, f.pool.last().committed)
+}
+
+func TestGroupService_AddMember_AllOrNothing(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance int
+		prepare func(f *groupFixture)
+		wantErr error
+	}{
+		{
+			name:    "lesson is full",
+			balance: 10,
+			prepare: func(f *groupFixture) {
+				f.lessons.lessons[2].CurrentStudents = 4
+			},
+			wantErr: repository.ErrLessonFull,
+		},
+		{
+			name:    "insufficient credits",
+			balance: 5,
+			prepare: func(f *groupFixture) {},
+			wantErr: repository.ErrInsufficientCredits,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGroupFixture(tt.balance)
+			tt.prepare(f)
+
+			result, err := f.svc.AddMember(context.Background(), uuid.New(), f.groups.group.ID, &models.AddGroupMemberRequest{StudentID: f.student.ID})
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, result)
+
+			assert.Zero(t, f.bookingsOf(f.student.ID), "no lesson should be booked")
+			assert.Equal(t, tt.balance, f.credits.balances[f.student.ID], "credits should not be deducted")
+			assert.Empty(t, f.groups.events)
+			assert.False(t, f.pool.last().committed)
+			assert.True(t, f.pool.last().rolledBack)
+		})
+	}
+}
+
+func TestGroupService_AddMember_RejectsNonStudent(t *testing.T) {
+	f := newGroupFixture(10)
+	f.student.Role = models.RoleTeacher
+
+	_, err := f.svc.AddMember(context.Background(), uuid.New(), f.groups.group.ID, &models.AddGroupMemberRequest{StudentID: f.student.ID})
+	assert.ErrorIs(t, err, models.ErrInvalidStudentID)
+	assert.Empty(t, f.pool.txs, "transaction should not be started")
+}
+
+func TestGroupService_RemoveMember_RefundsCredits(t *testing.T) {
+	f := newGroupFixture(10)
+	adminID := uuid.New()
+	_, err := f.svc.AddMember(context.Background(), adminID, f.groups.group.ID, &models.AddGroupMemberRequest{StudentID: f.student.ID})
+	require.NoError(t, err)
+	require.Equal(t, 4, f.credits.balances[f.student.ID])
+
+	result, err := f.svc.RemoveMember(context.Background(), adminID, f.groups.group.ID, f.student.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.LessonsAffected)
+	assert.Equal(t, 6, result.CreditsDelta)
+	assert.Equal(t, 10, f.credits.balances[f.student.ID])
+	assert.Zero(t, f.bookingsOf(f.student.ID))
+	for _, lesson := range f.lessons.lessons {
+		assert.Zero(t, lesson.CurrentStudents)
+	}
+	assert.False(t, f.groups.members[f.student.ID])
+	assert.Equal(t, models.GroupMemberRemoved, f.groups.events[len(f.groups.events)-1].Action)
+	assert.True(t, f.pool.last().committed)
+}
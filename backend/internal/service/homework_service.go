@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"io"
 	"os"
@@ -19,6 +20,7 @@ type HomeworkService struct {
 	lessonRepo   homeworkServiceLessonRepository
 	bookingRepo  homeworkServiceBookingRepository
 	userRepo     homeworkServiceUserRepository
+	fileStorage  *FileStorageService
 }
 
 // homeworkServiceRepository - интерфейс для dependency injection в тестах
@@ -60,6 +62,11 @@ func NewHomeworkService(
 	}
 }
 
+// SetFileStorage включает хранение файлов в BlobStore; без него файлы пишутся в uploads/homework
+func (s *HomeworkService) SetFileStorage(fileStorage *FileStorageService) {
+	s.fileStorage = fileStorage
+}
+
 // CreateHomework сохраняет файл и создает запись домашнего задания
 // Проверяет:
 // - Существование урока
@@ -88,6 +95,10 @@ func (s *HomeworkService) CreateHomework(ctx context.Context, userID uuid.UUID,
 		return nil, repository.ErrUnauthorized
 	}
 
+	if s.fileStorage != nil {
+		return s.createStoredHomework(ctx, userID, file, req)
+	}
+
 	// Создаем директорию для файлов если не существует
 	uploadsDir := "uploads/homework"
 	if err := os.MkdirAll(uploadsDir, 0755); err != nil {
@@ -134,6 +145,36 @@ func (s *HomeworkService) CreateHomework(ctx context.Context, userID uuid.UUID,
 	return created, nil
 }
 
+// createStoredHomework сохраняет файл домашнего задания в BlobStore и создает запись
+func (s *HomeworkService) createStoredHomework(ctx context.Context, userID uuid.UUID, file io.Reader, req *models.CreateHomeworkRequest) (*models.LessonHomework, error) {
+	safeFileName := filepath.Base(req.FileName)
+	blob, err := s.fileStorage.Save(ctx, models.StoredFileSourceHomework, userID, safeFileName, file, req.MimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	homework := &models.LessonHomework{
+		LessonID:    req.LessonID,
+		FileName:    safeFileName,
+		FilePath:    blob.Key,
+		FileSize:    blob.Size,
+		MimeType:    req.MimeType,
+		CreatedBy:   userID,
+		StorageKey:  sql.NullString{String: blob.Key, Valid: true},
+		ContentHash: sql.NullString{String: blob.Hash, Valid: true},
+	}
+
+	created, err := s.homeworkRepo.CreateHomework(ctx, homework)
+	if err != nil {
+		// Объект мог быть создан этой загрузкой - удаляем, если на него нет других ссылок
+		s.fileStorage.ReleaseQuietly(ctx, blob.Key)
+		return nil, fmt.Errorf("failed to create homework record: %w", err)
+	}
+
+	s.fileStorage.GenerateThumbnailAsync(ctx, models.StoredFileSourceHomework, created.ID, blob, created.MimeType)
+	return created, nil
+}
+
 // GetHomeworkByLesson получает список файлов ДЗ с проверкой доступа
 // Правила доступа:
 // - Admin: может видеть все ДЗ всех уроков
@@ -225,6 +266,17 @@ func (s *HomeworkService) DeleteHomework(ctx context.Context, userID uuid.UUID,
 		return repository.ErrUnauthorized
 	}
 
+	// Файл из BlobStore удаляется после записи: тот же объект может использоваться другими записями
+	if homework.StorageKey.Valid {
+		if err := s.homeworkRepo.DeleteHomework(ctx, homeworkID); err != nil {
+			return fmt.Errorf("failed to delete homework record: %w", err)
+		}
+		if s.fileStorage != nil {
+			s.fileStorage.ReleaseQuietly(ctx, homework.StorageKey.String)
+		}
+		return nil
+	}
+
 	// Удаляем файл из файловой системы
 	if err := os.Remove(homework.FilePath); err != nil {
 		// Логируем ошибку но продолжаем (файл может быть уже удален вручную)
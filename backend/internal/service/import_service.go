@@ -0,0 +1,663 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/internal/validator"
+	"tutoring-platform/pkg/xlsx"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// ImportRequest параметры массового импорта
+type ImportRequest struct {
+	Kind     models.ImportKind
+	FileName string
+	Data     []byte
+	// DryRun только проверяет файл и возвращает построчный отчет без изменений в БД
+	DryRun bool
+	// Location часовой пояс для времени занятий без явного смещения (по умолчанию UTC)
+	Location *time.Location
+	// Actor пользователь, запустивший импорт: его права ограничивают роли создаваемых пользователей.
+	// CLI передает администратора с uuid.Nil (оператор с доступом к БД)
+	Actor *models.User
+}
+
+// importAction строка, прошедшая проверку: применяется в общей транзакции импорта
+type importAction struct {
+	result     *models.ImportRowResult
+	externalID string
+	apply      func(ctx context.Context, tx pgx.Tx) (uuid.UUID, error)
+}
+
+// ImportService выполняет массовый импорт пользователей, занятий и записей на занятия из CSV/XLSX.
+// Каждая строка проверяется теми же валидаторами, что и при создании через API. Файл применяется
+// целиком в одной транзакции и только если в нем нет ошибок; повторный импорт пропускает
+// уже существующие сущности (по email, external_id или совпадающему занятию/записи)
+type ImportService struct {
+	pool             txBeginner
+	repo             importServiceRepository
+	userService      importUserService
+	bookingService   *BookingService
+	lessonRepo       *repository.LessonRepository
+	bookingRepo      *repository.BookingRepository
+	creditRepo       *repository.CreditRepository
+	lessonValidator  *validator.LessonValidator
+	bookingValidator *validator.BookingValidator
+}
+
+// importServiceRepository - часть ImportRepository, используемая ImportService
+type importServiceRepository interface {
+	GetUsersByEmails(ctx context.Context, emails []string) (map[string]*models.User, error)
+	GetExternalIDs(ctx context.Context, kind models.ImportKind, externalIDs []string) (map[string]uuid.UUID, error)
+	CreateRunTx(ctx context.Context, tx pgx.Tx, run *models.ImportRun) error
+	SaveExternalIDTx(ctx context.Context, tx pgx.Tx, kind models.ImportKind, externalID string, entityID, importID uuid.UUID) error
+	ClearCancelledBookingTx(ctx context.Context, tx pgx.Tx, studentID, lessonID uuid.UUID) error
+	ListRuns(ctx context.Context, limit int) ([]*models.ImportRun, error)
+}
+
+// importUserService проверяет и создает импортируемых пользователей; реализуется UserService
+type importUserService interface {
+	ValidateCreateUser(ctx context.Context, actor *models.User, req *models.CreateUserRequest) error
+	CreateUserTx(ctx context.Context, tx pgx.Tx, actor *models.User, req *models.CreateUserRequest) (*models.User, error)
+}
+
+// NewImportService создает новый ImportService
+func NewImportService(
+	pool txBeginner,
+	repo importServiceRepository,
+	userService importUserService,
+	bookingService *BookingService,
+	lessonRepo *repository.LessonRepository,
+	bookingRepo *repository.BookingRepository,
+	creditRepo *repository.CreditRepository,
+	bookingValidator *validator.BookingValidator,
+) *ImportService {
+	return &ImportService{
+		pool:             pool,
+		repo:             repo,
+		userService:      userService,
+		bookingService:   bookingService,
+		lessonRepo:       lessonRepo,
+		bookingRepo:      bookingRepo,
+		creditRepo:       creditRepo,
+		lessonValidator:  validator.NewLessonValidator(),
+		bookingValidator: bookingValidator,
+	}
+}
+
+// Import проверяет файл и, если это не пробный запуск, применяет его.
+// При ошибках в строках возвращает отчет вместе с models.ErrImportHasErrors
+func (s *ImportService) Import(ctx context.Context, req *ImportRequest) (*models.ImportReport, error) {
+	if !req.Kind.IsValid() {
+		return nil, models.ErrInvalidImportKind
+	}
+	if req.Actor == nil {
+		return nil, errors.New("import actor is required")
+	}
+	if len(req.Data) > models.MaxImportFileSize {
+		return nil, models.ErrImportFileTooLarge
+	}
+	loc := req.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	rows, err := readImportFile(req.FileName, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	records, err := models.ParseImportTable(req.Kind, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{Kind: req.Kind, FileName: req.FileName, DryRun: req.DryRun}
+	var actions []*importAction
+	switch req.Kind {
+	case models.ImportKindUsers:
+		actions, err = s.planUsers(ctx, records, req.Actor, report)
+	case models.ImportKindLessons:
+		actions, err = s.planLessons(ctx, records, loc, report)
+	case models.ImportKindBookings:
+		actions, err = s.planBookings(ctx, records, req.Actor.ID, report)
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.Count()
+
+	if report.HasErrors() {
+		if req.DryRun {
+			return report, nil
+		}
+		return report, models.ErrImportHasErrors
+	}
+	if req.DryRun {
+		return report, nil
+	}
+
+	if err := s.apply(ctx, req, report, actions); err != nil {
+		report.Count()
+		return report, err
+	}
+	report.Count()
+
+	log.Info().
+		Str("kind", string(req.Kind)).
+		Str("file_name", req.FileName).
+		Int("created", report.Created).
+		Int("skipped", report.Skipped).
+		Msg("Bulk import applied")
+
+	return report, nil
+}
+
+// ListRuns получает журнал примененных импортов
+func (s *ImportService) ListRuns(ctx context.Context, limit int) ([]*models.ImportRun, error) {
+	return s.repo.ListRuns(ctx, limit)
+}
+
+// apply применяет проверенные строки в одной транзакции и записывает импорт в журнал.
+// Ошибка любой строки (например, из-за параллельного изменения данных) откатывает весь файл
+func (s *ImportService) apply(ctx context.Context, req *ImportRequest, report *models.ImportReport, actions []*importAction) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Failed to rollback import transaction")
+		}
+	}()
+
+	run := &models.ImportRun{
+		Kind:        req.Kind,
+		FileName:    req.FileName,
+		RowsTotal:   report.Total,
+		RowsCreated: len(actions),
+		RowsSkipped: report.Skipped,
+	}
+	if req.Actor.ID != uuid.Nil {
+		run.CreatedBy = uuid.NullUUID{UUID: req.Actor.ID, Valid: true}
+	}
+	if err := s.repo.CreateRunTx(ctx, tx, run); err != nil {
+		return err
+	}
+
+	for _, action := range actions {
+		id, err := action.apply(ctx, tx)
+		if err == nil && action.externalID != "" {
+			err = s.repo.SaveExternalIDTx(ctx, tx, req.Kind, action.externalID, id, run.ID)
+		}
+		if err != nil {
+			// Транзакция откатывается: строки, отмеченные созданными, возвращаются в статус valid
+			for _, a := range actions {
+				a.result.Status = models.ImportRowValid
+				a.result.EntityID = nil
+				a.result.TemporaryPassword = ""
+			}
+			action.result.AddError(err)
+			return models.ErrImportHasErrors
+		}
+		action.result.Status = models.ImportRowCreated
+		action.result.EntityID = &id
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	report.ImportID = &run.ID
+	return nil
+}
+
+// planUsers проверяет строки импорта пользователей так же, как UserService.CreateUser от имени actor
+func (s *ImportService) planUsers(ctx context.Context, records []models.ImportRecord, actor *models.User, report *models.ImportReport) ([]*importAction, error) {
+	emails := make([]string, 0, len(records))
+	externalIDs := make([]string, 0, len(records))
+	for _, rec := range records {
+		emails = append(emails, strings.ToLower(rec.Get("email")))
+		if id := rec.Get("external_id"); id != "" {
+			externalIDs = append(externalIDs, id)
+		}
+	}
+	existing, err := s.repo.GetUsersByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := s.repo.GetExternalIDs(ctx, models.ImportKindUsers, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []*importAction
+	seen := make(map[string]int)
+	for _, rec := range records {
+		password, err := generateTemporaryPassword()
+		if err != nil {
+			return nil, err
+		}
+		row, errs := models.ParseUserImportRow(rec, password)
+		result := &models.ImportRowResult{Row: rec.Line, Key: row.Request.Email, Status: models.ImportRowValid}
+		report.Rows = append(report.Rows, result)
+		for _, e := range errs {
+			result.AddError(e)
+		}
+		if len(errs) == 0 {
+			if err := s.userService.ValidateCreateUser(ctx, actor, &row.Request); err != nil {
+				result.AddError(err)
+			}
+		}
+		if markDuplicate(result, seen, "email:"+row.Request.Email) || markDuplicate(result, seen, "external_id:"+row.ExternalID) {
+			continue
+		}
+		if result.Status == models.ImportRowError {
+			continue
+		}
+
+		if id, ok := imported[row.ExternalID]; ok {
+			markSkipped(result, id)
+			continue
+		}
+		if user, ok := existing[row.Request.Email]; ok {
+			markSkipped(result, user.ID)
+			continue
+		}
+
+		actions = append(actions, &importAction{
+			result:     result,
+			externalID: row.ExternalID,
+			apply: func(ctx context.Context, tx pgx.Tx) (uuid.UUID, error) {
+				user, err := s.userService.CreateUserTx(ctx, tx, actor, &row.Request)
+				if err != nil {
+					return uuid.Nil, err
+				}
+				if row.GeneratedPassword {
+					result.TemporaryPassword = row.Request.Password
+				}
+				return user.ID, nil
+			},
+		})
+	}
+	return actions, nil
+}
+
+// planLessons проверяет строки импорта занятий, включая пересечения занятий преподавателя
+// с расписанием в БД и с другими строками файла
+func (s *ImportService) planLessons(ctx context.Context, records []models.ImportRecord, loc *time.Location, report *models.ImportReport) ([]*importAction, error) {
+	emails := make([]string, 0, len(records))
+	externalIDs := make([]string, 0, len(records))
+	for _, rec := range records {
+		emails = append(emails, strings.ToLower(rec.Get("teacher_email")))
+		if id := rec.Get("external_id"); id != "" {
+			externalIDs = append(externalIDs, id)
+		}
+	}
+	teachers, err := s.repo.GetUsersByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := s.repo.GetExternalIDs(ctx, models.ImportKindLessons, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	type plannedLesson struct {
+		row        int
+		start, end time.Time
+	}
+	planned := make(map[uuid.UUID][]plannedLesson)
+
+	var actions []*importAction
+	seen := make(map[string]int)
+	for _, rec := range records {
+		row, errs := models.ParseLessonImportRow(rec, loc)
+		result := &models.ImportRowResult{Row: rec.Line, Status: models.ImportRowValid}
+		result.Key = row.TeacherEmail
+		if !row.Request.StartTime.IsZero() {
+			result.Key += " " + row.Request.StartTime.Format(time.RFC3339)
+		}
+		report.Rows = append(report.Rows, result)
+		for _, e := range errs {
+			result.AddError(e)
+		}
+		if markDuplicate(result, seen, "external_id:"+row.ExternalID) {
+			continue
+		}
+
+		if row.TeacherEmail != "" {
+			teacher, ok := teachers[row.TeacherEmail]
+			switch {
+			case !ok:
+				result.AddError(fmt.Errorf("teacher_email: %w", models.ErrImportUserNotFound))
+			case !teacher.CanBeAssignedAsTeacher():
+				result.AddError(models.ErrInvalidTeacherID)
+			default:
+				row.Request.TeacherID = teacher.ID
+			}
+		}
+		if result.Status == models.ImportRowError {
+			continue
+		}
+
+		row.Request.ApplyDefaults()
+		if err := row.Request.Validate(); err != nil {
+			result.AddError(err)
+		}
+		if err := s.lessonValidator.ValidateCreateLessonRequest(&row.Request); err != nil {
+			result.AddError(err)
+		}
+		if result.Status == models.ImportRowError {
+			continue
+		}
+
+		if id, ok := imported[row.ExternalID]; ok {
+			markSkipped(result, id)
+			continue
+		}
+
+		req := row.Request
+		overlapping, err := s.lessonRepo.GetOverlappingTeacherLessons(ctx, req.TeacherID, req.StartTime, req.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		if len(overlapping) == 1 && overlapping[0].StartTime.Equal(req.StartTime) && overlapping[0].EndTime.Equal(req.EndTime) {
+			// Такое же занятие уже есть (файл импортируется повторно)
+			markSkipped(result, overlapping[0].ID)
+			continue
+		}
+		if len(overlapping) > 0 {
+			result.AddError(repository.ErrLessonOverlapConflict)
+			continue
+		}
+		for _, p := range planned[req.TeacherID] {
+			if req.StartTime.Before(p.end) && p.start.Before(req.EndTime) {
+				result.AddError(fmt.Errorf("%w (строка %d)", models.ErrImportTeacherOverlap, p.row))
+				break
+			}
+		}
+		if result.Status == models.ImportRowError {
+			continue
+		}
+		planned[req.TeacherID] = append(planned[req.TeacherID], plannedLesson{row: rec.Line, start: req.StartTime, end: req.EndTime})
+
+		actions = append(actions, &importAction{
+			result:     result,
+			externalID: row.ExternalID,
+			apply: func(ctx context.Context, tx pgx.Tx) (uuid.UUID, error) {
+				return s.createLessonTx(ctx, tx, &req)
+			},
+		})
+	}
+	return actions, nil
+}
+
+// createLessonTx создает занятие по проверенному запросу
+func (s *ImportService) createLessonTx(ctx context.Context, tx pgx.Tx, req *models.CreateLessonRequest) (uuid.UUID, error) {
+	now := time.Now()
+	lesson := &models.Lesson{
+		ID:          uuid.New(),
+		TeacherID:   req.TeacherID,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		MaxStudents: req.MaxStudents,
+		CreditsCost: req.CreditsCost,
+		Color:       req.Color,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if req.Subject != nil {
+		lesson.Subject = sql.NullString{String: *req.Subject, Valid: true}
+	}
+	if req.HomeworkText != nil {
+		lesson.HomeworkText = sql.NullString{String: *req.HomeworkText, Valid: true}
+	}
+	if req.Link != nil {
+		lesson.Link = sql.NullString{String: *req.Link, Valid: true}
+	}
+
+	created, err := s.lessonRepo.CreateLessonTx(ctx, tx, lesson)
+	if err != nil {
+		if repository.IsExclusionViolationError(err) {
+			return uuid.Nil, repository.ErrLessonOverlapConflict
+		}
+		return uuid.Nil, err
+	}
+	return created.ID, nil
+}
+
+// planBookings проверяет строки импорта записей на занятия: каждую запись так же, как при ручной
+// записи администратором, и суммарно по файлу - вместимость занятий, баланс и расписание студентов
+func (s *ImportService) planBookings(ctx context.Context, records []models.ImportRecord, adminID uuid.UUID, report *models.ImportReport) ([]*importAction, error) {
+	emails := make([]string, 0, len(records))
+	externalIDs := make([]string, 0, len(records))
+	for _, rec := range records {
+		emails = append(emails, strings.ToLower(rec.Get("student_email")))
+		if id := rec.Get("lesson_external_id"); id != "" {
+			externalIDs = append(externalIDs, id)
+		}
+	}
+	students, err := s.repo.GetUsersByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	lessonIDs, err := s.repo.GetExternalIDs(ctx, models.ImportKindLessons, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	lessons := make(map[uuid.UUID]*models.Lesson)
+	seats := make(map[uuid.UUID]int)    // записи, добавляемые файлом на занятие
+	spent := make(map[uuid.UUID]int)    // кредиты, списываемые файлом со студента
+	balances := make(map[uuid.UUID]int) // текущий баланс студента
+	schedule := make(map[uuid.UUID][]*models.Lesson)
+
+	var actions []*importAction
+	seen := make(map[string]int)
+	for _, rec := range records {
+		row, errs := models.ParseBookingImportRow(rec)
+		result := &models.ImportRowResult{Row: rec.Line, Key: row.StudentEmail, Status: models.ImportRowValid}
+		report.Rows = append(report.Rows, result)
+		for _, e := range errs {
+			result.AddError(e)
+		}
+		if result.Status == models.ImportRowError {
+			continue
+		}
+
+		student, ok := students[row.StudentEmail]
+		if !ok {
+			result.AddError(fmt.Errorf("student_email: %w", models.ErrImportUserNotFound))
+		} else if !student.IsStudent() {
+			result.AddError(models.ErrImportNotStudent)
+		}
+
+		lessonID := row.LessonID
+		if lessonID == uuid.Nil {
+			lessonID = lessonIDs[row.LessonExternalID]
+		}
+		lesson, err := s.getImportLesson(ctx, lessons, lessonID)
+		if err != nil {
+			return nil, err
+		}
+		if lesson == nil {
+			result.AddError(models.ErrImportLessonNotFound)
+		}
+		if result.Status == models.ImportRowError {
+			continue
+		}
+		result.Key = fmt.Sprintf("%s %s", row.StudentEmail, lesson.ID)
+		if markDuplicate(result, seen, result.Key) {
+			continue
+		}
+
+		booking, err := s.bookingRepo.GetActiveBookingByStudentAndLesson(ctx, student.ID, lesson.ID)
+		if err != nil && !errors.Is(err, repository.ErrBookingNotFound) {
+			return nil, err
+		}
+		if booking != nil {
+			markSkipped(result, booking.ID)
+			continue
+		}
+
+		if err := s.bookingValidator.ValidateBooking(ctx, student.ID, lesson.ID, true); err != nil {
+			if !errors.Is(err, validator.ErrScheduleConflict) && !errors.Is(err, repository.ErrInsufficientCredits) {
+				return nil, err
+			}
+			result.AddError(err)
+			continue
+		}
+
+		if lesson.CurrentStudents+seats[lesson.ID] >= lesson.MaxStudents {
+			result.AddError(repository.ErrLessonFull)
+		}
+		cost := max(lesson.CreditsCost, 0)
+		if cost > 0 {
+			if _, ok := balances[student.ID]; !ok {
+				credit, err := s.creditRepo.GetBalance(ctx, student.ID)
+				if err != nil {
+					return nil, err
+				}
+				balances[student.ID] = credit.Balance
+			}
+			if spent[student.ID]+cost > balances[student.ID] {
+				result.AddError(repository.ErrInsufficientCredits)
+			}
+		}
+		for _, other := range schedule[student.ID] {
+			if lesson.StartTime.Before(other.EndTime) && other.StartTime.Before(lesson.EndTime) {
+				result.AddError(validator.ErrScheduleConflict)
+				break
+			}
+		}
+		if result.Status == models.ImportRowError {
+			continue
+		}
+		seats[lesson.ID]++
+		spent[student.ID] += cost
+		schedule[student.ID] = append(schedule[student.ID], lesson)
+
+		studentID := student.ID
+		actions = append(actions, &importAction{
+			result: result,
+			apply: func(ctx context.Context, tx pgx.Tx) (uuid.UUID, error) {
+				return s.createBookingTx(ctx, tx, studentID, lessonID, adminID)
+			},
+		})
+	}
+	return actions, nil
+}
+
+// getImportLesson получает занятие с кэшированием; возвращает nil, если занятие не найдено
+func (s *ImportService) getImportLesson(ctx context.Context, cache map[uuid.UUID]*models.Lesson, id uuid.UUID) (*models.Lesson, error) {
+	if id == uuid.Nil {
+		return nil, nil
+	}
+	if lesson, ok := cache[id]; ok {
+		return lesson, nil
+	}
+	lesson, err := s.lessonRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrLessonNotFound) {
+			cache[id] = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+	cache[id] = lesson
+	return lesson, nil
+}
+
+// createBookingTx записывает студента на занятие так же, как BookingService.CreateBooking
+// для администратора: снимает отметку об отписке, реактивирует отмененную запись или создает новую,
+// списывает кредиты и увеличивает счетчик студентов
+func (s *ImportService) createBookingTx(ctx context.Context, tx pgx.Tx, studentID, lessonID, adminID uuid.UUID) (uuid.UUID, error) {
+	if err := s.repo.ClearCancelledBookingTx(ctx, tx, studentID, lessonID); err != nil {
+		return uuid.Nil, err
+	}
+
+	booking, _, err := s.bookingService.bookTx(ctx, tx, studentID, lessonID, true, adminID, "Bulk import booking")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return booking.ID, nil
+}
+
+// markDuplicate отмечает строку ошибкой, если ключ уже встречался в файле. Пустые ключи не проверяются
+func markDuplicate(result *models.ImportRowResult, seen map[string]int, key string) bool {
+	if key == "" || strings.HasSuffix(key, ":") {
+		return false
+	}
+	if row, ok := seen[key]; ok {
+		result.AddError(fmt.Errorf("%w (строка %d)", models.ErrImportDuplicateRow, row))
+		return true
+	}
+	seen[key] = result.Row
+	return false
+}
+
+// markSkipped отмечает строку как уже импортированную
+func markSkipped(result *models.ImportRowResult, id uuid.UUID) {
+	result.Status = models.ImportRowSkipped
+	result.EntityID = &id
+}
+
+// readImportFile читает строки CSV или XLSX файла. Формат определяется по расширению,
+// а при его отсутствии - по сигнатуре ZIP архива
+func readImportFile(fileName string, data []byte) ([][]string, error) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	isZip := bytes.HasPrefix(data, []byte("PK\x03\x04"))
+
+	switch {
+	case ext == ".xlsx" || (ext == "" && isZip):
+		rows, err := xlsx.ReadRows(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", models.ErrImportUnsupportedFormat, err)
+		}
+		return rows, nil
+	case ext == ".csv" || ext == ".txt" || ext == "":
+		return readImportCSV(data)
+	default:
+		return nil, models.ErrImportUnsupportedFormat
+	}
+}
+
+// readImportCSV читает CSV в UTF-8 (с BOM или без) с разделителем ";", "," или табуляцией,
+// определяемым по строке заголовка
+func readImportCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+	comma := ','
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		comma = ';'
+	}
+	if bytes.Count(header, []byte("\t")) > bytes.Count(header, []byte(string(comma))) {
+		comma = '\t'
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrImportUnsupportedFormat, err)
+	}
+	return rows, nil
+}
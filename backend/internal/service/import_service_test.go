@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeImportRepo - ImportRepository без существующих пользователей и внешних идентификаторов
+type fakeImportRepo struct {
+	importServiceRepository
+	runs        []*models.ImportRun
+	externalIDs map[string]uuid.UUID
+}
+
+func (r *fakeImportRepo) GetUsersByEmails(ctx context.Context, emails []string) (map[string]*models.User, error) {
+	return map[string]*models.User{}, nil
+}
+
+func (r *fakeImportRepo) GetExternalIDs(ctx context.Context, kind models.ImportKind, externalIDs []string) (map[string]uuid.UUID, error) {
+	return map[string]uuid.UUID{}, nil
+}
+
+func (r *fakeImportRepo) CreateRunTx(ctx context.Context, tx pgx.Tx, run *models.ImportRun) error {
+	run.ID = uuid.New()
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func (r *fakeImportRepo) SaveExternalIDTx(ctx context.Context, tx pgx.Tx, kind models.ImportKind, externalID string, entityID, importID uuid.UUID) error {
+	r.externalIDs[externalID] = entityID
+	return nil
+}
+
+// fakeImportUserService создает пользователей в памяти. deniedRole имитирует отказ проверки прав на роль,
+// failEmail - ошибку строки при применении, например email, занятый параллельным запросом после проверки файла
+type fakeImportUserService struct {
+	deniedRole models.UserRole
+	failEmail  string
+	created    []*models.User
+}
+
+func (s *fakeImportUserService) ValidateCreateUser(ctx context.Context, actor *models.User, req *models.CreateUserRequest) error {
+	if req.Role == s.deniedRole {
+		return models.ErrRoleAssignDenied
+	}
+	return nil
+}
+
+func (s *fakeImportUserService) CreateUserTx(ctx context.Context, tx pgx.Tx, actor *models.User, req *models.CreateUserRequest) (*models.User, error) {
+	if req.Email == s.failEmail {
+		return nil, repository.ErrUserExists
+	}
+	user := &models.User{ID: uuid.New(), Email: req.Email, Role: req.Role}
+	s.created = append(s.created, user)
+	return user, nil
+}
+
+const importUsersCSV = `email,first_name,last_name,role,external_id
+anna@example.com,Anna,Petrova,student,crm-1
+boris@example.com,Boris,Ivanov,student,crm-2
+vera@example.com,Vera,Sidorova,teacher,crm-3
+`
+
+func newImportFixture(failEmail string) (*fakeTxBeginner, *fakeImportRepo, *fakeImportUserService, *ImportService) {
+	pool := &fakeTxBeginner{}
+	repo := &fakeImportRepo{externalIDs: map[string]uuid.UUID{}}
+	users := &fakeImportUserService{failEmail: failEmail}
+	svc := NewImportService(pool, repo, users, nil, nil, nil, nil, nil)
+	return pool, repo, users, svc
+}
+
+func newUsersImportRequest(data string, actor *models.User) *ImportRequest {
+	return &ImportRequest{
+		Kind:     models.ImportKindUsers,
+		FileName: "users.csv",
+		Data:     []byte(data),
+		Actor:    actor,
+	}
+}
+
+func TestImportService_Import_AppliesUsersInOneTransaction(t *testing.T) {
+	pool, repo, users, svc := newImportFixture("")
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
+	report, err := svc.Import(context.Background(), newUsersImportRequest(importUsersCSV, admin))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Created)
+	require.NotNil(t, report.ImportID)
+	assert.Len(t, users.created, 3)
+	assert.Len(t, repo.externalIDs, 3)
+	for _, row := range report.Rows {
+		assert.Equal(t, models.ImportRowCreated, row.Status)
+		assert.NotNil(t, row.EntityID)
+		assert.NotEmpty(t, row.TemporaryPassword, "generated password should be reported")
+	}
+	require.Len(t, pool.txs, 1)
+	assert.True(t, pool.last().committed)
+}
+
+func TestImportService_Import_RollsBackFileOnFailingRow(t *testing.T) {
+	pool, _, _, svc := newImportFixture("boris@example.com")
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
+	report, err := svc.Import(context.Background(), newUsersImportRequest(importUsersCSV, admin))
+	assert.ErrorIs(t, err, models.ErrImportHasErrors)
+	require.NotNil(t, report)
+
+	require.Len(t, pool.txs, 1)
+	assert.False(t, pool.last().committed)
+	assert.True(t, pool.last().rolledBack)
+	assert.Nil(t, report.ImportID)
+
+	// Строки, созданные до ошибки, откатываются вместе с транзакцией и не отмечаются созданными
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	for _, row := range report.Rows {
+		assert.Nil(t, row.EntityID, "row %d", row.Row)
+		assert.Empty(t, row.TemporaryPassword, "row %d", row.Row)
+		if row.Key == "boris@example.com" {
+			assert.Equal(t, models.ImportRowError, row.Status)
+			assert.Contains(t, row.Errors, repository.ErrUserExists.Error())
+		} else {
+			assert.Equal(t, models.ImportRowValid, row.Status)
+		}
+	}
+}
+
+func TestImportService_Import_InvalidRowBlocksFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "malformed email",
+			data: strings.Replace(importUsersCSV, "boris@example.com", "boris-at-example", 1),
+		},
+		{
+			name: "role the actor cannot assign",
+			data: strings.Replace(importUsersCSV, "Ivanov,student", "Ivanov,admin", 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, repo, users, svc := newImportFixture("")
+			users.deniedRole = models.RoleAdmin
+			actor := &models.User{ID: uuid.New(), Role: models.UserRole("coordinator")}
+
+			report, err := svc.Import(context.Background(), newUsersImportRequest(tt.data, actor))
+			assert.ErrorIs(t, err, models.ErrImportHasErrors)
+			require.NotNil(t, report)
+
+			assert.Equal(t, 1, report.Failed)
+			assert.Equal(t, 2, report.Valid)
+			assert.Empty(t, pool.txs, "nothing should be applied when the file has errors")
+			assert.Empty(t, users.created)
+			assert.Empty(t, repo.runs)
+		})
+	}
+}
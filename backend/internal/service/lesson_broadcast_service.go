@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io"
@@ -43,6 +44,7 @@ type LessonBroadcastService struct {
 	telegramUserRepo repository.TelegramUserRepository
 	telegramClient   *telegram.Client
 	uploadDir        string
+	fileStorage      *FileStorageService
 	mu               sync.Mutex
 }
 
@@ -72,6 +74,11 @@ func NewLessonBroadcastService(
 	}
 }
 
+// SetFileStorage включает хранение файлов рассылок в BlobStore; без него файлы пишутся в uploadDir
+func (s *LessonBroadcastService) SetFileStorage(fileStorage *FileStorageService) {
+	s.fileStorage = fileStorage
+}
+
 // CreateLessonBroadcast создает и отправляет рассылку по уроку
 func (s *LessonBroadcastService) CreateLessonBroadcast(
 	ctx context.Context,
@@ -130,7 +137,7 @@ func (s *LessonBroadcastService) CreateLessonBroadcast(
 
 	// Сохраняем файлы
 	if len(files) > 0 {
-		if err := s.saveFiles(ctx, createdBroadcast.ID, files); err != nil {
+		if err := s.saveFiles(ctx, userID, createdBroadcast.ID, files); err != nil {
 			log.Printf("Failed to save files for broadcast %s: %v", createdBroadcast.ID, err)
 			// Не прерываем процесс, рассылка всё равно будет отправлена
 		}
@@ -316,6 +323,10 @@ func (s *LessonBroadcastService) SendBroadcastAsync(ctx context.Context, broadca
 		lesson.StartTime.Format("02.01.2006 15:04"),
 		broadcast.Message)
 
+	// Файлы из BlobStore скачиваются один раз на всю рассылку
+	filePaths, cleanup := s.localFilePaths(ctx, broadcast.Files)
+	defer cleanup()
+
 	var sentCount, failedCount, skippedCount int
 	var mu sync.Mutex
 
@@ -357,10 +368,9 @@ func (s *LessonBroadcastService) SendBroadcastAsync(ctx context.Context, broadca
 			if len(broadcast.Files) > 1 {
 				mediaFiles := make([]telegram.MediaFile, len(broadcast.Files))
 				for i, file := range broadcast.Files {
-					filePath := filepath.Join(s.uploadDir, file.FilePath)
 					mediaFiles[i] = telegram.MediaFile{
 						FileName: file.FileName,
-						FilePath: filePath,
+						FilePath: filePaths[file.ID],
 					}
 				}
 
@@ -376,7 +386,7 @@ func (s *LessonBroadcastService) SendBroadcastAsync(ctx context.Context, broadca
 			} else {
 				// Если файл один, отправляем обычным способом
 				for _, file := range broadcast.Files {
-					if err := s.sendFile(telegramUser.ChatID, file); err != nil {
+					if err := s.sendFile(telegramUser.ChatID, file, filePaths[file.ID]); err != nil {
 						log.Printf("Failed to send file %s to student %s: %v", file.FileName, student.ID, err)
 						// Не считаем критичной ошибкой если не отправился файл, главное - сообщение доставлено
 					} else {
@@ -476,15 +486,38 @@ func (s *LessonBroadcastService) sendMessage(chatID int64, message string) error
 	return fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
-// sendFile отправляет файл через Telegram
-func (s *LessonBroadcastService) sendFile(chatID int64, file *models.BroadcastFile) error {
+// localFilePaths возвращает пути файлов рассылки на диске по ID файла. Файлы из BlobStore
+// копируются во временные файлы (для LocalStore используется сам объект), cleanup удаляет копии
+func (s *LessonBroadcastService) localFilePaths(ctx context.Context, files []*models.BroadcastFile) (map[uuid.UUID]string, func()) {
+	paths := make(map[uuid.UUID]string, len(files))
+	var cleanups []func()
+	for _, file := range files {
+		if !file.StorageKey.Valid || s.fileStorage == nil {
+			paths[file.ID] = filepath.Join(s.uploadDir, file.FilePath)
+			continue
+		}
+		localPath, cleanup, err := s.fileStorage.LocalCopy(ctx, file.StorageKey.String)
+		if err != nil {
+			log.Printf("Failed to load broadcast file %s from storage: %v", file.ID, err)
+			paths[file.ID] = ""
+			continue
+		}
+		paths[file.ID] = localPath
+		cleanups = append(cleanups, cleanup)
+	}
+	return paths, func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}
+}
+
+// sendFile отправляет файл через Telegram; filePath - путь к файлу на диске
+func (s *LessonBroadcastService) sendFile(chatID int64, file *models.BroadcastFile, filePath string) error {
 	if s.telegramClient == nil {
 		return fmt.Errorf("telegram client not configured")
 	}
 
-	// Полный путь к файлу на диске
-	filePath := filepath.Join(s.uploadDir, file.FilePath)
-
 	// Проверяем существование файла
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return fmt.Errorf("file not found: %s", filePath)
@@ -522,7 +555,7 @@ func (s *LessonBroadcastService) sendMediaGroup(chatID int64, files []telegram.M
 }
 
 // saveFiles сохраняет файлы на диск и создаёт записи в БД
-func (s *LessonBroadcastService) saveFiles(ctx context.Context, broadcastID uuid.UUID, files []*multipart.FileHeader) error {
+func (s *LessonBroadcastService) saveFiles(ctx context.Context, uploadedBy, broadcastID uuid.UUID, files []*multipart.FileHeader) error {
 	for _, fileHeader := range files {
 		// Открываем файл
 		file, err := fileHeader.Open()
@@ -536,6 +569,13 @@ func (s *LessonBroadcastService) saveFiles(ctx context.Context, broadcastID uuid
 			return fmt.Errorf("file %s exceeds maximum size of %d bytes", fileHeader.Filename, models.MaxBroadcastFileSize)
 		}
 
+		if s.fileStorage != nil {
+			if err := s.saveStoredFile(ctx, uploadedBy, broadcastID, fileHeader, file); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// Генерируем уникальное имя файла
 		fileID := uuid.New()
 		ext := filepath.Ext(fileHeader.Filename)
@@ -572,6 +612,33 @@ func (s *LessonBroadcastService) saveFiles(ctx context.Context, broadcastID uuid
 	return nil
 }
 
+// saveStoredFile сохраняет файл рассылки в BlobStore и создаёт запись в БД
+func (s *LessonBroadcastService) saveStoredFile(ctx context.Context, uploadedBy, broadcastID uuid.UUID, fileHeader *multipart.FileHeader, file io.Reader) error {
+	contentType := fileHeader.Header.Get("Content-Type")
+	blob, err := s.fileStorage.Save(ctx, models.StoredFileSourceLessonBroadcasts, uploadedBy, fileHeader.Filename, file, contentType)
+	if err != nil {
+		return fmt.Errorf("failed to save file %s: %w", fileHeader.Filename, err)
+	}
+
+	broadcastFile := &models.BroadcastFile{
+		BroadcastID: broadcastID,
+		FileName:    fileHeader.Filename,
+		FilePath:    blob.Key,
+		FileSize:    blob.Size,
+		MimeType:    contentType,
+		StorageKey:  sql.NullString{String: blob.Key, Valid: true},
+		ContentHash: sql.NullString{String: blob.Hash, Valid: true},
+	}
+
+	if err := s.broadcastRepo.AddBroadcastFile(ctx, broadcastFile); err != nil {
+		s.fileStorage.ReleaseQuietly(ctx, blob.Key)
+		return fmt.Errorf("failed to add file record: %w", err)
+	}
+
+	log.Printf("File stored: %s -> %s", fileHeader.Filename, blob.Key)
+	return nil
+}
+
 // finalizeBroadcast завершает рассылку, обновляя счетчики и статус
 func (s *LessonBroadcastService) finalizeBroadcast(
 	ctx context.Context,
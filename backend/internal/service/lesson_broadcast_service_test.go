@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"mime/multipart"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"tutoring-platform/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -108,6 +110,11 @@ func (m *MockUserRepo) Create(ctx context.Context, user *models.User) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepo) CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	args := m.Called(ctx, tx, user)
+	return args.Error(0)
+}
+
 func (m *MockUserRepo) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
 	args := m.Called(ctx, id, updates)
 	return args.Error(0)
@@ -559,7 +566,7 @@ func TestSendFile_TelegramClientNotConfigured(t *testing.T) {
 	}
 
 	// Execute
-	err := service.sendFile(12345, file)
+	err := service.sendFile(12345, file, filepath.Join(tmpDir, file.FilePath))
 
 	// Assert
 	assert.Error(t, err)
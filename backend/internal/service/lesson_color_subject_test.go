@@ -67,7 +67,7 @@ func TestUpdateLessonColor(t *testing.T) {
 	}
 
 	if *req.Color != newColor {
-		t.Errorf("Expected color %s, got %s", newColor, req.Color)
+		t.Errorf("Expected color %s, got %s", newColor, *req.Color)
 	}
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestUpdateLesson_CannotChangeIndividualMaxStudents verifies that individual lessons
@@ -127,7 +128,7 @@ func TestGroupLessonConstraints(t *testing.T) {
 		req.ApplyDefaults()
 
 		assert.Equal(t, 1, req.MaxStudents, "Default lesson should be individual with 1 max_students")
-		assert.NotNil(t, req.LessonType)
+		require.NotNil(t, req.LessonType)
 		assert.Equal(t, models.LessonTypeIndividual, *req.LessonType, "Default lesson type should be individual")
 
 		// Test with explicit GROUP type set
@@ -19,6 +19,11 @@ type BookingCreator interface {
 	CreateBooking(ctx context.Context, req *models.CreateBookingRequest) (*models.Booking, error)
 }
 
+// BlockedDatesProvider interface for loading holidays and breaks (to avoid circular dependency)
+type BlockedDatesProvider interface {
+	GetBlockingCalendar(ctx context.Context, from, to time.Time) (models.AcademicCalendar, error)
+}
+
 // LessonService обрабатывает бизнес-логику для уроков
 type LessonService struct {
 	lessonRepo      *repository.LessonRepository
@@ -26,6 +31,8 @@ type LessonService struct {
 	lessonValidator *validator.LessonValidator
 	bookingCreator  BookingCreator
 	telegramService *TelegramService
+	blockedDates    BlockedDatesProvider
+	audit           *AuditService
 }
 
 // NewLessonService создает новый LessonService
@@ -47,6 +54,16 @@ func (s *LessonService) SetTelegramService(ts *TelegramService) {
 	s.telegramService = ts
 }
 
+// SetAuditService sets the audit log for lesson deletions
+func (s *LessonService) SetAuditService(audit *AuditService) {
+	s.audit = audit
+}
+
+// SetBlockedDatesProvider sets the academic calendar used to skip or shift recurring lessons on holidays
+func (s *LessonService) SetBlockedDatesProvider(p BlockedDatesProvider) {
+	s.blockedDates = p
+}
+
 // CreateLesson создает новый урок
 func (s *LessonService) CreateLesson(ctx context.Context, req *models.CreateLessonRequest) (*models.Lesson, error) {
 	// Apply defaults BEFORE validation
@@ -342,12 +359,34 @@ func (s *LessonService) UpdateLesson(ctx context.Context, lessonID uuid.UUID, re
 
 // DeleteLesson выполняет мягкое удаление урока
 func (s *LessonService) DeleteLesson(ctx context.Context, lessonID uuid.UUID) error {
-	return s.lessonRepo.Delete(ctx, lessonID)
+	var before *models.Lesson
+	if s.audit != nil {
+		var err error
+		if before, err = s.lessonRepo.GetByID(ctx, lessonID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.lessonRepo.Delete(ctx, lessonID); err != nil {
+		return err
+	}
+
+	if before != nil {
+		s.audit.Record(ctx, models.AuditActionLessonDelete, models.AuditTargetLesson, lessonID.String(), before, nil)
+	}
+	return nil
 }
 
 // DeleteRecurringSeries удаляет все занятия в серии по recurring_group_id
 func (s *LessonService) DeleteRecurringSeries(ctx context.Context, recurringGroupID uuid.UUID) (int64, error) {
-	return s.lessonRepo.DeleteByRecurringGroupID(ctx, recurringGroupID)
+	deleted, err := s.lessonRepo.DeleteByRecurringGroupID(ctx, recurringGroupID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.audit.Record(ctx, models.AuditActionLessonSeriesDelete, models.AuditTargetLesson, recurringGroupID.String(),
+		map[string]interface{}{"recurring_group_id": recurringGroupID, "deleted_lessons": deleted}, nil)
+	return deleted, nil
 }
 
 // ListLessons получает список уроков с фильтрами
@@ -421,14 +460,39 @@ func (s *LessonService) CreateRecurringLessons(ctx context.Context, req *models.
 		endDate = *req.RecurringEndDate
 	}
 
+	policy, err := models.ParseHolidayPolicy(req.HolidayPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Загружаем праздники и каникулы на весь период серии (с запасом на перенос последнего занятия)
+	var calendar models.AcademicCalendar
+	if s.blockedDates != nil && policy != models.HolidayPolicyIgnore {
+		calendar, err = s.blockedDates.GetBlockingCalendar(ctx, req.StartTime, endDate.AddDate(0, 0, 7))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load academic calendar: %w", err)
+		}
+	}
+
 	groupID := uuid.New()
 	var lessons []*models.Lesson
 
 	for currentDate := req.StartTime; currentDate.Before(endDate) || currentDate.Equal(endDate); currentDate = currentDate.AddDate(0, 0, 7) {
+		// Переносим не дальше чем на 6 дней, чтобы не пересечься со следующим занятием серии
+		occurrence, ok := calendar.ResolveOccurrence(currentDate, policy, 6)
+		if !ok {
+			log.Info().
+				Str("recurring_group_id", groupID.String()).
+				Str("date", currentDate.Format("2006-01-02")).
+				Str("holiday_policy", string(policy)).
+				Msg("Skipping recurring lesson on blocked calendar date")
+			continue
+		}
+
 		weekReq := *req
 		duration := req.EndTime.Sub(req.StartTime)
-		weekReq.StartTime = currentDate
-		weekReq.EndTime = currentDate.Add(duration)
+		weekReq.StartTime = occurrence
+		weekReq.EndTime = occurrence.Add(duration)
 		weekReq.IsRecurring = true
 		weekReq.RecurringEndDate = req.RecurringEndDate
 
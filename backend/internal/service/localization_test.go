@@ -45,7 +45,6 @@ func TestLocalizationAllMessagesPresent(t *testing.T) {
 
 		// Success сообщения
 		{"SuccessLessonCreated", loc.SuccessLessonCreated},
-		{"SuccessTemplateCreated", loc.SuccessTemplateCreated},
 		{"SuccessBulkEditApplied", loc.SuccessBulkEditApplied},
 	}
 
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+)
+
+// moderationReviewRepository интерфейс очереди рассмотрения заблокированных сообщений
+type moderationReviewRepository interface {
+	ListBlocked(ctx context.Context, filter *models.ModerationQueueFilter) ([]*models.BlockedMessageReview, int, error)
+	ListBlockedBySender(ctx context.Context, senderID uuid.UUID) ([]*models.BlockedMessageReview, error)
+	GetBlocked(ctx context.Context, id uuid.UUID) (*models.BlockedMessageReview, error)
+	Review(ctx context.Context, id, reviewerID uuid.UUID, status, note string) error
+	Appeal(ctx context.Context, id uuid.UUID, reason string) (bool, error)
+	RuleStats(ctx context.Context) ([]*models.ModerationRuleStats, error)
+}
+
+// ListQueue возвращает очередь модерации по фильтру
+func (s *ModerationService) ListQueue(ctx context.Context, filter *models.ModerationQueueFilter) ([]*models.BlockedMessageReview, int, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+	return s.reviewRepo.ListBlocked(ctx, filter)
+}
+
+// GetBlocked возвращает блокировку по ID
+func (s *ModerationService) GetBlocked(ctx context.Context, id uuid.UUID) (*models.BlockedMessageReview, error) {
+	return s.reviewRepo.GetBlocked(ctx, id)
+}
+
+// ReleaseBlocked снимает блокировку: сообщение становится delivered и доставляется получателям
+func (s *ModerationService) ReleaseBlocked(ctx context.Context, reviewerID, id uuid.UUID, req *models.ModerationReviewRequest) (*models.BlockedMessageReview, error) {
+	item, err := s.review(ctx, reviewerID, id, models.ModerationReviewReleased, req)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.chatRepo.GetMessageByID(ctx, item.MessageID)
+	if err != nil {
+		log.Error().Err(err).Str("message_id", item.MessageID.String()).Msg("Failed to load released message for delivery")
+		return item, nil
+	}
+	s.sendStatusUpdateSSE(message.RoomID, message.ID, models.MessageStatusDelivered)
+	if s.onDelivered != nil {
+		s.onDelivered(ctx, message)
+	}
+	return item, nil
+}
+
+// ConfirmBlocked подтверждает блокировку сообщения
+func (s *ModerationService) ConfirmBlocked(ctx context.Context, reviewerID, id uuid.UUID, req *models.ModerationReviewRequest) (*models.BlockedMessageReview, error) {
+	return s.review(ctx, reviewerID, id, models.ModerationReviewConfirmed, req)
+}
+
+// review сохраняет решение администратора и записывает его в журнал аудита
+func (s *ModerationService) review(ctx context.Context, reviewerID, id uuid.UUID, status string, req *models.ModerationReviewRequest) (*models.BlockedMessageReview, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.reviewRepo.Review(ctx, id, reviewerID, status, req.Note); err != nil {
+		if errors.Is(err, models.ErrModerationAlreadyReviewed) {
+			// Различаем "уже рассмотрено" и "не найдено"
+			if _, getErr := s.reviewRepo.GetBlocked(ctx, id); getErr != nil {
+				return nil, getErr
+			}
+		}
+		return nil, err
+	}
+
+	action := models.AuditActionModerationConfirm
+	if status == models.ModerationReviewReleased {
+		action = models.AuditActionModerationRelease
+	}
+	s.audit.Record(ctx, action, models.AuditTargetBlockedMessage, id.String(),
+		map[string]interface{}{"review_status": models.ModerationReviewPending},
+		map[string]interface{}{"review_status": status, "review_note": req.Note})
+
+	return s.reviewRepo.GetBlocked(ctx, id)
+}
+
+// ListMyBlocked возвращает заблокированные сообщения отправителя с причинами и статусом апелляции
+func (s *ModerationService) ListMyBlocked(ctx context.Context, senderID uuid.UUID) ([]*models.BlockedMessageReview, error) {
+	return s.reviewRepo.ListBlockedBySender(ctx, senderID)
+}
+
+// Appeal подает апелляцию отправителя на блокировку. На каждую блокировку - одна апелляция;
+// подтвержденная блокировка возвращается в очередь на повторное рассмотрение
+func (s *ModerationService) Appeal(ctx context.Context, senderID, id uuid.UUID, req *models.ModerationAppealRequest) (*models.BlockedMessageReview, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	item, err := s.reviewRepo.GetBlocked(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.SenderID != senderID {
+		return nil, repository.ErrUnauthorized
+	}
+	if item.ReviewStatus == models.ModerationReviewReleased {
+		return nil, models.ErrModerationAlreadyReviewed
+	}
+	if item.IsAppealed() {
+		return nil, models.ErrModerationAppealExists
+	}
+
+	ok, err := s.reviewRepo.Appeal(ctx, id, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, models.ErrModerationAppealExists
+	}
+
+	s.notifyAdminAboutAppeal(item, req.Reason)
+	return s.reviewRepo.GetBlocked(ctx, id)
+}
+
+// RuleStats возвращает статистику блокировок по правилам модерации
+func (s *ModerationService) RuleStats(ctx context.Context) ([]*models.ModerationRuleStats, error) {
+	return s.reviewRepo.RuleStats(ctx)
+}
+
+// notifyAdminAboutAppeal отправляет администратору уведомление о новой апелляции
+func (s *ModerationService) notifyAdminAboutAppeal(item *models.BlockedMessageReview, reason string) {
+	if s.telegramService == nil {
+		return
+	}
+
+	text := fmt.Sprintf(
+		"⚖️ *Апелляция на блокировку сообщения*\n\n"+
+			"*Отправитель:* %s\n"+
+			"*Текст:* %s\n"+
+			"*Причина блокировки:* %s\n"+
+			"*Апелляция:* %s",
+		item.SenderName,
+		truncateText(item.MessageText, 100),
+		item.Reason,
+		truncateText(reason, 300),
+	)
+	if err := s.telegramService.SendAdminNotification(context.Background(), text); err != nil {
+		log.Warn().Err(err).Str("blocked_message_id", item.ID.String()).Msg("Failed to notify admin about moderation appeal")
+	}
+}
@@ -20,14 +20,27 @@ type chatRepository interface {
 	CreateBlockedMessage(ctx context.Context, blockedMsg *models.BlockedMessage) error
 }
 
+// Moderator бэкенд проверки текста сообщения. Позволяет заменить LLM (OpenRouter)
+// локальной реализацией, например RegexModerator
+type Moderator interface {
+	// Name возвращает имя бэкенда для логов и мониторинга
+	Name() string
+	// Moderate проверяет сообщение; ошибка означает недоступность бэкенда
+	Moderate(ctx context.Context, message string) (*ModerationResult, error)
+}
+
 // ModerationService управляет модерацией сообщений
 type ModerationService struct {
-	openRouterClient *OpenRouterClient
-	chatRepo         chatRepository
-	telegramService  *TelegramService
-	regexFallback    *RegexModerator
-	circuitBreaker   *CircuitBreaker
-	sseManager       *sse.ConnectionManagerUUID
+	moderator       Moderator
+	chatRepo        chatRepository
+	reviewRepo      moderationReviewRepository
+	telegramService *TelegramService
+	regexFallback   *RegexModerator
+	circuitBreaker  *CircuitBreaker
+	sseManager      *sse.ConnectionManagerUUID
+	audit           *AuditService
+	// onDelivered вызывается, когда сообщение прошло модерацию или блокировка снята
+	onDelivered func(ctx context.Context, message *models.Message)
 }
 
 // CircuitBreaker управляет состоянием circuit breaker для защиты от cascading failures
@@ -136,19 +149,52 @@ func (cb *CircuitBreaker) TryTransitionToHalfOpen() bool {
 	return false
 }
 
-// NewModerationService создает новый сервис модерации
+// NewModerationService создает новый сервис модерации. Без OpenRouter клиента
+// используется regex модерация; бэкенд можно заменить через SetModerator
 func NewModerationService(
 	openRouterClient *OpenRouterClient,
 	chatRepo chatRepository,
 	telegramService *TelegramService,
 ) *ModerationService {
-	return &ModerationService{
-		openRouterClient: openRouterClient,
-		chatRepo:         chatRepo,
-		telegramService:  telegramService,
-		regexFallback:    NewRegexModerator(),
-		circuitBreaker:   NewCircuitBreaker(),
+	s := &ModerationService{
+		chatRepo:        chatRepo,
+		telegramService: telegramService,
+		regexFallback:   NewRegexModerator(),
+		circuitBreaker:  NewCircuitBreaker(),
+	}
+	if openRouterClient != nil {
+		s.moderator = openRouterClient
+	}
+	return s
+}
+
+// SetModerator заменяет бэкенд модерации. nil - только regex модерация
+func (s *ModerationService) SetModerator(moderator Moderator) {
+	s.moderator = moderator
+}
+
+// SetReviewRepository устанавливает репозиторий очереди рассмотрения блокировок
+func (s *ModerationService) SetReviewRepository(repo moderationReviewRepository) {
+	s.reviewRepo = repo
+}
+
+// SetAuditService устанавливает сервис журнала аудита
+func (s *ModerationService) SetAuditService(audit *AuditService) {
+	s.audit = audit
+}
+
+// SetDeliveryHandler устанавливает обработчик доставки сообщения получателям
+// (SSE и Telegram уведомления), вызываемый после успешной модерации или снятия блокировки
+func (s *ModerationService) SetDeliveryHandler(handler func(ctx context.Context, message *models.Message)) {
+	s.onDelivered = handler
+}
+
+// ModeratorName возвращает имя активного бэкенда модерации
+func (s *ModerationService) ModeratorName() string {
+	if s.moderator == nil {
+		return s.regexFallback.Name()
 	}
+	return s.moderator.Name()
 }
 
 // SetSSEManager устанавливает SSE менеджер для отправки уведомлений об изменении статуса
@@ -170,38 +216,44 @@ func (s *ModerationService) ModerateMessageAsync(ctx context.Context, messageID
 		}
 
 		var blocked bool
-		var reason string
+		var reason, rule string
 		usedFallback := false
 
-		// Проверить circuit breaker или отсутствие OpenRouter клиента
-		if s.openRouterClient == nil || s.circuitBreaker.IsOpen() {
-			if s.openRouterClient == nil {
+		// Проверить circuit breaker или отсутствие бэкенда модерации
+		if s.moderator == nil || s.circuitBreaker.IsOpen() {
+			if s.moderator == nil {
 				log.Println("log")
 			} else {
 				log.Println("log")
 			}
-			blocked, reason = s.regexFallback.Check(message.MessageText)
+			rule, reason = s.regexFallback.Match(message.MessageText)
+			blocked = rule != ""
 			usedFallback = true
 		} else {
 			// Попробовать перейти в half-open если нужно
 			s.circuitBreaker.TryTransitionToHalfOpen()
 
-			// Попытка AI модерации через OpenRouter
-			result, err := s.openRouterClient.ModerateMessage(bgCtx, message.MessageText)
+			// Попытка модерации через основной бэкенд (OpenRouter или его замена)
+			result, err := s.moderator.Moderate(bgCtx, message.MessageText)
 			if err != nil {
-				log.Println("log")
+				log.Printf("Moderation backend %s failed, using regex fallback: %v", s.moderator.Name(), err)
 				s.circuitBreaker.RecordFailure()
 
 				// Fallback на regex модерацию
-				blocked, reason = s.regexFallback.Check(message.MessageText)
+				rule, reason = s.regexFallback.Match(message.MessageText)
+				blocked = rule != ""
 				usedFallback = true
-				reason = fmt.Sprintf("Regex fallback: %s (OpenRouter unavailable)", reason)
+				reason = fmt.Sprintf("Regex fallback: %s (%s unavailable)", reason, s.moderator.Name())
 			} else {
 				s.circuitBreaker.RecordSuccess()
 				blocked = result.Blocked
 				reason = result.Reason
+				rule = result.Rule
 			}
 		}
+		if blocked && rule == "" {
+			rule = models.ModerationRuleUnknown
+		}
 
 		now := time.Now()
 
@@ -226,10 +278,12 @@ func (s *ModerationService) ModerateMessageAsync(ctx context.Context, messageID
 					"used_fallback": usedFallback,
 					"moderated_at":  now,
 					"circuit_state": s.getCircuitState(),
+					"moderator":     s.ModeratorName(),
 				},
 				BlockedAt:     now,
 				AdminNotified: false,
 				AdminReviewed: false,
+				Rule:          rule,
 			}
 
 			if err := s.chatRepo.CreateBlockedMessage(bgCtx, blockedMsg); err != nil {
@@ -248,6 +302,12 @@ func (s *ModerationService) ModerateMessageAsync(ctx context.Context, messageID
 
 			// Отправить SSE уведомление об изменении статуса
 			s.sendStatusUpdateSSE(message.RoomID, messageID, models.MessageStatusDelivered)
+
+			// Доставить сообщение получателям
+			if s.onDelivered != nil {
+				message.Status = models.MessageStatusDelivered
+				s.onDelivered(bgCtx, message)
+			}
 		}
 	}()
 }
@@ -461,3 +461,34 @@ func TestModerationService_BlockMessage(t *testing.T) {
 		t.Error("Blocked message record should be created")
 	}
 }
+
+// TestRegexModerator_MatchRules проверяет, что блокировка относится к правилу для статистики
+func TestRegexModerator_MatchRules(t *testing.T) {
+	moderator := NewRegexModerator()
+
+	tests := []struct {
+		message string
+		rule    string
+	}{
+		{"Мой номер +79991234567", models.ModerationRulePhone},
+		{"Пиши на test@example.com", models.ModerationRuleEmail},
+		{"Найди меня в instagram", models.ModerationRuleSocial},
+		{"Созвонимся в zoom", models.ModerationRuleVideoPlatform},
+		{"Когда следующий урок?", ""},
+	}
+
+	for _, tt := range tests {
+		rule, _ := moderator.Match(tt.message)
+		if rule != tt.rule {
+			t.Errorf("Match(%q) rule = %q, want %q", tt.message, rule, tt.rule)
+		}
+
+		result, err := moderator.Moderate(context.Background(), tt.message)
+		if err != nil {
+			t.Fatalf("Moderate(%q) error = %v", tt.message, err)
+		}
+		if result.Blocked != (tt.rule != "") || result.Rule != tt.rule {
+			t.Errorf("Moderate(%q) = %+v, want rule %q", tt.message, result, tt.rule)
+		}
+	}
+}
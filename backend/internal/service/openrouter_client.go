@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"golang.org/x/time/rate"
+
+	"tutoring-platform/internal/models"
 )
 
 // OpenRouterClient управляет взаимодействием с OpenRouter API
@@ -36,6 +38,8 @@ type OpenRouterClient struct {
 type ModerationResult struct {
 	Blocked bool   `json:"blocked"`
 	Reason  string `json:"reason"`
+	// Rule правило, по которому сообщение заблокировано (models.ModerationRule*)
+	Rule string `json:"rule,omitempty"`
 }
 
 // openRouterRequest структура запроса к OpenRouter API
@@ -410,6 +414,24 @@ func (c *OpenRouterClient) ModerateMessage(ctx context.Context, message string)
 	return nil, fmt.Errorf("unexpected error: exhausted all retry attempts, last error: %w", lastErr)
 }
 
+// Name возвращает имя бэкенда модерации
+func (c *OpenRouterClient) Name() string {
+	return "openrouter"
+}
+
+// Moderate реализует Moderator: блокировки AI модели относятся к правилу "ai"
+func (c *OpenRouterClient) Moderate(ctx context.Context, message string) (*ModerationResult, error) {
+	result, err := c.ModerateMessage(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	result.Rule = ""
+	if result.Blocked {
+		result.Rule = models.ModerationRuleAI
+	}
+	return result, nil
+}
+
 // GetConsecutiveFailures возвращает количество последовательных ошибок
 // Может использоваться для мониторинга и диагностики
 func (c *OpenRouterClient) GetConsecutiveFailures() int {
@@ -9,6 +9,7 @@ import (
 	"tutoring-platform/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -39,6 +40,11 @@ func (m *LocalMockUserRepo) Create(ctx context.Context, user *models.User) error
 	return args.Error(0)
 }
 
+func (m *LocalMockUserRepo) CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	args := m.Called(ctx, tx, user)
+	return args.Error(0)
+}
+
 func (m *LocalMockUserRepo) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
 	args := m.Called(ctx, id, updates)
 	return args.Error(0)
@@ -85,6 +91,14 @@ func (m *LocalMockUserRepo) UpdateTelegramUsername(ctx context.Context, userID u
 	return args.Error(0)
 }
 
+func (m *LocalMockUserRepo) GetAllUsersWithTelegramInfo(ctx context.Context) ([]map[string]interface{}, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
 func TestPaymentSettings_GetStatus_Success(t *testing.T) {
 	mockRepo := new(LocalMockUserRepo)
 	service := NewPaymentSettingsService(mockRepo)
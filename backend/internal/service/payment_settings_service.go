@@ -13,7 +13,9 @@ import (
 
 // PaymentSettingsService обрабатывает бизнес-логику управления платежами пользователей
 type PaymentSettingsService struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	audit       *AuditService
+	permissions *PermissionService
 }
 
 // NewPaymentSettingsService создает новый PaymentSettingsService
@@ -23,6 +25,28 @@ func NewPaymentSettingsService(userRepo repository.UserRepository) *PaymentSetti
 	}
 }
 
+// SetAuditService устанавливает журнал аудита для изменений статуса платежей
+func (s *PaymentSettingsService) SetAuditService(audit *AuditService) {
+	s.audit = audit
+}
+
+// SetPermissionService подключает права ролей; без него проверяются права встроенных ролей
+func (s *PaymentSettingsService) SetPermissionService(permissions *PermissionService) {
+	s.permissions = permissions
+}
+
+// authorizeManager проверяет, что пользователь adminID может управлять платежами (payments.manage)
+func (s *PaymentSettingsService) authorizeManager(ctx context.Context, adminID uuid.UUID) error {
+	admin, err := s.userRepo.GetByID(ctx, adminID)
+	if err != nil {
+		return fmt.Errorf("failed to get admin user: %w", err)
+	}
+	if !s.permissions.HasPermission(ctx, admin, models.PermPaymentsManage) {
+		return repository.ErrUnauthorized
+	}
+	return nil
+}
+
 // GetPaymentStatus получает статус платежей для пользователя
 func (s *PaymentSettingsService) GetPaymentStatus(ctx context.Context, userID uuid.UUID) (bool, error) {
 	// Получаем пользователя
@@ -34,15 +58,10 @@ func (s *PaymentSettingsService) GetPaymentStatus(ctx context.Context, userID uu
 	return user.PaymentEnabled, nil
 }
 
-// UpdatePaymentStatus изменяет статус платежей для студента (только для администраторов)
+// UpdatePaymentStatus изменяет статус платежей для студента (право payments.manage)
 func (s *PaymentSettingsService) UpdatePaymentStatus(ctx context.Context, adminID, userID uuid.UUID, enabled bool) (*models.User, error) {
-	// Проверяем что adminID имеет роль 'admin'
-	admin, err := s.userRepo.GetByID(ctx, adminID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get admin user: %w", err)
-	}
-	if !admin.IsAdmin() {
-		return nil, repository.ErrUnauthorized
+	if err := s.authorizeManager(ctx, adminID); err != nil {
+		return nil, err
 	}
 
 	// Проверяем что userID существует
@@ -66,6 +85,8 @@ func (s *PaymentSettingsService) UpdatePaymentStatus(ctx context.Context, adminI
 
 	// Логируем изменение
 	log.Printf("Admin %s changed payment status for student %s to %v", adminID, userID, enabled)
+	s.audit.Record(ctx, models.AuditActionUserPaymentStatus, models.AuditTargetUser, userID.String(),
+		map[string]bool{"payment_enabled": user.PaymentEnabled}, map[string]bool{"payment_enabled": enabled})
 
 	// Получаем обновленного пользователя
 	updatedUser, err := s.userRepo.GetByID(ctx, userID)
@@ -76,15 +97,10 @@ func (s *PaymentSettingsService) UpdatePaymentStatus(ctx context.Context, adminI
 	return updatedUser, nil
 }
 
-// ListStudentsPaymentStatus возвращает список всех студентов с их статусом платежей
+// ListStudentsPaymentStatus возвращает список всех студентов с их статусом платежей (право payments.manage)
 func (s *PaymentSettingsService) ListStudentsPaymentStatus(ctx context.Context, adminID uuid.UUID, filterByEnabled *bool) ([]*models.StudentPaymentStatus, error) {
-	// Проверяем что adminID имеет роль 'admin'
-	admin, err := s.userRepo.GetByID(ctx, adminID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get admin user: %w", err)
-	}
-	if !admin.IsAdmin() {
-		return nil, repository.ErrUnauthorized
+	if err := s.authorizeManager(ctx, adminID); err != nil {
+		return nil, err
 	}
 
 	// Получаем всех студентов
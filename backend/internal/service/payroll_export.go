@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/pkg/xlsx"
+
+	"github.com/google/uuid"
+)
+
+// payrollExportHeader колонки выгрузки ведомостей
+var payrollExportHeader = []string{
+	"Преподаватель", "Тип", "Дата", "Начало", "Окончание", "Предмет / причина",
+	"Студентов", "Студенто-часов", "Кредитов", "Тип ставки", "Ставка", "Сумма",
+}
+
+// Значения колонки "Тип" выгрузки
+const (
+	payrollRowLesson     = "занятие"
+	payrollRowAdjustment = "корректировка"
+	payrollRowTotal      = "итого"
+)
+
+// PayrollExport файл выгрузки ведомостей
+type PayrollExport struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// ExportPeriod выгружает ведомости закрытого периода в CSV или XLSX:
+// по каждому преподавателю строки занятий, корректировок и итоговая строка
+func (s *PayrollService) ExportPeriod(ctx context.Context, periodID uuid.UUID, format models.PayrollExportFormat) (*PayrollExport, error) {
+	if !format.IsValid() {
+		return nil, models.ErrInvalidPayrollExportFormat
+	}
+
+	period, err := s.repo.GetPeriod(ctx, periodID)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.repo.ListStatements(ctx, &periodID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]interface{}, 0)
+	for _, summary := range summaries {
+		st, err := s.repo.GetStatement(ctx, periodID, summary.TeacherID)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, payrollExportRows(st)...)
+	}
+
+	name := fmt.Sprintf("payroll_%s_%s", period.PeriodStart.Format(models.CalendarDateLayout), period.PeriodEnd.Format(models.CalendarDateLayout))
+	export := &PayrollExport{FileName: name + "." + string(format)}
+
+	var buf bytes.Buffer
+	switch format {
+	case models.PayrollExportXLSX:
+		w := xlsx.NewWriter("Ведомость")
+		w.SetHeader(payrollExportHeader...)
+		for _, row := range rows {
+			w.AddRow(row...)
+		}
+		if err := w.Write(&buf); err != nil {
+			return nil, fmt.Errorf("failed to build payroll xlsx: %w", err)
+		}
+		export.ContentType = xlsx.ContentType
+	default:
+		if err := writePayrollCSV(&buf, rows); err != nil {
+			return nil, fmt.Errorf("failed to build payroll csv: %w", err)
+		}
+		export.ContentType = "text/csv; charset=utf-8"
+	}
+
+	export.Data = buf.Bytes()
+	return export, nil
+}
+
+// payrollExportRows формирует строки выгрузки для ведомости преподавателя
+func payrollExportRows(st *models.EarningsStatement) [][]interface{} {
+	rows := make([][]interface{}, 0, len(st.Lines)+len(st.Adjustments)+1)
+	for _, line := range st.Lines {
+		rateType := ""
+		if line.RateType != nil {
+			rateType = string(*line.RateType)
+		}
+		rows = append(rows, []interface{}{
+			st.TeacherName, payrollRowLesson,
+			line.StartTime.Format(models.CalendarDateLayout), line.StartTime.Format("15:04"), line.EndTime.Format("15:04"),
+			line.Subject, line.StudentsCount, line.StudentHours, line.CreditsConsumed,
+			rateType, line.RateAmount, line.Amount,
+		})
+	}
+	for _, adj := range st.Adjustments {
+		rows = append(rows, []interface{}{
+			st.TeacherName, payrollRowAdjustment,
+			adj.AppliesOn.Format(models.CalendarDateLayout), nil, nil,
+			adj.Reason, nil, nil, nil, nil, nil, adj.Amount,
+		})
+	}
+	rows = append(rows, []interface{}{
+		st.TeacherName, payrollRowTotal, nil, nil, nil, nil,
+		nil, st.StudentHours, st.CreditsConsumed, nil, nil, st.TotalAmount,
+	})
+	return rows
+}
+
+// writePayrollCSV записывает строки в CSV с разделителем ";" и BOM, чтобы Excel корректно открыл UTF-8
+func writePayrollCSV(buf *bytes.Buffer, rows [][]interface{}) error {
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(buf)
+	w.Comma = ';'
+
+	if err := w.Write(payrollExportHeader); err != nil {
+		return err
+	}
+	record := make([]string, len(payrollExportHeader))
+	for _, row := range rows {
+		for i, v := range row {
+			switch val := v.(type) {
+			case nil:
+				record[i] = ""
+			case float64:
+				record[i] = strconv.FormatFloat(val, 'f', 2, 64)
+			default:
+				record[i] = fmt.Sprint(val)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// PayrollService рассчитывает оплату преподавателей за проведенные занятия.
+// Для открытого периода ведомости считаются на лету, при закрытии периода фиксируются в БД
+// вместе с построчной расшифровкой, чтобы последующие изменения ставок и занятий их не меняли
+type PayrollService struct {
+	pool     *pgxpool.Pool
+	repo     *repository.PayrollRepository
+	userRepo repository.UserRepository
+}
+
+// NewPayrollService создает новый PayrollService
+func NewPayrollService(pool *pgxpool.Pool, repo *repository.PayrollRepository, userRepo repository.UserRepository) *PayrollService {
+	return &PayrollService{
+		pool:     pool,
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// CreateRate устанавливает ставку преподавателя с даты effective_from
+func (s *PayrollService) CreateRate(ctx context.Context, adminID uuid.UUID, req *models.CreatePayRateRequest) (*models.TeacherPayRate, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	teacher, err := s.getTeacher(ctx, req.TeacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := req.ToRate()
+	rate.TeacherName = teacher.GetFullName()
+	rate.CreatedBy = uuid.NullUUID{UUID: adminID, Valid: true}
+	if err := s.repo.CreateRate(ctx, rate); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("teacher_id", rate.TeacherID.String()).
+		Str("rate_type", string(rate.RateType)).
+		Float64("amount", rate.Amount).
+		Time("effective_from", rate.EffectiveFrom).
+		Msg("Teacher pay rate set")
+
+	return rate, nil
+}
+
+// ListRates получает историю ставок всех преподавателей или одного
+func (s *PayrollService) ListRates(ctx context.Context, teacherID *uuid.UUID) ([]*models.TeacherPayRate, error) {
+	return s.repo.ListRates(ctx, teacherID)
+}
+
+// Preview рассчитывает ведомости за период без сохранения.
+// Учитываются только уже завершившиеся занятия и еще не привязанные к периоду корректировки
+func (s *PayrollService) Preview(ctx context.Context, req *models.PayrollPeriodRequest, teacherID *uuid.UUID) ([]*models.EarningsStatement, error) {
+	start, end, err := req.Dates()
+	if err != nil {
+		return nil, err
+	}
+	return s.calculate(ctx, start, end, teacherID)
+}
+
+// ClosePeriod закрывает расчетный период: фиксирует ведомости всех преподавателей
+// и привязывает к периоду корректировки с датой внутри него. Период должен закончиться
+// и не пересекаться с уже закрытыми
+func (s *PayrollService) ClosePeriod(ctx context.Context, adminID uuid.UUID, req *models.PayrollPeriodRequest) (*models.PayrollPeriodDetails, error) {
+	start, end, err := req.Dates()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if dayAfter(end).After(now) {
+		return nil, models.ErrPayrollPeriodNotFinished
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Failed to rollback payroll close transaction")
+		}
+	}()
+
+	if err := s.repo.LockAdjustmentsTx(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	period := &models.PayrollPeriod{
+		PeriodStart: start,
+		PeriodEnd:   end,
+		ClosedBy:    uuid.NullUUID{UUID: adminID, Valid: true},
+	}
+	if err := s.repo.CreatePeriodTx(ctx, tx, period); err != nil {
+		return nil, err
+	}
+
+	lessons, err := s.repo.GetConductedLessonsTx(ctx, tx, start, dayAfter(end), now)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := s.repo.GetRatesEffectiveByTx(ctx, tx, end)
+	if err != nil {
+		return nil, err
+	}
+	adjustments, err := s.repo.AttachAdjustmentsTx(ctx, tx, period)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := models.BuildEarningsStatements(start, end, lessons, rates, adjustments)
+	for _, st := range statements {
+		st.PeriodID = uuid.NullUUID{UUID: period.ID, Valid: true}
+		if err := s.repo.CreateStatementTx(ctx, tx, st); err != nil {
+			return nil, err
+		}
+		period.TeachersCount++
+		period.TotalAmount += st.TotalAmount
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Info().
+		Str("period_id", period.ID.String()).
+		Time("period_start", start).
+		Time("period_end", end).
+		Int("teachers", period.TeachersCount).
+		Int("lessons", len(lessons)).
+		Float64("total_amount", period.TotalAmount).
+		Msg("Payroll period closed")
+
+	return &models.PayrollPeriodDetails{Period: period, Statements: statements}, nil
+}
+
+// ListPeriods получает закрытые периоды с итогами
+func (s *PayrollService) ListPeriods(ctx context.Context) ([]*models.PayrollPeriod, error) {
+	return s.repo.ListPeriods(ctx)
+}
+
+// GetPeriod получает закрытый период и сводные ведомости преподавателей (без построчной расшифровки)
+func (s *PayrollService) GetPeriod(ctx context.Context, periodID uuid.UUID) (*models.PayrollPeriodDetails, error) {
+	period, err := s.repo.GetPeriod(ctx, periodID)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := s.repo.ListStatements(ctx, &periodID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PayrollPeriodDetails{Period: period, Statements: statements}, nil
+}
+
+// GetStatement получает ведомость преподавателя за закрытый период с расшифровкой
+func (s *PayrollService) GetStatement(ctx context.Context, periodID, teacherID uuid.UUID) (*models.EarningsStatement, error) {
+	return s.repo.GetStatement(ctx, periodID, teacherID)
+}
+
+// GetTeacherEarnings получает начисления преподавателя: зафиксированные ведомости прошлых периодов
+// и предварительный расчет с конца последнего закрытого периода по сегодняшний день
+// (с начала месяца, если закрытых периодов еще нет)
+func (s *PayrollService) GetTeacherEarnings(ctx context.Context, teacherID uuid.UUID) (*models.TeacherEarnings, error) {
+	statements, err := s.repo.ListStatements(ctx, nil, &teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	lastEnd, err := s.repo.GetLastPeriodEnd(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if lastEnd != nil {
+		start = dayAfter(*lastEnd)
+	}
+
+	current := &models.EarningsStatement{TeacherID: teacherID, PeriodStart: start, PeriodEnd: today}
+	if !start.After(today) {
+		preview, err := s.calculate(ctx, start, today, &teacherID)
+		if err != nil {
+			return nil, err
+		}
+		if len(preview) > 0 {
+			current = preview[0]
+		}
+	}
+
+	return &models.TeacherEarnings{Current: current, Statements: statements}, nil
+}
+
+// CreateAdjustment создает премию или удержание. Дата корректировки не должна попадать в закрытый период
+func (s *PayrollService) CreateAdjustment(ctx context.Context, adminID uuid.UUID, req *models.CreateEarningsAdjustmentRequest) (*models.EarningsAdjustment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	teacher, err := s.getTeacher(ctx, req.TeacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	adj := req.ToAdjustment(time.Now().UTC())
+	adj.TeacherName = teacher.GetFullName()
+	adj.CreatedBy = uuid.NullUUID{UUID: adminID, Valid: true}
+	if err := s.repo.CreateAdjustment(ctx, adj); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("adjustment_id", adj.ID.String()).
+		Str("teacher_id", adj.TeacherID.String()).
+		Float64("amount", adj.Amount).
+		Msg("Earnings adjustment created")
+
+	return adj, nil
+}
+
+// ListAdjustments получает корректировки по фильтру
+func (s *PayrollService) ListAdjustments(ctx context.Context, filter repository.AdjustmentFilter) ([]*models.EarningsAdjustment, error) {
+	return s.repo.ListAdjustments(ctx, filter)
+}
+
+// DeleteAdjustment удаляет корректировку, еще не учтенную в закрытом периоде
+func (s *PayrollService) DeleteAdjustment(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeletePendingAdjustment(ctx, id)
+}
+
+// calculate рассчитывает ведомости за период [start, end] по текущим данным
+func (s *PayrollService) calculate(ctx context.Context, start, end time.Time, teacherID *uuid.UUID) ([]*models.EarningsStatement, error) {
+	lessons, err := s.repo.GetConductedLessons(ctx, start, dayAfter(end), time.Now(), teacherID)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := s.repo.GetRatesEffectiveBy(ctx, end, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	adjustments, err := s.repo.GetPendingAdjustments(ctx, start, end, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.BuildEarningsStatements(start, end, lessons, rates, adjustments), nil
+}
+
+// getTeacher получает пользователя, которому можно начислять оплату за занятия
+func (s *PayrollService) getTeacher(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	teacher, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !teacher.CanBeAssignedAsTeacher() {
+		return nil, models.ErrInvalidTeacherID
+	}
+	return teacher, nil
+}
+
+// dayAfter возвращает начало следующего дня для даты периода (конец периода включительный)
+func dayAfter(day time.Time) time.Time {
+	return day.AddDate(0, 0, 1)
+}
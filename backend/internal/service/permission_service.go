@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// permissionCacheTTL время жизни кеша прав: изменения ролей на других инстансах
+// применяются не позже чем через это время
+const permissionCacheTTL = time.Minute
+
+// Ресурсы, для которых регистрируются проверки владения
+const (
+	ResourceLesson = "lesson"
+)
+
+// OwnershipChecker проверяет, принадлежит ли объект пользователю. Используется для прав
+// с суффиксом .own: например, lessons.edit.own разрешает изменять только свои занятия
+type OwnershipChecker interface {
+	IsOwner(ctx context.Context, userID, resourceID uuid.UUID) (bool, error)
+}
+
+// OwnershipCheckerFunc адаптер функции к OwnershipChecker
+type OwnershipCheckerFunc func(ctx context.Context, userID, resourceID uuid.UUID) (bool, error)
+
+// IsOwner вызывает f(ctx, userID, resourceID)
+func (f OwnershipCheckerFunc) IsOwner(ctx context.Context, userID, resourceID uuid.UUID) (bool, error) {
+	return f(ctx, userID, resourceID)
+}
+
+// PermissionService отвечает на вопрос "может ли роль выполнить действие" по данным из БД.
+// Права ролей кешируются и перечитываются после изменений и по истечении permissionCacheTTL
+type PermissionService struct {
+	repo *repository.PermissionRepository
+
+	mu       sync.RWMutex
+	roles    map[models.UserRole]models.PermissionSet
+	loadedAt time.Time
+
+	owners map[string]OwnershipChecker
+}
+
+// NewPermissionService создает новый PermissionService
+func NewPermissionService(repo *repository.PermissionRepository) *PermissionService {
+	return &PermissionService{
+		repo:   repo,
+		owners: make(map[string]OwnershipChecker),
+	}
+}
+
+// RegisterOwnershipChecker регистрирует проверку владения для ресурса. Вызывается при старте
+func (s *PermissionService) RegisterOwnershipChecker(resource string, checker OwnershipChecker) {
+	s.owners[resource] = checker
+}
+
+// PermissionsFor возвращает права роли. Если права из БД недоступны или сервис не подключен (nil),
+// используются права встроенных ролей по умолчанию, чтобы сбой чтения не блокировал работу
+func (s *PermissionService) PermissionsFor(ctx context.Context, role models.UserRole) models.PermissionSet {
+	if s == nil {
+		if set, ok := models.DefaultRolePermissions[role]; ok {
+			return set
+		}
+		return models.NewPermissionSet()
+	}
+
+	s.mu.RLock()
+	roles, fresh := s.roles, time.Since(s.loadedAt) < permissionCacheTTL
+	s.mu.RUnlock()
+
+	if roles == nil || !fresh {
+		if err := s.reload(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to reload role permissions")
+		} else {
+			s.mu.RLock()
+			roles = s.roles
+			s.mu.RUnlock()
+		}
+	}
+
+	if set, ok := roles[role]; ok {
+		return set
+	}
+	if roles == nil {
+		if set, ok := models.DefaultRolePermissions[role]; ok {
+			return set
+		}
+	}
+	return models.NewPermissionSet()
+}
+
+// reload перечитывает права всех ролей из БД
+func (s *PermissionService) reload(ctx context.Context) error {
+	roles, err := s.repo.LoadRolePermissions(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.roles = roles
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// HasPermission проверяет, есть ли у пользователя право
+func (s *PermissionService) HasPermission(ctx context.Context, user *models.User, perm models.Permission) bool {
+	return s.PermissionsFor(ctx, user.Role).Has(perm)
+}
+
+// Authorize проверяет доступ к конкретному объекту: право allPerm дает доступ к любому объекту,
+// ownPerm - только к объектам, которыми пользователь владеет по проверке ресурса resource
+func (s *PermissionService) Authorize(ctx context.Context, user *models.User, ownPerm, allPerm models.Permission, resource string, resourceID uuid.UUID) (bool, error) {
+	perms := s.PermissionsFor(ctx, user.Role)
+	if perms.Has(allPerm) {
+		return true, nil
+	}
+	if !perms.Has(ownPerm) {
+		return false, nil
+	}
+
+	checker, ok := s.owners[resource]
+	if !ok {
+		return false, fmt.Errorf("no ownership checker registered for resource %q", resource)
+	}
+	return checker.IsOwner(ctx, user.ID, resourceID)
+}
+
+// RoleExists проверяет существование роли (встроенной или пользовательской)
+func (s *PermissionService) RoleExists(ctx context.Context, role models.UserRole) (bool, error) {
+	if role.IsBuiltin() {
+		return true, nil
+	}
+	return s.repo.RoleExists(ctx, role)
+}
+
+// ListPermissions возвращает реестр прав
+func (s *PermissionService) ListPermissions() []models.PermissionDefinition {
+	return models.PermissionRegistry
+}
+
+// ListRoles возвращает роли с правами
+func (s *PermissionService) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	return s.repo.ListRoles(ctx)
+}
+
+// CreateRole создает пользовательскую роль
+func (s *PermissionService) CreateRole(ctx context.Context, req *models.RoleRequest) (*models.Role, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Name.IsBuiltin() {
+		return nil, repository.ErrRoleExists
+	}
+	if err := s.repo.CreateRole(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.afterRoleChange(ctx, req.Name)
+}
+
+// UpdateRole заменяет описание и права роли. Права администратора изменить нельзя,
+// чтобы не потерять доступ к управлению ролями
+func (s *PermissionService) UpdateRole(ctx context.Context, req *models.RoleRequest) (*models.Role, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Name == models.RoleAdmin {
+		return nil, models.ErrSystemRoleLocked
+	}
+	if err := s.repo.UpdateRole(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.afterRoleChange(ctx, req.Name)
+}
+
+// DeleteRole удаляет пользовательскую роль, не назначенную пользователям
+func (s *PermissionService) DeleteRole(ctx context.Context, name models.UserRole) error {
+	if name.IsBuiltin() {
+		return models.ErrSystemRoleLocked
+	}
+	if err := s.repo.DeleteRole(ctx, name); err != nil {
+		return err
+	}
+	if err := s.reload(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to reload role permissions")
+	}
+	return nil
+}
+
+// afterRoleChange сбрасывает кеш прав и возвращает актуальную роль
+func (s *PermissionService) afterRoleChange(ctx context.Context, name models.UserRole) (*models.Role, error) {
+	if err := s.reload(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to reload role permissions")
+	}
+	return s.repo.GetRole(ctx, name)
+}
@@ -1,8 +1,11 @@
 package service
 
 import (
+	"context"
 	"regexp"
 	"strings"
+
+	"tutoring-platform/internal/models"
 )
 
 // RegexModerator предоставляет fallback модерацию на основе regex паттернов
@@ -36,32 +39,50 @@ func NewRegexModerator() *RegexModerator {
 
 // Check проверяет сообщение на наличие запрещенных паттернов
 func (m *RegexModerator) Check(message string) (blocked bool, reason string) {
+	rule, reason := m.Match(message)
+	return rule != "", reason
+}
+
+// Match возвращает сработавшее правило (models.ModerationRule*) и причину блокировки.
+// Для разрешенного сообщения правило пустое
+func (m *RegexModerator) Match(message string) (rule string, reason string) {
 	message = strings.ToLower(message)
 
 	// Проверка телефонных номеров
 	if m.phonePattern.MatchString(message) {
-		return true, "Message contains phone number"
+		return models.ModerationRulePhone, "Message contains phone number"
 	}
 
 	// Проверка email адресов
 	if m.emailPattern.MatchString(message) {
-		return true, "Message contains email address"
+		return models.ModerationRuleEmail, "Message contains email address"
 	}
 
 	// Проверка социальных сетей
 	if m.socialPattern.MatchString(message) {
-		return true, "Message contains social media reference"
+		return models.ModerationRuleSocial, "Message contains social media reference"
 	}
 
 	// Проверка платформ для видеозвонков
 	if m.platformPattern.MatchString(message) {
-		return true, "Message contains video call platform reference"
+		return models.ModerationRuleVideoPlatform, "Message contains video call platform reference"
 	}
 
 	// Проверка обфусцированных телефонов
 	if m.obfuscatedPhone.MatchString(message) {
-		return true, "Message contains obfuscated contact information"
+		return models.ModerationRuleObfuscatedPhone, "Message contains obfuscated contact information"
 	}
 
-	return false, ""
+	return "", ""
+}
+
+// Name возвращает имя бэкенда модерации
+func (m *RegexModerator) Name() string {
+	return "regex"
+}
+
+// Moderate реализует Moderator: локальная проверка без внешних вызовов, никогда не возвращает ошибку
+func (m *RegexModerator) Moderate(_ context.Context, message string) (*ModerationResult, error) {
+	rule, reason := m.Match(message)
+	return &ModerationResult{Blocked: rule != "", Reason: reason, Rule: rule}, nil
 }
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// SearchService выполняет полнотекстовый поиск по истории чатов, домашним заданиям и отчетам
+// о занятиях с учетом прав доступа пользователя: сообщения - только из комнат, где он участник,
+// занятия - по правилам видимости GetVisibleLessons
+type SearchService struct {
+	repo *repository.SearchRepository
+}
+
+// NewSearchService создает новый SearchService
+func NewSearchService(repo *repository.SearchRepository) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+// Search ищет по выбранному типу (или по всем типам) и возвращает не больше q.Limit результатов,
+// отсортированных по релевантности, с выделенными совпадениями во фрагментах
+func (s *SearchService) Search(ctx context.Context, userID uuid.UUID, userRole string, q *models.SearchQuery) ([]*models.SearchResult, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	results := []*models.SearchResult{}
+	for _, searchType := range q.Types() {
+		var (
+			found []*models.SearchResult
+			err   error
+		)
+		switch searchType {
+		case models.SearchTypeMessages:
+			found, err = s.repo.SearchMessages(ctx, userID, q.Query, q.Limit)
+		case models.SearchTypeHomework:
+			found, err = s.repo.SearchHomework(ctx, userID, userRole, q.Query, q.Limit)
+		case models.SearchTypeReports:
+			found, err = s.repo.SearchReports(ctx, userID, userRole, q.Query, q.Limit)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+
+	// Каждый тип уже отсортирован в БД; при поиске по всем типам объединяем по рангу
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Date.After(results[j].Date)
+	})
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+
+	for _, result := range results {
+		result.HighlightSnippet()
+	}
+	return results, nil
+}
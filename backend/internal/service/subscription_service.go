@@ -0,0 +1,928 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/pkg/metrics"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// subscriptionBillingInterval период запуска фонового биллинга подписок
+	subscriptionBillingInterval = 10 * time.Minute
+	// subscriptionBillingBatch ограничивает число подписок, обрабатываемых за один проход
+	subscriptionBillingBatch = 100
+	// subscriptionInvoiceStaleAfter время без ответа YooKassa, после которого статус счёта запрашивается вручную
+	subscriptionInvoiceStaleAfter = 15 * time.Minute
+	// subscriptionInvoiceSubmitDeadline время, в течение которого повторяется отправка счёта при сбоях сети
+	subscriptionInvoiceSubmitDeadline = 24 * time.Hour
+)
+
+// SubscriptionService управляет подписками: тарифы, первая оплата с сохранением способа оплаты,
+// автоматические продления фоновым биллингом, повторные попытки списания, отмена и смена тарифа
+// Кредиты начисляются только через CreditService.AddCreditsWithTx в одной транзакции с отметкой об оплате счёта
+type SubscriptionService struct {
+	pool             txBeginner
+	subscriptionRepo subscriptionServiceRepository
+	creditService    subscriptionCreditService
+	yookassaClient   YooKassaClientInterface
+	userRepo         repository.UserRepository
+	returnURL        string
+
+	stopBilling chan struct{}
+	billingDone chan struct{}
+}
+
+// subscriptionServiceRepository - часть SubscriptionRepository, используемая SubscriptionService
+type subscriptionServiceRepository interface {
+	ListPlans(ctx context.Context, activeOnly bool) ([]*models.SubscriptionPlan, error)
+	GetPlanByID(ctx context.Context, id uuid.UUID) (*models.SubscriptionPlan, error)
+	GetPlanByIDTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.SubscriptionPlan, error)
+	CreatePlan(ctx context.Context, plan *models.SubscriptionPlan) error
+	UpdatePlan(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error
+	List(ctx context.Context, status *models.SubscriptionStatus) ([]*models.SubscriptionWithPlan, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.SubscriptionWithPlan, error)
+	GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.Subscription, error)
+	GetLiveByUser(ctx context.Context, userID uuid.UUID) (*models.SubscriptionWithPlan, error)
+	CreateWithInvoiceTx(ctx context.Context, tx pgx.Tx, sub *models.Subscription, invoice *models.SubscriptionInvoice) error
+	UpdateTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, updates map[string]interface{}) error
+	ListDueForBilling(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+	ListEndingAtPeriodEnd(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+	CreateInvoiceTx(ctx context.Context, tx pgx.Tx, invoice *models.SubscriptionInvoice) error
+	GetInvoiceByID(ctx context.Context, id uuid.UUID) (*models.SubscriptionInvoice, error)
+	GetInvoiceForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.SubscriptionInvoice, error)
+	GetInvoiceIDByYooKassaID(ctx context.Context, yookassaPaymentID string) (uuid.UUID, error)
+	GetLastInvoiceTx(ctx context.Context, tx pgx.Tx, subscriptionID uuid.UUID) (*models.SubscriptionInvoice, error)
+	HasPendingInvoiceTx(ctx context.Context, tx pgx.Tx, subscriptionID uuid.UUID) (bool, error)
+	ListInvoices(ctx context.Context, subscriptionID uuid.UUID) ([]*models.SubscriptionInvoice, error)
+	ListStalePendingInvoices(ctx context.Context, olderThan time.Time, limit int) ([]*models.SubscriptionInvoice, error)
+	SetInvoicePayment(ctx context.Context, id uuid.UUID, yookassaPaymentID, confirmationURL string) error
+	UpdateInvoiceTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, updates map[string]interface{}) error
+}
+
+// subscriptionCreditService начисляет кредиты в транзакции оплаты счёта; реализуется CreditService
+type subscriptionCreditService interface {
+	AddCreditsWithTx(ctx context.Context, tx pgx.Tx, req *models.AddCreditsRequest) error
+}
+
+// NewSubscriptionService создает новый SubscriptionService
+func NewSubscriptionService(
+	pool txBeginner,
+	subscriptionRepo subscriptionServiceRepository,
+	creditService subscriptionCreditService,
+	yookassaClient YooKassaClientInterface,
+	userRepo repository.UserRepository,
+	returnURL string,
+) *SubscriptionService {
+	return &SubscriptionService{
+		pool:             pool,
+		subscriptionRepo: subscriptionRepo,
+		creditService:    creditService,
+		yookassaClient:   yookassaClient,
+		userRepo:         userRepo,
+		returnURL:        returnURL,
+	}
+}
+
+// ListPlans возвращает тарифы (activeOnly - только доступные для оформления)
+func (s *SubscriptionService) ListPlans(ctx context.Context, activeOnly bool) ([]*models.SubscriptionPlan, error) {
+	return s.subscriptionRepo.ListPlans(ctx, activeOnly)
+}
+
+// CreatePlan создает тариф
+func (s *SubscriptionService) CreatePlan(ctx context.Context, req *models.CreateSubscriptionPlanRequest) (*models.SubscriptionPlan, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	plan := &models.SubscriptionPlan{
+		Name:             req.Name,
+		Description:      req.Description,
+		CreditsPerPeriod: req.CreditsPerPeriod,
+		Price:            req.Price,
+		PeriodMonths:     req.PeriodMonths,
+		IsActive:         true,
+	}
+	if err := s.subscriptionRepo.CreatePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// UpdatePlan обновляет тариф
+// Новые цена и количество кредитов применяются к существующим подпискам со следующего продления
+func (s *SubscriptionService) UpdatePlan(ctx context.Context, id uuid.UUID, req *models.UpdateSubscriptionPlanRequest) (*models.SubscriptionPlan, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.CreditsPerPeriod != nil {
+		updates["credits_per_period"] = *req.CreditsPerPeriod
+	}
+	if req.Price != nil {
+		updates["price"] = *req.Price
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	if err := s.subscriptionRepo.UpdatePlan(ctx, id, updates); err != nil {
+		return nil, err
+	}
+
+	return s.subscriptionRepo.GetPlanByID(ctx, id)
+}
+
+// GetUserSubscription возвращает незавершённую подписку пользователя
+func (s *SubscriptionService) GetUserSubscription(ctx context.Context, userID uuid.UUID) (*models.SubscriptionWithPlan, error) {
+	return s.subscriptionRepo.GetLiveByUser(ctx, userID)
+}
+
+// GetUserInvoices возвращает счета незавершённой подписки пользователя
+func (s *SubscriptionService) GetUserInvoices(ctx context.Context, userID uuid.UUID) ([]*models.SubscriptionInvoice, error) {
+	sub, err := s.subscriptionRepo.GetLiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.subscriptionRepo.ListInvoices(ctx, sub.ID)
+}
+
+// ListSubscriptions возвращает подписки для администратора (status != nil - только с указанным статусом)
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, status *models.SubscriptionStatus) ([]*models.SubscriptionWithPlan, error) {
+	return s.subscriptionRepo.List(ctx, status)
+}
+
+// GetSubscriptionInvoices возвращает счета подписки для администратора
+func (s *SubscriptionService) GetSubscriptionInvoices(ctx context.Context, subscriptionID uuid.UUID) ([]*models.SubscriptionInvoice, error) {
+	if _, err := s.subscriptionRepo.GetByID(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.subscriptionRepo.ListInvoices(ctx, subscriptionID)
+}
+
+// Subscribe оформляет подписку: создаёт подписку в статусе pending и первый счёт,
+// платёж в YooKassa создаётся с save_payment_method для последующих автоплатежей
+func (s *SubscriptionService) Subscribe(ctx context.Context, userID uuid.UUID, req *models.SubscribeRequest) (*models.SubscribeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.PaymentEnabled {
+		return nil, repository.ErrPaymentDisabledForUser
+	}
+
+	plan, err := s.subscriptionRepo.GetPlanByID(ctx, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.IsActive {
+		return nil, models.ErrPlanNotActive
+	}
+
+	if _, err := s.subscriptionRepo.GetLiveByUser(ctx, userID); err == nil {
+		return nil, models.ErrSubscriptionAlreadyExists
+	} else if !errors.Is(err, repository.ErrSubscriptionNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	sub := &models.Subscription{
+		UserID: userID,
+		PlanID: plan.ID,
+		Status: models.SubscriptionStatusPending,
+	}
+	// Период первого счёта уточняется в момент оплаты
+	invoice := &models.SubscriptionInvoice{
+		UserID:      userID,
+		PlanID:      plan.ID,
+		Kind:        models.SubscriptionInvoiceInitial,
+		PeriodStart: now,
+		PeriodEnd:   models.AddBillingPeriod(now, plan.PeriodMonths),
+		Amount:      plan.Price,
+		Credits:     plan.CreditsPerPeriod,
+	}
+
+	err = s.inTx(ctx, func(tx pgx.Tx) error {
+		return s.subscriptionRepo.CreateWithInvoiceTx(ctx, tx, sub, invoice)
+	})
+	if err != nil {
+		// Параллельное оформление упирается в уникальный индекс незавершённой подписки
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, models.ErrSubscriptionAlreadyExists
+		}
+		return nil, err
+	}
+
+	resp, err := s.yookassaClient.CreatePayment(ctx, &CreatePaymentRequest{
+		Amount: Amount{
+			Value:    fmt.Sprintf("%.2f", invoice.Amount),
+			Currency: "RUB",
+		},
+		Capture: true,
+		Confirmation: Confirmation{
+			Type:      "redirect",
+			ReturnURL: s.returnURL,
+		},
+		Description:       fmt.Sprintf("Подписка «%s»", plan.Name),
+		Metadata:          Metadata{SubscriptionInvoiceID: invoice.ID.String()},
+		SavePaymentMethod: true,
+		IdempotencyKey:    invoice.ID.String(),
+	})
+	if err != nil {
+		// Первый платёж не создан - освобождаем пользователя для повторного оформления
+		if failErr := s.settleInvoiceFailed(ctx, invoice.ID, "не удалось создать платёж"); failErr != nil {
+			log.Error().Err(failErr).Str("invoice_id", invoice.ID.String()).Msg("Failed to mark subscription invoice as failed")
+		}
+		return nil, fmt.Errorf("failed to create YooKassa payment: %w", err)
+	}
+
+	if err := s.subscriptionRepo.SetInvoicePayment(ctx, invoice.ID, resp.ID, resp.Confirmation.ConfirmationURL); err != nil {
+		return nil, err
+	}
+
+	metrics.PaymentsProcessed.WithLabelValues("pending").Inc()
+
+	return &models.SubscribeResponse{
+		SubscriptionID:  sub.ID,
+		InvoiceID:       invoice.ID,
+		Amount:          invoice.Amount,
+		ConfirmationURL: resp.Confirmation.ConfirmationURL,
+	}, nil
+}
+
+// CancelSubscription отменяет подписку пользователя
+// По умолчанию подписка действует до конца оплаченного периода; immediately - завершается сразу,
+// уже начисленные за период кредиты остаются у пользователя
+func (s *SubscriptionService) CancelSubscription(ctx context.Context, userID uuid.UUID, req *models.CancelSubscriptionRequest) (*models.SubscriptionWithPlan, error) {
+	current, err := s.subscriptionRepo.GetLiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.cancel(ctx, current.ID, req.Immediately)
+}
+
+// CancelSubscriptionByID отменяет подписку по ID (операция администратора)
+func (s *SubscriptionService) CancelSubscriptionByID(ctx context.Context, subscriptionID uuid.UUID, req *models.CancelSubscriptionRequest) (*models.SubscriptionWithPlan, error) {
+	return s.cancel(ctx, subscriptionID, req.Immediately)
+}
+
+// ResumeSubscription снимает отмену на конец периода
+func (s *SubscriptionService) ResumeSubscription(ctx context.Context, userID uuid.UUID) (*models.SubscriptionWithPlan, error) {
+	current, err := s.subscriptionRepo.GetLiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.inSubscriptionTx(ctx, current.ID, func(tx pgx.Tx, sub *models.Subscription) error {
+		if sub.Status != models.SubscriptionStatusActive {
+			return models.ErrSubscriptionNotActive
+		}
+		if !sub.CancelAtPeriodEnd {
+			return nil
+		}
+		return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, map[string]interface{}{
+			"cancel_at_period_end": false,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.subscriptionRepo.GetByID(ctx, current.ID)
+}
+
+// ChangePlan меняет тариф подписки
+// Повышение (более дорогой тариф) применяется сразу: списывается пропорциональная доплата за остаток периода
+// и начисляется пропорциональная разница в кредитах. Понижение вступает в силу со следующего периода
+func (s *SubscriptionService) ChangePlan(ctx context.Context, userID uuid.UUID, req *models.ChangeSubscriptionPlanRequest) (*models.ChangeSubscriptionPlanResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	current, err := s.subscriptionRepo.GetLiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ChangeSubscriptionPlanResponse{}
+	var invoice *models.SubscriptionInvoice
+	var paymentMethodID string
+
+	err = s.inSubscriptionTx(ctx, current.ID, func(tx pgx.Tx, sub *models.Subscription) error {
+		if sub.Status != models.SubscriptionStatusActive || !sub.CurrentPeriodEnd.Valid {
+			return models.ErrSubscriptionNotActive
+		}
+
+		if req.PlanID == sub.PlanID {
+			if !sub.PendingPlanID.Valid {
+				return models.ErrSubscriptionSamePlan
+			}
+			// Возврат к текущему тарифу отменяет запланированное понижение
+			return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, map[string]interface{}{
+				"pending_plan_id": nil,
+			})
+		}
+
+		oldPlan, err := s.subscriptionRepo.GetPlanByIDTx(ctx, tx, sub.PlanID)
+		if err != nil {
+			return err
+		}
+		newPlan, err := s.subscriptionRepo.GetPlanByIDTx(ctx, tx, req.PlanID)
+		if err != nil {
+			return err
+		}
+		if !newPlan.IsActive {
+			return models.ErrPlanNotActive
+		}
+
+		now := time.Now()
+		if newPlan.Price <= oldPlan.Price {
+			effectiveFrom := sub.CurrentPeriodEnd.Time
+			result.EffectiveFrom = &effectiveFrom
+			return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, map[string]interface{}{
+				"pending_plan_id": newPlan.ID,
+			})
+		}
+
+		fraction := models.ProrationFraction(sub.CurrentPeriodStart.Time, sub.CurrentPeriodEnd.Time, now)
+		amount, credits := models.ProrateUpgrade(oldPlan, newPlan, fraction)
+		result.Immediate = true
+
+		// Доплата меньше минимальной суммы платежа - тариф меняется без списания
+		if amount < 1 {
+			return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, map[string]interface{}{
+				"plan_id":         newPlan.ID,
+				"pending_plan_id": nil,
+			})
+		}
+
+		if !sub.PaymentMethodID.Valid {
+			return models.ErrSubscriptionNoPaymentMethod
+		}
+		pending, err := s.subscriptionRepo.HasPendingInvoiceTx(ctx, tx, sub.ID)
+		if err != nil {
+			return err
+		}
+		if pending {
+			return models.ErrSubscriptionPaymentInProcess
+		}
+
+		invoice = &models.SubscriptionInvoice{
+			SubscriptionID: sub.ID,
+			UserID:         sub.UserID,
+			PlanID:         newPlan.ID,
+			Kind:           models.SubscriptionInvoiceProration,
+			PeriodStart:    now,
+			PeriodEnd:      sub.CurrentPeriodEnd.Time,
+			Amount:         amount,
+			Credits:        credits,
+		}
+		paymentMethodID = sub.PaymentMethodID.String
+		result.ProratedAmount = amount
+		result.ProratedCredits = credits
+		return s.subscriptionRepo.CreateInvoiceTx(ctx, tx, invoice)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Тариф переключается после подтверждения оплаты доплаты
+	if invoice != nil {
+		s.submitRecurringInvoice(ctx, invoice, paymentMethodID)
+	}
+
+	result.Subscription, err = s.subscriptionRepo.GetByID(ctx, current.ID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ProcessInvoicePaymentSucceeded обрабатывает успешную оплату счёта подписки (webhook payment.succeeded)
+func (s *SubscriptionService) ProcessInvoicePaymentSucceeded(ctx context.Context, yookassaPaymentID, paymentMethodID string, methodSaved bool) error {
+	invoiceID, err := s.subscriptionRepo.GetInvoiceIDByYooKassaID(ctx, yookassaPaymentID)
+	if err != nil {
+		return err
+	}
+	if !methodSaved {
+		paymentMethodID = ""
+	}
+	return s.settleInvoiceSucceeded(ctx, invoiceID, paymentMethodID)
+}
+
+// ProcessInvoicePaymentCancelled обрабатывает отклонённую оплату счёта подписки (webhook payment.canceled)
+func (s *SubscriptionService) ProcessInvoicePaymentCancelled(ctx context.Context, yookassaPaymentID, reason string) error {
+	invoiceID, err := s.subscriptionRepo.GetInvoiceIDByYooKassaID(ctx, yookassaPaymentID)
+	if err != nil {
+		return err
+	}
+	return s.settleInvoiceFailed(ctx, invoiceID, reason)
+}
+
+// StartBillingWorker запускает фоновый биллинг подписок
+func (s *SubscriptionService) StartBillingWorker() {
+	s.stopBilling = make(chan struct{})
+	s.billingDone = make(chan struct{})
+	go s.billingLoop()
+}
+
+// Shutdown останавливает фоновый биллинг подписок (для graceful shutdown)
+func (s *SubscriptionService) Shutdown() {
+	if s.stopBilling == nil {
+		return
+	}
+	close(s.stopBilling)
+	<-s.billingDone
+	log.Info().Msg("Subscription billing worker shutdown complete")
+}
+
+// billingLoop периодически запускает проход биллинга
+func (s *SubscriptionService) billingLoop() {
+	ticker := time.NewTicker(subscriptionBillingInterval)
+	defer ticker.Stop()
+	defer close(s.billingDone)
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), subscriptionBillingInterval/2)
+			if err := s.RunBillingCycle(ctx, time.Now()); err != nil {
+				log.Error().Err(err).Msg("Subscription billing cycle failed")
+			}
+			cancel()
+		case <-s.stopBilling:
+			log.Info().Msg("Subscription billing worker shutting down")
+			return
+		}
+	}
+}
+
+// RunBillingCycle выполняет один проход биллинга:
+// 1. завершает подписки, отменённые на конец периода
+// 2. сверяет с YooKassa счета, оставшиеся без ответа, и повторяет неотправленные
+// 3. создаёт и отправляет счета продления по подпискам с наступившим сроком списания
+// Ошибки отдельных подписок логируются и не прерывают проход
+func (s *SubscriptionService) RunBillingCycle(ctx context.Context, now time.Time) error {
+	ending, err := s.subscriptionRepo.ListEndingAtPeriodEnd(ctx, now, subscriptionBillingBatch)
+	if err != nil {
+		return err
+	}
+	for _, id := range ending {
+		if err := s.finishCancelledSubscription(ctx, id, now); err != nil {
+			log.Error().Err(err).Str("subscription_id", id.String()).Msg("Failed to finish cancelled subscription")
+		}
+	}
+
+	stale, err := s.subscriptionRepo.ListStalePendingInvoices(ctx, now.Add(-subscriptionInvoiceStaleAfter), subscriptionBillingBatch)
+	if err != nil {
+		return err
+	}
+	for _, invoice := range stale {
+		if err := s.reconcileInvoice(ctx, invoice, now); err != nil {
+			log.Error().Err(err).Str("invoice_id", invoice.ID.String()).Msg("Failed to reconcile subscription invoice")
+		}
+	}
+
+	due, err := s.subscriptionRepo.ListDueForBilling(ctx, now, subscriptionBillingBatch)
+	if err != nil {
+		return err
+	}
+	for _, id := range due {
+		if err := s.chargeRenewal(ctx, id, now); err != nil {
+			log.Error().Err(err).Str("subscription_id", id.String()).Msg("Failed to charge subscription renewal")
+		}
+	}
+
+	if len(ending)+len(stale)+len(due) > 0 {
+		log.Info().
+			Int("finished", len(ending)).
+			Int("reconciled", len(stale)).
+			Int("charged", len(due)).
+			Msg("Subscription billing cycle completed")
+	}
+	return nil
+}
+
+// cancel отменяет подписку на конец периода или немедленно
+func (s *SubscriptionService) cancel(ctx context.Context, subscriptionID uuid.UUID, immediately bool) (*models.SubscriptionWithPlan, error) {
+	err := s.inSubscriptionTx(ctx, subscriptionID, func(tx pgx.Tx, sub *models.Subscription) error {
+		if !sub.IsLive() {
+			return models.ErrSubscriptionNotActive
+		}
+
+		now := time.Now()
+		if sub.Status == models.SubscriptionStatusPending {
+			// Первая оплата ещё не прошла - закрываем счёт; если оплата всё же придёт, кредиты будут начислены
+			invoice, err := s.subscriptionRepo.GetLastInvoiceTx(ctx, tx, sub.ID)
+			if err != nil && !errors.Is(err, repository.ErrSubscriptionInvoiceNotFound) {
+				return err
+			}
+			if invoice != nil && invoice.Status == models.SubscriptionInvoicePending {
+				if err := s.subscriptionRepo.UpdateInvoiceTx(ctx, tx, invoice.ID, map[string]interface{}{
+					"status":         models.SubscriptionInvoiceFailed,
+					"failure_reason": "подписка отменена до оплаты",
+				}); err != nil {
+					return err
+				}
+			}
+			immediately = true
+		}
+
+		if !immediately {
+			if sub.Status != models.SubscriptionStatusActive {
+				immediately = true
+			} else {
+				return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, map[string]interface{}{
+					"cancel_at_period_end": true,
+				})
+			}
+		}
+
+		return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, map[string]interface{}{
+			"status":          models.SubscriptionStatusCancelled,
+			"cancelled_at":    now,
+			"next_billing_at": nil,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.subscriptionRepo.GetByID(ctx, subscriptionID)
+}
+
+// finishCancelledSubscription завершает подписку, отменённую на конец периода
+func (s *SubscriptionService) finishCancelledSubscription(ctx context.Context, subscriptionID uuid.UUID, now time.Time) error {
+	return s.inSubscriptionTx(ctx, subscriptionID, func(tx pgx.Tx, sub *models.Subscription) error {
+		if !sub.IsLive() || !sub.CancelAtPeriodEnd || sub.CurrentPeriodEnd.Time.After(now) {
+			return nil
+		}
+		return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, map[string]interface{}{
+			"status":          models.SubscriptionStatusCancelled,
+			"cancelled_at":    now,
+			"next_billing_at": nil,
+		})
+	})
+}
+
+// chargeRenewal создаёт счёт продления и списывает оплату сохранённым способом
+// Для подписки в просрочке повторно выставляется счёт за тот же неоплаченный период
+func (s *SubscriptionService) chargeRenewal(ctx context.Context, subscriptionID uuid.UUID, now time.Time) error {
+	var invoice *models.SubscriptionInvoice
+	var paymentMethodID string
+
+	err := s.inSubscriptionTx(ctx, subscriptionID, func(tx pgx.Tx, sub *models.Subscription) error {
+		if (sub.Status != models.SubscriptionStatusActive && sub.Status != models.SubscriptionStatusPastDue) ||
+			sub.CancelAtPeriodEnd || !sub.NextBillingAt.Valid || sub.NextBillingAt.Time.After(now) ||
+			!sub.CurrentPeriodEnd.Valid {
+			return nil
+		}
+
+		pending, err := s.subscriptionRepo.HasPendingInvoiceTx(ctx, tx, sub.ID)
+		if err != nil || pending {
+			return err
+		}
+
+		if !sub.PaymentMethodID.Valid {
+			log.Warn().Str("subscription_id", sub.ID.String()).Msg("Subscription has no saved payment method, expiring")
+			return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, map[string]interface{}{
+				"status":              models.SubscriptionStatusExpired,
+				"next_billing_at":     nil,
+				"last_failure_reason": models.ErrSubscriptionNoPaymentMethod.Error(),
+			})
+		}
+
+		planID := sub.PlanID
+		if sub.PendingPlanID.Valid {
+			planID = sub.PendingPlanID.UUID
+		}
+		plan, err := s.subscriptionRepo.GetPlanByIDTx(ctx, tx, planID)
+		if err != nil {
+			return err
+		}
+
+		periodStart := sub.CurrentPeriodEnd.Time
+		invoice = &models.SubscriptionInvoice{
+			SubscriptionID: sub.ID,
+			UserID:         sub.UserID,
+			PlanID:         plan.ID,
+			Kind:           models.SubscriptionInvoiceRenewal,
+			PeriodStart:    periodStart,
+			PeriodEnd:      models.AddBillingPeriod(periodStart, plan.PeriodMonths),
+			Amount:         plan.Price,
+			Credits:        plan.CreditsPerPeriod,
+			Attempt:        sub.FailedAttempts + 1,
+		}
+		paymentMethodID = sub.PaymentMethodID.String
+		return s.subscriptionRepo.CreateInvoiceTx(ctx, tx, invoice)
+	})
+	if err != nil {
+		return err
+	}
+
+	if invoice != nil {
+		s.submitRecurringInvoice(ctx, invoice, paymentMethodID)
+	}
+	return nil
+}
+
+// submitRecurringInvoice отправляет автоплатёж по счёту сохранённым способом оплаты
+// Ключ идемпотентности - ID счёта, поэтому повторная отправка после сбоя сети не приводит к двойному списанию
+// При ошибке отправки счёт остаётся pending и повторяется при сверке
+func (s *SubscriptionService) submitRecurringInvoice(ctx context.Context, invoice *models.SubscriptionInvoice, paymentMethodID string) {
+	resp, err := s.yookassaClient.CreatePayment(ctx, &CreatePaymentRequest{
+		Amount: Amount{
+			Value:    fmt.Sprintf("%.2f", invoice.Amount),
+			Currency: "RUB",
+		},
+		Capture:         true,
+		Description:     fmt.Sprintf("Подписка: счёт #%s", invoice.ID.String()),
+		Metadata:        Metadata{SubscriptionInvoiceID: invoice.ID.String()},
+		PaymentMethodID: paymentMethodID,
+		IdempotencyKey:  invoice.ID.String(),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("invoice_id", invoice.ID.String()).Msg("Failed to submit subscription payment, will retry")
+		return
+	}
+
+	if err := s.subscriptionRepo.SetInvoicePayment(ctx, invoice.ID, resp.ID, ""); err != nil {
+		log.Error().Err(err).Str("invoice_id", invoice.ID.String()).Msg("Failed to save subscription payment ID")
+		return
+	}
+
+	if err := s.applyPaymentStatus(ctx, invoice.ID, resp); err != nil {
+		log.Error().Err(err).Str("invoice_id", invoice.ID.String()).Msg("Failed to apply subscription payment status")
+	}
+}
+
+// reconcileInvoice обрабатывает счёт, оставшийся без ответа YooKassa:
+// неотправленный счёт отправляется повторно (или закрывается по истечении срока),
+// по отправленному запрашивается актуальный статус платежа
+func (s *SubscriptionService) reconcileInvoice(ctx context.Context, invoice *models.SubscriptionInvoice, now time.Time) error {
+	if !invoice.YooKassaPaymentID.Valid {
+		if now.Sub(invoice.CreatedAt) > subscriptionInvoiceSubmitDeadline {
+			return s.settleInvoiceFailed(ctx, invoice.ID, "не удалось отправить платёж в YooKassa")
+		}
+		sub, err := s.subscriptionRepo.GetByID(ctx, invoice.SubscriptionID)
+		if err != nil {
+			return err
+		}
+		if !sub.PaymentMethodID.Valid {
+			return s.settleInvoiceFailed(ctx, invoice.ID, models.ErrSubscriptionNoPaymentMethod.Error())
+		}
+		s.submitRecurringInvoice(ctx, invoice, sub.PaymentMethodID.String)
+		return nil
+	}
+
+	resp, err := s.yookassaClient.GetPayment(ctx, invoice.YooKassaPaymentID.String)
+	if err != nil {
+		return fmt.Errorf("failed to get YooKassa payment: %w", err)
+	}
+	return s.applyPaymentStatus(ctx, invoice.ID, resp)
+}
+
+// applyPaymentStatus применяет конечный статус платежа YooKassa к счёту; pending оставляет без изменений
+func (s *SubscriptionService) applyPaymentStatus(ctx context.Context, invoiceID uuid.UUID, resp *YooKassaPaymentResponse) error {
+	switch resp.Status {
+	case "succeeded":
+		paymentMethodID := ""
+		if resp.PaymentMethod.Saved {
+			paymentMethodID = resp.PaymentMethod.ID
+		}
+		return s.settleInvoiceSucceeded(ctx, invoiceID, paymentMethodID)
+	case "canceled":
+		reason := "платёж отклонён"
+		if resp.CancellationDetails != nil && resp.CancellationDetails.Reason != "" {
+			reason = resp.CancellationDetails.Reason
+		}
+		return s.settleInvoiceFailed(ctx, invoiceID, reason)
+	default:
+		return nil
+	}
+}
+
+// settleInvoiceSucceeded отмечает счёт оплаченным, начисляет кредиты и продлевает подписку в одной транзакции
+// Повторная обработка того же счёта (дубликат webhook, сверка) ничего не меняет
+// Если подписка к моменту оплаты уже завершена, кредиты всё равно начисляются - оплата получена
+func (s *SubscriptionService) settleInvoiceSucceeded(ctx context.Context, invoiceID uuid.UUID, paymentMethodID string) error {
+	found, err := s.subscriptionRepo.GetInvoiceByID(ctx, invoiceID)
+	if err != nil {
+		return err
+	}
+
+	var invoice *models.SubscriptionInvoice
+	err = s.inSubscriptionTx(ctx, found.SubscriptionID, func(tx pgx.Tx, sub *models.Subscription) error {
+		invoice, err = s.subscriptionRepo.GetInvoiceForUpdate(ctx, tx, invoiceID)
+		if err != nil {
+			return err
+		}
+		if invoice.ProcessedAt.Valid {
+			log.Info().Str("invoice_id", invoice.ID.String()).Msg("Subscription invoice already processed, skipping")
+			invoice = nil
+			return nil
+		}
+
+		plan, err := s.subscriptionRepo.GetPlanByIDTx(ctx, tx, invoice.PlanID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if invoice.Kind == models.SubscriptionInvoiceInitial {
+			// Оплаченный период начинается с момента оплаты, а не с момента оформления
+			invoice.PeriodStart = now
+			invoice.PeriodEnd = models.AddBillingPeriod(now, plan.PeriodMonths)
+		}
+
+		if invoice.Credits > 0 {
+			if err := s.creditService.AddCreditsWithTx(ctx, tx, &models.AddCreditsRequest{
+				UserID:      invoice.UserID,
+				Amount:      invoice.Credits,
+				Reason:      models.SubscriptionCreditReason(plan.Name, invoice),
+				PerformedBy: invoice.UserID, // Система от имени пользователя
+			}); err != nil {
+				return fmt.Errorf("failed to add subscription credits: %w", err)
+			}
+		}
+
+		if err := s.subscriptionRepo.UpdateInvoiceTx(ctx, tx, invoice.ID, map[string]interface{}{
+			"status":         models.SubscriptionInvoiceSucceeded,
+			"period_start":   invoice.PeriodStart,
+			"period_end":     invoice.PeriodEnd,
+			"failure_reason": "",
+			"processed_at":   now,
+		}); err != nil {
+			return err
+		}
+
+		if !sub.IsLive() {
+			log.Warn().
+				Str("subscription_id", sub.ID.String()).
+				Str("invoice_id", invoice.ID.String()).
+				Msg("Payment received for finished subscription, credits granted without renewal")
+			return nil
+		}
+
+		updates := map[string]interface{}{}
+		switch invoice.Kind {
+		case models.SubscriptionInvoiceInitial, models.SubscriptionInvoiceRenewal:
+			updates["status"] = models.SubscriptionStatusActive
+			updates["plan_id"] = invoice.PlanID
+			updates["pending_plan_id"] = nil
+			updates["current_period_start"] = invoice.PeriodStart
+			updates["current_period_end"] = invoice.PeriodEnd
+			updates["next_billing_at"] = invoice.PeriodEnd
+			updates["failed_attempts"] = 0
+			updates["last_failure_reason"] = ""
+		case models.SubscriptionInvoiceProration:
+			updates["plan_id"] = invoice.PlanID
+			updates["pending_plan_id"] = nil
+		}
+		if paymentMethodID != "" {
+			updates["payment_method_id"] = paymentMethodID
+		}
+		return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, updates)
+	})
+	if err != nil {
+		return err
+	}
+
+	if invoice != nil {
+		metrics.PaymentsProcessed.WithLabelValues("succeeded").Inc()
+		metrics.PaymentAmountTotal.Add(invoice.Amount)
+		metrics.CreditsAdded.Add(float64(invoice.Credits))
+
+		log.Info().
+			Str("invoice_id", invoice.ID.String()).
+			Str("kind", string(invoice.Kind)).
+			Int("credits", invoice.Credits).
+			Msg("Subscription invoice paid")
+	}
+	return nil
+}
+
+// settleInvoiceFailed отмечает счёт неоплаченным и применяет политику повторных попыток:
+// первая оплата - подписка завершается; продление - подписка переходит в past_due
+// со следующей попыткой по расписанию SubscriptionDunningSchedule, после исчерпания - expired;
+// доплата за смену тарифа - тариф не меняется
+func (s *SubscriptionService) settleInvoiceFailed(ctx context.Context, invoiceID uuid.UUID, reason string) error {
+	found, err := s.subscriptionRepo.GetInvoiceByID(ctx, invoiceID)
+	if err != nil {
+		return err
+	}
+
+	settled := false
+	err = s.inSubscriptionTx(ctx, found.SubscriptionID, func(tx pgx.Tx, sub *models.Subscription) error {
+		invoice, err := s.subscriptionRepo.GetInvoiceForUpdate(ctx, tx, invoiceID)
+		if err != nil {
+			return err
+		}
+		if invoice.Status != models.SubscriptionInvoicePending {
+			return nil
+		}
+		settled = true
+
+		if err := s.subscriptionRepo.UpdateInvoiceTx(ctx, tx, invoice.ID, map[string]interface{}{
+			"status":         models.SubscriptionInvoiceFailed,
+			"failure_reason": reason,
+		}); err != nil {
+			return err
+		}
+
+		if !sub.IsLive() {
+			return nil
+		}
+
+		switch invoice.Kind {
+		case models.SubscriptionInvoiceInitial:
+			return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, map[string]interface{}{
+				"status":              models.SubscriptionStatusExpired,
+				"last_failure_reason": reason,
+			})
+		case models.SubscriptionInvoiceRenewal:
+			failedAttempts := sub.FailedAttempts + 1
+			updates := map[string]interface{}{
+				"failed_attempts":     failedAttempts,
+				"last_failure_reason": reason,
+			}
+			if delay, ok := models.DunningRetryDelay(failedAttempts); ok {
+				updates["status"] = models.SubscriptionStatusPastDue
+				updates["next_billing_at"] = time.Now().Add(delay)
+			} else {
+				updates["status"] = models.SubscriptionStatusExpired
+				updates["next_billing_at"] = nil
+				log.Warn().Str("subscription_id", sub.ID.String()).Msg("Subscription renewal retries exhausted, expiring")
+			}
+			return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, updates)
+		default:
+			return s.subscriptionRepo.UpdateTx(ctx, tx, sub.ID, map[string]interface{}{
+				"last_failure_reason": reason,
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if settled {
+		metrics.PaymentsProcessed.WithLabelValues("cancelled").Inc()
+		log.Warn().Str("invoice_id", invoiceID.String()).Str("reason", reason).Msg("Subscription invoice payment failed")
+	}
+	return nil
+}
+
+// inSubscriptionTx выполняет fn в транзакции с заблокированной строкой подписки
+// Все изменения подписки и её счетов сериализуются этой блокировкой
+func (s *SubscriptionService) inSubscriptionTx(ctx context.Context, subscriptionID uuid.UUID, fn func(tx pgx.Tx, sub *models.Subscription) error) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		sub, err := s.subscriptionRepo.GetByIDForUpdate(ctx, tx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		return fn(tx, sub)
+	})
+}
+
+func (s *SubscriptionService) inTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Failed to rollback subscription transaction")
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
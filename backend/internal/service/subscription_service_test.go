@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"tutoring-platform/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscriptionRepo хранит одну подписку, тариф и счета в памяти
+type fakeSubscriptionRepo struct {
+	subscriptionServiceRepository
+	sub            *models.Subscription
+	plan           *models.SubscriptionPlan
+	invoices       map[uuid.UUID]*models.SubscriptionInvoice
+	byYooKassa     map[string]uuid.UUID
+	subUpdates     int
+	invoiceUpdates int
+}
+
+func (r *fakeSubscriptionRepo) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.Subscription, error) {
+	return r.sub, nil
+}
+
+func (r *fakeSubscriptionRepo) GetPlanByIDTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.SubscriptionPlan, error) {
+	return r.plan, nil
+}
+
+func (r *fakeSubscriptionRepo) GetInvoiceIDByYooKassaID(ctx context.Context, yookassaPaymentID string) (uuid.UUID, error) {
+	return r.byYooKassa[yookassaPaymentID], nil
+}
+
+func (r *fakeSubscriptionRepo) GetInvoiceByID(ctx context.Context, id uuid.UUID) (*models.SubscriptionInvoice, error) {
+	invoice := *r.invoices[id]
+	return &invoice, nil
+}
+
+func (r *fakeSubscriptionRepo) GetInvoiceForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.SubscriptionInvoice, error) {
+	invoice := *r.invoices[id]
+	return &invoice, nil
+}
+
+func (r *fakeSubscriptionRepo) UpdateInvoiceTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, updates map[string]interface{}) error {
+	invoice := r.invoices[id]
+	if status, ok := updates["status"].(models.SubscriptionInvoiceStatus); ok {
+		invoice.Status = status
+	}
+	if processedAt, ok := updates["processed_at"].(time.Time); ok {
+		invoice.ProcessedAt = sql.NullTime{Time: processedAt, Valid: true}
+	}
+	r.invoiceUpdates++
+	return nil
+}
+
+func (r *fakeSubscriptionRepo) UpdateTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, updates map[string]interface{}) error {
+	if status, ok := updates["status"].(models.SubscriptionStatus); ok {
+		r.sub.Status = status
+	}
+	if periodEnd, ok := updates["current_period_end"].(time.Time); ok {
+		r.sub.CurrentPeriodEnd = sql.NullTime{Time: periodEnd, Valid: true}
+	}
+	if failedAttempts, ok := updates["failed_attempts"].(int); ok {
+		r.sub.FailedAttempts = failedAttempts
+	}
+	if paymentMethodID, ok := updates["payment_method_id"].(string); ok {
+		r.sub.PaymentMethodID = sql.NullString{String: paymentMethodID, Valid: true}
+	}
+	r.subUpdates++
+	return nil
+}
+
+// fakeSubscriptionCredits запоминает начисления кредитов
+type fakeSubscriptionCredits struct {
+	requests []*models.AddCreditsRequest
+}
+
+func (c *fakeSubscriptionCredits) AddCreditsWithTx(ctx context.Context, tx pgx.Tx, req *models.AddCreditsRequest) error {
+	c.requests = append(c.requests, req)
+	return nil
+}
+
+// newSettlementFixture создает подписку past_due с ожидающим оплаты счётом продления на 8 кредитов
+func newSettlementFixture() (*fakeTxBeginner, *fakeSubscriptionRepo, *fakeSubscriptionCredits, *SubscriptionService, *models.SubscriptionInvoice) {
+	userID := uuid.New()
+	plan := &models.SubscriptionPlan{ID: uuid.New(), Name: "Monthly", CreditsPerPeriod: 8, PeriodMonths: 1}
+	sub := &models.Subscription{ID: uuid.New(), UserID: userID, PlanID: plan.ID, Status: models.SubscriptionStatusPastDue, FailedAttempts: 2}
+	periodStart := time.Now().Truncate(time.Second)
+	invoice := &models.SubscriptionInvoice{
+		ID:                uuid.New(),
+		SubscriptionID:    sub.ID,
+		UserID:            userID,
+		PlanID:            plan.ID,
+		Kind:              models.SubscriptionInvoiceRenewal,
+		PeriodStart:       periodStart,
+		PeriodEnd:         models.AddBillingPeriod(periodStart, 1),
+		Amount:            4000,
+		Credits:           8,
+		Status:            models.SubscriptionInvoicePending,
+		YooKassaPaymentID: sql.NullString{String: "yk-payment-1", Valid: true},
+	}
+
+	pool := &fakeTxBeginner{}
+	repo := &fakeSubscriptionRepo{
+		sub:        sub,
+		plan:       plan,
+		invoices:   map[uuid.UUID]*models.SubscriptionInvoice{invoice.ID: invoice},
+		byYooKassa: map[string]uuid.UUID{"yk-payment-1": invoice.ID},
+	}
+	credits := &fakeSubscriptionCredits{}
+	svc := NewSubscriptionService(pool, repo, credits, nil, nil, "")
+	return pool, repo, credits, svc, invoice
+}
+
+func TestSettleInvoiceSucceeded_GrantsCreditsAndRenews(t *testing.T) {
+	pool, repo, credits, svc, invoice := newSettlementFixture()
+
+	err := svc.ProcessInvoicePaymentSucceeded(context.Background(), "yk-payment-1", "pm-1", true)
+	require.NoError(t, err)
+
+	require.Len(t, credits.requests, 1)
+	assert.Equal(t, invoice.UserID, credits.requests[0].UserID)
+	assert.Equal(t, 8, credits.requests[0].Amount)
+
+	assert.Equal(t, models.SubscriptionInvoiceSucceeded, invoice.Status)
+	assert.True(t, invoice.ProcessedAt.Valid)
+	assert.Equal(t, models.SubscriptionStatusActive, repo.sub.Status)
+	assert.Equal(t, 0, repo.sub.FailedAttempts)
+	assert.True(t, repo.sub.CurrentPeriodEnd.Time.Equal(invoice.PeriodEnd))
+	assert.Equal(t, "pm-1", repo.sub.PaymentMethodID.String)
+	assert.True(t, pool.last().committed)
+}
+
+func TestSettleInvoiceSucceeded_IsIdempotent(t *testing.T) {
+	_, repo, credits, svc, invoice := newSettlementFixture()
+	ctx := context.Background()
+
+	// Дубликат webhook и последующая сверка статуса обрабатывают тот же счёт повторно
+	require.NoError(t, svc.ProcessInvoicePaymentSucceeded(ctx, "yk-payment-1", "pm-1", true))
+	processedAt := invoice.ProcessedAt.Time
+	require.NoError(t, svc.ProcessInvoicePaymentSucceeded(ctx, "yk-payment-1", "pm-1", true))
+	require.NoError(t, svc.settleInvoiceSucceeded(ctx, invoice.ID, ""))
+
+	assert.Len(t, credits.requests, 1, "credits must be granted exactly once")
+	assert.Equal(t, 1, repo.invoiceUpdates)
+	assert.Equal(t, 1, repo.subUpdates)
+	assert.True(t, invoice.ProcessedAt.Time.Equal(processedAt))
+}
+
+func TestSettleInvoiceSucceeded_FinishedSubscriptionStillGetsCredits(t *testing.T) {
+	_, repo, credits, svc, invoice := newSettlementFixture()
+	repo.sub.Status = models.SubscriptionStatusCancelled
+
+	require.NoError(t, svc.settleInvoiceSucceeded(context.Background(), invoice.ID, ""))
+
+	assert.Len(t, credits.requests, 1)
+	assert.Equal(t, models.SubscriptionInvoiceSucceeded, invoice.Status)
+	assert.Equal(t, models.SubscriptionStatusCancelled, repo.sub.Status, "finished subscription should not be renewed")
+	assert.Zero(t, repo.subUpdates)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
@@ -61,6 +62,14 @@ func (m *MockTelegramUserRepoForValidation) GetAllWithUserInfo(ctx context.Conte
 	return args.Get(0).([]*models.TelegramUser), args.Error(1)
 }
 
+func (m *MockTelegramUserRepoForValidation) GetSubscribedUserIDs(ctx context.Context, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
 func (m *MockTelegramUserRepoForValidation) GetByRoleWithUserInfo(ctx context.Context, role string) ([]*models.TelegramUser, error) {
 	args := m.Called(ctx, role)
 	if args.Get(0) == nil {
@@ -112,6 +121,11 @@ func (m *MockUserRepoForValidation) Create(ctx context.Context, user *models.Use
 	return args.Error(0)
 }
 
+func (m *MockUserRepoForValidation) CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	args := m.Called(ctx, tx, user)
+	return args.Error(0)
+}
+
 func (m *MockUserRepoForValidation) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -161,6 +175,14 @@ func (m *MockUserRepoForValidation) UpdateTelegramUsername(ctx context.Context,
 	return args.Error(0)
 }
 
+func (m *MockUserRepoForValidation) GetAllUsersWithTelegramInfo(ctx context.Context) ([]map[string]interface{}, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
 func (m *MockUserRepoForValidation) Exists(ctx context.Context, email string) (bool, error) {
 	args := m.Called(ctx, email)
 	return args.Bool(0), args.Error(1)
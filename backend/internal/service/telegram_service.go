@@ -133,6 +133,12 @@ type TelegramService struct {
 	tokenStore        *TokenStore // Deprecated: kept for backwards compatibility, use telegramTokenRepo
 	stopCleanup       chan struct{}
 	cleanupDone       chan struct{}
+	audit             *AuditService
+}
+
+// SetAuditService устанавливает журнал аудита для привязок Telegram администратором
+func (s *TelegramService) SetAuditService(audit *AuditService) {
+	s.audit = audit
 }
 
 // NewTelegramService создает новый TelegramService с запуском фоновой очистки токенов
@@ -507,6 +513,18 @@ func (s *TelegramService) SendUserNotification(ctx context.Context, userID uuid.
 
 // HandleWebhook обрабатывает webhook от Telegram
 func (s *TelegramService) HandleWebhook(ctx context.Context, update *telegram.Update) error {
+	// Нажатие inline кнопки рассылки: подтверждаем, чтобы у пользователя пропал индикатор загрузки
+	if update.CallbackQuery != nil {
+		log.Info().
+			Int64("telegram_id", update.CallbackQuery.From.ID).
+			Str("data", update.CallbackQuery.Data).
+			Msg("Broadcast button pressed")
+		if err := s.telegramClient.AnswerCallbackQuery(update.CallbackQuery.ID, ""); err != nil {
+			log.Warn().Err(err).Msg("Failed to answer callback query")
+		}
+		return nil
+	}
+
 	// Проверяем наличие сообщения
 	if update.Message == nil {
 		// Не сообщение - пропускаем
@@ -608,6 +626,14 @@ func (s *TelegramService) HandleWebhook(ctx context.Context, update *telegram.Up
 // Использует атомарную операцию для защиты от race condition при одновременных запросах.
 // Гарантирует, что только один пользователь может привязать данный telegram_id.
 func (s *TelegramService) SetUserTelegram(ctx context.Context, userID uuid.UUID, telegramID, chatID int64, username string) error {
+	// Предыдущая привязка нужна только для журнала аудита
+	var before interface{}
+	if s.audit != nil {
+		if link, err := s.telegramUserRepo.GetByUserID(ctx, userID); err == nil {
+			before = telegramLinkAuditState(link.TelegramID, link.ChatID, link.Username)
+		}
+	}
+
 	// Используем атомарную операцию с SELECT FOR UPDATE для защиты от race condition
 	// При одновременных запросах от разных пользователей с одинаковым telegram_id,
 	// только первый успешно привяжет, остальные получат ErrTelegramIDAlreadyLinked
@@ -629,9 +655,20 @@ func (s *TelegramService) SetUserTelegram(ctx context.Context, userID uuid.UUID,
 	}
 
 	log.Info().Msgf("Successfully set Telegram for user %s", userID)
+	s.audit.Record(ctx, models.AuditActionUserTelegramLink, models.AuditTargetUser, userID.String(),
+		before, telegramLinkAuditState(telegramID, chatID, username))
 	return nil
 }
 
+// telegramLinkAuditState состояние привязки Telegram для журнала аудита
+func telegramLinkAuditState(telegramID, chatID int64, username string) map[string]interface{} {
+	return map[string]interface{}{
+		"telegram_id": telegramID,
+		"chat_id":     chatID,
+		"username":    username,
+	}
+}
+
 // SendMessage отправляет сообщение в Telegram чат (для админ операций)
 func (s *TelegramService) SendMessage(ctx context.Context, chatID int64, message string) error {
 	if s.telegramClient == nil {
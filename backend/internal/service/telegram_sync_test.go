@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -39,6 +40,11 @@ func (m *SyncTestUserRepository) Create(ctx context.Context, user *models.User)
 	return args.Error(0)
 }
 
+func (m *SyncTestUserRepository) CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	args := m.Called(ctx, tx, user)
+	return args.Error(0)
+}
+
 func (m *SyncTestUserRepository) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
 	args := m.Called(ctx, id, updates)
 	return args.Error(0)
@@ -85,6 +91,14 @@ func (m *SyncTestUserRepository) UpdateTelegramUsername(ctx context.Context, use
 	return args.Error(0)
 }
 
+func (m *SyncTestUserRepository) GetAllUsersWithTelegramInfo(ctx context.Context) ([]map[string]interface{}, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
 // SyncTestTelegramTokenRepository - мок для синхронизационных тестов
 type SyncTestTelegramTokenRepository struct {
 	mock.Mock
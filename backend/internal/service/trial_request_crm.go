@@ -0,0 +1,527 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"tutoring-platform/internal/models"
+	"tutoring-platform/internal/repository"
+	"tutoring-platform/pkg/hash"
+)
+
+const (
+	// trialRequestReminderInterval период проверки наступивших сроков контакта по заявкам
+	trialRequestReminderInterval = 5 * time.Minute
+	// trialRequestReminderBatch максимальное число напоминаний за один проход
+	trialRequestReminderBatch = 100
+	// temporaryPasswordLength длина временного пароля студента, созданного из заявки
+	temporaryPasswordLength = 12
+)
+
+// temporaryPasswordAlphabet символы временного пароля без похожих друг на друга (0/O, 1/l/I)
+const temporaryPasswordAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// ListTrialRequests получает заявки по фильтру статуса и ответственного
+func (s *TrialRequestService) ListTrialRequests(ctx context.Context, filter models.TrialRequestFilter) ([]*models.TrialRequest, error) {
+	requests, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trial requests: %w", err)
+	}
+	return requests, nil
+}
+
+// GetTrialRequestDetails получает заявку вместе с журналом активности
+func (s *TrialRequestService) GetTrialRequestDetails(ctx context.Context, id int64) (*models.TrialRequestDetails, error) {
+	tr, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := s.repo.ListActivities(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TrialRequestDetails{TrialRequest: tr, Activities: activities}, nil
+}
+
+// UpdateStatus переводит заявку в другой статус воронки и записывает переход в журнал
+func (s *TrialRequestService) UpdateStatus(ctx context.Context, id int64, req *models.UpdateTrialRequestStatusRequest, actorID uuid.UUID) (*models.TrialRequest, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := s.inTrialRequestTx(ctx, id, func(tx pgx.Tx, tr *models.TrialRequest) error {
+		if tr.Status == models.TrialRequestStatusConverted {
+			return models.ErrTrialRequestAlreadyConverted
+		}
+		if !tr.Status.CanTransitionTo(req.Status) {
+			return models.ErrInvalidTrialRequestTransition
+		}
+
+		from := tr.Status
+		tr.Status = req.Status
+		tr.LostReason = nil
+		if req.Status == models.TrialRequestStatusLost {
+			// Закрытая заявка не требует контакта: напоминание больше не нужно
+			tr.LostReason = req.LostReason
+			tr.FollowUpAt = nil
+			tr.FollowUpRemindedAt = nil
+		}
+		if err := s.repo.UpdatePipelineTx(ctx, tx, tr); err != nil {
+			return err
+		}
+
+		activity := &models.TrialRequestActivity{
+			TrialRequestID: tr.ID,
+			ActorID:        &actorID,
+			Kind:           models.TrialRequestActivityStatusChange,
+			FromStatus:     &from,
+			ToStatus:       &tr.Status,
+		}
+		if tr.LostReason != nil {
+			activity.Body = *tr.LostReason
+		}
+		return s.repo.AddActivityTx(ctx, tx, activity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// Assign назначает ответственного администратора (или снимает назначение) и уведомляет его в Telegram
+func (s *TrialRequestService) Assign(ctx context.Context, id int64, req *models.AssignTrialRequestRequest, actorID uuid.UUID) (*models.TrialRequest, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	body := "Ответственный снят"
+	if req.AssignedTo != nil {
+		assignee, err := s.userRepo.GetByID(ctx, *req.AssignedTo)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return nil, models.ErrInvalidTrialRequestAssignee
+			}
+			return nil, fmt.Errorf("failed to get assignee: %w", err)
+		}
+		if !assignee.IsAdmin() {
+			return nil, models.ErrInvalidTrialRequestAssignee
+		}
+		body = "Ответственный: " + assignee.GetFullName()
+	}
+
+	err := s.inTrialRequestTx(ctx, id, func(tx pgx.Tx, tr *models.TrialRequest) error {
+		if err := ensureTrialRequestOpen(tr); err != nil {
+			return err
+		}
+
+		tr.AssignedTo = req.AssignedTo
+		if err := s.repo.UpdatePipelineTx(ctx, tx, tr); err != nil {
+			return err
+		}
+
+		return s.repo.AddActivityTx(ctx, tx, &models.TrialRequestActivity{
+			TrialRequestID: tr.ID,
+			ActorID:        &actorID,
+			Kind:           models.TrialRequestActivityAssignment,
+			Body:           body,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tr, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Самому себе уведомление не отправляем
+	if tr.AssignedTo != nil && *tr.AssignedTo != actorID {
+		go s.notifyAdmin(*tr.AssignedTo, "📋 Вам назначена заявка на пробное занятие\n\n"+s.formatTrialRequestMessage(tr))
+	}
+
+	return tr, nil
+}
+
+// SetFollowUp устанавливает (или снимает) срок следующего контакта.
+// При переносе срока напоминание будет отправлено заново
+func (s *TrialRequestService) SetFollowUp(ctx context.Context, id int64, req *models.SetTrialRequestFollowUpRequest, actorID uuid.UUID) (*models.TrialRequest, error) {
+	if err := req.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+
+	err := s.inTrialRequestTx(ctx, id, func(tx pgx.Tx, tr *models.TrialRequest) error {
+		if err := ensureTrialRequestOpen(tr); err != nil {
+			return err
+		}
+
+		tr.FollowUpAt = req.FollowUpAt
+		tr.FollowUpRemindedAt = nil
+		if err := s.repo.UpdatePipelineTx(ctx, tx, tr); err != nil {
+			return err
+		}
+
+		body := "Срок контакта снят"
+		if req.FollowUpAt != nil {
+			body = "Связаться до " + req.FollowUpAt.Format("02.01.2006 15:04")
+		}
+		if req.Note != "" {
+			body += ": " + req.Note
+		}
+
+		return s.repo.AddActivityTx(ctx, tx, &models.TrialRequestActivity{
+			TrialRequestID: tr.ID,
+			ActorID:        &actorID,
+			Kind:           models.TrialRequestActivityFollowUp,
+			Body:           body,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// AddNote добавляет заметку в журнал активности заявки
+func (s *TrialRequestService) AddNote(ctx context.Context, id int64, req *models.AddTrialRequestNoteRequest, actorID uuid.UUID) (*models.TrialRequestActivity, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	activity := &models.TrialRequestActivity{
+		ActorID: &actorID,
+		Kind:    models.TrialRequestActivityNote,
+		Body:    req.Body,
+	}
+	err := s.inTrialRequestTx(ctx, id, func(tx pgx.Tx, tr *models.TrialRequest) error {
+		activity.TrialRequestID = tr.ID
+		return s.repo.AddActivityTx(ctx, tx, activity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
+
+// ConvertTrialRequest конвертирует заявку одним действием: создает аккаунт студента,
+// бесплатное индивидуальное пробное занятие и запись студента на него,
+// а в заявке сохраняет ссылки на созданные сущности для аналитики воронки.
+// Временный пароль студента возвращается только в результате и нигде не сохраняется
+func (s *TrialRequestService) ConvertTrialRequest(ctx context.Context, id int64, req *models.ConvertTrialRequestRequest, actorID uuid.UUID) (*models.ConvertTrialRequestResult, error) {
+	current, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureTrialRequestOpen(current); err != nil {
+		return nil, err
+	}
+
+	req.ApplyDefaults(current)
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.lessonValidator.ValidateColor(req.Color); err != nil {
+		return nil, err
+	}
+	if req.Subject != nil {
+		if err := s.lessonValidator.ValidateSubject(*req.Subject); err != nil {
+			return nil, err
+		}
+	}
+
+	teacher, err := s.userRepo.GetByID(ctx, req.TeacherID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, models.ErrInvalidTeacherID
+		}
+		return nil, fmt.Errorf("failed to get teacher: %w", err)
+	}
+	if !teacher.CanBeAssignedAsTeacher() {
+		return nil, fmt.Errorf("user cannot be assigned as teacher (role: %s): %w", teacher.Role, models.ErrInvalidTeacherID)
+	}
+
+	exists, err := s.userRepo.Exists(ctx, req.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if user exists: %w", err)
+	}
+	if exists {
+		return nil, repository.ErrUserExists
+	}
+
+	password, err := generateTemporaryPassword()
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := hash.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := time.Now()
+	student := &models.User{
+		Email:          req.Email,
+		PasswordHash:   passwordHash,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		PaymentEnabled: true,
+	}
+	if handle := strings.TrimPrefix(current.Telegram, "@"); handle != "" {
+		student.TelegramUsername = sql.NullString{String: handle, Valid: true}
+	}
+
+	lesson := &models.Lesson{
+		ID:          uuid.New(),
+		TeacherID:   req.TeacherID,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		MaxStudents: 1,
+		CreditsCost: 0,
+		Color:       req.Color,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if req.Subject != nil && *req.Subject != "" {
+		lesson.Subject = sql.NullString{String: *req.Subject, Valid: true}
+	}
+	if req.Link != nil && *req.Link != "" {
+		lesson.Link = sql.NullString{String: *req.Link, Valid: true}
+	}
+
+	booking := &models.Booking{
+		ID:        uuid.New(),
+		Status:    models.BookingStatusActive,
+		BookedAt:  now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	var created *models.Lesson
+	err = s.inTrialRequestTx(ctx, id, func(tx pgx.Tx, tr *models.TrialRequest) error {
+		// Статус мог измениться между проверкой и блокировкой строки
+		if err := ensureTrialRequestOpen(tr); err != nil {
+			return err
+		}
+
+		if err := s.repo.CreateStudentTx(ctx, tx, student); err != nil {
+			return err
+		}
+
+		var err error
+		created, err = s.lessonRepo.CreateLessonTx(ctx, tx, lesson)
+		if err != nil {
+			if repository.IsExclusionViolationError(err) {
+				return repository.ErrLessonOverlapConflict
+			}
+			return err
+		}
+
+		booking.StudentID = student.ID
+		booking.LessonID = created.ID
+		if err := s.lessonRepo.CreateBookingTx(ctx, tx, booking); err != nil {
+			return err
+		}
+		if err := s.lessonRepo.IncrementStudents(ctx, tx, created.ID); err != nil {
+			return err
+		}
+		created.CurrentStudents++
+
+		if err := s.repo.MarkConvertedTx(ctx, tx, tr.ID, student.ID, created.ID, booking.ID, now); err != nil {
+			return err
+		}
+
+		from := tr.Status
+		to := models.TrialRequestStatusConverted
+		return s.repo.AddActivityTx(ctx, tx, &models.TrialRequestActivity{
+			TrialRequestID: tr.ID,
+			ActorID:        &actorID,
+			Kind:           models.TrialRequestActivityConversion,
+			Body: fmt.Sprintf("Создан студент %s, пробное занятие %s",
+				student.Email, req.StartTime.Format("02.01.2006 15:04")),
+			FromStatus: &from,
+			ToStatus:   &to,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tr, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("trial_request_id", id).
+		Str("student_id", student.ID.String()).
+		Str("lesson_id", created.ID.String()).
+		Msg("Trial request converted")
+
+	return &models.ConvertTrialRequestResult{
+		TrialRequest:      tr,
+		Student:           student,
+		Lesson:            created,
+		BookingID:         booking.ID,
+		TemporaryPassword: password,
+	}, nil
+}
+
+// StartReminderWorker запускает фоновую отправку напоминаний о наступивших сроках контакта
+func (s *TrialRequestService) StartReminderWorker() {
+	s.stopReminders = make(chan struct{})
+	s.remindersDone = make(chan struct{})
+	go s.reminderLoop()
+}
+
+// Shutdown останавливает фоновую отправку напоминаний (для graceful shutdown)
+func (s *TrialRequestService) Shutdown() {
+	if s.stopReminders == nil {
+		return
+	}
+	close(s.stopReminders)
+	<-s.remindersDone
+	log.Info().Msg("Trial request reminder worker shutdown complete")
+}
+
+// reminderLoop периодически отправляет напоминания по заявкам
+func (s *TrialRequestService) reminderLoop() {
+	ticker := time.NewTicker(trialRequestReminderInterval)
+	defer ticker.Stop()
+	defer close(s.remindersDone)
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), trialRequestReminderInterval/2)
+			if err := s.SendDueFollowUpReminders(ctx, time.Now()); err != nil {
+				log.Error().Err(err).Msg("Trial request reminder pass failed")
+			}
+			cancel()
+		case <-s.stopReminders:
+			log.Info().Msg("Trial request reminder worker shutting down")
+			return
+		}
+	}
+}
+
+// SendDueFollowUpReminders отправляет напоминания по заявкам с наступившим сроком контакта:
+// ответственному администратору, а если он не назначен или не привязал Telegram - в общий админский чат.
+// Напоминание отмечается отправленным только после успешной доставки
+func (s *TrialRequestService) SendDueFollowUpReminders(ctx context.Context, now time.Time) error {
+	if s.telegramService == nil {
+		return nil
+	}
+
+	due, err := s.repo.GetDueFollowUps(ctx, now, trialRequestReminderBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, tr := range due {
+		message := "⏰ Пора связаться по заявке на пробное занятие\n\n" + s.formatTrialRequestMessage(tr)
+
+		if err := s.deliverAdminMessage(ctx, tr.AssignedTo, message); err != nil {
+			log.Warn().Err(err).Int64("trial_request_id", tr.ID).Msg("Failed to send trial request follow-up reminder")
+			continue
+		}
+
+		if err := s.repo.MarkFollowUpReminded(ctx, tr.ID, *tr.FollowUpAt, now); err != nil {
+			log.Warn().Err(err).Int64("trial_request_id", tr.ID).Msg("Failed to mark trial request follow-up reminded")
+		}
+	}
+
+	return nil
+}
+
+// notifyAdmin отправляет уведомление администратору вне контекста запроса
+func (s *TrialRequestService) notifyAdmin(adminID uuid.UUID, message string) {
+	if s.telegramService == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.deliverAdminMessage(ctx, &adminID, message); err != nil {
+		log.Warn().Err(err).Str("admin_id", adminID.String()).Msg("Failed to notify trial request assignee")
+	}
+}
+
+// deliverAdminMessage отправляет сообщение назначенному администратору,
+// при отсутствии назначения или привязки Telegram - в общий админский чат
+func (s *TrialRequestService) deliverAdminMessage(ctx context.Context, adminID *uuid.UUID, message string) error {
+	if adminID != nil {
+		err := s.telegramService.SendUserNotification(ctx, *adminID, message)
+		if err == nil || !errors.Is(err, ErrUserNotLinked) {
+			return err
+		}
+	}
+	return s.telegramService.SendAdminNotification(ctx, message)
+}
+
+// inTrialRequestTx выполняет fn в транзакции с заблокированной строкой заявки
+func (s *TrialRequestService) inTrialRequestTx(ctx context.Context, id int64, fn func(tx pgx.Tx, tr *models.TrialRequest) error) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warn().Err(err).Msg("Failed to rollback trial request transaction")
+		}
+	}()
+
+	tr, err := s.repo.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx, tr); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ensureTrialRequestOpen проверяет, что заявка еще в работе
+func ensureTrialRequestOpen(tr *models.TrialRequest) error {
+	switch tr.Status {
+	case models.TrialRequestStatusConverted:
+		return models.ErrTrialRequestAlreadyConverted
+	case models.TrialRequestStatusLost:
+		return models.ErrTrialRequestClosed
+	default:
+		return nil
+	}
+}
+
+// generateTemporaryPassword генерирует случайный временный пароль
+func generateTemporaryPassword() (string, error) {
+	alphabetLen := big.NewInt(int64(len(temporaryPasswordAlphabet)))
+	password := make([]byte, temporaryPasswordLength)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, alphabetLen)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate temporary password: %w", err)
+		}
+		password[i] = temporaryPasswordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
@@ -8,24 +8,41 @@ import (
 	"tutoring-platform/internal/models"
 	"tutoring-platform/internal/repository"
 	"tutoring-platform/internal/validator"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // TrialRequestService обрабатывает бизнес-логику для запросов на пробные уроки
+// и CRM воронку заявок (статусы, ответственные, напоминания, конвертация в студента)
 type TrialRequestService struct {
+	pool            *pgxpool.Pool
 	repo            *repository.TrialRequestRepository
+	lessonRepo      *repository.LessonRepository
+	userRepo        repository.UserRepository
 	validator       *validator.TrialRequestValidator
+	lessonValidator *validator.LessonValidator
 	telegramService *TelegramService
+
+	stopReminders chan struct{}
+	remindersDone chan struct{}
 }
 
 // NewTrialRequestService создает новый TrialRequestService
 func NewTrialRequestService(
+	pool *pgxpool.Pool,
 	repo *repository.TrialRequestRepository,
-	validator *validator.TrialRequestValidator,
+	lessonRepo *repository.LessonRepository,
+	userRepo repository.UserRepository,
+	requestValidator *validator.TrialRequestValidator,
 	telegramService *TelegramService,
 ) *TrialRequestService {
 	return &TrialRequestService{
+		pool:            pool,
 		repo:            repo,
-		validator:       validator,
+		lessonRepo:      lessonRepo,
+		userRepo:        userRepo,
+		validator:       requestValidator,
+		lessonValidator: validator.NewLessonValidator(),
 		telegramService: telegramService,
 	}
 }
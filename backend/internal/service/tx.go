@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// txBeginner открывает транзакцию
+// Ему удовлетворяет *pgxpool.Pool; в тестах сервисов подменяется фейком
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeTx - транзакция для тестов сервисов с фейковыми репозиториями
+// Запоминает commit/rollback; остальные методы pgx.Tx не реализованы и паникуют при вызове
+type fakeTx struct {
+	pgx.Tx
+	committed  bool
+	rolledBack bool
+}
+
+func (t *fakeTx) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, nil
+}
+
+func (t *fakeTx) Commit(ctx context.Context) error {
+	if t.committed || t.rolledBack {
+		return pgx.ErrTxClosed
+	}
+	t.committed = true
+	return nil
+}
+
+func (t *fakeTx) Rollback(ctx context.Context) error {
+	if t.committed || t.rolledBack {
+		return pgx.ErrTxClosed
+	}
+	t.rolledBack = true
+	return nil
+}
+
+// fakeTxBeginner выдает fakeTx и запоминает все открытые транзакции
+type fakeTxBeginner struct {
+	txs []*fakeTx
+}
+
+func (b *fakeTxBeginner) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx := &fakeTx{}
+	b.txs = append(b.txs, tx)
+	return tx, nil
+}
+
+// last возвращает последнюю открытую транзакцию
+func (b *fakeTxBeginner) last() *fakeTx {
+	if len(b.txs) == 0 {
+		return nil
+	}
+	return b.txs[len(b.txs)-1]
+}
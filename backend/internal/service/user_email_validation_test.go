@@ -9,6 +9,7 @@ import (
 	"tutoring-platform/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -151,7 +152,7 @@ func TestUpdateUserEmailValidation(t *testing.T) {
 			}
 
 			// Выполняем обновление
-			result, err := service.UpdateUser(context.Background(), tt.userID, req)
+			result, err := service.UpdateUser(context.Background(), testAdminActor, tt.userID, req)
 
 			if tt.expectSuccess {
 				require.NoError(t, err, "unexpected error: %v", err)
@@ -209,7 +210,7 @@ func TestUpdateUserEmailUniqueness(t *testing.T) {
 		Email: &newEmail,
 	}
 
-	_, err := service.UpdateUser(ctx, user2ID, req)
+	_, err := service.UpdateUser(ctx, testAdminActor, user2ID, req)
 	assert.True(t, errors.Is(err, repository.ErrUserExists), "should return ErrUserExists, got %v", err)
 }
 
@@ -241,7 +242,7 @@ func TestUpdateUserEmailSelfUpdate(t *testing.T) {
 		Email: &sameEmail,
 	}
 
-	result, err := service.UpdateUser(ctx, userID, req)
+	result, err := service.UpdateUser(ctx, testAdminActor, userID, req)
 	assert.NoError(t, err, "should not return error")
 	assert.NotNil(t, result, "result should not be nil")
 	assert.Equal(t, sameEmail, result.Email, "email should remain the same")
@@ -275,13 +276,16 @@ func TestUpdateUserEmailWithoutChange(t *testing.T) {
 		FullName: &newName,
 	}
 
-	result, err := service.UpdateUser(ctx, userID, req)
+	result, err := service.UpdateUser(ctx, testAdminActor, userID, req)
 	assert.NoError(t, err, "should not return error")
 	assert.NotNil(t, result, "result should not be nil")
 	assert.Equal(t, "user@example.com", result.Email, "email should remain unchanged")
-	assert.Equal(t, newName, result.FullName, "full name should be updated")
+	assert.Equal(t, newName, result.GetFullName(), "full name should be updated")
 }
 
+// testAdminActor администратор, от имени которого тесты изменяют пользователей
+var testAdminActor = &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
 // MockUserRepositoryForEmail специальный мок для тестирования email валидации
 type MockUserRepositoryForEmail struct {
 	users  map[uuid.UUID]*models.User
@@ -310,6 +314,10 @@ func (m *MockUserRepositoryForEmail) Create(ctx context.Context, user *models.Us
 	return nil
 }
 
+func (m *MockUserRepositoryForEmail) CreateTx(ctx context.Context, tx pgx.Tx, user *models.User) error {
+	return m.Create(ctx, user)
+}
+
 func (m *MockUserRepositoryForEmail) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
 	user, exists := m.users[id]
 	if !exists {
@@ -324,8 +332,12 @@ func (m *MockUserRepositoryForEmail) Update(ctx context.Context, id uuid.UUID, u
 		m.emails[user.Email] = user
 	}
 
-	if newFullName, ok := updates["full_name"]; ok {
-		user.FullName = newFullName.(string)
+	if newFirstName, ok := updates["first_name"]; ok {
+		user.FirstName = newFirstName.(string)
+	}
+
+	if newLastName, ok := updates["last_name"]; ok {
+		user.LastName = newLastName.(string)
 	}
 
 	if newRole, ok := updates["role"]; ok {
@@ -405,6 +417,18 @@ func (m *MockUserRepositoryForEmail) UpdateTelegramUsername(ctx context.Context,
 	return nil
 }
 
+func (m *MockUserRepositoryForEmail) GetAllUsersWithTelegramInfo(ctx context.Context) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	for _, user := range m.users {
+		result = append(result, map[string]interface{}{
+			"id":    user.ID,
+			"email": user.Email,
+			"role":  user.Role,
+		})
+	}
+	return result, nil
+}
+
 // ptrString helper для создания указателя на строку
 func ptrString(s string) *string {
 	return &s
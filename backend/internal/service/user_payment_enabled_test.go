@@ -29,7 +29,7 @@ func TestUserService_CreateUser_PaymentEnabledDefault(t *testing.T) {
 		Role:     models.RoleStudent,
 	}
 
-	user, err := userService.CreateUser(ctx, req)
+	user, err := userService.CreateUser(ctx, testAdminActor, req)
 	require.NoError(t, err)
 	require.NotNil(t, user)
 
@@ -64,7 +64,7 @@ func TestUserService_UpdateUser_PaymentEnabled(t *testing.T) {
 		PaymentEnabled: &falseValue,
 	}
 
-	updatedUser, err := userService.UpdateUser(ctx, user.ID, updateReq)
+	updatedUser, err := userService.UpdateUser(ctx, testAdminActor, user.ID, updateReq)
 	require.NoError(t, err)
 	require.NotNil(t, updatedUser)
 
@@ -77,7 +77,7 @@ func TestUserService_UpdateUser_PaymentEnabled(t *testing.T) {
 		PaymentEnabled: &trueValue,
 	}
 
-	updatedUser2, err := userService.UpdateUser(ctx, user.ID, updateReq2)
+	updatedUser2, err := userService.UpdateUser(ctx, testAdminActor, user.ID, updateReq2)
 	require.NoError(t, err)
 	require.NotNil(t, updatedUser2)
 
@@ -105,11 +105,11 @@ func TestUserService_UpdateUser_PaymentEnabledNil(t *testing.T) {
 		// PaymentEnabled не передаём (nil)
 	}
 
-	updatedUser, err := userService.UpdateUser(ctx, user.ID, updateReq)
+	updatedUser, err := userService.UpdateUser(ctx, testAdminActor, user.ID, updateReq)
 	require.NoError(t, err)
 	require.NotNil(t, updatedUser)
 
 	// Проверяем что payment_enabled не изменился
 	assert.True(t, updatedUser.PaymentEnabled, "PaymentEnabled should remain true")
-	assert.Equal(t, newName, updatedUser.FullName, "FullName should be updated")
+	assert.Equal(t, newName, updatedUser.GetFullName(), "FullName should be updated")
 }
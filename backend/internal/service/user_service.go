@@ -11,6 +11,8 @@ import (
 	"tutoring-platform/pkg/hash"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
 )
 
 // UserService обрабатывает бизнес-логику для пользователей
@@ -18,6 +20,20 @@ type UserService struct {
 	userRepo    repository.UserRepository
 	creditRepo  *repository.CreditRepository
 	sessionRepo SessionRepositoryInterface
+	audit       *AuditService
+	permissions RoleRegistry
+}
+
+// RoleRegistry реестр ролей для UserService: существование роли и ее права (реализует PermissionService)
+type RoleRegistry interface {
+	RoleExists(ctx context.Context, role models.UserRole) (bool, error)
+	PermissionsFor(ctx context.Context, role models.UserRole) models.PermissionSet
+}
+
+// userAuditState состояние пользователя для журнала аудита (хеш пароля в JSON не попадает)
+type userAuditState struct {
+	*models.User
+	PasswordChanged bool `json:"password_changed,omitempty"`
 }
 
 // NewUserService создает новый UserService
@@ -37,10 +53,90 @@ func NewUserServiceWithSession(userRepo repository.UserRepository, creditRepo *r
 	}
 }
 
-// CreateUser создает нового пользователя и инициализирует кредиты
-func (s *UserService) CreateUser(ctx context.Context, req *models.CreateUserRequest) (*models.User, error) {
+// SetAuditService устанавливает журнал аудита для изменений пользователей
+func (s *UserService) SetAuditService(audit *AuditService) {
+	s.audit = audit
+}
+
+// SetPermissionService подключает реестр ролей, чтобы пользователям можно было назначать пользовательские роли
+func (s *UserService) SetPermissionService(permissions RoleRegistry) {
+	s.permissions = permissions
+}
+
+// roleValidator возвращает проверку роли: встроенные роли, а при подключенном PermissionService
+// и пользовательские роли из БД
+func (s *UserService) roleValidator(ctx context.Context) func(models.UserRole) bool {
+	return func(role models.UserRole) bool {
+		if s.permissions == nil {
+			return role.IsBuiltin()
+		}
+		exists, err := s.permissions.RoleExists(ctx, role)
+		if err != nil {
+			log.Error().Err(err).Str("role", string(role)).Msg("Failed to check role")
+			return false
+		}
+		return exists
+	}
+}
+
+// rolePermissions возвращает права роли; без PermissionService - права встроенных ролей
+func (s *UserService) rolePermissions(ctx context.Context, role models.UserRole) models.PermissionSet {
+	if s.permissions != nil {
+		return s.permissions.PermissionsFor(ctx, role)
+	}
+	if set, ok := models.DefaultRolePermissions[role]; ok {
+		return set
+	}
+	return models.NewPermissionSet()
+}
+
+// authorizeRoleAssignment проверяет, что actor может назначить роль: нужно право roles.manage,
+// а права роли должны быть строго меньше прав actor. Администратор может назначить любую роль
+func (s *UserService) authorizeRoleAssignment(ctx context.Context, actor *models.User, role models.UserRole) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+	actorPerms := s.rolePermissions(ctx, actor.Role)
+	if !actorPerms.Has(models.PermRolesManage) {
+		return models.ErrRoleAssignDenied
+	}
+	rolePerms := s.rolePermissions(ctx, role)
+	if !actorPerms.Contains(rolePerms) || rolePerms.Contains(actorPerms) {
+		return models.ErrRoleAssignDenied
+	}
+	return nil
+}
+
+// authorizeUserChange проверяет, что роль изменяемого пользователя не дает прав, которых нет у actor.
+// Свой профиль можно изменять всегда (смена собственной роли проверяется authorizeRoleAssignment)
+func (s *UserService) authorizeUserChange(ctx context.Context, actor, target *models.User) error {
+	if actor.IsAdmin() || actor.ID == target.ID {
+		return nil
+	}
+	if !s.rolePermissions(ctx, actor.Role).Contains(s.rolePermissions(ctx, target.Role)) {
+		return models.ErrUserOutranksActor
+	}
+	return nil
+}
+
+// ValidateCreateUser проверяет запрос на создание пользователя от имени actor.
+// Студентов может создавать любой actor с правом users.create, остальные роли - по правилам authorizeRoleAssignment
+func (s *UserService) ValidateCreateUser(ctx context.Context, actor *models.User, req *models.CreateUserRequest) error {
+	if err := req.ValidateWithRoles(s.roleValidator(ctx)); err != nil {
+		return err
+	}
+	if req.Role != models.RoleStudent {
+		if err := s.authorizeRoleAssignment(ctx, actor, req.Role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateUser создает нового пользователя и инициализирует кредиты (проверки - ValidateCreateUser)
+func (s *UserService) CreateUser(ctx context.Context, actor *models.User, req *models.CreateUserRequest) (*models.User, error) {
 	// Проверяем запрос
-	if err := req.Validate(); err != nil {
+	if err := s.ValidateCreateUser(ctx, actor, req); err != nil {
 		return nil, err
 	}
 
@@ -53,17 +149,62 @@ func (s *UserService) CreateUser(ctx context.Context, req *models.CreateUserRequ
 		return nil, repository.ErrUserExists
 	}
 
-	// Хешируем пароль
+	user, err := newUserFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	// Создаем кредиты для всех новых пользователей (триггер БД создает только для студентов)
+	// Для администраторов и учителей требуется явный вызов CreateCredit в сервисе
+	if err := s.creditRepo.CreateCredit(ctx, user.ID, 0); err != nil {
+		// Игнорируем ошибку дублирования ключа, так как кредиты могут быть уже созданы
+		// (например, если повторный запрос содержит данные о пользователе)
+		if !errors.Is(err, repository.ErrDuplicateCredit) {
+			return nil, fmt.Errorf("failed to create credit account for user: %w", err)
+		}
+	}
+
+	return user, nil
+}
+
+// CreateUserTx создает пользователя и его счет кредитов в транзакции вызывающего (массовый импорт)
+// с теми же проверками, что и CreateUser. Занятый email возвращается как repository.ErrUserExists
+func (s *UserService) CreateUserTx(ctx context.Context, tx pgx.Tx, actor *models.User, req *models.CreateUserRequest) (*models.User, error) {
+	if err := s.ValidateCreateUser(ctx, actor, req); err != nil {
+		return nil, err
+	}
+
+	user, err := newUserFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.CreateTx(ctx, tx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	if err := s.creditRepo.CreateCreditTx(ctx, tx, user.ID, 0); err != nil {
+		if !errors.Is(err, repository.ErrDuplicateCredit) {
+			return nil, fmt.Errorf("failed to create credit account for user: %w", err)
+		}
+	}
+
+	return user, nil
+}
+
+// newUserFromRequest строит пользователя по проверенному запросу: хеширует пароль
+// и разбивает FullName, если FirstName/LastName не переданы (старый API)
+func newUserFromRequest(req *models.CreateUserRequest) (*models.User, error) {
 	passwordHash, err := hash.HashPassword(req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Создаем пользователя
 	firstName := req.FirstName
 	lastName := req.LastName
-
-	// Поддержка старого API: если FirstName/LastName не переданы, используем FullName
 	if firstName == "" && lastName == "" && req.FullName != "" {
 		parts := strings.SplitN(strings.TrimSpace(req.FullName), " ", 2)
 		if len(parts) > 0 {
@@ -74,30 +215,14 @@ func (s *UserService) CreateUser(ctx context.Context, req *models.CreateUserRequ
 		}
 	}
 
-	user := &models.User{
+	return &models.User{
 		Email:          req.Email,
 		PasswordHash:   passwordHash,
 		FirstName:      firstName,
 		LastName:       lastName,
 		Role:           req.Role,
 		PaymentEnabled: true, // По умолчанию оплата включена
-	}
-
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
-
-	// Создаем кредиты для всех новых пользователей (триггер БД создает только для студентов)
-	// Для администраторов и учителей требуется явный вызов CreateCredit в сервисе
-	if err := s.creditRepo.CreateCredit(ctx, user.ID, 0); err != nil {
-		// Игнорируем ошибку дублирования ключа, так как кредиты могут быть уже созданы
-		// (например, если повторный запрос содержит данные о пользователе)
-		if !errors.Is(err, repository.ErrDuplicateCredit) {
-			return nil, fmt.Errorf("failed to create credit account for user: %w", err)
-		}
-	}
-
-	return user, nil
+	}, nil
 }
 
 // GetUser получает пользователя по ID
@@ -105,11 +230,21 @@ func (s *UserService) GetUser(ctx context.Context, userID uuid.UUID) (*models.Us
 	return s.userRepo.GetByID(ctx, userID)
 }
 
-// UpdateUser обновляет пользователя
-func (s *UserService) UpdateUser(ctx context.Context, userID uuid.UUID, req *models.UpdateUserRequest) (*models.User, error) {
+// UpdateUser обновляет пользователя от имени actor. Пользователя с большими правами, чем у actor,
+// изменить нельзя; смена роли проверяется authorizeRoleAssignment
+func (s *UserService) UpdateUser(ctx context.Context, actor *models.User, userID uuid.UUID, req *models.UpdateUserRequest) (*models.User, error) {
 	// Санитизация входных данных
 	req.Sanitize()
 
+	// Состояние до изменения нужно для проверки прав и журнала аудита
+	before, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.authorizeUserChange(ctx, actor, before); err != nil {
+		return nil, err
+	}
+
 	updates := make(map[string]interface{})
 
 	if req.Email != nil {
@@ -147,12 +282,13 @@ func (s *UserService) UpdateUser(ctx context.Context, userID uuid.UUID, req *mod
 			updates["last_name"] = parts[1]
 		}
 	}
-	if req.Role != nil {
-		if *req.Role != models.RoleStudent &&
-			*req.Role != models.RoleTeacher &&
-			*req.Role != models.RoleAdmin {
+	if req.Role != nil && *req.Role != before.Role {
+		if !s.roleValidator(ctx)(*req.Role) {
 			return nil, models.ErrInvalidRole
 		}
+		if err := s.authorizeRoleAssignment(ctx, actor, *req.Role); err != nil {
+			return nil, err
+		}
 		updates["role"] = *req.Role
 	}
 	if req.PaymentEnabled != nil {
@@ -176,11 +312,7 @@ func (s *UserService) UpdateUser(ctx context.Context, userID uuid.UUID, req *mod
 			if !isValidTelegramUsername(*req.ParentTelegramUsername) {
 				return nil, models.ErrInvalidTelegramHandle
 			}
-			currentUser, err := s.userRepo.GetByID(ctx, userID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get current user: %w", err)
-			}
-			if currentUser.Role != models.RoleStudent {
+			if before.Role != models.RoleStudent {
 				return nil, fmt.Errorf("parent_telegram_username can only be set for students")
 			}
 			updates["parent_telegram_username"] = *req.ParentTelegramUsername
@@ -218,6 +350,12 @@ func (s *UserService) UpdateUser(ctx context.Context, userID uuid.UUID, req *mod
 	if err != nil {
 		return nil, fmt.Errorf("failed to get updated user: %w", err)
 	}
+
+	if s.audit != nil {
+		passwordChanged := req.Password != nil && *req.Password != ""
+		s.audit.Record(ctx, models.AuditActionUserUpdate, models.AuditTargetUser, userID.String(),
+			userAuditState{User: before}, userAuditState{User: user, PasswordChanged: passwordChanged})
+	}
 	return user, nil
 }
 
@@ -291,13 +429,26 @@ func isValidTelegramUsername(username string) bool {
 	return true
 }
 
-// DeleteUser выполняет мягкое удаление пользователя и инвалидирует все его сессии
-func (s *UserService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+// DeleteUser выполняет мягкое удаление пользователя и инвалидирует все его сессии.
+// Пользователя с большими правами, чем у actor, удалить нельзя
+func (s *UserService) DeleteUser(ctx context.Context, actor *models.User, userID uuid.UUID) error {
+	before, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeUserChange(ctx, actor, before); err != nil {
+		return err
+	}
+
 	// Удаляем пользователя (мягкое удаление)
 	if err := s.userRepo.Delete(ctx, userID); err != nil {
 		return err
 	}
 
+	if s.audit != nil {
+		s.audit.Record(ctx, models.AuditActionUserDelete, models.AuditTargetUser, userID.String(), before, nil)
+	}
+
 	// Инвалидируем все сессии пользователя для немедленного выхода
 	// Это гарантирует что удаленный пользователь не сможет использовать существующие сессии
 	if s.sessionRepo != nil {
@@ -39,12 +39,27 @@ func NewYooKassaClient(shopID, secretKey string) *YooKassaClient {
 
 // CreatePaymentRequest представляет запрос на создание платежа в YooKassa
 type CreatePaymentRequest struct {
-	Amount         Amount       `json:"amount"`
-	Capture        bool         `json:"capture"`
-	Confirmation   Confirmation `json:"confirmation"`
-	Description    string       `json:"description"`
-	Metadata       Metadata     `json:"metadata,omitempty"`
-	IdempotencyKey string       `json:"-"` // В header, не в body
+	Amount            Amount       `json:"amount"`
+	Capture           bool         `json:"capture"`
+	Confirmation      Confirmation `json:"confirmation"`
+	Description       string       `json:"description"`
+	Metadata          Metadata     `json:"metadata,omitempty"`
+	SavePaymentMethod bool         `json:"save_payment_method,omitempty"` // Сохранить способ оплаты для автоплатежей
+	PaymentMethodID   string       `json:"payment_method_id,omitempty"`   // Автоплатеж сохранённым способом оплаты
+	IdempotencyKey    string       `json:"-"`                             // В header, не в body
+}
+
+// MarshalJSON не передаёт confirmation, если он не задан:
+// автоплатежи сохранённым способом оплаты проходят без участия пользователя
+func (r CreatePaymentRequest) MarshalJSON() ([]byte, error) {
+	type plain CreatePaymentRequest
+	if r.Confirmation.Type != "" {
+		return json.Marshal(plain(r))
+	}
+	return json.Marshal(struct {
+		plain
+		Confirmation *Confirmation `json:"confirmation,omitempty"`
+	}{plain: plain(r)})
 }
 
 // Amount представляет сумму платежа
@@ -61,17 +76,33 @@ type Confirmation struct {
 
 // Metadata представляет дополнительные данные платежа
 type Metadata struct {
-	PaymentID string `json:"payment_id,omitempty"` // ID платежа в нашей системе
+	PaymentID             string `json:"payment_id,omitempty"`              // ID платежа в нашей системе
+	SubscriptionInvoiceID string `json:"subscription_invoice_id,omitempty"` // ID счёта подписки в нашей системе
 }
 
 // YooKassaPaymentResponse представляет ответ от YooKassa при создании платежа
 type YooKassaPaymentResponse struct {
-	ID           string                   `json:"id"`
-	Status       string                   `json:"status"`
-	Paid         bool                     `json:"paid"`
-	Amount       Amount                   `json:"amount"`
-	Confirmation YooKassaConfirmationResp `json:"confirmation"`
-	Metadata     Metadata                 `json:"metadata"`
+	ID                  string                       `json:"id"`
+	Status              string                       `json:"status"`
+	Paid                bool                         `json:"paid"`
+	Amount              Amount                       `json:"amount"`
+	Confirmation        YooKassaConfirmationResp     `json:"confirmation"`
+	Metadata            Metadata                     `json:"metadata"`
+	PaymentMethod       YooKassaPaymentMethod        `json:"payment_method"`
+	CancellationDetails *YooKassaCancellationDetails `json:"cancellation_details,omitempty"`
+}
+
+// YooKassaPaymentMethod представляет способ оплаты платежа
+type YooKassaPaymentMethod struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Saved bool   `json:"saved"` // true - способ оплаты сохранён и доступен для автоплатежей
+}
+
+// YooKassaCancellationDetails представляет причину отмены платежа
+type YooKassaCancellationDetails struct {
+	Party  string `json:"party"`
+	Reason string `json:"reason"`
 }
 
 // YooKassaConfirmationResp представляет данные подтверждения в ответе
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// safeExtPattern допустимое расширение ключа: точка и до 10 латинских букв или цифр
+var safeExtPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// Blob сохраненный файл: ключ в хранилище, SHA-256 содержимого и размер
+type Blob struct {
+	Key  string
+	Hash string
+	Size int64
+}
+
+// ContentKey строит ключ объекта по хешу содержимого: "<prefix>/<первые 2 символа хеша>/<хеш><ext>".
+// Недопустимое расширение отбрасывается
+func ContentKey(prefix, hash, ext string) string {
+	ext = strings.ToLower(ext)
+	if !safeExtPattern.MatchString(ext) {
+		ext = ""
+	}
+	return path.Join(prefix, hash[:2], hash+ext)
+}
+
+// KeyGuard выполняет put под блокировкой ключа key, чтобы проверка существующего объекта и его сохранение
+// не пересекались с удалением объекта с тем же ключом. Ошибка put возвращается как есть
+type KeyGuard func(ctx context.Context, key string, put func() error) error
+
+// PutDeduplicated сохраняет содержимое r под ключом, вычисленным из SHA-256 содержимого.
+// Содержимое сначала записывается во временный файл (хеш нужен до выбора ключа);
+// если объект с таким ключом уже есть, повторная загрузка не выполняется.
+// guard (может быть nil) оборачивает проверку и сохранение объекта
+func PutDeduplicated(ctx context.Context, store BlobStore, prefix, ext string, r io.Reader, contentType string, guard KeyGuard) (*Blob, error) {
+	tmp, err := os.CreateTemp("", "blob-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	blob := &Blob{Key: ContentKey(prefix, hash, ext), Hash: hash, Size: size}
+
+	put := func() error {
+		if info, err := store.Stat(ctx, blob.Key); err == nil && info.Size == size {
+			return nil
+		} else if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind temp file: %w", err)
+		}
+		return store.Put(ctx, blob.Key, tmp, size, contentType)
+	}
+
+	if guard == nil {
+		err = put()
+	} else {
+		err = guard(ctx, blob.Key, put)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// LocalCopy возвращает путь к локальному файлу с содержимым объекта. Для LocalStore это сам файл,
+// для остальных хранилищ объект скачивается во временный файл, который удаляет cleanup
+func LocalCopy(ctx context.Context, store BlobStore, key string) (string, func(), error) {
+	if local, ok := store.(LocalPather); ok {
+		filePath, err := local.LocalPath(key)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := os.Stat(filePath); err != nil {
+			return "", nil, ErrNotFound
+		}
+		return filePath, func() {}, nil
+	}
+
+	body, _, err := store.Get(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp("", "blob-*"+path.Ext(key))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	cleanup := func() { os.Remove(tmp.Name()) }
+
+	_, err = io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to download object: %w", err)
+	}
+	return tmp.Name(), cleanup, nil
+}
@@ -0,0 +1,95 @@
+// Package storage содержит хранилища загруженных файлов (BlobStore): локальную файловую систему
+// и S3-совместимое объектное хранилище (AWS S3, MinIO, Yandex Object Storage).
+// Файлы адресуются ключами вида "<prefix>/<ab>/<sha256><ext>", поэтому одинаковое содержимое
+// хранится один раз (дедупликация по хешу содержимого)
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"tutoring-platform/internal/config"
+)
+
+var (
+	// ErrNotFound объект с таким ключом не найден
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrInvalidKey ключ пустой, абсолютный или выходит за пределы хранилища
+	ErrInvalidKey = errors.New("storage: invalid object key")
+)
+
+// ObjectInfo метаданные сохраненного объекта
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// BlobStore хранилище файлов по ключу
+type BlobStore interface {
+	// Put сохраняет объект; size - точный размер содержимого r
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект на чтение; вызывающий закрывает ReadCloser
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Stat возвращает метаданные объекта или ErrNotFound
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete удаляет объект; отсутствие объекта не считается ошибкой
+	Delete(ctx context.Context, key string) error
+}
+
+// Presigner хранилище, умеющее выдавать временные ссылки на скачивание напрямую из хранилища
+type Presigner interface {
+	PresignGet(ctx context.Context, key, fileName, contentType string, ttl time.Duration) (string, error)
+}
+
+// LocalPather хранилище, объекты которого доступны как локальные файлы
+type LocalPather interface {
+	LocalPath(key string) (string, error)
+}
+
+// Lister хранилище, умеющее перечислять объекты. prefix - начальные сегменты ключа без завершающего "/"
+// (например, "chat"); fn вызывается для каждого объекта, ее ошибка прерывает перечисление и возвращается из List
+type Lister interface {
+	List(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error
+}
+
+// ValidateKey проверяет, что ключ относительный и не содержит переходов в родительские каталоги
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || strings.ContainsRune(key, 0) {
+		return ErrInvalidKey
+	}
+	if path.Clean(key) != key {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." || part == "." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// New создает хранилище по конфигурации: LocalStore (по умолчанию) или S3Store
+func New(cfg *config.StorageConfig) (BlobStore, error) {
+	switch cfg.Backend {
+	case "", config.StorageBackendLocal:
+		return NewLocalStore(cfg.LocalDir)
+	case config.StorageBackendS3:
+		return NewS3Store(S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretKey,
+			UsePathStyle:    cfg.S3UsePathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q", cfg.Backend)
+	}
+}
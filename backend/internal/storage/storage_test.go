@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateKey(t *testing.T) {
+	valid := []string{"chat/ab/abc.pdf", "homework/00/file"}
+	for _, key := range valid {
+		if err := ValidateKey(key); err != nil {
+			t.Errorf("ValidateKey(%q) error = %v", key, err)
+		}
+	}
+
+	invalid := []string{"", "/etc/passwd", "../secret", "chat/../../secret", "chat//x", "chat/./x", "chat\\x", "chat/x/"}
+	for _, key := range invalid {
+		if err := ValidateKey(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ValidateKey(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+	testBlobStoreRoundTrip(t, store)
+
+	if err := store.Put(context.Background(), "../escape.txt", strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Put() outside root error = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestLocalStoreList(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+	testListerListsPrefix(t, store)
+}
+
+func TestPutDeduplicated(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+	ctx := context.Background()
+	content := []byte("одинаковое содержимое")
+
+	first, err := PutDeduplicated(ctx, store, "chat", ".TXT", bytes.NewReader(content), "text/plain", nil)
+	if err != nil {
+		t.Fatalf("PutDeduplicated() error = %v", err)
+	}
+	second, err := PutDeduplicated(ctx, store, "chat", ".txt", bytes.NewReader(content), "text/plain", nil)
+	if err != nil {
+		t.Fatalf("PutDeduplicated() error = %v", err)
+	}
+
+	if first.Key != second.Key || first.Hash != second.Hash {
+		t.Errorf("same content stored under different keys: %q and %q", first.Key, second.Key)
+	}
+	wantPrefix := "chat/" + first.Hash[:2] + "/" + first.Hash
+	if first.Key != wantPrefix+".txt" || first.Size != int64(len(content)) {
+		t.Errorf("PutDeduplicated() = %+v, want key %s.txt", first, wantPrefix)
+	}
+
+	other, err := PutDeduplicated(ctx, store, "chat", ".exe;rm", bytes.NewReader([]byte("другое")), "", nil)
+	if err != nil {
+		t.Fatalf("PutDeduplicated() error = %v", err)
+	}
+	if other.Key == first.Key || strings.Contains(other.Key, ".") {
+		t.Errorf("PutDeduplicated() key = %q, want different key without unsafe extension", other.Key)
+	}
+
+	path, cleanup, err := LocalCopy(ctx, store, first.Key)
+	if err != nil {
+		t.Fatalf("LocalCopy() error = %v", err)
+	}
+	defer cleanup()
+	data, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(data, content) {
+		t.Errorf("LocalCopy() content = %q, %v", data, err)
+	}
+}
+
+// TestPutDeduplicatedGuard проверяет, что проверка существующего объекта выполняется под guard:
+// объект, удаленный до получения блокировки, сохраняется заново
+func TestPutDeduplicatedGuard(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+	ctx := context.Background()
+	content := []byte("общий объект")
+
+	first, err := PutDeduplicated(ctx, store, "chat", ".txt", bytes.NewReader(content), "text/plain", nil)
+	if err != nil {
+		t.Fatalf("PutDeduplicated() error = %v", err)
+	}
+
+	var guarded []string
+	guard := func(ctx context.Context, key string, put func() error) error {
+		guarded = append(guarded, key)
+		// Параллельный Release успел удалить объект до блокировки
+		if err := store.Delete(ctx, key); err != nil {
+			return err
+		}
+		return put()
+	}
+	second, err := PutDeduplicated(ctx, store, "chat", ".txt", bytes.NewReader(content), "text/plain", guard)
+	if err != nil {
+		t.Fatalf("PutDeduplicated(guard) error = %v", err)
+	}
+	if len(guarded) != 1 || guarded[0] != first.Key || second.Key != first.Key {
+		t.Fatalf("guard keys = %v, want [%s]", guarded, first.Key)
+	}
+	if _, err := store.Stat(ctx, first.Key); err != nil {
+		t.Errorf("object deleted before the guard was not stored again: %v", err)
+	}
+
+	errLocked := errors.New("lock failed")
+	failing := func(ctx context.Context, key string, put func() error) error { return errLocked }
+	if _, err := PutDeduplicated(ctx, store, "chat", ".txt", bytes.NewReader(content), "text/plain", failing); !errors.Is(err, errLocked) {
+		t.Errorf("PutDeduplicated(failing guard) error = %v, want %v", err, errLocked)
+	}
+}
@@ -0,0 +1,250 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// MaxImagePixels ограничение на размер изображения при перекодировании (защита от "декомпрессионных бомб")
+const MaxImagePixels = 50_000_000
+
+// jpegQuality качество JPEG при перекодировании
+const jpegQuality = 90
+
+var (
+	// ErrInvalidImage изображение не удалось разобрать
+	ErrInvalidImage = errors.New("upload: invalid image")
+	// ErrImageTooLarge изображение превышает MaxImagePixels
+	ErrImageTooLarge = errors.New("upload: image dimensions too large")
+)
+
+// IsSanitizableImage проверяет, очищает ли SanitizeImage изображения данного типа
+func IsSanitizableImage(mimeType string) bool {
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// SanitizeImage записывает в dst изображение без метаданных (EXIF, XMP, комментарии).
+// JPEG, PNG и GIF перекодируются целиком; ориентация из EXIF применяется к пикселям JPEG,
+// чтобы фото не "перевернулось" после удаления EXIF. Для WebP кодировщика в стандартной библиотеке нет,
+// поэтому из контейнера RIFF удаляются блоки EXIF и XMP
+func SanitizeImage(dst io.Writer, src io.Reader, mimeType string) error {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	switch mimeType {
+	case "image/jpeg":
+		return sanitizeJPEG(dst, data)
+	case "image/png":
+		return sanitizePNG(dst, data)
+	case "image/gif":
+		return sanitizeGIF(dst, data)
+	case "image/webp":
+		return sanitizeWebP(dst, data)
+	default:
+		return fmt.Errorf("upload: unsupported image type %q", mimeType)
+	}
+}
+
+// checkDimensions проверяет размер изображения до полного декодирования
+func checkDimensions(cfg image.Config, err error) error {
+	if err != nil {
+		return ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return ErrInvalidImage
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxImagePixels {
+		return ErrImageTooLarge
+	}
+	return nil
+}
+
+func sanitizeJPEG(dst io.Writer, data []byte) error {
+	if err := checkDimensions(jpeg.DecodeConfig(bytes.NewReader(data))); err != nil {
+		return err
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ErrInvalidImage
+	}
+	return jpeg.Encode(dst, applyOrientation(img, jpegOrientation(data)), &jpeg.Options{Quality: jpegQuality})
+}
+
+func sanitizePNG(dst io.Writer, data []byte) error {
+	if err := checkDimensions(png.DecodeConfig(bytes.NewReader(data))); err != nil {
+		return err
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ErrInvalidImage
+	}
+	return png.Encode(dst, img)
+}
+
+func sanitizeGIF(dst io.Writer, data []byte) error {
+	if err := checkDimensions(gif.DecodeConfig(bytes.NewReader(data))); err != nil {
+		return err
+	}
+	anim, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return ErrInvalidImage
+	}
+	return gif.EncodeAll(dst, anim)
+}
+
+// sanitizeWebP удаляет блоки EXIF и XMP из контейнера RIFF и снимает соответствующие флаги в VP8X
+func sanitizeWebP(dst io.Writer, data []byte) error {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return ErrInvalidImage
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	for offset := 12; offset < len(data); {
+		if offset+8 > len(data) {
+			return ErrInvalidImage
+		}
+		fourCC := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		end := offset + 8 + size + size%2 // блоки выравниваются до четного размера
+		if size < 0 || end > len(data) {
+			return ErrInvalidImage
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+			// Метаданные не копируются
+		case "VP8X":
+			chunk := append([]byte(nil), data[offset:end]...)
+			if size > 0 {
+				chunk[8] &^= 0x08 | 0x04 // флаги наличия EXIF и XMP
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[offset:end]...)
+		}
+		offset = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	_, err := dst.Write(out)
+	return err
+}
+
+// jpegOrientation возвращает значение тега Orientation (1-8) из сегмента APP1 Exif; 1, если тега нет
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for offset := 2; offset+4 <= len(data); {
+		if data[offset] != 0xFF {
+			return 1
+		}
+		marker := data[offset+1]
+		if marker == 0xDA || marker == 0xD9 { // начало данных изображения или конец файла
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+		if length < 2 || offset+2+length > len(data) {
+			return 1
+		}
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		offset += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation ищет тег Orientation (0x0112) в IFD0 заголовка TIFF
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation поворачивает и отражает изображение согласно тегу EXIF Orientation
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	w, h := bounds.Dx(), bounds.Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // отражение по горизонтали
+				sx, sy = w-1-x, y
+			case 3: // поворот на 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // отражение по вертикали
+				sx, sy = x, h-1-y
+			case 5: // транспонирование
+				sx, sy = y, x
+			case 6: // поворот на 90° по часовой
+				sx, sy = y, h-1-x
+			case 7: // отражение относительно побочной диагонали
+				sx, sy = w-1-y, h-1-x
+			case 8: // поворот на 90° против часовой
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
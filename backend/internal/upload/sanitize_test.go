@@ -0,0 +1,172 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifSegment строит сегмент APP1 с одним тегом Orientation (порядок байт Motorola)
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2A\x00\x00\x00\x08")
+	tiff = append(tiff, 0x00, 0x01)             // одна запись IFD0
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03) // Orientation, SHORT
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x01) // count
+	tiff = append(tiff, byte(orientation>>8), byte(orientation), 0x00, 0x00)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00) // следующего IFD нет
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// jpegWithOrientation кодирует изображение 4x2 (левая половина красная) и вставляет EXIF после SOI
+func jpegWithOrientation(t *testing.T, orientation uint16) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			c := color.RGBA{0, 0, 255, 255}
+			if x < 2 {
+				c = color.RGBA{255, 0, 0, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	encoded := buf.Bytes()
+	return append(append(append([]byte(nil), encoded[:2]...), exifSegment(orientation)...), encoded[2:]...)
+}
+
+func TestSanitizeJPEG(t *testing.T) {
+	data := jpegWithOrientation(t, 6)
+	if got := jpegOrientation(data); got != 6 {
+		t.Fatalf("jpegOrientation() = %d, want 6", got)
+	}
+
+	var out bytes.Buffer
+	if err := SanitizeImage(&out, bytes.NewReader(data), "image/jpeg"); err != nil {
+		t.Fatalf("SanitizeImage() error = %v", err)
+	}
+	if bytes.Contains(out.Bytes(), []byte("Exif\x00\x00")) {
+		t.Error("sanitized JPEG still contains EXIF")
+	}
+
+	img, err := jpeg.Decode(&out)
+	if err != nil {
+		t.Fatalf("jpeg.Decode() error = %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 2 || b.Dy() != 4 {
+		t.Fatalf("sanitized size = %dx%d, want 2x4 (rotated)", b.Dx(), b.Dy())
+	}
+	// После поворота на 90° по часовой левая (красная) половина оказывается сверху
+	if r, _, b, _ := img.At(0, 0).RGBA(); r < b {
+		t.Errorf("top-left pixel is not red after rotation")
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// Пиксели 3x2 пронумерованы значением красного канала: 0 1 2 / 3 4 5
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		src.Set(i%3, i/3, color.RGBA{uint8(i), 0, 0, 255})
+	}
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{1, [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+		{2, [][]uint8{{2, 1, 0}, {5, 4, 3}}},
+		{3, [][]uint8{{5, 4, 3}, {2, 1, 0}}},
+		{4, [][]uint8{{3, 4, 5}, {0, 1, 2}}},
+		{5, [][]uint8{{0, 3}, {1, 4}, {2, 5}}},
+		{6, [][]uint8{{3, 0}, {4, 1}, {5, 2}}},
+		{7, [][]uint8{{5, 2}, {4, 1}, {3, 0}}},
+		{8, [][]uint8{{2, 5}, {1, 4}, {0, 3}}},
+	}
+
+	for _, tt := range tests {
+		got := applyOrientation(src, tt.orientation)
+		for y, row := range tt.want {
+			for x, want := range row {
+				if r, _, _, _ := got.At(x, y).RGBA(); uint8(r>>8) != want {
+					t.Errorf("orientation %d: pixel (%d,%d) = %d, want %d", tt.orientation, x, y, r>>8, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSanitizePNGTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	data := buf.Bytes()
+
+	// Подменяем размеры в IHDR (сигнатура 8 байт + длина и тип блока 8 байт) и пересчитываем CRC
+	binary.BigEndian.PutUint32(data[16:20], 100000)
+	binary.BigEndian.PutUint32(data[20:24], 100000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	err := SanitizeImage(&bytes.Buffer{}, bytes.NewReader(data), "image/png")
+	if !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("SanitizeImage() error = %v, want ErrImageTooLarge", err)
+	}
+}
+
+func TestSanitizeInvalidImage(t *testing.T) {
+	for _, mimeType := range []string{"image/jpeg", "image/png", "image/gif", "image/webp"} {
+		err := SanitizeImage(&bytes.Buffer{}, bytes.NewReader([]byte("not an image")), mimeType)
+		if !errors.Is(err, ErrInvalidImage) {
+			t.Errorf("SanitizeImage(%s) error = %v, want ErrInvalidImage", mimeType, err)
+		}
+	}
+}
+
+func webpChunk(fourCC string, data []byte) []byte {
+	chunk := append([]byte(fourCC), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func TestSanitizeWebP(t *testing.T) {
+	body := []byte("WEBP")
+	body = append(body, webpChunk("VP8X", []byte{0x08 | 0x04 | 0x10, 0, 0, 0, 0, 0, 0, 0, 0, 0})...)
+	body = append(body, webpChunk("VP8L", []byte{0x2F, 0, 0, 0, 0})...)
+	body = append(body, webpChunk("EXIF", []byte("Exif secret GPS"))...)
+	body = append(body, webpChunk("XMP ", []byte("<x:xmpmeta/>"))...)
+	data := append([]byte("RIFF\x00\x00\x00\x00"), body...)
+	binary.LittleEndian.PutUint32(data[4:8], uint32(len(body)))
+
+	var out bytes.Buffer
+	if err := SanitizeImage(&out, bytes.NewReader(data), "image/webp"); err != nil {
+		t.Fatalf("SanitizeImage() error = %v", err)
+	}
+	result := out.Bytes()
+
+	if bytes.Contains(result, []byte("EXIF")) || bytes.Contains(result, []byte("XMP ")) {
+		t.Error("sanitized WebP still contains metadata chunks")
+	}
+	if got := binary.LittleEndian.Uint32(result[4:8]); int(got) != len(result)-8 {
+		t.Errorf("RIFF size = %d, want %d", got, len(result)-8)
+	}
+	if flags := result[20]; flags != 0x10 {
+		t.Errorf("VP8X flags = %#x, want 0x10 (alpha only)", flags)
+	}
+}
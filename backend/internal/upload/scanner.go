@@ -0,0 +1,195 @@
+package upload
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize размер блока данных в команде INSTREAM
+const clamdChunkSize = 32 * 1024
+
+// eicarSignature тестовая строка EICAR, которую распознает любой антивирус
+const eicarSignature = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// ScanResult результат антивирусной проверки файла
+type ScanResult struct {
+	Infected  bool
+	Signature string // Название найденной сигнатуры (только при Infected)
+}
+
+// Scanner проверяет содержимое файла на вредоносный код
+type Scanner interface {
+	// Name возвращает название сканера для журнала и записи о карантине
+	Name() string
+	// Scan читает r до конца; ошибка означает, что проверку выполнить не удалось
+	Scan(ctx context.Context, r io.Reader) (*ScanResult, error)
+}
+
+// ClamdScanner проверяет файлы через демон ClamAV (clamd) командой INSTREAM
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner создает ClamdScanner по адресу вида "tcp://host:3310", "unix:///run/clamav/clamd.ctl"
+// или "host:3310". timeout ограничивает проверку одного файла
+func NewClamdScanner(address string, timeout time.Duration) (*ClamdScanner, error) {
+	network, addr := "tcp", address
+	if strings.Contains(address, "://") {
+		parsed, err := url.Parse(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid clamd address: %w", err)
+		}
+		switch parsed.Scheme {
+		case "tcp":
+			addr = parsed.Host
+		case "unix":
+			network, addr = "unix", parsed.Path
+		default:
+			return nil, fmt.Errorf("unsupported clamd address scheme %q", parsed.Scheme)
+		}
+	}
+	if addr == "" {
+		return nil, errors.New("clamd address is empty")
+	}
+	return &ClamdScanner{network: network, address: addr, timeout: timeout}, nil
+}
+
+// Name возвращает название сканера
+func (s *ClamdScanner) Name() string {
+	return "clamd"
+}
+
+// Scan передает содержимое в clamd блоками INSTREAM и разбирает ответ "stream: OK" / "stream: <сигнатура> FOUND"
+func (s *ClamdScanner) Scan(ctx context.Context, r io.Reader) (*ScanResult, error) {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("clamd: failed to send command: %w", err)
+	}
+
+	buf := make([]byte, 4+clamdChunkSize)
+	for {
+		n, readErr := r.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				return nil, fmt.Errorf("clamd: failed to send data: %w", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("clamd: failed to read upload: %w", readErr)
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, fmt.Errorf("clamd: failed to finish stream: %w", err)
+	}
+
+	reply, err := readClamdReply(conn)
+	if err != nil {
+		return nil, err
+	}
+	return parseClamdReply(reply)
+}
+
+// Ping проверяет доступность clamd командой PING
+func (s *ClamdScanner) Ping(ctx context.Context) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("zPING\x00")); err != nil {
+		return fmt.Errorf("clamd: failed to send command: %w", err)
+	}
+	reply, err := readClamdReply(conn)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("clamd: unexpected ping reply %q", reply)
+	}
+	return nil
+}
+
+// dial открывает соединение с clamd и выставляет на него общий дедлайн проверки
+func (s *ClamdScanner) dial(ctx context.Context) (net.Conn, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, fmt.Errorf("clamd: failed to connect: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("clamd: failed to set deadline: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// readClamdReply читает ответ clamd до завершающего нулевого байта (команды с префиксом "z")
+func readClamdReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return "", fmt.Errorf("clamd: failed to read reply: %w", err)
+	}
+	return strings.TrimSpace(strings.TrimRight(reply, "\x00")), nil
+}
+
+// parseClamdReply разбирает ответ на INSTREAM
+func parseClamdReply(reply string) (*ScanResult, error) {
+	body := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case body == "OK":
+		return &ScanResult{}, nil
+	case strings.HasSuffix(body, " FOUND"):
+		return &ScanResult{Infected: true, Signature: strings.TrimSuffix(body, " FOUND")}, nil
+	default:
+		return nil, fmt.Errorf("clamd: scan error: %s", reply)
+	}
+}
+
+// StubScanner находит только тестовую строку EICAR. Используется в тестах и при локальной разработке без clamd
+type StubScanner struct{}
+
+// Name возвращает название сканера
+func (StubScanner) Name() string {
+	return "stub"
+}
+
+// Scan ищет строку EICAR в содержимом
+func (StubScanner) Scan(ctx context.Context, r io.Reader) (*ScanResult, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(content, []byte(eicarSignature)) {
+		return &ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+	}
+	return &ScanResult{}, nil
+}
@@ -0,0 +1,168 @@
+package upload
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd принимает одно соединение, собирает данные INSTREAM и отвечает reply(содержимое)
+func fakeClamd(t *testing.T, reply func(command string, content []byte) string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		command, err := reader.ReadString(0)
+		if err != nil {
+			return
+		}
+		command = strings.TrimRight(command, "\x00")
+
+		var content bytes.Buffer
+		if command == "zINSTREAM" {
+			for {
+				var size uint32
+				if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+					return
+				}
+				if size == 0 {
+					break
+				}
+				if _, err := io.CopyN(&content, reader, int64(size)); err != nil {
+					return
+				}
+			}
+		}
+		conn.Write([]byte(reply(command, content.Bytes()) + "\x00"))
+	}()
+
+	return "tcp://" + ln.Addr().String()
+}
+
+func TestClamdScanner(t *testing.T) {
+	reply := func(command string, content []byte) string {
+		if bytes.Contains(content, []byte(eicarSignature)) {
+			return "stream: Win.Test.EICAR_HDB-1 FOUND"
+		}
+		return "stream: OK"
+	}
+
+	t.Run("clean", func(t *testing.T) {
+		scanner, err := NewClamdScanner(fakeClamd(t, reply), 5*time.Second)
+		if err != nil {
+			t.Fatalf("NewClamdScanner() error = %v", err)
+		}
+		// Больше одного блока INSTREAM
+		result, err := scanner.Scan(context.Background(), bytes.NewReader(bytes.Repeat([]byte("a"), 3*clamdChunkSize+17)))
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if result.Infected {
+			t.Errorf("Scan() Infected = true, want false")
+		}
+	})
+
+	t.Run("infected", func(t *testing.T) {
+		scanner, err := NewClamdScanner(fakeClamd(t, reply), 5*time.Second)
+		if err != nil {
+			t.Fatalf("NewClamdScanner() error = %v", err)
+		}
+		result, err := scanner.Scan(context.Background(), strings.NewReader(eicarSignature))
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if !result.Infected || result.Signature != "Win.Test.EICAR_HDB-1" {
+			t.Errorf("Scan() = %+v, want infected with Win.Test.EICAR_HDB-1", result)
+		}
+	})
+
+	t.Run("error reply", func(t *testing.T) {
+		addr := fakeClamd(t, func(string, []byte) string { return "INSTREAM size limit exceeded. ERROR" })
+		scanner, err := NewClamdScanner(addr, 5*time.Second)
+		if err != nil {
+			t.Fatalf("NewClamdScanner() error = %v", err)
+		}
+		if _, err := scanner.Scan(context.Background(), strings.NewReader("data")); err == nil {
+			t.Error("Scan() error = nil, want error")
+		}
+	})
+
+	t.Run("ping", func(t *testing.T) {
+		addr := fakeClamd(t, func(command string, _ []byte) string {
+			if command == "zPING" {
+				return "PONG"
+			}
+			return "UNKNOWN COMMAND"
+		})
+		scanner, err := NewClamdScanner(addr, 5*time.Second)
+		if err != nil {
+			t.Fatalf("NewClamdScanner() error = %v", err)
+		}
+		if err := scanner.Ping(context.Background()); err != nil {
+			t.Errorf("Ping() error = %v", err)
+		}
+	})
+}
+
+func TestNewClamdScannerAddress(t *testing.T) {
+	tests := []struct {
+		address     string
+		wantNetwork string
+		wantAddr    string
+		wantErr     bool
+	}{
+		{"tcp://clamav:3310", "tcp", "clamav:3310", false},
+		{"clamav:3310", "tcp", "clamav:3310", false},
+		{"unix:///run/clamav/clamd.ctl", "unix", "/run/clamav/clamd.ctl", false},
+		{"http://clamav:3310", "", "", true},
+		{"", "", "", true},
+	}
+
+	for _, tt := range tests {
+		scanner, err := NewClamdScanner(tt.address, time.Second)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NewClamdScanner(%q) error = nil, want error", tt.address)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewClamdScanner(%q) error = %v", tt.address, err)
+			continue
+		}
+		if scanner.network != tt.wantNetwork || scanner.address != tt.wantAddr {
+			t.Errorf("NewClamdScanner(%q) = %s %s, want %s %s", tt.address, scanner.network, scanner.address, tt.wantNetwork, tt.wantAddr)
+		}
+	}
+}
+
+func TestStubScanner(t *testing.T) {
+	var scanner Scanner = StubScanner{}
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader("prefix "+eicarSignature))
+	if err != nil || !result.Infected {
+		t.Errorf("Scan(EICAR) = %+v, %v; want infected", result, err)
+	}
+
+	result, err = scanner.Scan(context.Background(), strings.NewReader("harmless"))
+	if err != nil || result.Infected {
+		t.Errorf("Scan(clean) = %+v, %v; want clean", result, err)
+	}
+}
@@ -0,0 +1,176 @@
+// Package upload проверяет загружаемые файлы до сохранения: определяет тип по содержимому,
+// сверяет его с заявленным типом и расширением, проверяет антивирусом и очищает изображения от метаданных.
+// Для изображений и PDF строит превью
+package upload
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// sniffLen сколько первых байт файла используется для определения типа
+const sniffLen = 512
+
+// ErrTypeMismatch содержимое файла не соответствует заявленному типу или расширению
+var ErrTypeMismatch = errors.New("upload: content does not match declared type")
+
+// Типы, которые определяются по содержимому сверх net/http.DetectContentType
+const (
+	mimeOLEStorage = "application/x-ole-storage" // контейнер OLE2: .doc, .xls, .ppt
+	mimeDOCX       = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	mimeXLSX       = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	mimePPTX       = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+)
+
+// declaredAliases приводит заявленные клиентом типы к типу, который возвращает DetectContentType
+var declaredAliases = map[string]string{
+	"image/jpg":                     "image/jpeg",
+	"image/pjpeg":                   "image/jpeg",
+	"application/msword":            mimeOLEStorage,
+	"application/vnd.ms-excel":      mimeOLEStorage,
+	"application/vnd.ms-powerpoint": mimeOLEStorage,
+	"video/quicktime":               "video/mp4",
+	"audio/wave":                    "audio/wav",
+	"audio/x-wav":                   "audio/wav",
+	"audio/vnd.wave":                "audio/wav",
+	"application/ogg":               "audio/ogg",
+	"application/x-zip-compressed":  "application/zip",
+	"application/x-rar":             "application/x-rar-compressed",
+	"application/vnd.rar":           "application/x-rar-compressed",
+}
+
+// typeExtensions допустимые расширения имени файла для каждого определенного типа
+var typeExtensions = map[string][]string{
+	"image/jpeg":                   {".jpg", ".jpeg", ".jpe", ".jfif"},
+	"image/png":                    {".png"},
+	"image/gif":                    {".gif"},
+	"image/webp":                   {".webp"},
+	"application/pdf":              {".pdf"},
+	mimeOLEStorage:                 {".doc", ".xls", ".ppt"},
+	mimeDOCX:                       {".docx"},
+	mimeXLSX:                       {".xlsx"},
+	mimePPTX:                       {".pptx"},
+	"video/mp4":                    {".mp4", ".m4v", ".mov"},
+	"video/mpeg":                   {".mpeg", ".mpg"},
+	"video/webm":                   {".webm"},
+	"audio/mpeg":                   {".mp3"},
+	"audio/wav":                    {".wav"},
+	"audio/ogg":                    {".ogg", ".oga"},
+	"application/zip":              {".zip"},
+	"application/x-rar-compressed": {".rar"},
+	"application/x-7z-compressed":  {".7z"},
+}
+
+// dangerousExtensions расширения, которые браузер или ОС может исполнить; запрещены при любом содержимом
+var dangerousExtensions = map[string]bool{
+	".html": true, ".htm": true, ".xhtml": true, ".svg": true, ".js": true, ".mjs": true,
+	".exe": true, ".dll": true, ".msi": true, ".bat": true, ".cmd": true, ".com": true,
+	".sh": true, ".ps1": true, ".vbs": true, ".jar": true, ".php": true, ".scr": true,
+}
+
+// DetectContentType определяет MIME тип по содержимому файла.
+// Дополняет net/http.DetectContentType сигнатурами офисных форматов, 7z, MPEG и контейнера ISO BMFF (mp4/mov),
+// а zip-архивы OOXML различает по структуре (word/, xl/, ppt/). Параметры типа (charset) отбрасываются
+func DetectContentType(r io.ReaderAt, size int64) string {
+	head := make([]byte, sniffLen)
+	n, _ := r.ReadAt(head, 0)
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")):
+		return mimeOLEStorage
+	case bytes.HasPrefix(head, []byte("7z\xBC\xAF\x27\x1C")):
+		return "application/x-7z-compressed"
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		return "video/mp4"
+	case bytes.HasPrefix(head, []byte("\x00\x00\x01\xBA")), bytes.HasPrefix(head, []byte("\x00\x00\x01\xB3")):
+		return "video/mpeg"
+	}
+
+	detected, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+
+	switch detected {
+	case "audio/wave":
+		return "audio/wav"
+	case "application/ogg":
+		return "audio/ogg"
+	case "application/zip":
+		return detectZipContent(r, size)
+	case "application/octet-stream":
+		// MP3 без тега ID3 начинается сразу с синхрослова кадра
+		if len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0 {
+			return "audio/mpeg"
+		}
+	}
+	return detected
+}
+
+// detectZipContent отличает документы OOXML от обычного zip-архива по каталогам внутри архива
+func detectZipContent(r io.ReaderAt, size int64) string {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return "application/zip"
+	}
+	for _, file := range archive.File {
+		switch {
+		case strings.HasPrefix(file.Name, "word/"):
+			return mimeDOCX
+		case strings.HasPrefix(file.Name, "xl/"):
+			return mimeXLSX
+		case strings.HasPrefix(file.Name, "ppt/"):
+			return mimePPTX
+		}
+	}
+	return "application/zip"
+}
+
+// VerifyType сверяет тип, определенный по содержимому, с заявленным клиентом типом и расширением имени файла.
+// Документ OOXML может быть заявлен как zip; для text/plain допустимо любое безопасное расширение
+func VerifyType(detected, declared, fileName string) error {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if dangerousExtensions[ext] {
+		return ErrTypeMismatch
+	}
+
+	declared = normalizeDeclared(declared)
+	if declared != detected && !(declared == "application/zip" && isOOXML(detected)) {
+		return ErrTypeMismatch
+	}
+
+	allowed, known := typeExtensions[detected]
+	if !known || ext == "" {
+		return nil
+	}
+	for _, candidate := range allowed {
+		if ext == candidate {
+			return nil
+		}
+	}
+	return ErrTypeMismatch
+}
+
+// normalizeDeclared отбрасывает параметры заявленного типа и приводит синонимы к единому виду
+func normalizeDeclared(declared string) string {
+	if parsed, _, err := mime.ParseMediaType(declared); err == nil {
+		declared = parsed
+	}
+	declared = strings.ToLower(declared)
+	if alias, ok := declaredAliases[declared]; ok {
+		return alias
+	}
+	return declared
+}
+
+// isOOXML проверяет, является ли тип документом Office Open XML
+func isOOXML(mimeType string) bool {
+	return mimeType == mimeDOCX || mimeType == mimeXLSX || mimeType == mimePPTX
+}
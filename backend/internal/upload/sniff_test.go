@@ -0,0 +1,95 @@
+package upload
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func zipWith(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip Create() error = %v", err)
+		}
+		w.Write([]byte("<xml/>"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "image/png"},
+		{"jpeg", []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF\x00"), "image/jpeg"},
+		{"pdf", []byte("%PDF-1.7\n"), "application/pdf"},
+		{"text", []byte("hello, world\n"), "text/plain"},
+		{"html", []byte("<html><script>alert(1)</script></html>"), "text/html"},
+		{"ole2", []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1\x00\x00"), mimeOLEStorage},
+		{"7z", []byte("7z\xBC\xAF\x27\x1C\x00\x04"), "application/x-7z-compressed"},
+		{"quicktime", []byte("\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00"), "video/mp4"},
+		{"mpeg", []byte("\x00\x00\x01\xBA\x44\x00"), "video/mpeg"},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), "audio/wav"},
+		{"mp3 id3", []byte("ID3\x03\x00\x00\x00\x00\x00\x00"), "audio/mpeg"},
+		{"mp3 frame", []byte("\xFF\xFB\x90\x64\x00\x00"), "audio/mpeg"},
+		{"docx", zipWith(t, "[Content_Types].xml", "word/document.xml"), mimeDOCX},
+		{"xlsx", zipWith(t, "[Content_Types].xml", "xl/workbook.xml"), mimeXLSX},
+		{"pptx", zipWith(t, "[Content_Types].xml", "ppt/presentation.xml"), mimePPTX},
+		{"zip", zipWith(t, "notes.txt"), "application/zip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectContentType(bytes.NewReader(tt.content), int64(len(tt.content)))
+			if got != tt.want {
+				t.Errorf("DetectContentType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyType(t *testing.T) {
+	tests := []struct {
+		name     string
+		detected string
+		declared string
+		fileName string
+		wantErr  bool
+	}{
+		{"jpeg", "image/jpeg", "image/jpeg", "photo.JPG", false},
+		{"jpg alias", "image/jpeg", "image/jpg", "photo.jpeg", false},
+		{"no extension", "image/png", "image/png", "screenshot", false},
+		{"doc", mimeOLEStorage, "application/msword", "essay.doc", false},
+		{"docx as zip", mimeDOCX, "application/zip", "essay.docx", false},
+		{"mov", "video/mp4", "video/quicktime", "clip.mov", false},
+		{"text with charset", "text/plain", "text/plain; charset=utf-8", "notes.md", false},
+		{"html as text", "text/html", "text/plain", "notes.txt", true},
+		{"exe as pdf", "application/octet-stream", "application/pdf", "report.pdf", true},
+		{"png as jpeg", "image/png", "image/jpeg", "photo.jpg", true},
+		{"wrong extension", "application/pdf", "application/pdf", "report.png", true},
+		{"dangerous extension", "text/plain", "text/plain", "page.html", true},
+		{"zip as docx", "application/zip", mimeDOCX, "essay.docx", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyType(tt.detected, tt.declared, tt.fileName)
+			if tt.wantErr && !errors.Is(err, ErrTypeMismatch) {
+				t.Errorf("VerifyType() error = %v, want ErrTypeMismatch", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("VerifyType() error = %v, want nil", err)
+			}
+		})
+	}
+}
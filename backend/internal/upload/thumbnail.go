@@ -0,0 +1,210 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ThumbnailMaxSize наибольшая сторона превью в пикселях
+	ThumbnailMaxSize = 320
+	// ThumbnailMimeType формат превью
+	ThumbnailMimeType = "image/jpeg"
+	// thumbnailQuality качество JPEG превью
+	thumbnailQuality = 80
+)
+
+// ErrThumbnailUnsupported для файлов этого типа превью не строится
+var ErrThumbnailUnsupported = errors.New("upload: thumbnail not supported for this type")
+
+// PDFRenderer отрисовывает первую страницу PDF; наибольшая сторона результата не превышает maxSize
+type PDFRenderer interface {
+	RenderFirstPage(ctx context.Context, pdfPath string, maxSize int) (image.Image, error)
+}
+
+// PdftoppmRenderer отрисовывает PDF утилитой pdftoppm из poppler-utils
+type PdftoppmRenderer struct {
+	binary string
+}
+
+// NewPdftoppmRenderer находит pdftoppm в PATH; ошибка - утилита не установлена
+func NewPdftoppmRenderer() (*PdftoppmRenderer, error) {
+	binary, err := exec.LookPath("pdftoppm")
+	if err != nil {
+		return nil, fmt.Errorf("pdftoppm not found: %w", err)
+	}
+	return &PdftoppmRenderer{binary: binary}, nil
+}
+
+// RenderFirstPage отрисовывает первую страницу в PNG во временном каталоге и декодирует его
+func (r *PdftoppmRenderer) RenderFirstPage(ctx context.Context, pdfPath string, maxSize int) (image.Image, error) {
+	dir, err := os.MkdirTemp("", "pdf-preview-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	outPrefix := filepath.Join(dir, "page")
+	cmd := exec.CommandContext(ctx, r.binary,
+		"-f", "1", "-l", "1", "-singlefile", "-png",
+		"-scale-to", strconv.Itoa(maxSize),
+		pdfPath, outPrefix,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("pdftoppm failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	f, err := os.Open(outPrefix + ".png")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rendered page: %w", err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode rendered page: %w", err)
+	}
+	return img, nil
+}
+
+// Thumbnailer строит JPEG-превью изображений и первой страницы PDF
+type Thumbnailer struct {
+	pdf PDFRenderer
+}
+
+// NewThumbnailer создает Thumbnailer. pdf может быть nil - тогда превью PDF не строятся
+func NewThumbnailer(pdf PDFRenderer) *Thumbnailer {
+	return &Thumbnailer{pdf: pdf}
+}
+
+// Supports проверяет, строится ли превью для файлов данного типа.
+// WebP не поддерживается: декодера в стандартной библиотеке нет
+func (t *Thumbnailer) Supports(mimeType string) bool {
+	switch normalizeDeclared(mimeType) {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	case "application/pdf":
+		return t.pdf != nil
+	}
+	return false
+}
+
+// Generate записывает в dst превью файла srcPath
+func (t *Thumbnailer) Generate(ctx context.Context, dst io.Writer, srcPath, mimeType string) error {
+	if !t.Supports(mimeType) {
+		return ErrThumbnailUnsupported
+	}
+
+	var img image.Image
+	if normalizeDeclared(mimeType) == "application/pdf" {
+		page, err := t.pdf.RenderFirstPage(ctx, srcPath, ThumbnailMaxSize)
+		if err != nil {
+			return err
+		}
+		img = page
+	} else {
+		decoded, err := decodeImageFile(srcPath, normalizeDeclared(mimeType))
+		if err != nil {
+			return err
+		}
+		img = decoded
+	}
+
+	return jpeg.Encode(dst, resizeToFit(img, ThumbnailMaxSize), &jpeg.Options{Quality: thumbnailQuality})
+}
+
+// decodeImageFile декодирует изображение, предварительно проверив его размеры
+func decodeImageFile(srcPath, mimeType string) (image.Image, error) {
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var decodeConfig func(io.Reader) (image.Config, error)
+	var decode func(io.Reader) (image.Image, error)
+	switch mimeType {
+	case "image/jpeg":
+		decodeConfig, decode = jpeg.DecodeConfig, jpeg.Decode
+	case "image/png":
+		decodeConfig, decode = png.DecodeConfig, png.Decode
+	case "image/gif":
+		decodeConfig, decode = gif.DecodeConfig, gif.Decode // первый кадр
+	default:
+		return nil, ErrThumbnailUnsupported
+	}
+
+	if err := checkDimensions(decodeConfig(f)); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, err := decode(f)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	return img, nil
+}
+
+// resizeToFit уменьшает изображение усреднением по площади так, чтобы наибольшая сторона не превышала maxSize.
+// Прозрачные области заливаются белым: JPEG не поддерживает альфа-канал
+func resizeToFit(img image.Image, maxSize int) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Over)
+
+	dstW, dstH := w, h
+	if w > maxSize || h > maxSize {
+		if w >= h {
+			dstW, dstH = maxSize, max(1, h*maxSize/w)
+		} else {
+			dstW, dstH = max(1, w*maxSize/h), maxSize
+		}
+	}
+	if dstW == w && dstH == h {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*h/dstH, max(y*h/dstH+1, (y+1)*h/dstH)
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*w/dstW, max(x*w/dstW+1, (x+1)*w/dstW)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				offset := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[offset+c])
+					}
+					offset += 4
+				}
+			}
+
+			count := (x1 - x0) * (y1 - y0)
+			offset := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[offset+c] = uint8(sum[c] / count)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,117 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakePDFRenderer возвращает заранее заданную "страницу"
+type fakePDFRenderer struct {
+	page image.Image
+}
+
+func (f fakePDFRenderer) RenderFirstPage(ctx context.Context, pdfPath string, maxSize int) (image.Image, error) {
+	return f.page, nil
+}
+
+func writePNG(t *testing.T, img image.Image) string {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), "image.png")
+	f, err := os.Create(filePath)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return filePath
+}
+
+func TestThumbnailerImage(t *testing.T) {
+	// Прозрачное изображение 1000x500 с непрозрачной красной левой половиной
+	img := image.NewNRGBA(image.Rect(0, 0, 1000, 500))
+	for y := 0; y < 500; y++ {
+		for x := 0; x < 500; x++ {
+			img.Set(x, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+	srcPath := writePNG(t, img)
+
+	var out bytes.Buffer
+	if err := NewThumbnailer(nil).Generate(context.Background(), &out, srcPath, "image/png"); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	thumb, err := jpeg.Decode(&out)
+	if err != nil {
+		t.Fatalf("jpeg.Decode() error = %v", err)
+	}
+	if b := thumb.Bounds(); b.Dx() != ThumbnailMaxSize || b.Dy() != ThumbnailMaxSize/2 {
+		t.Fatalf("thumbnail size = %dx%d, want %dx%d", b.Dx(), b.Dy(), ThumbnailMaxSize, ThumbnailMaxSize/2)
+	}
+	if r, g, _, _ := thumb.At(10, 10).RGBA(); r>>8 < 200 || g>>8 > 60 {
+		t.Errorf("left pixel = %v, want red", thumb.At(10, 10))
+	}
+	if r, g, b, _ := thumb.At(ThumbnailMaxSize-10, 10).RGBA(); r>>8 < 200 || g>>8 < 200 || b>>8 < 200 {
+		t.Errorf("transparent pixel = %v, want white", thumb.At(ThumbnailMaxSize-10, 10))
+	}
+}
+
+func TestThumbnailerSmallImageKeepsSize(t *testing.T) {
+	srcPath := writePNG(t, image.NewGray(image.Rect(0, 0, 40, 30)))
+
+	var out bytes.Buffer
+	if err := NewThumbnailer(nil).Generate(context.Background(), &out, srcPath, "image/png"); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	cfg, err := jpeg.DecodeConfig(&out)
+	if err != nil {
+		t.Fatalf("jpeg.DecodeConfig() error = %v", err)
+	}
+	if cfg.Width != 40 || cfg.Height != 30 {
+		t.Errorf("thumbnail size = %dx%d, want 40x30", cfg.Width, cfg.Height)
+	}
+}
+
+func TestThumbnailerPDF(t *testing.T) {
+	withoutRenderer := NewThumbnailer(nil)
+	if withoutRenderer.Supports("application/pdf") {
+		t.Error("Supports(pdf) without renderer = true, want false")
+	}
+	err := withoutRenderer.Generate(context.Background(), &bytes.Buffer{}, "doc.pdf", "application/pdf")
+	if !errors.Is(err, ErrThumbnailUnsupported) {
+		t.Errorf("Generate(pdf) without renderer error = %v, want ErrThumbnailUnsupported", err)
+	}
+
+	thumbnailer := NewThumbnailer(fakePDFRenderer{page: image.NewGray(image.Rect(0, 0, 226, 320))})
+	var out bytes.Buffer
+	if err := thumbnailer.Generate(context.Background(), &out, "doc.pdf", "application/pdf"); err != nil {
+		t.Fatalf("Generate(pdf) error = %v", err)
+	}
+	if _, err := jpeg.Decode(&out); err != nil {
+		t.Errorf("PDF preview is not a JPEG: %v", err)
+	}
+}
+
+func TestThumbnailerSupports(t *testing.T) {
+	thumbnailer := NewThumbnailer(nil)
+	for _, mimeType := range []string{"image/jpeg", "image/jpg", "image/png", "image/gif"} {
+		if !thumbnailer.Supports(mimeType) {
+			t.Errorf("Supports(%s) = false, want true", mimeType)
+		}
+	}
+	for _, mimeType := range []string{"image/webp", "text/plain", "video/mp4"} {
+		if thumbnailer.Supports(mimeType) {
+			t.Errorf("Supports(%s) = true, want false", mimeType)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNoEvents возвращается, если в файле нет ни одного VEVENT
+var ErrNoEvents = errors.New("ics: no VEVENT entries found")
+
+// MaxEvents ограничивает количество событий в одном файле (защита от слишком больших импортов)
+const MaxEvents = 1000
+
+// Event представляет событие VEVENT из ICS файла (RFC 5545)
+// Поддерживается подмножество свойств, достаточное для календаря праздников:
+// UID, SUMMARY, CATEGORIES, DTSTART, DTEND
+type Event struct {
+	UID        string
+	Summary    string
+	Categories []string
+	Start      time.Time
+	End        time.Time // Для событий на весь день - исключительная граница (как в RFC 5545)
+	AllDay     bool
+}
+
+// LastDay возвращает последний покрываемый событием день (включительно)
+// Для событий на весь день DTEND исключительный, поэтому вычитаем один день
+func (e *Event) LastDay() time.Time {
+	if e.End.IsZero() || !e.End.After(e.Start) {
+		return e.Start
+	}
+	if e.AllDay {
+		return e.End.AddDate(0, 0, -1)
+	}
+	// Событие, заканчивающееся ровно в полночь, не занимает следующий день
+	if e.End.Hour() == 0 && e.End.Minute() == 0 && e.End.Second() == 0 {
+		return e.End.Add(-time.Second)
+	}
+	return e.End
+}
+
+// Parse разбирает ICS поток и возвращает список событий
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var current *Event
+
+	for i, line := range lines {
+		name, params, value, ok := splitContentLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current = &Event{}
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current == nil {
+				return nil, fmt.Errorf("ics: line %d: END:VEVENT without BEGIN", i+1)
+			}
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("ics: line %d: VEVENT without DTSTART", i+1)
+			}
+			events = append(events, *current)
+			if len(events) > MaxEvents {
+				return nil, fmt.Errorf("ics: too many events (max %d)", MaxEvents)
+			}
+			current = nil
+		case current == nil:
+			// Свойства вне VEVENT (VCALENDAR, VTIMEZONE) игнорируем
+			continue
+		case name == "UID":
+			current.UID = value
+		case name == "SUMMARY":
+			current.Summary = unescapeText(value)
+		case name == "CATEGORIES":
+			for _, category := range strings.Split(value, ",") {
+				if category = strings.TrimSpace(unescapeText(category)); category != "" {
+					current.Categories = append(current.Categories, category)
+				}
+			}
+		case name == "DTSTART":
+			t, allDay, err := parseDateValue(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("ics: line %d: invalid DTSTART: %w", i+1, err)
+			}
+			current.Start = t
+			current.AllDay = allDay
+		case name == "DTEND":
+			t, _, err := parseDateValue(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("ics: line %d: invalid DTEND: %w", i+1, err)
+			}
+			current.End = t
+		}
+	}
+
+	if current != nil {
+		return nil, errors.New("ics: unterminated VEVENT")
+	}
+	if len(events) == 0 {
+		return nil, ErrNoEvents
+	}
+
+	return events, nil
+}
+
+// unfoldLines читает строки и склеивает "сложенные" строки (продолжения начинаются с пробела или табуляции)
+func unfoldLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ics: failed to read input: %w", err)
+	}
+	return lines, nil
+}
+
+// splitContentLine разбирает строку вида NAME;PARAM=VALUE:value
+func splitContentLine(line string) (string, map[string]string, string, bool) {
+	colon := strings.Index(line, ":")
+	if colon <= 0 {
+		return "", nil, "", false
+	}
+
+	head := line[:colon]
+	value := line[colon+1:]
+
+	parts := strings.Split(head, ";")
+	name := strings.ToUpper(strings.TrimSpace(parts[0]))
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		if eq := strings.Index(p, "="); eq > 0 {
+			params[strings.ToUpper(p[:eq])] = strings.Trim(p[eq+1:], `"`)
+		}
+	}
+
+	return name, params, value, true
+}
+
+// parseDateValue разбирает DATE (20240101) или DATE-TIME (20240101T090000[Z]) значение
+func parseDateValue(value string, params map[string]string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+
+	loc := time.UTC
+	if tzid, ok := params["TZID"]; ok && !strings.HasSuffix(value, "Z") {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// unescapeText снимает экранирование TEXT значений (RFC 5545, раздел 3.3.11)
+func unescapeText(s string) string {
+	replacer := strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, "\n", `\N`, "\n")
+	return replacer.Replace(s)
+}
@@ -0,0 +1,104 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Test//RU\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:new-year-2025@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20250101\r\n" +
+	"DTEND;VALUE=DATE:20250109\r\n" +
+	"SUMMARY:Новогодние \r\n" +
+	" каникулы\r\n" +
+	"CATEGORIES:Break,Holidays\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:defender-day\r\n" +
+	"DTSTART:20250223T000000Z\r\n" +
+	"DTEND:20250224T000000Z\r\n" +
+	"SUMMARY:День защитника Отечества\\, выходной\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(sampleCalendar))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	first := events[0]
+	if first.UID != "new-year-2025@example.com" {
+		t.Errorf("unexpected UID: %q", first.UID)
+	}
+	if first.Summary != "Новогодние каникулы" {
+		t.Errorf("folded SUMMARY not unfolded: %q", first.Summary)
+	}
+	if !first.AllDay {
+		t.Error("expected all-day event")
+	}
+	if len(first.Categories) != 2 || first.Categories[0] != "Break" {
+		t.Errorf("unexpected categories: %v", first.Categories)
+	}
+	if got := first.LastDay().Format("2006-01-02"); got != "2025-01-08" {
+		t.Errorf("expected exclusive DTEND to give last day 2025-01-08, got %s", got)
+	}
+
+	second := events[1]
+	if second.Summary != "День защитника Отечества, выходной" {
+		t.Errorf("escaped comma not unescaped: %q", second.Summary)
+	}
+	if second.AllDay {
+		t.Error("expected timed event")
+	}
+	if got := second.LastDay().Format("2006-01-02"); got != "2025-02-23" {
+		t.Errorf("event ending at midnight should not cover next day, got %s", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "No events",
+			input: "BEGIN:VCALENDAR\nEND:VCALENDAR\n",
+		},
+		{
+			name:  "Missing DTSTART",
+			input: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\nEND:VCALENDAR\n",
+		},
+		{
+			name:  "Unterminated event",
+			input: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20250101\n",
+		},
+		{
+			name:  "Invalid date",
+			input: "BEGIN:VEVENT\nDTSTART;VALUE=DATE:2025-01-01\nEND:VEVENT\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.input)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestEvent_LastDay_SingleDay(t *testing.T) {
+	start := time.Date(2025, 5, 9, 0, 0, 0, 0, time.UTC)
+	event := Event{Start: start, AllDay: true}
+	if !event.LastDay().Equal(start) {
+		t.Errorf("event without DTEND should cover only start day, got %s", event.LastDay())
+	}
+}
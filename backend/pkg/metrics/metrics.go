@@ -126,4 +126,24 @@ var (
 			Help: "Total number of Telegram API errors",
 		},
 	)
+
+	// Analytics metrics
+	// Длительность обновления материализованных представлений аналитики
+	AnalyticsRefreshDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "analytics_refresh_duration_seconds",
+			Help:    "Duration of analytics materialized view refreshes",
+			Buckets: []float64{0.1, 0.5, 1, 5, 15, 60, 300},
+		},
+		[]string{"view"},
+	)
+
+	// Счетчик неудачных обновлений представлений аналитики
+	AnalyticsRefreshErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_refresh_errors_total",
+			Help: "Total number of failed analytics materialized view refreshes",
+		},
+		[]string{"view"},
+	)
 )
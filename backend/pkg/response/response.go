@@ -20,8 +20,9 @@ type ErrorResponse struct {
 
 // ErrorDetail содержит детали ошибки
 type ErrorDetail struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 // Success отправляет успешный JSON ответ
@@ -42,6 +43,11 @@ func Success(w http.ResponseWriter, statusCode int, data interface{}) {
 
 // Error отправляет JSON ответ с ошибкой
 func Error(w http.ResponseWriter, statusCode int, code string, message string) {
+	ErrorWithDetails(w, statusCode, code, message, nil)
+}
+
+// ErrorWithDetails отправляет JSON ответ с ошибкой и структурированными деталями (например, списком конфликтов)
+func ErrorWithDetails(w http.ResponseWriter, statusCode int, code string, message string, details interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
@@ -50,6 +56,7 @@ func Error(w http.ResponseWriter, statusCode int, code string, message string) {
 		Error: ErrorDetail{
 			Code:    code,
 			Message: message,
+			Details: details,
 		},
 	}
 
@@ -102,6 +109,13 @@ const (
 	ErrCodeChatFileNotFound     = "CHAT_FILE_NOT_FOUND"
 	ErrCodeChatPermissionDenied = "CHAT_PERMISSION_DENIED"
 	ErrCodeChatFileUploadFailed = "CHAT_FILE_UPLOAD_FAILED"
+
+	// Ошибки проверки загружаемых файлов
+	ErrCodeFileTypeMismatch = "FILE_TYPE_MISMATCH"
+	ErrCodeFileInfected     = "FILE_INFECTED"
+
+	// Ошибки квот хранилища файлов
+	ErrCodeStorageQuotaExceeded = "STORAGE_QUOTA_EXCEEDED"
 )
 
 // Предопределенные ответы с ошибками для типичных сценариев
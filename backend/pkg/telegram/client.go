@@ -372,17 +372,24 @@ func (c *Client) SendMediaGroup(chatID int64, files []MediaFile) (int, error) {
 
 // sendDocumentAttempt выполняет одну попытку отправки документа
 func (c *Client) sendDocumentAttempt(chatID int64, fileName, filePath string) error {
+	_, err := c.uploadFile("sendDocument", "document", chatID, fileName, filePath, nil)
+	return err
+}
+
+// uploadFile загружает локальный файл multipart-запросом в поле field метода method.
+// extra содержит дополнительные поля формы (caption, parse_mode и т.д.)
+func (c *Client) uploadFile(method, field string, chatID int64, fileName, filePath string, extra map[string]string) (*APIResponse, error) {
 	// Открываем файл
 	file, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
 	// Получаем информацию о файле
 	fileInfo, err := file.Stat()
 	if err != nil {
-		return fmt.Errorf("failed to stat file: %w", err)
+		return nil, fmt.Errorf("failed to stat file: %w", err)
 	}
 
 	// Создаем multipart form
@@ -391,54 +398,60 @@ func (c *Client) sendDocumentAttempt(chatID int64, fileName, filePath string) er
 
 	// Добавляем поле chat_id
 	if err := writer.WriteField("chat_id", fmt.Sprintf("%d", chatID)); err != nil {
-		return fmt.Errorf("failed to write chat_id field: %w", err)
+		return nil, fmt.Errorf("failed to write chat_id field: %w", err)
+	}
+
+	for key, value := range extra {
+		if err := writer.WriteField(key, value); err != nil {
+			return nil, fmt.Errorf("failed to write %s field: %w", key, err)
+		}
 	}
 
-	// Добавляем файл как document
-	part, err := writer.CreateFormFile("document", fileName)
+	// Добавляем сам файл
+	part, err := writer.CreateFormFile(field, fileName)
 	if err != nil {
-		return fmt.Errorf("failed to create form file: %w", err)
+		return nil, fmt.Errorf("failed to create form file: %w", err)
 	}
 
 	if _, err := io.Copy(part, file); err != nil {
-		return fmt.Errorf("failed to copy file data: %w", err)
+		return nil, fmt.Errorf("failed to copy file data: %w", err)
 	}
 
 	// Закрываем writer чтобы записать boundary
 	contentType := writer.FormDataContentType()
 	if err := writer.Close(); err != nil {
-		return fmt.Errorf("failed to close multipart writer: %w", err)
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
 	// Отправляем запрос
-	url := c.baseURL + "sendDocument"
+	url := c.baseURL + method
 	req, err := http.NewRequest(http.MethodPost, url, &body)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", contentType)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Парсим ответ
 	var apiResp APIResponse
 	if err := json.Unmarshal(respBody, &apiResp); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	// Обработка ошибок API
 	if !apiResp.Ok {
-		return &TelegramError{
+		return nil, &TelegramError{
 			ErrorCode:   apiResp.ErrorCode,
 			Description: apiResp.Description,
 		}
@@ -446,7 +459,7 @@ func (c *Client) sendDocumentAttempt(chatID int64, fileName, filePath string) er
 
 	// Обработка HTTP статусов
 	if resp.StatusCode >= 400 {
-		return &TelegramError{
+		return nil, &TelegramError{
 			ErrorCode:   resp.StatusCode,
 			Description: fmt.Sprintf("HTTP error: %s", resp.Status),
 		}
@@ -454,11 +467,12 @@ func (c *Client) sendDocumentAttempt(chatID int64, fileName, filePath string) er
 
 	log.Info().
 		Int64("chat_id", chatID).
+		Str("method", method).
 		Str("file_name", fileName).
 		Int64("file_size", fileInfo.Size()).
-		Msg("Document sent successfully")
+		Msg("File uploaded successfully")
 
-	return nil
+	return &apiResp, nil
 }
 
 // GetMe получает информацию о боте
@@ -0,0 +1,168 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Режимы форматирования текста сообщений (parse_mode)
+const (
+	ParseModeHTML       = "HTML"
+	ParseModeMarkdownV2 = "MarkdownV2"
+)
+
+// MediaKind тип отправляемого вложения
+type MediaKind string
+
+// Поддерживаемые типы вложений
+const (
+	MediaKindPhoto    MediaKind = "photo"
+	MediaKindDocument MediaKind = "document"
+)
+
+// MessageOptions дополнительные параметры отправки и редактирования сообщения
+type MessageOptions struct {
+	ParseMode   string                // Пусто - обычный текст
+	ReplyMarkup *InlineKeyboardMarkup // Inline кнопки под сообщением
+}
+
+// InputFile описывает вложение: уже загруженный в Telegram file_id или локальный файл
+type InputFile struct {
+	FileID   string // Если задан, файл повторно не загружается
+	FileName string // Отображаемое имя файла
+	FilePath string // Путь к файлу на диске
+}
+
+// applyTo добавляет параметры в JSON payload запроса
+func (o *MessageOptions) applyTo(payload map[string]interface{}) {
+	if o == nil {
+		return
+	}
+	if o.ParseMode != "" {
+		payload["parse_mode"] = o.ParseMode
+	}
+	if o.ReplyMarkup != nil {
+		payload["reply_markup"] = o.ReplyMarkup
+	}
+}
+
+// SendTextMessage отправляет текстовое сообщение с форматированием и кнопками.
+// Возвращает отправленное сообщение, чтобы сохранить его message_id
+func (c *Client) SendTextMessage(chatID int64, text string, opts *MessageOptions) (*Message, error) {
+	payload := map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+	}
+	opts.applyTo(payload)
+
+	resp, err := c.doRequest("sendMessage", payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return decodeMessage(resp)
+}
+
+// SendFile отправляет фото или документ. Если у файла есть FileID, используется он,
+// иначе файл загружается с диска. file_id загруженного файла можно получить через FileIDOf
+func (c *Client) SendFile(chatID int64, kind MediaKind, file InputFile) (*Message, error) {
+	method := "sendDocument"
+	if kind == MediaKindPhoto {
+		method = "sendPhoto"
+	}
+
+	var (
+		resp *APIResponse
+		err  error
+	)
+	if file.FileID != "" {
+		resp, err = c.doRequest(method, map[string]interface{}{
+			"chat_id":    chatID,
+			string(kind): file.FileID,
+		})
+	} else {
+		resp, err = c.uploadFile(method, string(kind), chatID, file.FileName, file.FilePath, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", kind, err)
+	}
+
+	return decodeMessage(resp)
+}
+
+// EditMessageText изменяет текст (и кнопки) ранее отправленного сообщения
+func (c *Client) EditMessageText(chatID, messageID int64, text string, opts *MessageOptions) error {
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": messageID,
+		"text":       text,
+	}
+	opts.applyTo(payload)
+
+	if _, err := c.doRequest("editMessageText", payload); err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMessage удаляет сообщение из чата.
+// Telegram позволяет боту удалять свои сообщения в личных чатах только в течение 48 часов
+func (c *Client) DeleteMessage(chatID, messageID int64) error {
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": messageID,
+	}
+
+	if _, err := c.doRequest("deleteMessage", payload); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	return nil
+}
+
+// AnswerCallbackQuery подтверждает нажатие inline кнопки, чтобы у пользователя пропал индикатор загрузки
+func (c *Client) AnswerCallbackQuery(callbackQueryID, text string) error {
+	payload := map[string]interface{}{
+		"callback_query_id": callbackQueryID,
+	}
+	if text != "" {
+		payload["text"] = text
+	}
+
+	if _, err := c.doRequest("answerCallbackQuery", payload); err != nil {
+		return fmt.Errorf("failed to answer callback query: %w", err)
+	}
+
+	return nil
+}
+
+// FileIDOf возвращает file_id вложения из отправленного сообщения
+// (для фото - самый большой размер)
+func FileIDOf(msg *Message) string {
+	if msg == nil {
+		return ""
+	}
+	if msg.Document != nil {
+		return msg.Document.FileID
+	}
+	if len(msg.Photo) > 0 {
+		return msg.Photo[len(msg.Photo)-1].FileID
+	}
+	return ""
+}
+
+// IsMessageNotModified проверяет ошибку editMessageText, когда новый текст совпадает со старым
+func IsMessageNotModified(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "message is not modified")
+}
+
+// decodeMessage разбирает Message из результата запроса
+func decodeMessage(resp *APIResponse) (*Message, error) {
+	var msg Message
+	if err := json.Unmarshal(resp.Result, &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	return &msg, nil
+}
@@ -14,6 +14,9 @@ type Message struct {
 	Chat      *Chat  `json:"chat"`
 	Text      string `json:"text,omitempty"`
 	Date      int64  `json:"date"`
+
+	Photo    []PhotoSize `json:"photo,omitempty"`
+	Document *Document   `json:"document,omitempty"`
 }
 
 // User представляет пользователя или бота в Telegram
@@ -42,3 +45,34 @@ type CallbackQuery struct {
 	Message *Message `json:"message,omitempty"`
 	Data    string   `json:"data,omitempty"`
 }
+
+// PhotoSize представляет один из размеров фотографии
+type PhotoSize struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// Document представляет файл, отправленный как документ
+type Document struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// InlineKeyboardButton представляет кнопку inline клавиатуры.
+// Должно быть задано ровно одно из полей URL или CallbackData
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	URL          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
+}
+
+// InlineKeyboardMarkup представляет inline клавиатуру под сообщением
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
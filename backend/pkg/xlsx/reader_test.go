@@ -0,0 +1,102 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReadRows_RoundTrip(t *testing.T) {
+	w := NewWriter("Users")
+	w.SetHeader("email", "first_name", "max_students")
+	w.AddRow("a@example.com", "Анна", 4)
+	w.AddRow("b@example.com", nil, 1.5)
+
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	rows, err := ReadRows(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadRows() error = %v", err)
+	}
+
+	want := [][]string{
+		{"email", "first_name", "max_students"},
+		{"a@example.com", "Анна", "4"},
+		{"b@example.com", "", "1.5"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if len(rows[i]) != len(want[i]) {
+			t.Fatalf("row %d = %v, want %v", i, rows[i], want[i])
+		}
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("cell [%d][%d] = %q, want %q", i, j, rows[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+func TestReadRows_SharedStringsAndGaps(t *testing.T) {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Data" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId7" Type="worksheet" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>email</t></si><si><r><t>Bold</t></r><r><t xml:space="preserve"> part</t></r></si></sst>`,
+		"xl/worksheets/data.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="3"><c r="B3"><v>46143.5</v></c><c r="C3" t="b"><v>1</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, _ := zw.Create(name)
+		_, _ = f.Write([]byte(content))
+	}
+	_ = zw.Close()
+
+	rows, err := ReadRows(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadRows() error = %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3 (empty row kept): %v", len(rows), rows)
+	}
+	if rows[0][0] != "email" || rows[0][1] != "" || rows[0][2] != "Bold part" {
+		t.Errorf("row 1 = %q", rows[0])
+	}
+	if len(rows[1]) != 0 {
+		t.Errorf("row 2 = %q, want empty", rows[1])
+	}
+	if rows[2][0] != "" || rows[2][1] != "46143.5" || rows[2][2] != "true" {
+		t.Errorf("row 3 = %q", rows[2])
+	}
+}
+
+func TestReadRows_InvalidFile(t *testing.T) {
+	data := []byte("email,name\n")
+	if _, err := ReadRows(bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("ReadRows() error = %v, want ErrInvalidFile", err)
+	}
+}
+
+func TestSerialToTime(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*3600)
+	got := SerialToTime(46143.5, loc)
+	want := time.Date(2026, 5, 1, 12, 0, 0, 0, loc)
+	if !got.Equal(want) {
+		t.Errorf("SerialToTime() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,217 @@
+// Package xlsx реализует минимальную запись XLSX (Office Open XML) на стандартной библиотеке:
+// одна таблица, строки и числа, жирная строка заголовка. Этого достаточно для выгрузок из админки
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType MIME тип XLSX файла
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// MaxSheetNameLength ограничение Excel на длину имени листа
+const MaxSheetNameLength = 31
+
+// Writer накапливает строки одного листа и сохраняет их в XLSX
+type Writer struct {
+	sheetName string
+	header    []string
+	rows      [][]interface{}
+}
+
+// NewWriter создает Writer с листом sheetName
+func NewWriter(sheetName string) *Writer {
+	return &Writer{sheetName: sanitizeSheetName(sheetName)}
+}
+
+// SetHeader задает строку заголовка (выделяется жирным)
+func (w *Writer) SetHeader(columns ...string) {
+	w.header = columns
+}
+
+// AddRow добавляет строку. Поддерживаются string, целые и дробные числа, bool и time.Time;
+// остальные значения записываются через fmt.Sprint
+func (w *Writer) AddRow(values ...interface{}) {
+	w.rows = append(w.rows, values)
+}
+
+// Write сохраняет книгу в out
+func (w *Writer) Write(out io.Writer) error {
+	zw := zip.NewWriter(out)
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", []byte(contentTypesXML)},
+		{"_rels/.rels", []byte(rootRelsXML)},
+		{"xl/workbook.xml", []byte(fmt.Sprintf(workbookXML, escape(w.sheetName)))},
+		{"xl/_rels/workbook.xml.rels", []byte(workbookRelsXML)},
+		{"xl/styles.xml", []byte(stylesXML)},
+		{"xl/worksheets/sheet1.xml", w.sheetXML()},
+	}
+
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return fmt.Errorf("xlsx: failed to create %s: %w", f.name, err)
+		}
+		if _, err := fw.Write(f.content); err != nil {
+			return fmt.Errorf("xlsx: failed to write %s: %w", f.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("xlsx: failed to finalize archive: %w", err)
+	}
+	return nil
+}
+
+// sheetXML формирует XML листа
+func (w *Writer) sheetXML() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	rowNum := 0
+	if len(w.header) > 0 {
+		rowNum++
+		values := make([]interface{}, len(w.header))
+		for i, h := range w.header {
+			values[i] = h
+		}
+		writeRow(&buf, rowNum, values, boldStyle)
+	}
+	for _, row := range w.rows {
+		rowNum++
+		writeRow(&buf, rowNum, row, defaultStyle)
+	}
+
+	buf.WriteString(`</sheetData></worksheet>`)
+	return buf.Bytes()
+}
+
+// Индексы стилей из stylesXML
+const (
+	defaultStyle = 0
+	boldStyle    = 1
+)
+
+// writeRow записывает строку листа
+func writeRow(buf *bytes.Buffer, rowNum int, values []interface{}, style int) {
+	fmt.Fprintf(buf, `<row r="%d">`, rowNum)
+	for i, v := range values {
+		ref := ColumnName(i) + strconv.Itoa(rowNum)
+		styleAttr := ""
+		if style != defaultStyle {
+			styleAttr = fmt.Sprintf(` s="%d"`, style)
+		}
+
+		switch val := v.(type) {
+		case nil:
+			continue
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			fmt.Fprintf(buf, `<c r="%s"%s><v>%d</v></c>`, ref, styleAttr, val)
+		case float32:
+			fmt.Fprintf(buf, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, strconv.FormatFloat(float64(val), 'f', -1, 32))
+		case float64:
+			fmt.Fprintf(buf, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, strconv.FormatFloat(val, 'f', -1, 64))
+		case bool:
+			b := 0
+			if val {
+				b = 1
+			}
+			fmt.Fprintf(buf, `<c r="%s"%s t="b"><v>%d</v></c>`, ref, styleAttr, b)
+		case time.Time:
+			writeString(buf, ref, styleAttr, val.Format("2006-01-02 15:04"))
+		case string:
+			writeString(buf, ref, styleAttr, val)
+		default:
+			writeString(buf, ref, styleAttr, fmt.Sprint(val))
+		}
+	}
+	buf.WriteString(`</row>`)
+}
+
+// writeString записывает строковую ячейку (inline string, без таблицы общих строк)
+func writeString(buf *bytes.Buffer, ref, styleAttr, value string) {
+	fmt.Fprintf(buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, styleAttr, escape(value))
+}
+
+// ColumnName возвращает буквенное имя столбца по индексу с нуля: 0 -> A, 25 -> Z, 26 -> AA
+func ColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// escape экранирует текст для XML и удаляет управляющие символы, недопустимые в XML 1.0
+func escape(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || r >= 0x20 {
+			return r
+		}
+		return -1
+	}, s)
+
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// sanitizeSheetName убирает символы, запрещенные Excel в имени листа, и обрезает длину
+func sanitizeSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = "Sheet1"
+	}
+	if runes := []rune(name); len(runes) > MaxSheetNameLength {
+		name = string(runes[:MaxSheetNameLength])
+	}
+	return name
+}
+
+const contentTypesXML = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookXML = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const workbookRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`